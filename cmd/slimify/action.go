@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+var githubAction bool
+
+// actionInput returns an input of the action from its INPUT_<NAME>
+// environment variable, as set by the GitHub Actions runner
+func actionInput(name string) string {
+	return strings.TrimSpace(os.Getenv("INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))))
+}
+
+// actionBoolInput returns a boolean input of the action, false if it is
+// empty. Like in @actions/core, only true, True, TRUE, false, False, and
+// FALSE are accepted.
+func actionBoolInput(name string) (bool, error) {
+	switch v := actionInput(name); v {
+	case "", "false", "False", "FALSE":
+		return false, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	default:
+		return false, fmt.Errorf("input %s must be true or false, got %q", name, v)
+	}
+}
+
+// appendToEnvFile appends content to the file named by an environment
+// variable of the runner (GITHUB_OUTPUT, GITHUB_STEP_SUMMARY), if it is set
+func appendToEnvFile(envVar, content string) error {
+	path := os.Getenv(envVar)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", envVar, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", envVar, err)
+	}
+	return f.Close()
+}
+
+// runGitHubAction is the entrypoint of the gh-slimify action. It reads the
+// action's inputs from INPUT_* environment variables, scans the workflows,
+// and writes the results to the job log, the step outputs (GITHUB_OUTPUT),
+// and the job summary (GITHUB_STEP_SUMMARY).
+func runGitHubAction() {
+	var err error
+	if v := actionInput("config"); v != "" {
+		configPath = v
+	}
+	changedBase = actionInput("base")
+	for name, dst := range map[string]*bool{
+		"changed":       &changedOnly,
+		"skip-duration": &skipDuration,
+		"check-run":     &checkRun,
+	} {
+		if *dst, err = actionBoolInput(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	failOnCandidates, err := actionBoolInput("fail-on-candidates")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Workflows are separated by whitespace or newlines; none scans them all
+	files := strings.Fields(actionInput("workflows"))
+	if changedOnly {
+		if len(files) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: inputs changed and workflows cannot be combined\n")
+			os.Exit(1)
+		}
+		changed, base, err := changedWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(changed) == 0 {
+			fmt.Printf("No workflow files changed since %s.\n", base)
+			writeActionResults(&scan.ScanResult{Candidates: []*scan.Candidate{}, IneligibleJobs: []*scan.IneligibleJob{}})
+			return
+		}
+		files = changed
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := scan.ScanWithOptions(opts, files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printScanResult(result)
+	if checkRun {
+		if err := createCheckRun(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	writeActionResults(result)
+
+	if failOnCandidates && len(result.Candidates) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d job(s) can be migrated to ubuntu-slim (fail-on-candidates)\n", len(result.Candidates))
+		os.Exit(1)
+	}
+}
+
+// writeActionResults writes the step outputs and the job summary of a scan
+func writeActionResults(result *scan.ScanResult) {
+	data, err := json.Marshal(result.Candidates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	outputs := fmt.Sprintf("candidates=%d\nineligible=%d\ncandidates-json=%s\n", len(result.Candidates), len(result.IneligibleJobs), data)
+	if err := appendToEnvFile("GITHUB_OUTPUT", outputs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := appendToEnvFile("GITHUB_STEP_SUMMARY", actionSummary(result)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// actionSummary renders the job summary of a scan in Markdown
+func actionSummary(result *scan.ScanResult) string {
+	var b strings.Builder
+	b.WriteString("### 🪶 gh-slimify\n\n")
+	if len(result.Candidates) == 0 {
+		b.WriteString("No job can move from `ubuntu-latest` to `ubuntu-slim`.\n")
+	} else {
+		fmt.Fprintf(&b, "**%d job(s) can run on `ubuntu-slim` instead of `ubuntu-latest`:**\n\n", len(result.Candidates))
+		writeCommentTable(&b, append([]*scan.Candidate{}, result.Candidates...))
+		b.WriteString("\nRun `gh slimify fix --all` to migrate them. Jobs marked ⚠️ need a review first (see `gh slimify why`).\n")
+	}
+	var staying []string
+	for _, j := range result.IneligibleJobs {
+		if !j.OutOfScope() {
+			staying = append(staying, fmt.Sprintf("- `%s` (`%s:%d`): %s\n", j.JobName, j.WorkflowPath, j.LineNumber, strings.Join(j.Reasons, "; ")))
+		}
+	}
+	if len(staying) > 0 {
+		fmt.Fprintf(&b, "\n<details><summary>%d job(s) must stay on <code>ubuntu-latest</code></summary>\n\n", len(staying))
+		b.WriteString(strings.Join(staying, ""))
+		b.WriteString("\n</details>\n")
+	}
+	for _, e := range result.LoadErrors {
+		fmt.Fprintf(&b, "\n> [!WARNING]\n> `%s` could not be analyzed: %s\n", e.Path, e.Error)
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/guard"
+	"github.com/fchimpan/gh-slimify/internal/plan"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var applyBackup bool
+
+func newApplyCmd() *cobra.Command {
+	applyCmd := &cobra.Command{
+		Use:   "apply <plan-file>",
+		Short: "Make the edits saved by 'gh slimify plan'",
+		Long: `Make the edits saved in a plan file by 'gh slimify plan', and nothing else.
+
+Before anything is modified, every workflow in the plan is checked against the
+SHA-256 hash recorded when the plan was created. If any of them has changed,
+no workflow is modified: create a new plan and review it again.
+If a workflow cannot be updated, the workflows already updated are rolled back.`,
+		Args: cobra.ExactArgs(1),
+		Run:  runApply,
+	}
+	applyCmd.Flags().BoolVar(&applyBackup, "backup", false, "Keep a copy of each modified workflow next to it (e.g., ci.yml"+workflow.BackupSuffix+")")
+	return applyCmd
+}
+
+func runApply(cmd *cobra.Command, args []string) {
+	if err := readonly.Check("apply a plan"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	p, err := plan.Load(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(p.Files) == 0 {
+		printf("The plan %s has no changes to apply.\n", args[0])
+		return
+	}
+
+	// Check every workflow before modifying any of them
+	var stale []string
+	for _, f := range p.Files {
+		if err := guard.CheckWorkflowPath(f.Path, workflow.Dir()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read file %s: %v\n", f.Path, err)
+			os.Exit(1)
+		}
+		if workflow.ContentHash(data) != f.SHA256 {
+			stale = append(stale, f.Path)
+		}
+	}
+	if len(stale) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d workflow(s) changed since the plan was created at %s:\n", len(stale), p.CreatedAt.Local().Format("2006-01-02 15:04"))
+		for _, path := range stale {
+			eprintf("  • %s\n", path)
+		}
+		fmt.Fprintf(os.Stderr, "No workflow was modified. Run 'gh slimify plan' again and review the new plan.\n")
+		os.Exit(1)
+	}
+
+	batch := workflow.NewBatch(applyBackup)
+	var entries []state.Entry
+	for _, f := range p.Files {
+		printf("Updating %s\n", f.Path)
+		err := batch.Track(f.Path)
+		if err == nil {
+			err = workflow.ApplyChanges(f.Path, f.SHA256, f.Changes())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+			if err := batch.Rollback(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to roll back updated workflows: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Rolled back the plan. No workflow was modified.\n")
+			}
+			os.Exit(1)
+		}
+		for _, job := range f.Jobs {
+			if len(job.Changes) == 0 {
+				continue
+			}
+			c := job.Changes[0]
+			printf("  ✓ Updated job \"%s\" (L%d) → %s\n", job.Name, c.Line, c.New)
+			entries = append(entries, state.Entry{Workflow: f.Path, JobID: job.ID, JobName: job.Name, From: c.Old, To: c.New})
+		}
+		printLine()
+	}
+
+	recordChanges("apply", entries)
+	printf("Successfully applied the plan to %d job(s).\n", len(entries))
+	if backups := batch.Backups(); len(backups) > 0 {
+		printf("Backups of the original workflows: %s\n", strings.Join(backups, ", "))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/foreign"
+	"github.com/spf13/cobra"
+)
+
+func newAuditForeignCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit-foreign [config-file...]",
+		Short: "(experimental) Audit GitLab CI and CircleCI configs for ubuntu-slim readiness",
+		Long: `Parse GitLab CI (.gitlab-ci.yml) and CircleCI (.circleci/config.yml) configurations
+just enough to report docker-in-docker usage and runner tags, to help see which
+pipelines could map to ubuntu-slim GitHub runners during multi-CI consolidation.
+
+This mode is experimental and read-only: no files are modified and there is no fix.
+Without arguments, the well-known config files in the current directory are audited.`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runAuditForeign,
+	}
+}
+
+func runAuditForeign(cmd *cobra.Command, args []string) {
+	paths := args
+	if len(paths) == 0 {
+		paths = foreign.DefaultPaths()
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no foreign CI configuration found. Specify a file or run from a directory containing %s or %s.\n", foreign.GitLabConfigPath, foreign.CircleCIConfigPath)
+		os.Exit(1)
+	}
+
+	slimCount := 0
+	dockerCount := 0
+	errorCount := 0
+	for _, path := range paths {
+		pipeline, err := foreign.Audit(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			errorCount++
+			continue
+		}
+
+		printf("\n📄 %s (%s)\n", pipeline.Path, pipeline.System)
+		for _, job := range pipeline.Jobs {
+			if job.CouldUseSlim() {
+				slimCount++
+				printf("  ✅ \"%s\" could map to ubuntu-slim\n", job.Name)
+			} else {
+				dockerCount++
+				printf("  ❌ \"%s\" requires docker\n", job.Name)
+				printf("       ❌ %s\n", strings.Join(job.Docker, ", "))
+			}
+			if len(job.Tags) > 0 {
+				printf("       Runner tags: %s\n", strings.Join(job.Tags, ", "))
+			}
+			for _, note := range job.Notes {
+				printf("       💡 %s\n", note)
+			}
+		}
+	}
+
+	printLine()
+	printf("✅ %d job(s) could map to ubuntu-slim\n", slimCount)
+	printf("❌ %d job(s) require docker\n", dockerCount)
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
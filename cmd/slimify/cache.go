@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// cacheContents describes the top-level entries of the cache directory
+var cacheContents = map[string]string{
+	"durations": "Job durations from previous scans, per repository (reused with --retry-unknown)",
+	"actions":   "runs.using of remote actions, per host (--resolve-actions)",
+	"responses": "GitHub API responses revalidated with ETags, per repository (disable with --no-cache)",
+}
+
+func newCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect the files gh-slimify keeps between runs",
+	}
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "info",
+		Short: "Show where caches and state are stored and what they contain",
+		Long: `Show the cache and state directories and the files in them.
+
+Caches are stored in the user cache directory (XDG_CACHE_HOME, e.g.,
+~/.cache/gh-slimify) and state in the user state directory (XDG_STATE_HOME,
+e.g., ~/.local/state/gh-slimify). With --state-dir, they are stored in its
+cache and state subdirectories instead. The migration history is kept in the
+repository (--state-file) so that it can be shared with your team.`,
+		Args: cobra.NoArgs,
+		Run:  runCacheInfo,
+	})
+	return cacheCmd
+}
+
+func runCacheInfo(cmd *cobra.Command, args []string) {
+	cacheDir, err := cache.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stateDir, err := cache.StateDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printf("Cache directory: %s\n", cacheDir)
+	printDirContents(cacheDir, cacheContents)
+	printLine()
+	printf("State directory: %s\n", stateDir)
+	printDirContents(stateDir, nil)
+	printLine()
+
+	if statePath == "" {
+		printLine("Migration history: disabled (--state-file is empty)")
+		return
+	}
+	s, err := state.Load(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printf("Migration history: %s (%d change(s), see 'gh slimify history')\n", statePath, len(s.Migrations))
+}
+
+// printDirContents lists the files under dir with their size, grouped by
+// top-level entry, with the description of the entry if known. Files nested
+// deeper than maxListDepth are summarized per directory.
+func printDirContents(dir string, descriptions map[string]string) {
+	var lines []string
+	var summaries []*dirSummary
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.IsDir() {
+			if rel != "." && !strings.Contains(rel, string(filepath.Separator)) {
+				lines = append(lines, fmt.Sprintf("  %s/  %s", rel, descriptions[rel]))
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if strings.Count(rel, string(filepath.Separator)) >= maxListDepth {
+			parent := filepath.ToSlash(filepath.Dir(rel))
+			if n := len(summaries); n == 0 || summaries[n-1].path != parent {
+				summaries = append(summaries, &dirSummary{path: parent, line: len(lines)})
+				lines = append(lines, "")
+			}
+			summaries[len(summaries)-1].add(info.Size())
+			return nil
+		}
+		lines = append(lines, fmt.Sprintf("    %s (%s)", filepath.ToSlash(rel), formatSize(info.Size())))
+		return nil
+	})
+	for _, sum := range summaries {
+		lines[sum.line] = fmt.Sprintf("    %s/ (%d file(s), %s)", sum.path, sum.files, formatSize(sum.size))
+	}
+	switch {
+	case os.IsNotExist(err):
+		printLine("  (not created yet)")
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+	case len(lines) == 0:
+		printLine("  (empty)")
+	default:
+		for _, line := range lines {
+			printLine(strings.TrimRight(line, " "))
+		}
+	}
+}
+
+// maxListDepth is the depth of the deepest files listed one by one by
+// printDirContents (e.g., durations/<host>/<owner>/<repo>.json)
+const maxListDepth = 4
+
+// dirSummary is the number and total size of the files in a directory
+type dirSummary struct {
+	path  string
+	line  int
+	files int
+	size  int64
+}
+
+func (s *dirSummary) add(size int64) {
+	s.files++
+	s.size += size
+}
+
+// formatSize formats a file size in bytes, KB, or MB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+var (
+	changedOnly bool
+	changedBase string
+)
+
+// changedWorkflows returns the workflow files added or modified since the
+// base ref, and the base ref. They are listed with git or, when git cannot
+// tell (e.g., in a shallow clone or with --no-git), with the compare API.
+func changedWorkflows() ([]string, string, error) {
+	base := changedBase
+	if base == "" {
+		base = defaultBaseRef()
+	}
+	if base == "" {
+		return nil, "", fmt.Errorf("cannot determine the base ref of --changed: specify it with --base (e.g., --base origin/main)")
+	}
+
+	files, gitErr := git.ChangedFiles(base, workflow.Dir())
+	if gitErr != nil {
+		var err error
+		files, err = changedFilesFromAPI(base)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list the files changed since %s: %w (git: %v)", base, err, gitErr)
+		}
+	}
+
+	var workflows []string
+	for _, f := range files {
+		f = filepath.ToSlash(f)
+		if strings.HasPrefix(f, workflow.Dir()+"/") && (strings.HasSuffix(f, ".yml") || strings.HasSuffix(f, ".yaml")) {
+			workflows = append(workflows, f)
+		}
+	}
+	sort.Strings(workflows)
+	return workflows, base, nil
+}
+
+// defaultBaseRef returns the base branch of the pull request in GitHub
+// Actions (GITHUB_BASE_REF), or the default branch of origin, as a
+// remote-tracking ref
+func defaultBaseRef() string {
+	if ref := os.Getenv("GITHUB_BASE_REF"); ref != "" {
+		return "origin/" + ref
+	}
+	ref, _ := git.RemoteHead("origin")
+	return ref
+}
+
+// changedFilesFromAPI lists the files changed between base and HEAD (or
+// GITHUB_SHA) with the compare API
+func changedFilesFromAPI(base string) ([]string, error) {
+	head := os.Getenv("GITHUB_SHA")
+	if head == "" {
+		sha, err := git.HeadCommit()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine HEAD: %w", err)
+		}
+		head = sha
+	}
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	// Remote-tracking refs are branches of the repository on GitHub
+	return client.GetChangedFiles(context.Background(), strings.TrimPrefix(base, "origin/"), head)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// checkRunName is the name of the check run created with --check-run
+const checkRunName = "gh-slimify"
+
+var checkRun bool
+
+// createCheckRun creates a check run on the commit being checked, with an
+// annotation on each ubuntu-latest job: a notice on jobs that can be migrated
+// (a warning if they require attention) and on jobs that cannot, and a
+// failure on runner label typos and workflows that fail to load, which also
+// fail the check run
+func createCheckRun(result *scan.ScanResult) error {
+	sha, err := checkRunSHA()
+	if err != nil {
+		return err
+	}
+	run := newCheckRun(result)
+	run.HeadSHA = sha
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return err
+	}
+	url, err := client.CreateCheckRun(context.Background(), run)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Created check run %s (%s): %s\n", checkRunName, run.Conclusion, url)
+	return nil
+}
+
+// newCheckRun builds the check run of a scan result, without its commit
+func newCheckRun(result *scan.ScanResult) *api.CheckRun {
+	var annotations []api.CheckAnnotation
+	failures := 0
+	for _, e := range result.LoadErrors {
+		annotations = append(annotations, api.CheckAnnotation{
+			Path: e.Path, StartLine: 1, EndLine: 1, Level: api.AnnotationFailure,
+			Title:   "Workflow could not be analyzed",
+			Message: e.Error,
+		})
+		failures++
+	}
+	for _, t := range result.LabelTypos {
+		annotations = append(annotations, api.CheckAnnotation{
+			Path: t.WorkflowPath, StartLine: t.LineNumber, EndLine: t.LineNumber, Level: api.AnnotationFailure,
+			Title:   "Runner label typo",
+			Message: fmt.Sprintf("runs-on %q of job %q looks like a typo of %q: the job would wait for a runner forever.", t.Label, t.JobName, t.Suggestion),
+		})
+		failures++
+	}
+	for _, c := range result.Candidates {
+		level := api.AnnotationNotice
+		message := fmt.Sprintf("Job %q can run on ubuntu-slim instead of ubuntu-latest.", c.JobName)
+		if c.HasWarnings() {
+			level = api.AnnotationWarning
+			message = fmt.Sprintf("Job %q can run on ubuntu-slim instead of ubuntu-latest, but requires attention.", c.JobName)
+			if len(c.CloudCLIs) > 0 {
+				message += fmt.Sprintf(" It relies on cloud CLIs missing in ubuntu-slim: %s.", strings.Join(c.CloudCLIs, ", "))
+			}
+			if len(c.MissingCommands) > 0 {
+				message += fmt.Sprintf(" Commands to install on ubuntu-slim: %s.", strings.Join(c.MissingCommands, ", "))
+				if actions := workflow.SetupActions(c.MissingCommands); len(actions) > 0 {
+					message += fmt.Sprintf(" Add %s, or run gh slimify fix --add-setup.", strings.Join(actions, ", "))
+				}
+			}
+		}
+		annotations = append(annotations, api.CheckAnnotation{
+			Path: c.WorkflowPath, StartLine: c.LineNumber, EndLine: c.LineNumber, Level: level,
+			Title:   "Can run on ubuntu-slim",
+			Message: message,
+		})
+	}
+	ineligible := 0
+	for _, j := range result.IneligibleJobs {
+		// Jobs on other runners are out of scope and not annotated
+		if j.OutOfScope() {
+			continue
+		}
+		annotations = append(annotations, api.CheckAnnotation{
+			Path: j.WorkflowPath, StartLine: j.LineNumber, EndLine: j.LineNumber, Level: api.AnnotationNotice,
+			Title:   "Stays on ubuntu-latest",
+			Message: fmt.Sprintf("Job %q cannot run on ubuntu-slim: %s.", j.JobName, strings.Join(j.Reasons, "; ")),
+		})
+		ineligible++
+	}
+
+	run := &api.CheckRun{Name: checkRunName, Annotations: annotations}
+	switch {
+	case failures > 0:
+		run.Conclusion = "failure"
+		run.Title = fmt.Sprintf("%d problem(s) found", failures)
+	case len(result.Candidates) > 0:
+		run.Conclusion = "neutral"
+		run.Title = fmt.Sprintf("%d job(s) can run on ubuntu-slim", len(result.Candidates))
+	default:
+		run.Conclusion = "success"
+		run.Title = "No job to migrate to ubuntu-slim"
+	}
+	run.Summary = fmt.Sprintf("%d job(s) can be migrated to ubuntu-slim and %d must stay on ubuntu-latest. %d runner label typo(s) and %d workflow(s) that failed to load.",
+		len(result.Candidates), ineligible, len(result.LabelTypos), len(result.LoadErrors))
+	return run
+}
+
+// checkRunSHA returns the commit to create the check run on: the head of the
+// pull request in pull_request workflows (GITHUB_SHA is a merge commit
+// there), GITHUB_SHA in other workflows, and HEAD elsewhere
+func checkRunSHA() (string, error) {
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var event struct {
+				PullRequest struct {
+					Head struct {
+						SHA string `json:"sha"`
+					} `json:"head"`
+				} `json:"pull_request"`
+			}
+			if json.Unmarshal(data, &event) == nil && event.PullRequest.Head.SHA != "" {
+				return event.PullRequest.Head.SHA, nil
+			}
+		}
+	}
+	if sha := os.Getenv("GITHUB_SHA"); sha != "" {
+		return sha, nil
+	}
+	sha, err := git.HeadCommit()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the commit of the check run: %w", err)
+	}
+	return sha, nil
+}
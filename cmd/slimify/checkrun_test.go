@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+func TestNewCheckRun(t *testing.T) {
+	safe := &scan.Candidate{WorkflowPath: "ci.yml", JobID: "lint", JobName: "lint", LineNumber: 4, Duration: "1m"}
+	warning := &scan.Candidate{WorkflowPath: "ci.yml", JobID: "test", JobName: "test", LineNumber: 9, Duration: "1m", MissingCommands: []string{"psql"}}
+	ineligible := &scan.IneligibleJob{WorkflowPath: "ci.yml", JobID: "docker", JobName: "docker", LineNumber: 14, Reasons: []string{"uses Docker commands"}}
+	outOfScope := &scan.IneligibleJob{WorkflowPath: "ci.yml", JobID: "mac", JobName: "mac", LineNumber: 19, Reasons: []string{"not ubuntu-latest"},
+		ReasonRuleIDs: []string{"SLIM001"}, Rules: []scan.RuleResult{{ID: "SLIM001", Result: scan.ResultFail, Reason: "not ubuntu-latest"}}}
+	typo := &scan.LabelTypo{WorkflowPath: "ci.yml", JobID: "build", JobName: "build", LineNumber: 24}
+	loadError := scan.LoadError{Path: "broken.yml", Error: "invalid YAML"}
+
+	tests := []struct {
+		name       string
+		result     *scan.ScanResult
+		conclusion string
+		// levels lists the annotation levels, in order
+		levels []string
+	}{
+		{
+			name:       "nothing to migrate",
+			result:     &scan.ScanResult{IneligibleJobs: []*scan.IneligibleJob{outOfScope}},
+			conclusion: "success",
+		},
+		{
+			name:       "candidates",
+			result:     &scan.ScanResult{Candidates: []*scan.Candidate{safe, warning}, IneligibleJobs: []*scan.IneligibleJob{ineligible, outOfScope}},
+			conclusion: "neutral",
+			levels:     []string{api.AnnotationNotice, api.AnnotationWarning, api.AnnotationNotice},
+		},
+		{
+			name:       "ineligible jobs only",
+			result:     &scan.ScanResult{IneligibleJobs: []*scan.IneligibleJob{ineligible}},
+			conclusion: "success",
+			levels:     []string{api.AnnotationNotice},
+		},
+		{
+			name:       "label typo",
+			result:     &scan.ScanResult{Candidates: []*scan.Candidate{safe}, LabelTypos: []*scan.LabelTypo{typo}},
+			conclusion: "failure",
+			levels:     []string{api.AnnotationFailure, api.AnnotationNotice},
+		},
+		{
+			name:       "load error",
+			result:     &scan.ScanResult{LoadErrors: []scan.LoadError{loadError}},
+			conclusion: "failure",
+			levels:     []string{api.AnnotationFailure},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newCheckRun(tt.result)
+			if run.Name != checkRunName || run.Conclusion != tt.conclusion {
+				t.Errorf("newCheckRun() = %s (%s), want %s (%s)", run.Name, run.Conclusion, checkRunName, tt.conclusion)
+			}
+			if len(run.Annotations) != len(tt.levels) {
+				t.Fatalf("newCheckRun() annotations = %+v, want levels %v", run.Annotations, tt.levels)
+			}
+			for i, level := range tt.levels {
+				if run.Annotations[i].Level != level {
+					t.Errorf("annotation %d (%s) level = %s, want %s", i, run.Annotations[i].Title, run.Annotations[i].Level, level)
+				}
+			}
+		})
+	}
+}
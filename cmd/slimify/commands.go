@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+func newCommandsCmd() *cobra.Command {
+	commandsCmd := &cobra.Command{
+		Use:   "commands",
+		Short: "Query the commands available on ubuntu-latest and ubuntu-slim",
+		Long: `Query the command dataset missing commands are detected with (see 'gh slimify
+db'), e.g., to check a tool before writing a workflow for ubuntu-slim.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the commands available on ubuntu-latest but missing on ubuntu-slim",
+		Long: `List the commands available on ubuntu-latest but missing on ubuntu-slim,
+with their version on ubuntu-latest when the dataset has it, and how to install
+them on ubuntu-slim when it is known (a setup action or an apt package).`,
+		Args: cobra.NoArgs,
+		Run:  runCommandsList,
+	}
+	listCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+
+	checkCmd := &cobra.Command{
+		Use:   "check <command>...",
+		Short: "Check whether commands are available on ubuntu-latest and ubuntu-slim",
+		Long: `Check whether commands are available on ubuntu-latest and ubuntu-slim, with
+their version when the dataset has it, and how to install the commands missing
+on ubuntu-slim.
+
+The command exits with status 1 if any command is not available on ubuntu-slim.`,
+		Example: `  gh slimify commands check jq lsof`,
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runCommandsCheck,
+	}
+	checkCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+
+	commandsCmd.AddCommand(listCmd, checkCmd)
+	return commandsCmd
+}
+
+func runCommandsList(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	missing := workflow.MissingCommands()
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, missing); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	tp := newTablePrinter()
+	tp.AddHeader([]string{"COMMAND", "UBUNTU-LATEST VERSION", "INSTALL ON UBUNTU-SLIM"})
+	for _, c := range missing {
+		tp.AddField(c.Name)
+		tp.AddField(c.LatestVersion)
+		tp.AddField(c.Installer)
+		tp.EndRow()
+	}
+	if err := tp.Render(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runCommandsCheck(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	infos := make([]workflow.CommandInfo, len(args))
+	unavailable := false
+	for i, name := range args {
+		infos[i] = workflow.LookupCommand(name)
+		unavailable = unavailable || !infos[i].Slim
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, infos); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, c := range infos {
+			latest := withVersion("ubuntu-latest", c.LatestVersion)
+			slim := withVersion("ubuntu-slim", c.SlimVersion)
+			switch {
+			case c.Slim && c.Latest:
+				printf("✅ %s: available on %s and %s\n", c.Name, latest, slim)
+			case c.Slim:
+				printf("✅ %s: available on %s (not on ubuntu-latest)\n", c.Name, slim)
+			case c.Latest:
+				printf("⚠️  %s: available on %s, but missing on ubuntu-slim\n", c.Name, latest)
+			default:
+				printf("❌ %s: not available on ubuntu-latest or ubuntu-slim\n", c.Name)
+			}
+			if c.Installer != "" {
+				printf("   💡 Install it with %s (or gh slimify fix --add-setup)\n", c.Installer)
+			}
+		}
+	}
+	if unavailable {
+		os.Exit(1)
+	}
+}
+
+// withVersion appends the version of a command to the image it is on, if known
+func withVersion(image, version string) string {
+	if version == "" {
+		return image
+	}
+	return fmt.Sprintf("%s (%s)", image, version)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// commentMarker identifies the comment of gh-slimify among the comments of a
+// pull request, so that it is updated in place
+const commentMarker = "<!-- gh-slimify -->"
+
+var (
+	commentPR     int
+	commentDryRun bool
+)
+
+func newCommentCmd() *cobra.Command {
+	commentCmd := &cobra.Command{
+		Use:   "comment --pr <number>",
+		Short: "Comment on a pull request with the jobs of its workflows that can use ubuntu-slim",
+		Long: `Scan the workflows added or modified by a pull request and post a summary
+comment on it: the new jobs that should have used ubuntu-slim, and the other
+jobs of the changed workflows that can be migrated. The comment is updated in
+place on later runs, so the pull request keeps a single, current summary.
+
+Run it from a checkout of the pull request's head. The changed workflows are
+listed with the GitHub API, and a job is new if its ID is not in the workflow
+at the base of the pull request. Use --dry-run to print the comment instead.`,
+		Example: `  gh slimify comment --pr 123
+  gh slimify comment --pr 123 --dry-run`,
+		Args: cobra.NoArgs,
+		Run:  runComment,
+	}
+	commentCmd.Flags().IntVar(&commentPR, "pr", 0, "Number of the pull request to comment on")
+	commentCmd.Flags().BoolVar(&commentDryRun, "dry-run", false, "Print the comment instead of posting it")
+	commentCmd.MarkFlagRequired("pr")
+	return commentCmd
+}
+
+func runComment(cmd *cobra.Command, args []string) {
+	if commentPR <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --pr must be a pull request number\n")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get repository info: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := context.Background()
+
+	pr, err := client.GetPullRequest(ctx, commentPR)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	files, err := client.GetPullRequestFiles(ctx, commentPR)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var workflows []string
+	for _, f := range files {
+		if strings.HasPrefix(f, workflow.Dir()+"/") && (strings.HasSuffix(f, ".yml") || strings.HasSuffix(f, ".yaml")) {
+			workflows = append(workflows, f)
+		}
+	}
+	sort.Strings(workflows)
+
+	result := &scan.ScanResult{}
+	if len(workflows) > 0 {
+		result, err = scan.ScanWithOptions(opts, workflows...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printLoadErrors(result)
+	}
+
+	// A job is new if the workflow at the base of the pull request does not
+	// have it (or does not exist)
+	baseJobs := make(map[string]map[string]bool)
+	for _, c := range result.Candidates {
+		if _, ok := baseJobs[c.WorkflowPath]; ok {
+			continue
+		}
+		data, err := client.GetFileContent(ctx, c.WorkflowPath, pr.Base.SHA)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		baseJobs[c.WorkflowPath] = workflowJobIDs(data)
+	}
+	var newJobs, existingJobs []*scan.Candidate
+	for _, c := range result.Candidates {
+		if baseJobs[c.WorkflowPath][c.JobID] {
+			existingJobs = append(existingJobs, c)
+		} else {
+			newJobs = append(newJobs, c)
+		}
+	}
+
+	body := commentBody(workflows, newJobs, existingJobs)
+	if commentDryRun {
+		fmt.Print(body)
+		return
+	}
+	url, created, err := client.UpsertIssueComment(ctx, commentPR, commentMarker, body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if created {
+		fmt.Printf("Commented on #%d: %s\n", commentPR, url)
+	} else {
+		fmt.Printf("Updated the comment on #%d: %s\n", commentPR, url)
+	}
+}
+
+// workflowJobIDs returns the job IDs of a workflow, or none if it cannot be
+// parsed
+func workflowJobIDs(data []byte) map[string]bool {
+	var wf struct {
+		Jobs map[string]any `yaml:"jobs"`
+	}
+	ids := make(map[string]bool)
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return ids
+	}
+	for id := range wf.Jobs {
+		ids[id] = true
+	}
+	return ids
+}
+
+// commentBody renders the pull request comment in Markdown
+func commentBody(workflows []string, newJobs, existingJobs []*scan.Candidate) string {
+	var b strings.Builder
+	b.WriteString(commentMarker + "\n")
+	b.WriteString("### 🪶 gh-slimify\n\n")
+
+	switch {
+	case len(workflows) == 0:
+		b.WriteString("This pull request does not change any workflow.\n")
+		return b.String()
+	case len(newJobs) == 0 && len(existingJobs) == 0:
+		fmt.Fprintf(&b, "No job of the %d changed workflow(s) can move to `ubuntu-slim`.\n", len(workflows))
+		return b.String()
+	}
+
+	if len(newJobs) > 0 {
+		fmt.Fprintf(&b, "**%d new job(s) could use `ubuntu-slim` instead of `ubuntu-latest`:**\n\n", len(newJobs))
+		writeCommentTable(&b, newJobs)
+		b.WriteString("\n")
+	}
+	if len(existingJobs) > 0 {
+		fmt.Fprintf(&b, "**%d other job(s) of the changed workflows can be migrated to `ubuntu-slim`:**\n\n", len(existingJobs))
+		writeCommentTable(&b, existingJobs)
+		b.WriteString("\n")
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, c := range append(append([]*scan.Candidate{}, newJobs...), existingJobs...) {
+		if !seen[c.WorkflowPath] {
+			seen[c.WorkflowPath] = true
+			paths = append(paths, c.WorkflowPath)
+		}
+	}
+	sort.Strings(paths)
+	fmt.Fprintf(&b, "Run `gh slimify fix %s` to migrate them. Jobs marked ⚠️ need a review first (see `gh slimify why`).\n", strings.Join(paths, " "))
+	return b.String()
+}
+
+// writeCommentTable writes candidates as a Markdown table
+func writeCommentTable(b *strings.Builder, candidates []*scan.Candidate) {
+	sort.Slice(candidates, func(i, k int) bool {
+		if candidates[i].WorkflowPath != candidates[k].WorkflowPath {
+			return candidates[i].WorkflowPath < candidates[k].WorkflowPath
+		}
+		return candidates[i].LineNumber < candidates[k].LineNumber
+	})
+	b.WriteString("| | Job | Workflow | Last duration |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, c := range candidates {
+		status := "✅"
+		if c.HasWarnings() {
+			status = "⚠️"
+		}
+		duration := c.Duration
+		if duration == "" {
+			duration = "unknown"
+		}
+		fmt.Fprintf(b, "| %s | `%s` | `%s:%d` | %s |\n", status, c.JobName, c.WorkflowPath, c.LineNumber, duration)
+	}
+}
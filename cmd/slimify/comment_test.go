@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+func TestCommentBody(t *testing.T) {
+	safe := &scan.Candidate{WorkflowPath: ".github/workflows/ci.yml", JobID: "lint", JobName: "Lint", LineNumber: 4, Duration: "1m5s"}
+	warning := &scan.Candidate{WorkflowPath: ".github/workflows/ci.yml", JobID: "test", JobName: "Test", LineNumber: 9, MissingCommands: []string{"psql"}}
+	release := &scan.Candidate{WorkflowPath: ".github/workflows/release.yml", JobID: "notes", JobName: "Notes", LineNumber: 3, Duration: "30s"}
+
+	tests := []struct {
+		name         string
+		workflows    []string
+		newJobs      []*scan.Candidate
+		existingJobs []*scan.Candidate
+		want         []string
+		notWant      []string
+	}{
+		{
+			name: "no changed workflow",
+			want: []string{"This pull request does not change any workflow."},
+		},
+		{
+			name:      "no candidate",
+			workflows: []string{".github/workflows/ci.yml"},
+			want:      []string{"No job of the 1 changed workflow(s) can move to `ubuntu-slim`."},
+			notWant:   []string{"| Job |", "gh slimify fix"},
+		},
+		{
+			name:         "new and existing jobs",
+			workflows:    []string{".github/workflows/ci.yml", ".github/workflows/release.yml"},
+			newJobs:      []*scan.Candidate{warning, safe},
+			existingJobs: []*scan.Candidate{release},
+			want: []string{
+				"**2 new job(s) could use `ubuntu-slim` instead of `ubuntu-latest`:**",
+				"| ✅ | `Lint` | `.github/workflows/ci.yml:4` | 1m5s |\n| ⚠️ | `Test` | `.github/workflows/ci.yml:9` | unknown |",
+				"**1 other job(s) of the changed workflows can be migrated to `ubuntu-slim`:**",
+				"| ✅ | `Notes` | `.github/workflows/release.yml:3` | 30s |",
+				"Run `gh slimify fix .github/workflows/ci.yml .github/workflows/release.yml` to migrate them.",
+			},
+		},
+		{
+			name:         "existing jobs only",
+			workflows:    []string{".github/workflows/release.yml"},
+			existingJobs: []*scan.Candidate{release},
+			want:         []string{"**1 other job(s)", "Run `gh slimify fix .github/workflows/release.yml`"},
+			notWant:      []string{"new job(s)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := commentBody(tt.workflows, tt.newJobs, tt.existingJobs)
+			// The marker finds the comment to update on the next run
+			if !strings.HasPrefix(got, commentMarker+"\n") {
+				t.Errorf("commentBody() does not start with the marker:\n%s", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commentBody() does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("commentBody() contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
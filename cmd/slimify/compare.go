@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var compareTargets []string
+
+func newCompareCmd() *cobra.Command {
+	compareCmd := &cobra.Command{
+		Use:   "compare <before.json> <after.json> | compare [flags] [workflow-file...]",
+		Short: "Compare two saved scan results, or target runners",
+		Long: `Compare two scan results saved with 'gh slimify --output json' and report
+jobs that became eligible for ubuntu-slim, regressions (jobs that can no longer
+be migrated, or safe jobs that now require attention), resolved warnings, and
+ineligibility reasons resolved in jobs that still cannot be migrated.
+Jobs are matched by workflow path and job ID.
+
+Given workflow files instead (or nothing, for all workflows), evaluate each
+job on ubuntu-latest against several target runners: ubuntu-slim (slim), an
+ARM runner (arm, evaluated like --arm), and a 4-core larger runner (larger).
+A matrix shows whether each job is safe to move, requires attention, or is
+ineligible on each target, followed by the number of eligible jobs and the
+change of the cost of one run of each of them, assuming jobs take as long on
+every runner. Prices can be overridden in .slimify.yml (pricing:).`,
+		Example: `  gh slimify compare before.json after.json
+  gh slimify compare
+  gh slimify compare --targets slim,arm .github/workflows/ci.yml`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runCompare,
+	}
+	compareCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	compareCmd.Flags().StringSliceVar(&compareTargets, "targets", []string{"slim", "arm", "larger"}, "Target runners to compare workflows against: slim, arm, larger")
+	compareCmd.RegisterFlagCompletionFunc("targets", completeTargets)
+	registerWorkflowCompletion(compareCmd)
+	return compareCmd
+}
+
+// isResultFiles reports whether the arguments of compare are two saved scan
+// results rather than workflow files
+func isResultFiles(args []string) bool {
+	return len(args) == 2 && strings.HasSuffix(args[0], ".json") && strings.HasSuffix(args[1], ".json")
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !isResultFiles(args) {
+		runCompareRunners(args)
+		return
+	}
+
+	before, err := scan.LoadResult(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	after, err := scan.LoadResult(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	comparison := scan.Compare(before, after)
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, comparison); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printf("Comparing %s → %s\n", args[0], args[1])
+	if comparison.IsEmpty() {
+		printLine("\nNo changes in migration eligibility.")
+		return
+	}
+	printChanges("✨ Newly eligible", comparison.NewlyEligible)
+	printChanges("❌ Regressions", comparison.Regressions)
+	printChanges("✅ Resolved warnings", comparison.ResolvedWarnings)
+	printChanges("✅ Resolved blockers", comparison.ResolvedBlockers)
+}
+
+// printChanges prints a section of the comparison
+func printChanges(title string, changes []*scan.JobChange) {
+	if len(changes) == 0 {
+		return
+	}
+	printf("\n%s (%d job(s)):\n", title, len(changes))
+	for _, c := range changes {
+		before := c.Before
+		if before == "" {
+			before = "new"
+		}
+		printf("   • \"%s\" (L%d) - %s → %s\n", c.JobName, c.LineNumber, before, c.After)
+		if len(c.Reasons) > 0 {
+			printf("     %s\n", strings.Join(c.Reasons, ", "))
+		}
+		printf("     %s\n", formatLocalLink(c.WorkflowPath, c.LineNumber))
+	}
+}
+
+// runCompareRunners evaluates the jobs of the given workflows against the
+// target runners selected with --targets
+func runCompareRunners(args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	targets, err := scan.LookupTargets(opts.Pricing, compareTargets...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --targets: %v\n", err)
+		os.Exit(1)
+	}
+
+	comparison, err := scan.CompareRunners(opts, targets, workflowArgs(args)...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, comparison); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, e := range comparison.LoadErrors {
+		eprintf("⚠️  Failed to load %s: %s\n", e.Path, e.Error)
+	}
+	if len(comparison.Jobs) == 0 {
+		printf("No jobs on %s to compare.\n", comparison.Source)
+		return
+	}
+	printf("Comparing %d job(s) on %s ($%.3f/min) with %d target runner(s):\n\n", len(comparison.Jobs), comparison.Source, comparison.SourcePrice, len(comparison.Targets))
+	if err := writeRunnerMatrix(comparison); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printLine()
+	for _, t := range comparison.Targets {
+		printf("🏁 %s (%s, $%.3f/min): %d eligible job(s), %d safe and %d requiring attention\n", t.Name, t.Label, t.PricePerMinute, t.Eligible, t.Safe, t.Warning)
+		if t.Priced > 0 {
+			printf("   💰 %s per run of the %d job(s) with a known duration\n", formatCostChange(t.CostChange), t.Priced)
+		}
+	}
+	printLine("\nCosts assume jobs take as long on every runner. Run 'gh slimify explain <workflow-file> --all-jobs' (with --arm for arm) for the reasons.")
+}
+
+// writeRunnerMatrix writes one row per job with its status on each target
+func writeRunnerMatrix(comparison *scan.RunnerComparison) error {
+	tp := newTablePrinter()
+	header := []string{"WORKFLOW", "LINE", "JOB", "DURATION"}
+	for _, t := range comparison.Targets {
+		header = append(header, strings.ToUpper(t.Name))
+	}
+	tp.AddHeader(header)
+	for _, job := range comparison.Jobs {
+		tp.AddField(job.WorkflowPath)
+		tp.AddField(strconv.Itoa(job.LineNumber))
+		tp.AddField(job.JobName)
+		duration := job.Duration
+		if duration == "" {
+			duration = "-"
+		}
+		tp.AddField(duration)
+		for _, r := range job.Results {
+			if color := statusColor(r.Status); color != "" && colorOutput {
+				tp.AddField(r.Status, tableprinter.WithColor(func(s string) string { return color + s + colorReset }))
+			} else {
+				tp.AddField(r.Status)
+			}
+		}
+		tp.EndRow()
+	}
+	return tp.Render()
+}
+
+// formatCostChange describes the change of the cost of a run in USD
+func formatCostChange(change float64) string {
+	switch {
+	case change < 0:
+		return fmt.Sprintf("~$%.3f less", -change)
+	case change > 0:
+		return fmt.Sprintf("~$%.3f more", change)
+	}
+	return "no cost change"
+}
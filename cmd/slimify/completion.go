@@ -0,0 +1,140 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// Shell completion of workflow files, job IDs, and rules. The completion
+// command itself is added by cobra (gh slimify completion bash|zsh|fish|powershell).
+
+// workflowPathsForCompletion returns the files in the workflows directory
+func workflowPathsForCompletion() []string {
+	var paths []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(workflow.Dir(), pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// completeWorkflowFiles completes workflow file arguments and --file. Other
+// files are completed by the shell when there are no workflows.
+func completeWorkflowFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	paths := workflowPathsForCompletion()
+	if len(paths) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp
+}
+
+// jobCompletions returns the job IDs of a workflow as "<prefix><job-id>",
+// described by their display names
+func jobCompletions(path, prefix string) []string {
+	wf, err := workflow.LoadWorkflow(path)
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, id := range wf.JobIDs() {
+		completions = append(completions, prefix+id+"\t"+wf.Jobs[id].Name)
+	}
+	return completions
+}
+
+// completeJobIDs completes --job with the job IDs of the workflows given as
+// arguments or with --file, or of every workflow if none is given
+func completeJobIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	paths, _ := workflow.ExpandPaths(append(append([]string{}, args...), workflowFiles...))
+	if len(paths) == 0 {
+		paths = workflowPathsForCompletion()
+	}
+	seen := make(map[string]bool)
+	var completions []string
+	for _, path := range paths {
+		for _, c := range jobCompletions(path, "") {
+			id, _, _ := strings.Cut(c, "\t")
+			if !seen[id] {
+				seen[id] = true
+				completions = append(completions, c)
+			}
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkflowJob completes <workflow-file>:<job-id>: workflow files
+// followed by a colon, then the jobs of the chosen workflow
+func completeWorkflowJob(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if i := strings.LastIndex(toComplete, ":"); i >= 0 {
+		path := toComplete[:i]
+		return jobCompletions(path, path+":"), cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, path := range workflowPathsForCompletion() {
+		completions = append(completions, path+":")
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeDurationsArgs completes the workflow file, then one of its job IDs
+func completeDurationsArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeWorkflowFiles(cmd, args, toComplete)
+	case 1:
+		return jobCompletions(args[0], ""), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRules completes rule IDs, described by their names
+func completeRules(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, r := range scan.Rules() {
+		completions = append(completions, r.ID+"\t"+r.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeExplainArgs completes rule IDs, or <workflow-file>:<job-id> once
+// a path is being typed, or workflow files with --all-jobs
+func completeExplainArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if explainAllJobs {
+		return completeWorkflowFiles(cmd, args, toComplete)
+	}
+	if strings.ContainsAny(toComplete, "./:") {
+		return completeWorkflowJob(cmd, args, toComplete)
+	}
+	return completeRules(cmd, args, toComplete)
+}
+
+// registerWorkflowCompletion completes the workflow file arguments of cmd and,
+// if cmd has one, its --job flag
+func registerWorkflowCompletion(cmd *cobra.Command) {
+	cmd.ValidArgsFunction = completeWorkflowFiles
+	if cmd.Flags().Lookup("job") != nil {
+		cmd.RegisterFlagCompletionFunc("job", completeJobIDs)
+	}
+}
+
+// completeTargets completes the target runners of compare --targets
+func completeTargets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, t := range scan.Targets(scan.DefaultPricing) {
+		completions = append(completions, t.Name+"\t"+t.Label)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// runnerImagesOwner and runnerImagesRepo are the repository of the runner
+// images whose releases the command dataset is updated from
+const (
+	runnerImagesOwner = "actions"
+	runnerImagesRepo  = "runner-images"
+)
+
+var (
+	commandsFile string
+
+	dbOutput          string
+	dbReleasePrefixes map[string]string
+)
+
+func newDBCmd() *cobra.Command {
+	dbCmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the dataset of commands available on the runner images",
+		Long: `Missing commands are detected with a dataset of the commands available on
+ubuntu-latest and ubuntu-slim. A dataset is built into gh slimify; "db update"
+refreshes it from the latest actions/runner-images releases, and --commands-file
+uses another dataset for a run.`,
+	}
+
+	infoCmd := &cobra.Command{
+		Use:   "info",
+		Short: "Show the command dataset in use",
+		Args:  cobra.NoArgs,
+		Run:   runDBInfo,
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the command dataset from actions/runner-images releases",
+		Long: `Update the command dataset from the software reports of the latest
+actions/runner-images release of each image, and write it to the state
+directory, where the following runs pick it up (--commands-file overrides it).
+
+The commands of the tools listed in the reports (e.g., kubectl, terraform) are
+recorded for each image, on top of the commands found on the runners when the
+dataset was generated. Releases are selected by tag prefix (--release-prefix);
+an image without a release keeps its tools.
+
+Use -o internal/workflow/commands.json in a clone of gh-slimify to update the
+built-in dataset.`,
+		Args: cobra.NoArgs,
+		Run:  runDBUpdate,
+	}
+	updateCmd.Flags().StringVarP(&dbOutput, "output", "o", "", "Path of the dataset to write (default: "+workflow.CommandsFileName+" in the state directory)")
+	updateCmd.Flags().StringToStringVar(&dbReleasePrefixes, "release-prefix", map[string]string{
+		workflow.ImageLatest: "ubuntu24/",
+		workflow.ImageSlim:   "ubuntu-slim/",
+	}, "Tag prefix of the runner-images releases of each image")
+
+	dbCmd.AddCommand(infoCmd, updateCmd)
+	return dbCmd
+}
+
+// loadCommands replaces the built-in command dataset with --commands-file,
+// or with the dataset written by "db update" in the state directory
+func loadCommands() {
+	if commandsFile != "" {
+		if err := workflow.LoadCommandsFile(commandsFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	path, err := updatedCommandsPath()
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	if err := workflow.LoadCommandsFile(path); err != nil {
+		slog.Warn("using the built-in command dataset", "error", err)
+	}
+}
+
+// updatedCommandsPath returns the path of the dataset written by "db update"
+func updatedCommandsPath() (string, error) {
+	dir, err := cache.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, workflow.CommandsFileName), nil
+}
+
+func runDBInfo(cmd *cobra.Command, args []string) {
+	source := "built-in"
+	if commandsFile != "" {
+		source = commandsFile
+	} else if path, err := updatedCommandsPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			source = path
+		}
+	}
+
+	db := workflow.Commands()
+	printf("Dataset: %s (version %s)\n", source, db.Version)
+	for _, image := range []string{workflow.ImageLatest, workflow.ImageSlim} {
+		ic := db.Images[image]
+		release := ""
+		if ic.Release != "" {
+			release = fmt.Sprintf(", %d tools from %s", len(ic.Tools), ic.Release)
+		}
+		printf("  %s: %d commands%s\n", image, len(ic.Commands), release)
+	}
+}
+
+func runDBUpdate(cmd *cobra.Command, args []string) {
+	output := dbOutput
+	if output == "" {
+		path, err := updatedCommandsPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		output = path
+	}
+	if err := readonly.Check("write " + output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	client, err := api.NewClient("github.com", runnerImagesOwner, runnerImagesRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := context.Background()
+	releases, err := client.ListReleases(ctx, runnerImagesOwner, runnerImagesRepo, 100)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	db := workflow.Commands()
+	images := make([]string, 0, len(dbReleasePrefixes))
+	for image := range dbReleasePrefixes {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	updated := 0
+	for _, image := range images {
+		ic, ok := db.Images[image]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown image %q in --release-prefix (use %s or %s)\n", image, workflow.ImageLatest, workflow.ImageSlim)
+			os.Exit(1)
+		}
+		release, report := latestSoftwareReport(releases, dbReleasePrefixes[image])
+		if report == nil {
+			eprintf("⚠️  %s: no release with a software report found (tag prefix %q); keeping its tools\n", image, dbReleasePrefixes[image])
+			continue
+		}
+		data, err := client.DownloadReleaseAsset(ctx, report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		tools, err := workflow.SoftwareReportTools(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s of %s: %v\n", report.Name, release.TagName, err)
+			os.Exit(1)
+		}
+		added, removed := diffCommands(ic.Tools, tools)
+		ic.Release, ic.Tools = release.TagName, tools
+		printf("  %s: %d tools from %s (+%d, -%d)\n", image, len(tools), release.TagName, len(added), len(removed))
+		updated++
+	}
+	if updated == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no image could be updated; the dataset was not written\n")
+		os.Exit(1)
+	}
+
+	db.Version = time.Now().UTC().Format(time.DateOnly)
+	data, err := db.Marshal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Written atomically: an interrupted update leaves the previous dataset
+	if err := cache.WriteFile(output, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printf("\nWrote the command dataset (version %s) to %s\n", db.Version, output)
+}
+
+// latestSoftwareReport returns the newest published release whose tag starts
+// with prefix and has a software report (a JSON asset), with the report
+func latestSoftwareReport(releases []*api.Release, prefix string) (*api.Release, *api.ReleaseAsset) {
+	for _, r := range releases {
+		if r.Draft || r.Prerelease || !strings.HasPrefix(r.TagName, prefix) {
+			continue
+		}
+		for _, a := range r.Assets {
+			if strings.HasSuffix(a.Name, ".json") {
+				return r, a
+			}
+		}
+	}
+	return nil, nil
+}
+
+// diffCommands returns the commands of after that are not in before, and
+// those of before that are not in after
+func diffCommands(before, after map[string]string) (added, removed []string) {
+	for c := range after {
+		if _, ok := before[c]; !ok {
+			added = append(added, c)
+		}
+	}
+	for c := range before {
+		if _, ok := after[c]; !ok {
+			removed = append(removed, c)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+// Fix actions recorded in the decision log
+const (
+	actionUpdated    = "updated"
+	actionSkipped    = "skipped"
+	actionFailed     = "failed"
+	actionRolledBack = "rolled_back"
+)
+
+// decisionRecord is a line of the decision log
+type decisionRecord struct {
+	Time    string `json:"time"`
+	Command string `json:"command"`
+	*scan.Decision
+}
+
+// decisionKey identifies a job in the fix actions map
+func decisionKey(workflowPath, jobID string) string {
+	return workflowPath + "\x00" + jobID
+}
+
+// writeDecisionLog writes one JSON record per job evaluated by the scan to path,
+// replacing its contents. actions maps decisionKey to what fix did with the job;
+// it is nil for scans.
+func writeDecisionLog(path, command string, result *scan.ScanResult, actions map[string]string) error {
+	if err := readonly.Check("write " + path); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create decision log: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, d := range result.Decisions() {
+		d.Action = actions[decisionKey(d.WorkflowPath, d.JobID)]
+		if err := enc.Encode(decisionRecord{Time: now, Command: command, Decision: d}); err != nil {
+			return fmt.Errorf("failed to write decision log: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write decision log: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var diffBaseline string
+
+func newDiffCmd() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff <old.json> <new.json> | diff --baseline <old.json> [flags] [workflow-file...]",
+		Short: "Report the migration progress between two scans",
+		Long: `Diff two scan results saved with 'gh slimify --output json' to track the
+migration over time: the jobs that became eligible for ubuntu-slim, the jobs
+that can no longer be migrated, the safe jobs that now require attention, and
+the resolved findings (warnings of jobs that can still be migrated, and
+ineligibility reasons of jobs that still cannot). Jobs are matched by workflow
+path and job ID.
+
+With --baseline, the workflows are scanned (all of them without arguments)
+and diffed against the saved baseline, e.g., a scan result committed to the
+repository or kept as a CI artifact.`,
+		Example: `  gh slimify diff last-month.json today.json
+  gh slimify diff --baseline .github/slimify-baseline.json --skip-duration`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runDiff,
+	}
+	diffCmd.Flags().StringVar(&diffBaseline, "baseline", "", "Scan result saved with --output json to diff a scan of the workflows against")
+	diffCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	registerWorkflowCompletion(diffCmd)
+	return diffCmd
+}
+
+// scanDiff is the progress of the migration between two scans, written by
+// diff -o json
+type scanDiff struct {
+	EligibleBefore int `json:"eligible_before"`
+	EligibleAfter  int `json:"eligible_after"`
+	// NewlyEligible lists jobs that can be migrated now but could not be, or
+	// did not exist, before
+	NewlyEligible []*scan.JobChange `json:"newly_eligible"`
+	// NewlyIneligible lists jobs that could be migrated before but cannot be now
+	NewlyIneligible []*scan.JobChange `json:"newly_ineligible"`
+	// NewWarnings lists safe jobs that now require attention
+	NewWarnings []*scan.JobChange `json:"new_warnings"`
+	// ResolvedFindings lists jobs that lost warnings or ineligibility reasons
+	// without changing status
+	ResolvedFindings []*scan.JobChange `json:"resolved_findings"`
+}
+
+// newScanDiff diffs two scan results with scan.Compare
+func newScanDiff(before, after *scan.ScanResult) *scanDiff {
+	comparison := scan.Compare(before, after)
+	d := &scanDiff{
+		EligibleBefore:   len(before.Candidates),
+		EligibleAfter:    len(after.Candidates),
+		NewlyEligible:    comparison.NewlyEligible,
+		NewlyIneligible:  []*scan.JobChange{},
+		NewWarnings:      []*scan.JobChange{},
+		ResolvedFindings: append(append([]*scan.JobChange{}, comparison.ResolvedWarnings...), comparison.ResolvedBlockers...),
+	}
+	for _, c := range comparison.Regressions {
+		if c.After == scan.StatusIneligible || c.After == scan.StatusMigrated {
+			d.NewlyIneligible = append(d.NewlyIneligible, c)
+		} else {
+			d.NewWarnings = append(d.NewWarnings, c)
+		}
+	}
+	return d
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var before, after *scan.ScanResult
+	var beforeName, afterName string
+	var err error
+	if diffBaseline != "" {
+		before, err = scan.LoadResult(diffBaseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts, err := scanOptions(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		after, err = scan.ScanWithOptions(opts, workflowArgs(args)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		beforeName, afterName = diffBaseline, "current workflows"
+	} else {
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "Error: diff requires two scan results, or --baseline <old.json>\n")
+			os.Exit(1)
+		}
+		if before, err = scan.LoadResult(args[0]); err == nil {
+			after, err = scan.LoadResult(args[1])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		beforeName, afterName = args[0], args[1]
+	}
+
+	d := newScanDiff(before, after)
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, d); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printf("Comparing %s → %s\n", beforeName, afterName)
+	printf("📊 Eligible jobs: %d → %d (%+d)\n", d.EligibleBefore, d.EligibleAfter, d.EligibleAfter-d.EligibleBefore)
+	if len(d.NewlyEligible)+len(d.NewlyIneligible)+len(d.NewWarnings)+len(d.ResolvedFindings) == 0 {
+		printLine("\nNo changes in migration eligibility.")
+		return
+	}
+	printChanges("✨ Newly eligible", d.NewlyEligible)
+	printChanges("❌ Newly ineligible", d.NewlyIneligible)
+	printChanges("⚠️  New warnings", d.NewWarnings)
+	printChanges("✅ Resolved findings", d.ResolvedFindings)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manDir string
+
+func newDocsCmd() *cobra.Command {
+	docsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate documentation from the command tree",
+	}
+	manCmd := &cobra.Command{
+		Use:   "man",
+		Short: "Generate man pages, one per command",
+		Long: `Generate a man page (section 1) for every command, generated from the same
+command tree as --help (e.g., slimify.1, slimify-fix.1).
+
+Set SOURCE_DATE_EPOCH to make the date in the pages reproducible.`,
+		Example: `  gh slimify docs man --dir ./man
+  man ./man/slimify-fix.1`,
+		Args: cobra.NoArgs,
+		Run:  runDocsMan,
+	}
+	manCmd.Flags().StringVar(&manDir, "dir", "man", "Directory to write the man pages to")
+	docsCmd.AddCommand(manCmd)
+	return docsCmd
+}
+
+func runDocsMan(cmd *cobra.Command, args []string) {
+	if err := os.MkdirAll(manDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	header := &doc.GenManHeader{
+		Title:   "SLIMIFY",
+		Section: "1",
+		Source:  "gh-slimify " + toolVersion(),
+		Manual:  "GitHub CLI extension manual",
+	}
+	root := cmd.Root()
+	root.DisableAutoGenTag = true
+	if err := doc.GenManTree(root, header, manDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to generate man pages: %v\n", err)
+		os.Exit(1)
+	}
+	printf("Wrote man pages to %s\n", manDir)
+}
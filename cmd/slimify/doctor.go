@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// Statuses of doctor checks
+const (
+	checkOK   = "ok"
+	checkWarn = "warning"
+	checkFail = "failed"
+	checkSkip = "skipped" // A check it depends on failed
+)
+
+// doctorCheck is the outcome of a doctor check, written by doctor -o json
+type doctorCheck struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+func newDoctorCmd() *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that gh slimify can work in this repository",
+		Long: `Check the environment gh slimify depends on, and print how to fix each
+problem found:
+
+  • Repository: the GitHub repository is detected (origin remote, --repo, or GH_REPO)
+  • Authentication: a token is found (gh auth login, GH_TOKEN, or GITHUB_TOKEN)
+  • GitHub API: the API is reachable and accepts the token
+  • Repository access: the token can read the repository
+  • Workflows directory: .github/workflows (or --workflows-dir) has workflows
+  • Target runner: the repository can run jobs on ubuntu-slim (or --to)
+  • Write access: workflows can be updated locally, and the token can push
+    branches for fix --pr
+
+Checks depending on a failed check are skipped. The command exits with status
+1 if any check failed.`,
+		Args: cobra.NoArgs,
+		Run:  runDoctor,
+	}
+	doctorCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	return doctorCmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	checks := runDoctorChecks(context.Background())
+	failed := false
+	for _, c := range checks {
+		failed = failed || c.Status == checkFail
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, checks); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		markers := map[string]string{checkOK: "✅", checkWarn: "⚠️ ", checkFail: "❌", checkSkip: "⏭️ "}
+		for _, c := range checks {
+			printf("%s %s: %s\n", markers[c.Status], c.Name, c.Detail)
+			if c.Remediation != "" {
+				printf("   💡 %s\n", c.Remediation)
+			}
+		}
+		if failed {
+			printLine("\nSome checks failed. gh slimify may not work until they are fixed.")
+		} else {
+			printLine("\nEverything looks good.")
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runDoctorChecks runs the checks of doctor in order
+func runDoctorChecks(ctx context.Context) []doctorCheck {
+	var checks []doctorCheck
+	add := func(name, status, detail, remediation string) {
+		checks = append(checks, doctorCheck{Name: name, Status: status, Detail: detail, Remediation: remediation})
+	}
+
+	host, owner, repo, repoErr := api.GetRepoInfo()
+	if repoErr != nil {
+		add("Repository", checkFail, repoErr.Error(), "Run gh slimify in a clone of a GitHub repository, or give the repository with --repo OWNER/REPO or GH_REPO.")
+		host = "github.com"
+	} else {
+		add("Repository", checkOK, fmt.Sprintf("%s/%s on %s", owner, repo, host), "")
+	}
+
+	var client *api.Client
+	if source, err := api.TokenSource(host); err != nil {
+		add("Authentication", checkFail, err.Error(), fmt.Sprintf("Run 'gh auth login --hostname %s', or set GH_TOKEN.", host))
+	} else if client, err = api.NewClient(host, owner, repo); err != nil {
+		add("Authentication", checkFail, err.Error(), fmt.Sprintf("Run 'gh auth login --hostname %s', or set GH_TOKEN.", host))
+	} else {
+		add("Authentication", checkOK, fmt.Sprintf("token for %s from %s", host, source), "")
+	}
+
+	apiReachable := false
+	if client == nil {
+		add("GitHub API", checkSkip, "no token", "")
+	} else if limit, err := client.GetRateLimit(ctx); err != nil {
+		add("GitHub API", checkFail, err.Error(), "Check your network connection and proxy settings, and that the token is valid ('gh auth status').")
+	} else if limit.Remaining == 0 {
+		apiReachable = true
+		add("GitHub API", checkWarn, fmt.Sprintf("reachable, but the rate limit is exhausted (0/%d requests left)", limit.Limit), "Wait for the rate limit to reset, or use --skip-duration to scan without the API.")
+	} else {
+		apiReachable = true
+		add("GitHub API", checkOK, fmt.Sprintf("reachable (%d/%d requests left)", limit.Remaining, limit.Limit), "")
+	}
+
+	var repository *api.Repository
+	switch {
+	case repoErr != nil || !apiReachable:
+		add("Repository access", checkSkip, "no repository or API access", "")
+	default:
+		var err error
+		if repository, err = client.GetRepository(ctx); err != nil {
+			add("Repository access", checkFail, err.Error(), fmt.Sprintf("Check that %s/%s exists and that the token can read it (for fine-grained tokens, grant access to the repository).", owner, repo))
+		} else {
+			add("Repository access", checkOK, fmt.Sprintf("%s repository readable", repository.Visibility), "")
+		}
+	}
+
+	dir := workflow.Dir()
+	dirExists := false
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		add("Workflows directory", checkFail, fmt.Sprintf("%s does not exist", dir), "Run gh slimify from the root of the repository, or set the directory with --workflows-dir or workflows_dir in .slimify.yml.")
+	} else if workflows, err := workflow.LoadWorkflows(); err != nil {
+		dirExists = true
+		add("Workflows directory", checkFail, err.Error(), "Fix the workflow files that cannot be parsed.")
+	} else if len(workflows) == 0 {
+		dirExists = true
+		add("Workflows directory", checkWarn, fmt.Sprintf("no workflow files in %s", dir), "Add workflows (*.yml or *.yaml), or set the directory with --workflows-dir.")
+	} else {
+		dirExists = true
+		add("Workflows directory", checkOK, fmt.Sprintf("%d workflow(s) in %s", len(workflows), dir), "")
+	}
+
+	target := workflow.TargetLabel()
+	switch {
+	case repoErr != nil || !apiReachable:
+		add("Target runner", checkSkip, "no repository or API access", "")
+	default:
+		err := client.CheckRunnerLabel(ctx, target, workflow.IsHostedLabel(target))
+		switch {
+		case err == nil:
+			add("Target runner", checkOK, fmt.Sprintf("%s is available", target), "")
+		case errors.Is(err, api.ErrRunnerUnverified):
+			add("Target runner", checkWarn, err.Error(), "Ask a repository administrator to check the runners, or use a token with admin access.")
+		default:
+			add("Target runner", checkFail, err.Error(), fmt.Sprintf("Enable GitHub Actions for the repository, or choose an available runner with --to (fix refuses to migrate jobs to %s).", target))
+		}
+	}
+
+	switch err := checkWritable(dir); {
+	case !dirExists:
+		add("Write access", checkSkip, fmt.Sprintf("%s does not exist", dir), "")
+	case readonly.Enabled():
+		add("Write access", checkWarn, "read-only mode is enabled: fix and other commands that write are disabled", "Unset "+readonly.EnvVar+" and do not pass --no-write to update workflows.")
+	case err != nil:
+		add("Write access", checkFail, err.Error(), fmt.Sprintf("Make %s writable to update workflows with fix.", dir))
+	case repository != nil && !repository.Permissions.Push:
+		add("Write access", checkWarn, fmt.Sprintf("%s is writable, but the token cannot push to %s/%s", dir, owner, repo), "Workflows can be updated locally; fix --pr needs push access to push its branch.")
+	case repository == nil:
+		add("Write access", checkOK, fmt.Sprintf("%s is writable (push access unknown)", dir), "")
+	default:
+		add("Write access", checkOK, fmt.Sprintf("%s is writable, and the token can push", dir), "")
+	}
+	return checks
+}
+
+// checkWritable checks that files can be created in dir, by creating and
+// removing a temporary file. Nothing is written in read-only mode or if dir
+// does not exist.
+func checkWritable(dir string) error {
+	if info, err := os.Stat(dir); readonly.Enabled() || err != nil || !info.IsDir() {
+		return nil
+	}
+	f, err := os.CreateTemp(dir, ".slimify-doctor-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
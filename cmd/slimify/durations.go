@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+func newDurationsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "durations <workflow-file> [job-id]",
+		Short: "Show how job execution durations are sampled from the GitHub API",
+		Long: `Perform only the duration lookup for a workflow (or a single job) and print
+the sampled run, the raw timestamps, and the computed duration.
+
+This is useful for debugging mismatches between the report and what you see
+in the GitHub Actions UI. No workflow files are modified.
+
+With --runs, every sampled run is listed, followed by the statistics.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeDurationsArgs,
+		Run:               runDurations,
+	}
+}
+
+func runDurations(cmd *cobra.Command, args []string) {
+	workflowPath := args[0]
+
+	wf, err := workflow.LoadWorkflow(workflowPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var jobIDs []string
+	if len(args) == 2 {
+		if _, ok := wf.Jobs[args[1]]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: job %s not found in %s\n", args[1], workflowPath)
+			os.Exit(1)
+		}
+		jobIDs = []string{args[1]}
+	} else {
+		jobIDs = wf.JobIDs()
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get repository info: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to create API client: %v\n", err)
+		os.Exit(1)
+	}
+	client.SetRunFilter(runFilter())
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if durationRuns < 1 || durationRuns > 100 {
+		fmt.Fprintf(os.Stderr, "Error: --runs must be between 1 and 100\n")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	printf("📄 %s (%s/%s)\n", workflowPath, owner, repo)
+	if filter := runFilter().String(); filter != "" {
+		printf("  Runs on %s only\n", filter)
+	}
+	for _, jobID := range jobIDs {
+		job := wf.Jobs[jobID]
+		printf("  • \"%s\" (ID: %s)\n", job.Name, jobID)
+
+		if durationRuns > 1 {
+			printSampledRuns(ctx, client, workflowPath, jobID, job.Name, formerNames(cfg.RenamedJobs, workflowPath, jobID))
+			continue
+		}
+
+		duration, err := client.GetJobDuration(ctx, workflowPath, jobID, job.Name, formerNames(cfg.RenamedJobs, workflowPath, jobID)...)
+		if err != nil {
+			printf("    ⚠️  Lookup failed: %v\n", err)
+			continue
+		}
+
+		printf("    Sampled run:  #%d (ID: %d)\n", duration.RunNumber, duration.RunID)
+		if duration.RunURL != "" {
+			printf("                  %s\n", duration.RunURL)
+		}
+		printf("    Matched job:  \"%s\"\n", duration.MatchedName)
+		printf("    started_at:   %s\n", duration.StartedAt.Format(time.RFC3339))
+		printf("    completed_at: %s\n", duration.CompletedAt.Format(time.RFC3339))
+		printf("    Duration:     %s (%s)\n", scan.FormatDuration(duration.Duration), duration.Duration)
+	}
+}
+
+// printSampledRuns prints the duration of a job in each of the runs sampled
+// with --runs, and their statistics
+func printSampledRuns(ctx context.Context, client *api.Client, workflowPath, jobID, jobName string, formerNames []string) {
+	durations, err := client.GetJobDurations(ctx, workflowPath, jobID, jobName, durationRuns, formerNames...)
+	if err != nil {
+		printf("    ⚠️  Lookup failed: %v\n", err)
+		return
+	}
+
+	samples := make([]time.Duration, len(durations))
+	for i, d := range durations {
+		printf("    Run #%d (ID: %d): %s (\"%s\", started %s)\n", d.RunNumber, d.RunID, scan.FormatDuration(d.Duration), d.MatchedName, d.StartedAt.Format(time.RFC3339))
+		samples[i] = d.Duration
+	}
+	stats := scan.NewDurationStats(samples)
+	printf("    Median:       %s\n", stats.Median)
+	printf("    p90:          %s\n", stats.P90)
+	printf("    Std. dev.:    %s\n", stats.StdDev)
+	if len(durations) < durationRuns {
+		printf("    Only %d of %d requested runs were found\n", len(durations), durationRuns)
+	}
+}
+
+// formerNames returns the display names a job had before being renamed,
+// configured under renamed_jobs in the config file
+func formerNames(renamedJobs map[string][]string, workflowPath, jobID string) []string {
+	return renamedJobs[filepath.ToSlash(filepath.Clean(workflowPath))+":"+jobID]
+}
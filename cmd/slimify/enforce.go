@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var enforceAllow []string
+
+func newEnforceCmd() *cobra.Command {
+	enforceCmd := &cobra.Command{
+		Use:   "enforce [flags]",
+		Short: "Fail when added or modified jobs could use ubuntu-slim but use ubuntu-latest",
+		Long: `Enforce a "slim by default" policy in CI: fail when a job added or modified
+since the base ref could be migrated to ubuntu-slim but still runs on
+ubuntu-latest. Jobs left untouched are not reported, so the policy can be
+adopted before every existing job is migrated.
+
+The workflows changed since the base ref are listed like with --changed, and
+each job is compared with its definition at the merge base with HEAD (or, when
+git cannot tell, at the base branch, with the contents API). Jobs requiring
+attention (e.g., missing commands) are reported too, since they can be
+migrated once their warnings are taken care of.
+
+Jobs may stay on ubuntu-latest if they are allowed with --allow or the
+enforce.allow list of the config file, as <workflow>:<job-id> where the
+workflow may be a glob pattern, or if they have a "# slimify:ignore"
+directive. Job durations are not looked up.
+
+The command exits with status 1 if any job violates the policy.`,
+		Example: `  gh slimify enforce
+  gh slimify enforce --base origin/main --allow release.yml:publish`,
+		Args: cobra.NoArgs,
+		Run:  runEnforce,
+	}
+	enforceCmd.Flags().StringVar(&changedBase, "base", "", "Base ref the changes are made since (default: origin/$GITHUB_BASE_REF in pull requests, else the default branch of origin)")
+	enforceCmd.Flags().StringArrayVar(&enforceAllow, "allow", nil, "Allow the job to stay on ubuntu-latest, as <workflow>:<job-id> where the workflow may be a glob pattern (e.g., --allow deploy.yml:production). Can be specified multiple times")
+	enforceCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	enforceCmd.RegisterFlagCompletionFunc("allow", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeWorkflowJob(cmd, nil, toComplete)
+	})
+	return enforceCmd
+}
+
+func runEnforce(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := scan.NewPolicy(cfg.Enforce.Allow); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config %s: enforce: %v\n", configPath, err)
+		os.Exit(1)
+	}
+	policy, err := scan.NewPolicy(append(append([]string{}, cfg.Enforce.Allow...), enforceAllow...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --allow: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// The policy does not depend on durations or costs
+	opts.SkipDuration = true
+	opts.Usage = false
+
+	workflows, base, err := changedWorkflows()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	changed, err := changedJobs(base, workflows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := &scan.ScanResult{}
+	if len(changed) > 0 {
+		result, err = scan.ScanWithOptions(opts, workflows...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printLoadErrors(result)
+	}
+	report := policy.Check(result, changed)
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printPolicyReport(report, base)
+	}
+	if len(report.Violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// changedJobs returns the jobs of the workflows added or modified
+// since the base ref, compared with the workflows at the merge base of base
+// and HEAD
+func changedJobs(base string, workflows []string) ([]scan.ChangedJob, error) {
+	if len(workflows) == 0 {
+		return nil, nil
+	}
+	read, err := baseWorkflowReader(base)
+	if err != nil {
+		return nil, err
+	}
+	var changed []scan.ChangedJob
+	for _, path := range workflows {
+		after, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		before, err := read(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", path, base, err)
+		}
+		added, modified, err := workflow.ModifiedJobs(before, after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s with %s: %w", path, base, err)
+		}
+		for _, id := range added {
+			changed = append(changed, scan.ChangedJob{WorkflowPath: path, JobID: id, Added: true})
+		}
+		for _, id := range modified {
+			changed = append(changed, scan.ChangedJob{WorkflowPath: path, JobID: id})
+		}
+	}
+	return changed, nil
+}
+
+// baseWorkflowReader returns a function reading workflows at the merge base
+// of base and HEAD with git or, when git cannot tell (e.g., in a shallow clone
+// or with --no-git), at the base branch with the contents API. It returns nil
+// content for workflows that do not exist there.
+func baseWorkflowReader(base string) (func(path string) ([]byte, error), error) {
+	if mergeBase, err := git.MergeBase(base); err == nil {
+		return func(path string) ([]byte, error) {
+			files, err := git.ListFiles(mergeBase, path)
+			if err != nil || len(files) == 0 {
+				return nil, err
+			}
+			return git.ShowFile(mergeBase, path)
+		}, nil
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	// Remote-tracking refs are branches of the repository on GitHub
+	ref := strings.TrimPrefix(base, "origin/")
+	return func(path string) ([]byte, error) {
+		return client.GetFileContent(context.Background(), path, ref)
+	}, nil
+}
+
+// printPolicyReport prints the jobs violating the "slim by default" policy
+func printPolicyReport(report *scan.PolicyReport, base string) {
+	if report.Checked == 0 {
+		printf("No jobs added or modified since %s.\n", base)
+		return
+	}
+	for _, v := range report.Allowed {
+		printf("ℹ️  %s:%d: \"%s\" (%s) is allowed to stay on %s\n", v.WorkflowPath, v.LineNumber, v.JobName, v.JobID, workflow.SourceLabel())
+	}
+	if len(report.Violations) == 0 {
+		printf("✅ No job added or modified since %s can use %s instead of %s (%d job(s) checked).\n", base, workflow.TargetLabel(), workflow.SourceLabel(), report.Checked)
+		return
+	}
+
+	printf("❌ %d job(s) added or modified since %s can use %s but use %s:\n", len(report.Violations), base, workflow.TargetLabel(), workflow.SourceLabel())
+	for _, v := range report.Violations {
+		change := "modified"
+		if v.Added {
+			change = "added"
+		}
+		printf("   • %s:%d: \"%s\" (%s, %s)\n", v.WorkflowPath, v.LineNumber, v.JobName, v.JobID, change)
+		if len(v.Warnings) > 0 {
+			printf("     ⚠️  %s\n", strings.Join(v.Warnings, ", "))
+		}
+	}
+	printLine()
+	printf("💡 Migrate them with 'gh slimify fix <workflow-file>', or allow them in %s (enforce.allow) or with --allow.\n", configPath)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var explainAllJobs bool
+
+func newExplainCmd() *cobra.Command {
+	explainCmd := &cobra.Command{
+		Use:   "explain [rule-id | workflow-file:job-id | workflow-file --all-jobs]",
+		Short: "Explain what a migration rule checks and how to resolve it",
+		Long: `Print what a rule checks, why it blocks (or affects) migration to ubuntu-slim,
+and how to resolve it. Rules can be given by ID (e.g., SLIM003) or name
+(e.g., container-actions). Without arguments, all rules are listed.
+Given a job (e.g., .github/workflows/ci.yml:build), its evaluation trace is
+printed like the why command, including how it is classified for each value
+of a workflow_dispatch input its runs-on depends on.
+
+Given a workflow with --all-jobs, the trace of every job is printed. With
+-o json, it is written as one document, e.g., to attach to a migration ticket
+or to compare the evaluation of real workflows before and after a rule change
+(use --skip-duration for reproducible results).
+
+Rule IDs are shown next to each reason in the scan output.`,
+		Example: `  gh slimify explain SLIM003
+  gh slimify explain .github/workflows/ci.yml:build
+  gh slimify explain .github/workflows/ci.yml --all-jobs -o json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeExplainArgs,
+		Run:               runExplain,
+	}
+	explainCmd.Flags().BoolVar(&explainAllJobs, "all-jobs", false, "Explain every job of the given workflow file")
+	explainCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format with --all-jobs: text or json")
+	return explainCmd
+}
+
+func runExplain(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if explainAllJobs {
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --all-jobs requires a workflow file\n")
+			fmt.Fprintf(os.Stderr, "Example: gh slimify explain .github/workflows/ci.yml --all-jobs -o json\n")
+			os.Exit(1)
+		}
+		runExplainWorkflow(args[0])
+		return
+	}
+	if outputFormat != outputText {
+		fmt.Fprintf(os.Stderr, "Error: --output %s requires --all-jobs\n", outputFormat)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 {
+		for _, r := range scan.Rules() {
+			printf("%s  %-18s %-8s %s\n", r.ID, r.Name, r.Severity, r.Description)
+		}
+		printLine()
+		printLine("Run 'gh slimify explain <rule-id>' for details.")
+		return
+	}
+
+	r, ok := scan.LookupRule(args[0])
+	if !ok && strings.Contains(args[0], ":") {
+		// A job reference (<workflow-file>:<job-id>) is explained by its evaluation trace
+		runWhy(cmd, args)
+		return
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown rule %q. Run 'gh slimify explain' to list rules.\n", args[0])
+		os.Exit(1)
+	}
+
+	printf("%s (%s)\n", r.ID, r.Name)
+	printf("Severity: %s\n", severityLabel(r.Severity))
+	printLine()
+	printf("Checks:\n  %s\n\n", r.Description)
+	printf("Why:\n  %s\n\n", r.Rationale)
+	printf("How to resolve:\n  %s\n", r.Remediation)
+	if r.Required {
+		printLine()
+		printLine("This rule cannot be disabled.")
+	} else {
+		printLine()
+		printf("Disable with --disable-rule %s or in the rules: section of %s.\n", r.ID, configPath)
+	}
+}
+
+// severityLabel describes what a violation of a rule with the severity means
+func severityLabel(s scan.Severity) string {
+	switch s {
+	case scan.SeverityError:
+		return "error (the job cannot be migrated)"
+	case scan.SeverityWarning:
+		return "warning (the job can be migrated but requires attention)"
+	default:
+		return "info (the job is labeled for review)"
+	}
+}
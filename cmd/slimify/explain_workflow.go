@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// workflowExplanation is the evaluation of every job of a workflow, written
+// by explain --all-jobs -o json
+type workflowExplanation struct {
+	Workflow string           `json:"workflow"`
+	Version  string           `json:"version"`
+	Jobs     []jobExplanation `json:"jobs"`
+}
+
+// jobExplanation is the evaluation trace of a job, like the why command prints
+type jobExplanation struct {
+	JobID   string `json:"job_id"`
+	JobName string `json:"job_name"`
+	Line    int    `json:"line"`
+	// Status is empty if the job was not evaluated
+	Status string `json:"status,omitempty"`
+	// Twin is the ID of the ubuntu-latest job a canary duplicates
+	Twin string `json:"twin,omitempty"`
+	// CalledWorkflow is the local reusable workflow the job calls; its jobs
+	// are evaluated instead
+	CalledWorkflow string                 `json:"called_workflow,omitempty"`
+	Rules          []scan.RuleResult      `json:"rules,omitempty"`
+	RunnerChoices  []scan.RunnerChoice    `json:"runner_choices,omitempty"`
+	SetupActions   []workflow.SetupAction `json:"setup_actions"`
+	Commands       []workflow.Command     `json:"commands"`
+}
+
+// runExplainWorkflow prints the evaluation trace of every job of a workflow,
+// in the order they are defined
+func runExplainWorkflow(workflowPath string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	wf, result, jobIDs, err := scanForExplanation(workflowPath, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, newWorkflowExplanation(workflowPath, wf, result, opts, jobIDs)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printf("📄 %s (%d job(s))\n", workflowPath, len(jobIDs))
+	for _, jobID := range jobIDs {
+		printLine()
+		printJobTrace(result, wf, jobID, opts)
+	}
+}
+
+// scanForExplanation loads and scans a workflow, and returns its job IDs in
+// the order they appear
+func scanForExplanation(workflowPath string, opts scan.Options) (*workflow.Workflow, *scan.ScanResult, []string, error) {
+	wf, err := workflow.LoadWorkflow(workflowPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	result, err := scan.ScanWithOptions(opts, workflowPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return wf, result, wf.JobIDs(), nil
+}
+
+// newWorkflowExplanation explains the classification of each job of a
+// scanned workflow
+func newWorkflowExplanation(workflowPath string, wf *workflow.Workflow, result *scan.ScanResult, opts scan.Options, jobIDs []string) *workflowExplanation {
+	doc := &workflowExplanation{Workflow: workflowPath, Version: toolVersion(), Jobs: []jobExplanation{}}
+	for _, jobID := range jobIDs {
+		doc.Jobs = append(doc.Jobs, explainJob(result, wf, jobID, opts))
+	}
+	return doc
+}
+
+// explainJob returns the evaluation trace of a job of a scanned workflow
+func explainJob(result *scan.ScanResult, wf *workflow.Workflow, jobID string, opts scan.Options) jobExplanation {
+	job := wf.Jobs[jobID]
+	e := jobExplanation{
+		JobID:         jobID,
+		JobName:       job.Name,
+		Line:          job.LineStart,
+		RunnerChoices: scan.RunnerChoices(job, wf, opts.Rules),
+		SetupActions:  job.SetupActions(),
+		Commands:      job.Commands(),
+	}
+	if e.SetupActions == nil {
+		e.SetupActions = []workflow.SetupAction{}
+	}
+	if e.Commands == nil {
+		e.Commands = []workflow.Command{}
+	}
+	if decision := findDecision(result, wf.Path, jobID); decision != nil {
+		e.Status = decision.Status
+		e.Twin = decision.Twin
+		e.Rules = decision.Rules
+	} else if called, ok := job.LocalReusableWorkflow(); ok {
+		e.CalledWorkflow = called
+	}
+	return e
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat   string
+	graphMarkdown bool
+)
+
+func newGraphCmd() *cobra.Command {
+	graphCmd := &cobra.Command{
+		Use:   "graph [flags] [workflow-file...]",
+		Short: "Draw the jobs of workflows as a Mermaid or Graphviz graph colored by status",
+		Long: `Draw the workflows as a Mermaid flowchart (or a Graphviz graph with -o dot):
+one box per workflow holding its jobs, linked by their needs:, and colored by
+status: green for safe, yellow for warning, red for ineligible, blue for jobs
+already migrated to ubuntu-slim, and gray for canaries and ignored jobs.
+
+Use --markdown to wrap the flowchart in a mermaid code block, which GitHub
+renders in Markdown files, issues, and pull requests. Without arguments, all
+workflows in .github/workflows are drawn.`,
+		Example: `  gh slimify graph
+  gh slimify graph --markdown >> docs/migration.md
+  gh slimify graph -o dot --skip-duration | dot -Tsvg > jobs.svg`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runGraph,
+	}
+	// Not outputFormat: its default is text for every other command
+	graphCmd.Flags().StringVarP(&graphFormat, "output", "o", outputMermaid, "Output format: mermaid or dot")
+	graphCmd.Flags().BoolVar(&graphMarkdown, "markdown", false, "Wrap the Mermaid flowchart in a mermaid code block for Markdown documents")
+	registerWorkflowCompletion(graphCmd)
+	return graphCmd
+}
+
+func runGraph(cmd *cobra.Command, args []string) {
+	if graphFormat != outputMermaid && graphFormat != outputDot {
+		fmt.Fprintf(os.Stderr, "Error: invalid --output %q: must be %s or %s\n", graphFormat, outputMermaid, outputDot)
+		os.Exit(1)
+	}
+	if graphMarkdown && graphFormat != outputMermaid {
+		fmt.Fprintf(os.Stderr, "Error: --markdown only applies to --output %s\n", outputMermaid)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := scan.ScanWithOptions(opts, workflowArgs(args)...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if graphMarkdown {
+		printLine("```mermaid")
+	}
+	if err := writeScanGraph(os.Stdout, result, graphFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if graphMarkdown {
+		printLine("```")
+	}
+	printLoadErrors(result)
+}
+
+// graphColors are the fill and border colors of jobs in graphs, by status.
+// Canaries and ignored jobs are gray.
+var graphColors = map[string][2]string{
+	scan.StatusSafe:       {"#d4edda", "#28a745"},
+	scan.StatusWarning:    {"#fff3cd", "#d39e00"},
+	scan.StatusIneligible: {"#f8d7da", "#dc3545"},
+	scan.StatusMigrated:   {"#cfe2ff", "#0d6efd"},
+}
+
+// graphColor returns the fill and border colors of a job status
+func graphColor(status string) (fill, border string) {
+	c, ok := graphColors[status]
+	if !ok {
+		return "#e9ecef", "#6c757d"
+	}
+	return c[0], c[1]
+}
+
+// graphWorkflow is a workflow of the graph, with its jobs in file order
+type graphWorkflow struct {
+	path string
+	jobs []*scan.Decision
+}
+
+// scanGraph groups the jobs of a scan result by workflow, in the order of
+// the decision log
+func scanGraph(result *scan.ScanResult) []*graphWorkflow {
+	var workflows []*graphWorkflow
+	for _, d := range result.Decisions() {
+		if len(workflows) == 0 || workflows[len(workflows)-1].path != d.WorkflowPath {
+			workflows = append(workflows, &graphWorkflow{path: d.WorkflowPath})
+		}
+		w := workflows[len(workflows)-1]
+		w.jobs = append(w.jobs, d)
+	}
+	return workflows
+}
+
+// writeScanGraph writes the jobs of a scan result and their needs: edges as
+// a Graphviz (dot) or Mermaid graph, with one cluster per workflow and jobs
+// colored by status
+func writeScanGraph(w io.Writer, result *scan.ScanResult, format string) error {
+	bw := bufio.NewWriter(w)
+	workflows := scanGraph(result)
+	if format == outputDot {
+		writeDotGraph(bw, workflows, result.Dependencies)
+	} else {
+		writeMermaidGraph(bw, workflows, result.Dependencies)
+	}
+	return bw.Flush()
+}
+
+// writeDotGraph writes a Graphviz digraph. Nodes are identified by
+// "<workflow>:<job-id>".
+func writeDotGraph(w io.Writer, workflows []*graphWorkflow, deps []*scan.JobDependency) {
+	fmt.Fprintln(w, "digraph slimify {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled"];`)
+	for i, wf := range workflows {
+		fmt.Fprintf(w, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(w, "    label=%s;\n", dotQuote(wf.path))
+		for _, d := range wf.jobs {
+			fill, border := graphColor(d.Status)
+			fmt.Fprintf(w, "    %s [label=%s, fillcolor=%q, color=%q, tooltip=%s];\n",
+				dotQuote(d.WorkflowPath+":"+d.JobID), dotQuote(d.JobName), fill, border, dotQuote(d.Status))
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, dep := range deps {
+		fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(dep.WorkflowPath+":"+dep.Needs), dotQuote(dep.WorkflowPath+":"+dep.JobID))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// dotQuote quotes s as a Graphviz string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// writeMermaidGraph writes a Mermaid flowchart. Job IDs may contain
+// characters Mermaid does not accept in node IDs, so nodes are numbered
+// (w0_j1) and labeled with the job names.
+func writeMermaidGraph(w io.Writer, workflows []*graphWorkflow, deps []*scan.JobDependency) {
+	fmt.Fprintln(w, "flowchart LR")
+	ids := make(map[string]string)
+	for i, wf := range workflows {
+		fmt.Fprintf(w, "  subgraph w%d[%s]\n", i, mermaidQuote(wf.path))
+		for k, d := range wf.jobs {
+			id := fmt.Sprintf("w%d_j%d", i, k)
+			ids[d.WorkflowPath+":"+d.JobID] = id
+			fmt.Fprintf(w, "    %s[%s]:::%s\n", id, mermaidQuote(d.JobName), d.Status)
+		}
+		fmt.Fprintln(w, "  end")
+	}
+	for _, dep := range deps {
+		from, to := ids[dep.WorkflowPath+":"+dep.Needs], ids[dep.WorkflowPath+":"+dep.JobID]
+		if from != "" && to != "" {
+			fmt.Fprintf(w, "  %s --> %s\n", from, to)
+		}
+	}
+	for _, status := range []string{scan.StatusSafe, scan.StatusWarning, scan.StatusIneligible, scan.StatusMigrated, scan.StatusCanary, scan.StatusIgnored} {
+		fill, border := graphColor(status)
+		fmt.Fprintf(w, "  classDef %s fill:%s,stroke:%s\n", status, fill, border)
+	}
+}
+
+// mermaidQuote quotes s as a Mermaid label, escaping double quotes
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var historyJobs []string
+
+func newHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history [flags] [workflow-file...]",
+		Short: "List the runner changes made by fix, apply, revert, and promote",
+		Long: `List the runner changes recorded in the state file (` + state.DefaultPath + ` by
+default, see --state-file) by fix, apply, revert, and promote: when each job was
+changed, from which runner to which, and with which gh-slimify version.
+Commit the state file to share the history with your team.
+
+Changes can be filtered by workflow file and by job ID with --job.`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runHistory,
+	}
+	historyCmd.Flags().StringSliceVar(&historyJobs, "job", nil, "Only list changes of the job(s) with the given job ID(s)")
+	historyCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	registerWorkflowCompletion(historyCmd)
+	return historyCmd
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if statePath == "" {
+		fmt.Fprintf(os.Stderr, "Error: no state file (--state-file is empty)\n")
+		os.Exit(1)
+	}
+
+	s, err := state.Load(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	workflows := make(map[string]bool)
+	for _, path := range workflowArgs(args) {
+		workflows[filepath.Clean(path)] = true
+	}
+	jobs := make(map[string]bool)
+	for _, id := range historyJobs {
+		jobs[id] = true
+	}
+	entries := []state.Entry{}
+	for _, e := range s.Migrations {
+		if len(workflows) > 0 && !workflows[filepath.Clean(e.Workflow)] {
+			continue
+		}
+		if len(jobs) > 0 && !jobs[e.JobID] {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, entries); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		printf("No runner changes recorded in %s.\n", statePath)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCOMMAND\tJOB\tCHANGE\tVERSION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s:%s\t%s → %s\t%s\n", e.Timestamp.Local().Format("2006-01-02 15:04"), e.Command, e.Workflow, e.JobID, e.From, e.To, e.Version)
+	}
+	w.Flush()
+}
+
+// recordChanges appends runner changes made by command to the state file,
+// stamped with the current time and gh-slimify version, and reports whether
+// they were recorded. Failing to record does not undo the changes, so it is
+// reported as a warning.
+func recordChanges(command string, entries []state.Entry) bool {
+	if statePath == "" || len(entries) == 0 {
+		return false
+	}
+	now := time.Now().UTC()
+	for i := range entries {
+		entries[i].Timestamp = now
+		entries[i].Command = command
+		entries[i].Version = toolVersion()
+	}
+	if err := state.Append(statePath, entries...); err != nil {
+		slog.Warn("failed to record changes", "state_file", statePath, "error", err)
+		return false
+	}
+	return true
+}
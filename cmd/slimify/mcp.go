@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/mcp"
+	"github.com/fchimpan/gh-slimify/internal/plan"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+func newMCPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve scan, explain, and plan as Model Context Protocol tools over stdio",
+		Long: `Run a Model Context Protocol (MCP) server on stdin and stdout, so that AI
+coding agents can query migration eligibility and propose workflow edits:
+
+  scan     Scan workflows and return the result as in 'gh slimify -o json'
+  explain  Describe a rule, or explain the classification of every job of a
+           workflow as in 'gh slimify explain <file> --all-jobs -o json'
+  plan     Compute the edits fix would make, as in a plan file, without
+           modifying any workflow
+
+The server runs in the repository's root directory. Global flags (e.g.,
+--skip-duration, --config, --disable-rule) apply to every tool call.`,
+		Example: `  gh slimify mcp
+  gh slimify mcp --skip-duration`,
+		Args: cobra.NoArgs,
+		Run:  runMCP,
+	}
+}
+
+// ruleInfo describes a rule, returned by the explain tool
+type ruleInfo struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Severity    scan.Severity `json:"severity"`
+	Description string        `json:"description"`
+	Rationale   string        `json:"rationale,omitempty"`
+	Remediation string        `json:"remediation,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+}
+
+// mcpPlan is the result of the plan tool
+type mcpPlan struct {
+	Plan *plan.Plan `json:"plan"`
+	// Skipped lists the jobs with warnings left out without force
+	Skipped []*scan.Candidate `json:"skipped,omitempty"`
+	Errors  []string          `json:"errors,omitempty"`
+}
+
+// workflowsSchema is the input schema of tools taking workflow files
+var workflowsSchema = map[string]any{
+	"type":        "array",
+	"items":       map[string]any{"type": "string"},
+	"description": "Workflow files to process (e.g., .github/workflows/ci.yml). Empty processes every workflow in .github/workflows.",
+}
+
+func runMCP(cmd *cobra.Command, args []string) {
+	// Options are computed once: the configuration file registers tools and
+	// inputs globally
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := &mcp.Server{
+		Name:    "gh-slimify",
+		Version: toolVersion(),
+		Tools: []*mcp.Tool{
+			{
+				Name:        "scan",
+				Description: "Scan GitHub Actions workflows for ubuntu-latest jobs that can migrate to the lightweight ubuntu-slim runner. Returns the candidates (with warnings such as missing commands), the ineligible jobs with their reasons, and the rules evaluated.",
+				InputSchema: map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"workflows": workflowsSchema},
+				},
+				Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
+					var a struct {
+						Workflows []string `json:"workflows"`
+					}
+					if err := json.Unmarshal(raw, &a); err != nil {
+						return nil, err
+					}
+					return scan.ScanWithOptions(opts, a.Workflows...)
+				},
+			},
+			{
+				Name:        "explain",
+				Description: "Describe a rule (by ID like SLIM002 or name like docker-commands), list every rule when no argument is given, or explain the classification of every job of a workflow file with the evidence of each rule.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"rule":     map[string]any{"type": "string", "description": "Rule ID or name"},
+						"workflow": map[string]any{"type": "string", "description": "Workflow file whose jobs to explain"},
+					},
+				},
+				Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
+					var a struct {
+						Rule     string `json:"rule"`
+						Workflow string `json:"workflow"`
+					}
+					if err := json.Unmarshal(raw, &a); err != nil {
+						return nil, err
+					}
+					switch {
+					case a.Rule != "" && a.Workflow != "":
+						return nil, fmt.Errorf("rule and workflow cannot be combined")
+					case a.Workflow != "":
+						wf, result, jobIDs, err := scanForExplanation(a.Workflow, opts)
+						if err != nil {
+							return nil, err
+						}
+						return newWorkflowExplanation(a.Workflow, wf, result, opts, jobIDs), nil
+					case a.Rule != "":
+						r, ok := scan.LookupRule(a.Rule)
+						if !ok {
+							return nil, fmt.Errorf("unknown rule %q", a.Rule)
+						}
+						return ruleInfo{ID: r.ID, Name: r.Name, Severity: r.Severity, Description: r.Description, Rationale: r.Rationale, Remediation: r.Remediation, Required: r.Required}, nil
+					}
+					rules := []ruleInfo{}
+					for _, r := range scan.Rules() {
+						rules = append(rules, ruleInfo{ID: r.ID, Name: r.Name, Severity: r.Severity, Description: r.Description})
+					}
+					return rules, nil
+				},
+			},
+			{
+				Name:        "plan",
+				Description: "Compute the edits that migrate eligible jobs to ubuntu-slim (file, job, line, column, old and new runs-on value), like 'gh slimify plan', without modifying any file. Jobs with warnings are skipped unless force is true.",
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"workflows": workflowsSchema,
+						"force":     map[string]any{"type": "boolean", "description": "Also plan jobs with warnings (missing commands or unknown execution time)"},
+					},
+				},
+				Handler: func(ctx context.Context, raw json.RawMessage) (any, error) {
+					var a struct {
+						Workflows []string `json:"workflows"`
+						Force     bool     `json:"force"`
+					}
+					if err := json.Unmarshal(raw, &a); err != nil {
+						return nil, err
+					}
+					result, err := scan.ScanWithOptions(opts, a.Workflows...)
+					if err != nil {
+						return nil, err
+					}
+					// Tool calls are handled one at a time
+					defer func(f bool) { force = f }(force)
+					force = a.Force
+					jobs, skipped, _ := selectJobs(result.Candidates)
+					p, errs := buildPlan(jobs)
+					out := mcpPlan{Plan: p, Skipped: skipped}
+					for _, err := range errs {
+						out.Errors = append(out.Errors, err.Error())
+					}
+					return out, nil
+				},
+			},
+		},
+	}
+
+	fmt.Fprintf(os.Stderr, "gh-slimify MCP server listening on stdio\n")
+	if err := server.Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorWindow             time.Duration
+	monitorMaxFailureIncrease float64
+	monitorMaxSlowdown        float64
+	monitorMinRuns            int
+	monitorJobs               []string
+)
+
+func newMonitorCmd() *cobra.Command {
+	monitorCmd := &cobra.Command{
+		Use:   "monitor [flags] [workflow-file...]",
+		Short: "Compare the health of migrated jobs before and after their migration",
+		Long: `For jobs already running on ubuntu-slim, compare the failure rate and the
+median duration of their recent runs with the runs before their migration, and
+flag the jobs that regressed, suggesting a revert.
+
+Runs are split at the time the job was migrated, as recorded in the state file
+by fix, apply, and promote (see 'gh slimify history'), and compared over
+--window on each side. Jobs migrated without gh-slimify are split by the
+runner labels GitHub reports for each run. Jobs with fewer than --min-runs
+runs since their migration are not judged. Without arguments, all workflows
+in .github/workflows are monitored.
+
+The command exits with status 1 if any job regressed, e.g., to alert from a
+scheduled workflow.`,
+		Example: `  gh slimify monitor
+  gh slimify monitor --window 336h --max-slowdown 50 .github/workflows/ci.yml`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runMonitor,
+	}
+	monitorCmd.Flags().DurationVar(&monitorWindow, "window", scan.MonitorWindow, "Period of runs compared on each side of a migration")
+	monitorCmd.Flags().Float64Var(&monitorMaxFailureIncrease, "max-failure-increase", 100*scan.DefaultMonitorThresholds.MaxFailureRateIncrease, "Largest increase of the failure rate tolerated, in percentage points")
+	monitorCmd.Flags().Float64Var(&monitorMaxSlowdown, "max-slowdown", 100*scan.DefaultMonitorThresholds.MaxSlowdown, "Largest increase of the median duration tolerated, in percent")
+	monitorCmd.Flags().IntVar(&monitorMinRuns, "min-runs", scan.DefaultMonitorThresholds.MinRuns, "Runs since the migration needed to judge a job")
+	monitorCmd.Flags().StringSliceVar(&monitorJobs, "job", nil, "Only monitor the job(s) with the given job ID(s)")
+	monitorCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	registerWorkflowCompletion(monitorCmd)
+	return monitorCmd
+}
+
+func runMonitor(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if monitorWindow <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --window must be positive\n")
+		os.Exit(1)
+	}
+
+	var workflows []*workflow.Workflow
+	files := workflowArgs(args)
+	if len(files) == 0 {
+		var err error
+		workflows, err = workflow.LoadWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load workflows: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, path := range files {
+		wf, err := workflow.LoadWorkflow(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load workflow %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		workflows = append(workflows, wf)
+	}
+
+	migratedAt := func(string, string) time.Time { return time.Time{} }
+	if statePath != "" {
+		s, err := state.Load(statePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		migratedAt = func(workflowPath, jobID string) time.Time {
+			return lastMigration(s, workflowPath, jobID)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range monitorJobs {
+		selected[id] = true
+	}
+	var jobs []scan.MonitoredJob
+	for _, j := range scan.MigratedJobs(workflows, migratedAt) {
+		if len(selected) == 0 || selected[j.JobID] {
+			jobs = append(jobs, j)
+		}
+	}
+
+	thresholds := scan.MonitorThresholds{
+		MaxFailureRateIncrease: monitorMaxFailureIncrease / 100,
+		MaxSlowdown:            monitorMaxSlowdown / 100,
+		MinRuns:                monitorMinRuns,
+	}
+	health, err := scan.Monitor(context.Background(), jobs, monitorWindow, thresholds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	regressed := 0
+	for _, h := range health {
+		if h.Regressed() {
+			regressed++
+		}
+	}
+	if outputFormat == outputJSON {
+		if health == nil {
+			health = []*scan.JobHealth{}
+		}
+		if err := writeJSON(os.Stdout, health); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printMonitor(health)
+	}
+	if regressed > 0 {
+		os.Exit(1)
+	}
+}
+
+// lastMigration returns when a job was last migrated to the target runner
+// according to the state file, or the zero time if it was not recorded
+func lastMigration(s *state.State, workflowPath, jobID string) time.Time {
+	var at time.Time
+	for _, e := range s.Migrations {
+		if filepath.Clean(e.Workflow) == filepath.Clean(workflowPath) && e.JobID == jobID && e.To == workflow.TargetLabel() {
+			at = e.Timestamp
+		}
+	}
+	return at
+}
+
+// printMonitor prints the health of migrated jobs, grouped by workflow
+func printMonitor(health []*scan.JobHealth) {
+	if len(health) == 0 {
+		printf("No jobs running on %s found.\n", workflow.TargetLabel())
+		return
+	}
+
+	regressed := 0
+	for i, h := range health {
+		if i == 0 || health[i-1].WorkflowPath != h.WorkflowPath {
+			if i > 0 {
+				printLine()
+			}
+			printf("📄 %s\n", h.WorkflowPath)
+		}
+		since := ""
+		if h.MigratedAt != nil {
+			since = " on " + h.MigratedAt.Local().Format("2006-01-02")
+		}
+		switch {
+		case h.Regressed():
+			regressed++
+			printf("  ❌ \"%s\" (%s) regressed since its migration%s:\n", h.JobName, h.JobID, since)
+			for _, r := range h.Regressions {
+				printf("     • %s\n", r)
+			}
+		case h.Insufficient:
+			printf("  ❓ \"%s\" (%s): not enough runs to judge since its migration%s\n", h.JobName, h.JobID, since)
+		default:
+			printf("  ✅ \"%s\" (%s): healthy since its migration%s\n", h.JobName, h.JobID, since)
+		}
+		printf("     Before: %s · After: %s\n", formatRunHealth(h.Before), formatRunHealth(h.After))
+		if h.Regressed() {
+			printf("     💡 Revert it with: gh slimify revert --job %s %s\n", h.JobID, h.WorkflowPath)
+		}
+	}
+
+	printLine()
+	if regressed == 0 {
+		printf("No regressions among %d migrated job(s).\n", len(health))
+	} else {
+		printf("%d of %d migrated job(s) regressed.\n", regressed, len(health))
+	}
+}
+
+// formatRunHealth describes the runs of a job on one side of its migration
+func formatRunHealth(h scan.RunHealth) string {
+	if h.Runs == 0 {
+		return "no runs"
+	}
+	s := fmt.Sprintf("%d run(s), %.0f%% failed", h.Runs, 100*h.FailureRate)
+	if h.Median != "" {
+		s += ", median " + h.Median
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Output formats selected with --output
+const (
+	outputText     = "text"
+	outputJSON     = "json"
+	outputTemplate = "template" // Rendered through the --template file (scan only)
+	outputTable    = "table"    // One row per job: aligned on terminals, tab-separated when piped (scan only)
+	outputDot      = "dot"      // Graphviz graph of the jobs' needs: (scan only)
+	outputMermaid  = "mermaid"  // Mermaid flowchart of the jobs' needs: (scan only)
+)
+
+var (
+	outputFormat string
+	templatePath string
+)
+
+// checkOutputFormat validates --output
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: must be %s or %s", outputFormat, outputText, outputJSON)
+	}
+}
+
+// checkScanOutputFormat validates --output and --template for scan, whose
+// result can also be rendered as a table or through a template
+func checkScanOutputFormat() error {
+	if outputFormat == outputTemplate {
+		if templatePath == "" {
+			return fmt.Errorf("--output %s requires --template <file>", outputTemplate)
+		}
+		return nil
+	}
+	if templatePath != "" {
+		return fmt.Errorf("--template requires --output %s", outputTemplate)
+	}
+	switch outputFormat {
+	case outputTable, outputDot, outputMermaid:
+		return nil
+	}
+	if err := checkOutputFormat(); err != nil {
+		return fmt.Errorf("invalid --output %q: must be %s, %s, %s, %s, %s, or %s with --template", outputFormat, outputText, outputJSON, outputTable, outputDot, outputMermaid, outputTemplate)
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to --template files in addition
+// to the built-in ones
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseOutputTemplate parses the --template file
+func parseOutputTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeJSON writes v as an indented JSON document
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
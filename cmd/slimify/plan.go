@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/plan"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var planPath string
+
+func newPlanCmd() *cobra.Command {
+	planCmd := &cobra.Command{
+		Use:   "plan [flags] [workflow-file...]",
+		Short: "Save the edits fix would make to a plan file, for review",
+		Long: `Select the jobs like fix does and save the exact edits it would make (file,
+job, line, old and new value) to a plan file, without modifying any workflow.
+Review the plan, then run 'gh slimify apply <plan-file>' to make the edits.
+
+The plan records a SHA-256 hash of each workflow, and apply refuses to run if
+any of them has changed since the plan was created.
+
+By default, you must specify workflow file(s) to process. Use --all to scan all
+workflows in .github/workflows/*.yml.`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runPlan,
+	}
+	planCmd.Flags().StringVarP(&planPath, "output", "o", "", "Path of the plan file to write (e.g., plan.json)")
+	planCmd.Flags().BoolVar(&force, "force", false, "Also plan jobs with warnings (missing commands or unknown execution time)")
+	planCmd.Flags().BoolVar(&excludeCredentialed, "exclude-credentialed", false, "Skip credentialed jobs (using secrets or OIDC tokens) so they can be migrated after manual review")
+	planCmd.Flags().BoolVar(&strict, "strict", false, "Do not write a plan if any workflow file fails to load")
+	planCmd.MarkFlagRequired("output")
+	registerWorkflowCompletion(planCmd)
+	return planCmd
+}
+
+func runPlan(cmd *cobra.Command, args []string) {
+	files := workflowArgs(args)
+
+	if !scanAll && len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no workflow files specified. Use --all to scan all workflows, or specify workflow file(s) as arguments or with --file flag.\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify plan -o plan.json .github/workflows/ci.yml\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify plan -o plan.json --all\n")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := scan.ScanWithOptions(opts, files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if printLoadErrors(result) {
+		if strict {
+			fmt.Fprintf(os.Stderr, "Error: no plan was written (--strict)\n")
+			os.Exit(1)
+		}
+		printLine()
+	}
+	printDeadlineExceeded(result)
+	printRateLimited(result)
+
+	jobsToPlan, skippedJobs, credentialedJobs := selectJobs(result.Candidates)
+	if len(credentialedJobs) > 0 {
+		printf("Skipping %d credentialed job(s) that require manual review.\n", len(credentialedJobs))
+	}
+	if len(skippedJobs) > 0 {
+		printf("Skipping %d job(s) with warnings. Use --force to plan them.\n", len(skippedJobs))
+	}
+
+	p, errs := buildPlan(jobsToPlan)
+	planned := 0
+	for _, file := range p.Files {
+		printf("\nPlanning %s\n", file.Path)
+		for _, job := range file.Jobs {
+			for _, c := range job.Changes {
+				printf("  • \"%s\" L%d:%d  %s → %s\n", job.Name, c.Line, c.Column, c.Old, c.New)
+			}
+			planned++
+		}
+	}
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	errorCount := len(errs)
+
+	if err := p.Write(planPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printLine()
+	if planned == 0 {
+		printf("No jobs to migrate. Wrote an empty plan to %s.\n", planPath)
+	} else {
+		printf("Planned %d job(s) in %d workflow(s) to use %s. Wrote %s.\n", planned, len(p.Files), workflow.TargetLabel(), planPath)
+		printf("💡 Review it, then run 'gh slimify apply %s'\n", planPath)
+	}
+	if errorCount > 0 {
+		fmt.Fprintf(os.Stderr, "Encountered %d error(s) during planning.\n", errorCount)
+		os.Exit(1)
+	}
+}
+
+// buildPlan computes the edits switching jobs to ubuntu-slim, grouped by
+// workflow file in path order. Jobs that cannot be planned are left out and
+// returned as errors, one per job.
+func buildPlan(jobs []*scan.Candidate) (*plan.Plan, []error) {
+	workflowMap := make(map[string][]*scan.Candidate)
+	for _, c := range jobs {
+		workflowMap[c.WorkflowPath] = append(workflowMap[c.WorkflowPath], c)
+	}
+	workflowPaths := make([]string, 0, len(workflowMap))
+	for path := range workflowMap {
+		workflowPaths = append(workflowPaths, path)
+	}
+	sort.Strings(workflowPaths)
+
+	p := &plan.Plan{
+		FormatVersion: plan.FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Version:       toolVersion(),
+		Files:         []plan.File{},
+	}
+	var errs []error
+	for _, workflowPath := range workflowPaths {
+		jobs := workflowMap[workflowPath]
+		data, err := os.ReadFile(workflowPath)
+		if err != nil {
+			for _, job := range jobs {
+				errs = append(errs, fmt.Errorf("job %s (ID: %s): failed to read file %s: %w", job.JobName, job.JobID, workflowPath, err))
+			}
+			continue
+		}
+		jobIDs := make([]string, 0, len(jobs))
+		for _, job := range jobs {
+			jobIDs = append(jobIDs, job.JobID)
+		}
+		changes, failed, err := workflow.PlanRunnerLabel(data, jobIDs, workflow.SourceLabel(), workflow.TargetLabel())
+		if err != nil {
+			for _, job := range jobs {
+				errs = append(errs, fmt.Errorf("job %s (ID: %s): %s: %w", job.JobName, job.JobID, workflowPath, err))
+			}
+			continue
+		}
+
+		file := plan.File{Path: workflowPath, SHA256: workflow.ContentHash(data)}
+		for _, job := range jobs {
+			if err := failed[job.JobID]; err != nil {
+				errs = append(errs, fmt.Errorf("planning job %s (ID: %s) in %s: %w", job.JobName, job.JobID, workflowPath, err))
+				continue
+			}
+			file.Jobs = append(file.Jobs, plan.Job{ID: job.JobID, Name: job.JobName, Changes: changes[job.JobID]})
+		}
+		if len(file.Jobs) > 0 {
+			p.Files = append(p.Files, file)
+		}
+	}
+	return p, errs
+}
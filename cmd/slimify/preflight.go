@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// preflightRunner checks that the repository can run jobs on label before
+// fix switches jobs to it, and exits if it cannot, since every migrated job
+// would then wait for a runner forever. When the check cannot be made (no
+// repository or token, missing permissions), a warning is logged and fix
+// goes on.
+func preflightRunner(label string) {
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		slog.Warn("skipped the runner availability check", "label", label, "error", err)
+		return
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		slog.Warn("skipped the runner availability check", "label", label, "error", err)
+		return
+	}
+
+	err = client.CheckRunnerLabel(context.Background(), label, workflow.IsHostedLabel(label))
+	switch {
+	case err == nil:
+	case errors.Is(err, api.ErrRunnerUnverified):
+		slog.Warn("could not check that the runner is available", "label", label, "error", err)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: cannot migrate jobs to %s: %v\n", label, err)
+		fmt.Fprintf(os.Stderr, "No workflow was updated. Use --skip-preflight to update them anyway.\n")
+		os.Exit(1)
+	}
+}
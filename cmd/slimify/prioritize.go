@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var prioritizeTop int
+
+func newPrioritizeCmd() *cobra.Command {
+	prioritizeCmd := &cobra.Command{
+		Use:   "prioritize [flags] [workflow-file...]",
+		Short: "Rank migration candidates by the runner minutes they consume",
+		Long: `Rank the jobs that can be migrated to ubuntu-slim by impact: the billable
+minutes they consumed over the last 30 days, i.e., how often they run times how
+long they take. The top jobs are listed with their number of runs, average
+billable minutes per run, and the estimated monthly savings of migrating them,
+so you know where to start.
+
+Usage is looked up like with --billing: the jobs of every completed run of the
+workflows are fetched from the GitHub API. Without arguments, all workflows in
+.github/workflows are ranked.`,
+		Example: `  gh slimify prioritize
+  gh slimify prioritize --top 20 -o json`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runPrioritize,
+	}
+	prioritizeCmd.Flags().IntVar(&prioritizeTop, "top", 10, "Number of jobs to list (0 lists every job)")
+	prioritizeCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	registerWorkflowCompletion(prioritizeCmd)
+	return prioritizeCmd
+}
+
+// priority is a ranked migration candidate, written by prioritize -o json
+type priority struct {
+	Rank            int     `json:"rank"`
+	WorkflowPath    string  `json:"workflow"`
+	JobID           string  `json:"job_id"`
+	JobName         string  `json:"job_name"`
+	LineNumber      int     `json:"line"`
+	Status          string  `json:"status"` // safe or warning, like in decision records
+	Runs            int     `json:"runs"`
+	BillableMinutes int     `json:"billable_minutes"`
+	AverageMinutes  float64 `json:"average_minutes"` // Billable minutes per run
+	Savings         float64 `json:"monthly_savings"`
+}
+
+func runPrioritize(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if prioritizeTop < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --top must not be negative\n")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Usage = true
+
+	files := workflowArgs(args)
+	result, err := scan.ScanWithOptions(opts, files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ranked := scan.RankByUsage(result.Candidates)
+	priorities := []priority{}
+	for i, c := range ranked {
+		if prioritizeTop > 0 && i == prioritizeTop {
+			break
+		}
+		status := scan.StatusSafe
+		if c.HasWarnings() {
+			status = scan.StatusWarning
+		}
+		p := priority{
+			Rank:            i + 1,
+			WorkflowPath:    c.WorkflowPath,
+			JobID:           c.JobID,
+			JobName:         c.JobName,
+			LineNumber:      c.LineNumber,
+			Status:          status,
+			Runs:            c.Usage.Runs,
+			BillableMinutes: c.Usage.BillableMinutes,
+			Savings:         c.Usage.Savings,
+		}
+		if c.Usage.Runs > 0 {
+			p.AverageMinutes = float64(c.Usage.BillableMinutes) / float64(c.Usage.Runs)
+		}
+		priorities = append(priorities, p)
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, priorities); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printLoadErrors(result)
+	printRateLimited(result)
+	if len(priorities) == 0 {
+		printLine("No migration candidates with usage found.")
+		return
+	}
+	printf("🏁 Top %d of %d migration candidate(s) by billable minutes over the last 30 days:\n\n", len(priorities), len(result.Candidates))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tRUNS\tAVG MIN\tMINUTES\tSAVINGS/MO\tSTATUS\tJOB")
+	for _, p := range priorities {
+		fmt.Fprintf(w, "%d\t%d\t%.1f\t%d\t~$%.2f\t%s\t\"%s\" %s\n", p.Rank, p.Runs, p.AverageMinutes, p.BillableMinutes, p.Savings, p.Status, p.JobName, formatLocalLink(p.WorkflowPath, p.LineNumber))
+	}
+	w.Flush()
+	if unknown := len(result.Candidates) - len(ranked); unknown > 0 {
+		printf("\n%d candidate(s) were not ranked because their usage could not be looked up (use --verbose for details).\n", unknown)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/guard"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var promoteJobs []string
+
+func newPromoteCmd() *cobra.Command {
+	promoteCmd := &cobra.Command{
+		Use:   "promote [flags] [workflow-file...]",
+		Short: "Make canary jobs already testing ubuntu-slim authoritative",
+		Long: `Find canary jobs: jobs that run on ubuntu-slim with continue-on-error: true
+next to an ubuntu-latest twin running the same steps (or named like it, e.g.,
+build and build-slim). Promoting a canary removes its twin, drops
+continue-on-error, and gives the canary the twin's job ID and name so that
+needs: references and status checks keep working.
+
+By default, you must specify workflow file(s) to process. Use --all to process
+all workflows in .github/workflows/*.yml.`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runPromote,
+	}
+	promoteCmd.Flags().StringSliceVar(&promoteJobs, "job", nil, "Only promote the canary job(s) with the given job ID(s)")
+	registerWorkflowCompletion(promoteCmd)
+	return promoteCmd
+}
+
+func runPromote(cmd *cobra.Command, args []string) {
+	if err := readonly.Check("promote canary jobs"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	files := workflowArgs(args)
+
+	if !scanAll && len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no workflow files specified. Use --all to process all workflows, or specify workflow file(s) as arguments or with --file flag.\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify promote .github/workflows/ci.yml\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify promote --all\n")
+		os.Exit(1)
+	}
+
+	var workflows []*workflow.Workflow
+	if scanAll {
+		var err error
+		workflows, err = workflow.LoadWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load workflows: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, path := range files {
+			wf, err := workflow.LoadWorkflow(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to load workflow %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			workflows = append(workflows, wf)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range promoteJobs {
+		selected[id] = true
+	}
+
+	promoted := 0
+	errorCount := 0
+	var entries []state.Entry
+	for _, wf := range workflows {
+		var canaries []*scan.Canary
+		for _, c := range scan.FindCanaries(wf) {
+			if len(selected) == 0 || selected[c.JobID] {
+				canaries = append(canaries, c)
+			}
+		}
+		if len(canaries) == 0 {
+			continue
+		}
+
+		printf("Promoting canaries in %s\n", wf.Path)
+		if err := guard.CheckWorkflowPath(wf.Path, workflow.Dir()); err != nil {
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+			errorCount += len(canaries)
+			printLine()
+			continue
+		}
+		for _, c := range canaries {
+			if err := workflow.PromoteJob(wf.Path, c.JobID, c.TwinID); err != nil {
+				fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+				errorCount++
+				continue
+			}
+			printf("  ✓ Promoted job \"%s\" (L%d) → replaces \"%s\" (L%d) as %s on %s\n", c.JobName, c.LineNumber, c.TwinName, c.TwinLine, c.TwinID, workflow.TargetLabel())
+			entries = append(entries, state.Entry{Workflow: wf.Path, JobID: c.TwinID, JobName: c.TwinName, From: workflow.SourceLabel(), To: workflow.TargetLabel()})
+			promoted++
+		}
+		printLine()
+	}
+
+	if promoted == 0 && errorCount == 0 {
+		printLine("No canary jobs found to promote.")
+		return
+	}
+	recordChanges("promote", entries)
+	printf("Successfully promoted %d canary job(s).\n", promoted)
+	if errorCount > 0 {
+		fmt.Fprintf(os.Stderr, "Encountered %d error(s) during promotion.\n", errorCount)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/v2"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+// newTemplateData builds the template data describing the updated jobs
+func newTemplateData(updated []*scan.Candidate) config.TemplateData {
+	data := config.TemplateData{
+		Count: len(updated),
+		Date:  time.Now().Format("20060102"),
+	}
+
+	seen := make(map[string]bool)
+	var savings time.Duration
+	known := false
+	for _, c := range updated {
+		if !seen[c.WorkflowPath] {
+			seen[c.WorkflowPath] = true
+			data.Workflows = append(data.Workflows, c.WorkflowPath)
+		}
+
+		duration := c.Duration
+		if d, err := time.ParseDuration(duration); err == nil {
+			savings += d
+			known = true
+		} else {
+			duration = "unknown"
+		}
+		data.Jobs = append(data.Jobs, config.TemplateJob{
+			Workflow: c.WorkflowPath,
+			ID:       c.JobID,
+			Name:     c.JobName,
+			Duration: duration,
+		})
+	}
+
+	sort.Strings(data.Workflows)
+	sort.Slice(data.Jobs, func(i, k int) bool {
+		if data.Jobs[i].Workflow != data.Jobs[k].Workflow {
+			return data.Jobs[i].Workflow < data.Jobs[k].Workflow
+		}
+		return data.Jobs[i].ID < data.Jobs[k].ID
+	})
+	data.Workflow = strings.Join(data.Workflows, ", ")
+
+	data.Savings = "unknown"
+	if known {
+		data.Savings = scan.FormatDuration(savings)
+	}
+	return data
+}
+
+// commitFixes creates a branch and commits the updated workflows, and the
+// extra files (e.g., the state file), using the configured templates for the
+// branch name and commit message. If openPR is true, the branch is pushed and
+// a pull request is opened with gh.
+func commitFixes(cfg *config.Config, updated []*scan.Candidate, openPR bool, extra ...string) error {
+	data := newTemplateData(updated)
+	templates := cfg.Templates
+
+	branch, err := templates.RenderBranch(data)
+	if err != nil {
+		return err
+	}
+	message, err := templates.RenderCommitMessage(data)
+	if err != nil {
+		return err
+	}
+	var title, body string
+	if openPR {
+		if title, err = templates.RenderPRTitle(data); err != nil {
+			return err
+		}
+		if body, err = templates.RenderPRBody(data); err != nil {
+			return err
+		}
+	}
+
+	if err := git.CreateBranch(branch); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	if err := git.Commit(message, append(append([]string{}, data.Workflows...), extra...)...); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	printf("Committed changes on branch %s.\n", branch)
+
+	if !openPR {
+		return nil
+	}
+
+	if err := git.Push("origin", branch); err != nil {
+		return fmt.Errorf("failed to push branch %s: %w", branch, err)
+	}
+	if err := readonly.Check("create pull request"); err != nil {
+		return err
+	}
+	stdout, stderr, err := gh.Exec("pr", "create", "--head", branch, "--title", title, "--body", body)
+	if err != nil {
+		return fmt.Errorf("failed to create pull request: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	printf("Opened pull request: %s\n", strings.TrimSpace(stdout.String()))
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// scanRef is the git ref workflows are read from (--ref), instead of the
+// working tree
+var scanRef string
+
+// setupRef makes workflows be read from --ref: with git show when git is
+// available, and with the contents API otherwise (--no-git). Files are not
+// modified, since they are not the ones scanned.
+func setupRef() {
+	if scanRef == "" {
+		return
+	}
+	readonly.Enable()
+
+	if !noGit {
+		if _, err := git.ResolveCommit(scanRef); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --ref %q: %v\n", scanRef, err)
+			os.Exit(1)
+		}
+		workflow.SetSource(gitSource{ref: scanRef})
+		return
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get repository info: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	workflow.SetSource(&apiSource{client: client, ref: scanRef})
+}
+
+// gitSource reads the files of a git ref of the local repository
+type gitSource struct {
+	ref string
+}
+
+func (s gitSource) ReadFile(path string) ([]byte, error) {
+	return git.ShowFile(s.ref, path)
+}
+
+func (s gitSource) Files(dir string) ([]string, error) {
+	return git.ListFiles(s.ref, dir)
+}
+
+// apiSource reads the files of a ref of the repository on GitHub with the
+// contents API. Paths are relative to the repository root, which the current
+// directory is assumed to be since git cannot tell (--no-git).
+type apiSource struct {
+	client *api.Client
+	ref    string
+}
+
+func (s *apiSource) ReadFile(path string) ([]byte, error) {
+	data, err := s.client.GetFileContent(context.Background(), filepath.ToSlash(filepath.Clean(path)), s.ref)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s does not exist at %s: %w", path, s.ref, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (s *apiSource) Files(dir string) ([]string, error) {
+	return s.client.GetTreeFiles(context.Background(), s.ref, filepath.ToSlash(filepath.Clean(dir)))
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/guard"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/state"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var revertJobs []string
+
+func newRevertCmd() *cobra.Command {
+	revertCmd := &cobra.Command{
+		Use:   "revert [flags] [workflow-file...]",
+		Short: "Move jobs from ubuntu-slim back to ubuntu-latest",
+		Long: `Replace runs-on: ubuntu-slim with ubuntu-latest to back out a migration that
+caused failures. All jobs running on ubuntu-slim are reverted unless --job
+selects some of them. Canary jobs (see 'gh slimify promote') are only reverted
+when selected with --job.
+
+By default, you must specify workflow file(s) to process. Use --all to process
+all workflows in .github/workflows/*.yml.`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runRevert,
+	}
+	revertCmd.Flags().StringSliceVar(&revertJobs, "job", nil, "Only revert the job(s) with the given job ID(s)")
+	registerWorkflowCompletion(revertCmd)
+	return revertCmd
+}
+
+func runRevert(cmd *cobra.Command, args []string) {
+	if err := readonly.Check("revert workflows"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	files := workflowArgs(args)
+
+	if !scanAll && len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no workflow files specified. Use --all to process all workflows, or specify workflow file(s) as arguments or with --file flag.\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify revert .github/workflows/ci.yml\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify revert --all\n")
+		os.Exit(1)
+	}
+
+	var workflows []*workflow.Workflow
+	if scanAll {
+		var err error
+		workflows, err = workflow.LoadWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load workflows: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, path := range files {
+			wf, err := workflow.LoadWorkflow(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to load workflow %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			workflows = append(workflows, wf)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range revertJobs {
+		selected[id] = true
+	}
+
+	reverted := 0
+	errorCount := 0
+	var entries []state.Entry
+	for _, wf := range workflows {
+		canaries := make(map[string]bool)
+		for _, c := range scan.FindCanaries(wf) {
+			canaries[c.JobID] = true
+		}
+
+		var jobIDs []string
+		for _, id := range wf.JobIDs() {
+			if !wf.Jobs[id].IsUbuntuSlim() {
+				continue
+			}
+			if (len(selected) > 0 && !selected[id]) || (len(selected) == 0 && canaries[id]) {
+				continue
+			}
+			jobIDs = append(jobIDs, id)
+		}
+		if len(jobIDs) == 0 {
+			continue
+		}
+
+		printf("Reverting %s\n", wf.Path)
+		if err := guard.CheckWorkflowPath(wf.Path, workflow.Dir()); err != nil {
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+			errorCount += len(jobIDs)
+			printLine()
+			continue
+		}
+		failed, err := workflow.ReplaceRunnerLabel(wf.Path, jobIDs, workflow.TargetLabel(), workflow.SourceLabel())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+			errorCount += len(jobIDs)
+			printLine()
+			continue
+		}
+		for _, id := range jobIDs {
+			job := wf.Jobs[id]
+			if err := failed[id]; err != nil {
+				fmt.Fprintf(os.Stderr, "  Error reverting job %s (ID: %s): %v\n", job.Name, id, err)
+				errorCount++
+				continue
+			}
+			printf("  ✓ Reverted job \"%s\" (L%d) → %s\n", job.Name, job.LineStart, workflow.SourceLabel())
+			entries = append(entries, state.Entry{Workflow: wf.Path, JobID: id, JobName: job.Name, From: workflow.TargetLabel(), To: workflow.SourceLabel()})
+			reverted++
+		}
+		printLine()
+	}
+
+	if reverted == 0 && errorCount == 0 {
+		printf("No jobs on %s found to revert.\n", workflow.TargetLabel())
+		return
+	}
+	recordChanges("revert", entries)
+	printf("Successfully reverted %d job(s) to %s.\n", reverted, workflow.SourceLabel())
+	if errorCount > 0 {
+		fmt.Fprintf(os.Stderr, "Encountered %d error(s) during revert.\n", errorCount)
+		os.Exit(1)
+	}
+}
@@ -1,64 +1,450 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/guard"
+	"github.com/fchimpan/gh-slimify/internal/logging"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
 	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/state"
 	"github.com/fchimpan/gh-slimify/internal/workflow"
 	"github.com/spf13/cobra"
 )
 
 var (
 	workflowFiles []string
+	workflowsDir  string
+	excludeFiles  []string
+	excludeJobs   []string
+	runnerFrom    string
+	runnerTo      string
+	armMode       bool
 	scanAll       bool
 	skipDuration  bool
 	verbose       bool
+	logLevel      string
+	logFormat     string
+	logFile       string
 	force         bool
+
+	retryUnknown   bool
+	resolveActions bool
+	resolveVars    bool
+	billing        bool
+	deadline       time.Duration
+	maxDuration    time.Duration
+	maxWorkers     int
+	durationRuns   int
+	runBranch      string
+	runEvent       string
+
+	suggestServices     bool
+	excludeCredentialed bool
+
+	configPath string
+	commitFix  bool
+	openPR     bool
+	noWrite    bool
+	fixMatrix  bool
+	fixCanary  bool
+	fixBackup  bool
+	fixJobs    []string
+	fixSetup   bool
+
+	skipPreflight bool
+
+	decisionLog string
+
+	repoSpec string
+	noGit    bool
+	noCache  bool
+
+	statePath string
+	stateDir  string
+
+	disabledRules []string
+	enabledRules  []string
+
+	strict        bool
+	checkMigrated bool
+	failOn        string
+
+	quiet       bool
+	summaryOnly bool
 )
 
 func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "slimify [flags] [workflow-file...]",
+		Use:   "slimify [flags] [workflow-file... | -]",
 		Short: "Scan GitHub Actions workflows for ubuntu-slim migration candidates",
 		Long: `slimify is a GitHub CLI extension that automatically detects and safely migrates
 eligible ubuntu-latest jobs to ubuntu-slim.
 
 By default, you must specify workflow file(s) to process. Use --all to scan all
-workflows in .github/workflows/*.yml.`,
+workflows in .github/workflows/*.yml. Directories and glob patterns (quote them
+so the shell does not expand them, e.g., 'ci/**/*.yml') are expanded to the
+workflow files they contain. Use - to scan a single workflow read from standard
+input (e.g., an unsaved editor buffer).`,
 		Run: runScan,
 		Args: cobra.ArbitraryArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			level := logLevel
+			if level == "" && verbose {
+				level = "debug"
+			}
+			if err := logging.Setup(logging.Options{Level: level, Format: logFormat, File: logFile}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			setupTerminal()
+			setupWorkflowsDir()
+			if armMode && !cmd.Flags().Changed("to") {
+				runnerTo = workflow.DefaultArmLabel
+			}
+			if err := workflow.SetRunnerLabels(runnerFrom, runnerTo); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if noWrite {
+				readonly.Enable()
+			}
+			if noGit {
+				git.Disable()
+			}
+			if noCache {
+				api.DisableResponseCache()
+			}
+			if stateDir != "" {
+				cache.SetRoot(stateDir)
+			}
+			loadCommands()
+			if repoSpec != "" {
+				if err := api.SetRepository(repoSpec); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			setupRef()
+		},
 	}
 
-	rootCmd.PersistentFlags().StringArrayVarP(&workflowFiles, "file", "f", []string{}, "Specify workflow file(s) to process. Can be specified multiple times (e.g., -f .github/workflows/ci.yml -f .github/workflows/test.yml)")
-	rootCmd.PersistentFlags().BoolVar(&scanAll, "all", false, "Scan all workflow files in .github/workflows/*.yml")
+	rootCmd.PersistentFlags().StringArrayVarP(&workflowFiles, "file", "f", []string{}, "Specify workflow file(s) to process. Can be specified multiple times (e.g., -f .github/workflows/ci.yml -f .github/workflows/test.yml), and accepts directories and glob patterns")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeFiles, "exclude-file", nil, "Leave out workflow files matching this glob pattern, matched against the file name if it has no slash (e.g., --exclude-file 'release-*.yml'). Can be specified multiple times")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeJobs, "exclude-job", nil, "Report the job as ignored instead of migrating it, as <workflow>:<job-id> where the workflow may be a glob pattern (e.g., --exclude-job deploy.yml:production). Can be specified multiple times")
+	rootCmd.PersistentFlags().StringVar(&runnerFrom, "from", workflow.DefaultSourceLabel, "Runner label to migrate jobs from: only jobs running on it are evaluated (e.g., ubuntu-24.04)")
+	rootCmd.PersistentFlags().StringVar(&runnerTo, "to", workflow.DefaultTargetLabel, "Runner label to migrate jobs to (e.g., an organization's own ubuntu-slim-internal label)")
+	rootCmd.PersistentFlags().BoolVar(&armMode, "arm", false, "Evaluate jobs for an ARM runner instead of ubuntu-slim (x86-only downloads, container images without arm64 variants), and migrate them to "+workflow.DefaultArmLabel+" unless --to is given")
+	rootCmd.PersistentFlags().BoolVar(&scanAll, "all", false, "Scan all workflow files in .github/workflows/*.yml (or the directory given with --workflows-dir)")
+	rootCmd.PersistentFlags().StringVar(&workflowsDir, "workflows-dir", "", "Directory scanned for workflow files instead of .github/workflows (e.g., .gitea/workflows). Overrides workflows_dir of the config file")
 	rootCmd.PersistentFlags().BoolVar(&skipDuration, "skip-duration", false, "Skip fetching job execution durations from GitHub API to avoid unnecessary API calls")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output including debug warnings")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output including debug warnings (same as --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Lowest level of diagnostics logged: debug, info, warn, or error (default warn)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Format of diagnostics: text, or json for one JSON object per line")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Replace emojis and symbols with plain markers (e.g., [ok], [warn], [x]) for terminals and log systems that mangle Unicode")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append diagnostics to this file instead of standard error")
+	rootCmd.PersistentFlags().BoolVar(&retryUnknown, "retry-unknown", false, "Reuse durations cached by previous scans and only re-attempt lookups that resolved to unknown")
+	rootCmd.PersistentFlags().BoolVar(&billing, "billing", false, "Look up the billable minutes of each eligible job over the last 30 days and estimate the monthly savings of migrating it")
+	rootCmd.PersistentFlags().BoolVar(&resolveActions, "resolve-actions", false, "Look up action.yml of third-party actions via GitHub API and treat jobs using Docker-based actions as ineligible")
+	rootCmd.PersistentFlags().BoolVar(&resolveVars, "resolve-variables", false, "Look up the repository and organization variables runs-on is set to (runs-on: ${{ vars.NAME }}) via GitHub API and evaluate the jobs on a variable set to ubuntu-latest")
+	rootCmd.PersistentFlags().DurationVar(&deadline, "deadline", 0, "Stop network lookups (durations, remote actions) when the scan exceeds this time (e.g., 60s) and report the jobs left unanalyzed")
+	rootCmd.PersistentFlags().IntVar(&maxWorkers, "max-workers", scan.DefaultMaxWorkers, "Number of job durations fetched from GitHub API concurrently")
+	rootCmd.PersistentFlags().StringVar(&runBranch, "branch", "", "Only look up job durations in runs on this branch (e.g., the default branch)")
+	rootCmd.PersistentFlags().StringVar(&runEvent, "event", "", "Only look up job durations in runs triggered by this event (e.g., push, pull_request, schedule)")
+	rootCmd.PersistentFlags().DurationVar(&maxDuration, "max-duration", 0, "Demote safe jobs whose execution time exceeds this duration (e.g., 15m) to requiring attention, since long CPU-bound jobs may not benefit from ubuntu-slim (default: max_duration in the config file)")
+	rootCmd.PersistentFlags().IntVar(&durationRuns, "runs", 1, "Number of latest successful runs to fetch job durations from, reporting the median, p90 and standard deviation (at most 100)")
+	rootCmd.PersistentFlags().BoolVar(&noWrite, "no-write", false, "Disable every code path that modifies files or creates remote resources (also enabled by "+readonly.EnvVar+"=1)")
+	rootCmd.PersistentFlags().StringVar(&repoSpec, "repo", "", "Repository to query for durations and actions as [HOST/]OWNER/REPO, instead of the origin remote (also set by "+api.RepoEnvVar+")")
+	rootCmd.PersistentFlags().StringVar(&scanRef, "ref", "", "Read workflow files from this git ref (branch, tag, or SHA) instead of the working tree, with the contents API under --no-git. Implies --no-write")
+	rootCmd.PersistentFlags().BoolVar(&noGit, "no-git", false, "Never run git (the repository must be given with --repo or "+api.RepoEnvVar+", and fix refuses to modify files it cannot check are tracked)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the cache of GitHub API responses, revalidated with ETags, in the cache directory")
+	rootCmd.PersistentFlags().StringVar(&statePath, "state-file", state.DefaultPath, "File recording the runner changes made by fix, apply, revert, and promote (empty to disable)")
+	rootCmd.PersistentFlags().StringVar(&stateDir, "state-dir", "", "Store caches and state in this directory instead of the user cache and state directories (XDG_CACHE_HOME, XDG_STATE_HOME)")
+	rootCmd.PersistentFlags().StringVar(&commandsFile, "commands-file", "", "Detect missing commands with this command dataset instead of the built-in one or the one written by 'gh slimify db update'")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", config.DefaultPath, "Path to the gh-slimify configuration file")
+	rootCmd.PersistentFlags().StringSliceVar(&disabledRules, "disable-rule", nil, "Disable rule(s) by ID or name (e.g., --disable-rule SLIM007,credentials). Overrides the config file")
+	rootCmd.PersistentFlags().StringSliceVar(&enabledRules, "enable-rule", nil, "Enable rule(s) disabled in the config file, by ID or name")
+	rootCmd.PersistentFlags().StringVar(&decisionLog, "decision-log", "", "Write one JSON record per job with every rule evaluated, its result, and evidence to the given path")
+	rootCmd.Flags().BoolVar(&suggestServices, "suggest-services", false, "Suggest docker-free alternatives for jobs that cannot migrate because of service containers")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Exit with status 1 if any workflow file fails to load")
+	rootCmd.Flags().BoolVar(&checkMigrated, "check-migrated", false, "Check the jobs already on ubuntu-slim against the migration rules again, and exit with status 1 if any no longer meets them (e.g., a docker build step was added since)")
+	rootCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with status 1 if any job has a finding of this severity or above: error, warning, or info (severities can be changed in the rules.severity section of the config file)")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the final counts instead of every job (e.g., in pre-commit hooks)")
+	rootCmd.Flags().BoolVar(&summaryOnly, "summary", false, "Print one line of counts per workflow instead of every job, followed by the final counts")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, json to save the scan result (e.g., for 'gh slimify compare'), table for one row per job (tab-separated when piped), dot or mermaid for the graph of the jobs' needs: colored by eligibility, or template to render it with --template")
+	rootCmd.Flags().BoolVar(&changedOnly, "changed", false, "Scan only the workflows added or modified since the base ref (e.g., in pull request checks)")
+	rootCmd.Flags().StringVar(&changedBase, "base", "", "Base ref of --changed (default: origin/$GITHUB_BASE_REF in pull requests, else the default branch of origin)")
+	rootCmd.Flags().BoolVar(&checkRun, "check-run", false, "Create a check run with an annotation on each job, shown in the Checks tab of pull requests (requires a GitHub App token such as GITHUB_TOKEN)")
+	rootCmd.Flags().BoolVar(&githubAction, "github-action", false, "Run as the gh-slimify GitHub Action: read inputs from INPUT_* environment variables and write step outputs and a job summary")
+	rootCmd.Flags().BoolVar(&workspace, "workspace", false, "Treat the arguments as repository root paths and scan every workflow of each, with a combined report keyed by repository")
+	rootCmd.Flags().StringVar(&templatePath, "template", "", "Go template (text/template) file the scan result is rendered through with --output template")
 
 	fixCmd := &cobra.Command{
 		Use:   "fix [flags] [workflow-file...]",
 		Short: "Automatically update workflows to use ubuntu-slim",
 		Long: `Replace runs-on: ubuntu-latest with ubuntu-slim for safe jobs that meet
 all migration criteria. By default, only safe jobs (no missing commands and known execution time)
-are updated. Use --force to also update jobs with warnings.
+are updated. Use --force to also update jobs with warnings, and --job to only
+update some jobs (e.g., --job lint).
+
+Use --matrix to keep exercising both runners instead of switching: runs-on
+becomes ${{ matrix.runner }} and strategy.matrix.runner: [ubuntu-latest, ubuntu-slim]
+is added to each job. Use --canary to try ubuntu-slim next to the current runner:
+each job is duplicated as <job>-slim, running on ubuntu-slim with
+continue-on-error: true, until 'gh slimify promote' makes the copy authoritative.
+
+Each workflow is written atomically, keeping its permissions and line endings.
+If a workflow cannot be updated, the workflows already updated are rolled back.
+Use --backup to keep a copy of each modified workflow (ci.yml.bak).
+
+Before writing anything, fix checks with the GitHub API that the repository can
+run jobs on the target runner (GitHub Actions enabled, GitHub-hosted runners
+available, or a runner with the label for other labels) and fails otherwise.
+Use --skip-preflight to skip the check.
+
+Use --add-setup to insert steps installing the missing commands of jobs at the
+top of them: an apt-get install step for commands with a known package (e.g.,
+lsof, zstd), and the setup action of toolchains (e.g., actions/setup-java).
+Jobs whose missing commands are all installed this way are updated as safe jobs.
 
 By default, you must specify workflow file(s) to process. Use --all to scan all
-workflows in .github/workflows/*.yml.`,
+workflows in .github/workflows/*.yml. Directories and glob patterns (quote them
+so the shell does not expand them, e.g., 'ci/**/*.yml') are expanded to the
+workflow files they contain.`,
 		Run: runFix,
 		Args: cobra.ArbitraryArgs,
 	}
 	fixCmd.Flags().BoolVar(&force, "force", false, "Also update jobs with warnings (missing commands or unknown execution time)")
+	fixCmd.Flags().BoolVar(&commitFix, "commit", false, "Create a branch and commit the updated workflows (branch name and message come from the templates in the config file)")
+	fixCmd.Flags().BoolVar(&openPR, "pr", false, "Like --commit, then push the branch and open a pull request with gh")
+	fixCmd.Flags().BoolVar(&excludeCredentialed, "exclude-credentialed", false, "Skip credentialed jobs (using secrets or OIDC tokens) so they can be migrated after manual review")
+	fixCmd.Flags().BoolVar(&strict, "strict", false, "Do not update anything if any workflow file fails to load")
+	fixCmd.Flags().BoolVar(&fixBackup, "backup", false, "Keep a copy of each modified workflow next to it (e.g., ci.yml"+workflow.BackupSuffix+")")
+	fixCmd.Flags().StringSliceVar(&fixJobs, "job", nil, "Only update the job(s) with the given job ID(s) (e.g., --job lint to migrate one job at a time)")
+	fixCmd.Flags().BoolVar(&fixSetup, "add-setup", false, "Insert steps installing missing commands with a known installer (apt-get install or a setup action) at the top of jobs, so that they can be updated as safe jobs")
+	fixCmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Do not check that the repository can run jobs on the target runner before updating workflows")
+	fixCmd.Flags().BoolVar(&fixMatrix, "matrix", false, "Run jobs on both ubuntu-latest and ubuntu-slim with a strategy.matrix instead of switching them to ubuntu-slim")
+	fixCmd.Flags().BoolVar(&fixCanary, "canary", false, "Duplicate jobs as <job>-slim canaries running on ubuntu-slim with continue-on-error instead of switching them (see promote)")
+
+	registerWorkflowCompletion(rootCmd)
+	registerWorkflowCompletion(fixCmd)
+	rootCmd.RegisterFlagCompletionFunc("file", completeWorkflowFiles)
+	rootCmd.RegisterFlagCompletionFunc("exclude-file", completeWorkflowFiles)
+	rootCmd.RegisterFlagCompletionFunc("exclude-job", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeWorkflowJob(cmd, nil, toComplete)
+	})
+	rootCmd.RegisterFlagCompletionFunc("workflows-dir", cobra.FixedCompletions(nil, cobra.ShellCompDirectiveFilterDirs))
+	rootCmd.RegisterFlagCompletionFunc("disable-rule", completeRules)
+	rootCmd.RegisterFlagCompletionFunc("enable-rule", completeRules)
+	rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{logging.FormatText, logging.FormatJSON}, cobra.ShellCompDirectiveNoFileComp))
 
 	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(newDurationsCmd())
+	rootCmd.AddCommand(newAuditForeignCmd())
+	rootCmd.AddCommand(newExplainCmd())
+	rootCmd.AddCommand(newWhyCmd())
+	rootCmd.AddCommand(newSimulateCmd())
+	rootCmd.AddCommand(newPromoteCmd())
+	rootCmd.AddCommand(newRevertCmd())
+	rootCmd.AddCommand(newVerifyCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newMonitorCmd())
+	rootCmd.AddCommand(newCacheCmd())
+	rootCmd.AddCommand(newCompareCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newPlanCmd())
+	rootCmd.AddCommand(newApplyCmd())
+	rootCmd.AddCommand(newPrioritizeCmd())
+	rootCmd.AddCommand(newCommentCmd())
+	rootCmd.AddCommand(newMCPCmd())
+	rootCmd.AddCommand(newDocsCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newDBCmd())
+	rootCmd.AddCommand(newCommandsCmd())
+	rootCmd.AddCommand(newGraphCmd())
+	rootCmd.AddCommand(newEnforceCmd())
 	return rootCmd
 }
 
+// scanOptions builds scan options from the global flags and the rules
+// enabled by the configuration file, and adds the configured container tools
+// and script inputs to the catalogs
+func scanOptions(cfg *config.Config) (scan.Options, error) {
+	if maxWorkers < 1 {
+		return scan.Options{}, fmt.Errorf("--max-workers must be at least 1")
+	}
+	if durationRuns < 1 || durationRuns > 100 {
+		return scan.Options{}, fmt.Errorf("--runs must be between 1 and 100")
+	}
+	if armMode && billing {
+		return scan.Options{}, fmt.Errorf("--billing estimates ubuntu-slim savings and is not supported with --arm")
+	}
+	if maxDuration < 0 {
+		return scan.Options{}, fmt.Errorf("--max-duration must not be negative")
+	}
+	rules, err := ruleSet(cfg)
+	if err != nil {
+		return scan.Options{}, err
+	}
+	for _, t := range cfg.ContainerTools {
+		tool := workflow.ContainerTool{Name: t.Name, Commands: t.Commands, Actions: t.Actions}
+		if err := workflow.AddContainerTools(tool); err != nil {
+			return scan.Options{}, fmt.Errorf("invalid config %s: %w", configPath, err)
+		}
+	}
+	for _, in := range cfg.ScriptInputs {
+		input := workflow.ScriptInput{Action: in.Action, Inputs: in.Inputs}
+		if err := workflow.AddScriptInputs(input); err != nil {
+			return scan.Options{}, fmt.Errorf("invalid config %s: script_inputs: %w", configPath, err)
+		}
+	}
+	limits := workflow.FootprintLimits{HeavyPaths: cfg.Footprint.HeavyPaths, HeavyCommands: cfg.Footprint.HeavyCommands}
+	if cfg.Footprint.MaxHeap != "" {
+		if limits.MaxHeap, err = workflow.ParseSize(cfg.Footprint.MaxHeap); err != nil {
+			return scan.Options{}, fmt.Errorf("invalid config %s: footprint: max_heap: %w", configPath, err)
+		}
+	}
+	if err := workflow.SetFootprintLimits(limits); err != nil {
+		return scan.Options{}, fmt.Errorf("invalid config %s: footprint: %w", configPath, err)
+	}
+	return scan.Options{
+		SkipDuration:     skipDuration,
+		RetryUnknown:     retryUnknown,
+		ResolveActions:   resolveActions,
+		ResolveVariables: resolveVars,
+		Usage:            billing,
+		Pricing:          pricing(cfg),
+		Rules:            rules,
+		MaxWorkers:       maxWorkers,
+		Runs:             durationRuns,
+		RunFilter:        runFilter(),
+		Deadline:         deadline,
+		FormerJobNames:   cfg.RenamedJobs,
+		ExcludeFiles:     excludeFiles,
+		ExcludeJobs:      excludeJobs,
+		MaxDuration:      maxDurationOption(cfg),
+		CheckMigrated:    checkMigrated,
+	}, nil
+}
+
+// setupWorkflowsDir sets the directory scanned for workflows from
+// --workflows-dir, or else from the config file. An invalid config file is
+// left to be reported by the command loading it.
+func setupWorkflowsDir() {
+	dir := workflowsDir
+	if dir == "" {
+		if cfg, err := config.Load(configPath); err == nil {
+			dir = cfg.WorkflowsDir
+		}
+	}
+	workflow.SetDir(dir)
+}
+
+// workflowArgs returns the workflow files given as arguments and with --file,
+// with directories and glob patterns expanded to the workflow files they contain
+func workflowArgs(args []string) []string {
+	if slices.Contains(args, workflow.StdinPath) || slices.Contains(workflowFiles, workflow.StdinPath) {
+		fmt.Fprintf(os.Stderr, "Error: reading a workflow from standard input (-) is only supported when scanning\n")
+		os.Exit(1)
+	}
+	files, err := workflow.ExpandPaths(append(append([]string{}, args...), workflowFiles...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return files
+}
+
+// ruleSet selects the enabled rules: the config file's rules are applied
+// first, then --disable-rule and --enable-rule
+func ruleSet(cfg *config.Config) (*scan.RuleSet, error) {
+	rules := scan.NewRuleSet()
+	if armMode {
+		rules.SetMode(scan.ModeArm)
+	}
+	if err := rules.Disable(cfg.Rules.Disable...); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+	if err := rules.Enable(cfg.Rules.Enable...); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+	ids := make([]string, 0, len(cfg.Rules.Severity))
+	for id := range cfg.Rules.Severity {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		severity, err := scan.ParseSeverity(cfg.Rules.Severity[id])
+		if err == nil {
+			err = rules.SetSeverity(id, severity)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid config %s: rules: severity: %s: %w", configPath, id, err)
+		}
+	}
+	if err := rules.Disable(disabledRules...); err != nil {
+		return nil, fmt.Errorf("invalid --disable-rule: %w", err)
+	}
+	if err := rules.Enable(enabledRules...); err != nil {
+		return nil, fmt.Errorf("invalid --enable-rule: %w", err)
+	}
+	return rules, nil
+}
+
 func runScan(cmd *cobra.Command, args []string) {
-	// Collect workflow files from args and --file flag
+	if githubAction {
+		runGitHubAction()
+		return
+	}
+	if workspace {
+		runWorkspace(args)
+		return
+	}
+
+	// Collect workflow files from args and --file flag, expanding directories
+	// and glob patterns. "-" reads a single workflow from standard input.
 	var files []string
-	files = append(files, args...)
-	files = append(files, workflowFiles...)
+	fromStdin := slices.Contains(args, workflow.StdinPath) || slices.Contains(workflowFiles, workflow.StdinPath)
+	if fromStdin {
+		if len(args)+len(workflowFiles) > 1 || scanAll || changedOnly {
+			fmt.Fprintf(os.Stderr, "Error: - reads a single workflow from standard input and cannot be combined with other workflow files, --all, or --changed\n")
+			os.Exit(1)
+		}
+		files = []string{workflow.StdinPath}
+	} else {
+		files = workflowArgs(args)
+	}
+
+	// With --changed, scan only the workflows changed since the base ref
+	if changedOnly {
+		if scanAll || len(files) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --changed selects the workflows to scan and cannot be combined with --all or workflow files\n")
+			os.Exit(1)
+		}
+		changed, base, err := changedWorkflows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(changed) == 0 {
+			fmt.Fprintf(os.Stderr, "No workflow files changed since %s.\n", base)
+			os.Exit(0)
+		}
+		files = changed
+	}
 
 	// If --all is specified, use empty slice to scan all workflows
 	// Otherwise, require at least one file to be specified
@@ -77,12 +463,145 @@ func runScan(cmd *cobra.Command, args []string) {
 		filesToScan = files
 	}
 
-	result, err := scan.Scan(skipDuration, verbose, filesToScan...)
+	if err := checkScanOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if quiet && summaryOnly {
+		fmt.Fprintf(os.Stderr, "Error: --quiet and --summary cannot be combined\n")
+		os.Exit(1)
+	}
+	if failOn != "" {
+		severity, err := scan.ParseSeverity(failOn)
+		if err != nil || severity == scan.SeverityOff {
+			fmt.Fprintf(os.Stderr, "Error: invalid --fail-on %q: expected error, warning, or info\n", failOn)
+			os.Exit(1)
+		}
+		failOn = string(severity)
+	}
+	if (quiet || summaryOnly) && outputFormat != outputText {
+		fmt.Fprintf(os.Stderr, "Error: --quiet and --summary only apply to --output %s\n", outputText)
+		os.Exit(1)
+	}
+	var outputTmpl *template.Template
+	if outputFormat == outputTemplate {
+		tmpl, err := parseOutputTemplate(templatePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outputTmpl = tmpl
+	}
+
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if fromStdin {
+		// A workflow from standard input has no runs to look up durations in
+		opts.SkipDuration = true
+	}
+
+	result, err := scan.ScanWithOptions(opts, filesToScan...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if decisionLog != "" {
+		if err := writeDecisionLog(decisionLog, "scan", result, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if checkRun {
+		if err := createCheckRun(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if outputTmpl != nil {
+		// Render fully first so that a failing template prints nothing
+		var buf bytes.Buffer
+		if err := outputTmpl.Execute(&buf, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to render --template: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(buf.Bytes())
+		if strict && len(result.LoadErrors) > 0 {
+			os.Exit(1)
+		}
+		exitOnFailures(result)
+		return
+	}
+
+	if outputFormat == outputDot || outputFormat == outputMermaid {
+		if err := writeScanGraph(os.Stdout, result, outputFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if printLoadErrors(result) && strict {
+			os.Exit(1)
+		}
+		exitOnFailures(result)
+		return
+	}
+
+	if outputFormat == outputTable {
+		if err := writeScanTable(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if printLoadErrors(result) && strict {
+			os.Exit(1)
+		}
+		exitOnFailures(result)
+		return
+	}
+
+	if outputFormat == outputJSON {
+		if err := result.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if strict && len(result.LoadErrors) > 0 {
+			os.Exit(1)
+		}
+		exitOnFailures(result)
+		return
+	}
+
+	if printScanResult(result) && strict {
+		os.Exit(1)
+	}
+	exitOnFailures(result)
+}
 
+// exitOnFailures exits with status 1 if --check-migrated found migrated jobs
+// that no longer meet the migration criteria, or if --fail-on found findings
+// of its severity or above
+func exitOnFailures(result *scan.ScanResult) {
+	if checkMigrated && brokenMigratedJobs(result.Migrated) > 0 {
+		os.Exit(1)
+	}
+	if failOn != "" && result.Findings(scan.Severity(failOn)) > 0 {
+		os.Exit(1)
+	}
+}
+
+// printScanResult prints the scan result as text, grouped by workflow file,
+// followed by a summary. With --summary, each workflow file is one line of
+// counts, and with --quiet, only the summary is printed. It returns true if
+// some workflow files could not be loaded.
+func printScanResult(result *scan.ScanResult) bool {
 	candidates := result.Candidates
 	ineligibleJobs := result.IneligibleJobs
 
@@ -98,6 +617,24 @@ func runScan(cmd *cobra.Command, args []string) {
 		ineligibleMap[job.WorkflowPath] = append(ineligibleMap[job.WorkflowPath], job)
 	}
 
+	// Group canaries by workflow file
+	canaryMap := make(map[string][]*scan.Canary)
+	for _, c := range result.Canaries {
+		canaryMap[c.WorkflowPath] = append(canaryMap[c.WorkflowPath], c)
+	}
+
+	// Group migrated jobs by workflow file
+	migratedMap := make(map[string][]*scan.MigratedJob)
+	for _, job := range result.Migrated {
+		migratedMap[job.WorkflowPath] = append(migratedMap[job.WorkflowPath], job)
+	}
+
+	// Group ignored jobs by workflow file
+	ignoredMap := make(map[string][]*scan.IgnoredJob)
+	for _, job := range result.Ignored {
+		ignoredMap[job.WorkflowPath] = append(ignoredMap[job.WorkflowPath], job)
+	}
+
 	// Display results grouped by workflow file
 	allWorkflowPaths := make(map[string]bool)
 	for path := range workflowMap {
@@ -106,9 +643,38 @@ func runScan(cmd *cobra.Command, args []string) {
 	for path := range ineligibleMap {
 		allWorkflowPaths[path] = true
 	}
+	for path := range canaryMap {
+		allWorkflowPaths[path] = true
+	}
+	for path := range migratedMap {
+		allWorkflowPaths[path] = true
+	}
+	for path := range ignoredMap {
+		allWorkflowPaths[path] = true
+	}
+	workflowPaths := make([]string, 0, len(allWorkflowPaths))
+	for path := range allWorkflowPaths {
+		workflowPaths = append(workflowPaths, path)
+	}
+	sort.Strings(workflowPaths)
 
-	for workflowPath := range allWorkflowPaths {
-		fmt.Printf("\n📄 %s\n", workflowPath)
+	readiness := result.Readiness
+	if summaryOnly && len(workflowPaths) > 0 {
+		printLine()
+	}
+	for _, workflowPath := range workflowPaths {
+		if quiet {
+			break
+		}
+		if summaryOnly {
+			printWorkflowSummary(workflowPath, workflowMap[workflowPath], len(ineligibleMap[workflowPath]), migratedMap[workflowPath], len(canaryMap[workflowPath]), len(ignoredMap[workflowPath]), readiness.Workflow(workflowPath))
+			continue
+		}
+		if r := readiness.Workflow(workflowPath); r != nil {
+			printf("\n📄 %s (readiness: %s)\n", workflowPath, formatReadiness(r))
+		} else {
+			printf("\n📄 %s\n", workflowPath)
+		}
 		jobs := workflowMap[workflowPath]
 
 		// Separate safe jobs and jobs with warnings
@@ -117,14 +683,7 @@ func runScan(cmd *cobra.Command, args []string) {
 		var safeJobs []*scan.Candidate
 		var warningJobs []*scan.Candidate
 		for _, job := range jobs {
-			duration := job.Duration
-			if duration == "" {
-				duration = "unknown"
-			}
-			hasMissingCommands := len(job.MissingCommands) > 0
-			hasUnknownDuration := duration == "unknown"
-
-			if hasMissingCommands || hasUnknownDuration {
+			if job.HasWarnings() {
 				warningJobs = append(warningJobs, job)
 			} else {
 				safeJobs = append(safeJobs, job)
@@ -133,17 +692,26 @@ func runScan(cmd *cobra.Command, args []string) {
 
 		// Display safe jobs first
 		if len(safeJobs) > 0 {
-			fmt.Printf("  ✅ Safe to migrate (%d job(s)):\n", len(safeJobs))
+			printf("  ✅ Safe to migrate (%d job(s)):\n", len(safeJobs))
 			for _, job := range safeJobs {
 				jobLink := formatLocalLink(workflowPath, job.LineNumber)
-				fmt.Printf("     • \"%s\" (L%d) - Last execution time: %s\n", job.JobName, job.LineNumber, job.Duration)
-				fmt.Printf("       %s\n", jobLink)
+				printf("     • \"%s\" (L%d) - %s\n", job.JobName, job.LineNumber, formatJobDuration(job))
+				if infos := ruleInfos(job.Rules); len(infos) > 0 {
+					printf("       ℹ️  %s\n", strings.Join(infos, ", "))
+				}
+				if job.IsCredentialed() {
+					printf("       🔐 Credentialed: %s\n", withRuleID(strings.Join(job.Credentials, ", "), scan.RuleCredentials))
+				}
+				printUsage(job.Usage)
+				printCalledBy(job.CalledBy)
+				printDownstream(job.Downstream)
+				printf("       %s\n", jobLink)
 			}
 		}
 
 		// Display jobs with warnings
 		if len(warningJobs) > 0 {
-			fmt.Printf("  ⚠️  Can migrate but requires attention (%d job(s)):\n", len(warningJobs))
+			printf("  ⚠️  Can migrate but requires attention (%d job(s)):\n", len(warningJobs))
 			for _, job := range warningJobs {
 				duration := job.Duration
 				if duration == "" {
@@ -152,20 +720,42 @@ func runScan(cmd *cobra.Command, args []string) {
 				jobLink := formatLocalLink(workflowPath, job.LineNumber)
 
 				// Build warning reasons in a single line
+				// Cloud CLIs come first: they are the most common breakage
 				var reasons []string
-				if len(job.MissingCommands) > 0 {
-					commandsStr := ""
-					for i, cmd := range job.MissingCommands {
-						if i > 0 {
-							commandsStr += ", "
-						}
-						commandsStr += cmd
-					}
-					reasons = append(reasons, fmt.Sprintf("Setup may be required (%s)", commandsStr))
+				if len(job.CloudCLIs) > 0 {
+					reasons = append(reasons, withRuleID(scan.CloudCLIsReason(job.CloudCLIs), scan.RuleCloudCLIs))
+				}
+				if others := nonCloudCommands(job); len(others) > 0 {
+					reasons = append(reasons, withRuleID(scan.MissingCommandsReason(others), scan.RuleMissingCommands))
+				}
+				if len(job.ToolchainVersions) > 0 {
+					reasons = append(reasons, withRuleID(scan.ToolchainVersionsReason(job.ToolchainVersions), scan.RuleToolchainVersions))
 				}
-				if duration == "unknown" {
+				if job.HasUnknownDuration() {
 					reasons = append(reasons, "Last execution time: unknown")
 				}
+				if job.LongRunning {
+					reasons = append(reasons, durationWarning(job.Rules))
+				}
+				if len(job.ManualServices) > 0 {
+					reasons = append(reasons, withRuleID(scan.ManualServicesReason(job.ManualServices), scan.RuleManualServices))
+				}
+				for _, id := range []string{scan.RuleDiskFootprint, scan.RuleMemoryFootprint} {
+					if reason := ruleWarning(job.Rules, id); reason != "" {
+						reasons = append(reasons, withRuleID(reason, id))
+					}
+				}
+				if len(job.Unenriched) > 0 {
+					reasons = append(reasons, fmt.Sprintf("Not analyzed before the deadline: %s", strings.Join(job.Unenriched, ", ")))
+				}
+				if job.Ignore != nil {
+					reasons = append(reasons, formatExpiredIgnore(job.Ignore))
+				}
+				if len(job.ContainerImages) > 0 {
+					reasons = append(reasons, withRuleID(fmt.Sprintf("Images must provide arm64 variants (%s)", strings.Join(job.ContainerImages, ", ")), scan.RuleContainerImages))
+				}
+				// Rules whose severity is set to warning in the config file
+				reasons = append(reasons, otherRuleWarnings(job.Rules)...)
 
 				warningMsg := ""
 				if len(reasons) > 0 {
@@ -175,35 +765,98 @@ func runScan(cmd *cobra.Command, args []string) {
 					}
 				}
 
-				fmt.Printf("     • \"%s\" (L%d)\n", job.JobName, job.LineNumber)
+				printf("     • \"%s\" (L%d)\n", job.JobName, job.LineNumber)
 				if warningMsg != "" {
-					fmt.Printf("       ⚠️  %s\n", warningMsg)
+					printf("       ⚠️  %s\n", warningMsg)
+					printEvidenceLines(job.Rules, scan.ResultWarn)
+				}
+				if infos := ruleInfos(job.Rules); len(infos) > 0 {
+					printf("       ℹ️  %s\n", strings.Join(infos, ", "))
 				}
 				if duration != "unknown" {
-					fmt.Printf("       Last execution time: %s\n", duration)
+					printf("       %s\n", formatJobDuration(job))
+				}
+				if job.IsCredentialed() {
+					printf("       🔐 Credentialed: %s\n", withRuleID(strings.Join(job.Credentials, ", "), scan.RuleCredentials))
 				}
-				fmt.Printf("       %s\n", jobLink)
+				printUsage(job.Usage)
+				printCalledBy(job.CalledBy)
+				printDownstream(job.Downstream)
+				printf("       %s\n", jobLink)
 			}
 		}
 
 		// Display ineligible jobs
 		ineligibleJobsForWorkflow := ineligibleMap[workflowPath]
 		if len(ineligibleJobsForWorkflow) > 0 {
-			fmt.Printf("  ❌ Cannot migrate (%d job(s)):\n", len(ineligibleJobsForWorkflow))
+			printf("  ❌ Cannot migrate (%d job(s)):\n", len(ineligibleJobsForWorkflow))
 			for _, job := range ineligibleJobsForWorkflow {
 				jobLink := formatLocalLink(workflowPath, job.LineNumber)
 				reasonsStr := ""
 				if len(job.Reasons) > 0 {
-					reasonsStr = job.Reasons[0]
+					reasonsStr = withRuleID(job.Reasons[0], job.ReasonRuleID(0))
 					for i := 1; i < len(job.Reasons); i++ {
-						reasonsStr += ", " + job.Reasons[i]
+						reasonsStr += ", " + withRuleID(job.Reasons[i], job.ReasonRuleID(i))
 					}
 				}
-				fmt.Printf("     • \"%s\" (L%d)\n", job.JobName, job.LineNumber)
+				printf("     • \"%s\" (L%d)\n", job.JobName, job.LineNumber)
 				if reasonsStr != "" {
-					fmt.Printf("       ❌ %s\n", reasonsStr)
+					printf("       ❌ %s\n", reasonsStr)
+					printEvidenceLines(job.Rules, scan.ResultFail)
+				}
+				if suggestServices {
+					for _, s := range job.ServiceSuggestions {
+						printf("       💡 %s: %s\n", formatServiceLabel(s), strings.Join(s.Alternatives, ", or "))
+					}
 				}
-				fmt.Printf("       %s\n", jobLink)
+				printCalledBy(job.CalledBy)
+				printf("       %s\n", jobLink)
+			}
+		}
+
+		// Display canaries next to the ubuntu-latest jobs they duplicate
+		if canaries := canaryMap[workflowPath]; len(canaries) > 0 {
+			printf("  🐤 Already testing %s (%d job(s)):\n", workflow.TargetLabel(), len(canaries))
+			for _, c := range canaries {
+				printf("     • \"%s\" (L%d) - canary of \"%s\" (L%d)\n", c.JobName, c.LineNumber, c.TwinName, c.TwinLine)
+				printf("       %s\n", formatLocalLink(workflowPath, c.LineNumber))
+			}
+		}
+
+		// Display jobs already migrated, with the rules they no longer meet
+		// when checked with --check-migrated
+		if migrated := migratedMap[workflowPath]; len(migrated) > 0 {
+			printf("  ✔ Already on %s (%d job(s)):\n", workflow.TargetLabel(), len(migrated))
+			for _, job := range migrated {
+				printf("     • \"%s\" (L%d)\n", job.JobName, job.LineNumber)
+				if job.IsBroken() {
+					reasons := make([]string, len(job.Reasons))
+					for i, reason := range job.Reasons {
+						reasons[i] = withRuleID(reason, job.ReasonRuleID(i))
+					}
+					printf("       ❌ No longer meets the migration criteria: %s\n", strings.Join(reasons, ", "))
+					printEvidenceLines(job.Rules, scan.ResultFail)
+				} else if warnings := ruleWarnings(job.Rules); len(warnings) > 0 {
+					printf("       ⚠️  %s\n", strings.Join(warnings, ", "))
+					printEvidenceLines(job.Rules, scan.ResultWarn)
+				}
+				printf("       %s\n", formatLocalLink(workflowPath, job.LineNumber))
+			}
+		}
+
+		// Display jobs hidden by slimify:ignore, with when the directive expires
+		if ignored := ignoredMap[workflowPath]; len(ignored) > 0 {
+			printf("  🙈 Ignored (%d job(s)):\n", len(ignored))
+			for _, job := range ignored {
+				until := "no expiry"
+				if job.Directive.Until != "" {
+					until = "until " + job.Directive.Until
+				}
+				printf("     • \"%s\" (L%d) - %s\n", job.JobName, job.LineNumber, until)
+				if job.Directive.Reason != "" {
+					printf("       Reason: %s\n", job.Directive.Reason)
+				}
+				printf("       %s\n", formatLocalLink(workflowPath, job.Directive.Line))
 			}
 		}
 	}
@@ -211,16 +864,13 @@ func runScan(cmd *cobra.Command, args []string) {
 	// Summary
 	safeCount := 0
 	warningCount := 0
+	credentialedCount := 0
 	for _, jobs := range workflowMap {
 		for _, job := range jobs {
-			duration := job.Duration
-			if duration == "" {
-				duration = "unknown"
+			if job.IsCredentialed() {
+				credentialedCount++
 			}
-			hasMissingCommands := len(job.MissingCommands) > 0
-			hasUnknownDuration := duration == "unknown"
-
-			if hasMissingCommands || hasUnknownDuration {
+			if job.HasWarnings() {
 				warningCount++
 			} else {
 				safeCount++
@@ -228,29 +878,77 @@ func runScan(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	fmt.Println()
+	if !quiet {
+		printLine()
+	}
 	if safeCount > 0 {
-		fmt.Printf("✅ %d job(s) can be safely migrated\n", safeCount)
+		printf("✅ %d job(s) can be safely migrated\n", safeCount)
 	}
 	if warningCount > 0 {
-		fmt.Printf("⚠️  %d job(s) can be migrated but require attention\n", warningCount)
+		printf("⚠️  %d job(s) can be migrated but require attention\n", warningCount)
+	}
+	if credentialedCount > 0 {
+		printf("🔐 %d eligible job(s) are credentialed and may require manual review\n", credentialedCount)
 	}
 	if len(ineligibleJobs) > 0 {
-		fmt.Printf("❌ %d job(s) cannot be migrated\n", len(ineligibleJobs))
+		printf("❌ %d job(s) cannot be migrated\n", len(ineligibleJobs))
 	}
 	if len(candidates) > 0 {
-		fmt.Printf("📊 Total: %d job(s) eligible for migration\n", len(candidates))
+		printf("📊 Total: %d job(s) eligible for migration\n", len(candidates))
+	}
+	if len(result.Canaries) > 0 {
+		printf("🐤 %d canary job(s) already test %s with continue-on-error\n", len(result.Canaries), workflow.TargetLabel())
+	}
+	if len(result.Migrated) > 0 {
+		printf("✔ %d job(s) already on %s\n", len(result.Migrated), workflow.TargetLabel())
+	}
+	if broken := brokenMigratedJobs(result.Migrated); broken > 0 {
+		printf("❌ %d migrated job(s) no longer meet the migration criteria\n", broken)
 	}
-	if len(candidates) == 0 && len(ineligibleJobs) == 0 {
-		fmt.Println("No jobs found that can be safely migrated to ubuntu-slim.")
+	if readiness != nil && readiness.EligibleJobs > 0 {
+		printf("📈 Slim readiness: %s\n", formatReadiness(&readiness.Readiness))
 	}
+	if len(result.Ignored) > 0 {
+		printf("🙈 %d job(s) ignored with slimify:ignore or --exclude-job\n", len(result.Ignored))
+	}
+	if len(candidates) == 0 && len(ineligibleJobs) == 0 && len(result.Canaries) == 0 && len(result.Migrated) == 0 && len(result.Ignored) == 0 {
+		printf("No jobs found that can be safely migrated to %s.\n", workflow.TargetLabel())
+	}
+	if quiet || summaryOnly {
+		printDeadlineExceeded(result)
+		printRateLimited(result)
+		return printLoadErrors(result)
+	}
+	if warningCount > 0 || credentialedCount > 0 || len(ineligibleJobs) > 0 || brokenMigratedJobs(result.Migrated) > 0 {
+		printLine("💡 Run 'gh slimify explain <rule-id>' to learn what a rule checks and how to resolve it")
+	}
+	if len(result.Canaries) > 0 {
+		printLine("💡 Run 'gh slimify promote' to remove their ubuntu-latest twins and make the canaries authoritative")
+	}
+	printSavings(candidates)
+	printLabelTypos(result)
+	printDeadlineExceeded(result)
+	printRateLimited(result)
+	return printLoadErrors(result)
 }
 
 func runFix(cmd *cobra.Command, args []string) {
-	// Collect workflow files from args and --file flag
-	var files []string
-	files = append(files, args...)
-	files = append(files, workflowFiles...)
+	if err := readonly.Check("fix workflows"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if fixSetup && armMode {
+		fmt.Fprintf(os.Stderr, "Error: --add-setup installs commands missing in ubuntu-slim and is not supported with --arm\n")
+		os.Exit(1)
+	}
+	if fixMatrix && fixCanary {
+		fmt.Fprintf(os.Stderr, "Error: --matrix and --canary cannot be used together\n")
+		os.Exit(1)
+	}
+
+	// Collect workflow files from args and --file flag, expanding directories
+	// and glob patterns
+	files := workflowArgs(args)
 
 	// If --all is specified, use empty slice to scan all workflows
 	// Otherwise, require at least one file to be specified
@@ -269,122 +967,394 @@ func runFix(cmd *cobra.Command, args []string) {
 		filesToScan = files
 	}
 
-	result, err := scan.Scan(skipDuration, verbose, filesToScan...)
+	// Load templates and rules before modifying anything so that mistakes fail fast
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	result, err := scan.ScanWithOptions(opts, filesToScan...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if printLoadErrors(result) {
+		if strict {
+			fmt.Fprintf(os.Stderr, "Error: no workflow was updated (--strict)\n")
+			os.Exit(1)
+		}
+		printLine()
+	}
+
+	// Record what was done with each job. The log is written on return and
+	// before exiting with an error.
+	actions := make(map[string]string)
+	logDecisions := func() {
+		if decisionLog == "" {
+			return
+		}
+		if err := writeDecisionLog(decisionLog, "fix", result, actions); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	defer logDecisions()
+
 	candidates := result.Candidates
+	if len(fixJobs) > 0 {
+		candidates = selectFixJobs(candidates)
+	}
+	printDeadlineExceeded(result)
+	printRateLimited(result)
 
 	if len(candidates) == 0 {
-		fmt.Println("No jobs found that can be safely migrated to ubuntu-slim.")
+		printf("No jobs found that can be safely migrated to %s.\n", workflow.TargetLabel())
 		return
 	}
 
-	// Filter candidates based on force flag
-	// Safe jobs: no missing commands AND execution time is known
-	// Warning jobs: missing commands OR execution time is unknown
-	var jobsToUpdate []*scan.Candidate
-	var skippedJobs []*scan.Candidate
+	// Plan the steps installing missing commands first, so that the jobs
+	// whose commands are all installed are selected as safe jobs
+	var setupSteps map[string][]workflow.SetupStep
+	if fixSetup {
+		setupSteps = planSetupSteps(candidates)
+	}
 
-	for _, job := range candidates {
-		duration := job.Duration
-		if duration == "" {
-			duration = "unknown"
-		}
-		hasMissingCommands := len(job.MissingCommands) > 0
-		hasUnknownDuration := duration == "unknown"
+	jobsToUpdate, skippedJobs, credentialedJobs := selectJobs(candidates)
+	for _, job := range credentialedJobs {
+		actions[decisionKey(job.WorkflowPath, job.JobID)] = actionSkipped
+	}
+	for _, job := range skippedJobs {
+		actions[decisionKey(job.WorkflowPath, job.JobID)] = actionSkipped
+	}
 
-		if hasMissingCommands || hasUnknownDuration {
-			if force {
-				jobsToUpdate = append(jobsToUpdate, job)
-			} else {
-				skippedJobs = append(skippedJobs, job)
-			}
-		} else {
-			jobsToUpdate = append(jobsToUpdate, job)
-		}
+	if len(credentialedJobs) > 0 {
+		printf("Skipping %d credentialed job(s) that require manual review.\n", len(credentialedJobs))
 	}
 
 	if len(jobsToUpdate) == 0 {
 		if len(skippedJobs) > 0 {
-			fmt.Printf("No safe jobs to update. %d job(s) have warnings and were skipped.\n", len(skippedJobs))
-			fmt.Println("Use --force to update jobs with warnings.")
+			printf("No safe jobs to update. %d job(s) have warnings and were skipped.\n", len(skippedJobs))
+			printLine("Use --force to update jobs with warnings.")
 		} else {
-			fmt.Println("No jobs found that can be safely migrated to ubuntu-slim.")
+			printf("No jobs found that can be safely migrated to %s.\n", workflow.TargetLabel())
 		}
 		return
 	}
 
+	from, to := workflow.SourceLabel(), workflow.TargetLabel()
+	if !skipPreflight {
+		preflightRunner(to)
+	}
+	target := to
+	if fixMatrix {
+		target = fmt.Sprintf("a runner matrix of %s and %s", from, to)
+	}
+	if fixCanary {
+		target = fmt.Sprintf("canaries on %s", to)
+	}
 	if force {
-		fmt.Println("Updating workflows to use ubuntu-slim (including jobs with warnings)...")
+		printf("Updating workflows to use %s (including jobs with warnings)...\n", target)
 	} else {
-		fmt.Println("Updating workflows to use ubuntu-slim (safe jobs only)...")
+		printf("Updating workflows to use %s (safe jobs only)...\n", target)
 		if len(skippedJobs) > 0 {
-			fmt.Printf("Skipping %d job(s) with warnings. Use --force to update them.\n", len(skippedJobs))
+			printf("Skipping %d job(s) with warnings. Use --force to update them.\n", len(skippedJobs))
 		}
 	}
-	fmt.Println()
+	printLine()
 
-	// Group jobs by workflow file
+	// Group jobs by workflow file, processed in path order
 	workflowMap := make(map[string][]*scan.Candidate)
 	for _, c := range jobsToUpdate {
 		workflowMap[c.WorkflowPath] = append(workflowMap[c.WorkflowPath], c)
 	}
+	workflowPaths := make([]string, 0, len(workflowMap))
+	for path := range workflowMap {
+		workflowPaths = append(workflowPaths, path)
+	}
+	sort.Strings(workflowPaths)
 
-	updatedCount := 0
+	var updatedJobs []*scan.Candidate
 	errorCount := 0
 
-	// Update each workflow file
-	for workflowPath, jobs := range workflowMap {
-		fmt.Printf("Updating %s\n", workflowPath)
+	// Files are tracked before they are written, so that a file that cannot be
+	// updated rolls back the files updated before it
+	batch := workflow.NewBatch(fixBackup)
+	fileFailed := false
+	failFile := func(jobs []*scan.Candidate, err error) {
+		fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
+		errorCount += len(jobs)
 		for _, job := range jobs {
-			// Reload workflow to get current state
-			wf, err := workflow.LoadWorkflow(workflowPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "  Error loading workflow %s: %v\n", workflowPath, err)
-				errorCount++
-				continue
-			}
+			actions[decisionKey(job.WorkflowPath, job.JobID)] = actionFailed
+		}
+		fileFailed = true
+		printLine()
+	}
+
+	// Update each workflow file
+	for _, workflowPath := range workflowPaths {
+		jobs := workflowMap[workflowPath]
+		printf("Updating %s\n", workflowPath)
+		if err := guard.CheckWorkflowPath(workflowPath, workflow.Dir()); err != nil {
+			failFile(jobs, err)
+			break
+		}
+
+		// Reload workflow to get current state
+		wf, err := workflow.LoadWorkflow(workflowPath)
+		if err != nil {
+			failFile(jobs, fmt.Errorf("failed to load workflow %s: %w", workflowPath, err))
+			break
+		}
 
-			// Verify job still exists and is eligible
+		// Verify jobs still exist, then update all of them in a single write
+		// (pass job IDs, not names, since UpdateRunsOnJobs and AddRunnerMatrix match by job ID)
+		var existing []*scan.Candidate
+		var jobIDs []string
+		for _, job := range jobs {
 			if _, ok := wf.Jobs[job.JobID]; !ok {
 				fmt.Fprintf(os.Stderr, "  Warning: job %s (ID: %s) not found in %s\n", job.JobName, job.JobID, workflowPath)
+				actions[decisionKey(job.WorkflowPath, job.JobID)] = actionSkipped
 				continue
 			}
+			existing = append(existing, job)
+			jobIDs = append(jobIDs, job.JobID)
+		}
+		if err := batch.Track(workflowPath); err != nil {
+			failFile(existing, err)
+			break
+		}
+		if len(setupSteps) > 0 {
+			existing, jobIDs, err = addSetupSteps(workflowPath, existing, setupSteps, actions, &errorCount)
+			if err != nil {
+				failFile(existing, err)
+				break
+			}
+		}
+		update := workflow.UpdateRunsOnJobs
+		if fixMatrix {
+			update = workflow.AddRunnerMatrix
+		}
+		if fixCanary {
+			update = workflow.AddCanaryJobs
+		}
+		failed, err := update(workflowPath, jobIDs, to)
+		if err != nil {
+			failFile(existing, err)
+			break
+		}
 
-			// Update runs-on value (pass jobID, not jobName, since UpdateRunsOn matches by job ID)
-			if err := workflow.UpdateRunsOn(workflowPath, job.JobID, "ubuntu-slim"); err != nil {
+		for _, job := range existing {
+			if err := failed[job.JobID]; err != nil {
 				fmt.Fprintf(os.Stderr, "  Error updating job %s (ID: %s) in %s: %v\n", job.JobName, job.JobID, workflowPath, err)
 				errorCount++
+				actions[decisionKey(job.WorkflowPath, job.JobID)] = actionFailed
 				continue
 			}
 
 			// Show warning indicator if job has warnings
-			duration := job.Duration
-			if duration == "" {
-				duration = "unknown"
+			runner := to
+			if fixMatrix {
+				runner = fmt.Sprintf("matrix [%s, %s]", from, to)
 			}
-			hasMissingCommands := len(job.MissingCommands) > 0
-			hasUnknownDuration := duration == "unknown"
-
-			if hasMissingCommands || hasUnknownDuration {
-				fmt.Printf("  ⚠️  Updated job \"%s\" (L%d) → ubuntu-slim (with warnings)\n", job.JobName, job.LineNumber)
+			if fixCanary {
+				runner = fmt.Sprintf("canary %s%s on %s", job.JobID, workflow.CanarySuffix, to)
+			}
+			if job.HasWarnings() {
+				printf("  ⚠️  Updated job \"%s\" (L%d) → %s (with warnings)\n", job.JobName, job.LineNumber, runner)
 			} else {
-				fmt.Printf("  ✓ Updated job \"%s\" (L%d) → ubuntu-slim\n", job.JobName, job.LineNumber)
+				printf("  ✓ Updated job \"%s\" (L%d) → %s\n", job.JobName, job.LineNumber, runner)
 			}
-			updatedCount++
+			for _, step := range setupSteps[decisionKey(job.WorkflowPath, job.JobID)] {
+				installer := step.Uses
+				if installer == "" {
+					installer = "apt-get install"
+				}
+				printf("    + Installs %s with %s\n", strings.Join(step.Commands, ", "), installer)
+			}
+			updatedJobs = append(updatedJobs, job)
+			actions[decisionKey(job.WorkflowPath, job.JobID)] = actionUpdated
+		}
+		printLine()
+	}
+
+	// A file that could not be updated leaves every workflow unchanged
+	if fileFailed {
+		if err := batch.Rollback(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to roll back updated workflows: %v\n", err)
+		} else {
+			if len(updatedJobs) > 0 {
+				fmt.Fprintf(os.Stderr, "Rolled back %d updated job(s). No workflow was modified.\n", len(updatedJobs))
+			}
+			for _, job := range updatedJobs {
+				actions[decisionKey(job.WorkflowPath, job.JobID)] = actionRolledBack
+			}
+			updatedJobs = nil
 		}
-		fmt.Println()
 	}
 
 	// Summary
-	fmt.Printf("Successfully updated %d job(s) to use ubuntu-slim.\n", updatedCount)
+	printf("Successfully updated %d job(s) to use %s.\n", len(updatedJobs), target)
+	if backups := batch.Backups(); len(backups) > 0 {
+		printf("Backups of the original workflows: %s\n", strings.Join(backups, ", "))
+	}
+	if fixCanary && len(updatedJobs) > 0 {
+		printf("💡 Once the canaries succeed, run 'gh slimify promote' to remove the %s jobs they duplicate\n", from)
+	}
 	if errorCount > 0 {
 		fmt.Fprintf(os.Stderr, "Encountered %d error(s) during update.\n", errorCount)
+		logDecisions()
 		os.Exit(1)
 	}
+
+	// Record the changes, and commit the state file with the workflows
+	recorded := to
+	if fixMatrix {
+		recorded = fmt.Sprintf("matrix(%s, %s)", from, to)
+	}
+	if fixCanary {
+		recorded = fmt.Sprintf("canary(%s)", to)
+	}
+	var entries []state.Entry
+	for _, job := range updatedJobs {
+		entries = append(entries, state.Entry{Workflow: job.WorkflowPath, JobID: job.JobID, JobName: job.JobName, From: from, To: recorded})
+	}
+	var extra []string
+	if recordChanges("fix", entries) {
+		extra = append(extra, statePath)
+	}
+
+	if (commitFix || openPR) && len(updatedJobs) > 0 {
+		if err := commitFixes(cfg, updatedJobs, openPR, extra...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logDecisions()
+			os.Exit(1)
+		}
+	}
+}
+
+// planSetupSteps plans the steps installing the missing commands of
+// candidates, keyed by decisionKey, and leaves only the commands without a
+// known installer in their MissingCommands
+func planSetupSteps(candidates []*scan.Candidate) map[string][]workflow.SetupStep {
+	planned := make(map[string][]workflow.SetupStep)
+	for _, c := range candidates {
+		if len(c.MissingCommands) == 0 {
+			continue
+		}
+		steps, unknown := workflow.PlanSetupSteps(c.MissingCommands)
+		if len(steps) == 0 {
+			continue
+		}
+		planned[decisionKey(c.WorkflowPath, c.JobID)] = steps
+		c.MissingCommands = unknown
+		c.CloudCLIs = filterCommands(c.CloudCLIs, unknown, true)
+	}
+	return planned
+}
+
+// nonCloudCommands returns the missing commands of a candidate that are not
+// cloud CLIs
+func nonCloudCommands(c *scan.Candidate) []string {
+	return filterCommands(c.MissingCommands, c.CloudCLIs, false)
+}
+
+// filterCommands returns the commands whose presence in set is in
+func filterCommands(commands, set []string, in bool) []string {
+	member := make(map[string]bool, len(set))
+	for _, cmd := range set {
+		member[cmd] = true
+	}
+	var result []string
+	for _, cmd := range commands {
+		if member[cmd] == in {
+			result = append(result, cmd)
+		}
+	}
+	return result
+}
+
+// addSetupSteps inserts the planned setup steps of jobs in a workflow, and
+// returns the jobs that can still be updated with their IDs. Jobs whose steps
+// cannot be inserted are reported as errors.
+func addSetupSteps(workflowPath string, jobs []*scan.Candidate, planned map[string][]workflow.SetupStep, actions map[string]string, errorCount *int) ([]*scan.Candidate, []string, error) {
+	steps := make(map[string][]workflow.SetupStep)
+	for _, job := range jobs {
+		if s, ok := planned[decisionKey(job.WorkflowPath, job.JobID)]; ok {
+			steps[job.JobID] = s
+		}
+	}
+	failed := make(map[string]error)
+	if len(steps) > 0 {
+		var err error
+		if failed, err = workflow.AddSetupSteps(workflowPath, steps); err != nil {
+			return jobs, nil, err
+		}
+	}
+
+	var remaining []*scan.Candidate
+	var jobIDs []string
+	for _, job := range jobs {
+		if err := failed[job.JobID]; err != nil {
+			fmt.Fprintf(os.Stderr, "  Error adding setup steps to job %s (ID: %s) in %s: %v\n", job.JobName, job.JobID, workflowPath, err)
+			*errorCount++
+			actions[decisionKey(job.WorkflowPath, job.JobID)] = actionFailed
+			continue
+		}
+		remaining = append(remaining, job)
+		jobIDs = append(jobIDs, job.JobID)
+	}
+	return remaining, jobIDs, nil
+}
+
+// selectFixJobs returns the candidates selected with --job, warning about
+// job IDs that are not candidates (e.g., ineligible jobs or typos)
+func selectFixJobs(candidates []*scan.Candidate) []*scan.Candidate {
+	selected := make(map[string]bool)
+	for _, id := range fixJobs {
+		selected[id] = true
+	}
+	found := make(map[string]bool)
+	var filtered []*scan.Candidate
+	for _, c := range candidates {
+		if selected[c.JobID] {
+			filtered = append(filtered, c)
+			found[c.JobID] = true
+		}
+	}
+	for _, id := range fixJobs {
+		if !found[id] {
+			eprintf("⚠️  Job %q is not a migration candidate in the given workflows\n", id)
+			found[id] = true
+		}
+	}
+	return filtered
+}
+
+// selectJobs splits candidates into the jobs to update, the jobs with warnings
+// skipped without --force, and the credentialed jobs skipped with
+// --exclude-credentialed
+// Safe jobs: no missing commands AND execution time is known
+// Warning jobs: missing commands OR execution time is unknown
+func selectJobs(candidates []*scan.Candidate) (toUpdate, skipped, credentialed []*scan.Candidate) {
+	for _, job := range candidates {
+		if excludeCredentialed && job.IsCredentialed() {
+			credentialed = append(credentialed, job)
+			continue
+		}
+
+		if job.HasWarnings() && !force {
+			skipped = append(skipped, job)
+		} else {
+			toUpdate = append(toUpdate, job)
+		}
+	}
+	return toUpdate, skipped, credentialed
 }
 
 // formatLocalLink formats a local file link with line number
@@ -414,3 +1384,345 @@ func formatLocalLink(filePath string, lineNumber int) string {
 
 	return fmt.Sprintf("%s:%d", relPath, lineNumber)
 }
+
+// withRuleID appends the ID of the rule that produced a reason, if any
+func withRuleID(reason, ruleID string) string {
+	if ruleID == "" {
+		return reason
+	}
+	return fmt.Sprintf("%s [%s]", reason, ruleID)
+}
+
+// durationWarning returns the reason of the duration rule (e.g., an execution
+// time above --max-duration)
+func durationWarning(rules []scan.RuleResult) string {
+	for _, r := range rules {
+		if r.Rule == "duration" && r.Result == scan.ResultWarn {
+			return r.Reason
+		}
+	}
+	return ""
+}
+
+// otherRuleWarnings returns the reasons, with their rule IDs, of the rules
+// that warned about the job but have no dedicated warning (e.g., rules whose
+// severity is set to warning in the config file)
+func otherRuleWarnings(rules []scan.RuleResult) []string {
+	reported := map[string]bool{
+		scan.RuleCloudCLIs:         true,
+		scan.RuleMissingCommands:   true,
+		scan.RuleToolchainVersions: true,
+		scan.RuleManualServices:    true,
+		scan.RuleDiskFootprint:     true,
+		scan.RuleMemoryFootprint:   true,
+		scan.RuleContainerImages:   true,
+	}
+	var warnings []string
+	for _, r := range rules {
+		if r.Result != scan.ResultWarn || reported[r.ID] || r.Rule == "duration" || r.Rule == "ignore" {
+			continue
+		}
+		warnings = append(warnings, withRuleID(r.Reason, r.ID))
+	}
+	return warnings
+}
+
+// ruleInfos returns the reasons, with their rule IDs, of the rules whose
+// findings are only informational (e.g., with the info severity in the config
+// file). Credentials and the execution time are reported on their own line.
+func ruleInfos(rules []scan.RuleResult) []string {
+	var infos []string
+	for _, r := range rules {
+		if r.Result == scan.ResultInfo && r.ID != scan.RuleCredentials && r.Rule != "duration" {
+			infos = append(infos, withRuleID(r.Reason, r.ID))
+		}
+	}
+	return infos
+}
+
+// ruleWarning returns the reason of the rule with the given ID if it warned
+// about the job, or ""
+func ruleWarning(rules []scan.RuleResult, id string) string {
+	for _, r := range rules {
+		if r.ID == id && r.Result == scan.ResultWarn {
+			return r.Reason
+		}
+	}
+	return ""
+}
+
+// formatExpiredIgnore describes the expired or invalid slimify:ignore
+// directive of a job that no longer hides it
+func formatExpiredIgnore(d *workflow.IgnoreDirective) string {
+	if d.Error != "" {
+		return fmt.Sprintf("Invalid slimify:ignore (L%d): %s", d.Line, d.Error)
+	}
+	msg := fmt.Sprintf("slimify:ignore expired on %s (L%d)", d.Until, d.Line)
+	if d.Reason != "" {
+		msg += ": " + d.Reason
+	}
+	return msg
+}
+
+// pricing returns the price per minute of the runners, from the config file
+// or the defaults
+func pricing(cfg *config.Config) scan.Pricing {
+	p := scan.DefaultPricing
+	if cfg.Pricing.UbuntuLatest > 0 {
+		p.UbuntuLatest = cfg.Pricing.UbuntuLatest
+	}
+	if cfg.Pricing.UbuntuSlim > 0 {
+		p.UbuntuSlim = cfg.Pricing.UbuntuSlim
+	}
+	if cfg.Pricing.UbuntuArm > 0 {
+		p.UbuntuArm = cfg.Pricing.UbuntuArm
+	}
+	if cfg.Pricing.Larger > 0 {
+		p.Larger = cfg.Pricing.Larger
+	}
+	return p
+}
+
+// maxDurationOption returns --max-duration, or else max_duration from the config file
+func maxDurationOption(cfg *config.Config) time.Duration {
+	if maxDuration > 0 {
+		return maxDuration
+	}
+	return cfg.MaxDuration
+}
+
+// runFilter returns the workflow runs selected with --branch and --event
+func runFilter() api.RunFilter {
+	return api.RunFilter{Branch: runBranch, Event: runEvent}
+}
+
+// formatJobDuration describes the execution time of a job: the last one, or
+// its statistics over the runs sampled with --runs
+func formatJobDuration(job *scan.Candidate) string {
+	if s := job.DurationStats; s != nil {
+		return fmt.Sprintf("Median execution time: %s (p90 %s, stddev %s over %d runs)", job.Duration, s.P90, s.StdDev, s.Runs)
+	}
+	if job.Duration == "" {
+		return "Last execution time: unknown"
+	}
+	return fmt.Sprintf("Last execution time: %s", job.Duration)
+}
+
+// printLoadErrors reports the workflow files that could not be loaded, all
+// together, and returns whether there were any
+func printLoadErrors(result *scan.ScanResult) bool {
+	if len(result.LoadErrors) == 0 {
+		return false
+	}
+	eprintf("\n⚠️  %d workflow file(s) could not be loaded:\n", len(result.LoadErrors))
+	for _, e := range result.LoadErrors {
+		eprintf("   • %s\n", e.Path)
+		fmt.Fprintf(os.Stderr, "     %s\n", e.Error)
+	}
+	return true
+}
+
+// printUsage prints the billable minutes and estimated monthly cost of a
+// job looked up with --billing
+func printUsage(u *scan.Usage) {
+	if u == nil {
+		return
+	}
+	printf("       💰 %d billable minute(s) in %d run(s) over 30 days: ~$%.2f/month, ~$%.2f/month on ubuntu-slim\n", u.BillableMinutes, u.Runs, u.Cost, u.SlimCost)
+}
+
+// ruleWarnings returns the reasons of the rules that warned, with their IDs
+func ruleWarnings(rules []scan.RuleResult) []string {
+	var warnings []string
+	for _, r := range rules {
+		if r.Result == scan.ResultWarn {
+			warnings = append(warnings, withRuleID(r.Reason, r.ID))
+		}
+	}
+	return warnings
+}
+
+// brokenMigratedJobs returns the number of migrated jobs that no longer
+// meet the migration criteria (with --check-migrated)
+func brokenMigratedJobs(jobs []*scan.MigratedJob) int {
+	broken := 0
+	for _, job := range jobs {
+		if job.IsBroken() {
+			broken++
+		}
+	}
+	return broken
+}
+
+// printWorkflowSummary prints the counts of a workflow file on one line, with
+// its readiness if it has eligible jobs
+func printWorkflowSummary(workflowPath string, candidates []*scan.Candidate, ineligible int, migrated []*scan.MigratedJob, canaries, ignored int, readiness *scan.Readiness) {
+	var counts []string
+	safe := 0
+	for _, c := range candidates {
+		if !c.HasWarnings() {
+			safe++
+		}
+	}
+	if safe > 0 {
+		counts = append(counts, fmt.Sprintf("✅ %d safe", safe))
+	}
+	if warnings := len(candidates) - safe; warnings > 0 {
+		counts = append(counts, fmt.Sprintf("⚠️  %d require attention", warnings))
+	}
+	if ineligible > 0 {
+		counts = append(counts, fmt.Sprintf("❌ %d cannot migrate", ineligible))
+	}
+	if len(migrated) > 0 {
+		counts = append(counts, fmt.Sprintf("✔ %d migrated", len(migrated)))
+	}
+	if broken := brokenMigratedJobs(migrated); broken > 0 {
+		counts = append(counts, fmt.Sprintf("❌ %d migrated no longer eligible", broken))
+	}
+	if canaries > 0 {
+		counts = append(counts, fmt.Sprintf("🐤 %d canary", canaries))
+	}
+	if ignored > 0 {
+		counts = append(counts, fmt.Sprintf("🙈 %d ignored", ignored))
+	}
+	if readiness != nil {
+		counts = append(counts, fmt.Sprintf("📈 %s", formatReadiness(readiness)))
+	}
+	printf("📄 %s: %s\n", workflowPath, strings.Join(counts, ", "))
+}
+
+// formatReadiness formats a readiness score with its grade (e.g., "62.5%,
+// grade C")
+func formatReadiness(r *scan.Readiness) string {
+	return fmt.Sprintf("%g%%, grade %s", r.Score, r.Grade)
+}
+
+// printSavings prints the estimated monthly savings of migrating the jobs
+// looked up with --billing, and the jobs saving the most
+func printSavings(candidates []*scan.Candidate) {
+	var withUsage []*scan.Candidate
+	total := 0.0
+	for _, c := range candidates {
+		if c.Usage != nil {
+			withUsage = append(withUsage, c)
+			total += c.Usage.Savings
+		}
+	}
+	if len(withUsage) == 0 {
+		return
+	}
+	sort.SliceStable(withUsage, func(i, j int) bool {
+		return withUsage[i].Usage.Savings > withUsage[j].Usage.Savings
+	})
+
+	printf("\n💰 Migrating %d eligible job(s) would save an estimated ~$%.2f/month\n", len(withUsage), total)
+	for i, c := range withUsage {
+		if i == 5 || c.Usage.Savings <= 0 {
+			break
+		}
+		printf("  • ~$%.2f/month: \"%s\" (%s:%d)\n", c.Usage.Savings, c.JobName, c.WorkflowPath, c.LineNumber)
+	}
+	printLine("  Estimates assume the same durations on ubuntu-slim; adjust prices under pricing: in the config file.")
+}
+
+// printLabelTypos reports the runs-on labels that look like typos of known
+// runner labels
+func printLabelTypos(result *scan.ScanResult) {
+	if len(result.LabelTypos) == 0 {
+		return
+	}
+	printf("\n🔤 %d runs-on label(s) look like typos; jobs with an unknown label wait for a runner forever:\n", len(result.LabelTypos))
+	for _, t := range result.LabelTypos {
+		printf("  • \"%s\" (L%d): \"%s\" - did you mean \"%s\"?\n", t.JobName, t.LineNumber, t.Label, t.Suggestion)
+		printf("    %s\n", formatLocalLink(t.WorkflowPath, t.LineNumber))
+	}
+}
+
+// printDeadlineExceeded reports the jobs that were not fully analyzed because
+// the --deadline budget was exceeded
+func printDeadlineExceeded(result *scan.ScanResult) {
+	if !result.DeadlineExceeded {
+		return
+	}
+	count := 0
+	for _, c := range result.Candidates {
+		if len(c.Unenriched) > 0 {
+			count++
+		}
+	}
+	eprintf("⏱️  Deadline of %s exceeded: %d job(s) were not fully analyzed and are treated as requiring attention\n", deadline, count)
+}
+
+// printRateLimited reports that some durations are unknown because the GitHub
+// API rate limit was exhausted
+func printRateLimited(result *scan.ScanResult) {
+	if !result.RateLimited {
+		return
+	}
+	eprintf("⏳ GitHub API rate limit exhausted: some execution times are unknown. Run again later with --retry-unknown to look them up.\n")
+}
+
+// printEvidenceLines prints the workflow lines where the rules with the given
+// result matched (e.g., "L42: docker build -t app . [SLIM002]"), in line
+// order, once each
+func printEvidenceLines(rules []scan.RuleResult, result string) {
+	type located struct {
+		number int
+		text   string
+	}
+	var lines []located
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if r.Result != result {
+			continue
+		}
+		for _, e := range r.Evidence {
+			if e.LineNumber == 0 {
+				continue
+			}
+			text := e.Line
+			if e.Source != "" {
+				text += " (" + e.Source + ")"
+			}
+			text = withRuleID(text, r.ID)
+			if key := fmt.Sprintf("%d %s", e.LineNumber, text); !seen[key] {
+				seen[key] = true
+				lines = append(lines, located{e.LineNumber, text})
+			}
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].number < lines[j].number })
+	for _, l := range lines {
+		printf("          L%d: %s\n", l.number, l.text)
+	}
+}
+
+// printCalledBy prints the callers of a job's reusable workflow, if any
+func printCalledBy(callers []scan.Caller) {
+	if len(callers) == 0 {
+		return
+	}
+	refs := make([]string, len(callers))
+	for i, c := range callers {
+		refs[i] = c.String()
+	}
+	printf("       ↪ Called by %s\n", strings.Join(refs, ", "))
+}
+
+// printDownstream prints the jobs that need a candidate, whose timing
+// changes when it is migrated
+func printDownstream(downstream []string) {
+	if len(downstream) == 0 {
+		return
+	}
+	printf("       → Also affects the timing of downstream jobs: %s\n", strings.Join(downstream, ", "))
+}
+
+// formatServiceLabel formats a service name with its image for display
+// e.g., "db (postgres:14)" or "redis" when the name matches the image
+func formatServiceLabel(s scan.ServiceSuggestion) string {
+	if s.Image == "" || s.Image == s.Service {
+		return s.Service
+	}
+	return fmt.Sprintf("%s (%s)", s.Service, s.Image)
+}
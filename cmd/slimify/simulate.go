@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateImage    string
+	simulateManifest string
+)
+
+func newSimulateCmd() *cobra.Command {
+	simulateCmd := &cobra.Command{
+		Use:   "simulate <workflow-file> <job-id>",
+		Short: "Check the commands of a job against the ubuntu-slim image",
+		Long: `Check every command a job runs against the ubuntu-slim image itself, for
+ground-truth missing commands instead of the command dataset the scan uses.
+
+With --image, each command is looked up with command -v in a container of the
+image, run with docker (which pulls the image if needed). With --manifest, the
+commands are read from a file instead: a command dataset (see 'gh slimify db'),
+or a list of commands, one per line, such as the output of compgen -c on an
+ubuntu-slim runner.
+
+Commands provided by setup actions or installed earlier in the job are not
+checked, nor are commands run by a relative path (e.g., ./gradlew). Commands
+whose availability differs from the command dataset are pointed out.
+
+The command exits with status 1 if any command is missing.`,
+		Example: `  gh slimify simulate --image my-registry/ubuntu-slim:latest .github/workflows/ci.yml build
+  gh slimify simulate --manifest compgen.txt .github/workflows/ci.yml build`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeDurationsArgs,
+		Run:               runSimulate,
+	}
+	simulateCmd.Flags().StringVar(&simulateImage, "image", "", "Container image of ubuntu-slim to run the commands in with docker")
+	simulateCmd.Flags().StringVar(&simulateManifest, "manifest", "", "File listing the commands of ubuntu-slim (a command dataset, or one command per line)")
+	simulateCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text or json")
+	return simulateCmd
+}
+
+func runSimulate(cmd *cobra.Command, args []string) {
+	if err := checkOutputFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if (simulateImage == "") == (simulateManifest == "") {
+		fmt.Fprintf(os.Stderr, "Error: specify the ubuntu-slim image with either --image or --manifest\n")
+		os.Exit(1)
+	}
+
+	workflowPath, jobID := args[0], args[1]
+	wf, err := workflow.LoadWorkflow(workflowPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	job, ok := wf.Jobs[jobID]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: job %s not found in %s\n", jobID, workflowPath)
+		os.Exit(1)
+	}
+
+	lookup, source := scan.ImageLookup(simulateImage), "image "+simulateImage
+	if simulateManifest != "" {
+		data, err := os.ReadFile(simulateManifest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read manifest: %v\n", err)
+			os.Exit(1)
+		}
+		lookup, err = scan.ManifestLookup(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid manifest %s: %v\n", simulateManifest, err)
+			os.Exit(1)
+		}
+		source = "manifest " + simulateManifest
+	}
+
+	simulated, err := scan.Simulate(context.Background(), job, lookup)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	missing := 0
+	for _, c := range simulated {
+		if !c.Available {
+			missing++
+		}
+	}
+	if outputFormat == outputJSON {
+		if simulated == nil {
+			simulated = []scan.SimulatedCommand{}
+		}
+		if err := writeJSON(os.Stdout, simulated); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printSimulation(workflowPath, jobID, job.Name, source, simulated, missing)
+	}
+	if missing > 0 {
+		os.Exit(1)
+	}
+}
+
+// printSimulation prints the commands of a job checked against a runner image
+func printSimulation(workflowPath, jobID, jobName, source string, simulated []scan.SimulatedCommand, missing int) {
+	printf("📄 %s\n", workflowPath)
+	printf("  Job \"%s\" (%s), checked against %s:\n", jobName, jobID, source)
+	if len(simulated) == 0 {
+		printLine("  No commands to check.")
+		return
+	}
+	for _, c := range simulated {
+		e := c.Evidence
+		e.Pattern = ""
+		if c.Available {
+			printf("  ✅ %s\n", c.Command)
+		} else {
+			printf("  ❌ %s: not found (%s)\n", c.Command, formatEvidence(e))
+			if c.Installer != "" {
+				printf("     💡 Install it with %s (or gh slimify fix --add-setup)\n", c.Installer)
+			}
+		}
+		if c.Available != c.InDataset {
+			printf("     ℹ️  The command dataset has it %s on %s\n", availability(c.InDataset), workflow.ImageSlim)
+		}
+	}
+
+	printLine()
+	if missing == 0 {
+		printf("All %d command(s) are available.\n", len(simulated))
+	} else {
+		printf("%d of %d command(s) are missing.\n", missing, len(simulated))
+	}
+}
+
+// availability describes whether a command is available
+func availability(available bool) string {
+	if available {
+		return "available"
+	}
+	return "missing"
+}
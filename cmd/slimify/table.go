@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+// writeScanTable writes the scan result with one row per job, in the order
+// of the decision log (by workflow path, then line number)
+func writeScanTable(result *scan.ScanResult) error {
+	tp := newTablePrinter()
+	tp.AddHeader([]string{"WORKFLOW", "LINE", "JOB", "STATUS", "DURATION", "DETAILS"})
+	for _, d := range result.Decisions() {
+		tp.AddField(d.WorkflowPath)
+		tp.AddField(strconv.Itoa(d.LineNumber))
+		tp.AddField(d.JobName)
+		if color := statusColor(d.Status); color != "" && colorOutput {
+			tp.AddField(d.Status, tableprinter.WithColor(func(s string) string { return color + s + colorReset }))
+		} else {
+			tp.AddField(d.Status)
+		}
+		tp.AddField(decisionDuration(d))
+		tp.AddField(decisionDetails(d))
+		tp.EndRow()
+	}
+	return tp.Render()
+}
+
+// statusColor returns the color of a job status in tables, or "" if it is
+// not colored
+func statusColor(status string) string {
+	switch status {
+	case scan.StatusSafe:
+		return colorGreen
+	case scan.StatusWarning:
+		return colorYellow
+	case scan.StatusIneligible:
+		return colorRed
+	}
+	return ""
+}
+
+// decisionDuration returns the execution time looked up for a job, or "" if
+// it was not looked up or is unknown
+func decisionDuration(d *scan.Decision) string {
+	for _, r := range d.Rules {
+		if r.Rule == "duration" && len(r.Evidence) > 0 {
+			return r.Evidence[0].Line
+		}
+	}
+	return ""
+}
+
+// decisionDetails summarizes why a job is not safe to migrate: the reasons
+// of the rules it fails or warns about, with their IDs
+func decisionDetails(d *scan.Decision) string {
+	var details []string
+	for _, r := range d.Rules {
+		if r.Result != scan.ResultFail && r.Result != scan.ResultWarn {
+			continue
+		}
+		reason := r.Reason
+		if reason == "" {
+			reason = r.Rule
+		}
+		if r.ID != "" {
+			reason += " [" + r.ID + "]"
+		}
+		details = append(details, reason)
+	}
+	if d.Status == scan.StatusWarning && decisionDuration(d) == "" && len(details) == 0 {
+		// The duration lookup was skipped, so the time is unknown
+		details = append(details, "execution time unknown")
+	}
+	if d.Status == scan.StatusCanary && d.Twin != "" {
+		details = append(details, "canary of "+d.Twin)
+	}
+	return strings.Join(details, "; ")
+}
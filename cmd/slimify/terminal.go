@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+)
+
+var (
+	// asciiOutput replaces emojis and other symbols with plain markers (--ascii)
+	asciiOutput bool
+	// colorOutput colors lines by severity. It is enabled when standard output
+	// is a terminal, unless NO_COLOR is set (see go-gh's term.FromEnv).
+	colorOutput bool
+)
+
+const (
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+	colorReset  = "\x1b[0m"
+)
+
+// setupTerminal enables colors if standard output supports them
+func setupTerminal() {
+	colorOutput = term.FromEnv().IsColorEnabled()
+}
+
+// newTablePrinter returns a table printer for standard output: aligned
+// columns fitting the terminal width on terminals, tab-separated values
+// otherwise
+func newTablePrinter() tableprinter.TablePrinter {
+	t := term.FromEnv()
+	width, _, err := t.Size()
+	if err != nil || width <= 0 {
+		width = 80
+	}
+	return tableprinter.New(os.Stdout, t.IsTerminalOutput(), width)
+}
+
+// asciiMarkers replaces the symbols of the text output with plain markers
+// for terminals and log systems that mangle Unicode. Emojis followed by a
+// variation selector, and the extra space that aligns them, are listed
+// before their bare form.
+var asciiMarkers = strings.NewReplacer(
+	"⚠️  ", "[warn] ",
+	"⚠️", "[warn]",
+	"⚠", "[warn]",
+	"⏱️  ", "[deadline] ",
+	"⏱️", "[deadline]",
+	"⏭️  ", "[skip] ",
+	"⏭️", "[skip]",
+	"ℹ️  ", "[info] ",
+	"ℹ️", "[info]",
+	"ℹ", "[info]",
+	"✅", "[ok]",
+	"❌", "[x]",
+	"✓", "[ok]",
+	"✔", "[migrated]",
+	"📄", "==",
+	"📦", "==",
+	"💡", "[tip]",
+	"🔐", "[cred]",
+	"🐤", "[canary]",
+	"🙈", "[ignored]",
+	"📊", "[total]",
+	"💰", "[$]",
+	"🔤", "[typo]",
+	"✨", "[new]",
+	"🏁", "[top]",
+	"📈", "[readiness]",
+	"⏳", "[rate-limit]",
+	"❓", "[?]",
+	"🪶", "",
+	"•", "-",
+	"→", "->",
+	"↪", "<-",
+)
+
+// severityColors maps the marker a line starts with to its color
+var severityColors = []struct {
+	marker string
+	color  string
+}{
+	{"✅", colorGreen},
+	{"✓", colorGreen},
+	{"⚠", colorYellow},
+	{"❌", colorRed},
+}
+
+// decorate colors the lines of s that start with a severity marker, and
+// replaces symbols with plain markers with --ascii
+func decorate(s string, color bool) string {
+	if color {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			trimmed := strings.TrimLeft(line, " ")
+			for _, sc := range severityColors {
+				if strings.HasPrefix(trimmed, sc.marker) {
+					lines[i] = sc.color + line + colorReset
+					break
+				}
+			}
+		}
+		s = strings.Join(lines, "\n")
+	}
+	if asciiOutput {
+		s = asciiMarkers.Replace(s)
+	}
+	return s
+}
+
+// printf prints the text output to standard output, like fmt.Printf,
+// decorated for the terminal
+func printf(format string, a ...interface{}) {
+	fmt.Fprint(os.Stdout, decorate(fmt.Sprintf(format, a...), colorOutput))
+}
+
+// printLine prints the text output to standard output, like fmt.Println,
+// decorated for the terminal
+func printLine(a ...interface{}) {
+	fmt.Fprint(os.Stdout, decorate(fmt.Sprintln(a...), colorOutput))
+}
+
+// eprintf prints a message to standard error, like fmt.Fprintf, with plain
+// markers if --ascii is set. Standard error is never colored.
+func eprintf(format string, a ...interface{}) {
+	fmt.Fprint(os.Stderr, decorate(fmt.Sprintf(format, a...), false))
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/git"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// verifyPollInterval is how often verify checks the dispatched runs
+const verifyPollInterval = 15 * time.Second
+
+var (
+	verifyTimeout    time.Duration
+	verifyKeepBranch bool
+)
+
+func newVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify [flags] [workflow-file...]",
+		Short: "Run the migrated jobs on ubuntu-slim before merging the fix",
+		Long: `Check end to end that the jobs fix would migrate succeed on ubuntu-slim:
+verify commits the fix to a temporary branch (slimify-verify-<time>) and pushes
+it, without touching the working tree or the checked out branch, triggers each
+workflow on it with workflow_dispatch, waits for the runs to complete, and
+reports whether the migrated jobs succeeded. The workflow_dispatch trigger is
+added to the workflows that do not have it. The branch is deleted afterwards
+unless --keep-branch is given.
+
+Jobs are selected like fix does: safe jobs only, unless --force is given, and
+only the jobs given with --job if any. Inputs of workflow_dispatch take their
+default values, and workflows must already exist on the default branch for
+GitHub to dispatch them. Pushing the branch may also trigger workflows running
+on push.
+
+Jobs of local reusable workflows are not verified: dispatching the called
+workflow does not run them as their callers do.
+
+The command exits with status 1 if any migrated job did not succeed, including
+jobs that were skipped or did not run, since they were not verified.`,
+		Example: `  gh slimify verify .github/workflows/ci.yml
+  gh slimify verify --all --job lint --timeout 1h`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runVerify,
+	}
+	verifyCmd.Flags().BoolVar(&force, "force", false, "Also verify jobs with warnings (missing commands or unknown execution time)")
+	verifyCmd.Flags().StringSliceVar(&fixJobs, "job", nil, "Only verify the job(s) with the given job ID(s)")
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 30*time.Minute, "Time to wait for the dispatched runs to complete")
+	verifyCmd.Flags().BoolVar(&verifyKeepBranch, "keep-branch", false, "Keep the temporary branch after the runs complete")
+	registerWorkflowCompletion(verifyCmd)
+	return verifyCmd
+}
+
+// verifiedWorkflow is a workflow dispatched on the temporary branch
+type verifiedWorkflow struct {
+	path string
+	jobs []*scan.Candidate
+	run  *api.WorkflowRun
+	err  error
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	if err := readonly.Check("verify workflows"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	files := workflowArgs(args)
+	if !scanAll && len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no workflow files specified. Use --all to verify all workflows, or specify workflow file(s) as arguments or with --file flag.\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify verify .github/workflows/ci.yml\n")
+		os.Exit(1)
+	}
+	if scanAll {
+		files = []string{}
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := scan.ScanWithOptions(opts, files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if printLoadErrors(result) {
+		printLine()
+	}
+
+	candidates := result.Candidates
+	if len(fixJobs) > 0 {
+		candidates = selectFixJobs(candidates)
+	}
+	jobs, skipped, _ := selectJobs(candidates)
+	jobs = excludeCalledJobs(jobs)
+	if len(jobs) == 0 {
+		printf("No jobs to verify on %s.\n", workflow.TargetLabel())
+		if len(skipped) > 0 {
+			printf("%d job(s) have warnings. Use --force to verify them.\n", len(skipped))
+		}
+		return
+	}
+
+	workflows, content, err := verificationContent(jobs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to get repository info: %v\n", err)
+		os.Exit(1)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	parent, err := git.HeadCommit()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	branch := fmt.Sprintf("slimify-verify-%s", time.Now().UTC().Format("20060102150405"))
+	commit, err := git.CommitFiles(parent, fmt.Sprintf("Verify the migration of %d job(s) to %s", len(jobs), workflow.TargetLabel()), content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to commit the fix: %v\n", err)
+		os.Exit(1)
+	}
+	if err := git.PushCommit("origin", commit, branch); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to push branch %s: %v\n", branch, err)
+		os.Exit(1)
+	}
+	printf("Pushed the fix of %d job(s) to branch %s.\n", len(jobs), branch)
+
+	failed := dispatchAndWait(client, workflows, branch)
+
+	if verifyKeepBranch {
+		printf("Kept branch %s.\n", branch)
+	} else if err := git.DeleteRemoteBranch("origin", branch); err != nil {
+		eprintf("⚠️  Failed to delete branch %s: %v\n", branch, err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// excludeCalledJobs drops the jobs of local reusable workflows, which cannot
+// be verified: dispatching the called workflow does not run them like their
+// callers do, and their runs are named after the calling job
+func excludeCalledJobs(jobs []*scan.Candidate) []*scan.Candidate {
+	var verifiable []*scan.Candidate
+	for _, c := range jobs {
+		if len(c.CalledBy) > 0 {
+			eprintf("⚠️  Skipping \"%s\" (%s:%d): it runs in a reusable workflow called by %s and cannot be verified\n", c.JobName, c.WorkflowPath, c.LineNumber, c.CalledBy[0])
+			continue
+		}
+		verifiable = append(verifiable, c)
+	}
+	return verifiable
+}
+
+// verificationContent returns the workflows of jobs, in path order, and
+// their content with the jobs migrated and the workflow_dispatch trigger
+func verificationContent(jobs []*scan.Candidate) ([]*verifiedWorkflow, map[string][]byte, error) {
+	byPath := make(map[string]*verifiedWorkflow)
+	var workflows []*verifiedWorkflow
+	for _, c := range jobs {
+		wf := byPath[c.WorkflowPath]
+		if wf == nil {
+			wf = &verifiedWorkflow{path: c.WorkflowPath}
+			byPath[c.WorkflowPath] = wf
+			workflows = append(workflows, wf)
+		}
+		wf.jobs = append(wf.jobs, c)
+	}
+	sort.Slice(workflows, func(i, k int) bool { return workflows[i].path < workflows[k].path })
+
+	content := make(map[string][]byte)
+	for _, wf := range workflows {
+		data, err := os.ReadFile(wf.path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read file %s: %w", wf.path, err)
+		}
+		jobIDs := make([]string, len(wf.jobs))
+		for i, c := range wf.jobs {
+			jobIDs[i] = c.JobID
+		}
+		data, failed, err := workflow.ReplaceRunnerLabelContent(data, jobIDs, workflow.SourceLabel(), workflow.TargetLabel())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML %s: %w", wf.path, err)
+		}
+		for _, c := range wf.jobs {
+			if err := failed[c.JobID]; err != nil {
+				return nil, nil, fmt.Errorf("failed to update job %s in %s: %w", c.JobID, wf.path, err)
+			}
+		}
+		data, added, err := workflow.AddDispatchTrigger(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot add the workflow_dispatch trigger to %s: %w", wf.path, err)
+		}
+		if added {
+			printf("Adding the workflow_dispatch trigger to %s on the temporary branch.\n", wf.path)
+		}
+		content[wf.path] = data
+	}
+	return workflows, content, nil
+}
+
+// dispatchAndWait dispatches the workflows on branch, waits up to
+// --timeout for their runs to complete, prints the conclusion of the migrated
+// jobs, and reports whether any did not succeed
+func dispatchAndWait(client *api.Client, workflows []*verifiedWorkflow, branch string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+
+	for _, wf := range workflows {
+		wf.err = client.DispatchWorkflow(ctx, wf.path, branch)
+	}
+	printf("Waiting up to %s for the runs to complete...\n", verifyTimeout)
+	for pending := true; pending; {
+		pending = false
+		for _, wf := range workflows {
+			if wf.err != nil || (wf.run != nil && wf.run.Status == "completed") {
+				continue
+			}
+			if wf.run == nil {
+				wf.run, wf.err = client.LatestRun(ctx, wf.path, branch, "workflow_dispatch")
+			} else {
+				wf.run, wf.err = client.GetRun(ctx, wf.run.ID)
+			}
+			pending = pending || (wf.err == nil && (wf.run == nil || wf.run.Status != "completed"))
+		}
+		if !pending {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			for _, wf := range workflows {
+				if wf.err == nil && (wf.run == nil || wf.run.Status != "completed") {
+					wf.err = fmt.Errorf("the run did not complete within %s", verifyTimeout)
+				}
+			}
+			pending = false
+		case <-time.After(verifyPollInterval):
+		}
+	}
+	printLine()
+
+	failed := false
+	for _, wf := range workflows {
+		printf("📄 %s\n", wf.path)
+		if wf.run != nil {
+			printf("  Run: %s\n", wf.run.HTMLURL)
+		}
+		if wf.err != nil {
+			eprintf("  ❌ %v\n", wf.err)
+			failed = true
+			continue
+		}
+		runJobs, err := client.GetRunJobs(context.Background(), wf.run.ID)
+		if err != nil {
+			eprintf("  ❌ %v\n", err)
+			failed = true
+			continue
+		}
+		for _, c := range wf.jobs {
+			if !printVerifiedJob(c, runJobs) {
+				failed = true
+			}
+		}
+	}
+	printLine()
+	if failed {
+		printf("Some migrated jobs did not succeed on %s or were not verified.\n", workflow.TargetLabel())
+	} else {
+		printf("All migrated jobs succeeded on %s.\n", workflow.TargetLabel())
+	}
+	return failed
+}
+
+// printVerifiedJob prints the conclusion of a migrated job in a run,
+// including its matrix instances, and reports whether it succeeded. Skipped
+// jobs and jobs that did not run were not verified: they did not succeed.
+func printVerifiedJob(c *scan.Candidate, runJobs []api.RunJob) bool {
+	succeeded, found := true, false
+	for _, j := range runJobs {
+		if !api.MatchesJob(j.Name, c.JobID, c.JobName, nil) {
+			continue
+		}
+		found = true
+		switch j.Conclusion {
+		case "success":
+			printf("  ✅ \"%s\" succeeded on %s\n", j.Name, workflow.TargetLabel())
+		case "skipped":
+			printf("  ⚠️  \"%s\" was skipped and not verified (its if: condition may depend on the event)\n", j.Name)
+			succeeded = false
+		default:
+			printf("  ❌ \"%s\": %s (%s)\n", j.Name, j.Conclusion, j.HTMLURL)
+			succeeded = false
+		}
+	}
+	if !found {
+		printf("  ❓ \"%s\" (%s) did not run and was not verified\n", c.JobName, c.JobID)
+		succeeded = false
+	}
+	return succeeded
+}
@@ -0,0 +1,13 @@
+package main
+
+import "runtime/debug"
+
+// toolVersion returns the version of gh-slimify from the build information
+// (the module version, or "(devel)" for local builds)
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(devel)"
+	}
+	return info.Main.Version
+}
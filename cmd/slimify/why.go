@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+func newWhyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "why <workflow-file>:<job-id>",
+		Short: "Show how a job was classified",
+		Long: `Print the full evaluation trace for one job: each rule evaluated with the
+matched step and line, the commands extracted from its steps, the setup actions
+detected, and the duration lookup result.
+
+This is useful for debugging surprising classifications. No workflow files are modified.`,
+		Example:           "  gh slimify why .github/workflows/ci.yml:build",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeWorkflowJob,
+		Run:               runWhy,
+	}
+}
+
+// resultIcons maps rule results to the icons printed by why
+var resultIcons = map[string]string{
+	scan.ResultPass: "✅",
+	scan.ResultFail: "❌",
+	scan.ResultWarn: "⚠️ ",
+	scan.ResultInfo: "🔐",
+	scan.ResultSkip: "⏭️ ",
+}
+
+func runWhy(cmd *cobra.Command, args []string) {
+	var workflowPath, jobID string
+	if i := strings.LastIndex(args[0], ":"); i >= 0 {
+		workflowPath, jobID = args[0][:i], args[0][i+1:]
+	}
+	if workflowPath == "" || jobID == "" {
+		fmt.Fprintf(os.Stderr, "Error: expected <workflow-file>:<job-id>, got %q\n", args[0])
+		fmt.Fprintf(os.Stderr, "Example: gh slimify why .github/workflows/ci.yml:build\n")
+		os.Exit(1)
+	}
+
+	wf, err := workflow.LoadWorkflow(workflowPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	job, ok := wf.Jobs[jobID]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: job %s not found in %s\n", jobID, workflowPath)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := scan.ScanWithOptions(opts, workflowPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printf("📄 %s\n", workflowPath)
+	if !printJobTrace(result, wf, jobID, opts) {
+		return
+	}
+
+	printLine("\nDuration:")
+	// The scan already looked up the durations of candidates
+	if c := findCandidate(result, workflowPath, jobID); c != nil && !opts.SkipDuration {
+		printf("  %s\n", formatJobDuration(c))
+		return
+	}
+	printWhyDuration(workflowPath, jobID, job.Name, formerNames(opts.FormerJobNames, workflowPath, jobID))
+}
+
+// printJobTrace prints the evaluation trace of a job of a scanned workflow:
+// its status, rules, runner input choices, setup actions, and commands. It
+// returns false if the job was not evaluated.
+func printJobTrace(result *scan.ScanResult, wf *workflow.Workflow, jobID string, opts scan.Options) bool {
+	job := wf.Jobs[jobID]
+	printf("• \"%s\" (ID: %s, L%d)\n", job.Name, jobID, job.LineStart)
+
+	decision := findDecision(result, wf.Path, jobID)
+	if decision == nil {
+		// Jobs calling a local reusable workflow are reported as the called workflow's jobs
+		if called, ok := job.LocalReusableWorkflow(); ok {
+			printf("\nThe job calls the reusable workflow %s; its jobs are evaluated instead.\n", called)
+			printf("Run 'gh slimify why %s:<job-id>' for one of them.\n", called)
+			return false
+		}
+		printLine("\nThe job was not evaluated.")
+		return false
+	}
+
+	printf("  Status: %s\n", statusLabel(decision.Status))
+	if decision.Twin != "" {
+		printf("  Canary of job %s; run 'gh slimify promote %s' to make it authoritative\n", decision.Twin, wf.Path)
+	}
+
+	printLine("\nRules:")
+	for _, r := range decision.Rules {
+		label := r.Rule
+		if r.ID != "" {
+			label = r.ID + " " + r.Rule
+		}
+		line := fmt.Sprintf("  %s %s: %s", resultIcons[r.Result], label, r.Result)
+		if r.Reason != "" {
+			line += " (" + r.Reason + ")"
+		}
+		printLine(line)
+		for _, e := range r.Evidence {
+			printf("       %s\n", formatEvidence(e))
+		}
+	}
+
+	if choices := scan.RunnerChoices(job, wf, opts.Rules); len(choices) > 0 {
+		printf("\nRunner input (runs-on: %v):\n", job.RunsOn)
+		for _, c := range choices {
+			label := c.Input + "=" + c.Value
+			if c.Default {
+				label += " (default)"
+			}
+			if c.Eligible() {
+				printf("  ✅ %s: eligible\n", label)
+			} else {
+				printf("  ❌ %s: %s\n", label, strings.Join(c.Reasons, ", "))
+			}
+		}
+	}
+
+	printLine("\nSetup actions:")
+	setupActions := job.SetupActions()
+	if len(setupActions) == 0 {
+		printLine("  (none)")
+	}
+	for _, a := range setupActions {
+		printf("  • %s (step \"%s\") provides %s\n", a.Uses, a.Step, strings.Join(a.Commands, ", "))
+	}
+
+	printLine("\nCommands:")
+	commands := job.Commands()
+	if len(commands) == 0 {
+		printLine("  (none)")
+	}
+	for _, c := range commands {
+		where := fmt.Sprintf("step \"%s\"", c.Step)
+		if c.Source != "" {
+			where += " → " + c.Source
+		}
+		if c.LineNumber > 0 {
+			where = fmt.Sprintf("L%d %s", c.LineNumber, where)
+		}
+		printf("  • %s: %s\n", where, c.Line)
+	}
+	return true
+}
+
+// findDecision returns the decision for a job, or nil if the job was not evaluated
+func findDecision(result *scan.ScanResult, workflowPath, jobID string) *scan.Decision {
+	for _, d := range result.Decisions() {
+		if filepath.Clean(d.WorkflowPath) == filepath.Clean(workflowPath) && d.JobID == jobID {
+			return d
+		}
+	}
+	return nil
+}
+
+// findCandidate returns the scan candidate for a job, or nil if the job is not one
+func findCandidate(result *scan.ScanResult, workflowPath, jobID string) *scan.Candidate {
+	for _, c := range result.Candidates {
+		if filepath.Clean(c.WorkflowPath) == filepath.Clean(workflowPath) && c.JobID == jobID {
+			return c
+		}
+	}
+	return nil
+}
+
+// statusLabel describes a decision status as shown in the scan output
+func statusLabel(status string) string {
+	switch status {
+	case scan.StatusSafe:
+		return "✅ Safe to migrate"
+	case scan.StatusWarning:
+		return "⚠️  Can migrate but requires attention"
+	case scan.StatusCanary:
+		return "🐤 Already testing ubuntu-slim"
+	case scan.StatusIgnored:
+		return "🙈 Ignored with slimify:ignore"
+	case scan.StatusMigrated:
+		return "✔ Already on ubuntu-slim"
+	default:
+		return "❌ Cannot migrate"
+	}
+}
+
+// formatEvidence formats where a rule matched (e.g., L42 step "Build" → scripts/build.sh: docker build . [pattern])
+func formatEvidence(e workflow.Evidence) string {
+	var parts []string
+	if e.LineNumber > 0 {
+		parts = append(parts, fmt.Sprintf("L%d", e.LineNumber))
+	}
+	if e.Step != "" {
+		where := fmt.Sprintf("step \"%s\"", e.Step)
+		if e.Source != "" {
+			where += " → " + e.Source
+		}
+		parts = append(parts, where+":")
+	} else if e.Source != "" {
+		parts = append(parts, e.Source+":")
+	}
+	if e.Line != "" {
+		parts = append(parts, e.Line)
+	}
+	if e.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("[matched %s]", e.Pattern))
+	}
+	return strings.Join(parts, " ")
+}
+
+// printWhyDuration prints the result of looking up the last execution time of
+// a job that is not a scan candidate
+func printWhyDuration(workflowPath, jobID, jobName string, formerNames []string) {
+	if skipDuration {
+		printLine("  Skipped (--skip-duration)")
+		return
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		printf("  ⚠️  Lookup failed: failed to get repository info: %v\n", err)
+		return
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		printf("  ⚠️  Lookup failed: failed to create API client: %v\n", err)
+		return
+	}
+	client.SetRunFilter(runFilter())
+
+	ctx := context.Background()
+	if deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+	duration, err := client.GetJobDuration(ctx, workflowPath, jobID, jobName, formerNames...)
+	if err != nil {
+		printf("  ⚠️  Lookup failed: %v\n", err)
+		return
+	}
+	printf("  %s from run #%d", scan.FormatDuration(duration.Duration), duration.RunNumber)
+	if duration.RunURL != "" {
+		printf(" (%s)", duration.RunURL)
+	}
+	printLine()
+	printf("  Matched job \"%s\", %s → %s\n", duration.MatchedName, duration.StartedAt.Format(time.RFC3339), duration.CompletedAt.Format(time.RFC3339))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/config"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+)
+
+var workspace bool
+
+// workspaceRepo is the scan result of one repository of a workspace
+type workspaceRepo struct {
+	Path string `json:"path"`
+	// Repository is OWNER/REPO of the repository's origin remote, if known
+	Repository string `json:"repository,omitempty"`
+	// Error is why the repository could not be scanned
+	Error  string           `json:"error,omitempty"`
+	Result *scan.ScanResult `json:"result,omitempty"`
+}
+
+// workspaceResult is the combined scan result of several local checkouts,
+// written by --workspace -o json
+type workspaceResult struct {
+	Repositories []*workspaceRepo `json:"repositories"`
+}
+
+// runWorkspace scans every workflow of each repository root and prints a
+// combined report keyed by repository. Workflow paths are relative to their
+// repository root.
+func runWorkspace(roots []string) {
+	if len(roots) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --workspace requires repository root paths\n")
+		fmt.Fprintf(os.Stderr, "Example: gh slimify --workspace ~/src/repo-a ~/src/repo-b\n")
+		os.Exit(1)
+	}
+	if len(workflowFiles) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: --workspace scans every workflow of each repository and cannot be combined with --file\n")
+		os.Exit(1)
+	}
+	if repoSpec != "" || os.Getenv(api.RepoEnvVar) != "" {
+		fmt.Fprintf(os.Stderr, "Error: --workspace looks up each repository from its origin remote and cannot be combined with --repo or %s\n", api.RepoEnvVar)
+		os.Exit(1)
+	}
+	if decisionLog != "" {
+		fmt.Fprintf(os.Stderr, "Error: --decision-log is not supported with --workspace\n")
+		os.Exit(1)
+	}
+	if checkRun {
+		fmt.Fprintf(os.Stderr, "Error: --check-run is not supported with --workspace\n")
+		os.Exit(1)
+	}
+	if outputFormat != outputText && outputFormat != outputJSON {
+		fmt.Fprintf(os.Stderr, "Error: --workspace supports --output %s or %s\n", outputText, outputJSON)
+		os.Exit(1)
+	}
+
+	// The configuration file is read once, from the current directory
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var repos []*workspaceRepo
+	failed := false
+	for _, root := range roots {
+		repo := &workspaceRepo{Path: root}
+		repos = append(repos, repo)
+		// Paths in the scan, the git remote, and repository scripts are
+		// resolved from the working directory, so each repository is scanned
+		// (and printed) from its root
+		err := inDir(root, func() error {
+			if _, owner, name, err := api.GetRepoInfo(); err == nil {
+				repo.Repository = owner + "/" + name
+			}
+			result, err := scan.ScanWithOptions(opts)
+			if err != nil {
+				return err
+			}
+			repo.Result = result
+			if outputFormat == outputText {
+				label := root
+				if repo.Repository != "" {
+					label += " (" + repo.Repository + ")"
+				}
+				printf("\n📦 %s\n", label)
+				if printScanResult(result) && strict {
+					failed = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			repo.Error = err.Error()
+			failed = true
+			if outputFormat == outputText {
+				eprintf("\n📦 %s\nError: %v\n", root, err)
+			}
+		} else if strict && len(repo.Result.LoadErrors) > 0 {
+			failed = true
+		}
+	}
+
+	if outputFormat == outputJSON {
+		if err := writeJSON(os.Stdout, workspaceResult{Repositories: repos}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printWorkspaceSummary(repos)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// printWorkspaceSummary prints the number of jobs by status in each repository
+func printWorkspaceSummary(repos []*workspaceRepo) {
+	printf("\n📦 Workspace summary (%d repositories):\n", len(repos))
+	totalSafe, totalWarning, totalIneligible := 0, 0, 0
+	for _, repo := range repos {
+		if repo.Result == nil {
+			printf("  • %s: not scanned (%s)\n", repo.Path, repo.Error)
+			continue
+		}
+		safe, warning := 0, 0
+		for _, c := range repo.Result.Candidates {
+			if c.HasWarnings() {
+				warning++
+			} else {
+				safe++
+			}
+		}
+		ineligible := len(repo.Result.IneligibleJobs)
+		printf("  • %s: ✅ %d safe, ⚠️  %d requiring attention, ❌ %d cannot migrate\n", repo.Path, safe, warning, ineligible)
+		totalSafe += safe
+		totalWarning += warning
+		totalIneligible += ineligible
+	}
+	printf("  Total: ✅ %d safe, ⚠️  %d requiring attention, ❌ %d cannot migrate\n", totalSafe, totalWarning, totalIneligible)
+}
+
+// inDir runs fn with dir as the working directory, then restores it
+func inDir(dir string, fn func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(abs); err != nil {
+		return err
+	}
+	defer os.Chdir(cwd)
+	return fn()
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// contentsResponse represents the response from the repository contents API
+type contentsResponse struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// escapePath escapes each segment of a slash-separated repository path for
+// use in an API URL
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// GetActionRunsUsing fetches the action.yml (or action.yaml) of an action in
+// another repository and returns its runs.using value (e.g., "node20", "composite", "docker").
+// actionPath is the subdirectory of the action within the repository, empty for the root.
+func (c *Client) GetActionRunsUsing(ctx context.Context, owner, repo, actionPath, ref string) (string, error) {
+	var lastErr error
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		filePath := path.Join(actionPath, name)
+		apiPath := fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s", owner, repo, escapePath(filePath), url.QueryEscape(ref))
+
+		var response contentsResponse
+		if err := c.get(ctx, apiPath, &response); err != nil {
+			var httpErr *api.HTTPError
+			if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+				lastErr = fmt.Errorf("%s not found in %s/%s@%s", filePath, owner, repo, ref)
+				continue
+			}
+			return "", fmt.Errorf("failed to fetch %s from %s/%s@%s: %w", filePath, owner, repo, ref, err)
+		}
+
+		if response.Encoding != "base64" {
+			return "", fmt.Errorf("unexpected encoding %q for %s", response.Encoding, filePath)
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(response.Content, "\n", ""))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode %s: %w", filePath, err)
+		}
+
+		var metadata struct {
+			Runs struct {
+				Using string `yaml:"using"`
+			} `yaml:"runs"`
+		}
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		return metadata.Runs.Using, nil
+	}
+	return "", lastErr
+}
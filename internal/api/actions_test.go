@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestGetActionRunsUsing(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("runs:\n  using: docker\n  image: Dockerfile\n"))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Only action.yaml exists, in a directory whose name must be escaped
+		if r.URL.EscapedPath() == "/repos/acme/tools/contents/actions/lint%20%231/action.yaml" && r.URL.Query().Get("ref") == "v1" {
+			fmt.Fprintf(w, `{"encoding":"base64","content":%q}`, content)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"Not Found"}`)
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	using, err := client.GetActionRunsUsing(ctx, "acme", "tools", "actions/lint #1", "v1")
+	if err != nil || using != "docker" {
+		t.Errorf("GetActionRunsUsing() = %q, %v, want docker", using, err)
+	}
+	if _, err := client.GetActionRunsUsing(ctx, "acme", "tools", "actions/missing", "v1"); err == nil {
+		t.Errorf("GetActionRunsUsing() of a missing action error = nil, want an error")
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// tokenEnvVars are the environment variables holding a GitHub token, in order
+// of precedence. GitHub Actions provides GITHUB_TOKEN.
+var tokenEnvVars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+
+// authToken returns the token to authenticate to host with. It is looked up
+// like gh does (GH_TOKEN and GITHUB_TOKEN for github.com, GH_ENTERPRISE_TOKEN
+// for other hosts, then gh's credentials), so the gh CLI is not needed when a
+// token is in the environment. When nothing is found for host, GH_TOKEN or
+// GITHUB_TOKEN is used for any host, e.g., in GitHub Enterprise Server
+// workflows.
+func authToken(host string) (string, error) {
+	token, _, err := lookupToken(host)
+	return token, err
+}
+
+// TokenSource returns where the token to authenticate to host with comes
+// from: an environment variable (e.g., GH_TOKEN), or gh's configuration or
+// credential store (e.g., oauth_token or keyring)
+func TokenSource(host string) (string, error) {
+	_, source, err := lookupToken(host)
+	return source, err
+}
+
+// lookupToken returns the token of authToken and its source
+func lookupToken(host string) (string, string, error) {
+	if token, source := auth.TokenForHost(host); token != "" {
+		return token, source, nil
+	}
+	for _, name := range tokenEnvVars {
+		if token := os.Getenv(name); token != "" {
+			return token, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("no GitHub token found for %s: run 'gh auth login', or set GH_TOKEN or GITHUB_TOKEN", host)
+}
@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestAuthToken(t *testing.T) {
+	// Keep gh's configuration and CLI out of the lookup
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_PATH", "/nonexistent/gh")
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	if _, err := authToken("github.com"); err == nil {
+		t.Error("authToken() without a token error = nil, want an error")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "actions-token")
+	for _, host := range []string{"github.com", "ghe.example.com"} {
+		if token, err := authToken(host); err != nil || token != "actions-token" {
+			t.Errorf("authToken(%q) = (%q, %v), want GITHUB_TOKEN", host, token, err)
+		}
+	}
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "enterprise-token")
+	if token, _ := authToken("ghe.example.com"); token != "enterprise-token" {
+		t.Errorf("authToken() for an enterprise host = %q, want GH_ENTERPRISE_TOKEN", token)
+	}
+	t.Setenv("GH_TOKEN", "gh-token")
+	if token, _ := authToken("github.com"); token != "gh-token" {
+		t.Errorf("authToken() = %q, want GH_TOKEN to take precedence", token)
+	}
+}
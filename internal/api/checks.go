@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// maxAnnotationsPerRequest is the number of annotations the checks API
+// accepts per request; more are added by updating the check run
+const maxAnnotationsPerRequest = 50
+
+// Check run annotation levels
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// CheckAnnotation is an annotation of a check run on a line of a file
+type CheckAnnotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"annotation_level"`
+	Title     string `json:"title,omitempty"`
+	Message   string `json:"message"`
+}
+
+// CheckRun is a completed check run on a commit
+type CheckRun struct {
+	Name    string
+	HeadSHA string
+	// Conclusion is success, neutral, or failure
+	Conclusion  string
+	Title       string
+	Summary     string
+	Annotations []CheckAnnotation
+}
+
+// checkRunOutput is the output of a check run in requests to the checks API
+type checkRunOutput struct {
+	Title       string            `json:"title"`
+	Summary     string            `json:"summary"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// CreateCheckRun creates a completed check run with its annotations, in
+// batches of 50, and returns its URL. The checks API requires a GitHub App
+// token, such as GITHUB_TOKEN in GitHub Actions.
+func (c *Client) CreateCheckRun(ctx context.Context, run *CheckRun) (string, error) {
+	if err := readonly.Check("create check run " + run.Name); err != nil {
+		return "", err
+	}
+
+	annotations := run.Annotations
+	batch := func() []CheckAnnotation {
+		n := min(len(annotations), maxAnnotationsPerRequest)
+		b := annotations[:n]
+		annotations = annotations[n:]
+		return b
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"name":       run.Name,
+		"head_sha":   run.HeadSHA,
+		"status":     "completed",
+		"conclusion": run.Conclusion,
+		"output":     checkRunOutput{Title: run.Title, Summary: run.Summary, Annotations: batch()},
+	})
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID      int64  `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	apiPath := fmt.Sprintf("repos/%s/%s/check-runs", c.owner, c.repo)
+	if err := c.restClient.DoWithContext(ctx, http.MethodPost, apiPath, bytes.NewReader(payload), &created); err != nil {
+		return "", fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for len(annotations) > 0 {
+		payload, err := json.Marshal(map[string]any{
+			"output": checkRunOutput{Title: run.Title, Summary: run.Summary, Annotations: batch()},
+		})
+		if err != nil {
+			return "", err
+		}
+		apiPath := fmt.Sprintf("repos/%s/%s/check-runs/%d", c.owner, c.repo, created.ID)
+		if err := c.restClient.DoWithContext(ctx, http.MethodPatch, apiPath, bytes.NewReader(payload), nil); err != nil {
+			return "", fmt.Errorf("failed to add annotations to check run: %w", err)
+		}
+	}
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestCreateCheckRun(t *testing.T) {
+	type request struct {
+		method     string
+		path       string
+		name       string
+		conclusion string
+		output     checkRunOutput
+	}
+	var requests []request
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name       string         `json:"name"`
+			Conclusion string         `json:"conclusion"`
+			Output     checkRunOutput `json:"output"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		requests = append(requests, request{r.Method, r.URL.Path, body.Name, body.Conclusion, body.Output})
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":42,"html_url":"https://github.com/octo/app/runs/42"}`)
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+
+	run := &CheckRun{Name: "gh-slimify", HeadSHA: "abc123", Conclusion: "neutral", Title: "120 job(s)", Summary: "summary"}
+	for i := 0; i < 120; i++ {
+		run.Annotations = append(run.Annotations, CheckAnnotation{Path: "ci.yml", StartLine: i + 1, EndLine: i + 1, Level: AnnotationNotice, Message: fmt.Sprint(i)})
+	}
+	url, err := client.CreateCheckRun(context.Background(), run)
+	if err != nil {
+		t.Fatalf("CreateCheckRun() unexpected error: %v", err)
+	}
+	if url != "https://github.com/octo/app/runs/42" {
+		t.Errorf("CreateCheckRun() URL = %q", url)
+	}
+
+	// The check run is created with the first 50 annotations, and updated
+	// with the others
+	want := []struct {
+		method      string
+		path        string
+		annotations int
+		first       string
+	}{
+		{http.MethodPost, "/repos/octo/app/check-runs", 50, "0"},
+		{http.MethodPatch, "/repos/octo/app/check-runs/42", 50, "50"},
+		{http.MethodPatch, "/repos/octo/app/check-runs/42", 20, "100"},
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("CreateCheckRun() sent %d requests, want %d", len(requests), len(want))
+	}
+	for i, w := range want {
+		r := requests[i]
+		if r.method != w.method || r.path != w.path || len(r.output.Annotations) != w.annotations || r.output.Annotations[0].Message != w.first {
+			t.Errorf("request %d = %s %s with %d annotations, want %s %s with %d annotations from %s", i, r.method, r.path, len(r.output.Annotations), w.method, w.path, w.annotations, w.first)
+		}
+		if r.output.Title != run.Title || r.output.Summary != run.Summary {
+			t.Errorf("request %d output = %q, %q, want the title and summary", i, r.output.Title, r.output.Summary)
+		}
+	}
+	if r := requests[0]; r.name != "gh-slimify" || r.conclusion != "neutral" {
+		t.Errorf("CreateCheckRun() created %q with conclusion %q, want gh-slimify, neutral", r.name, r.conclusion)
+	}
+}
@@ -2,12 +2,17 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	ghrepo "github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/fchimpan/gh-slimify/internal/git"
 )
 
 
@@ -17,6 +22,33 @@ type Client struct {
 	host       string
 	owner      string
 	repo       string
+	limiter    *rateLimiter
+	runFilter  RunFilter
+}
+
+// RunFilter selects the workflow runs job durations are looked up in
+type RunFilter struct {
+	Branch string // Only runs on this branch, if set
+	Event  string // Only runs triggered by this event (e.g., push), if set
+}
+
+// String describes the filter (e.g., "branch main, event push"), or returns
+// "" if it selects every run
+func (f RunFilter) String() string {
+	var parts []string
+	if f.Branch != "" {
+		parts = append(parts, "branch "+f.Branch)
+	}
+	if f.Event != "" {
+		parts = append(parts, "event "+f.Event)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetRunFilter makes the client look up job durations only in the workflow
+// runs selected by f
+func (c *Client) SetRunFilter(f RunFilter) {
+	c.runFilter = f
 }
 
 // NewClient creates a new GitHub API client
@@ -26,8 +58,18 @@ func NewClient(host, owner, repo string) (*Client, error) {
 		host = "github.com"
 	}
 
-	// Create REST client with automatic authentication from gh CLI
-	restClient, err := api.DefaultRESTClient()
+	// Create REST client for the repository's host, authenticated with a
+	// token from the environment or gh CLI. GET responses are cached on disk
+	// and revalidated with their ETag.
+	token, err := authToken(host)
+	if err != nil {
+		return nil, err
+	}
+	opts := api.ClientOptions{Host: host, AuthToken: token}
+	if dir := responseCacheDir(host, owner, repo); dir != "" {
+		opts.Transport = &etagTransport{dir: dir, next: http.DefaultTransport}
+	}
+	restClient, err := api.NewRESTClient(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST client: %w", err)
 	}
@@ -37,49 +79,97 @@ func NewClient(host, owner, repo string) (*Client, error) {
 		host:       host,
 		owner:      owner,
 		repo:       repo,
+		limiter:    &rateLimiter{},
 	}, nil
 }
 
 
+// maxRunsPerPage is the largest page of workflow runs the API returns
+const maxRunsPerPage = 100
+
 // JobDuration represents job execution duration information
 type JobDuration struct {
 	JobName  string
 	Duration time.Duration
+
+	// Details of the sampled run, useful for debugging mismatches with the Actions UI
+	RunID       int64
+	RunNumber   int
+	RunURL      string
+	MatchedName string // Job name as reported by the API
+	StartedAt   time.Time
+	CompletedAt time.Time
 }
 
 // GetJobDuration gets the latest execution duration for a specific job in a workflow
 // jobID is the key in the jobs map, jobDisplayName is the custom display name or job ID if not specified
-func (c *Client) GetJobDuration(ctx context.Context, workflowPath, jobID, jobDisplayName string) (*JobDuration, error) {
+// formerNames are display names the job had before it was renamed, matched in runs
+// where the job is not found under its current name
+func (c *Client) GetJobDuration(ctx context.Context, workflowPath, jobID, jobDisplayName string, formerNames ...string) (*JobDuration, error) {
+	durations, err := c.GetJobDurations(ctx, workflowPath, jobID, jobDisplayName, 1, formerNames...)
+	if err != nil {
+		return nil, err
+	}
+	return durations[0], nil
+}
+
+// GetJobDurations gets the execution durations of a job in up to runs of the
+// latest successful workflow runs, latest first. Runs where the job is not
+// found are skipped, so fewer durations may be returned.
+func (c *Client) GetJobDurations(ctx context.Context, workflowPath, jobID, jobDisplayName string, runs int, formerNames ...string) ([]*JobDuration, error) {
 	// Get workflow runs
-	runs, err := c.getWorkflowRuns(ctx, workflowPath)
+	workflowRuns, err := c.getWorkflowRuns(ctx, workflowPath, runs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workflow runs: %w", err)
 	}
 
-	if len(runs) == 0 {
+	if len(workflowRuns) == 0 {
+		if filter := c.runFilter.String(); filter != "" {
+			return nil, fmt.Errorf("no workflow runs found (%s)", filter)
+		}
 		return nil, fmt.Errorf("no workflow runs found")
 	}
 
-	// Try to find the job in the latest successful run
-	for _, run := range runs {
+	// Try to find the job in the latest successful runs
+	var durations []*JobDuration
+	for _, run := range workflowRuns {
 		if run.Status != "completed" || run.Conclusion != "success" {
 			continue
 		}
 
-		duration, err := c.getJobDurationFromRun(ctx, run.ID, jobID, jobDisplayName)
+		duration, err := c.getJobDurationFromRun(ctx, run.ID, jobID, jobDisplayName, formerNames)
 		if err != nil {
+			// Stop if the lookup was canceled or timed out
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// Other runs would hit the rate limit too
+			if IsRateLimited(err) {
+				return nil, err
+			}
 			// Continue to next run if job not found in this run
 			continue
 		}
-		return duration, nil
+		duration.RunID = run.ID
+		duration.RunNumber = run.RunNumber
+		duration.RunURL = run.HTMLURL
+		durations = append(durations, duration)
+		if len(durations) == runs {
+			break
+		}
 	}
 
-	return nil, fmt.Errorf("no successful run found with job %s (ID: %s)", jobDisplayName, jobID)
+	if len(durations) == 0 {
+		return nil, fmt.Errorf("no successful run found with job %s (ID: %s)", jobDisplayName, jobID)
+	}
+	return durations, nil
 }
 
 // workflowRun represents a workflow run
 type workflowRun struct {
 	ID         int64  `json:"id"`
+	RunNumber  int    `json:"run_number"`
+	HTMLURL    string `json:"html_url"`
 	Status     string `json:"status"`
 	Conclusion string `json:"conclusion"`
 }
@@ -91,10 +181,12 @@ type workflowRunsResponse struct {
 
 // job represents a job in a workflow run
 type job struct {
-	Name        string `json:"name"`
-	Status      string `json:"status"`
-	StartedAt   string `json:"started_at"`
-	CompletedAt string `json:"completed_at"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	Conclusion  string   `json:"conclusion"`
+	Labels      []string `json:"labels"`
+	StartedAt   string   `json:"started_at"`
+	CompletedAt string   `json:"completed_at"`
 }
 
 // jobsResponse represents the response from jobs API
@@ -104,11 +196,11 @@ type jobsResponse struct {
 
 // getJobDurationFromRun gets the duration of a specific job from a workflow run
 // jobID is the key in the jobs map, jobDisplayName is the custom display name or job ID if not specified
-func (c *Client) getJobDurationFromRun(ctx context.Context, runID int64, jobID, jobDisplayName string) (*JobDuration, error) {
+func (c *Client) getJobDurationFromRun(ctx context.Context, runID int64, jobID, jobDisplayName string, formerNames []string) (*JobDuration, error) {
 	path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs", c.owner, c.repo, runID)
 
 	var response jobsResponse
-	err := c.restClient.Get(path, &response)
+	err := c.get(ctx, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch jobs: %w", err)
 	}
@@ -134,6 +226,15 @@ func (c *Client) getJobDurationFromRun(ctx context.Context, runID int64, jobID,
 		}
 	}
 
+	// Runs from before the job was renamed report one of its former names
+	for _, name := range formerNames {
+		for _, j := range response.Jobs {
+			if strings.EqualFold(j.Name, name) {
+				return parseJobDuration(&j, jobDisplayName)
+			}
+		}
+	}
+
 	return nil, fmt.Errorf("job %s (ID: %s) not found in run %d", jobDisplayName, jobID, runID)
 }
 
@@ -156,22 +257,72 @@ func parseJobDuration(j *job, jobDisplayName string) (*JobDuration, error) {
 	duration := completedTime.Sub(startTime)
 
 	return &JobDuration{
-		JobName:  jobDisplayName,
-		Duration: duration,
+		JobName:     jobDisplayName,
+		Duration:    duration,
+		MatchedName: j.Name,
+		StartedAt:   startTime,
+		CompletedAt: completedTime,
 	}, nil
 }
 
-// GetRepoInfo gets repository owner and name from git remote
+// repository is returned by GetRepoInfo when set with SetRepository
+var repository struct {
+	host, owner, repo string
+}
+
+// SetRepository makes GetRepoInfo return the given repository instead of
+// reading the git remote. spec is [HOST/]OWNER/REPO (e.g., fchimpan/gh-slimify);
+// the host defaults to github.com.
+func SetRepository(spec string) error {
+	parts := strings.Split(spec, "/")
+	if len(parts) == 2 {
+		parts = append([]string{"github.com"}, parts...)
+	}
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid repository %q: expected [HOST/]OWNER/REPO", spec)
+	}
+	repository.host, repository.owner, repository.repo = parts[0], parts[1], parts[2]
+	return nil
+}
+
+// RepoEnvVar is the environment variable that gives the repository like
+// --repo, as in gh
+const RepoEnvVar = "GH_REPO"
+
+// GetRepoInfo gets the repository given with SetRepository or GH_REPO, or
+// from the origin git remote. When origin is missing or cannot be parsed
+// (e.g., in CI checkouts), the repository is resolved like gh does, from the
+// other remotes and SSH host aliases.
 func GetRepoInfo() (host, owner, repo string, err error) {
+	if repository.owner != "" {
+		return repository.host, repository.owner, repository.repo, nil
+	}
+	if spec := os.Getenv(RepoEnvVar); spec != "" {
+		if err := SetRepository(spec); err != nil {
+			return "", "", "", fmt.Errorf("%s: %w", RepoEnvVar, err)
+		}
+		return repository.host, repository.owner, repository.repo, nil
+	}
+
 	// Try to get from git remote
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.Output()
+	remoteURL, err := git.RemoteURL("origin")
+	if errors.Is(err, git.ErrDisabled) {
+		return "", "", "", fmt.Errorf("%w: specify the repository with --repo or %s", err, RepoEnvVar)
+	}
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get git remote: %w", err)
+		err = fmt.Errorf("failed to get git remote: %w", err)
+	} else if host, owner, repo, err = parseRemoteURL(remoteURL); err == nil {
+		return host, owner, repo, nil
 	}
 
-	remoteURL := strings.TrimSpace(string(output))
+	if r, ghErr := ghrepo.Current(); ghErr == nil {
+		return r.Host, r.Owner, r.Name, nil
+	}
+	return "", "", "", fmt.Errorf("%w (specify the repository with --repo or %s)", err, RepoEnvVar)
+}
 
+// parseRemoteURL gets the repository host, owner and name from a git remote URL
+func parseRemoteURL(remoteURL string) (host, owner, repo string, err error) {
 	// Parse git remote URL
 	// Support formats:
 	// - https://github.com/owner/repo.git
@@ -210,16 +361,25 @@ func GetRepoInfo() (host, owner, repo string, err error) {
 	return host, owner, repo, nil
 }
 
-// getWorkflowRuns gets workflow runs for a specific workflow file
-func (c *Client) getWorkflowRuns(_ context.Context, workflowPath string) ([]workflowRun, error) {
+// getWorkflowRuns gets the latest workflow runs for a specific workflow file,
+// enough to find the given number of successful runs in most cases
+func (c *Client) getWorkflowRuns(ctx context.Context, workflowPath string, runs int) ([]workflowRun, error) {
 	// Use the full workflow path (e.g., ".github/workflows/ci.yaml")
 	// GitHub API accepts both workflow ID and workflow path
 	// URL encode the path for the API call
 	encodedPath := strings.ReplaceAll(workflowPath, "/", "%2F")
-	path := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?per_page=10", c.owner, c.repo, encodedPath)
+	// Failed runs are skipped, so fetch at least 10 runs and more than asked for
+	perPage := min(max(10, 2*runs), maxRunsPerPage)
+	path := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?per_page=%d", c.owner, c.repo, encodedPath, perPage)
+	if c.runFilter.Branch != "" {
+		path += "&branch=" + url.QueryEscape(c.runFilter.Branch)
+	}
+	if c.runFilter.Event != "" {
+		path += "&event=" + url.QueryEscape(c.runFilter.Event)
+	}
 
 	var response workflowRunsResponse
-	err := c.restClient.Get(path, &response)
+	err := c.get(ctx, path, &response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflow runs: %w", err)
 	}
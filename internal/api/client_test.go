@@ -0,0 +1,107 @@
+package api
+
+import "testing"
+
+func TestRunFilter_String(t *testing.T) {
+	tests := []struct {
+		filter RunFilter
+		want   string
+	}{
+		{RunFilter{}, ""},
+		{RunFilter{Branch: "main"}, "branch main"},
+		{RunFilter{Event: "schedule"}, "event schedule"},
+		{RunFilter{Branch: "main", Event: "push"}, "branch main, event push"},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesJob(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Build", true},
+		{"build", true},
+		{"compile", true},
+		{"Build (ubuntu-latest, 20)", true},
+		{"Old build", true},
+		{"Build and test", false},
+		{"Test (Build)", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesJob(tt.name, "compile", "Build", []string{"Old build"}); got != tt.want {
+			t.Errorf("MatchesJob(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetRepoInfo_Env(t *testing.T) {
+	t.Cleanup(func() { repository.host, repository.owner, repository.repo = "", "", "" })
+	t.Setenv(RepoEnvVar, "ghe.example.com/octo/hello")
+	host, owner, repo, err := GetRepoInfo()
+	if err != nil || host != "ghe.example.com" || owner != "octo" || repo != "hello" {
+		t.Errorf("GetRepoInfo() = (%q, %q, %q, %v), want (ghe.example.com, octo, hello, nil)", host, owner, repo, err)
+	}
+
+	repository.owner = ""
+	t.Setenv(RepoEnvVar, "octo")
+	if _, _, _, err := GetRepoInfo(); err == nil {
+		t.Errorf("GetRepoInfo() with %s=octo error = nil, want an error", RepoEnvVar)
+	}
+}
+
+func TestSetRepository(t *testing.T) {
+	t.Cleanup(func() { repository.host, repository.owner, repository.repo = "", "", "" })
+	tests := []struct {
+		spec  string
+		want  string
+		valid bool
+	}{
+		{"fchimpan/gh-slimify", "github.com/fchimpan/gh-slimify", true},
+		{"ghe.example.com/octo/hello", "ghe.example.com/octo/hello", true},
+		{"gh-slimify", "", false},
+		{"/gh-slimify", "", false},
+		{"fchimpan/", "", false},
+		{"/octo/hello", "", false},
+		{"ghe.example.com//hello", "", false},
+		{"ghe.example.com/octo/", "", false},
+		{"ghe.example.com/octo/hello/extra", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		repository.host, repository.owner, repository.repo = "", "", ""
+		err := SetRepository(tt.spec)
+		if (err == nil) != tt.valid {
+			t.Errorf("SetRepository(%q) error = %v, want valid = %v", tt.spec, err, tt.valid)
+			continue
+		}
+		if got := repository.host + "/" + repository.owner + "/" + repository.repo; tt.valid && got != tt.want {
+			t.Errorf("SetRepository(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+		if !tt.valid && repository.owner != "" {
+			t.Errorf("SetRepository(%q) set the repository despite the error", tt.spec)
+		}
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		want  string
+		valid bool
+	}{
+		{"https://github.com/fchimpan/gh-slimify.git", "github.com/fchimpan/gh-slimify", true},
+		{"git@ghe.example.com:octo/hello", "ghe.example.com/octo/hello", true},
+		{"ssh://git@github.com/fchimpan/gh-slimify.git", "", false},
+	}
+	for _, tt := range tests {
+		host, owner, repo, err := parseRemoteURL(tt.url)
+		if (err == nil) != tt.valid || (tt.valid && host+"/"+owner+"/"+repo != tt.want) {
+			t.Errorf("parseRemoteURL(%q) = (%q, %q, %q, %v), want %q", tt.url, host, owner, repo, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// compareResponse represents the response from the compare API
+type compareResponse struct {
+	Files []struct {
+		Filename string `json:"filename"`
+		Status   string `json:"status"`
+	} `json:"files"`
+}
+
+// GetChangedFiles returns the files added or modified between the merge base
+// of base and head (branches, tags, or commit SHAs) and head, with the
+// compare API. The API lists at most 300 files.
+func (c *Client) GetChangedFiles(ctx context.Context, base, head string) ([]string, error) {
+	apiPath := fmt.Sprintf("repos/%s/%s/compare/%s...%s", c.owner, c.repo, url.PathEscape(base), url.PathEscape(head))
+
+	var response compareResponse
+	if err := c.get(ctx, apiPath, &response); err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, err)
+	}
+
+	var files []string
+	for _, f := range response.Files {
+		if f.Status != "removed" {
+			files = append(files, f.Filename)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestGetChangedFiles(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/repos/octo/app/compare/main...feature%2Fslim":
+			io.WriteString(w, `{"files":[
+				{"filename":".github/workflows/ci.yml","status":"modified"},
+				{"filename":".github/workflows/release.yml","status":"added"},
+				{"filename":".github/workflows/deploy.yml","previous_filename":".github/workflows/cd.yml","status":"renamed"},
+				{"filename":".github/workflows/old.yml","status":"removed"}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	// Renamed files are listed under their new name, removed files are not
+	files, err := client.GetChangedFiles(ctx, "main", "feature/slim")
+	want := []string{".github/workflows/ci.yml", ".github/workflows/release.yml", ".github/workflows/deploy.yml"}
+	if err != nil || !reflect.DeepEqual(files, want) {
+		t.Errorf("GetChangedFiles() = %v, %v, want %v", files, err, want)
+	}
+	if _, err := client.GetChangedFiles(ctx, "main", "missing"); err == nil {
+		t.Errorf("GetChangedFiles() with a missing ref error = nil, want an error")
+	}
+}
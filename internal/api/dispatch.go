@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// WorkflowRun is a run of a workflow
+type WorkflowRun struct {
+	ID         int64  `json:"id"`
+	HTMLURL    string `json:"html_url"`
+	Status     string `json:"status"`     // e.g., queued, in_progress, completed
+	Conclusion string `json:"conclusion"` // e.g., success, failure, set once completed
+}
+
+// RunJob is a job of a workflow run
+type RunJob struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// DispatchWorkflow triggers a run of a workflow on ref with its
+// workflow_dispatch trigger, with the default values of its inputs
+func (c *Client) DispatchWorkflow(ctx context.Context, workflowPath, ref string) error {
+	if err := readonly.Check("dispatch " + workflowPath); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]string{"ref": ref})
+	if err != nil {
+		return err
+	}
+	apiPath := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/dispatches", c.owner, c.repo, strings.ReplaceAll(workflowPath, "/", "%2F"))
+	if err := c.restClient.DoWithContext(ctx, http.MethodPost, apiPath, bytes.NewReader(payload), nil); err != nil {
+		return fmt.Errorf("failed to dispatch %s: %w", workflowPath, err)
+	}
+	return nil
+}
+
+// LatestRun returns the latest run of a workflow on branch triggered by
+// event, or nil if there is none
+func (c *Client) LatestRun(ctx context.Context, workflowPath, branch, event string) (*WorkflowRun, error) {
+	apiPath := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?branch=%s&event=%s&per_page=1", c.owner, c.repo, strings.ReplaceAll(workflowPath, "/", "%2F"), url.QueryEscape(branch), url.QueryEscape(event))
+	var response struct {
+		WorkflowRuns []*WorkflowRun `json:"workflow_runs"`
+	}
+	if err := c.get(ctx, apiPath, &response); err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow runs: %w", err)
+	}
+	if len(response.WorkflowRuns) == 0 {
+		return nil, nil
+	}
+	return response.WorkflowRuns[0], nil
+}
+
+// GetRun fetches a workflow run
+func (c *Client) GetRun(ctx context.Context, runID int64) (*WorkflowRun, error) {
+	var run WorkflowRun
+	if err := c.get(ctx, fmt.Sprintf("repos/%s/%s/actions/runs/%d", c.owner, c.repo, runID), &run); err != nil {
+		return nil, fmt.Errorf("failed to fetch run %d: %w", runID, err)
+	}
+	return &run, nil
+}
+
+// GetRunJobs returns the jobs of the latest attempt of a workflow run
+func (c *Client) GetRunJobs(ctx context.Context, runID int64) ([]RunJob, error) {
+	var jobs []RunJob
+	for page := 1; ; page++ {
+		apiPath := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?per_page=100&page=%d", c.owner, c.repo, runID, page)
+		var response struct {
+			Jobs []RunJob `json:"jobs"`
+		}
+		if err := c.get(ctx, apiPath, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+		}
+		jobs = append(jobs, response.Jobs...)
+		if len(response.Jobs) < 100 {
+			return jobs, nil
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestDispatchWorkflow(t *testing.T) {
+	var dispatched string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.EscapedPath() == "/repos/octo/app/actions/workflows/.github%2Fworkflows%2Fci.yml/dispatches":
+			body, _ := io.ReadAll(r.Body)
+			dispatched = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.EscapedPath() == "/repos/octo/app/actions/workflows/.github%2Fworkflows%2Fci.yml/runs":
+			if r.URL.Query().Get("branch") != "verify" || r.URL.Query().Get("event") != "workflow_dispatch" {
+				io.WriteString(w, `{"workflow_runs":[]}`)
+				return
+			}
+			io.WriteString(w, `{"workflow_runs":[{"id":42,"html_url":"https://github.com/octo/app/actions/runs/42","status":"in_progress","conclusion":null}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	if err := client.DispatchWorkflow(ctx, ".github/workflows/ci.yml", "verify"); err != nil {
+		t.Fatalf("DispatchWorkflow() unexpected error: %v", err)
+	}
+	if dispatched != `{"ref":"verify"}` {
+		t.Errorf("DispatchWorkflow() sent %s, want the ref", dispatched)
+	}
+
+	run, err := client.LatestRun(ctx, ".github/workflows/ci.yml", "verify", "workflow_dispatch")
+	if err != nil || run == nil || run.ID != 42 || run.Status != "in_progress" {
+		t.Errorf("LatestRun() = %+v, %v, want run 42 in progress", run, err)
+	}
+	if run, err := client.LatestRun(ctx, ".github/workflows/ci.yml", "main", "workflow_dispatch"); run != nil || err != nil {
+		t.Errorf("LatestRun() on a branch without runs = %+v, %v, want nil", run, err)
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/cache"
+)
+
+// responseCacheDisabled is set by DisableResponseCache
+var responseCacheDisabled bool
+
+// DisableResponseCache makes clients neither read nor write the response
+// cache (--no-cache)
+func DisableResponseCache() {
+	responseCacheDisabled = true
+}
+
+// responseCacheDir returns the directory of the cached responses of a
+// repository, or "" if the response cache is disabled or unavailable
+func responseCacheDir(host, owner, repo string) string {
+	if responseCacheDisabled {
+		return ""
+	}
+	dir, err := cache.Dir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "responses", host, owner, repo)
+}
+
+// cachedResponse is a GET response stored in the response cache
+type cachedResponse struct {
+	URL         string    `json:"url"`
+	ETag        string    `json:"etag"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body"`
+	StoredAt    time.Time `json:"stored_at"`
+}
+
+// etagTransport caches GET responses on disk and revalidates them with
+// If-None-Match. Unchanged responses (304 Not Modified) are served from the
+// cache, and do not count against the GitHub API rate limit.
+type etagTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+
+	var cached cachedResponse
+	revalidate := cache.ReadJSON(path, &cached) == nil && cached.URL == url && cached.ETag != ""
+	if revalidate {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if revalidate && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		// Keep the headers of the 304 response (e.g., rate limit headers)
+		header := resp.Header.Clone()
+		if cached.ContentType != "" {
+			header.Set("Content-Type", cached.ContentType)
+		}
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	if resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "" {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		// A response that cannot be cached is still returned
+		_ = cache.WriteJSON(path, cachedResponse{
+			URL:         url,
+			ETag:        resp.Header.Get("ETag"),
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+			StoredAt:    time.Now(),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEtagTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"total_count":1}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &etagTransport{dir: t.TempDir(), next: http.DefaultTransport}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/repos/o/r/actions/runs")
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != `{"total_count":1}` {
+			t.Errorf("request %d = %d %q, want 200 with the cached body", i+1, resp.StatusCode, body)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("request %d Content-Type = %q, want application/json", i+1, ct)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("server got %d requests, want 2", requests)
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// maxPullRequestFilePages bounds the pages of pull request files fetched; the
+// API lists at most 3000 files
+const maxPullRequestFilePages = 30
+
+// PullRequest is a pull request of the client's repository
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Base   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+}
+
+// GetPullRequest fetches a pull request
+func (c *Client) GetPullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.get(ctx, fmt.Sprintf("repos/%s/%s/pulls/%d", c.owner, c.repo, number), &pr); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request #%d: %w", number, err)
+	}
+	return &pr, nil
+}
+
+// GetPullRequestFiles returns the files added or modified by a pull request
+func (c *Client) GetPullRequestFiles(ctx context.Context, number int) ([]string, error) {
+	var files []string
+	for page := 1; page <= maxPullRequestFilePages; page++ {
+		var response []struct {
+			Filename string `json:"filename"`
+			Status   string `json:"status"`
+		}
+		apiPath := fmt.Sprintf("repos/%s/%s/pulls/%d/files?per_page=100&page=%d", c.owner, c.repo, number, page)
+		if err := c.get(ctx, apiPath, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch files of pull request #%d: %w", number, err)
+		}
+		for _, f := range response {
+			if f.Status != "removed" {
+				files = append(files, f.Filename)
+			}
+		}
+		if len(response) < 100 {
+			break
+		}
+	}
+	return files, nil
+}
+
+// GetFileContent fetches a file of the client's repository at ref. It
+// returns nil without an error if the file does not exist at ref.
+func (c *Client) GetFileContent(ctx context.Context, filePath, ref string) ([]byte, error) {
+	apiPath := fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s", c.owner, c.repo, escapePath(filePath), url.QueryEscape(ref))
+
+	var response contentsResponse
+	if err := c.get(ctx, apiPath, &response); err != nil {
+		var httpErr *api.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch %s@%s: %w", filePath, ref, err)
+	}
+	if response.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected encoding %q for %s", response.Encoding, filePath)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(response.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	return data, nil
+}
+
+// GetTreeFiles returns the files of the client's repository under dir at
+// ref, relative to the repository root
+func (c *Client) GetTreeFiles(ctx context.Context, ref, dir string) ([]string, error) {
+	var response struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	apiPath := fmt.Sprintf("repos/%s/%s/git/trees/%s?recursive=1", c.owner, c.repo, url.PathEscape(ref))
+	if err := c.get(ctx, apiPath, &response); err != nil {
+		return nil, fmt.Errorf("failed to fetch the files of %s: %w", ref, err)
+	}
+	if response.Truncated {
+		slog.Warn("the file tree is truncated, some files may be missing", "ref", ref)
+	}
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	var files []string
+	for _, entry := range response.Tree {
+		if entry.Type == "blob" && (dir == "" || entry.Path == dir || strings.HasPrefix(entry.Path, dir+"/")) {
+			files = append(files, entry.Path)
+		}
+	}
+	return files, nil
+}
+
+// issueComment represents a comment on an issue or pull request
+type issueComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// actionsBotLogin is the author of the comments made with the GITHUB_TOKEN
+// of a GitHub Actions workflow, which cannot look itself up
+const actionsBotLogin = "github-actions[bot]"
+
+// UpsertIssueComment updates the comment on an issue or pull request whose
+// body contains marker (e.g., a hidden HTML comment) and that the
+// authenticated user wrote, or creates one. It returns the URL of the comment
+// and whether it was created.
+func (c *Client) UpsertIssueComment(ctx context.Context, number int, marker, body string) (string, bool, error) {
+	if err := readonly.Check(fmt.Sprintf("comment on #%d", number)); err != nil {
+		return "", false, err
+	}
+
+	login, err := c.authenticatedLogin(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	existing, err := c.findIssueComment(ctx, number, marker, login)
+	if err != nil {
+		return "", false, err
+	}
+
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return "", false, err
+	}
+	var comment issueComment
+	if existing != nil {
+		apiPath := fmt.Sprintf("repos/%s/%s/issues/comments/%d", c.owner, c.repo, existing.ID)
+		if err := c.restClient.DoWithContext(ctx, http.MethodPatch, apiPath, bytes.NewReader(payload), &comment); err != nil {
+			return "", false, fmt.Errorf("failed to update comment on #%d: %w", number, err)
+		}
+		return comment.HTMLURL, false, nil
+	}
+	apiPath := fmt.Sprintf("repos/%s/%s/issues/%d/comments", c.owner, c.repo, number)
+	if err := c.restClient.DoWithContext(ctx, http.MethodPost, apiPath, bytes.NewReader(payload), &comment); err != nil {
+		return "", false, fmt.Errorf("failed to comment on #%d: %w", number, err)
+	}
+	return comment.HTMLURL, true, nil
+}
+
+// authenticatedLogin returns the login of the authenticated user. The
+// GITHUB_TOKEN of GitHub Actions cannot look itself up, and comments as
+// github-actions[bot].
+func (c *Client) authenticatedLogin(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.get(ctx, "user", &user); err != nil {
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			return actionsBotLogin, nil
+		}
+		return "", fmt.Errorf("failed to fetch the authenticated user: %w", err)
+	}
+	return user.Login, nil
+}
+
+// findIssueComment returns the first comment on an issue or pull request
+// written by login whose body contains marker, or nil. Comments of other
+// users quoting the marker are skipped: they cannot be updated.
+func (c *Client) findIssueComment(ctx context.Context, number int, marker, login string) (*issueComment, error) {
+	for page := 1; ; page++ {
+		var comments []issueComment
+		apiPath := fmt.Sprintf("repos/%s/%s/issues/%d/comments?per_page=100&page=%d", c.owner, c.repo, number, page)
+		if err := c.get(ctx, apiPath, &comments); err != nil {
+			return nil, fmt.Errorf("failed to fetch comments of #%d: %w", number, err)
+		}
+		for i := range comments {
+			if strings.EqualFold(comments[i].User.Login, login) && strings.Contains(comments[i].Body, marker) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < 100 {
+			return nil, nil
+		}
+	}
+}
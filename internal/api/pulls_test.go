@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestGetPullRequest(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("on: push\njobs: {}\n"))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/octo/app/pulls/7":
+			io.WriteString(w, `{"number":7,"title":"Add lint","base":{"ref":"main","sha":"abc123"}}`)
+		case "/repos/octo/app/pulls/7/files":
+			// A full first page, then a last page with a removed file
+			var files []map[string]string
+			if r.URL.Query().Get("page") == "1" {
+				for i := 0; i < 100; i++ {
+					files = append(files, map[string]string{"filename": fmt.Sprintf("src/%d.go", i), "status": "modified"})
+				}
+			} else {
+				files = append(files,
+					map[string]string{"filename": ".github/workflows/ci.yml", "status": "added"},
+					map[string]string{"filename": ".github/workflows/old.yml", "status": "removed"},
+				)
+			}
+			json.NewEncoder(w).Encode(files)
+		case "/repos/octo/app/contents/.github/workflows/ci.yml":
+			if r.URL.Query().Get("ref") != "main" {
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"message":"Not Found"}`)
+				return
+			}
+			// The API wraps base64 content every 60 characters
+			fmt.Fprintf(w, `{"encoding":"base64","content":%q}`, content[:10]+"\n"+content[10:]+"\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	pr, err := client.GetPullRequest(ctx, 7)
+	if err != nil || pr.Number != 7 || pr.Base.Ref != "main" || pr.Base.SHA != "abc123" {
+		t.Errorf("GetPullRequest() = %+v, %v, want #7 based on main", pr, err)
+	}
+	if _, err := client.GetPullRequest(ctx, 8); err == nil {
+		t.Errorf("GetPullRequest() of a missing pull request error = nil, want an error")
+	}
+
+	files, err := client.GetPullRequestFiles(ctx, 7)
+	if err != nil {
+		t.Fatalf("GetPullRequestFiles() unexpected error: %v", err)
+	}
+	if len(files) != 101 || files[100] != ".github/workflows/ci.yml" {
+		t.Errorf("GetPullRequestFiles() = %d files ending with %q, want 101 files without the removed one", len(files), files[len(files)-1])
+	}
+
+	data, err := client.GetFileContent(ctx, ".github/workflows/ci.yml", "main")
+	if err != nil || string(data) != "on: push\njobs: {}\n" {
+		t.Errorf("GetFileContent() = %q, %v, want the decoded content", data, err)
+	}
+	if data, err := client.GetFileContent(ctx, ".github/workflows/ci.yml", "feature"); data != nil || err != nil {
+		t.Errorf("GetFileContent() of a missing file = %q, %v, want nil, nil", data, err)
+	}
+}
+
+func TestUpsertIssueComment(t *testing.T) {
+	const marker = "<!-- gh-slimify -->"
+	tests := []struct {
+		name string
+		// comments of the pull request on page 2, after 100 unrelated ones
+		comments   []map[string]any
+		wantMethod string
+		wantPath   string
+		created    bool
+	}{
+		{
+			name:       "create",
+			comments:   []map[string]any{{"id": 200, "body": "LGTM", "user": map[string]string{"login": "slimify-bot"}}},
+			wantMethod: http.MethodPost,
+			wantPath:   "/repos/octo/app/issues/7/comments",
+			created:    true,
+		},
+		{
+			name: "update",
+			comments: []map[string]any{
+				{"id": 200, "body": "> " + marker + "\nWhy?", "user": map[string]string{"login": "alice"}},
+				{"id": 201, "body": marker + "\nold", "user": map[string]string{"login": "slimify-bot"}},
+			},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/repos/octo/app/issues/comments/201",
+		},
+		{
+			// Comments quoting the marker cannot be updated
+			name:       "quoted by another user",
+			comments:   []map[string]any{{"id": 200, "body": "> " + marker, "user": map[string]string{"login": "alice"}}},
+			wantMethod: http.MethodPost,
+			wantPath:   "/repos/octo/app/issues/7/comments",
+			created:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/user":
+					io.WriteString(w, `{"login":"slimify-bot"}`)
+				case r.Method == http.MethodGet && r.URL.Path == "/repos/octo/app/issues/7/comments":
+					page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+					comments := tt.comments
+					if page == 1 {
+						comments = nil
+						for i := 0; i < 100; i++ {
+							comments = append(comments, map[string]any{"id": i, "body": "comment", "user": map[string]string{"login": "alice"}})
+						}
+					}
+					json.NewEncoder(w).Encode(comments)
+				case r.Method != http.MethodGet:
+					method, path = r.Method, r.URL.Path
+					data, _ := io.ReadAll(r.Body)
+					body = string(data)
+					io.WriteString(w, `{"id":201,"html_url":"https://github.com/octo/app/pull/7#issuecomment-201"}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					io.WriteString(w, `{"message":"Not Found"}`)
+				}
+			})
+			rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+			if err != nil {
+				t.Fatalf("NewRESTClient() unexpected error: %v", err)
+			}
+			client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+
+			url, created, err := client.UpsertIssueComment(context.Background(), 7, marker, marker+"\nnew")
+			if err != nil {
+				t.Fatalf("UpsertIssueComment() unexpected error: %v", err)
+			}
+			if method != tt.wantMethod || path != tt.wantPath || created != tt.created {
+				t.Errorf("UpsertIssueComment() sent %s %s (created %v), want %s %s (created %v)", method, path, created, tt.wantMethod, tt.wantPath, tt.created)
+			}
+			var payload map[string]string
+			if err := json.Unmarshal([]byte(body), &payload); err != nil || !reflect.DeepEqual(payload, map[string]string{"body": marker + "\nnew"}) {
+				t.Errorf("UpsertIssueComment() sent %s, want the body", body)
+			}
+			if url != "https://github.com/octo/app/pull/7#issuecomment-201" {
+				t.Errorf("UpsertIssueComment() URL = %q", url)
+			}
+		})
+	}
+}
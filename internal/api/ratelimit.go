@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+const (
+	// maxRetries is the number of times a request is retried after a rate
+	// limit or a transient server error
+	maxRetries = 3
+	// maxRateLimitWait is the longest wait for the rate limit to reset. When
+	// the reset is further away, the limit is considered exhausted.
+	maxRateLimitWait = 5 * time.Minute
+	// secondaryRateLimitWait is the initial backoff after hitting a secondary
+	// rate limit that does not say when to retry, doubled on each retry
+	secondaryRateLimitWait = time.Minute
+	// serverErrorWait is the initial backoff after a transient server error,
+	// doubled on each retry
+	serverErrorWait = time.Second
+)
+
+// ErrRateLimited is wrapped by errors of requests that were not made, or
+// failed, because the GitHub API rate limit is exhausted
+var ErrRateLimited = errors.New("GitHub API rate limit exhausted")
+
+// rateLimiter pauses every request of a client, including those made
+// concurrently, while the GitHub API rate limit is exceeded
+type rateLimiter struct {
+	mu    sync.Mutex
+	until time.Time
+	// exhausted is when the rate limit resets, if it is too far away to wait
+	// for. Requests fail with ErrRateLimited until then.
+	exhausted time.Time
+}
+
+// wait blocks until the rate limit pause is over or ctx is done, and fails
+// if the rate limit is exhausted
+func (r *rateLimiter) wait(ctx context.Context) error {
+	r.mu.Lock()
+	d := time.Until(r.until)
+	exhausted := time.Until(r.exhausted)
+	r.mu.Unlock()
+	if exhausted > 0 {
+		return fmt.Errorf("%w (resets in %s)", ErrRateLimited, exhausted.Round(time.Second))
+	}
+	if d <= 0 {
+		return nil
+	}
+	return sleep(ctx, d)
+}
+
+// pause makes requests wait for d, unless they already wait longer
+func (r *rateLimiter) pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until := time.Now().Add(d); until.After(r.until) {
+		r.until = until
+	}
+}
+
+// exhaust makes requests fail for d
+func (r *rateLimiter) exhaust(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until := time.Now().Add(d); until.After(r.exhausted) {
+		r.exhausted = until
+	}
+}
+
+// get performs a GET request on path and decodes the response. When the rate
+// limit is exceeded, every request of the client waits until it resets, and
+// the request is retried; secondary rate limits and transient server errors
+// are retried with exponential backoff and jitter. Requests fail with
+// ErrRateLimited when the limit is exhausted.
+func (c *Client) get(ctx context.Context, path string, response interface{}) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+		err := c.restClient.DoWithContext(ctx, http.MethodGet, path, nil, response)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		if wait, limited := rateLimitDelay(err, time.Now(), attempt); limited {
+			if wait > maxRateLimitWait {
+				c.limiter.exhaust(wait)
+				return fmt.Errorf("%w (resets in %s): %w", ErrRateLimited, wait.Round(time.Second), err)
+			}
+			if attempt == maxRetries {
+				return fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			c.limiter.pause(wait)
+			continue
+		}
+
+		if !isTransient(err) || attempt == maxRetries {
+			return err
+		}
+		if err := sleep(ctx, jitter(serverErrorWait<<attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// IsRateLimited reports whether err is caused by the GitHub API rate limit
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// rateLimitDelay reports whether err is a rate limit error of the GitHub API
+// (primary or secondary) and how long to wait before retrying, from the
+// Retry-After or X-RateLimit-Reset headers, or with exponential backoff and
+// jitter for the attempt-th retry when they are missing
+func rateLimitDelay(err error, now time.Time, attempt int) (time.Duration, bool) {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return 0, false
+	}
+	if httpErr.StatusCode != http.StatusForbidden && httpErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if s := httpErr.Headers.Get("Retry-After"); s != "" {
+		if seconds, err := strconv.Atoi(s); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if httpErr.Headers.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(httpErr.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Unix(reset, 0).Sub(now)
+			if wait < time.Second {
+				wait = time.Second
+			}
+			return wait, true
+		}
+	}
+	if httpErr.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(httpErr.Message), "rate limit") {
+		return jitter(secondaryRateLimitWait << attempt), true
+	}
+	return 0, false
+}
+
+// isTransient reports whether err is a server error worth retrying
+func isTransient(err error) bool {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	switch httpErr.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// jitter returns d plus a random duration of up to a quarter of d, so that
+// concurrent requests do not retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	if d < 4 {
+		return d
+	}
+	return d + rand.N(d/4)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	httpError := func(status int, message string, headers map[string]string) error {
+		h := http.Header{}
+		for k, v := range headers {
+			h.Set(k, v)
+		}
+		return fmt.Errorf("failed to fetch jobs: %w", &api.HTTPError{StatusCode: status, Message: message, Headers: h})
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		attempt     int
+		wantWait    time.Duration // Minimum wait; jitter adds up to a quarter
+		wantLimited bool
+	}{
+		{
+			name:        "primary rate limit waits until reset",
+			err:         httpError(http.StatusForbidden, "API rate limit exceeded", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()+90, 10)}),
+			wantWait:    90 * time.Second,
+			wantLimited: true,
+		},
+		{
+			name:        "retry-after takes precedence",
+			err:         httpError(http.StatusForbidden, "secondary rate limit", map[string]string{"Retry-After": "30", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()+90, 10)}),
+			wantWait:    30 * time.Second,
+			wantLimited: true,
+		},
+		{
+			name:        "secondary rate limit without headers",
+			err:         httpError(http.StatusForbidden, "You have exceeded a secondary rate limit", nil),
+			wantWait:    secondaryRateLimitWait,
+			wantLimited: true,
+		},
+		{
+			name:        "secondary rate limit backs off exponentially",
+			err:         httpError(http.StatusTooManyRequests, "", nil),
+			attempt:     2,
+			wantWait:    4 * secondaryRateLimitWait,
+			wantLimited: true,
+		},
+		{
+			name:        "forbidden is not a rate limit",
+			err:         httpError(http.StatusForbidden, "Resource not accessible by integration", map[string]string{"X-RateLimit-Remaining": "4999"}),
+			wantLimited: false,
+		},
+		{
+			name:        "not found",
+			err:         httpError(http.StatusNotFound, "Not Found", nil),
+			wantLimited: false,
+		},
+		{
+			name:        "not an HTTP error",
+			err:         errors.New("connection refused"),
+			wantLimited: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, limited := rateLimitDelay(tt.err, now, tt.attempt)
+			if limited != tt.wantLimited || wait < tt.wantWait || wait > tt.wantWait+tt.wantWait/4 {
+				t.Errorf("rateLimitDelay() = (%v, %v), want (%v, %v)", wait, limited, tt.wantWait, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusInternalServerError: true,
+		http.StatusNotFound:            false,
+		http.StatusForbidden:           false,
+	} {
+		err := fmt.Errorf("failed to fetch workflow runs: %w", &api.HTTPError{StatusCode: status})
+		if got := isTransient(err); got != want {
+			t.Errorf("isTransient(HTTP %d) = %v, want %v", status, got, want)
+		}
+	}
+	if isTransient(errors.New("connection reset")) {
+		t.Error("isTransient() = true for a non-HTTP error, want false")
+	}
+}
+
+func TestRateLimiter_Exhausted(t *testing.T) {
+	r := &rateLimiter{}
+	r.exhaust(time.Hour)
+	if err := r.wait(context.Background()); !IsRateLimited(err) {
+		t.Errorf("wait() error = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Release is a release of a repository
+type Release struct {
+	TagName    string          `json:"tag_name"`
+	Name       string          `json:"name"`
+	Draft      bool            `json:"draft"`
+	Prerelease bool            `json:"prerelease"`
+	Assets     []*ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// ListReleases returns the latest releases of a repository, newest first, up
+// to count (at most 100)
+func (c *Client) ListReleases(ctx context.Context, owner, repo string, count int) ([]*Release, error) {
+	var releases []*Release
+	if err := c.get(ctx, fmt.Sprintf("repos/%s/%s/releases?per_page=%d", owner, repo, count), &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s/%s: %w", owner, repo, err)
+	}
+	return releases, nil
+}
+
+// DownloadReleaseAsset returns the content of a release asset
+func (c *Client) DownloadReleaseAsset(ctx context.Context, asset *ReleaseAsset) ([]byte, error) {
+	resp, err := c.restClient.RequestWithContext(ctx, http.MethodGet, asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
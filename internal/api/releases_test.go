@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestListReleasesAndDownloadAsset(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/actions/runner-images/releases":
+			if got := r.URL.Query().Get("per_page"); got != "10" {
+				t.Errorf("per_page = %q, want 10", got)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `[{"tag_name":"ubuntu24/20261012.1","assets":[{"name":"internal.ubuntu24.json","browser_download_url":"https://github.com/actions/runner-images/releases/download/ubuntu24%2F20261012.1/internal.ubuntu24.json"}]}]`)
+		case "/actions/runner-images/releases/download/ubuntu24/20261012.1/internal.ubuntu24.json":
+			io.WriteString(w, `{"ToolName":"Kubectl"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	releases, err := client.ListReleases(ctx, "actions", "runner-images", 10)
+	if err != nil {
+		t.Fatalf("ListReleases() unexpected error: %v", err)
+	}
+	if len(releases) != 1 || releases[0].TagName != "ubuntu24/20261012.1" || len(releases[0].Assets) != 1 {
+		t.Fatalf("ListReleases() = %+v, want one release with one asset", releases)
+	}
+
+	data, err := client.DownloadReleaseAsset(ctx, releases[0].Assets[0])
+	if err != nil {
+		t.Fatalf("DownloadReleaseAsset() unexpected error: %v", err)
+	}
+	if string(data) != `{"ToolName":"Kubectl"}` {
+		t.Errorf("DownloadReleaseAsset() = %s", data)
+	}
+
+	missing := &ReleaseAsset{Name: "missing.json", BrowserDownloadURL: "https://github.com/actions/runner-images/missing.json"}
+	if _, err := client.DownloadReleaseAsset(ctx, missing); err == nil {
+		t.Error("DownloadReleaseAsset() expected an error for a missing asset")
+	}
+}
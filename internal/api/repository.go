@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// Repository is the client's repository, as seen by the token
+type Repository struct {
+	FullName   string `json:"full_name"`
+	Visibility string `json:"visibility"` // public, private, or internal
+	Archived   bool   `json:"archived"`
+	// Permissions are the permissions of the token's user on the repository
+	Permissions struct {
+		Admin bool `json:"admin"`
+		Push  bool `json:"push"`
+		Pull  bool `json:"pull"`
+	} `json:"permissions"`
+}
+
+// GetRepository fetches the client's repository
+func (c *Client) GetRepository(ctx context.Context) (*Repository, error) {
+	var r Repository
+	if err := c.get(ctx, fmt.Sprintf("repos/%s/%s", c.owner, c.repo), &r); err != nil {
+		return nil, fmt.Errorf("failed to fetch repository %s/%s: %w", c.owner, c.repo, err)
+	}
+	return &r, nil
+}
+
+// RateLimit is the core rate limit of the token
+type RateLimit struct {
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+}
+
+// GetRateLimit fetches the core rate limit of the token. The request does not
+// count against the limit, so it checks that the API is reachable and
+// accepts the token.
+func (c *Client) GetRateLimit(ctx context.Context) (*RateLimit, error) {
+	var response struct {
+		Resources struct {
+			Core RateLimit `json:"core"`
+		} `json:"resources"`
+	}
+	if err := c.get(ctx, "rate_limit", &response); err != nil {
+		return nil, fmt.Errorf("failed to reach the GitHub API on %s: %w", c.host, err)
+	}
+	return &response.Resources.Core, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// ErrRunnerUnverified is returned by CheckRunnerLabel when the runners or
+// settings a label depends on cannot be read (missing permissions, network
+// errors)
+var ErrRunnerUnverified = errors.New("runner availability could not be verified")
+
+// SupportsHostedRunners reports whether GitHub-hosted runners can be used on
+// host: github.com and GHE.com can, GitHub Enterprise Server cannot
+func SupportsHostedRunners(host string) bool {
+	host = strings.ToLower(host)
+	return host == "" || host == "github.com" || strings.HasSuffix(host, ".ghe.com")
+}
+
+// CheckRunnerLabel verifies that jobs of the client's repository can run on
+// label before workflows are switched to it. GitHub Actions must be enabled
+// for the repository. GitHub-hosted labels (hosted) also require a host with
+// GitHub-hosted runners; other labels must belong to a self-hosted runner of
+// the repository or its organization, or to a larger runner of the
+// organization. Errors wrap ErrRunnerUnverified when what the check depends
+// on cannot be read.
+func (c *Client) CheckRunnerLabel(ctx context.Context, label string, hosted bool) error {
+	if hosted && !SupportsHostedRunners(c.host) {
+		return fmt.Errorf("%s is a GitHub-hosted runner, which is not available on %s (GitHub Enterprise Server)", label, c.host)
+	}
+
+	var permissions struct {
+		Enabled bool `json:"enabled"`
+	}
+	// Only repository administrators can read the permissions. Hosted runners
+	// are available whenever Actions is enabled, so they are assumed to be
+	// when the permissions cannot be read.
+	err := c.get(ctx, fmt.Sprintf("repos/%s/%s/actions/permissions", c.owner, c.repo), &permissions)
+	switch {
+	case err == nil && !permissions.Enabled:
+		return fmt.Errorf("GitHub Actions is disabled for %s/%s", c.owner, c.repo)
+	case err != nil && (!hosted || !isForbidden(err)):
+		return fmt.Errorf("%w: failed to fetch the GitHub Actions permissions of %s/%s: %w", ErrRunnerUnverified, c.owner, c.repo, err)
+	}
+	if hosted {
+		return nil
+	}
+
+	labels, err := c.runnerLabels(ctx)
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("no runner of %s/%s or its organization has the label %s", c.owner, c.repo, label)
+}
+
+// runnerLabels returns the labels of the self-hosted runners of the client's
+// repository and organization, and the names of the organization's larger
+// runners, which are their labels. Organization runners are skipped for
+// repositories of users. The labels found so far are returned with an error
+// wrapping ErrRunnerUnverified when a list cannot be read.
+func (c *Client) runnerLabels(ctx context.Context) ([]string, error) {
+	var labels []string
+	var unverified error
+
+	type runnersResponse struct {
+		Runners []struct {
+			Name   string `json:"name"`
+			Labels []struct {
+				Name string `json:"name"`
+			} `json:"labels"`
+		} `json:"runners"`
+	}
+	lists := []struct {
+		path  string
+		named bool // Runners are targeted by name (larger runners)
+		org   bool
+	}{
+		{path: fmt.Sprintf("repos/%s/%s/actions/runners?per_page=100", c.owner, c.repo)},
+		{path: fmt.Sprintf("orgs/%s/actions/runners?per_page=100", c.owner), org: true},
+		{path: fmt.Sprintf("orgs/%s/actions/hosted-runners?per_page=100", c.owner), named: true, org: true},
+	}
+	for _, list := range lists {
+		var response runnersResponse
+		if err := c.get(ctx, list.path, &response); err != nil {
+			var httpErr *api.HTTPError
+			switch {
+			case list.org && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound:
+				// The owner is a user
+			default:
+				unverified = fmt.Errorf("%w: failed to list runners: %w", ErrRunnerUnverified, err)
+			}
+			continue
+		}
+		for _, r := range response.Runners {
+			if list.named {
+				labels = append(labels, r.Name)
+			}
+			for _, l := range r.Labels {
+				labels = append(labels, l.Name)
+			}
+		}
+	}
+	return labels, unverified
+}
+
+// isForbidden reports whether err is a GitHub API error caused by missing
+// permissions: forbidden, or not found for resources hidden from the token
+func isForbidden(err error) bool {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return (httpErr.StatusCode == http.StatusForbidden && !IsRateLimited(err)) || httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusUnauthorized
+}
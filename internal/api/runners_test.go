@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// handlerTransport serves requests with a handler instead of the network
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func TestSupportsHostedRunners(t *testing.T) {
+	for host, want := range map[string]bool{
+		"":                   true,
+		"github.com":         true,
+		"octocorp.ghe.com":   true,
+		"github.example.com": false,
+	} {
+		if got := SupportsHostedRunners(host); got != want {
+			t.Errorf("SupportsHostedRunners(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestCheckRunnerLabel(t *testing.T) {
+	// responses maps API paths to JSON bodies; other paths are not found
+	newClient := func(t *testing.T, host string, responses map[string]string) *Client {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := responses[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"message":"Not Found"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, body)
+		})
+		rest, err := api.NewRESTClient(api.ClientOptions{Host: host, AuthToken: "token", Transport: handlerTransport{handler}})
+		if err != nil {
+			t.Fatalf("NewRESTClient() unexpected error: %v", err)
+		}
+		return &Client{restClient: rest, host: host, owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	}
+	enabled := map[string]string{"/repos/octo/app/actions/permissions": `{"enabled":true}`}
+	ctx := context.Background()
+
+	if err := newClient(t, "github.com", enabled).CheckRunnerLabel(ctx, "ubuntu-slim", true); err != nil {
+		t.Errorf("CheckRunnerLabel(ubuntu-slim) unexpected error: %v", err)
+	}
+	// Non-administrators cannot read the permissions
+	if err := newClient(t, "github.com", nil).CheckRunnerLabel(ctx, "ubuntu-slim", true); err != nil {
+		t.Errorf("CheckRunnerLabel(ubuntu-slim) without permissions unexpected error: %v", err)
+	}
+	disabled := map[string]string{"/repos/octo/app/actions/permissions": `{"enabled":false}`}
+	if err := newClient(t, "github.com", disabled).CheckRunnerLabel(ctx, "ubuntu-slim", true); err == nil || errors.Is(err, ErrRunnerUnverified) {
+		t.Errorf("CheckRunnerLabel() with Actions disabled error = %v, want a failure", err)
+	}
+	if err := newClient(t, "github.example.com", enabled).CheckRunnerLabel(ctx, "ubuntu-slim", true); err == nil {
+		t.Error("CheckRunnerLabel() on GitHub Enterprise Server expected an error")
+	}
+
+	runners := map[string]string{
+		"/repos/octo/app/actions/permissions": `{"enabled":true}`,
+		"/repos/octo/app/actions/runners":     `{"runners":[{"name":"box","labels":[{"name":"self-hosted"},{"name":"linux"}]}]}`,
+		"/orgs/octo/actions/runners":          `{"runners":[]}`,
+		"/orgs/octo/actions/hosted-runners":   `{"runners":[{"name":"ubuntu-4core","labels":[]}]}`,
+	}
+	for _, label := range []string{"linux", "ubuntu-4core"} {
+		if err := newClient(t, "github.com", runners).CheckRunnerLabel(ctx, label, false); err != nil {
+			t.Errorf("CheckRunnerLabel(%s) unexpected error: %v", label, err)
+		}
+	}
+	if err := newClient(t, "github.com", runners).CheckRunnerLabel(ctx, "gpu", false); err == nil || errors.Is(err, ErrRunnerUnverified) {
+		t.Errorf("CheckRunnerLabel(gpu) error = %v, want a failure", err)
+	}
+	if err := newClient(t, "github.com", enabled).CheckRunnerLabel(ctx, "gpu", false); !errors.Is(err, ErrRunnerUnverified) {
+		t.Errorf("CheckRunnerLabel(gpu) without access to the runners error = %v, want ErrRunnerUnverified", err)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxUsageRuns is the largest number of runs of a workflow inspected by
+// GetJobRuns, to bound the number of API requests
+const maxUsageRuns = 500
+
+// JobRun is a job of a completed workflow run
+type JobRun struct {
+	RunID      int64
+	Name       string // Job name as reported by the API
+	Conclusion string // e.g., success, failure, cancelled
+	Labels     []string
+	StartedAt  time.Time
+	Duration   time.Duration
+}
+
+// GetJobRuns returns the jobs of the completed runs of a workflow created
+// since the given time, including re-run attempts, inspecting at most
+// maxUsageRuns runs. Jobs without timing information (e.g., skipped jobs)
+// are left out. Every run is included, regardless of the run filter.
+func (c *Client) GetJobRuns(ctx context.Context, workflowPath string, since time.Time) ([]JobRun, error) {
+	encodedPath := strings.ReplaceAll(workflowPath, "/", "%2F")
+	created := url.QueryEscape(">=" + since.UTC().Format("2006-01-02"))
+
+	var jobRuns []JobRun
+	for page := 1; (page-1)*maxRunsPerPage < maxUsageRuns; page++ {
+		path := fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?status=completed&created=%s&per_page=%d&page=%d", c.owner, c.repo, encodedPath, created, maxRunsPerPage, page)
+		var response workflowRunsResponse
+		if err := c.get(ctx, path, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch workflow runs: %w", err)
+		}
+
+		for _, run := range response.WorkflowRuns {
+			jobs, err := c.getRunJobs(ctx, run.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, j := range jobs {
+				d, err := parseJobDuration(&j, j.Name)
+				if err != nil {
+					continue
+				}
+				jobRuns = append(jobRuns, JobRun{RunID: run.ID, Name: j.Name, Conclusion: j.Conclusion, Labels: j.Labels, StartedAt: d.StartedAt, Duration: d.Duration})
+			}
+		}
+		if len(response.WorkflowRuns) < maxRunsPerPage {
+			break
+		}
+	}
+	return jobRuns, nil
+}
+
+// getRunJobs returns the jobs of every attempt of a workflow run
+func (c *Client) getRunJobs(ctx context.Context, runID int64) ([]job, error) {
+	var jobs []job
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?filter=all&per_page=100&page=%d", c.owner, c.repo, runID, page)
+		var response jobsResponse
+		if err := c.get(ctx, path, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+		}
+		jobs = append(jobs, response.Jobs...)
+		if len(response.Jobs) < 100 {
+			return jobs, nil
+		}
+	}
+}
+
+// MatchesJob reports whether a job name reported by the API is the job with
+// the given ID and display name, or one of its former display names,
+// including its matrix instances (e.g., "test (ubuntu-latest, 20)"). Names
+// are compared case-insensitively.
+func MatchesJob(name, jobID, jobDisplayName string, formerNames []string) bool {
+	if i := strings.LastIndex(name, " ("); i > 0 && strings.HasSuffix(name, ")") {
+		if MatchesJob(name[:i], jobID, jobDisplayName, formerNames) {
+			return true
+		}
+	}
+	if strings.EqualFold(name, jobDisplayName) || strings.EqualFold(name, jobID) {
+		return true
+	}
+	for _, former := range formerNames {
+		if strings.EqualFold(name, former) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// ErrVariableNotFound is returned by GetVariable when neither the repository
+// nor its organization has the variable
+var ErrVariableNotFound = errors.New("variable not found")
+
+// variable is a configuration variable of GitHub Actions
+type variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// GetVariable returns the value of a configuration variable (vars.NAME) of
+// the client's repository, or of its organization if the repository has none
+// with that name, as repository variables take precedence in workflows.
+// Environment variables are not looked up. Returns an error wrapping
+// ErrVariableNotFound if neither has it.
+func (c *Client) GetVariable(ctx context.Context, name string) (string, error) {
+	var v variable
+	err := c.get(ctx, fmt.Sprintf("repos/%s/%s/actions/variables/%s", c.owner, c.repo, name), &v)
+	var httpErr *api.HTTPError
+	switch {
+	case err == nil:
+		return v.Value, nil
+	case !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound:
+		return "", fmt.Errorf("failed to fetch variable %s of %s/%s: %w", name, c.owner, c.repo, err)
+	}
+
+	// Organization variables shared with the repository; the owner of a
+	// repository of a user has none
+	var response struct {
+		Variables []variable `json:"variables"`
+	}
+	err = c.get(ctx, fmt.Sprintf("repos/%s/%s/actions/organization-variables?per_page=100", c.owner, c.repo), &response)
+	if err != nil && (!errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound) {
+		return "", fmt.Errorf("failed to list organization variables of %s/%s: %w", c.owner, c.repo, err)
+	}
+	for _, v := range response.Variables {
+		if strings.EqualFold(v.Name, name) {
+			return v.Value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s in %s/%s or its organization", ErrVariableNotFound, name, c.owner, c.repo)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestGetVariable(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/octo/app/actions/variables/DEFAULT_RUNNER":
+			io.WriteString(w, `{"name":"DEFAULT_RUNNER","value":"ubuntu-latest"}`)
+		case "/repos/octo/app/actions/organization-variables":
+			io.WriteString(w, `{"total_count":2,"variables":[{"name":"ORG_RUNNER","value":"ubuntu-24.04"},{"name":"DEFAULT_RUNNER","value":"windows-latest"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	})
+	rest, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: handlerTransport{handler}})
+	if err != nil {
+		t.Fatalf("NewRESTClient() unexpected error: %v", err)
+	}
+	client := &Client{restClient: rest, host: "github.com", owner: "octo", repo: "app", limiter: &rateLimiter{}}
+	ctx := context.Background()
+
+	// Repository variables take precedence over organization variables
+	for name, want := range map[string]string{"DEFAULT_RUNNER": "ubuntu-latest", "org_runner": "ubuntu-24.04"} {
+		got, err := client.GetVariable(ctx, name)
+		if err != nil || got != want {
+			t.Errorf("GetVariable(%s) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := client.GetVariable(ctx, "MISSING"); !errors.Is(err, ErrVariableNotFound) {
+		t.Errorf("GetVariable(MISSING) error = %v, want ErrVariableNotFound", err)
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName is the directory name used under the user cache and state directories
+const appName = "gh-slimify"
+
+// root, if set with SetRoot, holds the cache and state directories instead of
+// the user directories
+var root string
+
+// SetRoot stores caches in root/cache and state in root/state instead of the
+// user cache and state directories (--state-dir)
+func SetRoot(dir string) {
+	root = dir
+}
+
+// Dir returns the cache directory for gh-slimify (e.g., ~/.cache/gh-slimify on Linux).
+// It respects XDG_CACHE_HOME via os.UserCacheDir.
+func Dir() (string, error) {
+	if root != "" {
+		return filepath.Join(root, "cache"), nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user cache directory: %w", err)
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// StateDir returns the directory for data kept across runs that, unlike
+// caches, cannot be recomputed (e.g., ~/.local/state/gh-slimify on Linux).
+// It respects XDG_STATE_HOME, and uses the user config directory on macOS
+// and Windows, which have no state directory.
+func StateDir() (string, error) {
+	if root != "" {
+		return filepath.Join(root, "state"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine user state directory: %w", err)
+		}
+		return filepath.Join(base, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", appName), nil
+}
+
+// ReadJSON reads a JSON file into v.
+// It returns an error satisfying os.IsNotExist if the file does not exist.
+func ReadJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteJSON writes v as indented JSON to path, creating parent directories.
+// The file is written to a temporary file first and renamed into place so
+// readers never observe a partially written file.
+func WriteJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return WriteFile(path, append(data, '\n'))
+}
+
+// WriteFile writes data to path like WriteJSON: parent directories are
+// created, and the file is renamed into place once fully written.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file in %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStateDir(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("XDG_STATE_HOME is not used on this platform")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+
+	dir, err := StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() unexpected error: %v", err)
+	}
+	if want := filepath.Join(home, ".local", "state", appName); dir != want {
+		t.Errorf("StateDir() = %s, want %s", dir, want)
+	}
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", xdg)
+	if dir, _ := StateDir(); dir != filepath.Join(xdg, appName) {
+		t.Errorf("StateDir() = %s, want it under XDG_STATE_HOME %s", dir, xdg)
+	}
+}
+
+func TestSetRoot(t *testing.T) {
+	root := t.TempDir()
+	SetRoot(root)
+	t.Cleanup(func() { SetRoot("") })
+
+	if dir, _ := Dir(); dir != filepath.Join(root, "cache") {
+		t.Errorf("Dir() = %s, want %s", dir, filepath.Join(root, "cache"))
+	}
+	if dir, _ := StateDir(); dir != filepath.Join(root, "state") {
+		t.Errorf("StateDir() = %s, want %s", dir, filepath.Join(root, "state"))
+	}
+}
@@ -0,0 +1,155 @@
+// Package config loads the optional repository configuration file (.slimify.yml).
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the configuration file loaded from the repository root
+const DefaultPath = ".slimify.yml"
+
+// Config is the gh-slimify configuration
+type Config struct {
+	// Templates customize the text generated by fix --commit and fix --pr
+	Templates Templates `yaml:"templates"`
+	// Rules enables and disables migration rules
+	Rules Rules `yaml:"rules"`
+	// ContainerTools extends the catalog of container tools whose commands and
+	// actions make a job ineligible
+	ContainerTools []ContainerTool `yaml:"container_tools"`
+	// ScriptInputs lists actions whose with: inputs hold shell scripts to
+	// analyze like run: steps (e.g., the script input of an SSH action)
+	ScriptInputs []ScriptInput `yaml:"script_inputs"`
+	// RenamedJobs maps jobs, as "<workflow-path>:<job-id>", to the display names
+	// they had before being renamed, so durations can be looked up in past runs
+	RenamedJobs map[string][]string `yaml:"renamed_jobs"`
+	// Pricing overrides the price per minute of the runners used to estimate
+	// costs with --billing
+	Pricing Pricing `yaml:"pricing"`
+	// WorkflowsDir is the directory scanned for workflows instead of
+	// .github/workflows (e.g., .gitea/workflows). --workflows-dir overrides it.
+	WorkflowsDir string `yaml:"workflows_dir"`
+	// MaxDuration demotes safe jobs running longer than it (e.g., 15m) to
+	// requiring attention. --max-duration overrides it.
+	MaxDuration time.Duration `yaml:"max_duration"`
+	// Footprint sets the thresholds of the disk and memory footprint rules
+	// (SLIM012, SLIM013)
+	Footprint Footprint `yaml:"footprint"`
+	// Enforce configures the "slim by default" policy checked by enforce
+	Enforce Enforce `yaml:"enforce"`
+}
+
+// Enforce configures the "slim by default" policy
+type Enforce struct {
+	// Allow lists the jobs, as "<workflow>:<job-id>" where the workflow may be
+	// a glob pattern, allowed to stay on ubuntu-latest when added or modified
+	Allow []string `yaml:"allow"`
+}
+
+// Footprint sets the thresholds of the footprint rules. Heavy paths and
+// commands are added to the built-in ones.
+type Footprint struct {
+	// MaxHeap is the largest heap (e.g., 4g) a job may give the JVM (-Xmx) or
+	// Node.js (--max-old-space-size) without being reported. Defaults to 3g.
+	MaxHeap string `yaml:"max_heap"`
+	// HeavyPaths are cache paths growing to several GB (e.g., ~/.cache/my-tool)
+	HeavyPaths []string `yaml:"heavy_paths"`
+	// HeavyCommands are build tools whose builds need much memory (e.g., buck2)
+	HeavyCommands []string `yaml:"heavy_commands"`
+}
+
+// Pricing is the price per minute of the runners, in USD. Zero values keep
+// the default price.
+type Pricing struct {
+	UbuntuLatest float64 `yaml:"ubuntu_latest"`
+	UbuntuSlim   float64 `yaml:"ubuntu_slim"`
+	UbuntuArm    float64 `yaml:"ubuntu_arm"`
+	Larger       float64 `yaml:"larger"`
+}
+
+// ContainerTool is a container tool added to the catalog. A tool with the name
+// of a built-in one (e.g., podman) adds patterns and prefixes to it.
+type ContainerTool struct {
+	Name string `yaml:"name"`
+	// Commands are regular expressions matched from the command name (e.g., '\bfinch\s+build\b')
+	Commands []string `yaml:"commands"`
+	// Actions are prefixes of uses: values (e.g., "my-org/image-build")
+	Actions []string `yaml:"actions"`
+}
+
+// ScriptInput names the with: inputs of an action that hold shell scripts
+type ScriptInput struct {
+	// Action is the action without a version (e.g., "appleboy/ssh-action")
+	Action string `yaml:"action"`
+	// Inputs are the with: keys holding scripts (e.g., ["script"])
+	Inputs []string `yaml:"inputs"`
+}
+
+// Rules lists rules to disable or enable, by ID (e.g., SLIM007) or name
+// (e.g., missing-commands). Enable takes precedence over Disable.
+type Rules struct {
+	Disable []string `yaml:"disable"`
+	Enable  []string `yaml:"enable"`
+	// Severity maps rules, by ID or name, to the severity of their findings:
+	// error, warning, info, or off (e.g., docker-commands: warning).
+	// unknown-duration sets the severity of unknown execution times.
+	Severity map[string]string `yaml:"severity"`
+}
+
+// Load reads the configuration file at path. A missing file is not an error:
+// the default configuration is returned instead. Templates are validated on load
+// so that mistakes are reported before any workflow is modified.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	cfg.Templates.setDefaults()
+	if err := cfg.Templates.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	renamed, err := normalizeJobKeys(cfg.RenamedJobs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: renamed_jobs: %w", path, err)
+	}
+	cfg.RenamedJobs = renamed
+	if cfg.Pricing.UbuntuLatest < 0 || cfg.Pricing.UbuntuSlim < 0 || cfg.Pricing.UbuntuArm < 0 || cfg.Pricing.Larger < 0 {
+		return nil, fmt.Errorf("invalid config %s: pricing: prices must not be negative", path)
+	}
+	if cfg.MaxDuration < 0 {
+		return nil, fmt.Errorf("invalid config %s: max_duration must not be negative", path)
+	}
+	return cfg, nil
+}
+
+// normalizeJobKeys cleans the workflow paths of "<workflow-path>:<job-id>" keys
+// (e.g., "./.github/workflows/ci.yml:build" becomes ".github/workflows/ci.yml:build")
+func normalizeJobKeys(m map[string][]string) (map[string][]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	normalized := make(map[string][]string, len(m))
+	for key, names := range m {
+		i := strings.LastIndex(key, ":")
+		if i <= 0 || i == len(key)-1 {
+			return nil, fmt.Errorf("%q is not <workflow-path>:<job-id>", key)
+		}
+		path := filepath.ToSlash(filepath.Clean(key[:i]))
+		normalized[path+":"+key[i+1:]] = append(normalized[path+":"+key[i+1:]], names...)
+	}
+	return normalized, nil
+}
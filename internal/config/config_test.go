@@ -0,0 +1,259 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), DefaultPath))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Templates.Branch != DefaultBranchTemplate {
+		t.Errorf("Branch = %q, want default", cfg.Templates.Branch)
+	}
+	if cfg.Templates.PRBody != DefaultPRBodyTemplate {
+		t.Errorf("PRBody = %q, want default", cfg.Templates.PRBody)
+	}
+}
+
+func TestLoad_Templates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := `templates:
+  branch: "ci/slim-{{trimExt (base .Workflow)}}"
+  commit_message: "ci: use ubuntu-slim for {{join .Jobs \", \"}} ({{.Savings}})"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	data := TemplateData{
+		Workflow:  ".github/workflows/lint.yml",
+		Workflows: []string{".github/workflows/lint.yml"},
+		Jobs: []TemplateJob{
+			{Workflow: ".github/workflows/lint.yml", ID: "lint", Name: "Lint", Duration: "2m"},
+			{Workflow: ".github/workflows/lint.yml", ID: "fmt", Name: "Format", Duration: "unknown"},
+		},
+		Count:   2,
+		Savings: "2m",
+		Date:    "20250101",
+	}
+
+	branch, err := cfg.Templates.RenderBranch(data)
+	if err != nil {
+		t.Fatalf("RenderBranch() error = %v", err)
+	}
+	if branch != "ci/slim-lint" {
+		t.Errorf("RenderBranch() = %q, want %q", branch, "ci/slim-lint")
+	}
+
+	message, err := cfg.Templates.RenderCommitMessage(data)
+	if err != nil {
+		t.Fatalf("RenderCommitMessage() error = %v", err)
+	}
+	if want := "ci: use ubuntu-slim for Lint, Format (2m)"; message != want {
+		t.Errorf("RenderCommitMessage() = %q, want %q", message, want)
+	}
+
+	// Templates that are not configured fall back to the defaults
+	title, err := cfg.Templates.RenderPRTitle(data)
+	if err != nil {
+		t.Fatalf("RenderPRTitle() error = %v", err)
+	}
+	if title != "Migrate 2 job(s) to ubuntu-slim" {
+		t.Errorf("RenderPRTitle() = %q", title)
+	}
+	body, err := cfg.Templates.RenderPRBody(data)
+	if err != nil {
+		t.Fatalf("RenderPRBody() error = %v", err)
+	}
+	if !strings.Contains(body, "- `.github/workflows/lint.yml`: Format (last execution time: unknown)") {
+		t.Errorf("RenderPRBody() = %q, missing job line", body)
+	}
+}
+
+func TestLoad_InvalidTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte("templates:\n  pr_title: \"{{.Count\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "pr_title") {
+		t.Errorf("Load() error = %v, want error mentioning pr_title", err)
+	}
+}
+
+func TestLoad_Rules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := `rules:
+  disable: [SLIM007, credentials]
+  enable: [credentials]
+  severity:
+    docker-commands: warning
+    unknown-duration: "off"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if strings.Join(cfg.Rules.Disable, ",") != "SLIM007,credentials" {
+		t.Errorf("Rules.Disable = %v", cfg.Rules.Disable)
+	}
+	if strings.Join(cfg.Rules.Enable, ",") != "credentials" {
+		t.Errorf("Rules.Enable = %v", cfg.Rules.Enable)
+	}
+	if cfg.Rules.Severity["docker-commands"] != "warning" || cfg.Rules.Severity["unknown-duration"] != "off" {
+		t.Errorf("Rules.Severity = %v", cfg.Rules.Severity)
+	}
+}
+
+func TestLoad_ContainerTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := `container_tools:
+  - name: finch
+    commands: ['\bfinch\s+build\b']
+    actions: [my-org/image-build]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.ContainerTools) != 1 {
+		t.Fatalf("ContainerTools = %+v, want 1 tool", cfg.ContainerTools)
+	}
+	tool := cfg.ContainerTools[0]
+	if tool.Name != "finch" || len(tool.Commands) != 1 || tool.Commands[0] != `\bfinch\s+build\b` || len(tool.Actions) != 1 {
+		t.Errorf("ContainerTools[0] = %+v", tool)
+	}
+}
+
+func TestLoad_ScriptInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := `script_inputs:
+  - action: appleboy/ssh-action
+    inputs: [script, script_stop]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.ScriptInputs) != 1 || cfg.ScriptInputs[0].Action != "appleboy/ssh-action" || len(cfg.ScriptInputs[0].Inputs) != 2 {
+		t.Errorf("ScriptInputs = %+v, want appleboy/ssh-action with 2 inputs", cfg.ScriptInputs)
+	}
+}
+
+func TestLoad_RenamedJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := `renamed_jobs:
+  "./.github/workflows/ci.yml:build": ["Compile", "Build and test"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if names := cfg.RenamedJobs[".github/workflows/ci.yml:build"]; strings.Join(names, ",") != "Compile,Build and test" {
+		t.Errorf("RenamedJobs = %v", cfg.RenamedJobs)
+	}
+
+	if err := os.WriteFile(path, []byte("renamed_jobs:\n  build: [Compile]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("Load() should reject a key without a job ID")
+	}
+}
+
+func TestLoad_Pricing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte("pricing:\n  ubuntu_latest: 0.008\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Pricing.UbuntuLatest != 0.008 || cfg.Pricing.UbuntuSlim != 0 {
+		t.Errorf("Pricing = %+v, want ubuntu_latest 0.008 and the default ubuntu_slim", cfg.Pricing)
+	}
+
+	if err := os.WriteFile(path, []byte("pricing:\n  ubuntu_slim: -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() with a negative price: expected error, got nil")
+	}
+}
+
+func TestLoad_WorkflowsDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte("workflows_dir: .gitea/workflows\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.WorkflowsDir != ".gitea/workflows" {
+		t.Errorf("WorkflowsDir = %q, want .gitea/workflows", cfg.WorkflowsDir)
+	}
+}
+
+func TestLoad_MaxDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte("max_duration: 15m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.MaxDuration != 15*time.Minute {
+		t.Errorf("MaxDuration = %v, want 15m", cfg.MaxDuration)
+	}
+
+	if err := os.WriteFile(path, []byte("max_duration: -1m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() with a negative max_duration: expected error, got nil")
+	}
+}
+
+func TestLoad_Footprint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	content := "footprint:\n  max_heap: 4g\n  heavy_paths: [~/.cache/buck]\n  heavy_commands: [buck2]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Footprint.MaxHeap != "4g" || len(cfg.Footprint.HeavyPaths) != 1 || cfg.Footprint.HeavyPaths[0] != "~/.cache/buck" || len(cfg.Footprint.HeavyCommands) != 1 || cfg.Footprint.HeavyCommands[0] != "buck2" {
+		t.Errorf("Footprint = %+v, want the configured thresholds", cfg.Footprint)
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// Default templates for generated git and pull request text
+const (
+	DefaultBranchTemplate        = `gh-slimify/ubuntu-slim-{{.Date}}`
+	DefaultCommitMessageTemplate = `Migrate {{.Count}} job(s) to ubuntu-slim
+
+{{range .Jobs}}- {{.Workflow}}: {{.Name}}
+{{end}}`
+	DefaultPRTitleTemplate = `Migrate {{.Count}} job(s) to ubuntu-slim`
+	DefaultPRBodyTemplate  = `This PR moves the following jobs from ` + "`ubuntu-latest`" + ` to the lightweight ` + "`ubuntu-slim`" + ` runner:
+
+{{range .Jobs}}- ` + "`{{.Workflow}}`" + `: {{.Name}} (last execution time: {{.Duration}})
+{{end}}
+Runner time moved to ubuntu-slim per run: {{.Savings}}
+
+Generated by [gh-slimify](https://github.com/fchimpan/gh-slimify).
+`
+)
+
+// Templates are Go templates (text/template) for the text generated when
+// committing fixes and opening pull requests. They are executed with TemplateData.
+type Templates struct {
+	Branch        string `yaml:"branch"`
+	CommitMessage string `yaml:"commit_message"`
+	PRTitle       string `yaml:"pr_title"`
+	PRBody        string `yaml:"pr_body"`
+}
+
+// TemplateData is the data available to templates
+type TemplateData struct {
+	// Workflow is the updated workflow path, or a comma-separated list of paths
+	// when several workflows were updated
+	Workflow string
+	// Workflows lists the updated workflow paths
+	Workflows []string
+	// Jobs lists the updated jobs
+	Jobs []TemplateJob
+	// Count is the number of updated jobs
+	Count int
+	// Savings is the runner time moved to ubuntu-slim per run, summed over the last
+	// execution times of the updated jobs (e.g., "12m30s"), or "unknown"
+	Savings string
+	// Date is the current date as YYYYMMDD, useful for unique branch names
+	Date string
+}
+
+// TemplateJob is an updated job in TemplateData
+type TemplateJob struct {
+	Workflow string
+	ID       string
+	Name     string
+	Duration string // Last execution time, or "unknown"
+}
+
+// String returns the job name, so {{.Jobs}} and {{join .Jobs ", "}} print job names
+func (j TemplateJob) String() string {
+	return j.Name
+}
+
+// templateFuncs are the functions available to templates
+var templateFuncs = template.FuncMap{
+	// join joins the elements of any slice with sep: {{join .Jobs ", "}}
+	"join": func(list any, sep string) string {
+		v := reflect.ValueOf(list)
+		if v.Kind() != reflect.Slice {
+			return fmt.Sprint(list)
+		}
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(parts, sep)
+	},
+	// base returns the last element of a path: {{base .Workflow}}
+	"base": filepath.Base,
+	// trimExt removes the file extension: {{trimExt (base .Workflow)}}
+	"trimExt": func(path string) string {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// setDefaults fills in empty templates with the defaults
+func (t *Templates) setDefaults() {
+	if t.Branch == "" {
+		t.Branch = DefaultBranchTemplate
+	}
+	if t.CommitMessage == "" {
+		t.CommitMessage = DefaultCommitMessageTemplate
+	}
+	if t.PRTitle == "" {
+		t.PRTitle = DefaultPRTitleTemplate
+	}
+	if t.PRBody == "" {
+		t.PRBody = DefaultPRBodyTemplate
+	}
+}
+
+// validate parses every template
+func (t *Templates) validate() error {
+	for name, text := range t.byName() {
+		if _, err := template.New(name).Funcs(templateFuncs).Parse(text); err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Templates) byName() map[string]string {
+	return map[string]string{
+		"branch":         t.Branch,
+		"commit_message": t.CommitMessage,
+		"pr_title":       t.PRTitle,
+		"pr_body":        t.PRBody,
+	}
+}
+
+// RenderBranch renders the branch name. Surrounding whitespace is trimmed.
+func (t *Templates) RenderBranch(data TemplateData) (string, error) {
+	s, err := render("branch", t.Branch, data)
+	return strings.TrimSpace(s), err
+}
+
+// RenderCommitMessage renders the commit message. Surrounding whitespace is trimmed.
+func (t *Templates) RenderCommitMessage(data TemplateData) (string, error) {
+	s, err := render("commit_message", t.CommitMessage, data)
+	return strings.TrimSpace(s), err
+}
+
+// RenderPRTitle renders the pull request title. Surrounding whitespace is trimmed.
+func (t *Templates) RenderPRTitle(data TemplateData) (string, error) {
+	s, err := render("pr_title", t.PRTitle, data)
+	return strings.TrimSpace(s), err
+}
+
+// RenderPRBody renders the pull request body
+func (t *Templates) RenderPRBody(data TemplateData) (string, error) {
+	return render("pr_body", t.PRBody, data)
+}
+
+// render executes a template with data
+func render(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,270 @@
+// Package foreign provides an experimental, read-only audit of CI configurations
+// from other systems (GitLab CI, CircleCI). It parses them just enough to report
+// docker-in-docker usage and runner tags, so pipelines that could map to
+// ubuntu-slim GitHub runners can be identified during multi-CI consolidation.
+package foreign
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Well-known configuration paths for supported CI systems
+const (
+	GitLabConfigPath   = ".gitlab-ci.yml"
+	CircleCIConfigPath = ".circleci/config.yml"
+)
+
+// Pipeline is an audited CI configuration file
+type Pipeline struct {
+	Path   string
+	System string // "gitlab" or "circleci"
+	Jobs   []*Job
+}
+
+// Job is an audited job from a foreign CI configuration
+type Job struct {
+	Name   string
+	Image  string   // Container image the job runs in, if any
+	Tags   []string // Runner tags (GitLab) or executor/resource class (CircleCI)
+	Docker []string // Evidence of docker usage (docker-in-docker services, docker commands, remote docker)
+	Notes  []string // Hints for mapping the job to GitHub Actions
+}
+
+// CouldUseSlim reports whether the job shows no docker usage and could map to an ubuntu-slim runner
+func (j *Job) CouldUseSlim() bool {
+	return len(j.Docker) == 0
+}
+
+// DefaultPaths returns the well-known foreign CI configuration files that exist
+// in the current directory
+func DefaultPaths() []string {
+	var paths []string
+	for _, path := range []string{GitLabConfigPath, CircleCIConfigPath} {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Audit loads and audits a foreign CI configuration file.
+// The CI system is detected from the file name.
+func Audit(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML %s: %w", path, err)
+	}
+
+	var jobs []*Job
+	var system string
+	switch {
+	case strings.HasSuffix(path, ".gitlab-ci.yml") || strings.HasSuffix(path, ".gitlab-ci.yaml"):
+		system = "gitlab"
+		jobs = auditGitLab(config)
+	case strings.Contains(path, ".circleci"):
+		system = "circleci"
+		jobs = auditCircleCI(config)
+	default:
+		return nil, fmt.Errorf("unsupported CI configuration %s: expected %s or %s", path, GitLabConfigPath, CircleCIConfigPath)
+	}
+
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].Name < jobs[k].Name })
+	return &Pipeline{Path: path, System: system, Jobs: jobs}, nil
+}
+
+// gitLabReservedKeys are top-level GitLab CI keywords that are not jobs
+var gitLabReservedKeys = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true, "workflow": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true,
+}
+
+// auditGitLab audits the jobs of a GitLab CI configuration
+func auditGitLab(config map[string]any) []*Job {
+	// Global defaults are inherited by jobs that do not override them
+	defaults, _ := config["default"].(map[string]any)
+	if defaults == nil {
+		defaults = config
+	}
+
+	var jobs []*Job
+	for name, value := range config {
+		def, ok := value.(map[string]any)
+		// Hidden jobs (templates) start with "." and never run
+		if !ok || gitLabReservedKeys[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if _, hasScript := def["script"]; !hasScript {
+			if _, hasTrigger := def["trigger"]; !hasTrigger {
+				continue
+			}
+		}
+
+		job := &Job{Name: name}
+		job.Image = imageName(inherit(def, defaults, "image"))
+		job.Tags = stringList(inherit(def, defaults, "tags"))
+
+		for _, service := range stringList(inherit(def, defaults, "services")) {
+			if strings.Contains(service, "dind") {
+				job.Docker = append(job.Docker, fmt.Sprintf("docker-in-docker service %s", service))
+			}
+		}
+		if strings.HasPrefix(job.Image, "docker:") || job.Image == "docker" {
+			job.Docker = append(job.Docker, fmt.Sprintf("runs in docker image %s", job.Image))
+		}
+		if vars, ok := def["variables"].(map[string]any); ok {
+			if _, ok := vars["DOCKER_HOST"]; ok {
+				job.Docker = append(job.Docker, "sets DOCKER_HOST")
+			}
+		}
+		for _, key := range []string{"before_script", "script", "after_script"} {
+			script := strings.Join(stringList(inherit(def, defaults, key)), "\n")
+			if workflow.ScriptUsesContainerCommands(script) {
+				job.Docker = append(job.Docker, fmt.Sprintf("uses docker commands in %s", key))
+			}
+		}
+
+		if job.Image != "" && job.CouldUseSlim() {
+			job.Notes = append(job.Notes, fmt.Sprintf("replace image %s with a setup action (ubuntu-slim does not support container:)", job.Image))
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// auditCircleCI audits the jobs of a CircleCI configuration
+func auditCircleCI(config map[string]any) []*Job {
+	executors, _ := config["executors"].(map[string]any)
+	jobDefs, _ := config["jobs"].(map[string]any)
+
+	var jobs []*Job
+	for name, value := range jobDefs {
+		def, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// Resolve named executors (executor: name or executor: {name: ...})
+		executor := def
+		namedExecutor := false
+		ref := def["executor"]
+		if m, ok := ref.(map[string]any); ok {
+			ref = m["name"]
+		}
+		if refName, ok := ref.(string); ok {
+			if e, ok := executors[refName].(map[string]any); ok {
+				executor = e
+				namedExecutor = true
+			}
+		}
+
+		job := &Job{Name: name}
+		if images, ok := executor["docker"].([]any); ok && len(images) > 0 {
+			if first, ok := images[0].(map[string]any); ok {
+				job.Image, _ = first["image"].(string)
+			}
+			job.Tags = append(job.Tags, "executor: docker")
+		}
+		if machine, ok := executor["machine"]; ok && machine != false {
+			job.Tags = append(job.Tags, "executor: machine")
+			job.Notes = append(job.Notes, "machine executors provide a full VM; check for docker usage before mapping to ubuntu-slim")
+		}
+		if class, ok := executor["resource_class"].(string); ok {
+			job.Tags = append(job.Tags, "resource_class: "+class)
+		}
+		if class, ok := def["resource_class"].(string); ok && namedExecutor {
+			job.Tags = append(job.Tags, "resource_class: "+class)
+		}
+
+		steps, _ := def["steps"].([]any)
+		for _, step := range steps {
+			switch v := step.(type) {
+			case string:
+				if v == "setup_remote_docker" {
+					job.Docker = append(job.Docker, "uses setup_remote_docker")
+				}
+			case map[string]any:
+				if _, ok := v["setup_remote_docker"]; ok {
+					job.Docker = append(job.Docker, "uses setup_remote_docker")
+				}
+				if workflow.ScriptUsesContainerCommands(circleCIRunCommand(v["run"])) {
+					job.Docker = append(job.Docker, "uses docker commands in run step")
+				}
+			}
+		}
+
+		if job.Image != "" && job.CouldUseSlim() {
+			job.Notes = append(job.Notes, fmt.Sprintf("replace image %s with a setup action (ubuntu-slim does not support container:)", job.Image))
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// circleCIRunCommand returns the command of a CircleCI run step (run: cmd or run: {command: cmd})
+func circleCIRunCommand(run any) string {
+	switch v := run.(type) {
+	case string:
+		return v
+	case map[string]any:
+		command, _ := v["command"].(string)
+		return command
+	default:
+		return ""
+	}
+}
+
+// inherit returns def[key], falling back to defaults[key]
+func inherit(def, defaults map[string]any, key string) any {
+	if v, ok := def[key]; ok {
+		return v
+	}
+	return defaults[key]
+}
+
+// imageName returns the image name from "image: name" or "image: {name: name}"
+func imageName(v any) string {
+	switch image := v.(type) {
+	case string:
+		return image
+	case map[string]any:
+		name, _ := image["name"].(string)
+		return name
+	default:
+		return ""
+	}
+}
+
+// stringList returns the strings in a scalar or list value. List entries that are
+// mappings contribute their "name" (e.g., GitLab services: [{name: docker:dind}]).
+func stringList(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		var out []string
+		for _, item := range val {
+			switch i := item.(type) {
+			case string:
+				out = append(out, i)
+			case map[string]any:
+				if name, ok := i["name"].(string); ok {
+					out = append(out, name)
+				}
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
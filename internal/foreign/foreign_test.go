@@ -0,0 +1,106 @@
+package foreign
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		content    string
+		wantSystem string
+		wantSlim   map[string]bool
+		wantTags   map[string][]string
+	}{
+		{
+			name: "gitlab",
+			path: ".gitlab-ci.yml",
+			content: `stages: [test, build]
+default:
+  tags: [linux]
+.template:
+  script: echo hidden
+lint:
+  image: node:20
+  script:
+    - npm run lint
+build-image:
+  image: docker:24
+  services:
+    - docker:24-dind
+  script:
+    - docker build -t app .
+  tags: [docker, large]
+deploy:
+  script:
+    - docker push app
+`,
+			wantSystem: "gitlab",
+			wantSlim:   map[string]bool{"lint": true, "build-image": false, "deploy": false},
+			wantTags:   map[string][]string{"lint": {"linux"}, "build-image": {"docker", "large"}, "deploy": {"linux"}},
+		},
+		{
+			name: "circleci",
+			path: ".circleci/config.yml",
+			content: `version: 2.1
+executors:
+  node:
+    docker:
+      - image: cimg/node:20.0
+    resource_class: small
+jobs:
+  test:
+    executor: node
+    steps:
+      - checkout
+      - run: npm test
+  image:
+    docker:
+      - image: cimg/base:stable
+    steps:
+      - checkout
+      - setup_remote_docker
+      - run:
+          command: docker build -t app .
+`,
+			wantSystem: "circleci",
+			wantSlim:   map[string]bool{"test": true, "image": false},
+			wantTags:   map[string][]string{"test": {"executor: docker", "resource_class: small"}, "image": {"executor: docker"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.path)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			pipeline, err := Audit(path)
+			if err != nil {
+				t.Fatalf("Audit() unexpected error: %v", err)
+			}
+			if pipeline.System != tt.wantSystem {
+				t.Errorf("Audit() system = %s, want %s", pipeline.System, tt.wantSystem)
+			}
+			if len(pipeline.Jobs) != len(tt.wantSlim) {
+				t.Fatalf("Audit() returned %d jobs, want %d", len(pipeline.Jobs), len(tt.wantSlim))
+			}
+			for _, job := range pipeline.Jobs {
+				if got := job.CouldUseSlim(); got != tt.wantSlim[job.Name] {
+					t.Errorf("job %s CouldUseSlim() = %v, want %v (docker: %v)", job.Name, got, tt.wantSlim[job.Name], job.Docker)
+				}
+				if !reflect.DeepEqual(job.Tags, tt.wantTags[job.Name]) {
+					t.Errorf("job %s tags = %v, want %v", job.Name, job.Tags, tt.wantTags[job.Name])
+				}
+			}
+		})
+	}
+}
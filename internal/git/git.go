@@ -0,0 +1,287 @@
+// Package git runs the git commands used to commit workflow fixes.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// Timeout bounds each git command. Commands talking to a remote (push) are
+// bounded by NetworkTimeout instead.
+var (
+	Timeout        = 30 * time.Second
+	NetworkTimeout = 5 * time.Minute
+)
+
+// ErrDisabled is returned by every command when git usage is disabled (--no-git)
+var ErrDisabled = errors.New("git is disabled (--no-git)")
+
+var disabled bool
+
+// Disable turns off git usage for the rest of the process. Every command
+// fails with ErrDisabled without running git.
+func Disable() {
+	disabled = true
+}
+
+// Error describes a git command that failed or timed out
+type Error struct {
+	Args   []string // Arguments passed to git
+	Stderr string   // Trimmed standard error, if any
+	// Err is the underlying error. It wraps context.DeadlineExceeded if the
+	// command timed out.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("git %s: %v", e.Args[0], e.Err)
+	}
+	return fmt.Sprintf("git %s: %v: %s", e.Args[0], e.Err, e.Stderr)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// run runs git with args, bounded by Timeout, and returns its trimmed
+// standard output. On failure, the error is an *Error.
+func run(args ...string) (string, error) {
+	return runWithTimeout(Timeout, args...)
+}
+
+// runWithTimeout runs git with args, killing it after timeout
+func runWithTimeout(timeout time.Duration, args ...string) (string, error) {
+	out, err := output(timeout, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// output runs git with args, killing it after timeout, and returns its
+// standard output as is
+func output(timeout time.Duration, args ...string) ([]byte, error) {
+	return outputWith(timeout, nil, nil, args...)
+}
+
+// outputWith is output with standard input and environment variables added
+// to the environment of the process
+func outputWith(timeout time.Duration, stdin []byte, env []string, args ...string) ([]byte, error) {
+	if disabled {
+		return nil, ErrDisabled
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+		}
+		return nil, &Error{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return stdout.Bytes(), nil
+}
+
+// CurrentBranch returns the name of the checked out branch
+func CurrentBranch() (string, error) {
+	return run("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// CreateBranch creates a branch from HEAD and checks it out
+func CreateBranch(name string) error {
+	if err := readonly.Check("create branch " + name); err != nil {
+		return err
+	}
+	_, err := run("checkout", "-b", name)
+	return err
+}
+
+// Commit commits the given paths with message. Only the given paths are
+// committed, even if other changes are staged.
+func Commit(message string, paths ...string) error {
+	if err := readonly.Check("commit"); err != nil {
+		return err
+	}
+	if _, err := run(append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+	_, err := run(append([]string{"commit", "-m", message, "--"}, paths...)...)
+	return err
+}
+
+// Push pushes branch to remote and sets it as the upstream
+func Push(remote, branch string) error {
+	if err := readonly.Check("push " + branch); err != nil {
+		return err
+	}
+	_, err := runWithTimeout(NetworkTimeout, "push", "--set-upstream", remote, branch)
+	return err
+}
+
+// TopLevel returns the absolute path of the repository's working tree root
+func TopLevel() (string, error) {
+	return run("rev-parse", "--show-toplevel")
+}
+
+// IsTracked reports whether path is tracked by git (committed or staged)
+func IsTracked(path string) (bool, error) {
+	out, err := run("ls-files", "--full-name", "--", path)
+	if err != nil {
+		return false, err
+	}
+	return out != "", nil
+}
+
+// RemoteURL returns the URL of a remote (e.g., origin)
+func RemoteURL(remote string) (string, error) {
+	return run("remote", "get-url", remote)
+}
+
+// HeadCommit returns the SHA of HEAD
+func HeadCommit() (string, error) {
+	return run("rev-parse", "HEAD")
+}
+
+// RemoteHead returns the default branch of a remote as a remote-tracking ref
+// (e.g., origin/main)
+func RemoteHead(remote string) (string, error) {
+	return run("symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD")
+}
+
+// MergeBase returns the best common ancestor of base and HEAD
+func MergeBase(base string) (string, error) {
+	return run("merge-base", base, "HEAD")
+}
+
+// ChangedFiles returns the files under dir added or modified since the merge
+// base of base and HEAD, including uncommitted and untracked files, relative
+// to the current directory
+func ChangedFiles(base, dir string) ([]string, error) {
+	mergeBase, err := MergeBase(base)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := run("diff", "--name-only", "--diff-filter=d", "--relative", mergeBase, "--", dir)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run("ls-files", "--others", "--exclude-standard", "--", dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(changed+"\n"+untracked, "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ResolveCommit returns the commit a ref (branch, tag, or SHA) points to
+func ResolveCommit(ref string) (string, error) {
+	return run("rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+}
+
+// ShowFile returns the content of a file at ref, where path is relative to
+// the current directory. It fails if path is not a file at ref.
+func ShowFile(ref, path string) ([]byte, error) {
+	return output(Timeout, "cat-file", "blob", ref+":./"+filepath.ToSlash(filepath.Clean(path)))
+}
+
+// ListFiles returns the files under dir at ref, relative to the current
+// directory. It returns dir itself if dir is a file at ref.
+func ListFiles(ref, dir string) ([]string, error) {
+	out, err := output(Timeout, "ls-tree", "-r", "-z", "--name-only", "--end-of-options", ref, "--", dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// CommitFiles creates a commit on top of parent in which files, keyed by
+// path relative to the current directory, have the given content, and returns
+// its SHA. The working tree, the index, and the branches are left untouched:
+// the commit is built in a temporary index.
+func CommitFiles(parent, message string, files map[string][]byte) (string, error) {
+	if err := readonly.Check("commit"); err != nil {
+		return "", err
+	}
+	prefix, err := run("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	index, err := os.CreateTemp("", "slimify-index-*")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if _, err := outputWith(Timeout, nil, env, "read-tree", parent); err != nil {
+		return "", err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		blob, err := outputWith(Timeout, files[path], nil, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return "", err
+		}
+		entry := fmt.Sprintf("100644,%s,%s", strings.TrimSpace(string(blob)), prefix+filepath.ToSlash(filepath.Clean(path)))
+		if _, err := outputWith(Timeout, nil, env, "update-index", "--add", "--cacheinfo", entry); err != nil {
+			return "", err
+		}
+	}
+	tree, err := outputWith(Timeout, nil, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return run("commit-tree", strings.TrimSpace(string(tree)), "-p", parent, "-m", message)
+}
+
+// PushCommit pushes a commit to branch on remote, creating the branch
+func PushCommit(remote, commit, branch string) error {
+	if err := readonly.Check("push " + branch); err != nil {
+		return err
+	}
+	_, err := runWithTimeout(NetworkTimeout, "push", remote, commit+":refs/heads/"+branch)
+	return err
+}
+
+// DeleteRemoteBranch deletes branch on remote
+func DeleteRemoteBranch(remote, branch string) error {
+	if err := readonly.Check("delete branch " + branch); err != nil {
+		return err
+	}
+	_, err := runWithTimeout(NetworkTimeout, "push", remote, "--delete", branch)
+	return err
+}
@@ -0,0 +1,258 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitCmd(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestCommitFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	remote := t.TempDir()
+	repo := t.TempDir()
+	gitCmd(t, "init", "-q", "--bare", remote)
+	t.Chdir(repo)
+	gitCmd(t, "init", "-q")
+	gitCmd(t, "config", "user.name", "test")
+	gitCmd(t, "config", "user.email", "test@example.com")
+	gitCmd(t, "remote", "add", "origin", remote)
+
+	workflows := filepath.Join(".github", "workflows")
+	if err := os.MkdirAll(workflows, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ci := filepath.Join(workflows, "ci.yml")
+	if err := os.WriteFile(ci, []byte("runs-on: ubuntu-latest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, "add", ci)
+	gitCmd(t, "commit", "-q", "-m", "initial")
+	// Uncommitted changes must survive the commit
+	if err := os.WriteFile(ci, []byte("runs-on: ubuntu-latest # local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("notes.txt", []byte("untracked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	statusBefore := gitCmd(t, "status", "--porcelain")
+	headBefore := gitCmd(t, "rev-parse", "HEAD")
+	branchBefore := gitCmd(t, "rev-parse", "--abbrev-ref", "HEAD")
+
+	// Paths are relative to the current directory
+	t.Chdir(workflows)
+	parent, err := HeadCommit()
+	if err != nil {
+		t.Fatalf("HeadCommit() unexpected error: %v", err)
+	}
+	commit, err := CommitFiles(parent, "Migrate", map[string][]byte{
+		"ci.yml":      []byte("runs-on: ubuntu-slim\n"),
+		"release.yml": []byte("runs-on: ubuntu-slim\n"),
+	})
+	if err != nil {
+		t.Fatalf("CommitFiles() unexpected error: %v", err)
+	}
+	t.Chdir(repo)
+
+	if got := gitCmd(t, "ls-tree", "-r", "--name-only", commit); got != ".github/workflows/ci.yml\n.github/workflows/release.yml\n" {
+		t.Errorf("CommitFiles() tree = %q, want ci.yml and release.yml", got)
+	}
+	for _, path := range []string{".github/workflows/ci.yml", ".github/workflows/release.yml"} {
+		if got := gitCmd(t, "cat-file", "blob", commit+":"+path); got != "runs-on: ubuntu-slim\n" {
+			t.Errorf("CommitFiles() %s = %q, want the given content", path, got)
+		}
+	}
+	if got := gitCmd(t, "rev-parse", commit+"^"); got != headBefore {
+		t.Errorf("CommitFiles() parent = %q, want %q", got, headBefore)
+	}
+	if got := gitCmd(t, "status", "--porcelain"); got != statusBefore {
+		t.Errorf("git status after CommitFiles() = %q, want %q", got, statusBefore)
+	}
+	if got := gitCmd(t, "rev-parse", "HEAD"); got != headBefore {
+		t.Errorf("HEAD after CommitFiles() = %q, want %q", got, headBefore)
+	}
+	if got := gitCmd(t, "rev-parse", "--abbrev-ref", "HEAD"); got != branchBefore {
+		t.Errorf("branch after CommitFiles() = %q, want %q", got, branchBefore)
+	}
+
+	if err := PushCommit("origin", commit, "slimify-verify"); err != nil {
+		t.Fatalf("PushCommit() unexpected error: %v", err)
+	}
+	if got := gitCmd(t, "--git-dir", remote, "rev-parse", "refs/heads/slimify-verify"); got != commit+"\n" {
+		t.Errorf("pushed branch = %q, want %q", got, commit)
+	}
+	if err := DeleteRemoteBranch("origin", "slimify-verify"); err != nil {
+		t.Fatalf("DeleteRemoteBranch() unexpected error: %v", err)
+	}
+	if out, err := exec.Command("git", "--git-dir", remote, "rev-parse", "--verify", "-q", "refs/heads/slimify-verify").Output(); err == nil {
+		t.Errorf("branch still exists after DeleteRemoteBranch(): %s", out)
+	}
+	if got := gitCmd(t, "status", "--porcelain"); got != statusBefore {
+		t.Errorf("git status after pushing = %q, want %q", got, statusBefore)
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("exit status 128")
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{Args: []string{"rev-parse", "HEAD"}, Err: cause}, "git rev-parse: exit status 128"},
+		{&Error{Args: []string{"push", "origin"}, Stderr: "fatal: no remote", Err: cause}, "git push: exit status 128: fatal: no remote"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, cause) {
+			t.Errorf("errors.Is(%v, cause) = false, want true", tt.err)
+		}
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		args     []string
+		want     string
+		deadline bool
+	}{
+		{"success", time.Minute, []string{"--version"}, "git version", false},
+		{"failure", time.Minute, []string{"not-a-command"}, "", false},
+		{"timeout", time.Nanosecond, []string{"--version"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runWithTimeout(tt.timeout, tt.args...)
+			if tt.want != "" {
+				if err != nil || !strings.HasPrefix(out, tt.want) {
+					t.Errorf("runWithTimeout() = %q, %v, want %q...", out, err, tt.want)
+				}
+				return
+			}
+			var gitErr *Error
+			if !errors.As(err, &gitErr) {
+				t.Fatalf("runWithTimeout() error = %v, want an *Error", err)
+			}
+			if got := errors.Is(err, context.DeadlineExceeded); got != tt.deadline {
+				t.Errorf("errors.Is(%v, context.DeadlineExceeded) = %v, want %v", err, got, tt.deadline)
+			}
+		})
+	}
+}
+
+func TestDisable(t *testing.T) {
+	t.Cleanup(func() { disabled = false })
+	Disable()
+	if _, err := HeadCommit(); !errors.Is(err, ErrDisabled) {
+		t.Errorf("HeadCommit() after Disable() error = %v, want ErrDisabled", err)
+	}
+	if _, err := ShowFile("HEAD", "ci.yml"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("ShowFile() after Disable() error = %v, want ErrDisabled", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	remote := t.TempDir()
+	repo := t.TempDir()
+	gitCmd(t, "init", "-q", "--bare", remote)
+	t.Chdir(repo)
+	gitCmd(t, "init", "-q", "-b", "main")
+	gitCmd(t, "config", "user.name", "test")
+	gitCmd(t, "config", "user.email", "test@example.com")
+	gitCmd(t, "remote", "add", "origin", remote)
+
+	workflows := filepath.Join(".github", "workflows")
+	if err := os.MkdirAll(workflows, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(workflows, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ci.yml", "on: push\n")
+	write("lint.yml", "on: push\n")
+	write("old.yml", "on: push\n")
+	if err := os.WriteFile("README.md", []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, "add", ".")
+	gitCmd(t, "commit", "-q", "-m", "initial")
+	gitCmd(t, "push", "-q", "origin", "main")
+	gitCmd(t, "remote", "set-head", "origin", "main")
+
+	// The base branch moves on after the feature branch forks: its changes
+	// are not changes of the feature branch
+	gitCmd(t, "checkout", "-q", "-b", "feature")
+	gitCmd(t, "checkout", "-q", "main")
+	write("lint.yml", "on: pull_request\n")
+	gitCmd(t, "commit", "-q", "-am", "lint on pull requests")
+	gitCmd(t, "push", "-q", "origin", "main")
+	gitCmd(t, "checkout", "-q", "feature")
+
+	write("ci.yml", "on: pull_request\n")
+	gitCmd(t, "commit", "-q", "-am", "ci on pull requests")
+	gitCmd(t, "rm", "-q", filepath.Join(workflows, "old.yml"))
+	gitCmd(t, "commit", "-q", "-m", "remove old")
+	// Uncommitted and untracked files are changes too, files outside the
+	// directory are not
+	write("release.yml", "on: push\n")
+	if err := os.WriteFile("README.md", []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := RemoteHead("origin")
+	if err != nil || base != "origin/main" {
+		t.Fatalf("RemoteHead() = %q, %v, want origin/main", base, err)
+	}
+	head, err := HeadCommit()
+	if err != nil || head+"\n" != gitCmd(t, "rev-parse", "feature") {
+		t.Errorf("HeadCommit() = %q, %v, want the feature branch", head, err)
+	}
+	files, err := ChangedFiles(base, workflows)
+	if err != nil {
+		t.Fatalf("ChangedFiles() unexpected error: %v", err)
+	}
+	want := []string{".github/workflows/ci.yml", ".github/workflows/release.yml"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ChangedFiles() = %v, want %v", files, want)
+	}
+
+	// Paths are relative to the current directory
+	t.Chdir(workflows)
+	files, err = ChangedFiles(base, ".")
+	if err != nil || strings.Join(files, ",") != "ci.yml,release.yml" {
+		t.Errorf("ChangedFiles() from the workflows directory = %v, %v, want ci.yml and release.yml", files, err)
+	}
+
+	if _, err := ChangedFiles("origin/missing", "."); err == nil {
+		t.Errorf("ChangedFiles() with a missing base error = nil, want an error")
+	}
+}
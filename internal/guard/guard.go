@@ -0,0 +1,72 @@
+// Package guard protects fix from modifying files it should not touch,
+// such as files outside the workflows directory or symlinks escaping the repository.
+package guard
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/git"
+)
+
+// CheckWorkflowPath returns an error unless path is safe to modify:
+// it must resolve (following symlinks) to a file inside workflowsDir of the
+// current git repository, and that file must be tracked by git.
+// workflowsDir is relative to the repository root (e.g., ".github/workflows"),
+// unless it is absolute.
+func CheckWorkflowPath(path, workflowsDir string) error {
+	root, err := git.TopLevel()
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: cannot determine git repository: %w", path, err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: %w", path, err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: %w", path, err)
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: %w", path, err)
+	}
+	if !within(real, root) {
+		return fmt.Errorf("refusing to modify %s: resolves to %s outside the repository %s", path, real, root)
+	}
+
+	dir := workflowsDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: %w", path, err)
+	}
+	if !within(dir, root) {
+		return fmt.Errorf("refusing to modify %s: workflows directory %s resolves outside the repository", path, workflowsDir)
+	}
+	if !within(real, dir) {
+		return fmt.Errorf("refusing to modify %s: not inside %s", path, workflowsDir)
+	}
+
+	tracked, err := git.IsTracked(real)
+	if err != nil {
+		return fmt.Errorf("refusing to modify %s: %w", path, err)
+	}
+	if !tracked {
+		return fmt.Errorf("refusing to modify %s: not tracked by git", path)
+	}
+	return nil
+}
+
+// within reports whether path is dir or inside dir. Both must be absolute and clean.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,78 @@
+package guard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitCmd(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestCheckWorkflowPath(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	outside := t.TempDir()
+	t.Chdir(repo)
+	gitCmd(t, "init", "-q")
+
+	workflows := filepath.Join(".github", "workflows")
+	if err := os.MkdirAll(workflows, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("on: push\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(workflows, "ci.yml"))
+	write(filepath.Join(workflows, "untracked.yml"))
+	write("other.yml")
+	write(filepath.Join(outside, "evil.yml"))
+	if err := os.Symlink(filepath.Join(outside, "evil.yml"), filepath.Join(workflows, "escape.yml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("ci.yml", filepath.Join(workflows, "alias.yml")); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, "add", filepath.Join(workflows, "ci.yml"), filepath.Join(workflows, "escape.yml"), filepath.Join(workflows, "alias.yml"), "other.yml")
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "tracked workflow", path: filepath.Join(workflows, "ci.yml")},
+		{name: "symlink within workflows", path: filepath.Join(workflows, "alias.yml")},
+		{name: "untracked workflow", path: filepath.Join(workflows, "untracked.yml"), wantErr: "not tracked by git"},
+		{name: "outside workflows directory", path: "other.yml", wantErr: "not inside"},
+		{name: "path traversal", path: filepath.Join(workflows, "..", "..", "other.yml"), wantErr: "not inside"},
+		{name: "symlink escaping the repository", path: filepath.Join(workflows, "escape.yml"), wantErr: "outside the repository"},
+		{name: "missing file", path: filepath.Join(workflows, "missing.yml"), wantErr: "refusing to modify"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckWorkflowPath(tt.path, workflows)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckWorkflowPath(%q) error = %v, want nil", tt.path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckWorkflowPath(%q) error = %v, want error containing %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
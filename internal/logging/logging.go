@@ -0,0 +1,169 @@
+// Package logging sets up the leveled logger (log/slog) diagnostics are
+// written to, so warnings and debug messages can be filtered with --log-level
+// and captured apart from the report with --log-format json and --log-file.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// FormatText writes one line per message, prefixed by its level (e.g.,
+	// "Warning: failed to save action cache error=..."), for people
+	FormatText = "text"
+	// FormatJSON writes one JSON object per message, for automation
+	FormatJSON = "json"
+)
+
+// Options configures the logger set up by Setup
+type Options struct {
+	// Level is the lowest level logged: debug, info, warn, or error. If
+	// empty, warnings and errors are logged.
+	Level string
+	// Format is FormatText or FormatJSON. If empty, FormatText is used.
+	Format string
+	// File is the path messages are appended to. If empty, they are written
+	// to standard error.
+	File string
+}
+
+// ParseLevel parses a level name (debug, info, warn or warning, error),
+// ignoring case
+func ParseLevel(s string) (slog.Level, error) {
+	if strings.EqualFold(s, "warning") {
+		return slog.LevelWarn, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (expected debug, info, warn, or error)", s)
+	}
+	return level, nil
+}
+
+// Setup makes the default logger of log/slog, used for diagnostics by every
+// package, write messages as configured by opts. The log file, if any, stays
+// open until the process exits.
+func Setup(opts Options) error {
+	level := slog.LevelWarn
+	if opts.Level != "" {
+		var err error
+		if level, err = ParseLevel(opts.Level); err != nil {
+			return err
+		}
+	}
+	var w io.Writer = os.Stderr
+	if opts.File != "" {
+		f, err := os.OpenFile(opts.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+	handler, err := NewHandler(w, opts.Format, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler returns a handler writing messages of at least level to w in
+// format (FormatText or FormatJSON; FormatText if empty)
+func NewHandler(w io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	switch format {
+	case "", FormatText:
+		return &textHandler{mu: &sync.Mutex{}, w: w, level: level}, nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q (expected %s or %s)", format, FormatText, FormatJSON)
+}
+
+// textHandler writes messages the way gh-slimify always printed diagnostics:
+// the level as a prefix ("Warning: ", "Error: "), the message, then the
+// attributes as key=value pairs. Info messages have no prefix, and times are
+// left out.
+type textHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Level
+	attrs  string
+	prefix string // Group prefix of attribute keys, e.g., "action."
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		appendAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr writes a as " key=value", quoting values that contain spaces or
+// quotes, and flattening groups into dotted keys
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(" " + prefix + a.Key + "=" + value)
+}
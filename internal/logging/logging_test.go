@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestTextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, FormatText, slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("NewHandler() unexpected error: %v", err)
+	}
+	logger := slog.New(handler)
+	logger.Debug("not logged")
+	logger.Info("Retrying 2 unknown duration(s)")
+	logger.Warn("failed to save action cache", "error", errors.New("disk full"))
+	logger.With("workflow", "ci.yml").WithGroup("job").Error("failed to get duration", "id", "build")
+
+	want := `Retrying 2 unknown duration(s)
+Warning: failed to save action cache error="disk full"
+Error: failed to get duration workflow=ci.yml job.id=build
+`
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, FormatJSON, slog.LevelDebug)
+	if err != nil {
+		t.Fatalf("NewHandler() unexpected error: %v", err)
+	}
+	slog.New(handler).Debug("failed to resolve action", "action", "owner/repo@v1")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "failed to resolve action" || record["action"] != "owner/repo@v1" {
+		t.Errorf("record = %v", record)
+	}
+}
+
+func TestNewHandler_InvalidFormat(t *testing.T) {
+	if _, err := NewHandler(&bytes.Buffer{}, "xml", slog.LevelWarn); err == nil {
+		t.Error("NewHandler() expected an error for an unknown format")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = (%v, %v), want (%v, error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestServer_Serve(t *testing.T) {
+	s := &Server{
+		Name:    "test",
+		Version: "1.0.0",
+		Tools: []*Tool{
+			{
+				Name:        "echo",
+				Description: "Echo the message",
+				InputSchema: map[string]any{"type": "object"},
+				Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+					var a struct {
+						Message string `json:"message"`
+					}
+					if err := json.Unmarshal(args, &a); err != nil {
+						return nil, err
+					}
+					if a.Message == "" {
+						return nil, errors.New("message is required")
+					}
+					return map[string]string{"message": a.Message}, nil
+				},
+			},
+		},
+	}
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve() unexpected error: %v", err)
+	}
+
+	var responses []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses = append(responses, r)
+	}
+	if len(responses) != 6 {
+		t.Fatalf("got %d responses, want 6 (notifications are not answered): %s", len(responses), out.String())
+	}
+
+	if v := responses[0]["result"].(map[string]any)["protocolVersion"]; v != "2024-11-05" {
+		t.Errorf("initialize protocolVersion = %v, want the client's version", v)
+	}
+	if tools := responses[1]["result"].(map[string]any)["tools"].([]any); len(tools) != 1 {
+		t.Errorf("tools/list = %v, want 1 tool", tools)
+	}
+	call := responses[2]["result"].(map[string]any)
+	if text := call["content"].([]any)[0].(map[string]any)["text"]; !strings.Contains(text.(string), `"message": "hi"`) || call["isError"] != nil {
+		t.Errorf("tools/call result = %v, want the echoed message", call)
+	}
+	if failed := responses[3]["result"].(map[string]any); failed["isError"] != true {
+		t.Errorf("tools/call with a failing handler = %v, want isError", failed)
+	}
+	if code := responses[4]["error"].(map[string]any)["code"]; code != float64(codeMethodNotFound) {
+		t.Errorf("unknown method error code = %v, want %d", code, codeMethodNotFound)
+	}
+	if code := responses[5]["error"].(map[string]any)["code"]; code != float64(codeParseError) {
+		t.Errorf("invalid JSON error code = %v, want %d", code, codeParseError)
+	}
+}
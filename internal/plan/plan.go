@@ -0,0 +1,72 @@
+// Package plan stores the edits fix would make to workflows in a plan file,
+// so that they can be reviewed before they are applied, and only applied to
+// the workflow contents they were computed from.
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// FormatVersion is the version of the plan file format
+const FormatVersion = 1
+
+// Plan is the content of a plan file
+type Plan struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Version       string    `json:"version,omitempty"` // gh-slimify version that created the plan
+	Files         []File    `json:"files"`
+}
+
+// File lists the planned edits of a workflow file
+type File struct {
+	Path string `json:"path"`
+	// SHA256 is the hash of the file content the edits were computed from
+	// (see workflow.ContentHash)
+	SHA256 string `json:"sha256"`
+	Jobs   []Job  `json:"jobs"`
+}
+
+// Job lists the planned edits of a job
+type Job struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name,omitempty"`
+	Changes []workflow.Change `json:"changes"`
+}
+
+// Changes returns the planned edits of every job of the file
+func (f *File) Changes() []workflow.Change {
+	var changes []workflow.Change
+	for _, job := range f.Jobs {
+		changes = append(changes, job.Changes...)
+	}
+	return changes
+}
+
+// Load reads the plan file at path
+func Load(path string) (*Plan, error) {
+	p := &Plan{}
+	if err := cache.ReadJSON(path, p); err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	if p.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported plan file format version %d in %s (expected %d)", p.FormatVersion, path, FormatVersion)
+	}
+	return p, nil
+}
+
+// Write writes the plan to path
+func (p *Plan) Write(path string) error {
+	if err := readonly.Check("write " + path); err != nil {
+		return err
+	}
+	if err := cache.WriteJSON(path, p); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package plan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	p := &Plan{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Files: []File{{
+			Path:   ".github/workflows/ci.yml",
+			SHA256: "abc",
+			Jobs: []Job{
+				{ID: "lint", Name: "Lint", Changes: []workflow.Change{{Line: 4, Column: 14, Old: "ubuntu-latest", New: "ubuntu-slim"}}},
+				{ID: "test", Changes: []workflow.Change{{Line: 8, Column: 15, Old: "ubuntu-latest", New: "ubuntu-slim"}}},
+			},
+		}},
+	}
+	if err := p.Write(path); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("Load() = %+v, want %+v", got, p)
+	}
+	if changes := got.Files[0].Changes(); len(changes) != 2 || changes[1].Line != 8 {
+		t.Errorf("Changes() = %+v, want the changes of both jobs", changes)
+	}
+}
+
+func TestWrite_ReadOnly(t *testing.T) {
+	t.Setenv(readonly.EnvVar, "1")
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := (&Plan{FormatVersion: FormatVersion}).Write(path); !errors.Is(err, readonly.ErrReadOnly) {
+		t.Errorf("Write() error = %v, want ErrReadOnly", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Write() in read-only mode created %s", path)
+	}
+}
+
+func TestLoad_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"format_version": 2, "files": []}`), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() expected an error for an unsupported format version")
+	}
+}
@@ -0,0 +1,43 @@
+// Package readonly implements the global read-only guard (--no-write or
+// SLIMIFY_READONLY=1). Every code path that modifies the repository or creates
+// remote resources calls Check first, so the scan engine can be embedded in
+// read-only automation with confidence.
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvVar is the environment variable that enables read-only mode
+const EnvVar = "SLIMIFY_READONLY"
+
+// ErrReadOnly is returned by Check when read-only mode is enabled
+var ErrReadOnly = errors.New("read-only mode is enabled (--no-write or " + EnvVar + "=1)")
+
+var enabled bool
+
+// Enable turns on read-only mode for the rest of the process. It cannot be turned off.
+func Enable() {
+	enabled = true
+}
+
+// Enabled reports whether read-only mode is on, via Enable or the environment
+func Enabled() bool {
+	if enabled {
+		return true
+	}
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvVar)))
+	return v != "" && v != "0" && v != "false" && v != "no"
+}
+
+// Check returns an error wrapping ErrReadOnly if read-only mode is on.
+// action describes the blocked operation (e.g., "write .github/workflows/ci.yml").
+func Check(action string) error {
+	if Enabled() {
+		return fmt.Errorf("cannot %s: %w", action, ErrReadOnly)
+	}
+	return nil
+}
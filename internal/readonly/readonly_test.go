@@ -0,0 +1,44 @@
+package readonly
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck_Env(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"no", false},
+		{"1", true},
+		{"true", true},
+		{"YES", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(EnvVar, tt.value)
+			err := Check("write file")
+			if got := errors.Is(err, ErrReadOnly); got != tt.want {
+				t.Errorf("Check() with %s=%q error = %v, want read-only %v", EnvVar, tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnable(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	t.Cleanup(func() { enabled = false })
+
+	if err := Check("write file"); err != nil {
+		t.Fatalf("Check() before Enable() error = %v", err)
+	}
+	Enable()
+	if err := Check("write file"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Check() after Enable() error = %v, want ErrReadOnly", err)
+	}
+}
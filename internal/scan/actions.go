@@ -0,0 +1,186 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// actionCacheTTL is how long resolved action metadata is reused.
+// Tags such as v4 can move, so entries expire instead of living forever.
+const actionCacheTTL = 7 * 24 * time.Hour
+
+// trustedActionOwners are organizations whose actions are known not to be
+// Docker-based, so they are never looked up
+var trustedActionOwners = map[string]bool{
+	"actions": true,
+}
+
+// actionCache persists runs.using of remote actions between scans, keyed by
+// the action reference (e.g., "owner/repo/path@ref")
+type actionCache struct {
+	Entries map[string]*actionCacheEntry `json:"entries"`
+}
+
+// actionCacheEntry is the result of a single action metadata lookup
+type actionCacheEntry struct {
+	Using     string    `json:"using"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// actionCachePath returns the cache file for remote action metadata on a host
+func actionCachePath(host string) (string, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "actions", host+".json"), nil
+}
+
+// actionResolver looks up runs.using of remote actions, caching results in
+// memory and on disk
+type actionResolver struct {
+	client    *api.Client
+	cache     *actionCache
+	cachePath string
+	dirty     bool
+}
+
+// newActionResolver creates a resolver for actions hosted on the current repository's host
+func newActionResolver() (*actionResolver, error) {
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	r := &actionResolver{client: client, cache: &actionCache{}}
+	if path, err := actionCachePath(host); err == nil {
+		r.cachePath = path
+		if err := cache.ReadJSON(path, r.cache); err != nil && !os.IsNotExist(err) {
+			slog.Debug("ignoring action cache", "error", err)
+		}
+	}
+	if r.cache.Entries == nil {
+		r.cache.Entries = make(map[string]*actionCacheEntry)
+	}
+	return r, nil
+}
+
+// using returns runs.using of the action, fetching it if not cached
+func (r *actionResolver) using(ctx context.Context, ref workflow.ActionRef) (string, error) {
+	key := ref.String()
+	if entry, ok := r.cache.Entries[key]; ok && time.Since(entry.FetchedAt) < actionCacheTTL {
+		return entry.Using, nil
+	}
+
+	using, err := r.client.GetActionRunsUsing(ctx, ref.Owner, ref.Repo, ref.Path, ref.Ref)
+	if err != nil {
+		return "", err
+	}
+	r.cache.Entries[key] = &actionCacheEntry{Using: using, FetchedAt: time.Now()}
+	r.dirty = true
+	return using, nil
+}
+
+// dockerActions returns the remote actions used by the job that run in a Docker container
+func (r *actionResolver) dockerActions(ctx context.Context, job *workflow.Job) []workflow.ActionRef {
+	var refs []workflow.ActionRef
+	for _, ref := range job.RemoteActions() {
+		if trustedActionOwners[ref.Owner] {
+			continue
+		}
+		using, err := r.using(ctx, ref)
+		if err != nil {
+			// Unresolvable actions (private repositories, deleted refs) are not
+			// treated as Docker-based
+			slog.Debug("failed to resolve action", "action", ref.String(), "error", err)
+			continue
+		}
+		if using == "docker" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// save writes newly resolved entries to the on-disk cache
+func (r *actionResolver) save() {
+	if !r.dirty || r.cachePath == "" {
+		return
+	}
+	if err := cache.WriteJSON(r.cachePath, r.cache); err != nil {
+		slog.Debug("failed to save action cache", "error", err)
+	}
+}
+
+// excludeDockerActionJobs moves candidates using Docker-based remote actions to
+// the ineligible jobs, unless the severity of the rule was lowered in set.
+// jobs maps each candidate to its parsed job. Candidates whose actions could
+// not be resolved before ctx is done are marked as unenriched.
+func excludeDockerActionJobs(ctx context.Context, candidates []*Candidate, ineligibleJobs []*IneligibleJob, jobs map[*Candidate]*workflow.Job, set *RuleSet) ([]*Candidate, []*IneligibleJob, error) {
+	if len(candidates) == 0 {
+		return candidates, ineligibleJobs, nil
+	}
+
+	resolver, err := newActionResolver()
+	if err != nil {
+		return candidates, ineligibleJobs, err
+	}
+	defer resolver.save()
+
+	remaining := candidates[:0]
+	for _, candidate := range candidates {
+		var dockerRefs []workflow.ActionRef
+		if ctx.Err() == nil {
+			dockerRefs = resolver.dockerActions(ctx, jobs[candidate])
+		}
+		if len(dockerRefs) == 0 && ctx.Err() != nil {
+			candidate.Unenriched = append(candidate.Unenriched, EnrichmentActions)
+			candidate.Rules = append(candidate.Rules, RuleResult{ID: RuleDockerActions, Rule: "docker-actions", Result: ResultSkip, Reason: "deadline exceeded"})
+			remaining = append(remaining, candidate)
+			continue
+		}
+		if len(dockerRefs) == 0 {
+			candidate.Rules = append(candidate.Rules, RuleResult{ID: RuleDockerActions, Rule: "docker-actions", Result: ResultPass})
+			remaining = append(remaining, candidate)
+			continue
+		}
+
+		reasons := make([]string, 0, len(dockerRefs))
+		evidence := make([]workflow.Evidence, 0, len(dockerRefs))
+		for _, ref := range dockerRefs {
+			reasons = append(reasons, fmt.Sprintf("uses Docker-based action %s", ref))
+			evidence = append(evidence, workflow.Evidence{Line: ref.String(), Pattern: "runs.using: docker"})
+		}
+		if result := severityResults[set.Severity(RuleDockerActions)]; result != ResultFail {
+			candidate.Rules = append(candidate.Rules, RuleResult{ID: RuleDockerActions, Rule: "docker-actions", Result: result, Reason: strings.Join(reasons, "; "), Evidence: evidence})
+			remaining = append(remaining, candidate)
+			continue
+		}
+		ineligibleJobs = append(ineligibleJobs, &IneligibleJob{
+			WorkflowPath:  candidate.WorkflowPath,
+			JobID:         candidate.JobID,
+			JobName:       candidate.JobName,
+			LineNumber:    candidate.LineNumber,
+			Column:        candidate.Column,
+			Reasons:       reasons,
+			ReasonRuleIDs: slices.Repeat([]string{RuleDockerActions}, len(reasons)),
+			CalledBy:      candidate.CalledBy,
+			Rules:         append(candidate.Rules, RuleResult{ID: RuleDockerActions, Rule: "docker-actions", Result: ResultFail, Reason: strings.Join(reasons, "; "), Evidence: evidence}),
+		})
+	}
+	return remaining, ineligibleJobs, nil
+}
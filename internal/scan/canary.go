@@ -0,0 +1,106 @@
+package scan
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Canary is a job that already tests ubuntu-slim: it runs on ubuntu-slim with
+// continue-on-error and duplicates an ubuntu-latest job (its twin) in the
+// same workflow. Promoting the canary removes the twin and makes the canary
+// authoritative.
+type Canary struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	TwinID       string `json:"twin_id"`
+	TwinName     string `json:"twin_name"`
+	TwinLine     int    `json:"twin_line"`
+}
+
+// canaryAffixes are added to a twin's job ID to name its canary (e.g., build-slim)
+var canaryAffixes = []string{"-slim", "_slim", "-canary", "_canary", "slim-", "slim_"}
+
+// FindCanaries returns the canary jobs of a workflow paired with their twins,
+// sorted by line number. A twin is the ubuntu-latest job whose steps run the
+// same actions and commands as the canary, or whose job ID is the canary's
+// without a slim or canary affix.
+func FindCanaries(wf *workflow.Workflow) []*Canary {
+	var canaryIDs, twinIDs []string
+	for jobID, job := range wf.Jobs {
+		switch {
+		case job.IsUbuntuSlim() && job.ContinuesOnError():
+			canaryIDs = append(canaryIDs, jobID)
+		case job.IsUbuntuLatest() && !job.ContinuesOnError():
+			twinIDs = append(twinIDs, jobID)
+		}
+	}
+	sort.Strings(canaryIDs)
+	sort.Strings(twinIDs)
+
+	var canaries []*Canary
+	paired := make(map[string]bool)
+	for _, canaryID := range canaryIDs {
+		canary := wf.Jobs[canaryID]
+		twinID := ""
+		for _, id := range twinIDs {
+			if !paired[id] && sameSteps(canary, wf.Jobs[id]) {
+				twinID = id
+				break
+			}
+		}
+		if twinID == "" {
+			for _, id := range twinIDs {
+				if !paired[id] && isAffixed(canaryID, id) {
+					twinID = id
+					break
+				}
+			}
+		}
+		if twinID == "" {
+			continue
+		}
+		paired[twinID] = true
+		twin := wf.Jobs[twinID]
+		canaries = append(canaries, &Canary{
+			WorkflowPath: wf.Path,
+			JobID:        canaryID,
+			JobName:      canary.Name,
+			LineNumber:   canary.LineStart,
+			TwinID:       twinID,
+			TwinName:     twin.Name,
+			TwinLine:     twin.LineStart,
+		})
+	}
+
+	sort.Slice(canaries, func(i, j int) bool { return canaries[i].LineNumber < canaries[j].LineNumber })
+	return canaries
+}
+
+// sameSteps reports whether two jobs run the same actions and commands.
+// Step names, inputs, and environment may differ.
+func sameSteps(a, b *workflow.Job) bool {
+	if len(a.Steps) == 0 || len(a.Steps) != len(b.Steps) {
+		return false
+	}
+	for i := range a.Steps {
+		if a.Steps[i].Uses != b.Steps[i].Uses || strings.TrimSpace(a.Steps[i].Run) != strings.TrimSpace(b.Steps[i].Run) {
+			return false
+		}
+	}
+	return reflect.DeepEqual(a.Services, b.Services) && reflect.DeepEqual(a.Container, b.Container)
+}
+
+// isAffixed reports whether canaryID is twinID with a canary affix
+func isAffixed(canaryID, twinID string) bool {
+	for _, affix := range canaryAffixes {
+		if canaryID == twinID+affix || canaryID == affix+twinID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestFindCanaries(t *testing.T) {
+	wf := &workflow.Workflow{
+		Path: ".github/workflows/ci.yml",
+		Jobs: map[string]*workflow.Job{
+			"build": {Name: "build", RunsOn: "ubuntu-latest", LineStart: 5, Steps: []workflow.Step{{Uses: "actions/checkout@v4"}, {Run: "make"}}},
+			"try-slim": {Name: "try slim", RunsOn: "ubuntu-slim", ContinueOnError: true, LineStart: 12,
+				Steps: []workflow.Step{{Name: "Checkout", Uses: "actions/checkout@v4"}, {Run: "make\n"}}},
+			"lint":      {Name: "lint", RunsOn: "ubuntu-latest", LineStart: 20, Steps: []workflow.Step{{Run: "make lint"}}},
+			"lint-slim": {Name: "lint-slim", RunsOn: "ubuntu-slim", ContinueOnError: "true", LineStart: 25, Steps: []workflow.Step{{Run: "make lint GOFLAGS=-p=1"}}},
+			// Not a canary: failures are not ignored
+			"test-slim": {Name: "test-slim", RunsOn: "ubuntu-slim", LineStart: 30, Steps: []workflow.Step{{Run: "make test"}}},
+			"test":      {Name: "test", RunsOn: "ubuntu-latest", LineStart: 35, Steps: []workflow.Step{{Run: "make test"}}},
+		},
+	}
+
+	canaries := FindCanaries(wf)
+	if len(canaries) != 2 {
+		t.Fatalf("FindCanaries() returned %d canaries, want 2", len(canaries))
+	}
+	if canaries[0].JobID != "try-slim" || canaries[0].TwinID != "build" {
+		t.Errorf("canaries[0] = %+v, want try-slim paired with build by steps", canaries[0])
+	}
+	if canaries[1].JobID != "lint-slim" || canaries[1].TwinID != "lint" {
+		t.Errorf("canaries[1] = %+v, want lint-slim paired with lint by job ID", canaries[1])
+	}
+}
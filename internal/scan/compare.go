@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+)
+
+// JobChange is a job whose classification changed between two scans
+type JobChange struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"` // Line in the later scan
+	// Before and After are the statuses of the job in each scan (StatusSafe,
+	// StatusWarning, StatusIneligible, StatusMigrated, StatusCanary), or "" if
+	// the job was not reported by that scan
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Reasons are the ineligibility reasons or warnings the job gained
+	// (regressions) or lost (resolved warnings)
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Comparison lists the jobs whose classification changed between two scans
+type Comparison struct {
+	// NewlyEligible lists jobs that can be migrated now but could not be, or
+	// did not exist, before
+	NewlyEligible []*JobChange `json:"newly_eligible"`
+	// Regressions lists jobs that could be migrated before but cannot be now,
+	// safe jobs that now require attention, and migrated jobs that no longer
+	// meet the migration criteria (in scans with Options.CheckMigrated)
+	Regressions []*JobChange `json:"regressions"`
+	// ResolvedWarnings lists jobs that can still be migrated and lost warnings
+	ResolvedWarnings []*JobChange `json:"resolved_warnings"`
+	// ResolvedBlockers lists jobs that still cannot be migrated but lost some
+	// of their ineligibility reasons
+	ResolvedBlockers []*JobChange `json:"resolved_blockers"`
+}
+
+// IsEmpty reports whether no job changed
+func (c *Comparison) IsEmpty() bool {
+	return len(c.NewlyEligible) == 0 && len(c.Regressions) == 0 && len(c.ResolvedWarnings) == 0 && len(c.ResolvedBlockers) == 0
+}
+
+// Compare diffs two scan results of the same repository. Jobs are matched by
+// workflow path and job ID. Jobs that were not reported by the later scan
+// (e.g., migrated to ubuntu-slim or deleted) are not changes.
+func Compare(before, after *ScanResult) *Comparison {
+	earlier := make(map[string]*Decision)
+	for _, d := range before.Decisions() {
+		earlier[d.WorkflowPath+":"+d.JobID] = d
+	}
+
+	comparison := &Comparison{
+		NewlyEligible:    []*JobChange{},
+		Regressions:      []*JobChange{},
+		ResolvedWarnings: []*JobChange{},
+		ResolvedBlockers: []*JobChange{},
+	}
+	for _, d := range after.Decisions() {
+		change := &JobChange{
+			WorkflowPath: d.WorkflowPath,
+			JobID:        d.JobID,
+			JobName:      d.JobName,
+			LineNumber:   d.LineNumber,
+			After:        d.Status,
+		}
+		prev := earlier[d.WorkflowPath+":"+d.JobID]
+		if prev != nil {
+			change.Before = prev.Status
+		}
+
+		switch {
+		case isEligibleStatus(d.Status) && (prev == nil || !isEligibleStatus(prev.Status)):
+			change.Reasons = ruleReasons(d, ResultWarn)
+			comparison.NewlyEligible = append(comparison.NewlyEligible, change)
+		case d.Status == StatusMigrated:
+			var before []string
+			if prev != nil {
+				before = ruleReasons(prev, ResultFail)
+			}
+			if added := subtract(ruleReasons(d, ResultFail), before); len(added) > 0 {
+				change.Reasons = added
+				comparison.Regressions = append(comparison.Regressions, change)
+			}
+		case prev == nil:
+		case isEligibleStatus(prev.Status) && d.Status == StatusIneligible:
+			change.Reasons = ruleReasons(d, ResultFail)
+			comparison.Regressions = append(comparison.Regressions, change)
+		case isEligibleStatus(prev.Status) && isEligibleStatus(d.Status):
+			if added := subtract(ruleReasons(d, ResultWarn), ruleReasons(prev, ResultWarn)); prev.Status == StatusSafe && len(added) > 0 {
+				change.Reasons = added
+				comparison.Regressions = append(comparison.Regressions, change)
+			} else if resolved := subtract(ruleReasons(prev, ResultWarn), ruleReasons(d, ResultWarn)); len(resolved) > 0 {
+				change.Reasons = resolved
+				comparison.ResolvedWarnings = append(comparison.ResolvedWarnings, change)
+			}
+		case prev.Status == StatusIneligible && d.Status == StatusIneligible:
+			if resolved := subtract(ruleReasons(prev, ResultFail), ruleReasons(d, ResultFail)); len(resolved) > 0 {
+				change.Reasons = resolved
+				comparison.ResolvedBlockers = append(comparison.ResolvedBlockers, change)
+			}
+		}
+	}
+	return comparison
+}
+
+// isEligibleStatus reports whether a job with the given status can be migrated
+func isEligibleStatus(status string) bool {
+	return status == StatusSafe || status == StatusWarning
+}
+
+// ruleReasons returns the reasons of the rules with the given result, sorted.
+// Missing commands and cloud CLIs are reported one by one, so that installing one of them
+// resolves a warning.
+func ruleReasons(d *Decision, result string) []string {
+	seen := make(map[string]bool)
+	var reasons []string
+	add := func(reason string) {
+		if !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+	for _, r := range d.Rules {
+		if r.Result != result {
+			continue
+		}
+		switch {
+		case r.ID == RuleMissingCommands && len(r.Evidence) > 0:
+			for _, e := range r.Evidence {
+				add(fmt.Sprintf("Setup may be required (%s)", e.Pattern))
+			}
+		case r.ID == RuleCloudCLIs && len(r.Evidence) > 0:
+			for _, e := range r.Evidence {
+				add(fmt.Sprintf("Relies on cloud CLIs missing in ubuntu-slim (%s)", e.Pattern))
+			}
+		case r.Reason != "":
+			add(r.Reason)
+		default:
+			add(r.Rule)
+		}
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// subtract returns the elements of a that are not in b
+func subtract(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	var diff []string
+	for _, s := range a {
+		if !in[s] {
+			diff = append(diff, s)
+		}
+	}
+	return diff
+}
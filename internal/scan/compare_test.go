@@ -0,0 +1,158 @@
+package scan
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestCompare(t *testing.T) {
+	unknownDuration := RuleResult{Rule: "duration", Result: ResultWarn, Reason: "Last execution time: unknown"}
+	missing := func(commands ...string) RuleResult {
+		r := RuleResult{ID: RuleMissingCommands, Rule: "missing-commands", Result: ResultWarn, Reason: "Setup may be required"}
+		for _, c := range commands {
+			r.Evidence = append(r.Evidence, workflow.Evidence{Pattern: c})
+		}
+		return r
+	}
+	docker := RuleResult{ID: "SLIM002", Rule: "container-commands", Result: ResultFail, Reason: "uses Docker commands"}
+	candidate := func(jobID string, rules ...RuleResult) *Candidate {
+		c := &Candidate{WorkflowPath: "ci.yml", JobID: jobID, JobName: jobID, LineNumber: 1, Duration: "1m", Rules: rules}
+		for _, r := range rules {
+			if r.Rule == "duration" {
+				c.Duration = ""
+			}
+			for _, e := range r.Evidence {
+				c.MissingCommands = append(c.MissingCommands, e.Pattern)
+			}
+		}
+		return c
+	}
+	services := RuleResult{ID: "SLIM003", Rule: "services", Result: ResultFail, Reason: "uses service containers"}
+	ineligible := func(jobID string, rules ...RuleResult) *IneligibleJob {
+		if len(rules) == 0 {
+			rules = []RuleResult{docker}
+		}
+		job := &IneligibleJob{WorkflowPath: "ci.yml", JobID: jobID, JobName: jobID, LineNumber: 1, Rules: rules}
+		for _, r := range rules {
+			job.Reasons = append(job.Reasons, r.Reason)
+		}
+		return job
+	}
+
+	before := &ScanResult{
+		Candidates: []*Candidate{
+			candidate("regressed"),
+			candidate("now-warning"),
+			candidate("fixed", missing("jq", "zstd"), unknownDuration),
+			candidate("unchanged", unknownDuration),
+		},
+		IneligibleJobs: []*IneligibleJob{ineligible("unblocked"), ineligible("still-blocked"), ineligible("less-blocked", docker, services)},
+	}
+	after := &ScanResult{
+		Candidates: []*Candidate{
+			candidate("now-warning", missing("jq")),
+			candidate("fixed", missing("jq")),
+			candidate("unchanged", unknownDuration),
+			candidate("unblocked"),
+			candidate("added", unknownDuration),
+		},
+		IneligibleJobs: []*IneligibleJob{ineligible("regressed"), ineligible("still-blocked"), ineligible("less-blocked", services)},
+	}
+
+	got := Compare(before, after)
+
+	summarize := func(changes []*JobChange) map[string][]string {
+		m := make(map[string][]string)
+		for _, c := range changes {
+			m[c.JobID+" "+c.Before+"→"+c.After] = c.Reasons
+		}
+		return m
+	}
+	wantNewlyEligible := map[string][]string{
+		"unblocked ineligible→safe": nil,
+		"added →warning":            {"Last execution time: unknown"},
+	}
+	wantRegressions := map[string][]string{
+		"regressed safe→ineligible": {"uses Docker commands"},
+		"now-warning safe→warning":  {"Setup may be required (jq)"},
+	}
+	wantResolved := map[string][]string{
+		"fixed warning→warning": {"Last execution time: unknown", "Setup may be required (zstd)"},
+	}
+	if s := summarize(got.NewlyEligible); !reflect.DeepEqual(s, wantNewlyEligible) {
+		t.Errorf("NewlyEligible = %v, want %v", s, wantNewlyEligible)
+	}
+	if s := summarize(got.Regressions); !reflect.DeepEqual(s, wantRegressions) {
+		t.Errorf("Regressions = %v, want %v", s, wantRegressions)
+	}
+	if s := summarize(got.ResolvedWarnings); !reflect.DeepEqual(s, wantResolved) {
+		t.Errorf("ResolvedWarnings = %v, want %v", s, wantResolved)
+	}
+	wantBlockers := map[string][]string{
+		"less-blocked ineligible→ineligible": {"uses Docker commands"},
+	}
+	if s := summarize(got.ResolvedBlockers); !reflect.DeepEqual(s, wantBlockers) {
+		t.Errorf("ResolvedBlockers = %v, want %v", s, wantBlockers)
+	}
+
+	if !Compare(after, after).IsEmpty() {
+		t.Error("Compare() of identical results is not empty")
+	}
+}
+
+func TestScanResult_WriteJSON(t *testing.T) {
+	result := &ScanResult{
+		Candidates: []*Candidate{{
+			WorkflowPath: "ci.yml",
+			JobID:        "lint",
+			JobName:      "Lint",
+			LineNumber:   5,
+			Duration:     "1m30s",
+			CalledBy:     []Caller{{WorkflowPath: "release.yml", JobID: "build", JobName: "build"}},
+			Rules:        []RuleResult{{Rule: "duration", Result: ResultPass}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"ineligible_jobs": []`)) {
+		t.Errorf("WriteJSON() should write empty job lists as []:\n%s", buf.String())
+	}
+
+	got, err := ReadResult(&buf)
+	if err != nil {
+		t.Fatalf("ReadResult() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Candidates, result.Candidates) {
+		t.Errorf("ReadResult() candidates = %+v, want %+v", got.Candidates[0], result.Candidates[0])
+	}
+
+	if _, err := ReadResult(bytes.NewBufferString("not json")); err == nil {
+		t.Error("ReadResult() expected error for invalid input")
+	}
+}
+
+func TestCompare_Migrated(t *testing.T) {
+	docker := RuleResult{ID: "SLIM002", Rule: "docker-commands", Result: ResultFail, Reason: "uses Docker commands"}
+	migrated := func(jobID string, rules ...RuleResult) *MigratedJob {
+		return &MigratedJob{WorkflowPath: "ci.yml", JobID: jobID, JobName: jobID, LineNumber: 1, Reasons: failedReasons(rules), Rules: rules}
+	}
+	before := &ScanResult{
+		Candidates: []*Candidate{{WorkflowPath: "ci.yml", JobID: "build", JobName: "build", LineNumber: 1, Duration: "1m"}},
+		Migrated:   []*MigratedJob{migrated("lint"), migrated("image", docker)},
+	}
+	after := &ScanResult{Migrated: []*MigratedJob{migrated("build"), migrated("lint", docker), migrated("image", docker)}}
+
+	got := Compare(before, after)
+	if len(got.Regressions) != 1 || got.Regressions[0].JobID != "lint" || got.Regressions[0].Before != StatusMigrated || !reflect.DeepEqual(got.Regressions[0].Reasons, []string{"uses Docker commands"}) {
+		t.Errorf("Regressions = %+v, want lint, which now uses Docker commands", got.Regressions)
+	}
+	if len(got.NewlyEligible) != 0 || len(got.ResolvedBlockers) != 0 || len(got.ResolvedWarnings) != 0 {
+		t.Errorf("Compare() = %+v, want regressions only", got)
+	}
+}
@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Rule results
+const (
+	ResultPass = "pass" // The job satisfies the rule
+	ResultFail = "fail" // The job violates the rule and cannot be migrated
+	ResultWarn = "warn" // The job can be migrated but requires attention
+	ResultInfo = "info" // Informational label that does not affect eligibility
+	ResultSkip = "skip" // The rule was not evaluated
+)
+
+// Job statuses in decision records
+const (
+	StatusSafe       = "safe"
+	StatusWarning    = "warning"
+	StatusIneligible = "ineligible"
+	StatusCanary     = "canary"   // Already runs on ubuntu-slim next to its ubuntu-latest twin
+	StatusIgnored    = "ignored"  // Excluded by an active slimify:ignore directive
+	StatusMigrated   = "migrated" // Already runs on ubuntu-slim
+)
+
+// RuleResult is the outcome of evaluating a single rule against a job
+type RuleResult struct {
+	ID       string              `json:"id,omitempty"`
+	Rule     string              `json:"rule"`
+	Result   string              `json:"result"`
+	Reason   string              `json:"reason,omitempty"`
+	Evidence []workflow.Evidence `json:"evidence,omitempty"`
+}
+
+// Decision records how a job was evaluated, for auditing
+type Decision struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	Column       int    `json:"column,omitempty"`
+	Status       string `json:"status"`
+	// Action is what fix did with the job (updated, skipped, or failed).
+	// It is empty for scans.
+	Action string       `json:"action,omitempty"`
+	Rules  []RuleResult `json:"rules"`
+	// Twin is the ID of the ubuntu-latest job a canary duplicates
+	Twin string `json:"twin,omitempty"`
+}
+
+// durationRule returns the result of the execution time rule for a candidate.
+// Unknown execution times have the severity of RuleUnknownDuration.
+func durationRule(c *Candidate, opts Options) RuleResult {
+	unknown := c.Duration == "" || c.Duration == "unknown"
+	severity := opts.Rules.Severity(RuleUnknownDuration)
+	switch {
+	case unknown && severity == SeverityOff:
+		return RuleResult{Rule: "duration", Result: ResultSkip, Reason: "disabled"}
+	case unknown && severity == SeverityInfo:
+		return RuleResult{Rule: "duration", Result: ResultInfo, Reason: "Last execution time: unknown"}
+	case opts.SkipDuration:
+		return RuleResult{Rule: "duration", Result: ResultSkip, Reason: "duration lookup skipped (--skip-duration)"}
+	case c.IsUnenriched(EnrichmentDuration):
+		return RuleResult{Rule: "duration", Result: ResultWarn, Reason: "duration lookup skipped: deadline exceeded"}
+	case unknown:
+		return RuleResult{Rule: "duration", Result: severityResults[severity], Reason: "Last execution time: unknown"}
+	case c.LongRunning:
+		return RuleResult{Rule: "duration", Result: ResultWarn, Reason: LongRunningReason(c.Duration, opts.MaxDuration), Evidence: []workflow.Evidence{{Line: c.Duration}}}
+	default:
+		return RuleResult{Rule: "duration", Result: ResultPass, Evidence: []workflow.Evidence{{Line: c.Duration}}}
+	}
+}
+
+// LongRunningReason describes a job whose execution time exceeds the maximum
+// duration (e.g., "Execution time 25m exceeds the maximum duration of 15m:
+// long CPU-bound jobs may not benefit from ubuntu-slim")
+func LongRunningReason(duration string, maxDuration time.Duration) string {
+	return fmt.Sprintf("Execution time %s exceeds the maximum duration of %s: long CPU-bound jobs may not benefit from ubuntu-slim", duration, FormatDuration(maxDuration))
+}
+
+// ignoreRule returns the result of a job's slimify:ignore directive: skipped
+// while it is active, and a warning once it expired or if it is invalid
+func ignoreRule(d *workflow.IgnoreDirective, active bool) RuleResult {
+	evidence := []workflow.Evidence{{Line: fmt.Sprintf("L%d", d.Line), Pattern: "slimify:ignore"}}
+	var reason string
+	result := ResultWarn
+	switch {
+	case d.Error != "":
+		return RuleResult{Rule: "ignore", Result: ResultWarn, Reason: "invalid slimify:ignore: " + d.Error, Evidence: evidence}
+	case active && d.Until == "":
+		reason, result = "ignored", ResultSkip
+	case active:
+		reason, result = "ignored until "+d.Until, ResultSkip
+	default:
+		reason = "slimify:ignore expired on " + d.Until
+	}
+	if d.Reason != "" {
+		reason += ": " + d.Reason
+	}
+	return RuleResult{Rule: "ignore", Result: result, Reason: reason, Evidence: evidence}
+}
+
+// Decisions returns a decision record for every evaluated job, sorted by
+// workflow path and line number
+func (r *ScanResult) Decisions() []*Decision {
+	var decisions []*Decision
+	for _, c := range r.Candidates {
+		status := StatusSafe
+		if c.HasWarnings() {
+			status = StatusWarning
+		}
+		decisions = append(decisions, &Decision{
+			WorkflowPath: c.WorkflowPath,
+			JobID:        c.JobID,
+			JobName:      c.JobName,
+			LineNumber:   c.LineNumber,
+			Column:       c.Column,
+			Status:       status,
+			Rules:        c.Rules,
+		})
+	}
+	for _, job := range r.IneligibleJobs {
+		decisions = append(decisions, &Decision{
+			WorkflowPath: job.WorkflowPath,
+			JobID:        job.JobID,
+			JobName:      job.JobName,
+			LineNumber:   job.LineNumber,
+			Column:       job.Column,
+			Status:       StatusIneligible,
+			Rules:        job.Rules,
+		})
+	}
+
+	for _, job := range r.Ignored {
+		decisions = append(decisions, &Decision{
+			WorkflowPath: job.WorkflowPath,
+			JobID:        job.JobID,
+			JobName:      job.JobName,
+			LineNumber:   job.LineNumber,
+			Status:       StatusIgnored,
+			Rules:        []RuleResult{ignoreRule(job.Directive, true)},
+		})
+	}
+	for _, job := range r.Migrated {
+		rules := job.Rules
+		if rules == nil {
+			rules = []RuleResult{}
+		}
+		decisions = append(decisions, &Decision{
+			WorkflowPath: job.WorkflowPath,
+			JobID:        job.JobID,
+			JobName:      job.JobName,
+			LineNumber:   job.LineNumber,
+			Status:       StatusMigrated,
+			Rules:        rules,
+		})
+	}
+	for _, c := range r.Canaries {
+		decisions = append(decisions, &Decision{
+			WorkflowPath: c.WorkflowPath,
+			JobID:        c.JobID,
+			JobName:      c.JobName,
+			LineNumber:   c.LineNumber,
+			Status:       StatusCanary,
+			Rules:        []RuleResult{},
+			Twin:         c.TwinID,
+		})
+	}
+
+	sort.Slice(decisions, func(i, k int) bool {
+		if decisions[i].WorkflowPath != decisions[k].WorkflowPath {
+			return decisions[i].WorkflowPath < decisions[k].WorkflowPath
+		}
+		if decisions[i].LineNumber != decisions[k].LineNumber {
+			return decisions[i].LineNumber < decisions[k].LineNumber
+		}
+		return decisions[i].JobID < decisions[k].JobID
+	})
+	return decisions
+}
@@ -0,0 +1,103 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanResult_Decisions(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowDir := filepath.Join(tmpDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowDir, 0755); err != nil {
+		t.Fatalf("Failed to create workflow directory: %v", err)
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer func() {
+		os.Chdir(originalWd)
+	}()
+
+	content := `name: ci
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo lint
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Build image
+        run: |
+          echo building
+          docker build -t app .
+  windows:
+    runs-on: windows-latest
+    steps:
+      - run: echo hello
+`
+	if err := os.WriteFile(filepath.Join(workflowDir, "ci.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := Scan(true, ".github/workflows/ci.yml")
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	decisions := result.Decisions()
+	if len(decisions) != 3 {
+		t.Fatalf("Decisions() returned %d decisions, want 3", len(decisions))
+	}
+
+	// Sorted by line number. The duration is unknown since its lookup was skipped.
+	wantJobs := []string{"lint", "image", "windows"}
+	wantStatus := []string{StatusWarning, StatusIneligible, StatusIneligible}
+	for i, d := range decisions {
+		if d.JobID != wantJobs[i] {
+			t.Errorf("decisions[%d].JobID = %q, want %q", i, d.JobID, wantJobs[i])
+		}
+		if d.Status != wantStatus[i] {
+			t.Errorf("decisions[%d].Status = %q, want %q", i, d.Status, wantStatus[i])
+		}
+	}
+
+	results := make(map[string]RuleResult)
+	for _, r := range decisions[1].Rules {
+		results[r.Rule] = r
+	}
+	docker := results["docker-commands"]
+	if docker.Result != ResultFail || docker.Reason != "uses Docker commands" {
+		t.Errorf("docker-commands = %+v, want fail with reason", docker)
+	}
+	if len(docker.Evidence) != 1 || docker.Evidence[0].Step != "Build image" || docker.Evidence[0].Line != "docker build -t app ." {
+		t.Errorf("docker-commands evidence = %+v, want the docker build line of step \"Build image\"", docker.Evidence)
+	}
+	if results["services"].Result != ResultPass {
+		t.Errorf("services = %+v, want pass", results["services"])
+	}
+
+	// Rules after runs-on are skipped for jobs not on ubuntu-latest
+	for _, r := range decisions[2].Rules {
+		want := ResultSkip
+		if r.Rule == "runs-on" {
+			want = ResultFail
+		}
+		if r.Result != want {
+			t.Errorf("windows rule %s = %q, want %q", r.Rule, r.Result, want)
+		}
+	}
+
+	// Duration lookup was skipped for the candidate
+	last := decisions[0].Rules[len(decisions[0].Rules)-1]
+	if last.Rule != "duration" || last.Result != ResultSkip {
+		t.Errorf("last lint rule = %+v, want skipped duration", last)
+	}
+}
@@ -0,0 +1,246 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/cache"
+)
+
+// DefaultMaxWorkers is the number of durations looked up concurrently when
+// Options.MaxWorkers is not set
+const DefaultMaxWorkers = 4
+
+// DurationProvider looks up the durations of a job in its latest successful
+// runs, newest first, like api.Client.GetJobDurations. Implementations are
+// called concurrently.
+type DurationProvider interface {
+	GetJobDurations(ctx context.Context, workflowPath, jobID, jobDisplayName string, runs int, formerNames ...string) ([]*api.JobDuration, error)
+}
+
+// ErrOffline is returned by OfflineDurations
+var ErrOffline = errors.New("duration lookups are disabled (offline)")
+
+// OfflineDurations is a DurationProvider that never accesses the network:
+// every duration is unknown
+var OfflineDurations DurationProvider = offlineDurations{}
+
+type offlineDurations struct{}
+
+func (offlineDurations) GetJobDurations(ctx context.Context, workflowPath, jobID, jobDisplayName string, runs int, formerNames ...string) ([]*api.JobDuration, error) {
+	return nil, ErrOffline
+}
+
+// durationCache persists duration lookup results between scans so that
+// --retry-unknown can re-attempt only the lookups that previously failed
+type durationCache struct {
+	Entries map[string]*durationCacheEntry `json:"entries"`
+}
+
+// durationCacheEntry is the result of a single duration lookup
+type durationCacheEntry struct {
+	Duration  time.Duration  `json:"duration,omitempty"`
+	Stats     *DurationStats `json:"stats,omitempty"`
+	Unknown   bool           `json:"unknown,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	FetchedAt time.Time      `json:"fetched_at"`
+}
+
+// durationCachePath returns the cache file for a repository's duration lookups
+func durationCachePath(host, owner, repo string) (string, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "durations", host, owner, repo+".json"), nil
+}
+
+// durationCacheKey returns the cache key for a candidate ("<workflow-path>:<job-id>"),
+// followed by the run filter, if any (e.g., " (branch main, event push)")
+func durationCacheKey(c *Candidate, filter api.RunFilter) string {
+	key := filepath.ToSlash(c.WorkflowPath) + ":" + c.JobID
+	if f := filter.String(); f != "" {
+		key += " (" + f + ")"
+	}
+	return key
+}
+
+// fetchDurations fetches job execution durations with opts.Durations, or from
+// GitHub API if it is nil. GitHub API results are merged into the on-disk
+// duration cache. With opts.RetryUnknown, cached known durations are reused
+// and only unknown ones are fetched again.
+// Candidates not looked up before ctx is done are marked as unenriched.
+// rateLimited is true if some durations are unknown because the GitHub API
+// rate limit was exhausted.
+func fetchDurations(ctx context.Context, candidates []*Candidate, opts Options) (rateLimited bool, err error) {
+	if len(candidates) == 0 {
+		return false, nil
+	}
+	// Load results of previous scans; a missing or broken cache is not an
+	// error. Durations of other providers are not cached.
+	cached := &durationCache{Entries: make(map[string]*durationCacheEntry)}
+	var host, owner, repo, cachePath string
+	persist := false
+	if opts.Durations == nil {
+		// Get repository info from git remote
+		host, owner, repo, err = api.GetRepoInfo()
+		if err != nil {
+			return false, fmt.Errorf("failed to get repository info: %w", err)
+		}
+		var cacheErr error
+		cachePath, cacheErr = durationCachePath(host, owner, repo)
+		if cacheErr == nil {
+			persist = true
+			if err := cache.ReadJSON(cachePath, cached); err != nil && !os.IsNotExist(err) {
+				slog.Debug("ignoring duration cache", "error", err)
+			}
+		}
+		if cached.Entries == nil {
+			cached.Entries = make(map[string]*durationCacheEntry)
+		}
+	}
+
+	// Reuse known durations when only unknown lookups should be retried
+	pending := candidates
+	if opts.RetryUnknown {
+		pending = nil
+		for _, candidate := range candidates {
+			if entry, ok := cached.Entries[durationCacheKey(candidate, opts.RunFilter)]; ok && !entry.Unknown {
+				candidate.Duration = FormatDuration(entry.Duration)
+				candidate.DurationStats = entry.Stats
+				continue
+			}
+			pending = append(pending, candidate)
+		}
+		slog.Info(fmt.Sprintf("Retrying %d unknown duration(s), reusing %d cached duration(s)", len(pending), len(candidates)-len(pending)))
+		if len(pending) == 0 {
+			return false, nil
+		}
+	}
+
+	provider := opts.Durations
+	if provider == nil {
+		// Create API client
+		client, err := api.NewClient(host, owner, repo)
+		if err != nil {
+			return false, fmt.Errorf("failed to create API client: %w", err)
+		}
+		client.SetRunFilter(opts.RunFilter)
+		provider = client
+	}
+
+	// Fetch durations concurrently; the client pauses every worker while the
+	// API rate limit is exceeded
+	workers := opts.MaxWorkers
+	if workers <= 0 {
+		workers = DefaultMaxWorkers
+	}
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	var mu sync.Mutex // Guards cached.Entries and rateLimited
+	queue := make(chan *Candidate)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for candidate := range queue {
+				entry, limited := fetchDuration(ctx, provider, candidate, opts)
+				if entry == nil {
+					continue
+				}
+				mu.Lock()
+				cached.Entries[durationCacheKey(candidate, opts.RunFilter)] = entry
+				rateLimited = rateLimited || limited
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, candidate := range pending {
+		queue <- candidate
+	}
+	close(queue)
+	wg.Wait()
+
+	if persist {
+		if err := cache.WriteJSON(cachePath, cached); err != nil {
+			slog.Debug("failed to save duration cache", "error", err)
+		}
+	}
+
+	return rateLimited, nil
+}
+
+// fetchDuration looks up the duration of a candidate and returns the entry
+// to cache, or nil if the lookup was cut short by ctx, and whether the
+// duration is unknown because the rate limit was exhausted
+func fetchDuration(ctx context.Context, provider DurationProvider, candidate *Candidate, opts Options) (*durationCacheEntry, bool) {
+	if ctx.Err() != nil {
+		candidate.Unenriched = append(candidate.Unenriched, EnrichmentDuration)
+		return nil, false
+	}
+	workflowPath, jobID, jobName := durationLookupKey(candidate)
+	runs := max(opts.Runs, 1)
+	durations, err := provider.GetJobDurations(ctx, workflowPath, jobID, jobName, runs, formerLookupNames(candidate, opts.FormerJobNames)...)
+	if err == nil && len(durations) == 0 {
+		err = fmt.Errorf("no successful run of job %s found", jobName)
+	}
+	if err != nil && ctx.Err() != nil {
+		// Cut short by the deadline; not cached so the next scan retries it
+		candidate.Unenriched = append(candidate.Unenriched, EnrichmentDuration)
+		return nil, false
+	}
+	if err != nil {
+		// Log error for debugging but continue to next candidate
+		slog.Debug("failed to get duration", "job", candidate.JobName, "job_id", candidate.JobID, "workflow", candidate.WorkflowPath, "error", err)
+		return &durationCacheEntry{Unknown: true, Error: err.Error(), FetchedAt: time.Now()}, api.IsRateLimited(err)
+	}
+
+	// Format duration as human-readable string; over several runs, the median
+	// is used and the statistics are kept
+	entry := &durationCacheEntry{Duration: durations[0].Duration, FetchedAt: time.Now()}
+	if runs > 1 {
+		samples := make([]time.Duration, len(durations))
+		for i, d := range durations {
+			samples[i] = d.Duration
+		}
+		entry.Duration = percentile(samples, 50)
+		entry.Stats = NewDurationStats(samples)
+	}
+	candidate.Duration = FormatDuration(entry.Duration)
+	candidate.DurationStats = entry.Stats
+	return entry, false
+}
+
+// formerLookupNames returns the former display names of a candidate to match
+// in past runs, prefixed with the caller's name like durationLookupKey
+func formerLookupNames(c *Candidate, formerJobNames map[string][]string) []string {
+	names := formerJobNames[filepath.ToSlash(filepath.Clean(c.WorkflowPath))+":"+c.JobID]
+	if len(c.CalledBy) == 0 || len(names) == 0 {
+		return names
+	}
+	prefixed := make([]string, len(names))
+	for i, name := range names {
+		prefixed[i] = c.CalledBy[0].JobName + " / " + name
+	}
+	return prefixed
+}
+
+// durationLookupKey returns the workflow path, job ID, and display name to use when
+// looking up a candidate's duration. Jobs in reusable workflows run as part of the
+// caller's workflow run and are named "<caller job> / <called job>" by the API.
+func durationLookupKey(c *Candidate) (workflowPath, jobID, jobName string) {
+	if len(c.CalledBy) == 0 {
+		return c.WorkflowPath, c.JobID, c.JobName
+	}
+	caller := c.CalledBy[0]
+	return caller.WorkflowPath, caller.JobID + " / " + c.JobID, caller.JobName + " / " + c.JobName
+}
@@ -0,0 +1,127 @@
+package scan
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// ChangedJob is a job added or modified by a change (e.g., a pull request),
+// see workflow.ModifiedJobs
+type ChangedJob struct {
+	WorkflowPath string
+	JobID        string
+	Added        bool
+}
+
+// PolicyViolation is a changed job that could be migrated to ubuntu-slim but
+// still runs on ubuntu-latest
+type PolicyViolation struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	// Added is true for a new job, and false for a modified one
+	Added bool `json:"added"`
+	// Warnings lists why the job requires attention (e.g., missing commands),
+	// which its migration should take care of
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PolicyReport is the result of checking changed jobs against the "slim by
+// default" policy
+type PolicyReport struct {
+	// Checked is the number of changed jobs checked
+	Checked int `json:"checked"`
+	// Violations lists the changed jobs that should run on ubuntu-slim
+	Violations []*PolicyViolation `json:"violations"`
+	// Allowed lists the changed jobs that would be violations but are allowed
+	// to stay on ubuntu-latest
+	Allowed []*PolicyViolation `json:"allowed"`
+}
+
+// Policy forbids adding or modifying jobs that could be migrated to
+// ubuntu-slim without migrating them, except for allowed jobs
+type Policy struct {
+	allowed []excludedJob
+}
+
+// NewPolicy validates the allowed jobs, given as "<workflow>:<job-id>" where
+// the workflow may be a glob pattern (e.g., "release-*.yml:publish")
+func NewPolicy(allow []string) (*Policy, error) {
+	p := &Policy{}
+	for _, a := range allow {
+		job, err := parseJobPattern(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed job %w", err)
+		}
+		p.allowed = append(p.allowed, job)
+	}
+	return p, nil
+}
+
+// allows reports whether the job jobID of the workflow at path is allowed
+func (p *Policy) allows(path, jobID string) bool {
+	e := exclusions{jobs: p.allowed}
+	return e.job(path, jobID)
+}
+
+// Check returns the changed jobs of a scan result that violate the policy:
+// the candidates among them. Jobs requiring attention are violations too,
+// since they can be migrated once their warnings are taken care of.
+func (p *Policy) Check(result *ScanResult, changed []ChangedJob) *PolicyReport {
+	added := make(map[string]bool, len(changed))
+	isChanged := make(map[string]bool, len(changed))
+	for _, c := range changed {
+		key := policyKey(c.WorkflowPath, c.JobID)
+		isChanged[key] = true
+		added[key] = c.Added
+	}
+
+	report := &PolicyReport{Checked: len(changed), Violations: []*PolicyViolation{}, Allowed: []*PolicyViolation{}}
+	for _, c := range result.Candidates {
+		key := policyKey(c.WorkflowPath, c.JobID)
+		if !isChanged[key] {
+			continue
+		}
+		v := &PolicyViolation{
+			WorkflowPath: c.WorkflowPath,
+			JobID:        c.JobID,
+			JobName:      c.JobName,
+			LineNumber:   c.LineNumber,
+			Added:        added[key],
+			Warnings:     candidateWarnings(c),
+		}
+		if p.allows(c.WorkflowPath, c.JobID) {
+			report.Allowed = append(report.Allowed, v)
+		} else {
+			report.Violations = append(report.Violations, v)
+		}
+	}
+	for _, list := range [][]*PolicyViolation{report.Violations, report.Allowed} {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].WorkflowPath != list[j].WorkflowPath {
+				return list[i].WorkflowPath < list[j].WorkflowPath
+			}
+			return list[i].LineNumber < list[j].LineNumber
+		})
+	}
+	return report
+}
+
+// policyKey identifies a job by its cleaned workflow path and ID
+func policyKey(path, jobID string) string {
+	return filepath.ToSlash(filepath.Clean(path)) + ":" + jobID
+}
+
+// candidateWarnings returns the reasons of the rules that warned about a
+// candidate
+func candidateWarnings(c *Candidate) []string {
+	var warnings []string
+	for _, r := range c.Rules {
+		if r.Result == ResultWarn {
+			warnings = append(warnings, r.Reason)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,52 @@
+package scan
+
+import "testing"
+
+func TestPolicy_Check(t *testing.T) {
+	result := &ScanResult{
+		Candidates: []*Candidate{
+			{WorkflowPath: ".github/workflows/ci.yml", JobID: "lint", JobName: "Lint", LineNumber: 4},
+			{WorkflowPath: ".github/workflows/ci.yml", JobID: "test", JobName: "test", LineNumber: 9, Rules: []RuleResult{
+				{ID: RuleMissingCommands, Result: ResultWarn, Reason: "Missing commands: psql"},
+			}},
+			{WorkflowPath: ".github/workflows/release-v2.yml", JobID: "publish", JobName: "publish", LineNumber: 4},
+			{WorkflowPath: ".github/workflows/ci.yml", JobID: "unchanged", JobName: "unchanged", LineNumber: 14},
+		},
+		IneligibleJobs: []*IneligibleJob{
+			{WorkflowPath: ".github/workflows/ci.yml", JobID: "docker", JobName: "docker", LineNumber: 19},
+		},
+	}
+	changed := []ChangedJob{
+		{WorkflowPath: "./.github/workflows/ci.yml", JobID: "test", Added: true},
+		{WorkflowPath: ".github/workflows/ci.yml", JobID: "lint"},
+		{WorkflowPath: ".github/workflows/ci.yml", JobID: "docker", Added: true},
+		{WorkflowPath: ".github/workflows/release-v2.yml", JobID: "publish"},
+	}
+
+	policy, err := NewPolicy([]string{"release-*.yml:publish"})
+	if err != nil {
+		t.Fatalf("NewPolicy() unexpected error: %v", err)
+	}
+	report := policy.Check(result, changed)
+	if report.Checked != 4 {
+		t.Errorf("Checked = %d, want 4", report.Checked)
+	}
+	if len(report.Violations) != 2 {
+		t.Fatalf("Violations = %+v, want lint and test", report.Violations)
+	}
+	if v := report.Violations[0]; v.JobID != "lint" || v.Added || len(v.Warnings) != 0 {
+		t.Errorf("Violations[0] = %+v, want the modified lint job", v)
+	}
+	if v := report.Violations[1]; v.JobID != "test" || !v.Added || len(v.Warnings) != 1 {
+		t.Errorf("Violations[1] = %+v, want the added test job with a warning", v)
+	}
+	if len(report.Allowed) != 1 || report.Allowed[0].JobID != "publish" {
+		t.Errorf("Allowed = %+v, want publish", report.Allowed)
+	}
+
+	for _, allow := range []string{"publish", "ci.yml:", "[.yml:lint"} {
+		if _, err := NewPolicy([]string{allow}); err == nil {
+			t.Errorf("NewPolicy(%q) expected an error", allow)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// excludedJob is a job excluded by Options.ExcludeJobs
+type excludedJob struct {
+	pattern string // Workflow path glob
+	jobID   string
+}
+
+// exclusions holds the workflow files and jobs excluded from a scan
+type exclusions struct {
+	files []string
+	jobs  []excludedJob
+}
+
+// newExclusions validates the patterns of Options.ExcludeFiles and
+// Options.ExcludeJobs
+func newExclusions(files, jobs []string) (*exclusions, error) {
+	e := &exclusions{}
+	for _, f := range files {
+		if err := workflow.CheckPattern(f); err != nil {
+			return nil, fmt.Errorf("invalid excluded file: %w", err)
+		}
+		e.files = append(e.files, f)
+	}
+	for _, j := range jobs {
+		job, err := parseJobPattern(j)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded job %w", err)
+		}
+		e.jobs = append(e.jobs, job)
+	}
+	return e, nil
+}
+
+// parseJobPattern parses a job given as "<workflow>:<job-id>", where the
+// workflow may be a glob pattern
+func parseJobPattern(j string) (excludedJob, error) {
+	i := strings.LastIndex(j, ":")
+	if i <= 0 || i == len(j)-1 {
+		return excludedJob{}, fmt.Errorf("%q: expected <workflow>:<job-id>", j)
+	}
+	if err := workflow.CheckPattern(j[:i]); err != nil {
+		return excludedJob{}, fmt.Errorf("%q: %w", j, err)
+	}
+	return excludedJob{pattern: j[:i], jobID: j[i+1:]}, nil
+}
+
+// file reports whether the workflow at path is excluded
+func (e *exclusions) file(path string) bool {
+	for _, f := range e.files {
+		if workflow.MatchPath(f, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// job reports whether the job jobID of the workflow at path is excluded
+func (e *exclusions) job(path, jobID string) bool {
+	for _, j := range e.jobs {
+		if j.jobID == jobID && workflow.MatchPath(j.pattern, path) {
+			return true
+		}
+	}
+	return false
+}
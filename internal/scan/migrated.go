@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// MigratedJob is a job already running on ubuntu-slim (the target runner),
+// other than a canary. With Options.CheckMigrated, it is evaluated again
+// against the migration rules, as if it still ran on ubuntu-latest, to catch
+// changes made since its migration (e.g., a docker build step added later).
+type MigratedJob struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	// Duration is the last execution time of the job, which weighs it in the
+	// readiness score (see NewReadinessReport)
+	Duration string `json:"duration,omitempty"`
+	// CalledBy lists the jobs calling this job's workflow as a reusable
+	// workflow. Durations are looked up in the runs of the caller.
+	CalledBy []Caller `json:"called_by,omitempty"`
+	// Reasons lists why the job no longer meets the migration criteria
+	// (Options.CheckMigrated only)
+	Reasons []string `json:"reasons,omitempty"`
+	// ReasonRuleIDs holds the ID of the rule that produced each reason
+	// (Options.CheckMigrated only)
+	ReasonRuleIDs []string `json:"reason_rule_ids,omitempty"`
+	// Rules holds the result of every rule evaluated against the job
+	// (Options.CheckMigrated only)
+	Rules []RuleResult `json:"rules,omitempty"`
+}
+
+// IsBroken reports whether the job no longer meets the migration criteria
+func (j *MigratedJob) IsBroken() bool {
+	return len(j.Reasons) > 0
+}
+
+// ReasonRuleID returns the ID of the rule that produced the i-th reason, or ""
+func (j *MigratedJob) ReasonRuleID(i int) string {
+	return reasonRuleID(j.ReasonRuleIDs, i)
+}
+
+// isMigrated reports whether a job already runs on the target runner. Jobs
+// on self-hosted runners with the target label are not: they are not the
+// hosted runner jobs are migrated to.
+func isMigrated(job *workflow.Job) bool {
+	return job.IsUbuntuSlim() && !job.IsUbuntuLatest() && !job.IsSelfHosted()
+}
+
+// checkMigrated evaluates the rules against a migrated job, as if it ran on
+// ubuntu-latest
+func checkMigrated(m *MigratedJob, job *workflow.Job, wf *workflow.Workflow, set *RuleSet) {
+	assume := *job
+	assume.RunsOn = workflow.SourceLabel()
+	m.Rules = evaluateRules(&assume, wf, set)
+	m.Reasons = failedReasons(m.Rules)
+	m.ReasonRuleIDs = failedRuleIDs(m.Rules)
+}
+
+// migratedDurationLookups returns candidates standing for the migrated jobs
+// in duration lookups
+func migratedDurationLookups(migrated []*MigratedJob) []*Candidate {
+	lookups := make([]*Candidate, len(migrated))
+	for i, m := range migrated {
+		lookups[i] = &Candidate{WorkflowPath: m.WorkflowPath, JobID: m.JobID, JobName: m.JobName, LineNumber: m.LineNumber, CalledBy: m.CalledBy}
+	}
+	return lookups
+}
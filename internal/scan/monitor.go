@@ -0,0 +1,211 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// MonitorWindow is the period of runs compared on each side of a migration
+const MonitorWindow = 14 * 24 * time.Hour
+
+// MonitorThresholds decide when a migrated job regressed
+type MonitorThresholds struct {
+	// MaxFailureRateIncrease is the largest increase of the failure rate
+	// tolerated, in percentage points (0.1 is 10 points)
+	MaxFailureRateIncrease float64
+	// MaxSlowdown is the largest increase of the median duration tolerated,
+	// relative to the one before the migration (0.25 is 25% slower)
+	MaxSlowdown float64
+	// MinRuns is the number of runs after the migration below which the job
+	// is not judged
+	MinRuns int
+}
+
+// DefaultMonitorThresholds are the thresholds of gh slimify monitor
+var DefaultMonitorThresholds = MonitorThresholds{MaxFailureRateIncrease: 0.1, MaxSlowdown: 0.25, MinRuns: 5}
+
+// MonitoredJob is a job already migrated to the target runner
+type MonitoredJob struct {
+	WorkflowPath string
+	JobID        string
+	JobName      string
+	// MigratedAt is when the job was migrated, from the state file. If it is
+	// zero, runs are told apart by the runner labels the API reports.
+	MigratedAt time.Time
+}
+
+// RunHealth summarizes the runs of a job on one side of its migration.
+// Cancelled and skipped runs are not counted.
+type RunHealth struct {
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"` // Failed and timed out runs
+	FailureRate float64 `json:"failure_rate"`
+	// Median is the median duration of successful runs, empty if none succeeded
+	Median string `json:"median,omitempty"`
+
+	median time.Duration
+}
+
+// JobHealth compares the runs of a migrated job before and after its migration
+type JobHealth struct {
+	WorkflowPath string     `json:"workflow_path"`
+	JobID        string     `json:"job_id"`
+	JobName      string     `json:"job_name"`
+	MigratedAt   *time.Time `json:"migrated_at,omitempty"`
+	Before       RunHealth  `json:"before"`
+	After        RunHealth  `json:"after"`
+	// Regressions lists why the job looks worse since its migration,
+	// suggesting a revert
+	Regressions []string `json:"regressions,omitempty"`
+	// Insufficient is true if there are too few runs on either side to judge
+	Insufficient bool `json:"insufficient_data,omitempty"`
+}
+
+// Regressed reports whether the job looks worse since its migration
+func (h *JobHealth) Regressed() bool {
+	return len(h.Regressions) > 0
+}
+
+// MigratedJobs returns the jobs of workflows running on the target runner.
+// migratedAt returns when a job was migrated, or the zero time if unknown.
+func MigratedJobs(workflows []*workflow.Workflow, migratedAt func(workflowPath, jobID string) time.Time) []MonitoredJob {
+	var jobs []MonitoredJob
+	for _, wf := range workflows {
+		for _, jobID := range wf.JobIDs() {
+			job := wf.Jobs[jobID]
+			if !job.IsUbuntuSlim() {
+				continue
+			}
+			jobs = append(jobs, MonitoredJob{WorkflowPath: wf.Path, JobID: jobID, JobName: job.Name, MigratedAt: migratedAt(wf.Path, jobID)})
+		}
+	}
+	return jobs
+}
+
+// Monitor compares the runs of migrated jobs over window before and after
+// their migration, fetching the runs of each workflow once
+func Monitor(ctx context.Context, jobs []MonitoredJob, window time.Duration, thresholds MonitorThresholds) ([]*JobHealth, error) {
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	// Runs are fetched from the start of the earliest window of a workflow
+	now := time.Now()
+	since := make(map[string]time.Time)
+	var workflowPaths []string
+	for _, j := range jobs {
+		start := now.Add(-window)
+		if !j.MigratedAt.IsZero() && j.MigratedAt.Add(-window).Before(start) {
+			start = j.MigratedAt.Add(-window)
+		}
+		s, ok := since[j.WorkflowPath]
+		if !ok {
+			workflowPaths = append(workflowPaths, j.WorkflowPath)
+		}
+		if !ok || start.Before(s) {
+			since[j.WorkflowPath] = start
+		}
+	}
+
+	runs := make(map[string][]api.JobRun)
+	for _, path := range workflowPaths {
+		jobRuns, err := client.GetJobRuns(ctx, path, since[path])
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch runs of %s: %w", path, err)
+		}
+		runs[path] = jobRuns
+	}
+
+	health := make([]*JobHealth, len(jobs))
+	for i, j := range jobs {
+		health[i] = newJobHealth(runs[j.WorkflowPath], j, window, thresholds)
+	}
+	return health, nil
+}
+
+// newJobHealth compares the runs of a job before and after its migration:
+// runs started before and after MigratedAt within window, or runs on the
+// source and target runner labels if MigratedAt is unknown
+func newJobHealth(jobRuns []api.JobRun, job MonitoredJob, window time.Duration, thresholds MonitorThresholds) *JobHealth {
+	h := &JobHealth{WorkflowPath: job.WorkflowPath, JobID: job.JobID, JobName: job.JobName}
+	if !job.MigratedAt.IsZero() {
+		migratedAt := job.MigratedAt
+		h.MigratedAt = &migratedAt
+	}
+
+	var before, after []api.JobRun
+	for _, jr := range jobRuns {
+		if !api.MatchesJob(jr.Name, job.JobID, job.JobName, nil) {
+			continue
+		}
+		switch {
+		case !job.MigratedAt.IsZero():
+			if jr.StartedAt.Before(job.MigratedAt) {
+				if !jr.StartedAt.Before(job.MigratedAt.Add(-window)) {
+					before = append(before, jr)
+				}
+			} else {
+				after = append(after, jr)
+			}
+		case slices.Contains(jr.Labels, workflow.SourceLabel()):
+			before = append(before, jr)
+		case slices.Contains(jr.Labels, workflow.TargetLabel()):
+			after = append(after, jr)
+		}
+	}
+	h.Before = newRunHealth(before)
+	h.After = newRunHealth(after)
+
+	if h.Before.Runs == 0 || h.After.Runs < thresholds.MinRuns {
+		h.Insufficient = true
+		return h
+	}
+	if increase := h.After.FailureRate - h.Before.FailureRate; increase > thresholds.MaxFailureRateIncrease {
+		h.Regressions = append(h.Regressions, fmt.Sprintf("failure rate rose from %.0f%% to %.0f%%", 100*h.Before.FailureRate, 100*h.After.FailureRate))
+	}
+	if h.Before.median > 0 && h.After.median > 0 {
+		if slowdown := float64(h.After.median-h.Before.median) / float64(h.Before.median); slowdown > thresholds.MaxSlowdown {
+			h.Regressions = append(h.Regressions, fmt.Sprintf("median duration rose from %s to %s (+%.0f%%)", h.Before.Median, h.After.Median, 100*slowdown))
+		}
+	}
+	return h
+}
+
+// newRunHealth summarizes job runs
+func newRunHealth(jobRuns []api.JobRun) RunHealth {
+	var h RunHealth
+	var durations []time.Duration
+	for _, jr := range jobRuns {
+		switch jr.Conclusion {
+		case "success":
+			durations = append(durations, jr.Duration)
+		case "failure", "timed_out":
+			h.Failures++
+		default:
+			continue
+		}
+		h.Runs++
+	}
+	if h.Runs > 0 {
+		h.FailureRate = float64(h.Failures) / float64(h.Runs)
+	}
+	if len(durations) > 0 {
+		h.median = percentile(durations, 50)
+		h.Median = FormatDuration(h.median)
+	}
+	return h
+}
@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+)
+
+func TestNewJobHealth(t *testing.T) {
+	migratedAt := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	run := func(name, conclusion string, started time.Time, d time.Duration, labels ...string) api.JobRun {
+		return api.JobRun{Name: name, Conclusion: conclusion, StartedAt: started, Duration: d, Labels: labels}
+	}
+	var jobRuns []api.JobRun
+	// Before: 10 runs, 1 failure, 1 minute each, and one run outside the window
+	for i := 0; i < 10; i++ {
+		conclusion := "success"
+		if i == 0 {
+			conclusion = "failure"
+		}
+		jobRuns = append(jobRuns, run("Build", conclusion, migratedAt.Add(-time.Duration(i+1)*day), time.Minute, "ubuntu-latest"))
+	}
+	jobRuns = append(jobRuns, run("Build", "failure", migratedAt.Add(-30*day), time.Minute, "ubuntu-latest"))
+	// After: 5 runs, 2 failures, 2 minutes each, a cancelled run, and another job
+	for i := 0; i < 5; i++ {
+		conclusion := "success"
+		if i < 2 {
+			conclusion = "timed_out"
+		}
+		jobRuns = append(jobRuns, run("Build (20)", conclusion, migratedAt.Add(time.Duration(i+1)*day), 2*time.Minute, "ubuntu-slim"))
+	}
+	jobRuns = append(jobRuns,
+		run("Build", "cancelled", migratedAt.Add(day), 10*time.Minute, "ubuntu-slim"),
+		run("Lint", "failure", migratedAt.Add(day), time.Minute, "ubuntu-slim"),
+	)
+
+	job := MonitoredJob{WorkflowPath: "ci.yml", JobID: "build", JobName: "Build", MigratedAt: migratedAt}
+	h := newJobHealth(jobRuns, job, 14*day, DefaultMonitorThresholds)
+	if h.Before.Runs != 10 || h.Before.Failures != 1 || h.Before.Median != "1m" {
+		t.Errorf("Before = %+v, want 10 runs, 1 failure, and a median of 1m", h.Before)
+	}
+	if h.After.Runs != 5 || h.After.Failures != 2 || h.After.Median != "2m" {
+		t.Errorf("After = %+v, want 5 runs, 2 failures, and a median of 2m", h.After)
+	}
+	want := []string{"failure rate rose from 10% to 40%", "median duration rose from 1m to 2m (+100%)"}
+	if !reflect.DeepEqual(h.Regressions, want) || h.Insufficient {
+		t.Errorf("Regressions = %q (insufficient: %v), want %q", h.Regressions, h.Insufficient, want)
+	}
+
+	// Without a migration time, runs are told apart by runner label
+	job.MigratedAt = time.Time{}
+	h = newJobHealth(jobRuns, job, 14*day, DefaultMonitorThresholds)
+	if h.Before.Runs != 11 || h.After.Runs != 5 || h.MigratedAt != nil {
+		t.Errorf("newJobHealth() without migration time = %+v, want 11 runs before and 5 after", h)
+	}
+
+	// Too few runs after the migration to judge
+	h = newJobHealth(jobRuns, job, 14*day, MonitorThresholds{MinRuns: 6})
+	if !h.Insufficient || h.Regressed() {
+		t.Errorf("newJobHealth() with MinRuns 6 = %+v, want insufficient data", h)
+	}
+
+	// Within the thresholds
+	h = newJobHealth(jobRuns, job, 14*day, MonitorThresholds{MaxFailureRateIncrease: 0.5, MaxSlowdown: 1.5, MinRuns: 5})
+	if h.Regressed() {
+		t.Errorf("Regressions = %q, want none within the thresholds", h.Regressions)
+	}
+}
@@ -0,0 +1,158 @@
+package scan
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Readiness is the "slim readiness" of a set of jobs: the percentage of the
+// jobs that can run on ubuntu-slim (the migrated jobs and the candidates)
+// already migrated, weighted by their execution time
+type Readiness struct {
+	// Workflow is the workflow file, or "" for the whole repository
+	Workflow string `json:"workflow,omitempty"`
+	// Score is the percentage of eligible minutes on ubuntu-slim, from 0 to 100
+	Score float64 `json:"score"`
+	// Grade is the letter grade of the score, from A to F
+	Grade        string `json:"grade"`
+	MigratedJobs int    `json:"migrated_jobs"`
+	EligibleJobs int    `json:"eligible_jobs"` // Migrated jobs and candidates
+	// MigratedMinutes and EligibleMinutes are the execution times the score
+	// is computed from
+	MigratedMinutes float64 `json:"migrated_minutes"`
+	EligibleMinutes float64 `json:"eligible_minutes"`
+}
+
+// ReadinessReport is the slim readiness of the scanned repository and of
+// each of its workflow files
+type ReadinessReport struct {
+	Readiness
+	Workflows []*Readiness `json:"workflows"`
+}
+
+// gradeThresholds lists the minimum score of each grade, best first
+var gradeThresholds = []struct {
+	grade string
+	min   float64
+}{
+	{"A", 90},
+	{"B", 75},
+	{"C", 50},
+	{"D", 25},
+	{"F", 0},
+}
+
+// Grade returns the letter grade of a readiness score
+func Grade(score float64) string {
+	for _, t := range gradeThresholds {
+		if score >= t.min {
+			return t.grade
+		}
+	}
+	return "F"
+}
+
+// NewReadinessReport computes the slim readiness of a scan result. Each job
+// weighs its execution time in minutes; jobs whose execution time is unknown
+// weigh the average of the known ones, and every job weighs the same when
+// none is known. Ineligible jobs are not counted, and a set of jobs without
+// eligible jobs scores 100 since nothing is left to migrate. The durations of
+// migrated jobs are looked up by the scan along with those of the candidates,
+// which costs one more API request per migrated job unless
+// Options.SkipDuration is set.
+func NewReadinessReport(result *ScanResult) *ReadinessReport {
+	type weighted struct {
+		workflow string
+		minutes  float64 // 0 when unknown
+		migrated bool
+	}
+	var jobs []weighted
+	for _, m := range result.Migrated {
+		jobs = append(jobs, weighted{workflow: m.WorkflowPath, minutes: durationMinutes(m.Duration), migrated: true})
+	}
+	for _, c := range result.Candidates {
+		jobs = append(jobs, weighted{workflow: c.WorkflowPath, minutes: durationMinutes(c.Duration)})
+	}
+
+	fallback := 1.0
+	known, total := 0, 0.0
+	for _, j := range jobs {
+		if j.minutes > 0 {
+			known++
+			total += j.minutes
+		}
+	}
+	if known > 0 {
+		fallback = total / float64(known)
+	}
+
+	report := &ReadinessReport{Workflows: []*Readiness{}}
+	byWorkflow := make(map[string]*Readiness)
+	for _, j := range jobs {
+		w, ok := byWorkflow[j.workflow]
+		if !ok {
+			w = &Readiness{Workflow: j.workflow}
+			byWorkflow[j.workflow] = w
+			report.Workflows = append(report.Workflows, w)
+		}
+		minutes := j.minutes
+		if minutes == 0 {
+			minutes = fallback
+		}
+		for _, r := range []*Readiness{&report.Readiness, w} {
+			r.EligibleJobs++
+			r.EligibleMinutes += minutes
+			if j.migrated {
+				r.MigratedJobs++
+				r.MigratedMinutes += minutes
+			}
+		}
+	}
+	sort.Slice(report.Workflows, func(i, j int) bool {
+		return report.Workflows[i].Workflow < report.Workflows[j].Workflow
+	})
+	for _, r := range append([]*Readiness{&report.Readiness}, report.Workflows...) {
+		r.score()
+	}
+	return report
+}
+
+// Workflow returns the readiness of a workflow file, or nil if it has no
+// eligible jobs or if r is nil (e.g., a result saved without readiness)
+func (r *ReadinessReport) Workflow(path string) *Readiness {
+	if r == nil {
+		return nil
+	}
+	for _, w := range r.Workflows {
+		if w.Workflow == path {
+			return w
+		}
+	}
+	return nil
+}
+
+// score computes the score and grade from the minutes, rounded to a tenth
+func (r *Readiness) score() {
+	r.Score = 100
+	if r.EligibleMinutes > 0 {
+		r.Score = roundTenth(100 * r.MigratedMinutes / r.EligibleMinutes)
+	}
+	r.Grade = Grade(r.Score)
+	r.MigratedMinutes = roundTenth(r.MigratedMinutes)
+	r.EligibleMinutes = roundTenth(r.EligibleMinutes)
+}
+
+// durationMinutes parses a job duration (e.g., "4m12s") into minutes, or
+// returns 0 if it is unknown
+func durationMinutes(duration string) float64 {
+	d, err := time.ParseDuration(duration)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d.Minutes()
+}
+
+func roundTenth(f float64) float64 {
+	return math.Round(f*10) / 10
+}
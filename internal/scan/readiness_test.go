@@ -0,0 +1,62 @@
+package scan
+
+import "testing"
+
+func TestNewReadinessReport(t *testing.T) {
+	result := &ScanResult{
+		Candidates: []*Candidate{
+			{WorkflowPath: "ci.yml", JobID: "test", Duration: "6m"},
+			{WorkflowPath: "ci.yml", JobID: "lint"},
+			{WorkflowPath: "release.yml", JobID: "publish", Duration: "2m"},
+		},
+		IneligibleJobs: []*IneligibleJob{
+			{WorkflowPath: "ci.yml", JobID: "docker"},
+		},
+		Migrated: []*MigratedJob{
+			{WorkflowPath: "ci.yml", JobID: "build", Duration: "4m"},
+			{WorkflowPath: "docs.yml", JobID: "docs"},
+		},
+	}
+
+	report := NewReadinessReport(result)
+	// Unknown durations weigh the average of the known ones: 4 minutes
+	if r := report.Readiness; r.MigratedJobs != 2 || r.EligibleJobs != 5 || r.MigratedMinutes != 8 || r.EligibleMinutes != 20 || r.Score != 40 || r.Grade != "D" {
+		t.Errorf("NewReadinessReport() = %+v, want 2/5 jobs, 8/20 minutes, 40 (D)", r)
+	}
+	want := map[string]struct {
+		score float64
+		grade string
+	}{
+		"ci.yml":      {28.6, "D"},
+		"docs.yml":    {100, "A"},
+		"release.yml": {0, "F"},
+	}
+	if len(report.Workflows) != len(want) {
+		t.Fatalf("NewReadinessReport().Workflows = %+v, want %d workflows", report.Workflows, len(want))
+	}
+	for path, w := range want {
+		r := report.Workflow(path)
+		if r == nil || r.Score != w.score || r.Grade != w.grade {
+			t.Errorf("Workflow(%q) = %+v, want %v (%s)", path, r, w.score, w.grade)
+		}
+	}
+
+	// Every job weighs the same without known durations, and nothing left to
+	// migrate scores 100
+	report = NewReadinessReport(&ScanResult{Candidates: []*Candidate{{WorkflowPath: "ci.yml"}}, Migrated: []*MigratedJob{{WorkflowPath: "ci.yml"}, {WorkflowPath: "ci.yml"}, {WorkflowPath: "ci.yml"}}})
+	if report.Score != 75 || report.Grade != "B" {
+		t.Errorf("NewReadinessReport() without durations = %v (%s), want 75 (B)", report.Score, report.Grade)
+	}
+	if report = NewReadinessReport(&ScanResult{}); report.Score != 100 || report.Grade != "A" {
+		t.Errorf("NewReadinessReport() without eligible jobs = %v (%s), want 100 (A)", report.Score, report.Grade)
+	}
+}
+
+func TestMigratedDurationLookups(t *testing.T) {
+	caller := Caller{WorkflowPath: "ci.yml", JobID: "call", JobName: "Call"}
+	lookups := migratedDurationLookups([]*MigratedJob{{WorkflowPath: "build.yml", JobID: "build", JobName: "Build", CalledBy: []Caller{caller}}})
+	// Jobs of reusable workflows are looked up in the runs of their caller
+	if path, jobID, jobName := durationLookupKey(lookups[0]); path != "ci.yml" || jobID != "call / build" || jobName != "Call / Build" {
+		t.Errorf("durationLookupKey() = %q, %q, %q, want the caller's workflow and job", path, jobID, jobName)
+	}
+}
@@ -0,0 +1,47 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteJSON writes the scan result as an indented JSON document, the format
+// read by ReadResult. Empty job lists are written as [] rather than null.
+func (r *ScanResult) WriteJSON(w io.Writer) error {
+	out := *r
+	if out.Candidates == nil {
+		out.Candidates = []*Candidate{}
+	}
+	if out.IneligibleJobs == nil {
+		out.IneligibleJobs = []*IneligibleJob{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
+
+// ReadResult reads a scan result written by WriteJSON
+func ReadResult(r io.Reader) (*ScanResult, error) {
+	var result ScanResult
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid scan result: %w", err)
+	}
+	return &result, nil
+}
+
+// LoadResult reads a scan result saved to a file (e.g., with --output json)
+func LoadResult(path string) (*ScanResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan result: %w", err)
+	}
+	defer f.Close()
+	result, err := ReadResult(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return result, nil
+}
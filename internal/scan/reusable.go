@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Caller identifies a job that calls a reusable workflow via jobs.<id>.uses
+type Caller struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+}
+
+// String formats the caller as "<workflow-path>:<job-id>"
+func (c Caller) String() string {
+	return c.WorkflowPath + ":" + c.JobID
+}
+
+// resolveReusableWorkflows follows local reusable workflow references
+// (jobs.<id>.uses: ./.github/workflows/build.yml) from the given workflows.
+// It returns the given workflows plus any called workflows that were not
+// already loaded, and the callers of each called workflow keyed by its cleaned path.
+// Called workflows that fail to load are reported in loadErrors, keyed by path.
+func resolveReusableWorkflows(workflows []*workflow.Workflow) ([]*workflow.Workflow, map[string][]Caller, map[string]error) {
+	loaded := make(map[string]bool, len(workflows))
+	for _, wf := range workflows {
+		loaded[filepath.Clean(wf.Path)] = true
+	}
+
+	callers := make(map[string][]Caller)
+	loadErrors := make(map[string]error)
+
+	// Breadth-first, since reusable workflows can call other reusable workflows
+	all := append([]*workflow.Workflow(nil), workflows...)
+	for i := 0; i < len(all); i++ {
+		wf := all[i]
+		for _, jobID := range wf.JobIDs() {
+			job := wf.Jobs[jobID]
+			path, ok := job.LocalReusableWorkflow()
+			if !ok {
+				continue
+			}
+			callers[path] = append(callers[path], Caller{
+				WorkflowPath: wf.Path,
+				JobID:        jobID,
+				JobName:      job.Name,
+			})
+
+			if loaded[path] || loadErrors[path] != nil {
+				continue
+			}
+			called, err := workflow.LoadWorkflow(path)
+			if err != nil {
+				slog.Debug("failed to load reusable workflow", "workflow", path, "caller", wf.Path, "error", err)
+				loadErrors[path] = err
+				continue
+			}
+			loaded[path] = true
+			all = append(all, called)
+		}
+	}
+
+	return all, callers, loadErrors
+}
+
+// reusableCallReasons returns the ineligibility reasons for a job that calls a
+// reusable workflow, or nil if the call is attributed to the called workflow's jobs.
+func reusableCallReasons(job *workflow.Job, loadErrors map[string]error) []string {
+	path, ok := job.LocalReusableWorkflow()
+	if !ok {
+		return []string{fmt.Sprintf("calls remote reusable workflow %s (not analyzed)", job.Uses)}
+	}
+	if err := loadErrors[path]; err != nil {
+		return []string{fmt.Sprintf("calls reusable workflow %s that could not be loaded", path)}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan_ReusableWorkflows(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowDir := filepath.Join(tmpDir, ".github", "workflows")
+	if err := os.MkdirAll(workflowDir, 0755); err != nil {
+		t.Fatalf("Failed to create workflow directory: %v", err)
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer func() {
+		os.Chdir(originalWd)
+	}()
+
+	files := map[string]string{
+		"ci.yml": `name: ci
+on: push
+jobs:
+  build:
+    name: Build
+    uses: ./.github/workflows/build.yml
+  remote:
+    uses: octo-org/shared/.github/workflows/lint.yml@v1
+`,
+		"build.yml": `name: build
+on: workflow_call
+jobs:
+  compile:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo compile
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(workflowDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	// Only the caller is specified; the called workflow must be followed
+	result, err := Scan(true, ".github/workflows/ci.yml")
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	if len(result.Candidates) != 1 {
+		t.Fatalf("Scan() returned %d candidates, want 1: %+v", len(result.Candidates), result.Candidates)
+	}
+	c := result.Candidates[0]
+	if c.JobID != "compile" || c.WorkflowPath != filepath.Join(".github", "workflows", "build.yml") {
+		t.Errorf("candidate = %s in %s, want compile in build.yml", c.JobID, c.WorkflowPath)
+	}
+	if len(c.CalledBy) != 1 || c.CalledBy[0].String() != ".github/workflows/ci.yml:build" {
+		t.Errorf("candidate CalledBy = %v, want [.github/workflows/ci.yml:build]", c.CalledBy)
+	}
+
+	workflowPath, jobID, jobName := durationLookupKey(c)
+	if workflowPath != ".github/workflows/ci.yml" || jobID != "build / compile" || jobName != "Build / compile" {
+		t.Errorf("durationLookupKey() = (%s, %s, %s), want caller-based lookup", workflowPath, jobID, jobName)
+	}
+	former := formerLookupNames(c, map[string][]string{".github/workflows/build.yml:compile": {"Compile"}})
+	if len(former) != 1 || former[0] != "Build / Compile" {
+		t.Errorf("formerLookupNames() = %v, want [Build / Compile]", former)
+	}
+
+	ineligible := make(map[string]*IneligibleJob)
+	for _, job := range result.IneligibleJobs {
+		ineligible[job.JobID] = job
+	}
+	if _, ok := ineligible["build"]; ok {
+		t.Errorf("local reusable workflow caller should not be reported as ineligible")
+	}
+	if job, ok := ineligible["image"]; !ok || len(job.CalledBy) != 1 {
+		t.Errorf("called job using docker should be ineligible and attributed to its caller, got %+v", job)
+	}
+	if _, ok := ineligible["remote"]; !ok {
+		t.Errorf("remote reusable workflow caller should be reported as ineligible")
+	}
+}
@@ -0,0 +1,858 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Severity is how a rule violation affects a job
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The job cannot be migrated
+	SeverityWarning Severity = "warning" // The job can be migrated but requires attention
+	SeverityInfo    Severity = "info"    // The job is labeled; eligibility is not affected
+	SeverityOff     Severity = "off"     // The rule is not evaluated (see RuleSet.SetSeverity)
+)
+
+// ParseSeverity parses a severity configured for a rule: error, warning,
+// info, or off
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(strings.TrimSpace(s))); severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity %q (error, warning, info, or off)", s)
+}
+
+// severityRanks orders severities from the least to the most severe
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// AtLeast reports whether the severity is at least as severe as min
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRanks[s] > 0 && severityRanks[s] >= severityRanks[min]
+}
+
+// Mode is the kind of runner jobs are evaluated for
+type Mode string
+
+const (
+	ModeSlim Mode = "slim" // Migration to ubuntu-slim (the default)
+	ModeArm  Mode = "arm"  // Migration to an ARM runner (e.g., ubuntu-24.04-arm)
+	// Migration to a larger x64 runner, which runs the ubuntu-latest image
+	// with more cores: only the scope and credentials rules apply
+	ModeLarger Mode = "larger"
+)
+
+// Finding describes a rule violation
+type Finding struct {
+	Reason   string
+	Evidence []workflow.Evidence
+	// Assume, if set, is the job the remaining rules are evaluated against when
+	// a required rule is violated, instead of skipping them (e.g., the job with
+	// runs-on set to ubuntu-latest when runs-on is an input that can select it)
+	Assume *workflow.Job
+}
+
+// Rule is a migration check. To add a rule, append it to the registry below.
+type Rule struct {
+	ID          string // Stable identifier (e.g., "SLIM002")
+	Name        string // Short name used in the decision log (e.g., "docker-commands")
+	Severity    Severity
+	Description string
+	Rationale   string // Why a violation blocks (or affects) migration to ubuntu-slim
+	Remediation string // How to resolve a violation
+	// Required rules cannot be disabled. When a required rule is violated,
+	// the remaining rules are skipped.
+	Required bool
+	// Modes are the modes the rule is evaluated in. If empty, the rule only
+	// applies to ModeSlim.
+	Modes []Mode
+	// Check returns a finding if the job violates the rule, or nil.
+	// wf may be nil. Rules without Check are evaluated separately by Scan
+	// because they need network access.
+	Check func(job *workflow.Job, wf *workflow.Workflow) *Finding
+}
+
+// AppliesTo reports whether the rule is evaluated in mode
+func (r *Rule) AppliesTo(mode Mode) bool {
+	if len(r.Modes) == 0 {
+		return mode == ModeSlim
+	}
+	for _, m := range r.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// registry lists the rules in evaluation order
+var registry = []*Rule{
+	{
+		ID:          "SLIM001",
+		Name:        "runs-on",
+		Severity:    SeverityError,
+		Description: "The job runs on ubuntu-latest",
+		Rationale:   "ubuntu-slim replaces ubuntu-latest. Jobs on other runners (windows, macos, self-hosted, pinned Ubuntu versions, or runners chosen by an expression) are out of scope and never modified.",
+		Remediation: "Nothing to do. To migrate the job anyway, change runs-on to ubuntu-latest and scan again, or switch it to ubuntu-slim by hand. If runs-on is reported as a typo of a known label, fix it: the job waits for a runner forever.",
+		Required:    true,
+		Modes:       []Mode{ModeSlim, ModeArm, ModeLarger},
+		Check: func(job *workflow.Job, wf *workflow.Workflow) *Finding {
+			if job.IsUbuntuLatest() {
+				return nil
+			}
+			if f := runnerInputFinding(job, wf); f != nil {
+				return f
+			}
+			if f := runnerVariableFinding(job, wf); f != nil {
+				return f
+			}
+			reason := "does not run on " + workflow.SourceLabel()
+			line := fmt.Sprint(job.RunsOn)
+			group := job.RunnerGroup()
+			if group != "" {
+				line = fmt.Sprintf("group: %s, labels: %s", group, strings.Join(job.RunnerLabels(), ", "))
+			}
+			switch typos := job.RunnerLabelTypos(); {
+			case len(typos) > 0:
+				reason = fmt.Sprintf("runs-on %q looks like a typo of %q", typos[0].Label, typos[0].Suggestion)
+			case job.IsSelfHosted():
+				reason = fmt.Sprintf("runs on self-hosted runners (%s)", strings.Join(job.RunnerLabels(), ", "))
+			case group != "":
+				reason = fmt.Sprintf("runs in runner group %s without the %s label", group, workflow.SourceLabel())
+			}
+			return &Finding{
+				Reason:   reason,
+				Evidence: []workflow.Evidence{{Line: line, Pattern: "runs-on"}},
+			}
+		},
+	},
+	{
+		ID:          "SLIM002",
+		Name:        "docker-commands",
+		Severity:    SeverityError,
+		Description: "The job does not run Docker or other container commands (podman, buildah, nerdctl, skopeo, kaniko)",
+		Rationale:   "ubuntu-slim runs the job itself inside a container and provides neither a Docker daemon nor a rootless container runtime, so docker build, docker compose, podman run, buildah bud, and similar commands fail.",
+		Remediation: "Keep the job on ubuntu-latest, or move the container work to a separate job that stays on ubuntu-latest and migrate the rest. Tools missing from the catalog can be added under container_tools: in the config file.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.DockerCommandEvidence()
+			return findingIf(containerCommandReason(evidence), evidence)
+		},
+	},
+	{
+		ID:          "SLIM003",
+		Name:        "container-actions",
+		Severity:    SeverityError,
+		Description: "The job does not use container-based GitHub Actions",
+		Rationale:   "Container-based actions (e.g., docker/build-push-action, docker/login-action, or local actions with runs.using: docker) need a Docker daemon, which ubuntu-slim does not provide.",
+		Remediation: "Keep the job on ubuntu-latest, or replace the action with a JavaScript or composite action that does the same work without Docker.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			return findingIf("uses container-based GitHub Actions", job.ContainerActionEvidence())
+		},
+	},
+	{
+		ID:          "SLIM004",
+		Name:        "services",
+		Severity:    SeverityError,
+		Description: "The job does not use service containers",
+		Rationale:   "Service containers (services:) are started by the runner with Docker, which is not available on ubuntu-slim.",
+		Remediation: "Keep the job on ubuntu-latest, or run the service without Docker: install it with a setup action or package manager in a step (use --suggest-services for alternatives).",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			if !job.HasServices() {
+				return nil
+			}
+			images := job.ServiceImages()
+			names := make([]string, 0, len(images))
+			for name := range images {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			evidence := []workflow.Evidence{{Pattern: "services"}}
+			if len(names) > 0 {
+				evidence = evidence[:0]
+				for _, name := range names {
+					evidence = append(evidence, workflow.Evidence{Line: strings.TrimSpace(name + " " + images[name]), Pattern: "services"})
+				}
+			}
+			return &Finding{Reason: "uses service containers", Evidence: evidence}
+		},
+	},
+	{
+		ID:          "SLIM005",
+		Name:        "container",
+		Severity:    SeverityError,
+		Description: "The job does not run its steps in a container (container:)",
+		Rationale:   "container: runs the job's steps inside a Docker container. ubuntu-slim is itself a container and cannot start nested containers.",
+		Remediation: "Keep the job on ubuntu-latest, or remove container: and install the tools the job needs in setup steps.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			if !job.HasContainer() {
+				return nil
+			}
+			return &Finding{
+				Reason:   "uses container syntax",
+				Evidence: []workflow.Evidence{{Line: fmt.Sprint(job.Container), Pattern: "container"}},
+			}
+		},
+	},
+	{
+		ID:          "SLIM006",
+		Name:        "docker-actions",
+		Severity:    SeverityError,
+		Description: "The job does not use Docker-based third-party actions (checked with --resolve-actions)",
+		Rationale:   "Third-party actions with runs.using: docker are run by the runner with Docker, which is not available on ubuntu-slim. Their names often do not reveal this, so they are only detected with --resolve-actions.",
+		Remediation: "Keep the job on ubuntu-latest, or replace the action with a JavaScript or composite alternative.",
+	},
+	{
+		ID:          "SLIM007",
+		Name:        "missing-commands",
+		Severity:    SeverityWarning,
+		Description: "The job does not use commands missing in ubuntu-slim without installing them",
+		Rationale:   "ubuntu-slim ships far fewer preinstalled tools than ubuntu-latest. The job can be migrated, but commands that exist only on ubuntu-latest (e.g., nvm, lsof) fail with \"command not found\" unless they are installed first.",
+		Remediation: "Install the commands in an earlier step (the setup action named in the reason, apt-get install, pip install, ...), then scan again; commands installed earlier in the job are not reported. fix --add-setup inserts the steps for commands with a known installer.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			// Cloud CLIs are reported by SLIM011
+			var evidence []workflow.Evidence
+			var commands []string
+			for _, e := range job.MissingCommandEvidence() {
+				if !workflow.IsCloudCLI(e.Pattern) {
+					evidence = append(evidence, e)
+					commands = append(commands, e.Pattern)
+				}
+			}
+			if len(evidence) == 0 {
+				return nil
+			}
+			return &Finding{
+				Reason:   MissingCommandsReason(commands),
+				Evidence: evidence,
+			}
+		},
+	},
+	{
+		ID:          "SLIM008",
+		Name:        "credentials",
+		Severity:    SeverityInfo,
+		Description: "The job does not handle secrets or OIDC tokens",
+		Rationale:   "Jobs handling secrets or OIDC tokens can be migrated, but a change of runner in such jobs deserves a manual review.",
+		Remediation: "Review the job, then migrate it with fix, or skip such jobs with fix --exclude-credentialed.",
+		Modes:       []Mode{ModeSlim, ModeArm, ModeLarger},
+		Check: func(job *workflow.Job, wf *workflow.Workflow) *Finding {
+			var permissions interface{}
+			if wf != nil {
+				permissions = wf.Permissions
+			}
+			usage := job.CredentialUsage(permissions)
+			if len(usage) == 0 {
+				return nil
+			}
+			evidence := make([]workflow.Evidence, len(usage))
+			for i, u := range usage {
+				evidence[i] = workflow.Evidence{Line: u}
+			}
+			return &Finding{Reason: "credentialed: " + strings.Join(usage, ", "), Evidence: evidence}
+		},
+	},
+	{
+		ID:          "SLIM009",
+		Name:        "testcontainers",
+		Severity:    SeverityError,
+		Description: "The job does not run tests using Testcontainers (heuristic)",
+		Rationale:   "Testcontainers starts containers from the tests through a Docker daemon, which ubuntu-slim does not provide. A job is flagged when it runs a test command (go test, npm test, pytest, mvn verify, ...) in a project depending on Testcontainers (go.mod, package.json, requirements, pom.xml, build.gradle) or with TESTCONTAINERS_* environment variables.",
+		Remediation: "Keep the job on ubuntu-latest, or split the tests using Testcontainers into a separate job that stays on ubuntu-latest. If the job's tests do not start containers, disable the rule with --disable-rule SLIM009.",
+		Check: func(job *workflow.Job, wf *workflow.Workflow) *Finding {
+			var env map[string]interface{}
+			if wf != nil {
+				env = wf.Env
+			}
+			return findingIf("runs tests using Testcontainers", job.TestcontainersEvidence(env))
+		},
+	},
+	{
+		ID:          "SLIM010",
+		Name:        "toolchain-versions",
+		Severity:    SeverityWarning,
+		Description: "The job does not rely on a preinstalled toolchain whose version differs on ubuntu-slim",
+		Rationale:   "node and python3 are preinstalled on both ubuntu-latest and ubuntu-slim, but not necessarily in the same version. A job using the preinstalled version without pinning it may behave differently after the migration. Versions come from the command dataset, updated with gh slimify db update; toolchains without a known version on both images are not reported.",
+		Remediation: "Pin the version the job needs with the setup action named in the reason (e.g., actions/setup-node with node-version: 20), then scan again.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.ToolchainVersionEvidence()
+			if len(evidence) == 0 {
+				return nil
+			}
+			commands := make([]string, len(evidence))
+			for i, e := range evidence {
+				commands[i] = e.Pattern
+			}
+			return &Finding{Reason: ToolchainVersionsReason(commands), Evidence: evidence}
+		},
+	},
+	{
+		ID:          "SLIM011",
+		Name:        "cloud-clis",
+		Severity:    SeverityWarning,
+		Description: "The job does not use cloud CLIs missing in ubuntu-slim without installing them",
+		Rationale:   "Cloud and infrastructure CLIs (aws, az, gcloud, kubectl, helm, terraform, ...) are preinstalled on ubuntu-latest but mostly not on ubuntu-slim. Deployment jobs rarely install the CLIs they call, which makes them the most common breakage after a migration. They are reported here rather than by SLIM007.",
+		Remediation: "Install the CLIs in an earlier step (the setup action named in the reason, or the vendor's installer), then scan again. fix --add-setup inserts the steps for CLIs with a known setup action.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.CloudCLIEvidence()
+			if len(evidence) == 0 {
+				return nil
+			}
+			commands := make([]string, len(evidence))
+			for i, e := range evidence {
+				commands[i] = e.Pattern
+			}
+			return &Finding{Reason: CloudCLIsReason(commands), Evidence: evidence}
+		},
+	},
+	{
+		ID:          "SLIM012",
+		Name:        "disk-footprint",
+		Severity:    SeverityWarning,
+		Description: "The job does not download large Git LFS objects, submodules, artifacts, or caches (heuristic)",
+		Rationale:   "ubuntu-slim has a much smaller disk than ubuntu-latest. Checkouts with Git LFS or submodules, downloads of all the run's artifacts, and caches of build tools known to grow to several GB (~/.gradle, ~/.m2, ~/.cache/bazel, ...) may fill it. Cache paths are configurable in the footprint section of the configuration file.",
+		Remediation: "Check the size of what the job downloads: fetch only the LFS objects, submodules, and artifacts it needs, or keep the job on ubuntu-latest. If the downloads are small, disable the rule with --disable-rule SLIM012.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.DiskFootprintEvidence()
+			return findingIf(footprintReason("May exceed the disk of ubuntu-slim", evidence), evidence)
+		},
+	},
+	{
+		ID:          "SLIM013",
+		Name:        "memory-footprint",
+		Severity:    SeverityWarning,
+		Description: "The job does not run memory-hungry builds: Android, Bazel, Gradle, or large heaps (heuristic)",
+		Rationale:   "ubuntu-slim has much less memory than ubuntu-latest. Android, Bazel, and Gradle builds, and JVM or Node.js processes given a heap above the maximum heap (-Xmx, --max-old-space-size; 3g by default), may be killed for running out of memory. The maximum heap and build tools are configurable in the footprint section of the configuration file.",
+		Remediation: "Lower the heap and the parallelism of the build (e.g., org.gradle.workers.max), then check that it succeeds on ubuntu-slim, or keep the job on ubuntu-latest.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.MemoryFootprintEvidence()
+			return findingIf(footprintReason("May exceed the memory of ubuntu-slim", evidence), evidence)
+		},
+	},
+	{
+		ID:          "SLIM014",
+		Name:        "manual-services",
+		Severity:    SeverityWarning,
+		Description: "The job does not start daemons usually provided as services (redis-server, pg_ctl start, systemctl start mysql, ...)",
+		Rationale:   "Some jobs start databases and other daemons in run: steps instead of services:, relying on packages preinstalled on ubuntu-latest (PostgreSQL, MySQL) and on an init system for service and systemctl. ubuntu-slim does not necessarily provide either. Daemons whose package is installed earlier in the job are not reported.",
+		Remediation: "Install the daemon's package in an earlier step and start the daemon directly (e.g., redis-server --daemonize yes), then check that the job succeeds on ubuntu-slim, or keep the job on ubuntu-latest with services:.",
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.ManualServiceEvidence()
+			if len(evidence) == 0 {
+				return nil
+			}
+			return &Finding{Reason: ManualServicesReason(evidencePatterns(evidence)), Evidence: evidence}
+		},
+	},
+	{
+		ID:          "ARM001",
+		Name:        "x86-dependencies",
+		Severity:    SeverityError,
+		Description: "The job does not download x86 binaries or select an x86 architecture (ARM mode)",
+		Rationale:   "ARM runners cannot run x86-64 binaries. Jobs downloading release assets built for amd64/x86_64 (curl, wget, gh release download), forcing --platform linux/amd64, or setting up tools with architecture: x64 fail on them.",
+		Remediation: "Download the arm64 (aarch64) build of the tool, preferably selected from the runner architecture (runner.arch), or keep the job on x86.",
+		Modes:       []Mode{ModeArm},
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := job.ArchSpecificEvidence()
+			if len(evidence) == 0 {
+				return nil
+			}
+			return &Finding{Reason: fmt.Sprintf("depends on x86 (%s)", evidence[0].Line), Evidence: evidence}
+		},
+	},
+	{
+		ID:          "ARM002",
+		Name:        "container-images",
+		Severity:    SeverityWarning,
+		Description: "The job does not run containers whose images may lack arm64 variants (ARM mode)",
+		Rationale:   "ARM runners provide Docker, so container commands, container actions, services, and container: work, but every image the job pulls must be published for linux/arm64.",
+		Remediation: "Check that the images are multi-platform (docker manifest inspect <image>) or switch to images that are, then migrate the job.",
+		Modes:       []Mode{ModeArm},
+		Check: func(job *workflow.Job, _ *workflow.Workflow) *Finding {
+			evidence := append(job.DockerCommandEvidence(), job.ContainerActionEvidence()...)
+			images := job.ServiceImages()
+			names := make([]string, 0, len(images))
+			for name := range images {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				evidence = append(evidence, workflow.Evidence{Line: strings.TrimSpace(name + " " + images[name]), Pattern: "services"})
+			}
+			if job.HasContainer() {
+				evidence = append(evidence, workflow.Evidence{Line: fmt.Sprint(job.Container), Pattern: "container"})
+			}
+			return findingIf("runs containers: images must provide linux/arm64 variants", evidence)
+		},
+	},
+}
+
+// IDs of rules referenced outside the registry
+const (
+	RuleDockerActions     = "SLIM006"
+	RuleMissingCommands   = "SLIM007"
+	RuleCredentials       = "SLIM008"
+	RuleToolchainVersions = "SLIM010"
+	RuleCloudCLIs         = "SLIM011"
+	RuleDiskFootprint     = "SLIM012"
+	RuleMemoryFootprint   = "SLIM013"
+	RuleManualServices    = "SLIM014"
+	RuleContainerImages   = "ARM002"
+)
+
+// RuleUnknownDuration names the severity of unknown execution times (see
+// RuleSet.SetSeverity). It is not a rule of the registry: durations are looked
+// up after the rules are evaluated.
+const RuleUnknownDuration = "unknown-duration"
+
+// MissingCommandsReason describes the commands missing in ubuntu-slim, naming
+// the setup action installing each command that has one (e.g., "Setup may be
+// required (go via actions/setup-go@v5, lsof)")
+func MissingCommandsReason(commands []string) string {
+	return fmt.Sprintf("Setup may be required (%s)", describeSetup(commands))
+}
+
+// CloudCLIsReason describes the cloud CLIs missing in ubuntu-slim like
+// MissingCommandsReason (e.g., "Relies on cloud CLIs missing in ubuntu-slim
+// (kubectl via azure/setup-kubectl@v4, aws)")
+func CloudCLIsReason(commands []string) string {
+	return fmt.Sprintf("Relies on cloud CLIs missing in ubuntu-slim (%s)", describeSetup(commands))
+}
+
+// describeSetup lists commands, naming the setup action installing each
+// command that has one
+func describeSetup(commands []string) string {
+	described := make([]string, len(commands))
+	for i, cmd := range commands {
+		described[i] = cmd
+		if actions := workflow.SetupActions([]string{cmd}); len(actions) > 0 {
+			described[i] = fmt.Sprintf("%s via %s", cmd, actions[0])
+		}
+	}
+	return strings.Join(described, ", ")
+}
+
+// ToolchainVersionsReason describes the preinstalled toolchains whose version
+// differs on ubuntu-slim, with the step pinning each (e.g., "Preinstalled
+// version differs on ubuntu-slim (node 20.19.5 → 22.20.0; pin it with
+// actions/setup-node@v4 with node-version: 20)")
+func ToolchainVersionsReason(commands []string) string {
+	described := make([]string, len(commands))
+	for i, cmd := range commands {
+		info := workflow.LookupCommand(cmd)
+		described[i] = fmt.Sprintf("%s %s → %s; pin it with %s", cmd, info.LatestVersion, info.SlimVersion, workflow.ToolchainPin(cmd))
+	}
+	return fmt.Sprintf("Preinstalled version differs on ubuntu-slim (%s)", strings.Join(described, ", "))
+}
+
+// ManualServicesReason describes the daemons a job starts in run: steps
+// (e.g., "Starts services manually (redis-server, postgresql)")
+func ManualServicesReason(services []string) string {
+	return fmt.Sprintf("Starts services manually (%s)", strings.Join(services, ", "))
+}
+
+// footprintReason describes the footprint evidence of a job, listing what
+// matched (e.g., "May exceed the memory of ubuntu-slim (gradlew, -Xmx6g)")
+func footprintReason(reason string, evidence []workflow.Evidence) string {
+	return fmt.Sprintf("%s (%s)", reason, strings.Join(evidencePatterns(evidence), ", "))
+}
+
+// evidencePatterns returns the patterns of evidence, once each
+func evidencePatterns(evidence []workflow.Evidence) []string {
+	var patterns []string
+	seen := make(map[string]bool)
+	for _, e := range evidence {
+		if !seen[e.Pattern] {
+			patterns = append(patterns, e.Pattern)
+			seen[e.Pattern] = true
+		}
+	}
+	return patterns
+}
+
+// findingIf returns a finding with reason if there is evidence, or nil
+func findingIf(reason string, evidence []workflow.Evidence) *Finding {
+	if len(evidence) == 0 {
+		return nil
+	}
+	return &Finding{Reason: reason, Evidence: evidence}
+}
+
+// containerCommandReason returns "uses Docker commands", or names the
+// container tools used if others than docker are (e.g., "uses container
+// commands (podman, buildah)")
+func containerCommandReason(evidence []workflow.Evidence) string {
+	var tools []string
+	seen := make(map[string]bool)
+	for _, e := range evidence {
+		if !seen[e.Tool] {
+			seen[e.Tool] = true
+			tools = append(tools, e.Tool)
+		}
+	}
+	if len(tools) <= 1 && (len(tools) == 0 || tools[0] == "docker") {
+		return "uses Docker commands"
+	}
+	return fmt.Sprintf("uses container commands (%s)", strings.Join(tools, ", "))
+}
+
+// Rules returns the registered rules in evaluation order
+func Rules() []*Rule {
+	return append([]*Rule(nil), registry...)
+}
+
+// LookupRule returns the rule with the given ID (case-insensitive) or name
+func LookupRule(idOrName string) (*Rule, bool) {
+	for _, r := range registry {
+		if strings.EqualFold(r.ID, idOrName) || r.Name == idOrName {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// RuleSet selects the enabled rules: the rules of its mode (ModeSlim unless
+// set with SetMode) that are not disabled, with the severity set with
+// SetSeverity or their own. A nil RuleSet enables every rule of ModeSlim.
+type RuleSet struct {
+	disabled   map[string]bool
+	severities map[string]Severity
+	mode       Mode
+}
+
+// SetMode selects the rules evaluated for mode
+func (s *RuleSet) SetMode(mode Mode) {
+	s.mode = mode
+}
+
+// WithMode returns a copy of the rule set that evaluates the rules of mode,
+// with the same rules disabled
+func (s *RuleSet) WithMode(mode Mode) *RuleSet {
+	c := NewRuleSet()
+	if s != nil {
+		for id := range s.disabled {
+			c.disabled[id] = true
+		}
+		for id, severity := range s.severities {
+			c.severities[id] = severity
+		}
+	}
+	c.mode = mode
+	return c
+}
+
+// Mode returns the mode the rules are evaluated in
+func (s *RuleSet) Mode() Mode {
+	if s == nil || s.mode == "" {
+		return ModeSlim
+	}
+	return s.mode
+}
+
+// NewRuleSet returns a rule set with every rule enabled
+func NewRuleSet() *RuleSet {
+	return &RuleSet{disabled: make(map[string]bool), severities: make(map[string]Severity)}
+}
+
+// Disable disables the rules given by ID or name
+func (s *RuleSet) Disable(rules ...string) error {
+	for _, idOrName := range rules {
+		r, ok := LookupRule(idOrName)
+		if !ok {
+			return fmt.Errorf("unknown rule %q", idOrName)
+		}
+		if r.Required {
+			return fmt.Errorf("rule %s (%s) cannot be disabled", r.ID, r.Name)
+		}
+		s.disabled[r.ID] = true
+	}
+	return nil
+}
+
+// Enable re-enables the rules given by ID or name
+func (s *RuleSet) Enable(rules ...string) error {
+	for _, idOrName := range rules {
+		r, ok := LookupRule(idOrName)
+		if !ok {
+			return fmt.Errorf("unknown rule %q", idOrName)
+		}
+		delete(s.disabled, r.ID)
+	}
+	return nil
+}
+
+// SetSeverity overrides the severity of the rule given by ID or name, or of
+// unknown execution times (RuleUnknownDuration), which decides how jobs are
+// classified: errors make them ineligible, warnings make them require
+// attention, and info only labels them. SeverityOff disables the rule.
+// Required rules are always errors.
+func (s *RuleSet) SetSeverity(idOrName string, severity Severity) error {
+	if _, err := ParseSeverity(string(severity)); err != nil {
+		return err
+	}
+	if idOrName == RuleUnknownDuration {
+		s.severities[RuleUnknownDuration] = severity
+		return nil
+	}
+	r, ok := LookupRule(idOrName)
+	if !ok {
+		return fmt.Errorf("unknown rule %q", idOrName)
+	}
+	if r.Required && severity != SeverityError {
+		return fmt.Errorf("the severity of rule %s (%s) cannot be changed", r.ID, r.Name)
+	}
+	if severity == SeverityOff {
+		return s.Disable(r.ID)
+	}
+	s.severities[r.ID] = severity
+	return nil
+}
+
+// Severity returns the severity of the rule with the given ID, or of unknown
+// execution times (RuleUnknownDuration)
+func (s *RuleSet) Severity(id string) Severity {
+	if s != nil {
+		if severity, ok := s.severities[id]; ok {
+			return severity
+		}
+	}
+	if r, ok := LookupRule(id); ok {
+		return r.Severity
+	}
+	return SeverityWarning
+}
+
+// Enabled reports whether the rule with the given ID is enabled
+func (s *RuleSet) Enabled(id string) bool {
+	if r, ok := LookupRule(id); ok && !r.AppliesTo(s.Mode()) {
+		return false
+	}
+	return s == nil || !s.disabled[id]
+}
+
+// evaluateRules evaluates the enabled rules that have a Check against a job.
+// Rules after a violated required rule are skipped.
+func evaluateRules(job *workflow.Job, wf *workflow.Workflow, set *RuleSet) []RuleResult {
+	var results []RuleResult
+	blocked := false
+	assumed := -1 // Index of the result whose finding assumed another job
+	for _, r := range registry {
+		if r.Check == nil || !r.AppliesTo(set.Mode()) {
+			continue
+		}
+		result := RuleResult{ID: r.ID, Rule: r.Name}
+		switch {
+		case !set.Enabled(r.ID):
+			result.Result = ResultSkip
+			result.Reason = "disabled"
+		case blocked:
+			result.Result = ResultSkip
+		default:
+			f := r.Check(job, wf)
+			if f == nil {
+				result.Result = ResultPass
+				break
+			}
+			result.Result = severityResults[set.Severity(r.ID)]
+			result.Reason = f.Reason
+			result.Evidence = f.Evidence
+			if r.Required && f.Assume != nil {
+				job = f.Assume
+				assumed = len(results)
+			} else if r.Required {
+				blocked = true
+			}
+		}
+		results = append(results, result)
+	}
+	// "eligible when runner=ubuntu-latest" only holds if nothing else failed
+	if assumed >= 0 && len(failedReasons(results[assumed+1:])) > 0 {
+		results[assumed].Reason, _, _ = strings.Cut(results[assumed].Reason, ":")
+	}
+	return results
+}
+
+// severityResults maps rule severities to the result of a violation
+var severityResults = map[Severity]string{
+	SeverityError:   ResultFail,
+	SeverityWarning: ResultWarn,
+	SeverityInfo:    ResultInfo,
+}
+
+// failedReasons returns the reasons of failed results
+func failedReasons(results []RuleResult) []string {
+	var reasons []string
+	for _, r := range results {
+		if r.Result == ResultFail {
+			reasons = append(reasons, r.Reason)
+		}
+	}
+	return reasons
+}
+
+// failedRuleIDs returns the rule IDs of failed results, in the order of
+// failedReasons
+func failedRuleIDs(results []RuleResult) []string {
+	var ids []string
+	for _, r := range results {
+		if r.Result == ResultFail {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// ruleEvidence returns the evidence of the result of the rule with the given ID
+func ruleEvidence(results []RuleResult, id string) []workflow.Evidence {
+	for _, r := range results {
+		if r.ID == id {
+			return r.Evidence
+		}
+	}
+	return nil
+}
+
+// warningEvidence returns the evidence of the result of the rule with the
+// given ID if the rule warned about the job, and none if its severity was
+// changed (e.g., to info)
+func warningEvidence(results []RuleResult, id string) []workflow.Evidence {
+	for _, r := range results {
+		if r.ID == id && r.Result == ResultWarn {
+			return r.Evidence
+		}
+	}
+	return nil
+}
+
+// RunnerChoice classifies a job for one value of the workflow_dispatch or
+// workflow_call input its runs-on is set to
+type RunnerChoice struct {
+	Input   string `json:"input"`
+	Value   string `json:"value"`
+	Default bool   `json:"default,omitempty"`
+	// Reasons lists why the job cannot be migrated with this value
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Eligible reports whether the job can be migrated when the input has this value
+func (c RunnerChoice) Eligible() bool {
+	return len(c.Reasons) == 0
+}
+
+// RunnerChoices classifies a job for each value of the workflow_dispatch or
+// workflow_call input its runs-on is set to (runs-on: ${{ inputs.runner }}). Returns nil if runs-on
+// is not an input or its values cannot be enumerated.
+func RunnerChoices(job *workflow.Job, wf *workflow.Workflow, set *RuleSet) []RunnerChoice {
+	name, input := runnerInput(job, wf)
+	if input == nil {
+		return nil
+	}
+	var choices []RunnerChoice
+	for _, value := range input.Values() {
+		probe := *job
+		probe.RunsOn = value
+		choices = append(choices, RunnerChoice{
+			Input:   name,
+			Value:   value,
+			Default: value == input.DefaultValue(),
+			Reasons: failedReasons(evaluateRules(&probe, wf, set)),
+		})
+	}
+	return choices
+}
+
+// runnerInput returns the workflow_dispatch or workflow_call input runs-on is
+// set to, or a nil input
+func runnerInput(job *workflow.Job, wf *workflow.Workflow) (string, *workflow.DispatchInput) {
+	name, ok := job.RunnerInput()
+	if !ok || wf == nil {
+		return "", nil
+	}
+	input := wf.Input(name)
+	if input == nil || len(input.Values()) == 0 {
+		return "", nil
+	}
+	return name, input
+}
+
+// runnerInputFinding returns the runs-on finding for a job whose runs-on is a
+// workflow_dispatch or workflow_call input, or nil if it is not. If ubuntu-latest is one of
+// the input's values, the remaining rules are evaluated assuming it.
+func runnerInputFinding(job *workflow.Job, wf *workflow.Workflow) *Finding {
+	name, input := runnerInput(job, wf)
+	if input == nil {
+		return nil
+	}
+
+	var evidence []workflow.Evidence
+	selectsUbuntuLatest := false
+	for _, value := range input.Values() {
+		line := name + "=" + value
+		if value == input.DefaultValue() {
+			line += " (default)"
+		}
+		evidence = append(evidence, workflow.Evidence{Line: line, Pattern: "inputs." + name})
+		selectsUbuntuLatest = selectsUbuntuLatest || value == workflow.SourceLabel()
+	}
+	if !selectsUbuntuLatest {
+		return &Finding{
+			Reason:   fmt.Sprintf("runs-on depends on input %s, which never selects %s", name, workflow.SourceLabel()),
+			Evidence: evidence,
+		}
+	}
+
+	label := name + "=" + workflow.SourceLabel()
+	if input.DefaultValue() == workflow.SourceLabel() {
+		label += " (default)"
+	}
+	assume := *job
+	assume.RunsOn = workflow.SourceLabel()
+	return &Finding{
+		Reason:   fmt.Sprintf("runs-on depends on input %s: eligible when %s", name, label),
+		Evidence: evidence,
+		Assume:   &assume,
+	}
+}
+
+// runnerVariableFinding returns the runs-on finding for a job whose runs-on is
+// a configuration variable, or nil if it is not. If the variable was looked up
+// and is set to ubuntu-latest, the remaining rules are evaluated assuming it.
+func runnerVariableFinding(job *workflow.Job, wf *workflow.Workflow) *Finding {
+	name, ok := job.RunnerVariable()
+	if !ok {
+		return nil
+	}
+	var value string
+	if wf != nil {
+		value, ok = wf.Variables[name]
+	}
+	if !ok {
+		return &Finding{
+			Reason:   fmt.Sprintf("runs-on depends on variable %s, whose value is unknown (look it up with --resolve-variables)", name),
+			Evidence: []workflow.Evidence{{Line: fmt.Sprint(job.RunsOn), Pattern: "vars." + name}},
+		}
+	}
+
+	evidence := []workflow.Evidence{{Line: name + "=" + value, Pattern: "vars." + name}}
+	if value != workflow.SourceLabel() {
+		return &Finding{
+			Reason:   fmt.Sprintf("runs-on depends on variable %s, which is set to %s", name, value),
+			Evidence: evidence,
+		}
+	}
+	assume := *job
+	assume.RunsOn = value
+	return &Finding{
+		Reason:   fmt.Sprintf("runs-on depends on variable %s: eligible while %s=%s", name, name, value),
+		Evidence: evidence,
+		Assume:   &assume,
+	}
+}
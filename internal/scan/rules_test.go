@@ -0,0 +1,261 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestRegistry(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, r := range Rules() {
+		if r.ID == "" || r.Name == "" || r.Description == "" || r.Rationale == "" || r.Remediation == "" {
+			t.Errorf("rule %+v is missing an ID, name, or documentation", r)
+		}
+		if seen[r.ID] || seen[r.Name] {
+			t.Errorf("rule %s (%s) is registered twice", r.ID, r.Name)
+		}
+		seen[r.ID] = true
+		seen[r.Name] = true
+		if _, ok := severityResults[r.Severity]; !ok {
+			t.Errorf("rule %s has unknown severity %q", r.ID, r.Severity)
+		}
+	}
+}
+
+func TestRuleSet(t *testing.T) {
+	rules := NewRuleSet()
+	if err := rules.Disable("slim007", "credentials"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if rules.Enabled("SLIM007") || rules.Enabled("SLIM008") {
+		t.Errorf("SLIM007 and SLIM008 should be disabled")
+	}
+	if err := rules.Enable("missing-commands"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	if !rules.Enabled("SLIM007") {
+		t.Errorf("SLIM007 should be re-enabled")
+	}
+
+	if err := rules.Disable("SLIM999"); err == nil {
+		t.Errorf("Disable() of an unknown rule should fail")
+	}
+	if err := rules.Disable("runs-on"); err == nil {
+		t.Errorf("Disable() of a required rule should fail")
+	}
+
+	var all *RuleSet
+	if !all.Enabled("SLIM002") {
+		t.Errorf("a nil RuleSet should enable every rule")
+	}
+}
+
+func TestRuleSet_SetSeverity(t *testing.T) {
+	rules := NewRuleSet()
+	for idOrName, severity := range map[string]Severity{"docker-commands": SeverityWarning, "SLIM008": SeverityError, RuleUnknownDuration: SeverityInfo, "services": SeverityOff} {
+		if err := rules.SetSeverity(idOrName, severity); err != nil {
+			t.Fatalf("SetSeverity(%s, %s) error = %v", idOrName, severity, err)
+		}
+	}
+	if got := rules.Severity("SLIM002"); got != SeverityWarning {
+		t.Errorf("Severity(SLIM002) = %s, want warning", got)
+	}
+	if got := rules.Severity(RuleCredentials); got != SeverityError {
+		t.Errorf("Severity(SLIM008) = %s, want error", got)
+	}
+	if got := rules.Severity(RuleMissingCommands); got != SeverityWarning {
+		t.Errorf("Severity(SLIM007) = %s, want its own severity", got)
+	}
+	if got := rules.Severity(RuleUnknownDuration); got != SeverityInfo {
+		t.Errorf("Severity(unknown-duration) = %s, want info", got)
+	}
+	if rules.Enabled("SLIM004") {
+		t.Errorf("SLIM004 should be disabled by the off severity")
+	}
+	if got := rules.WithMode(ModeArm).Severity("SLIM002"); got != SeverityWarning {
+		t.Errorf("WithMode() Severity(SLIM002) = %s, want the severity to be kept", got)
+	}
+
+	if err := rules.SetSeverity("runs-on", SeverityWarning); err == nil {
+		t.Errorf("SetSeverity() of a required rule should fail")
+	}
+	if err := rules.SetSeverity("SLIM999", SeverityInfo); err == nil {
+		t.Errorf("SetSeverity() of an unknown rule should fail")
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("ParseSeverity(fatal) should fail")
+	}
+
+	job := &workflow.Job{RunsOn: "ubuntu-latest", Steps: []workflow.Step{{Run: "docker build -t app ."}}}
+	results := evaluateRules(job, nil, rules)
+	if reasons := failedReasons(results); len(reasons) != 0 {
+		t.Errorf("failed reasons = %v, want none with docker-commands as a warning", reasons)
+	}
+	if evidence := warningEvidence(results, "SLIM002"); len(evidence) != 1 {
+		t.Errorf("docker-commands warning evidence = %v, want the docker build", evidence)
+	}
+}
+
+func TestEvaluateRules_Disabled(t *testing.T) {
+	job := &workflow.Job{
+		RunsOn: "ubuntu-latest",
+		Steps: []workflow.Step{
+			{Run: "docker build -t app ."},
+			{Run: "lsof -i :8080"},
+		},
+	}
+
+	if reasons := failedReasons(evaluateRules(job, nil, nil)); len(reasons) != 1 || reasons[0] != "uses Docker commands" {
+		t.Errorf("failed reasons = %v, want [uses Docker commands]", reasons)
+	}
+
+	rules := NewRuleSet()
+	if err := rules.Disable("SLIM002", "SLIM007"); err != nil {
+		t.Fatal(err)
+	}
+	results := evaluateRules(job, nil, rules)
+	if reasons := failedReasons(results); len(reasons) != 0 {
+		t.Errorf("failed reasons = %v, want none with docker-commands disabled", reasons)
+	}
+	for _, r := range results {
+		if (r.ID == "SLIM002" || r.ID == "SLIM007") && r.Result != ResultSkip {
+			t.Errorf("disabled rule %s result = %q, want %q", r.ID, r.Result, ResultSkip)
+		}
+	}
+	if evidence := ruleEvidence(results, RuleMissingCommands); len(evidence) != 0 {
+		t.Errorf("missing-commands evidence = %v, want none when disabled", evidence)
+	}
+}
+
+func TestRunnerChoices(t *testing.T) {
+	wf := &workflow.Workflow{
+		DispatchInputs: map[string]*workflow.DispatchInput{
+			"runner": {Type: "choice", Default: "ubuntu-latest", Options: []string{"ubuntu-latest", "windows-latest"}},
+		},
+	}
+	job := &workflow.Job{
+		RunsOn: "${{ inputs.runner }}",
+		Steps:  []workflow.Step{{Run: "echo hi"}},
+	}
+
+	choices := RunnerChoices(job, wf, nil)
+	if len(choices) != 2 {
+		t.Fatalf("RunnerChoices() returned %d choices, want 2", len(choices))
+	}
+	if !choices[0].Eligible() || !choices[0].Default || choices[0].Value != "ubuntu-latest" {
+		t.Errorf("choices[0] = %+v, want eligible default ubuntu-latest", choices[0])
+	}
+	if choices[1].Eligible() {
+		t.Errorf("choices[1] = %+v, want not eligible", choices[1])
+	}
+
+	reasons := failedReasons(evaluateRules(job, wf, nil))
+	want := "runs-on depends on input runner: eligible when runner=ubuntu-latest (default)"
+	if len(reasons) != 1 || reasons[0] != want {
+		t.Errorf("failed reasons = %v, want [%s]", reasons, want)
+	}
+
+	// Other failures are evaluated against ubuntu-latest and reported too
+	job.Steps = []workflow.Step{{Run: "docker build ."}}
+	reasons = failedReasons(evaluateRules(job, wf, nil))
+	if len(reasons) != 2 || reasons[0] != "runs-on depends on input runner" || reasons[1] != "uses Docker commands" {
+		t.Errorf("failed reasons = %v, want runs-on and Docker commands", reasons)
+	}
+}
+
+func TestContainerCommandReason(t *testing.T) {
+	job := &workflow.Job{
+		RunsOn: "ubuntu-latest",
+		Steps:  []workflow.Step{{Run: "docker build -t app ."}, {Run: "podman push app"}},
+	}
+	want := "uses container commands (docker, podman)"
+	if reasons := failedReasons(evaluateRules(job, nil, nil)); len(reasons) != 1 || reasons[0] != want {
+		t.Errorf("failed reasons = %v, want [%s]", reasons, want)
+	}
+}
+
+func TestMissingCommandsReason(t *testing.T) {
+	want := "Setup may be required (go via actions/setup-go@v5, lsof)"
+	if got := MissingCommandsReason([]string{"go", "lsof"}); got != want {
+		t.Errorf("MissingCommandsReason() = %q, want %q", got, want)
+	}
+}
+
+func TestCloudCLIRule(t *testing.T) {
+	job := &workflow.Job{RunsOn: "ubuntu-latest", Steps: []workflow.Step{
+		{Run: "lsof -i :8080"},
+		{Run: "kubectl apply -f k8s/"},
+	}}
+	results := map[string]*Finding{}
+	for _, id := range []string{RuleMissingCommands, RuleCloudCLIs} {
+		r, ok := LookupRule(id)
+		if !ok {
+			t.Fatalf("LookupRule(%s) not found", id)
+		}
+		results[id] = r.Check(job, nil)
+	}
+	if f := results[RuleMissingCommands]; f == nil || f.Reason != "Setup may be required (lsof)" {
+		t.Errorf("SLIM007 finding = %+v, want lsof only", f)
+	}
+	want := "Relies on cloud CLIs missing in ubuntu-slim (kubectl via azure/setup-kubectl@v4)"
+	if f := results[RuleCloudCLIs]; f == nil || f.Reason != want {
+		t.Errorf("SLIM011 finding = %+v, want %q", f, want)
+	}
+}
+
+func TestRunnerVariableFinding(t *testing.T) {
+	job := &workflow.Job{
+		RunsOn: "${{ vars.DEFAULT_RUNNER }}",
+		Steps:  []workflow.Step{{Run: "echo hi"}},
+	}
+	wf := &workflow.Workflow{}
+
+	tests := []struct {
+		variables map[string]string
+		want      string
+	}{
+		{nil, "runs-on depends on variable DEFAULT_RUNNER, whose value is unknown (look it up with --resolve-variables)"},
+		{map[string]string{"DEFAULT_RUNNER": "windows-latest"}, "runs-on depends on variable DEFAULT_RUNNER, which is set to windows-latest"},
+		{map[string]string{"DEFAULT_RUNNER": "ubuntu-latest"}, "runs-on depends on variable DEFAULT_RUNNER: eligible while DEFAULT_RUNNER=ubuntu-latest"},
+	}
+	for _, tt := range tests {
+		wf.Variables = tt.variables
+		if reasons := failedReasons(evaluateRules(job, wf, nil)); len(reasons) != 1 || reasons[0] != tt.want {
+			t.Errorf("variables %v: failed reasons = %v, want [%s]", tt.variables, reasons, tt.want)
+		}
+	}
+
+	// Jobs on a variable set to ubuntu-latest are evaluated against it
+	job.Steps = []workflow.Step{{Run: "docker build ."}}
+	reasons := failedReasons(evaluateRules(job, wf, nil))
+	if len(reasons) != 2 || reasons[0] != "runs-on depends on variable DEFAULT_RUNNER" || reasons[1] != "uses Docker commands" {
+		t.Errorf("failed reasons = %v, want runs-on and Docker commands", reasons)
+	}
+}
+
+func TestRunnerScopeReasons(t *testing.T) {
+	tests := []struct {
+		runsOn any
+		want   string
+	}{
+		{[]any{"self-hosted", "linux"}, "runs on self-hosted runners (self-hosted, linux)"},
+		{[]any{"self-hosted", "ubuntu-latest"}, "runs on self-hosted runners (self-hosted, ubuntu-latest)"},
+		{map[string]any{"group": "private"}, "runs in runner group private without the ubuntu-latest label"},
+		{"windows-latest", "does not run on ubuntu-latest"},
+	}
+	for _, tt := range tests {
+		job := &workflow.Job{RunsOn: tt.runsOn, Steps: []workflow.Step{{Run: "echo hi"}}}
+		if reasons := failedReasons(evaluateRules(job, nil, nil)); len(reasons) != 1 || reasons[0] != tt.want {
+			t.Errorf("runs-on %v: failed reasons = %v, want [%s]", tt.runsOn, reasons, tt.want)
+		}
+	}
+
+	job := &workflow.Job{
+		RunsOn: map[string]any{"group": "larger", "labels": []any{"ubuntu-latest"}},
+		Steps:  []workflow.Step{{Run: "echo hi"}},
+	}
+	if reasons := failedReasons(evaluateRules(job, nil, nil)); len(reasons) != 0 {
+		t.Errorf("runner group with ubuntu-latest: failed reasons = %v, want none", reasons)
+	}
+}
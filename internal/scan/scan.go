@@ -3,7 +3,10 @@ package scan
 import (
 	"context"
 	"fmt"
-	"os"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/fchimpan/gh-slimify/internal/api"
@@ -12,45 +15,354 @@ import (
 
 // Candidate represents a job that is eligible for migration
 type Candidate struct {
-	WorkflowPath    string
-	JobID           string // Job ID (the key in the jobs map)
-	JobName         string // Job display name (name: field in YAML, or job ID if not specified)
-	LineNumber      int
-	Duration        string // Will be populated from GitHub API later
-	MissingCommands []string // Commands that exist in ubuntu-latest but need to be installed in ubuntu-slim
+	WorkflowPath    string   `json:"workflow"`
+	JobID           string   `json:"job_id"`   // Job ID (the key in the jobs map)
+	JobName         string   `json:"job_name"` // Job display name (name: field in YAML, or job ID if not specified)
+	LineNumber      int      `json:"line"`
+	Column          int      `json:"column,omitempty"`           // Column of runs-on (or of the job ID if it has none)
+	Duration        string   `json:"duration,omitempty"`         // Will be populated from GitHub API later
+	MissingCommands []string `json:"missing_commands,omitempty"` // Commands that exist in ubuntu-latest but need to be installed in ubuntu-slim
+	// DurationStats summarizes the execution time over several runs when
+	// Options.Runs is greater than one. Duration is then the median.
+	DurationStats *DurationStats `json:"duration_stats,omitempty"`
+	// Usage is the billable usage of the job and its estimated cost, looked
+	// up with Options.Usage
+	Usage *Usage `json:"usage,omitempty"`
+	// Credentials lists why the job handles credentials (secrets, OIDC tokens).
+	// Such jobs are labeled "credentialed" so they can be routed to manual review.
+	Credentials []string `json:"credentials,omitempty"`
+	// CalledBy lists the jobs calling this job's workflow as a reusable workflow.
+	// Migrating the job affects every caller.
+	CalledBy []Caller `json:"called_by,omitempty"`
+	// Downstream lists the IDs of the jobs of the workflow that need this job,
+	// directly or through other jobs. Migrating it changes when they run.
+	Downstream []string `json:"downstream,omitempty"`
+	// Rules holds the result of every rule evaluated against the job
+	Rules []RuleResult `json:"rules"`
+	// Unenriched lists the network lookups left undone because the scan
+	// deadline was exceeded (EnrichmentDuration, EnrichmentActions)
+	Unenriched []string `json:"unenriched,omitempty"`
+	// Ignore is the expired or invalid "# slimify:ignore" directive of the
+	// job, which no longer hides it
+	Ignore *workflow.IgnoreDirective `json:"ignore,omitempty"`
+	// ContainerImages lists the containers the job runs, whose images must
+	// provide arm64 variants (ModeArm only)
+	ContainerImages []string `json:"container_images,omitempty"`
+	// ToolchainVersions lists the preinstalled toolchains the job relies on
+	// whose version differs on ubuntu-slim (e.g., node)
+	ToolchainVersions []string `json:"toolchain_versions,omitempty"`
+	// CloudCLIs lists the cloud CLIs among MissingCommands (e.g., aws,
+	// kubectl), the most common breakage after a migration
+	CloudCLIs []string `json:"cloud_clis,omitempty"`
+	// LongRunning reports whether the job's execution time exceeds
+	// Options.MaxDuration: long CPU-bound jobs may not benefit from the
+	// smaller ubuntu-slim runner
+	LongRunning bool `json:"long_running,omitempty"`
+	// Footprint lists what makes the job likely to exceed the smaller disk or
+	// memory of ubuntu-slim (e.g., lfs, gradlew, -Xmx6g)
+	Footprint []string `json:"footprint,omitempty"`
+	// ManualServices lists the daemons the job starts in run: steps instead
+	// of services: (e.g., redis-server, postgresql)
+	ManualServices []string `json:"manual_services,omitempty"`
+}
+
+// Network lookups that enrich candidates, reported in Candidate.Unenriched
+const (
+	EnrichmentDuration = "duration"
+	EnrichmentActions  = "remote actions"
+)
+
+// IsCredentialed reports whether the candidate handles secrets or OIDC tokens
+func (c *Candidate) IsCredentialed() bool {
+	return len(c.Credentials) > 0
+}
+
+// HasWarnings reports whether the candidate requires attention before migrating:
+// it uses commands missing in ubuntu-slim or preinstalled toolchains whose
+// version differs there, its execution time is unknown or exceeds
+// Options.MaxDuration, it is likely to exceed the disk or memory of
+// ubuntu-slim, it starts services manually, it was not fully analyzed before
+// the scan deadline, or its ignore directive expired. In ModeArm, the
+// containers it runs are warnings as well, and so is any rule whose severity
+// was set to warning.
+func (c *Candidate) HasWarnings() bool {
+	return len(c.MissingCommands) > 0 || len(c.ToolchainVersions) > 0 || c.HasUnknownDuration() || c.LongRunning || len(c.Footprint) > 0 || len(c.ManualServices) > 0 || len(c.Unenriched) > 0 || c.Ignore != nil || len(c.ContainerImages) > 0 || c.hasResult(ResultWarn)
+}
+
+// HasUnknownDuration reports whether the execution time of the candidate is
+// unknown, including when it was not looked up, and requires attention: unless
+// the severity of RuleUnknownDuration was set to info or off
+func (c *Candidate) HasUnknownDuration() bool {
+	if c.Duration != "" && c.Duration != "unknown" {
+		return false
+	}
+	for _, r := range c.Rules {
+		if r.Rule == "duration" {
+			return r.Result == ResultWarn || (r.Result == ResultSkip && r.Reason != "disabled")
+		}
+	}
+	return true
+}
+
+// hasResult reports whether a rule evaluated against the candidate had the
+// given result
+func (c *Candidate) hasResult(result string) bool {
+	for _, r := range c.Rules {
+		if r.Result == result {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnenriched reports whether the given lookup was left undone because the
+// scan deadline was exceeded
+func (c *Candidate) IsUnenriched(lookup string) bool {
+	for _, l := range c.Unenriched {
+		if l == lookup {
+			return true
+		}
+	}
+	return false
 }
 
 // IneligibleJob represents a job that is not eligible for migration
 type IneligibleJob struct {
-	WorkflowPath string
-	JobID        string // Job ID (the key in the jobs map)
-	JobName      string // Job display name (name: field in YAML, or job ID if not specified)
-	LineNumber   int
-	Reasons      []string // Reasons why the job cannot be migrated
+	WorkflowPath string   `json:"workflow"`
+	JobID        string   `json:"job_id"`   // Job ID (the key in the jobs map)
+	JobName      string   `json:"job_name"` // Job display name (name: field in YAML, or job ID if not specified)
+	LineNumber   int      `json:"line"`
+	Column       int      `json:"column,omitempty"` // Column of runs-on (or of the job ID if it has none)
+	Reasons      []string `json:"reasons"`          // Reasons why the job cannot be migrated
+	// ReasonRuleIDs holds the ID of the rule that produced each reason, or ""
+	// if the reason does not come from a rule (e.g., calls to remote reusable
+	// workflows)
+	ReasonRuleIDs []string `json:"reason_rule_ids,omitempty"`
+	// ServiceSuggestions lists docker-free alternatives for each service container
+	// when the job is ineligible because of services:
+	ServiceSuggestions []ServiceSuggestion `json:"service_suggestions,omitempty"`
+	CalledBy           []Caller            `json:"called_by,omitempty"` // Jobs calling this job's workflow as a reusable workflow
+	// Rules holds the result of every rule evaluated against the job
+	Rules []RuleResult `json:"rules"`
+}
+
+// ReasonRuleID returns the ID of the rule that produced the i-th reason, or ""
+// if the reason does not come from a rule
+func (j *IneligibleJob) ReasonRuleID(i int) string {
+	return reasonRuleID(j.ReasonRuleIDs, i)
+}
+
+// reasonRuleID returns the i-th rule ID of ids, or "" if there is none
+func reasonRuleID(ids []string, i int) string {
+	if i < len(ids) {
+		return ids[i]
+	}
+	return ""
+}
+
+// OutOfScope reports whether the job is ineligible only because it does not
+// run on ubuntu-latest (e.g., it runs on macOS or Windows). Jobs already on
+// ubuntu-slim are not ineligible: they are listed in ScanResult.Migrated.
+func (j *IneligibleJob) OutOfScope() bool {
+	for i := range j.Reasons {
+		if j.ReasonRuleID(i) != "SLIM001" {
+			return false
+		}
+	}
+	return len(j.Reasons) > 0
 }
 
 // ScanResult contains both eligible candidates and ineligible jobs
 type ScanResult struct {
-	Candidates     []*Candidate
-	IneligibleJobs []*IneligibleJob
+	Candidates     []*Candidate     `json:"candidates"`
+	IneligibleJobs []*IneligibleJob `json:"ineligible_jobs"`
+	// DeadlineExceeded is true if network lookups were cut short by Options.Deadline
+	DeadlineExceeded bool `json:"deadline_exceeded,omitempty"`
+	// RateLimited is true if some durations are unknown because the GitHub API
+	// rate limit was exhausted
+	RateLimited bool `json:"rate_limited,omitempty"`
+	// Canaries lists the jobs already testing ubuntu-slim next to their
+	// ubuntu-latest twin. They are not reported as ineligible.
+	Canaries []*Canary `json:"canaries,omitempty"`
+	// Migrated lists the jobs already running on ubuntu-slim, other than
+	// canaries. They are not reported as ineligible.
+	Migrated []*MigratedJob `json:"migrated,omitempty"`
+	// LoadErrors lists the workflow files given to the scan that could not be
+	// loaded. The other files are scanned.
+	LoadErrors []LoadError `json:"load_errors,omitempty"`
+	// Ignored lists the jobs excluded by an active "# slimify:ignore" directive.
+	// They are not evaluated.
+	Ignored []*IgnoredJob `json:"ignored,omitempty"`
+	// LabelTypos lists the runs-on labels of every job, including ignored
+	// jobs and canaries, that look like typos of known runner labels
+	LabelTypos []*LabelTypo `json:"label_typos,omitempty"`
+	// Dependencies lists the needs: edges between the jobs of the scanned
+	// workflows, including ignored jobs and canaries
+	Dependencies []*JobDependency `json:"dependencies,omitempty"`
+	// Readiness is the slim readiness score of the scanned workflows, see
+	// NewReadinessReport
+	Readiness *ReadinessReport `json:"readiness,omitempty"`
+}
+
+// resultSeverities maps rule results to the severity of the finding
+var resultSeverities = map[string]Severity{
+	ResultFail: SeverityError,
+	ResultWarn: SeverityWarning,
+	ResultInfo: SeverityInfo,
+}
+
+// Findings returns the number of rule findings with at least the severity min
+// among the candidates and ineligible jobs. Jobs not running on ubuntu-latest
+// are out of scope: their runs-on findings (SLIM001) are not counted.
+func (r *ScanResult) Findings(min Severity) int {
+	var results []RuleResult
+	for _, c := range r.Candidates {
+		results = append(results, c.Rules...)
+	}
+	for _, j := range r.IneligibleJobs {
+		results = append(results, j.Rules...)
+	}
+	n := 0
+	for _, result := range results {
+		if result.ID != "SLIM001" && resultSeverities[result.Result].AtLeast(min) {
+			n++
+		}
+	}
+	return n
+}
+
+// JobDependency is a needs: edge between two jobs of a workflow: JobID starts
+// once Needs completes
+type JobDependency struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	Needs        string `json:"needs"`
+}
+
+// LabelTypo is a runs-on label of a job that looks like a typo of a known
+// runner label. Such jobs wait for a runner forever.
+type LabelTypo struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	workflow.LabelTypo
+}
+
+// IgnoredJob is a job excluded from the scan by a "# slimify:ignore" directive
+type IgnoredJob struct {
+	WorkflowPath string                    `json:"workflow"`
+	JobID        string                    `json:"job_id"`
+	JobName      string                    `json:"job_name"`
+	LineNumber   int                       `json:"line"`
+	Directive    *workflow.IgnoreDirective `json:"directive"`
+}
+
+// LoadError is a workflow file that could not be loaded (missing or unparsable)
+type LoadError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Options configures a scan
+type Options struct {
+	// SkipDuration skips fetching job execution durations from GitHub API.
+	SkipDuration bool
+	// RetryUnknown reuses durations cached by previous scans and only re-attempts
+	// lookups that previously resolved to unknown (or were never attempted).
+	RetryUnknown bool
+	// ResolveActions looks up action.yml of third-party actions via GitHub API
+	// and marks jobs using Docker-based actions as ineligible.
+	ResolveActions bool
+	// ResolveVariables looks up the repository and organization variables
+	// runs-on is set to (runs-on: ${{ vars.DEFAULT_RUNNER }}) via GitHub API, so
+	// that jobs on a variable set to ubuntu-latest are evaluated.
+	ResolveVariables bool
+	// Rules selects the enabled rules. If nil, every rule is enabled.
+	Rules *RuleSet
+	// MaxWorkers is the number of durations looked up concurrently. If zero,
+	// DefaultMaxWorkers is used.
+	MaxWorkers int
+	// Runs is the number of latest successful runs whose durations are looked
+	// up for each job. When greater than one, Candidate.DurationStats is set.
+	// If zero, only the latest successful run is used.
+	Runs int
+	// RunFilter selects the workflow runs durations are looked up in (e.g.,
+	// only runs on the default branch). The zero value selects every run.
+	RunFilter api.RunFilter
+	// Durations looks up job durations. If nil, they are looked up with the
+	// GitHub API for the repository of the origin remote (or GH_REPO), and
+	// cached on disk. Durations of other providers are not cached, and
+	// RunFilter does not apply to them.
+	Durations DurationProvider
+	// Usage looks up the billable minutes of each candidate over the last
+	// UsageWindow and estimates its monthly cost with Pricing.
+	Usage bool
+	// Pricing is the price per minute of the runners used to estimate costs
+	Pricing Pricing
+	// Deadline bounds the time a scan spends, measured from its start. When it
+	// is exceeded, network lookups stop and the candidates they did not reach
+	// are marked in Candidate.Unenriched. Zero means no limit.
+	Deadline time.Duration
+	// FormerJobNames maps jobs, as "<workflow-path>:<job-id>", to display names
+	// they had before being renamed. Durations are looked up under these names
+	// in runs where the job is not found under its current name.
+	FormerJobNames map[string][]string
+	// ExcludeFiles are glob patterns of workflow files left out of the scan
+	// (e.g., ".github/workflows/release-*.yml"). Patterns without a slash
+	// match the file name.
+	ExcludeFiles []string
+	// ExcludeJobs are jobs, as "<workflow>:<job-id>", reported as ignored
+	// instead of being evaluated. The workflow may be a glob pattern like
+	// those of ExcludeFiles (e.g., "deploy.yml:production").
+	ExcludeJobs []string
+	// MaxDuration demotes the candidates whose execution time exceeds it to
+	// requiring attention (Candidate.LongRunning). Zero means no limit.
+	MaxDuration time.Duration
+	// CheckMigrated evaluates the rules against the jobs already running on
+	// ubuntu-slim, to report those that no longer meet the migration criteria
+	// (MigratedJob.Reasons)
+	CheckMigrated bool
 }
 
 // Scan scans workflows and returns migration candidates and ineligible jobs
 // If paths are provided, only those files are scanned. Otherwise, all workflow files
-// in .github/workflows are scanned.
+// in the workflows directory (see workflow.SetDir) are scanned.
 // skipDuration, if true, skips fetching job execution durations from GitHub API.
-// verbose, if true, enables verbose output including debug warnings.
-func Scan(skipDuration bool, verbose bool, paths ...string) (*ScanResult, error) {
+// Diagnostics are logged with log/slog; debug messages explain why lookups failed.
+func Scan(skipDuration bool, paths ...string) (*ScanResult, error) {
+	return ScanWithOptions(Options{SkipDuration: skipDuration}, paths...)
+}
+
+// ScanWithOptions scans workflows like Scan, configured by opts
+func ScanWithOptions(opts Options, paths ...string) (*ScanResult, error) {
+	excluded, err := newExclusions(opts.ExcludeFiles, opts.ExcludeJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
 	var workflows []*workflow.Workflow
-	var err error
+	var loadErrors []LoadError
 
 	if len(paths) > 0 {
-		// Load only specified files
+		// Load only specified files, collecting every file that fails to load
+		// instead of stopping at the first one
 		workflows = make([]*workflow.Workflow, 0, len(paths))
 		for _, path := range paths {
+			if excluded.file(path) {
+				continue
+			}
 			wf, err := workflow.LoadWorkflow(path)
 			if err != nil {
-				return nil, fmt.Errorf("failed to load workflow %s: %w", path, err)
+				loadErrors = append(loadErrors, LoadError{Path: path, Error: err.Error()})
+				continue
 			}
 			workflows = append(workflows, wf)
 		}
@@ -62,7 +374,7 @@ func Scan(skipDuration bool, verbose bool, paths ...string) (*ScanResult, error)
 		}
 
 		if len(workflows) == 0 {
-			fmt.Fprintf(os.Stderr, "No workflow files found in .github/workflows\n")
+			slog.Warn("no workflow files found", "dir", workflow.Dir())
 			return &ScanResult{
 				Candidates:     []*Candidate{},
 				IneligibleJobs: []*IneligibleJob{},
@@ -70,50 +382,278 @@ func Scan(skipDuration bool, verbose bool, paths ...string) (*ScanResult, error)
 		}
 	}
 
+	// Follow local reusable workflow calls so called jobs are analyzed and
+	// attributed to their callers
+	workflows, callers, reusableLoadErrors := resolveReusableWorkflows(workflows)
+
+	if opts.ResolveVariables {
+		if err := resolveRunnerVariables(ctx, workflows); err != nil {
+			// Log error but don't fail the scan
+			slog.Warn("failed to resolve runner variables", "error", err)
+		}
+	}
+
 	var candidates []*Candidate
 	var ineligibleJobs []*IneligibleJob
+	var canaries []*Canary
+	var migrated []*MigratedJob
+	var ignored []*IgnoredJob
+	var labelTypos []*LabelTypo
+	var dependencies []*JobDependency
+	candidateJobs := make(map[*Candidate]*workflow.Job)
+	now := time.Now()
 
 	for _, wf := range workflows {
-		for jobID, job := range wf.Jobs {
-			// Check migration criteria
-			isEligible, reasons := checkEligibility(job)
-			if isEligible {
-				// Check for missing commands and include in candidate
-				missingCommands := job.GetMissingCommands()
-				candidates = append(candidates, &Candidate{
-					WorkflowPath:    wf.Path,
-					JobID:           jobID,
-					JobName:         job.Name,
-					LineNumber:      job.LineStart,
-					MissingCommands: missingCommands,
+		if excluded.file(wf.Path) {
+			continue
+		}
+		calledBy := callers[filepath.Clean(wf.Path)]
+		canaryIDs := make(map[string]bool)
+		for _, c := range FindCanaries(wf) {
+			canaries = append(canaries, c)
+			canaryIDs[c.JobID] = true
+		}
+		for _, jobID := range wf.JobIDs() {
+			job := wf.Jobs[jobID]
+			for _, dep := range job.Dependencies() {
+				if wf.Jobs[dep] != nil {
+					dependencies = append(dependencies, &JobDependency{WorkflowPath: wf.Path, JobID: jobID, Needs: dep})
+				}
+			}
+			for _, typo := range job.RunnerLabelTypos() {
+				labelTypos = append(labelTypos, &LabelTypo{
+					WorkflowPath: wf.Path,
+					JobID:        jobID,
+					JobName:      job.Name,
+					LineNumber:   job.LineStart,
+					LabelTypo:    typo,
 				})
-			} else {
-				// Record ineligible job with reasons
-				ineligibleJobs = append(ineligibleJobs, &IneligibleJob{
+			}
+			if canaryIDs[jobID] {
+				continue
+			}
+			if job.Ignore != nil && job.Ignore.Active(now) {
+				ignored = append(ignored, &IgnoredJob{
 					WorkflowPath: wf.Path,
 					JobID:        jobID,
 					JobName:      job.Name,
 					LineNumber:   job.LineStart,
-					Reasons:      reasons,
+					Directive:    job.Ignore,
 				})
+				continue
+			}
+			if excluded.job(wf.Path, jobID) {
+				ignored = append(ignored, &IgnoredJob{
+					WorkflowPath: wf.Path,
+					JobID:        jobID,
+					JobName:      job.Name,
+					LineNumber:   job.LineStart,
+					Directive:    &workflow.IgnoreDirective{Line: job.LineStart, Reason: "excluded from the scan"},
+				})
+				continue
+			}
+			if isMigrated(job) {
+				m := &MigratedJob{
+					WorkflowPath: wf.Path,
+					JobID:        jobID,
+					JobName:      job.Name,
+					LineNumber:   job.LineStart,
+					CalledBy:     calledBy,
+				}
+				if opts.CheckMigrated {
+					checkMigrated(m, job, wf, opts.Rules)
+				}
+				migrated = append(migrated, m)
+				continue
+			}
+			// Jobs calling a local reusable workflow have no runs-on; the called
+			// workflow's jobs are reported instead, attributed to this caller
+			if job.IsReusableWorkflowCall() {
+				if reasons := reusableCallReasons(job, reusableLoadErrors); len(reasons) > 0 {
+					ineligibleJobs = append(ineligibleJobs, &IneligibleJob{
+						WorkflowPath: wf.Path,
+						JobID:        jobID,
+						JobName:      job.Name,
+						LineNumber:   job.LineStart,
+						Column:       job.Start().Column,
+						Reasons:      reasons,
+						Rules:        []RuleResult{{Rule: "reusable-workflow", Result: ResultFail, Reason: reasons[0], Evidence: []workflow.Evidence{{Line: job.Uses, Pattern: "uses"}}}},
+					})
+				}
+				continue
+			}
+
+			// Check migration criteria
+			rules := evaluateRules(job, wf, opts.Rules)
+			reasons := failedReasons(rules)
+			if len(reasons) == 0 {
+				candidate := &Candidate{
+					WorkflowPath: wf.Path,
+					JobID:        jobID,
+					JobName:      job.Name,
+					LineNumber:   job.LineStart,
+					Column:       job.Start().Column,
+					CalledBy:     calledBy,
+					Downstream:   wf.Dependents(jobID),
+					Rules:        rules,
+					Ignore:       job.Ignore, // Expired or invalid, since active directives skip the job
+				}
+				// Missing commands and credentials are evidenced by their rules.
+				// Cloud CLIs are missing commands reported by a rule of their own.
+				// Warnings are only recorded while their rules warn: rules set to
+				// the info severity merely label the job.
+				for _, e := range warningEvidence(rules, RuleCloudCLIs) {
+					candidate.CloudCLIs = append(candidate.CloudCLIs, e.Pattern)
+					candidate.MissingCommands = append(candidate.MissingCommands, e.Pattern)
+				}
+				for _, e := range warningEvidence(rules, RuleMissingCommands) {
+					candidate.MissingCommands = append(candidate.MissingCommands, e.Pattern)
+				}
+				for _, e := range ruleEvidence(rules, RuleCredentials) {
+					candidate.Credentials = append(candidate.Credentials, e.Line)
+				}
+				for _, e := range warningEvidence(rules, RuleContainerImages) {
+					candidate.ContainerImages = append(candidate.ContainerImages, e.Line)
+				}
+				for _, e := range warningEvidence(rules, RuleToolchainVersions) {
+					candidate.ToolchainVersions = append(candidate.ToolchainVersions, e.Pattern)
+				}
+				for _, e := range warningEvidence(rules, RuleManualServices) {
+					if !slices.Contains(candidate.ManualServices, e.Pattern) {
+						candidate.ManualServices = append(candidate.ManualServices, e.Pattern)
+					}
+				}
+				for _, e := range append(warningEvidence(rules, RuleDiskFootprint), warningEvidence(rules, RuleMemoryFootprint)...) {
+					if !slices.Contains(candidate.Footprint, e.Pattern) {
+						candidate.Footprint = append(candidate.Footprint, e.Pattern)
+					}
+				}
+				candidates = append(candidates, candidate)
+				candidateJobs[candidate] = job
+			} else {
+				// Record ineligible job with reasons
+				ineligible := &IneligibleJob{
+					WorkflowPath:  wf.Path,
+					JobID:         jobID,
+					JobName:       job.Name,
+					LineNumber:    job.LineStart,
+					Column:        job.Start().Column,
+					Reasons:       reasons,
+					ReasonRuleIDs: failedRuleIDs(rules),
+					CalledBy:      calledBy,
+					Rules:         rules,
+				}
+				if job.IsUbuntuLatest() && job.HasServices() && opts.Rules.Mode() == ModeSlim {
+					ineligible.ServiceSuggestions = suggestServiceReplacements(job)
+				}
+				ineligibleJobs = append(ineligibleJobs, ineligible)
 			}
 		}
 	}
 
+	// Inspect third-party actions, which may run in a container even though
+	// their names do not reveal it
+	if opts.ResolveActions && opts.Rules.Enabled(RuleDockerActions) {
+		candidates, ineligibleJobs, err = excludeDockerActionJobs(ctx, candidates, ineligibleJobs, candidateJobs, opts.Rules)
+		if err != nil {
+			// Log error but don't fail the scan
+			slog.Warn("failed to resolve remote actions", "error", err)
+		}
+	}
+
 	// Fetch duration from GitHub API for each candidate (unless skipped)
-	if !skipDuration {
-		if err := fetchDurations(candidates, verbose); err != nil {
+	rateLimited := false
+	if !opts.SkipDuration {
+		// Durations of migrated jobs weigh them in the readiness score, at the
+		// cost of one more lookup per migrated job
+		migratedLookups := migratedDurationLookups(migrated)
+		lookups := append(append([]*Candidate{}, candidates...), migratedLookups...)
+		if rateLimited, err = fetchDurations(ctx, lookups, opts); err != nil {
 			// Log error but don't fail the scan
-			if verbose {
-				fmt.Fprintf(os.Stderr, "Warning: failed to fetch job durations from GitHub API: %v\n", err)
-			}
+			slog.Debug("failed to fetch job durations from GitHub API", "error", err)
+		}
+		for i, m := range migrated {
+			m.Duration = migratedLookups[i].Duration
+		}
+	}
+	if opts.Usage {
+		limited, err := fetchUsage(ctx, candidates, opts)
+		if err != nil {
+			// Log error but don't fail the scan
+			slog.Warn("failed to fetch billable minutes from GitHub API", "error", err)
+		}
+		rateLimited = rateLimited || limited
+	}
+	eligible := candidates[:0]
+	for _, candidate := range candidates {
+		candidate.LongRunning = isLongRunning(candidate, opts)
+		candidate.Rules = append(candidate.Rules, durationRule(candidate, opts))
+		if candidate.Ignore != nil {
+			candidate.Rules = append(candidate.Rules, ignoreRule(candidate.Ignore, false))
+		}
+		// Unknown execution times make jobs ineligible when set to the error
+		// severity
+		if reasons := failedReasons(candidate.Rules); len(reasons) > 0 {
+			ineligibleJobs = append(ineligibleJobs, &IneligibleJob{
+				WorkflowPath:  candidate.WorkflowPath,
+				JobID:         candidate.JobID,
+				JobName:       candidate.JobName,
+				LineNumber:    candidate.LineNumber,
+				Column:        candidate.Column,
+				Reasons:       reasons,
+				ReasonRuleIDs: failedRuleIDs(candidate.Rules),
+				CalledBy:      candidate.CalledBy,
+				Rules:         candidate.Rules,
+			})
+			continue
 		}
+		eligible = append(eligible, candidate)
 	}
+	candidates = eligible
+
+	// Report jobs by file path, then line number, whatever the order the
+	// workflows were loaded in
+	sort.Slice(candidates, func(i, j int) bool {
+		return locationLess(candidates[i].WorkflowPath, candidates[i].LineNumber, candidates[j].WorkflowPath, candidates[j].LineNumber)
+	})
+	sort.Slice(ineligibleJobs, func(i, j int) bool {
+		return locationLess(ineligibleJobs[i].WorkflowPath, ineligibleJobs[i].LineNumber, ineligibleJobs[j].WorkflowPath, ineligibleJobs[j].LineNumber)
+	})
+	sort.Slice(canaries, func(i, j int) bool {
+		return locationLess(canaries[i].WorkflowPath, canaries[i].LineNumber, canaries[j].WorkflowPath, canaries[j].LineNumber)
+	})
+	sort.Slice(migrated, func(i, j int) bool {
+		return locationLess(migrated[i].WorkflowPath, migrated[i].LineNumber, migrated[j].WorkflowPath, migrated[j].LineNumber)
+	})
+	sort.Slice(ignored, func(i, j int) bool {
+		return locationLess(ignored[i].WorkflowPath, ignored[i].LineNumber, ignored[j].WorkflowPath, ignored[j].LineNumber)
+	})
+	sort.Slice(labelTypos, func(i, j int) bool {
+		return locationLess(labelTypos[i].WorkflowPath, labelTypos[i].LineNumber, labelTypos[j].WorkflowPath, labelTypos[j].LineNumber)
+	})
 
-	return &ScanResult{
-		Candidates:     candidates,
-		IneligibleJobs: ineligibleJobs,
-	}, nil
+	result := &ScanResult{
+		Candidates:       candidates,
+		IneligibleJobs:   ineligibleJobs,
+		DeadlineExceeded: ctx.Err() != nil,
+		Canaries:         canaries,
+		Migrated:         migrated,
+		LoadErrors:       loadErrors,
+		Ignored:          ignored,
+		LabelTypos:       labelTypos,
+		Dependencies:     dependencies,
+		RateLimited:      rateLimited,
+	}
+	result.Readiness = NewReadinessReport(result)
+	return result, nil
+}
+
+// locationLess orders jobs by workflow path, then line number
+func locationLess(pathA string, lineA int, pathB string, lineB int) bool {
+	if pathA != pathB {
+		return pathA < pathB
+	}
+	return lineA < lineB
 }
 
 // checkEligibility checks if a job meets all migration criteria and returns
@@ -127,37 +667,10 @@ func Scan(skipDuration bool, verbose bool, paths ...string) (*ScanResult, error)
 // 6. Duration check will be added later via GitHub API
 // Returns (isEligible, reasons) where reasons is empty if eligible.
 func checkEligibility(job *workflow.Job) (bool, []string) {
-	var reasons []string
-
-	// Criterion 1: Must run on ubuntu-latest
-	if !job.IsUbuntuLatest() {
-		reasons = append(reasons, "does not run on ubuntu-latest")
-		return false, reasons
-	}
-
-	// Criterion 2: Must not use Docker commands
-	if job.HasDockerCommands() {
-		reasons = append(reasons, "uses Docker commands")
-	}
-
-	// Criterion 3: Must not use container-based GitHub Actions
-	if job.HasContainerActions() {
-		reasons = append(reasons, "uses container-based GitHub Actions")
-	}
-
-	// Criterion 4: Must not use services
-	if job.HasServices() {
-		reasons = append(reasons, "uses service containers")
-	}
-
-	// Criterion 5: Must not use container: syntax
-	if job.HasContainer() {
-		reasons = append(reasons, "uses container syntax")
-	}
-
+	// Criteria 1-5 are rules in the registry (see rules.go).
 	// Criterion 6: Duration check will be done via GitHub API
 	// Duration is fetched after eligibility check to avoid blocking on API calls
-
+	reasons := failedReasons(evaluateRules(job, nil, nil))
 	if len(reasons) > 0 {
 		return false, reasons
 	}
@@ -171,47 +684,19 @@ func isEligible(job *workflow.Job) bool {
 	return isEligible
 }
 
-// fetchDurations fetches job execution durations from GitHub API
-// verbose, if true, enables verbose output including debug warnings.
-func fetchDurations(candidates []*Candidate, verbose bool) error {
-	if len(candidates) == 0 {
-		return nil
-	}
-
-	// Get repository info from git remote
-	host, owner, repo, err := api.GetRepoInfo()
-	if err != nil {
-		return fmt.Errorf("failed to get repository info: %w", err)
-	}
-
-	// Create API client
-	client, err := api.NewClient(host, owner, repo)
-	if err != nil {
-		return fmt.Errorf("failed to create API client: %w", err)
-	}
-
-	ctx := context.Background()
-
-	// Fetch duration for each candidate
-	for _, candidate := range candidates {
-		duration, err := client.GetJobDuration(ctx, candidate.WorkflowPath, candidate.JobID, candidate.JobName)
-		if err != nil {
-			// Log error for debugging but continue to next candidate
-			if verbose {
-				fmt.Fprintf(os.Stderr, "Warning: failed to get duration for job %s (ID: %s) in %s: %v\n", candidate.JobName, candidate.JobID, candidate.WorkflowPath, err)
-			}
-			continue
-		}
-
-		// Format duration as human-readable string
-		candidate.Duration = formatDuration(duration.Duration)
+// isLongRunning reports whether the execution time of a candidate exceeds
+// Options.MaxDuration
+func isLongRunning(c *Candidate, opts Options) bool {
+	if opts.MaxDuration <= 0 {
+		return false
 	}
-
-	return nil
+	// Durations are formatted like 4m12s, which parses back
+	d, err := time.ParseDuration(c.Duration)
+	return err == nil && d > opts.MaxDuration
 }
 
-// formatDuration formats a duration as a human-readable string
-func formatDuration(d time.Duration) string {
+// FormatDuration formats a duration as a human-readable string (e.g., "4m12s")
+func FormatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%.0fs", d.Seconds())
 	}
@@ -1,10 +1,16 @@
 package scan
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fchimpan/gh-slimify/internal/api"
 	"github.com/fchimpan/gh-slimify/internal/workflow"
 )
 
@@ -417,7 +423,7 @@ on: push`,
 			}
 
 			// Run Scan (skip duration for tests to avoid API calls)
-			result, err := Scan(true, false)
+			result, err := Scan(true)
 
 			if tt.expectError && err == nil {
 				t.Errorf("Scan() expected error but got none")
@@ -477,7 +483,7 @@ func TestScan_NoWorkflowDirectory(t *testing.T) {
 		os.Chdir(originalWd)
 	}()
 
-	result, err := Scan(true, false)
+	result, err := Scan(true)
 	if err == nil {
 		t.Error("Scan() expected error when workflow directory doesn't exist")
 	}
@@ -485,3 +491,575 @@ func TestScan_NoWorkflowDirectory(t *testing.T) {
 		t.Errorf("Scan() expected nil result, got %v", result)
 	}
 }
+
+func TestScan_LoadErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	valid := filepath.Join(tmpDir, "ci.yml")
+	if err := os.WriteFile(valid, []byte("on: push\njobs:\n  lint:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make lint\n"), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+	invalid := filepath.Join(tmpDir, "broken.yml")
+	if err := os.WriteFile(invalid, []byte("jobs: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+	missing := filepath.Join(tmpDir, "missing.yml")
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, missing, valid, invalid)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() error = %v, want load errors to be collected", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].JobID != "lint" {
+		t.Errorf("ScanWithOptions() candidates = %v, want the lint job of the valid file", result.Candidates)
+	}
+	if len(result.LoadErrors) != 2 {
+		t.Fatalf("ScanWithOptions() returned %d load errors, want 2", len(result.LoadErrors))
+	}
+	if result.LoadErrors[0].Path != missing || result.LoadErrors[1].Path != invalid {
+		t.Errorf("ScanWithOptions() load errors = %v, want %s and %s in order", result.LoadErrors, missing, invalid)
+	}
+}
+
+func TestScan_IgnoreDirectives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  # slimify:ignore until=2999-01-01 reason=flaky
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test: # slimify:ignore until=2000-01-01
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	if len(result.Ignored) != 1 || result.Ignored[0].JobID != "lint" || result.Ignored[0].Directive.Reason != "flaky" {
+		t.Errorf("ScanWithOptions() ignored = %+v, want the lint job", result.Ignored)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].JobID != "test" {
+		t.Fatalf("ScanWithOptions() candidates = %+v, want the test job whose directive expired", result.Candidates)
+	}
+	test := result.Candidates[0]
+	if test.Ignore == nil || !test.HasWarnings() {
+		t.Errorf("expired directive: Ignore = %+v, HasWarnings() = %v, want a warning", test.Ignore, test.HasWarnings())
+	}
+	if last := test.Rules[len(test.Rules)-1]; last.Rule != "ignore" || last.Result != ResultWarn {
+		t.Errorf("last rule = %+v, want an ignore warning", last)
+	}
+}
+
+func TestScan_LabelTypos(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-lastest
+    steps:
+      - run: make lint
+  test:
+    runs-on: [self_hosted, linux]
+    steps:
+      - run: make test
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	if len(result.LabelTypos) != 2 {
+		t.Fatalf("ScanWithOptions() label typos = %+v, want 2", result.LabelTypos)
+	}
+	if typo := result.LabelTypos[0]; typo.JobID != "lint" || typo.Suggestion != "ubuntu-latest" {
+		t.Errorf("first label typo = %+v, want lint with suggestion ubuntu-latest", typo)
+	}
+	if typo := result.LabelTypos[1]; typo.JobID != "test" || typo.Suggestion != "self-hosted" {
+		t.Errorf("second label typo = %+v, want test with suggestion self-hosted", typo)
+	}
+	for _, job := range result.IneligibleJobs {
+		if job.JobID == "lint" && job.Reasons[0] != `runs-on "ubuntu-lastest" looks like a typo of "ubuntu-latest"` {
+			t.Errorf("lint reasons = %v, want the typo", job.Reasons)
+		}
+	}
+}
+
+func TestScan_SortedByLocation(t *testing.T) {
+	dir := t.TempDir()
+	content := `on: push
+jobs:
+  zeta:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make zeta
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+  alpha:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make alpha
+  mid:
+    runs-on: ubuntu-latest
+    services:
+      db:
+        image: postgres
+    steps:
+      - run: make mid
+`
+	var paths []string
+	for _, name := range []string{"b.yml", "a.yml"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write workflow: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	// Map iteration must not leak into the order, so scan a few times
+	for i := 0; i < 5; i++ {
+		result, err := ScanWithOptions(Options{SkipDuration: true}, paths...)
+		if err != nil {
+			t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+		}
+		var candidates, ineligible []string
+		for _, c := range result.Candidates {
+			candidates = append(candidates, filepath.Base(c.WorkflowPath)+":"+c.JobID)
+		}
+		for _, j := range result.IneligibleJobs {
+			ineligible = append(ineligible, filepath.Base(j.WorkflowPath)+":"+j.JobID)
+		}
+		if got, want := strings.Join(candidates, ","), "a.yml:zeta,a.yml:alpha,b.yml:zeta,b.yml:alpha"; got != want {
+			t.Fatalf("candidates = %s, want %s", got, want)
+		}
+		if got, want := strings.Join(ineligible, ","), "a.yml:image,a.yml:mid,b.yml:image,b.yml:mid"; got != want {
+			t.Fatalf("ineligible jobs = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestIneligibleJob_OutOfScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  mac:
+    runs-on: macos-latest
+    steps:
+      - run: make test
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	for _, job := range result.IneligibleJobs {
+		if got, want := job.OutOfScope(), job.JobID == "mac"; got != want {
+			t.Errorf("%s OutOfScope() = %v, want %v", job.JobID, got, want)
+		}
+		// Reasons are attributed to the rule that recorded them
+		want := map[string]string{"mac": "SLIM001", "image": "SLIM002"}[job.JobID]
+		if len(job.Reasons) != 1 || job.ReasonRuleID(0) != want {
+			t.Errorf("%s reasons = %v (rules %v), want one from %s", job.JobID, job.Reasons, job.ReasonRuleIDs, want)
+		}
+	}
+	if len(result.IneligibleJobs) != 2 {
+		t.Errorf("ineligible jobs = %d, want 2", len(result.IneligibleJobs))
+	}
+}
+
+func TestCandidate_HasWarnings(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate *Candidate
+		expected  bool
+	}{
+		{
+			name:      "known duration",
+			candidate: &Candidate{Duration: "4m"},
+			expected:  false,
+		},
+		{
+			name:      "unknown duration",
+			candidate: &Candidate{Duration: "unknown"},
+			expected:  true,
+		},
+		{
+			name:      "missing commands",
+			candidate: &Candidate{Duration: "4m", MissingCommands: []string{"lsof"}},
+			expected:  true,
+		},
+		{
+			name:      "remote actions not resolved before the deadline",
+			candidate: &Candidate{Duration: "4m", Unenriched: []string{EnrichmentActions}},
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.candidate.HasWarnings(); got != tt.expected {
+				t.Errorf("HasWarnings() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeDurations returns fixed durations per job display name
+type fakeDurations map[string][]time.Duration
+
+func (f fakeDurations) GetJobDurations(ctx context.Context, workflowPath, jobID, jobDisplayName string, runs int, formerNames ...string) ([]*api.JobDuration, error) {
+	samples, ok := f[jobDisplayName]
+	if !ok {
+		return nil, errors.New("no runs")
+	}
+	var durations []*api.JobDuration
+	for _, d := range samples[:min(runs, len(samples))] {
+		durations = append(durations, &api.JobDuration{JobName: jobDisplayName, Duration: d})
+	}
+	return durations, nil
+}
+
+func TestScan_DurationProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    name: Test
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	provider := fakeDurations{"lint": {time.Minute, 3 * time.Minute, 2 * time.Minute}}
+	result, err := ScanWithOptions(Options{Durations: provider, Runs: 3}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	durations := make(map[string]string)
+	for _, c := range result.Candidates {
+		durations[c.JobID] = c.Duration
+		if c.JobID == "lint" && (c.DurationStats == nil || c.DurationStats.Runs != 3) {
+			t.Errorf("lint DurationStats = %+v, want 3 runs", c.DurationStats)
+		}
+	}
+	if durations["lint"] != "2m" || durations["test"] != "" {
+		t.Errorf("durations = %v, want lint 2m (the median) and test unknown", durations)
+	}
+
+	result, err = ScanWithOptions(Options{Durations: OfflineDurations}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	for _, c := range result.Candidates {
+		if c.Duration != "" {
+			t.Errorf("%s Duration with OfflineDurations = %q, want unknown", c.JobID, c.Duration)
+		}
+	}
+}
+
+func TestScan_MaxDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    name: Test
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	provider := fakeDurations{"lint": {2 * time.Minute}, "Test": {25*time.Minute + 30*time.Second}}
+	result, err := ScanWithOptions(Options{Durations: provider, MaxDuration: 15 * time.Minute}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	for _, c := range result.Candidates {
+		long := c.JobID == "test"
+		if c.LongRunning != long || c.HasWarnings() != long {
+			t.Errorf("%s LongRunning = %v, HasWarnings() = %v, want %v", c.JobID, c.LongRunning, c.HasWarnings(), long)
+		}
+		rule := c.Rules[len(c.Rules)-1]
+		if long && (rule.Rule != "duration" || rule.Result != ResultWarn || rule.Reason != LongRunningReason("25m30s", 15*time.Minute)) {
+			t.Errorf("%s duration rule = %+v, want a warning about the maximum duration", c.JobID, rule)
+		}
+	}
+}
+
+func TestScan_Severities(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+  debug:
+    runs-on: ubuntu-latest
+    steps:
+      - run: lsof -i :8080
+  test:
+    name: Test
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	rules := NewRuleSet()
+	for idOrName, severity := range map[string]Severity{"docker-commands": SeverityWarning, "missing-commands": SeverityInfo, RuleUnknownDuration: SeverityError} {
+		if err := rules.SetSeverity(idOrName, severity); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := fakeDurations{"image": {2 * time.Minute}, "debug": {time.Minute}}
+	result, err := ScanWithOptions(Options{Durations: provider, Rules: rules}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+
+	warnings := make(map[string]bool)
+	for _, c := range result.Candidates {
+		warnings[c.JobID] = c.HasWarnings()
+	}
+	// docker-commands only requires attention, and missing commands are info
+	if want := map[string]bool{"image": true, "debug": false}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("candidates (with warnings) = %v, want %v", warnings, want)
+	}
+	// The unknown execution time of Test makes it ineligible
+	if len(result.IneligibleJobs) != 1 || result.IneligibleJobs[0].JobID != "test" || result.IneligibleJobs[0].Reasons[0] != "Last execution time: unknown" {
+		t.Errorf("IneligibleJobs = %+v, want test for its unknown execution time", result.IneligibleJobs)
+	}
+	// docker is a missing command of image as well
+	for min, want := range map[Severity]int{SeverityError: 1, SeverityWarning: 2, SeverityInfo: 4} {
+		if got := result.Findings(min); got != want {
+			t.Errorf("Findings(%s) = %d, want %d", min, got, want)
+		}
+	}
+
+	// Unknown execution times set to off do not require attention
+	rules = NewRuleSet()
+	if err := rules.SetSeverity(RuleUnknownDuration, SeverityOff); err != nil {
+		t.Fatal(err)
+	}
+	result, err = ScanWithOptions(Options{SkipDuration: true, Rules: rules}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	for _, c := range result.Candidates {
+		if c.HasUnknownDuration() {
+			t.Errorf("%s HasUnknownDuration() = true, want false with unknown-duration off", c.JobID)
+		}
+	}
+}
+
+func TestScan_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`
+	var paths []string
+	for _, name := range []string{"ci.yml", "release.yml"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write workflow: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	opts := Options{SkipDuration: true, ExcludeFiles: []string{"release*.yml"}, ExcludeJobs: []string{"ci.yml:deploy"}}
+	result, err := ScanWithOptions(opts, paths...)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].JobID != "lint" || filepath.Base(result.Candidates[0].WorkflowPath) != "ci.yml" {
+		t.Errorf("Candidates = %v, want only ci.yml:lint", result.Candidates)
+	}
+	if len(result.Ignored) != 1 || result.Ignored[0].JobID != "deploy" {
+		t.Errorf("Ignored = %v, want ci.yml:deploy", result.Ignored)
+	}
+
+	for _, opts := range []Options{
+		{SkipDuration: true, ExcludeJobs: []string{"deploy"}},
+		{SkipDuration: true, ExcludeFiles: []string{"[.yml"}},
+	} {
+		if _, err := ScanWithOptions(opts, paths...); err == nil {
+			t.Errorf("ScanWithOptions(%+v) expected an error", opts)
+		}
+	}
+}
+
+func TestScan_ArmMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  tools:
+    runs-on: ubuntu-latest
+    steps:
+      - run: wget https://example.com/tool-x86_64.tar.gz
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	rules := NewRuleSet()
+	rules.SetMode(ModeArm)
+	result, err := ScanWithOptions(Options{SkipDuration: true, Rules: rules}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].JobID != "image" {
+		t.Fatalf("Candidates = %v, want image", result.Candidates)
+	}
+	if got := result.Candidates[0].ContainerImages; len(got) != 1 || got[0] != "docker build ." {
+		t.Errorf("ContainerImages = %q, want the docker build command", got)
+	}
+	for _, r := range result.Candidates[0].Rules {
+		if strings.HasPrefix(r.ID, "SLIM") && r.ID != "SLIM001" && r.ID != "SLIM008" {
+			t.Errorf("rule %s evaluated in ARM mode", r.ID)
+		}
+	}
+	if len(result.IneligibleJobs) != 1 || result.IneligibleJobs[0].JobID != "tools" {
+		t.Errorf("IneligibleJobs = %v, want tools", result.IneligibleJobs)
+	}
+}
+
+func TestScan_Dependencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+  test:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+  deploy:
+    needs: [test, missing]
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	// needs: of jobs that do not exist are left out
+	want := []*JobDependency{
+		{WorkflowPath: path, JobID: "test", Needs: "build"},
+		{WorkflowPath: path, JobID: "deploy", Needs: "test"},
+	}
+	if !reflect.DeepEqual(result.Dependencies, want) {
+		t.Errorf("Dependencies = %+v, want %+v", result.Dependencies, want)
+	}
+	for _, c := range result.Candidates {
+		want := map[string][]string{"build": {"test", "deploy"}}[c.JobID]
+		if !reflect.DeepEqual(c.Downstream, want) {
+			t.Errorf("%s: Downstream = %v, want %v", c.JobID, c.Downstream, want)
+		}
+	}
+}
+
+func TestScan_Migrated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-slim
+    steps:
+      - run: make lint
+  image:
+    runs-on: ubuntu-slim
+    steps:
+      - run: docker build .
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+  mac:
+    runs-on: macos-latest
+    steps:
+      - run: make
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := ScanWithOptions(Options{SkipDuration: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	if len(result.Migrated) != 2 || result.Migrated[0].JobID != "lint" || result.Migrated[1].JobID != "image" || result.Migrated[1].IsBroken() {
+		t.Errorf("Migrated = %+v, want lint and image, unchecked", result.Migrated)
+	}
+	if len(result.IneligibleJobs) != 1 || result.IneligibleJobs[0].JobID != "mac" {
+		t.Errorf("IneligibleJobs = %+v, want mac only", result.IneligibleJobs)
+	}
+
+	result, err = ScanWithOptions(Options{SkipDuration: true, CheckMigrated: true}, path)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() unexpected error: %v", err)
+	}
+	lint, image := result.Migrated[0], result.Migrated[1]
+	if lint.IsBroken() {
+		t.Errorf("lint Reasons = %v, want none", lint.Reasons)
+	}
+	if !image.IsBroken() || image.ReasonRuleID(0) != "SLIM002" {
+		t.Errorf("image Reasons = %v, want the Docker commands", image.Reasons)
+	}
+}
@@ -0,0 +1,94 @@
+package scan
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// keepOnUbuntuLatest is always offered as the last alternative, since some
+// services have no practical docker-free replacement.
+const keepOnUbuntuLatest = "keep this job on ubuntu-latest"
+
+// serviceAlternatives maps well-known service images to docker-free
+// alternatives that work on ubuntu-slim. Keys are image names without
+// registry, namespace, or tag (e.g., "postgres" for "docker.io/library/postgres:14").
+var serviceAlternatives = map[string][]string{
+	"postgres":      {"ankane/setup-postgres", "install postgresql via apt-get and start it in a step"},
+	"postgis":       {"ankane/setup-postgres (with postgis)", "install postgresql-postgis via apt-get"},
+	"mysql":         {"ankane/setup-mysql", "shogo82148/actions-setup-mysql"},
+	"mariadb":       {"ankane/setup-mariadb", "shogo82148/actions-setup-mysql (distribution: mariadb)"},
+	"redis":         {"shogo82148/actions-setup-redis", "install redis-server via apt-get and run it in the background"},
+	"valkey":        {"shogo82148/actions-setup-redis", "install redis-server via apt-get and run it in the background"},
+	"mongo":         {"ankane/setup-mongodb"},
+	"elasticsearch": {"ankane/setup-elasticsearch"},
+	"opensearch":    {"ankane/setup-opensearch"},
+	"memcached":     {"install memcached via apt-get and run it in the background"},
+	"rabbitmq":      {"install rabbitmq-server via apt-get"},
+	"minio":         {"download the minio server binary and run it in the background"},
+}
+
+// ServiceSuggestion describes docker-free alternatives for a service container
+type ServiceSuggestion struct {
+	Service      string   `json:"service"`         // Service name (the key in the services map)
+	Image        string   `json:"image,omitempty"` // Image reference, empty if unknown
+	Alternatives []string `json:"alternatives"`    // Suggested replacements, always ending with keeping the job on ubuntu-latest
+}
+
+// suggestServiceReplacements returns suggestions for each service container in the job.
+// Suggestions are sorted by service name.
+func suggestServiceReplacements(job *workflow.Job) []ServiceSuggestion {
+	images := job.ServiceImages()
+	if len(images) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestions := make([]ServiceSuggestion, 0, len(names))
+	for _, name := range names {
+		image := images[name]
+
+		// Fall back to the service name when the image is unknown,
+		// since services are commonly named after what they run (e.g., "redis")
+		key := serviceImageName(image)
+		if key == "" {
+			key = strings.ToLower(name)
+		}
+
+		var alternatives []string
+		alternatives = append(alternatives, serviceAlternatives[key]...)
+		alternatives = append(alternatives, keepOnUbuntuLatest)
+
+		suggestions = append(suggestions, ServiceSuggestion{
+			Service:      name,
+			Image:        image,
+			Alternatives: alternatives,
+		})
+	}
+	return suggestions
+}
+
+// serviceImageName extracts the bare image name from an image reference.
+// e.g., "ghcr.io/org/postgres:14@sha256:..." → "postgres"
+func serviceImageName(image string) string {
+	image = strings.ToLower(strings.TrimSpace(image))
+	if image == "" || strings.Contains(image, "${{") {
+		return ""
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	if i := strings.Index(image, ":"); i >= 0 {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestSuggestServiceReplacements(t *testing.T) {
+	tests := []struct {
+		name     string
+		services any
+		want     []ServiceSuggestion
+	}{
+		{
+			name:     "no services",
+			services: nil,
+			want:     nil,
+		},
+		{
+			name: "known images sorted by service name",
+			services: map[string]any{
+				"redis": map[string]any{"image": "redis:7"},
+				"db":    map[string]any{"image": "postgres:14"},
+			},
+			want: []ServiceSuggestion{
+				{Service: "db", Image: "postgres:14", Alternatives: append(append([]string{}, serviceAlternatives["postgres"]...), keepOnUbuntuLatest)},
+				{Service: "redis", Image: "redis:7", Alternatives: append(append([]string{}, serviceAlternatives["redis"]...), keepOnUbuntuLatest)},
+			},
+		},
+		{
+			name: "image with registry and digest",
+			services: map[string]any{
+				"cache": map[string]any{"image": "ghcr.io/acme/redis:7@sha256:abc"},
+			},
+			want: []ServiceSuggestion{
+				{Service: "cache", Image: "ghcr.io/acme/redis:7@sha256:abc", Alternatives: append(append([]string{}, serviceAlternatives["redis"]...), keepOnUbuntuLatest)},
+			},
+		},
+		{
+			name: "falls back to service name when image is an expression",
+			services: map[string]any{
+				"mysql": map[string]any{"image": "${{ matrix.image }}"},
+			},
+			want: []ServiceSuggestion{
+				{Service: "mysql", Image: "${{ matrix.image }}", Alternatives: append(append([]string{}, serviceAlternatives["mysql"]...), keepOnUbuntuLatest)},
+			},
+		},
+		{
+			name: "unknown image only suggests keeping ubuntu-latest",
+			services: map[string]any{
+				"app": map[string]any{"image": "acme/custom-service:1"},
+			},
+			want: []ServiceSuggestion{
+				{Service: "app", Image: "acme/custom-service:1", Alternatives: []string{keepOnUbuntuLatest}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &workflow.Job{RunsOn: "ubuntu-latest", Services: tt.services}
+			got := suggestServiceReplacements(job)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("suggestServiceReplacements() returned %d suggestions, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i].Service != tt.want[i].Service || got[i].Image != tt.want[i].Image {
+					t.Errorf("suggestion[%d] = %s (%s), want %s (%s)", i, got[i].Service, got[i].Image, tt.want[i].Service, tt.want[i].Image)
+				}
+				if len(got[i].Alternatives) != len(tt.want[i].Alternatives) {
+					t.Errorf("suggestion[%d] alternatives = %v, want %v", i, got[i].Alternatives, tt.want[i].Alternatives)
+					continue
+				}
+				for j := range got[i].Alternatives {
+					if got[i].Alternatives[j] != tt.want[i].Alternatives[j] {
+						t.Errorf("suggestion[%d] alternatives = %v, want %v", i, got[i].Alternatives, tt.want[i].Alternatives)
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// CommandLookup reports which of commands are available on a runner image
+type CommandLookup func(ctx context.Context, commands []string) (map[string]bool, error)
+
+// SimulatedCommand is a command a job runs, checked against a runner image
+type SimulatedCommand struct {
+	Command   string `json:"command"`
+	Available bool   `json:"available"`
+	// InDataset reports whether the command dataset lists the command on
+	// ubuntu-slim, which the scan's missing commands are detected with
+	InDataset bool              `json:"in_dataset"`
+	Installer string            `json:"installer,omitempty"` // How to install it, if not available
+	Evidence  workflow.Evidence `json:"evidence"`            // First use of the command in the job
+}
+
+// Simulate checks every command the job runs that the runner image must
+// provide (see Job.RequiredCommandEvidence) with lookup, in the order the
+// job runs them
+func Simulate(ctx context.Context, job *workflow.Job, lookup CommandLookup) ([]SimulatedCommand, error) {
+	evidence := job.RequiredCommandEvidence()
+	if len(evidence) == 0 {
+		return nil, nil
+	}
+	commands := make([]string, len(evidence))
+	for i, e := range evidence {
+		commands[i] = e.Pattern
+	}
+	available, err := lookup(ctx, commands)
+	if err != nil {
+		return nil, err
+	}
+
+	simulated := make([]SimulatedCommand, len(evidence))
+	for i, e := range evidence {
+		c := SimulatedCommand{
+			Command:   e.Pattern,
+			Available: available[e.Pattern],
+			InDataset: workflow.LookupCommand(e.Pattern).Slim,
+			Evidence:  e,
+		}
+		if !c.Available {
+			c.Installer = workflow.Installer(e.Pattern)
+		}
+		simulated[i] = c
+	}
+	return simulated, nil
+}
+
+// simulateScript prints the arguments that are commands, in bash like the
+// run: steps of ubuntu-slim, so that builtins and keywords count as available
+const simulateScript = `for c in "$@"; do if command -v -- "$c" >/dev/null 2>&1; then printf '%s\n' "$c"; fi; done`
+
+// ImageLookup returns a CommandLookup that runs command -v for each command
+// in a container of image with docker (which pulls the image if needed)
+func ImageLookup(image string) CommandLookup {
+	return func(ctx context.Context, commands []string) (map[string]bool, error) {
+		args := append([]string{"run", "--rm", "--platform", "linux/amd64", "--entrypoint", "bash", image, "-c", simulateScript, "simulate"}, commands...)
+		cmd := exec.CommandContext(ctx, "docker", args...)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return nil, fmt.Errorf("docker is required to run image %s: %w", image, err)
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("failed to run image %s: %s", image, msg)
+			}
+			return nil, fmt.Errorf("failed to run image %s: %w", image, err)
+		}
+		available := make(map[string]bool)
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				available[line] = true
+			}
+		}
+		return available, nil
+	}
+}
+
+// ManifestLookup returns a CommandLookup reading the commands of a runner
+// image from a software manifest: a command dataset (its ubuntu-slim
+// commands, see "gh slimify db"), or a list of commands, one per line, such
+// as the output of compgen -c on a runner, which includes the shell builtins
+func ManifestLookup(data []byte) (CommandLookup, error) {
+	commands := make(map[string]bool)
+	if json.Valid(data) {
+		db, err := workflow.ParseCommandsDB(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command dataset: %w", err)
+		}
+		slim := db.Images[workflow.ImageSlim]
+		for _, cmd := range slim.Commands {
+			commands[cmd] = true
+		}
+		for cmd := range slim.Tools {
+			commands[cmd] = true
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				commands[line] = true
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if len(commands) == 0 {
+			return nil, fmt.Errorf("no commands found in manifest")
+		}
+	}
+
+	return func(_ context.Context, names []string) (map[string]bool, error) {
+		available := make(map[string]bool)
+		for _, name := range names {
+			available[name] = commands[name]
+		}
+		return available, nil
+	}, nil
+}
@@ -0,0 +1,60 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestSimulate(t *testing.T) {
+	lookup, err := ManifestLookup([]byte("# compgen -c\necho\nmake\ngo\n\njq\n"))
+	if err != nil {
+		t.Fatalf("ManifestLookup() unexpected error: %v", err)
+	}
+	job := &workflow.Job{
+		RunsOn: "ubuntu-latest",
+		Steps: []workflow.Step{
+			{Run: "echo building\nmake build"},
+			{Run: "lsof -i :8080\n./scripts/check.sh"},
+		},
+	}
+
+	simulated, err := Simulate(context.Background(), job, lookup)
+	if err != nil {
+		t.Fatalf("Simulate() unexpected error: %v", err)
+	}
+	want := []struct {
+		command   string
+		available bool
+	}{{"echo", true}, {"make", true}, {"lsof", false}}
+	if len(simulated) != len(want) {
+		t.Fatalf("Simulate() = %+v, want %v", simulated, want)
+	}
+	for i, w := range want {
+		c := simulated[i]
+		if c.Command != w.command || c.Available != w.available {
+			t.Errorf("Simulate()[%d] = %s (available: %v), want %s (available: %v)", i, c.Command, c.Available, w.command, w.available)
+		}
+	}
+	if lsof := simulated[2]; lsof.InDataset || lsof.Installer == "" || lsof.Evidence.Line != "lsof -i :8080" {
+		t.Errorf("Simulate() lsof = %+v, want missing from the dataset with an installer", lsof)
+	}
+}
+
+func TestManifestLookup(t *testing.T) {
+	lookup, err := ManifestLookup([]byte(`{"schema":1,"version":"2026-01-01","images":{"ubuntu-latest":{"commands":["jq","lsof"]},"ubuntu-slim":{"commands":["jq"],"tools":{"yq":"4.44"}}}}`))
+	if err != nil {
+		t.Fatalf("ManifestLookup() unexpected error: %v", err)
+	}
+	available, _ := lookup(context.Background(), []string{"jq", "yq", "lsof"})
+	if !available["jq"] || !available["yq"] || available["lsof"] {
+		t.Errorf("lookup() = %v, want jq and yq from the ubuntu-slim image of the dataset", available)
+	}
+
+	for _, manifest := range []string{`{"schema":1}`, "# no commands\n"} {
+		if _, err := ManifestLookup([]byte(manifest)); err == nil {
+			t.Errorf("ManifestLookup(%q) expected error, got none", manifest)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package scan
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// DurationStats summarizes the execution time of a job over several
+// successful runs, looked up with Options.Runs
+type DurationStats struct {
+	Runs   int    `json:"runs"`   // Number of runs sampled
+	Median string `json:"median"` // Median execution time
+	P90    string `json:"p90"`    // 90th percentile execution time
+	StdDev string `json:"stddev"` // Standard deviation, the square root of the variance
+}
+
+// NewDurationStats computes the statistics of durations, which must not be empty
+func NewDurationStats(durations []time.Duration) *DurationStats {
+	return &DurationStats{
+		Runs:   len(durations),
+		Median: FormatDuration(percentile(durations, 50)),
+		P90:    FormatDuration(percentile(durations, 90)),
+		StdDev: FormatDuration(stdDev(durations)),
+	}
+}
+
+// percentile returns the p-th percentile of durations by linear interpolation
+// between the closest ranks
+func percentile(durations []time.Duration, p float64) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	frac := rank - float64(lower)
+	return sorted[lower] + time.Duration(math.Round(frac*float64(sorted[upper]-sorted[lower])))
+}
+
+// stdDev returns the population standard deviation of durations
+func stdDev(durations []time.Duration) time.Duration {
+	var sum float64
+	for _, d := range durations {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(durations))
+	var variance float64
+	for _, d := range durations {
+		variance += (float64(d) - mean) * (float64(d) - mean)
+	}
+	variance /= float64(len(durations))
+	return time.Duration(math.Sqrt(variance))
+}
@@ -0,0 +1,41 @@
+package scan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDurationStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      DurationStats
+	}{
+		{
+			name:      "single run",
+			durations: []time.Duration{4 * time.Minute},
+			want:      DurationStats{Runs: 1, Median: "4m", P90: "4m", StdDev: "0s"},
+		},
+		{
+			name:      "even number of runs",
+			durations: []time.Duration{3 * time.Minute, time.Minute, 4 * time.Minute, 2 * time.Minute},
+			want:      DurationStats{Runs: 4, Median: "2m30s", P90: "3m42s", StdDev: "1m7s"},
+		},
+		{
+			name: "outlier",
+			durations: []time.Duration{
+				time.Minute, time.Minute, time.Minute, time.Minute, time.Minute,
+				time.Minute, time.Minute, time.Minute, time.Minute, 11 * time.Minute,
+			},
+			want: DurationStats{Runs: 10, Median: "1m", P90: "2m", StdDev: "3m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDurationStats(tt.durations); *got != tt.want {
+				t.Errorf("NewDurationStats() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package scan
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Target is a runner jobs on the source runner (see workflow.SourceLabel) can
+// be moved to, compared by CompareRunners
+type Target struct {
+	Name           string  `json:"name"`  // Short name, e.g., "arm"
+	Label          string  `json:"label"` // runs-on label
+	Mode           Mode    `json:"mode"`  // Rules the jobs are evaluated with
+	PricePerMinute float64 `json:"price_per_minute"`
+}
+
+// Targets returns the runners CompareRunners knows, priced with p: ubuntu-slim,
+// the ARM runner, and a 4-core larger runner. Larger runners are created by
+// organizations under labels of their choice; the label is the one GitHub
+// suggests.
+func Targets(p Pricing) []Target {
+	return []Target{
+		{Name: "slim", Label: workflow.DefaultTargetLabel, Mode: ModeSlim, PricePerMinute: p.UbuntuSlim},
+		{Name: "arm", Label: workflow.DefaultArmLabel, Mode: ModeArm, PricePerMinute: p.UbuntuArm},
+		{Name: "larger", Label: "ubuntu-latest-4-cores", Mode: ModeLarger, PricePerMinute: p.Larger},
+	}
+}
+
+// LookupTargets returns the targets with the given names, in that order
+func LookupTargets(p Pricing, names ...string) ([]Target, error) {
+	targets := Targets(p)
+	var selected []Target
+	for _, name := range names {
+		found := false
+		for _, t := range targets {
+			if t.Name == name {
+				selected = append(selected, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			known := make([]string, len(targets))
+			for i, t := range targets {
+				known[i] = t.Name
+			}
+			return nil, fmt.Errorf("unknown target %q (expected %s)", name, strings.Join(known, ", "))
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no target to compare")
+	}
+	return selected, nil
+}
+
+// RunnerComparison is the eligibility of jobs for several target runners
+type RunnerComparison struct {
+	Source      string                 `json:"source"` // runs-on label the jobs are on
+	SourcePrice float64                `json:"source_price_per_minute"`
+	Targets     []*TargetSummary       `json:"targets"`
+	Jobs        []*RunnerJobComparison `json:"jobs"`
+	LoadErrors  []LoadError            `json:"load_errors,omitempty"`
+}
+
+// TargetSummary sums up the comparison of the jobs for one target
+type TargetSummary struct {
+	Target
+	Safe     int `json:"safe"`
+	Warning  int `json:"warning"`
+	Eligible int `json:"eligible"` // Safe and warning jobs
+	// Priced counts the eligible jobs whose duration is known
+	Priced int `json:"priced"`
+	// CostChange is the change of the cost of one run of each priced job, in
+	// USD (negative when the target is cheaper), assuming jobs take as long on
+	// the target
+	CostChange float64 `json:"cost_change_per_run"`
+}
+
+// RunnerJobComparison is the eligibility of one job for each target
+type RunnerJobComparison struct {
+	WorkflowPath string `json:"workflow"`
+	JobID        string `json:"job_id"`
+	JobName      string `json:"job_name"`
+	LineNumber   int    `json:"line"`
+	Duration     string `json:"duration,omitempty"`
+	// Results are in the order of RunnerComparison.Targets
+	Results []*TargetResult `json:"results"`
+}
+
+// TargetResult is the eligibility of a job for a target
+type TargetResult struct {
+	Target string `json:"target"`
+	Status string `json:"status"` // StatusSafe, StatusWarning, or StatusIneligible
+	// Reasons are the ineligibility reasons, or the warnings of eligible jobs
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// CompareRunners evaluates the jobs on the source runner against each target.
+// Durations are looked up once (unless opts.SkipDuration) and shared by the
+// targets. Jobs that are out of scope, ignored, or canaries are not compared.
+func CompareRunners(opts Options, targets []Target, paths ...string) (*RunnerComparison, error) {
+	// The larger runner mode only checks the scope, so this scan reports
+	// every job that can be compared, with its duration
+	base := opts
+	base.Rules = opts.Rules.WithMode(ModeLarger)
+	baseResult, err := ScanWithOptions(base, paths...)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make(map[string]*Candidate)
+	for _, c := range baseResult.Candidates {
+		candidates[c.WorkflowPath+":"+c.JobID] = c
+	}
+
+	comparison := &RunnerComparison{
+		Source:      workflow.SourceLabel(),
+		SourcePrice: opts.Pricing.UbuntuLatest,
+		Jobs:        []*RunnerJobComparison{},
+		LoadErrors:  baseResult.LoadErrors,
+	}
+	jobs := make(map[string]*RunnerJobComparison)
+	for _, d := range baseResult.Decisions() {
+		if !comparable(d) {
+			continue
+		}
+		job := &RunnerJobComparison{
+			WorkflowPath: d.WorkflowPath,
+			JobID:        d.JobID,
+			JobName:      d.JobName,
+			LineNumber:   d.LineNumber,
+		}
+		if c := candidates[d.WorkflowPath+":"+d.JobID]; c != nil && c.Duration != "unknown" {
+			job.Duration = c.Duration
+		}
+		jobs[d.WorkflowPath+":"+d.JobID] = job
+		comparison.Jobs = append(comparison.Jobs, job)
+	}
+
+	for _, target := range targets {
+		var result *ScanResult
+		if target.Mode == ModeLarger {
+			result = baseResult
+		} else {
+			targetOpts := opts
+			targetOpts.Rules = opts.Rules.WithMode(target.Mode)
+			targetOpts.SkipDuration = true
+			if result, err = ScanWithOptions(targetOpts, paths...); err != nil {
+				return nil, err
+			}
+			shareDurations(result, candidates, opts)
+		}
+
+		summary := &TargetSummary{Target: target}
+		for _, d := range result.Decisions() {
+			job := jobs[d.WorkflowPath+":"+d.JobID]
+			if job == nil || !comparable(d) {
+				continue
+			}
+			r := &TargetResult{Target: target.Name, Status: d.Status}
+			switch d.Status {
+			case StatusSafe:
+				summary.Safe++
+			case StatusWarning:
+				summary.Warning++
+				r.Reasons = ruleReasons(d, ResultWarn)
+			case StatusIneligible:
+				r.Reasons = ruleReasons(d, ResultFail)
+			}
+			if isEligibleStatus(d.Status) {
+				summary.Eligible++
+				if minutes, ok := billableMinutes(job.Duration); ok {
+					summary.Priced++
+					summary.CostChange += minutes * (target.PricePerMinute - opts.Pricing.UbuntuLatest)
+				}
+			}
+			job.Results = append(job.Results, r)
+		}
+		comparison.Targets = append(comparison.Targets, summary)
+	}
+	return comparison, nil
+}
+
+// comparable reports whether a job of a scan is compared: eligible jobs, and
+// ineligible ones that are in scope
+func comparable(d *Decision) bool {
+	switch d.Status {
+	case StatusSafe, StatusWarning:
+		return true
+	case StatusIneligible:
+		for _, r := range d.Rules {
+			if r.ID == "SLIM001" && r.Result == ResultFail {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// shareDurations gives the candidates of a scan made without durations the
+// ones looked up by the scan of another target, and evaluates their duration
+// rule again
+func shareDurations(result *ScanResult, durations map[string]*Candidate, opts Options) {
+	for _, c := range result.Candidates {
+		if d := durations[c.WorkflowPath+":"+c.JobID]; d != nil {
+			c.Duration = d.Duration
+			c.DurationStats = d.DurationStats
+			if d.IsUnenriched(EnrichmentDuration) && !c.IsUnenriched(EnrichmentDuration) {
+				c.Unenriched = append(c.Unenriched, EnrichmentDuration)
+			}
+		}
+		c.LongRunning = isLongRunning(c, opts)
+		for i, r := range c.Rules {
+			if r.Rule == "duration" {
+				c.Rules[i] = durationRule(c, opts)
+			}
+		}
+	}
+}
+
+// billableMinutes returns the minutes a job run taking duration (as formatted
+// by FormatDuration) is billed, rounded up to the minute like GitHub does
+func billableMinutes(duration string) (float64, bool) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, false
+	}
+	return math.Ceil(d.Minutes()), true
+}
@@ -0,0 +1,103 @@
+package scan
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+)
+
+// countingDurations counts the duration lookups of a DurationProvider
+type countingDurations struct {
+	DurationProvider
+	calls int
+}
+
+func (c *countingDurations) GetJobDurations(ctx context.Context, workflowPath, jobID, jobDisplayName string, runs int, formerNames ...string) ([]*api.JobDuration, error) {
+	c.calls++
+	return c.DurationProvider.GetJobDurations(ctx, workflowPath, jobID, jobDisplayName, runs, formerNames...)
+}
+
+func TestCompareRunners(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  tools:
+    runs-on: ubuntu-latest
+    steps:
+      - run: wget https://example.com/tool-x86_64.tar.gz
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+  mac:
+    runs-on: macos-latest
+    steps:
+      - run: make
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	provider := &countingDurations{DurationProvider: fakeDurations{
+		"lint":  {90 * time.Second},
+		"tools": {30 * time.Second},
+		"image": {5 * time.Minute},
+	}}
+	targets, err := LookupTargets(DefaultPricing, "slim", "arm", "larger")
+	if err != nil {
+		t.Fatalf("LookupTargets() unexpected error: %v", err)
+	}
+	comparison, err := CompareRunners(Options{Durations: provider, Pricing: DefaultPricing}, targets, path)
+	if err != nil {
+		t.Fatalf("CompareRunners() unexpected error: %v", err)
+	}
+	if provider.calls != 3 {
+		t.Errorf("durations looked up %d times, want once per job (3)", provider.calls)
+	}
+
+	statuses := make(map[string][]string)
+	for _, job := range comparison.Jobs {
+		for _, r := range job.Results {
+			statuses[job.JobID] = append(statuses[job.JobID], r.Status)
+		}
+	}
+	want := map[string][]string{
+		"image": {StatusIneligible, StatusWarning, StatusSafe},
+		"lint":  {StatusSafe, StatusSafe, StatusSafe},
+		"tools": {StatusSafe, StatusIneligible, StatusSafe},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("compared jobs = %v, want image, lint, and tools", statuses)
+	}
+	for id, w := range want {
+		got := statuses[id]
+		if len(got) != len(w) || got[0] != w[0] || got[1] != w[1] || got[2] != w[2] {
+			t.Errorf("%s statuses = %v, want %v", id, got, w)
+		}
+	}
+
+	slim, arm := comparison.Targets[0], comparison.Targets[1]
+	if slim.Eligible != 2 || slim.Priced != 2 {
+		t.Errorf("slim summary = %+v, want 2 eligible priced jobs", slim)
+	}
+	// lint (2 billable minutes) and tools (1) at $0.002 instead of $0.006
+	if math.Abs(slim.CostChange-(-0.012)) > 1e-9 {
+		t.Errorf("slim CostChange = %v, want -0.012", slim.CostChange)
+	}
+	if arm.Safe != 1 || arm.Warning != 1 {
+		t.Errorf("arm summary = %+v, want 1 safe and 1 warning", arm)
+	}
+
+	if _, err := LookupTargets(DefaultPricing, "gpu"); err == nil {
+		t.Error("LookupTargets() expected an error for an unknown target")
+	}
+}
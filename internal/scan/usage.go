@@ -0,0 +1,136 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+)
+
+// UsageWindow is the period billable minutes are summed over, as an estimate
+// of a month of usage
+const UsageWindow = 30 * 24 * time.Hour
+
+// Pricing is the price per minute of the runners, in USD
+type Pricing struct {
+	UbuntuLatest float64
+	UbuntuSlim   float64
+	UbuntuArm    float64 // ARM runner, compared by CompareRunners
+	Larger       float64 // Larger x64 runner, compared by CompareRunners
+}
+
+// DefaultPricing is the price per minute of the GitHub-hosted runners:
+// Linux 2-core for ubuntu-latest, Linux 1-core for ubuntu-slim, Linux 2-core
+// arm64 for the ARM runner, and Linux 4-core for the larger runner
+var DefaultPricing = Pricing{UbuntuLatest: 0.006, UbuntuSlim: 0.002, UbuntuArm: 0.005, Larger: 0.012}
+
+// Usage is the billable usage of a job over the last UsageWindow, and its
+// estimated monthly cost on each runner, assuming the same durations
+type Usage struct {
+	// Runs counts the job runs, including matrix instances and re-run attempts
+	Runs int `json:"runs"`
+	// BillableMinutes is rounded up to the minute for each job run, like
+	// GitHub bills them
+	BillableMinutes int     `json:"billable_minutes"`
+	Cost            float64 `json:"monthly_cost"`      // On ubuntu-latest, in USD
+	SlimCost        float64 `json:"monthly_cost_slim"` // On ubuntu-slim, in USD
+	Savings         float64 `json:"monthly_savings"`   // Cost minus SlimCost
+}
+
+// fetchUsage sums the billable minutes of each candidate over the runs of the
+// last UsageWindow and estimates its monthly cost with opts.Pricing. Runs are
+// fetched once per workflow. Candidates whose usage could not be looked up
+// are left without usage. rateLimited is true if the GitHub API rate limit
+// was exhausted.
+func fetchUsage(ctx context.Context, candidates []*Candidate, opts Options) (rateLimited bool, err error) {
+	if len(candidates) == 0 {
+		return false, nil
+	}
+
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return false, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	// Jobs of reusable workflows run as part of their caller's runs
+	byWorkflow := make(map[string][]*Candidate)
+	for _, c := range candidates {
+		workflowPath, _, _ := durationLookupKey(c)
+		byWorkflow[workflowPath] = append(byWorkflow[workflowPath], c)
+	}
+	workflowPaths := make([]string, 0, len(byWorkflow))
+	for path := range byWorkflow {
+		workflowPaths = append(workflowPaths, path)
+	}
+	sort.Strings(workflowPaths)
+
+	since := time.Now().Add(-UsageWindow)
+	for _, workflowPath := range workflowPaths {
+		if ctx.Err() != nil {
+			break
+		}
+		jobRuns, err := client.GetJobRuns(ctx, workflowPath, since)
+		if err != nil {
+			slog.Debug("failed to get billable minutes", "workflow", workflowPath, "error", err)
+			if api.IsRateLimited(err) {
+				return true, nil
+			}
+			continue
+		}
+		for _, c := range byWorkflow[workflowPath] {
+			_, jobID, jobName := durationLookupKey(c)
+			c.Usage = newUsage(jobRuns, jobID, jobName, formerLookupNames(c, opts.FormerJobNames), opts.Pricing)
+		}
+	}
+	return false, nil
+}
+
+// newUsage sums the billable minutes of the runs of a job
+func newUsage(jobRuns []api.JobRun, jobID, jobName string, formerNames []string, pricing Pricing) *Usage {
+	u := &Usage{}
+	for _, jr := range jobRuns {
+		if !api.MatchesJob(jr.Name, jobID, jobName, formerNames) {
+			continue
+		}
+		u.Runs++
+		u.BillableMinutes += int(math.Ceil(jr.Duration.Minutes()))
+	}
+	u.Cost = float64(u.BillableMinutes) * pricing.UbuntuLatest
+	u.SlimCost = float64(u.BillableMinutes) * pricing.UbuntuSlim
+	u.Savings = u.Cost - u.SlimCost
+	return u
+}
+
+// RankByUsage returns the candidates with usage, the ones consuming the most
+// billable minutes first: the product of how often they run and how long
+// they take. Ties are ranked by number of runs, then by location.
+func RankByUsage(candidates []*Candidate) []*Candidate {
+	var ranked []*Candidate
+	for _, c := range candidates {
+		if c.Usage != nil {
+			ranked = append(ranked, c)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Usage.BillableMinutes != b.Usage.BillableMinutes {
+			return a.Usage.BillableMinutes > b.Usage.BillableMinutes
+		}
+		if a.Usage.Runs != b.Usage.Runs {
+			return a.Usage.Runs > b.Usage.Runs
+		}
+		if a.WorkflowPath != b.WorkflowPath {
+			return a.WorkflowPath < b.WorkflowPath
+		}
+		return a.LineNumber < b.LineNumber
+	})
+	return ranked
+}
@@ -0,0 +1,53 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+)
+
+func TestNewUsage(t *testing.T) {
+	jobRuns := []api.JobRun{
+		{RunID: 1, Name: "Build", Duration: 90 * time.Second},
+		{RunID: 1, Name: "Lint", Duration: 10 * time.Minute},
+		{RunID: 2, Name: "Build (ubuntu-latest, 20)", Duration: 2 * time.Minute},
+		{RunID: 2, Name: "Build (ubuntu-latest, 22)", Duration: 30 * time.Second},
+		{RunID: 3, Name: "Compile", Duration: 4 * time.Minute},
+	}
+
+	got := newUsage(jobRuns, "build", "Build", []string{"Compile"}, Pricing{UbuntuLatest: 0.01, UbuntuSlim: 0.004})
+	want := Usage{Runs: 4, BillableMinutes: 2 + 2 + 1 + 4, Cost: 0.09, SlimCost: 0.036, Savings: 0.054}
+	if got.Runs != want.Runs || got.BillableMinutes != want.BillableMinutes {
+		t.Errorf("newUsage() = %+v, want %+v", *got, want)
+	}
+	for name, pair := range map[string][2]float64{
+		"Cost":     {got.Cost, want.Cost},
+		"SlimCost": {got.SlimCost, want.SlimCost},
+		"Savings":  {got.Savings, want.Savings},
+	} {
+		if diff := pair[0] - pair[1]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("newUsage().%s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+}
+
+func TestRankByUsage(t *testing.T) {
+	candidates := []*Candidate{
+		{JobID: "lint", WorkflowPath: "ci.yml", LineNumber: 5, Usage: &Usage{Runs: 100, BillableMinutes: 100}},
+		{JobID: "unknown", WorkflowPath: "ci.yml", LineNumber: 9},
+		{JobID: "test", WorkflowPath: "ci.yml", LineNumber: 12, Usage: &Usage{Runs: 50, BillableMinutes: 400}},
+		{JobID: "docs", WorkflowPath: "docs.yml", LineNumber: 3, Usage: &Usage{Runs: 20, BillableMinutes: 100}},
+		{JobID: "nightly", WorkflowPath: "a.yml", LineNumber: 3, Usage: &Usage{Runs: 20, BillableMinutes: 100}},
+	}
+
+	var got []string
+	for _, c := range RankByUsage(candidates) {
+		got = append(got, c.JobID)
+	}
+	want := []string{"test", "lint", "nightly", "docs"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("RankByUsage() = %v, want %v", got, want)
+	}
+}
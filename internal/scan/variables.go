@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// variableLookup returns the value of a configuration variable
+type variableLookup func(ctx context.Context, name string) (string, error)
+
+// resolveRunnerVariables looks up the configuration variables runs-on is set
+// to (runs-on: ${{ vars.DEFAULT_RUNNER }}) in the current repository and its
+// organization, and records them in Workflow.Variables
+func resolveRunnerVariables(ctx context.Context, workflows []*workflow.Workflow) error {
+	host, owner, repo, err := api.GetRepoInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get repository info: %w", err)
+	}
+	client, err := api.NewClient(host, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+	setRunnerVariables(ctx, workflows, client.GetVariable)
+	return nil
+}
+
+// setRunnerVariables records the values of the variables runs-on is set to in
+// Workflow.Variables, looking each variable up once. Variables that cannot be
+// looked up are left out, so their jobs are reported with an unknown value.
+func setRunnerVariables(ctx context.Context, workflows []*workflow.Workflow, lookup variableLookup) {
+	values := make(map[string]string)
+	failed := make(map[string]bool)
+	for _, wf := range workflows {
+		for _, job := range wf.Jobs {
+			name, ok := job.RunnerVariable()
+			if !ok || failed[name] {
+				continue
+			}
+			value, ok := values[name]
+			if !ok {
+				v, err := lookup(ctx, name)
+				if err != nil {
+					level := slog.LevelWarn
+					if errors.Is(err, api.ErrVariableNotFound) {
+						level = slog.LevelDebug
+					}
+					slog.Log(ctx, level, "failed to look up variable", "variable", name, "error", err)
+					failed[name] = true
+					continue
+				}
+				value, values[name] = v, v
+			}
+			if wf.Variables == nil {
+				wf.Variables = make(map[string]string)
+			}
+			wf.Variables[name] = value
+		}
+	}
+}
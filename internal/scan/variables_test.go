@@ -0,0 +1,41 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+func TestSetRunnerVariables(t *testing.T) {
+	newWorkflow := func(runsOn ...string) *workflow.Workflow {
+		wf := &workflow.Workflow{Jobs: make(map[string]*workflow.Job)}
+		for i, r := range runsOn {
+			wf.Jobs[fmt.Sprint("job", i)] = &workflow.Job{RunsOn: r}
+		}
+		return wf
+	}
+	ci := newWorkflow("${{ vars.DEFAULT_RUNNER }}", "${{ vars.MISSING }}", "ubuntu-latest")
+	release := newWorkflow("${{ vars.DEFAULT_RUNNER }}")
+
+	lookups := make(map[string]int)
+	lookup := func(ctx context.Context, name string) (string, error) {
+		lookups[name]++
+		if name == "DEFAULT_RUNNER" {
+			return "ubuntu-latest", nil
+		}
+		return "", api.ErrVariableNotFound
+	}
+	setRunnerVariables(context.Background(), []*workflow.Workflow{ci, release}, lookup)
+
+	if lookups["DEFAULT_RUNNER"] != 1 || lookups["MISSING"] != 1 || len(lookups) != 2 {
+		t.Errorf("lookups = %v, want each variable looked up once", lookups)
+	}
+	want := map[string]string{"DEFAULT_RUNNER": "ubuntu-latest"}
+	if !reflect.DeepEqual(ci.Variables, want) || !reflect.DeepEqual(release.Variables, want) {
+		t.Errorf("Variables = %v and %v, want %v", ci.Variables, release.Variables, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Package state records the runner changes made by gh-slimify (fix, apply,
+// revert, promote) in a state file kept in the repository, so that a team can audit
+// past migrations and back them out.
+package state
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/cache"
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// DefaultPath is the state file written at the repository root
+const DefaultPath = ".slimify-state.json"
+
+// Entry records a runner change of a job
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Command   string    `json:"command"` // The gh-slimify command that made the change (fix, apply, revert, promote)
+	Workflow  string    `json:"workflow"`
+	JobID     string    `json:"job_id"`
+	JobName   string    `json:"job_name,omitempty"`
+	From      string    `json:"from"`              // Runner before the change
+	To        string    `json:"to"`                // Runner after the change
+	Version   string    `json:"version,omitempty"` // gh-slimify version
+}
+
+// State is the content of the state file
+type State struct {
+	// Migrations lists the changes in the order they were made
+	Migrations []Entry `json:"migrations"`
+}
+
+// Load reads the state file at path. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	s := &State{}
+	if err := cache.ReadJSON(path, s); err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return s, nil
+}
+
+// Append adds entries to the state file at path, creating it if needed
+func Append(path string, entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := readonly.Check("write " + path); err != nil {
+		return err
+	}
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	s.Migrations = append(s.Migrations, entries...)
+	if err := cache.WriteJSON(path, s); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file unexpected error: %v", err)
+	}
+	if len(s.Migrations) != 0 {
+		t.Errorf("Load() of a missing file = %v, want no migrations", s.Migrations)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	fix := Entry{Timestamp: now, Command: "fix", Workflow: "ci.yml", JobID: "lint", From: "ubuntu-latest", To: "ubuntu-slim", Version: "v1.0.0"}
+	revert := Entry{Timestamp: now.Add(time.Hour), Command: "revert", Workflow: "ci.yml", JobID: "lint", From: "ubuntu-slim", To: "ubuntu-latest"}
+	if err := Append(path, fix); err != nil {
+		t.Fatalf("Append() unexpected error: %v", err)
+	}
+	if err := Append(path, revert); err != nil {
+		t.Fatalf("Append() unexpected error: %v", err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(s.Migrations) != 2 || s.Migrations[0] != fix || s.Migrations[1] != revert {
+		t.Errorf("Load() = %+v, want the appended entries in order", s.Migrations)
+	}
+}
+
+func TestAppend_ReadOnly(t *testing.T) {
+	t.Setenv(readonly.EnvVar, "1")
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	err := Append(path, Entry{Command: "fix"})
+	if !errors.Is(err, readonly.ErrReadOnly) {
+		t.Errorf("Append() error = %v, want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,196 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalAction represents an action defined in the repository
+// (e.g., a step with uses: ./.github/actions/setup)
+type LocalAction struct {
+	Path  string // Directory of the action, relative to the repository root
+	Using string // runs.using (e.g., "composite", "docker", "node20")
+	Image string // runs.image for docker actions
+	Steps []Step // runs.steps for composite actions
+}
+
+// IsComposite reports whether the action is a composite action
+func (a *LocalAction) IsComposite() bool {
+	return a.Using == "composite"
+}
+
+// IsDocker reports whether the action runs in a Docker container
+func (a *LocalAction) IsDocker() bool {
+	return a.Using == "docker"
+}
+
+// actionMetadata represents the parts of action.yml relevant for migration
+type actionMetadata struct {
+	Runs struct {
+		Using string `yaml:"using"`
+		Image string `yaml:"image"`
+		Steps []Step `yaml:"steps"`
+	} `yaml:"runs"`
+}
+
+// LoadLocalAction loads action.yml (or action.yaml) from a local action directory
+func LoadLocalAction(dir string) (*LocalAction, error) {
+	var data []byte
+	var err error
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		data, err = readRepoFile(filepath.Join(dir, name))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read action metadata in %s: %w", dir, err)
+	}
+
+	var metadata actionMetadata
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse action metadata in %s: %w", dir, err)
+	}
+
+	return &LocalAction{
+		Path:  dir,
+		Using: metadata.Runs.Using,
+		Image: metadata.Runs.Image,
+		Steps: metadata.Runs.Steps,
+	}, nil
+}
+
+// localActionPath returns the cleaned directory of a local action reference
+// (e.g., ".github/actions/setup" for "./.github/actions/setup").
+// Returns false if uses does not reference a local action.
+func localActionPath(uses string) (string, bool) {
+	if !strings.HasPrefix(uses, "./") {
+		return "", false
+	}
+	return filepath.Clean(strings.TrimPrefix(uses, "./")), true
+}
+
+// loadLocalActions loads the local actions used by steps into actions, following
+// composite actions that use other local actions. Actions that cannot be loaded
+// are skipped, since they may be created by earlier steps at runtime.
+func loadLocalActions(steps []Step, actions map[string]*LocalAction) {
+	for _, step := range steps {
+		path, ok := localActionPath(step.Uses)
+		if !ok {
+			continue
+		}
+		if _, loaded := actions[path]; loaded {
+			continue
+		}
+		action, err := LoadLocalAction(path)
+		actions[path] = action
+		if err != nil {
+			continue
+		}
+		if action.IsComposite() {
+			loadLocalActions(action.Steps, actions)
+		}
+	}
+}
+
+// expandedSteps returns the job's steps with the steps of local composite
+// actions inlined after the step that uses them, in execution order.
+func (j *Job) expandedSteps() []Step {
+	if len(j.LocalActions) == 0 {
+		return j.Steps
+	}
+
+	var steps []Step
+	expanding := make(map[string]bool)
+	var expand func([]Step)
+	expand = func(in []Step) {
+		for _, step := range in {
+			steps = append(steps, step)
+			path, ok := localActionPath(step.Uses)
+			if !ok || expanding[path] {
+				continue
+			}
+			if action := j.LocalActions[path]; action != nil && action.IsComposite() {
+				expanding[path] = true
+				expand(action.Steps)
+				expanding[path] = false
+			}
+		}
+	}
+	expand(j.Steps)
+	return steps
+}
+
+// dockerLocalActions returns the paths of local actions used by the job
+// (directly or through composite actions) that run in a Docker container
+func (j *Job) dockerLocalActions() []string {
+	var paths []string
+	for _, step := range j.expandedSteps() {
+		path, ok := localActionPath(step.Uses)
+		if !ok {
+			continue
+		}
+		if action := j.LocalActions[path]; action != nil && action.IsDocker() {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ActionRef is a reference to an action in another repository
+// (e.g., "owner/repo/path@ref" in uses:)
+type ActionRef struct {
+	Owner string
+	Repo  string
+	Path  string // Subdirectory of the action within the repository, empty for the root
+	Ref   string
+}
+
+// String formats the reference as it appears in uses:
+func (r ActionRef) String() string {
+	s := r.Owner + "/" + r.Repo
+	if r.Path != "" {
+		s += "/" + r.Path
+	}
+	return s + "@" + r.Ref
+}
+
+// ParseActionRef parses a remote action reference from a step's uses: value.
+// Returns false for local actions (./path), docker:// images, and malformed references.
+func ParseActionRef(uses string) (ActionRef, bool) {
+	if uses == "" || strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return ActionRef{}, false
+	}
+	name, ref, ok := strings.Cut(uses, "@")
+	if !ok || ref == "" {
+		return ActionRef{}, false
+	}
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ActionRef{}, false
+	}
+	r := ActionRef{Owner: parts[0], Repo: parts[1], Ref: ref}
+	if len(parts) == 3 {
+		r.Path = strings.Trim(parts[2], "/")
+	}
+	return r, true
+}
+
+// RemoteActions returns the unique remote actions used by the job's steps,
+// including steps of local composite actions, in order of first use.
+func (j *Job) RemoteActions() []ActionRef {
+	var refs []ActionRef
+	seen := make(map[ActionRef]bool)
+	for _, step := range j.expandedSteps() {
+		ref, ok := ParseActionRef(step.Uses)
+		if !ok || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
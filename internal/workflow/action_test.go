@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWorkflow_LocalActions(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".github/actions/outer/action.yml": `name: outer
+runs:
+  using: composite
+  steps:
+    - uses: ./.github/actions/inner
+    - run: lsof -i :8080
+      shell: bash
+`,
+		".github/actions/inner/action.yaml": `name: inner
+runs:
+  using: composite
+  steps:
+    - run: docker build -t app .
+      shell: bash
+`,
+		".github/actions/image/action.yml": `name: image
+runs:
+  using: docker
+  image: Dockerfile
+`,
+		".github/actions/node/action.yml": `name: node
+runs:
+  using: node20
+  main: index.js
+`,
+		".github/workflows/ci.yml": `name: ci
+on: push
+jobs:
+  composite:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/outer
+  docker:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/image
+  node:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/node
+  missing:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/does-not-exist
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer func() {
+		os.Chdir(originalWd)
+	}()
+
+	wf, err := LoadWorkflow(".github/workflows/ci.yml")
+	if err != nil {
+		t.Fatalf("LoadWorkflow() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		jobID               string
+		wantDockerCommands  bool
+		wantContainerAction bool
+		wantMissing         []string
+	}{
+		{jobID: "composite", wantDockerCommands: true, wantContainerAction: false, wantMissing: []string{"docker", "lsof"}},
+		{jobID: "docker", wantDockerCommands: false, wantContainerAction: true},
+		{jobID: "node", wantDockerCommands: false, wantContainerAction: false},
+		{jobID: "missing", wantDockerCommands: false, wantContainerAction: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.jobID, func(t *testing.T) {
+			job := wf.Jobs[tt.jobID]
+			if got := job.HasDockerCommands(); got != tt.wantDockerCommands {
+				t.Errorf("HasDockerCommands() = %v, want %v", got, tt.wantDockerCommands)
+			}
+			if got := job.HasContainerActions(); got != tt.wantContainerAction {
+				t.Errorf("HasContainerActions() = %v, want %v", got, tt.wantContainerAction)
+			}
+			got := job.GetMissingCommands()
+			if len(got) != len(tt.wantMissing) {
+				t.Fatalf("GetMissingCommands() = %v, want %v", got, tt.wantMissing)
+			}
+			for i := range got {
+				if got[i] != tt.wantMissing[i] {
+					t.Errorf("GetMissingCommands() = %v, want %v", got, tt.wantMissing)
+				}
+			}
+		})
+	}
+}
+
+func TestParseActionRef(t *testing.T) {
+	tests := []struct {
+		uses   string
+		want   ActionRef
+		wantOK bool
+	}{
+		{uses: "actions/checkout@v4", want: ActionRef{Owner: "actions", Repo: "checkout", Ref: "v4"}, wantOK: true},
+		{uses: "github/codeql-action/init@v3", want: ActionRef{Owner: "github", Repo: "codeql-action", Path: "init", Ref: "v3"}, wantOK: true},
+		{uses: "owner/repo/a/b@0123abc", want: ActionRef{Owner: "owner", Repo: "repo", Path: "a/b", Ref: "0123abc"}, wantOK: true},
+		{uses: "./.github/actions/setup"},
+		{uses: "docker://alpine:3.20"},
+		{uses: "owner/repo"},
+		{uses: "repo@v1"},
+		{uses: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			got, ok := ParseActionRef(tt.uses)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseActionRef(%q) = %+v, %v, want %+v, %v", tt.uses, got, ok, tt.want, tt.wantOK)
+			}
+			if ok && got.String() != tt.uses {
+				t.Errorf("String() = %q, want %q", got.String(), tt.uses)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// x86Pattern matches x86 architecture names in URLs, file names, and
+// platforms (e.g., "tool_linux_amd64.tar.gz", "x86_64", "linux/amd64")
+var x86Pattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(amd64|x86[_-]64|x64|i[36]86)(?:[^a-z0-9]|$)`)
+
+// downloadCommands are commands that fetch files, whose x86 URLs point to
+// binaries that do not run on ARM
+var downloadCommands = map[string]bool{
+	"curl":   true,
+	"wget":   true,
+	"aria2c": true,
+}
+
+// archInputs are with: inputs of actions selecting an architecture or
+// platform (e.g., actions/setup-python's architecture: x64)
+var archInputs = map[string]bool{
+	"arch":         true,
+	"architecture": true,
+	"platform":     true,
+	"platforms":    true,
+}
+
+// ArchSpecificEvidence returns the places where the job depends on the x86
+// architecture, which prevent it from running on ARM runners: downloads of
+// x86 binaries (curl, wget, gh release download), commands forcing the
+// linux/amd64 platform (e.g., docker run --platform linux/amd64), and action
+// inputs selecting an x86 architecture (e.g., architecture: x64). Pattern
+// holds the matched architecture.
+func (j *Job) ArchSpecificEvidence() []Evidence {
+	var evidence []Evidence
+	for _, step := range j.expandedSteps() {
+		for _, cmd := range stepCommands(step) {
+			line := cmd.Line()
+			if !isDownload(cmd) && !strings.Contains(strings.ToLower(line), "--platform") {
+				continue
+			}
+			if m := x86Pattern.FindStringSubmatch(line); m != nil {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: line, Pattern: strings.ToLower(m[1]), LineNumber: commandLineNumber(step, cmd)})
+			}
+		}
+
+		keys := make([]string, 0, len(step.With))
+		for key := range step.With {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !archInputs[strings.ToLower(key)] {
+				continue
+			}
+			value := fmt.Sprint(step.With[key])
+			if m := x86Pattern.FindStringSubmatch(value); m != nil && !strings.Contains(strings.ToLower(value), "arm64") {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Line: key + ": " + value, Pattern: strings.ToLower(m[1]), LineNumber: step.Pos.Line})
+			}
+		}
+	}
+	return evidence
+}
+
+// isDownload reports whether the command fetches files
+func isDownload(cmd shellCommand) bool {
+	name := cmd.Name()
+	if downloadCommands[name] {
+		return true
+	}
+	i := cmd.commandIndex()
+	return name == "gh" && len(cmd.Words) > i+2 && cmd.Words[i+1] == "release" && cmd.Words[i+2] == "download"
+}
@@ -0,0 +1,32 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_ArchSpecificEvidence(t *testing.T) {
+	job := &Job{
+		Steps: []Step{
+			{Run: "curl -fsSLO https://example.com/tool_linux_amd64.tar.gz\necho x86_64"},
+			{Run: "wget https://example.com/tool-linux-arm64.tar.gz"},
+			{Run: "gh release download v1 --pattern '*x86_64*'"},
+			{Run: "docker run --platform linux/amd64 alpine"},
+			{Uses: "actions/setup-python@v5", With: map[string]interface{}{"python-version": "3.12", "architecture": "x64"}},
+			{Uses: "docker/build-push-action@v6", With: map[string]interface{}{"platforms": "linux/amd64,linux/arm64"}},
+		},
+	}
+	var got []string
+	for _, e := range job.ArchSpecificEvidence() {
+		got = append(got, e.Line+" ["+e.Pattern+"]")
+	}
+	want := []string{
+		"curl -fsSLO https://example.com/tool_linux_amd64.tar.gz [amd64]",
+		"gh release download v1 --pattern *x86_64* [x86_64]",
+		"docker run --platform linux/amd64 alpine [amd64]",
+		"architecture: x64 [x64]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ArchSpecificEvidence() = %q, want %q", got, want)
+	}
+}
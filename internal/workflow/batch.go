@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+)
+
+// BackupSuffix is appended to the path of a workflow file to name its backup
+// (e.g., ci.yml.bak, which GitHub Actions does not run)
+const BackupSuffix = ".bak"
+
+// Batch records the original content of workflow files modified together, so
+// that the files already written can be restored if a later file fails.
+type Batch struct {
+	backup    bool
+	paths     []string
+	originals map[string][]byte
+	backups   []string
+}
+
+// NewBatch returns an empty batch. If backup is true, Track also copies each
+// file to its path with BackupSuffix before it is modified.
+func NewBatch(backup bool) *Batch {
+	return &Batch{backup: backup, originals: make(map[string][]byte)}
+}
+
+// Track records the content of filePath before it is modified. Files tracked
+// more than once keep the content recorded first.
+func (b *Batch) Track(filePath string) error {
+	if _, ok := b.originals[filePath]; ok {
+		return nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	if b.backup {
+		if err := readonly.Check("write " + filePath + BackupSuffix); err != nil {
+			return err
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath+BackupSuffix, data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", filePath, err)
+		}
+		b.backups = append(b.backups, filePath+BackupSuffix)
+	}
+	b.paths = append(b.paths, filePath)
+	b.originals[filePath] = data
+	return nil
+}
+
+// Backups returns the paths of the backups written by Track
+func (b *Batch) Backups() []string {
+	return b.backups
+}
+
+// Rollback restores the tracked files to their original content, most recent
+// first, and removes the backups, which are no longer needed. It attempts
+// every file and returns the errors encountered.
+func (b *Batch) Rollback() error {
+	var errs []error
+	for i := len(b.paths) - 1; i >= 0; i-- {
+		path := b.paths[i]
+		if err := writeFileAtomic(path, b.originals[path]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, path := range b.backups {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	b.paths = nil
+	b.originals = make(map[string][]byte)
+	b.backups = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBatch_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.yml")
+	second := filepath.Join(dir, "b.yml")
+	original := "jobs:\r\n  lint:\r\n    runs-on: ubuntu-latest\r\n"
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	batch := NewBatch(true)
+	for _, path := range []string{first, second} {
+		if err := batch.Track(path); err != nil {
+			t.Fatalf("Track() unexpected error: %v", err)
+		}
+		if _, err := UpdateRunsOnJobs(path, []string{"lint"}, "ubuntu-slim"); err != nil {
+			t.Fatalf("UpdateRunsOnJobs() unexpected error: %v", err)
+		}
+	}
+
+	backup, err := os.ReadFile(first + BackupSuffix)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup = %q, want %q", backup, original)
+	}
+	updated, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "jobs:\r\n  lint:\r\n    runs-on: ubuntu-slim\r\n"; string(updated) != want {
+		t.Errorf("updated file = %q, want %q (line endings preserved)", updated, want)
+	}
+
+	if err := batch.Rollback(); err != nil {
+		t.Fatalf("Rollback() unexpected error: %v", err)
+	}
+	for _, path := range []string{first, second} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != original {
+			t.Errorf("%s after Rollback() = %q, want %q", filepath.Base(path), data, original)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, want 0600", filepath.Base(path), info.Mode().Perm())
+		}
+		if _, err := os.Stat(path + BackupSuffix); !os.IsNotExist(err) {
+			t.Errorf("backup of %s still exists after Rollback()", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"gopkg.in/yaml.v3"
+)
+
+// CanarySuffix is added to a job's ID to name the canary added by AddCanaryJobs
+const CanarySuffix = "-slim"
+
+// AddCanaryJobs duplicates jobs instead of switching them: a copy of each job,
+// with CanarySuffix added to its job ID and " (slim)" to its name, runs on
+// newRunsOn with continue-on-error: true, right after the original job, so that
+// both runners can be compared before committing to one (see PromoteJob).
+// Jobs must run on ubuntu-latest, which stands for the source label set with
+// SetRunnerLabels. Like UpdateRunsOnJobs, all jobs are updated in a single
+// atomic write, and jobs that cannot be duplicated are returned with their
+// error, keyed by job ID.
+func AddCanaryJobs(filePath string, jobIDs []string, newRunsOn string) (map[string]error, error) {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML %s: %w", filePath, err)
+	}
+
+	failed := make(map[string]error)
+	var edits []textEdit
+	for _, jobID := range jobIDs {
+		edit, err := canaryJobEdit(data, root, jobID, sourceLabel, newRunsOn)
+		if err != nil {
+			failed[jobID] = err
+			continue
+		}
+		edits = append(edits, edit)
+	}
+	if len(edits) == 0 {
+		return failed, nil
+	}
+
+	updated := applyEdits(data, edits)
+	if _, err := parseDocument(updated); err != nil {
+		return nil, fmt.Errorf("failed to update %s: result is not valid YAML: %w", filePath, err)
+	}
+	if err := writeFileAtomic(filePath, updated); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// canaryJobEdit returns the edit inserting, after a job running on from, its
+// canary running on to
+func canaryJobEdit(data []byte, root *yaml.Node, jobID, from, to string) (textEdit, error) {
+	_, jobs := mappingValue(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode || jobs.Style == yaml.FlowStyle {
+		return textEdit{}, fmt.Errorf("jobs is not a block mapping")
+	}
+	jobKey, job := mappingValue(jobs, jobID)
+	if job == nil {
+		return textEdit{}, fmt.Errorf("job %s not found", jobID)
+	}
+	if job.Kind != yaml.MappingNode || job.Style == yaml.FlowStyle {
+		return textEdit{}, fmt.Errorf("job %s is not a block mapping", jobID)
+	}
+	canaryID := jobID + CanarySuffix
+	if key, _ := mappingValue(jobs, canaryID); key != nil {
+		return textEdit{}, fmt.Errorf("job %s already exists", canaryID)
+	}
+	runsOnKey, runsOn := mappingValue(job, "runs-on")
+	if runsOn == nil {
+		return textEdit{}, fmt.Errorf("runs-on not found")
+	}
+	if runsOn.Kind != yaml.ScalarNode || runsOn.Value != from {
+		return textEdit{}, fmt.Errorf("runs-on is not %s", from)
+	}
+
+	// The edits below are made to the job's own text, which is then copied
+	edit, err := scalarValueEdit(data, jobKey, canaryID)
+	if err != nil {
+		return textEdit{}, err
+	}
+	edits := []textEdit{edit}
+
+	edit, err = scalarValueEdit(data, runsOn, to)
+	if err != nil {
+		return textEdit{}, err
+	}
+	edits = append(edits, edit)
+
+	// Keep the canary's failures from failing the workflow
+	if key, value := mappingValue(job, "continue-on-error"); key == nil {
+		insert, err := lineInsertion(data, runsOnKey, pad(runsOnKey.Column-1)+"continue-on-error: true"+newline(data))
+		if err != nil {
+			return textEdit{}, err
+		}
+		edits = append(edits, insert)
+	} else if value.Kind != yaml.ScalarNode {
+		return textEdit{}, fmt.Errorf("continue-on-error is not a scalar")
+	} else if value.Value != "true" {
+		edit, err := scalarValueEdit(data, value, "true")
+		if err != nil {
+			return textEdit{}, err
+		}
+		edits = append(edits, edit)
+	}
+
+	// Tell the canary's status check apart from the job's
+	if _, name := mappingValue(job, "name"); name != nil && name.Kind == yaml.ScalarNode && name.Style != yaml.LiteralStyle && name.Style != yaml.FoldedStyle {
+		edit, err := scalarValueEdit(data, name, name.Value+" (slim)")
+		if err != nil {
+			return textEdit{}, err
+		}
+		edits = append(edits, edit)
+	}
+
+	block := jobBlockDeletion(data, root, jobs, jobKey)
+	end := block.offset + block.length
+	for i, e := range edits {
+		if e.offset < block.offset || e.offset+e.length > end {
+			return textEdit{}, fmt.Errorf("job %s cannot be copied: line %d is outside of its block", jobID, jobKey.Line)
+		}
+		edits[i].offset -= block.offset
+	}
+	text := string(applyEdits(data[block.offset:end], edits))
+	if end == len(data) && !strings.HasSuffix(string(data), "\n") {
+		text = newline(data) + text
+	}
+	return textEdit{offset: end, text: text}, nil
+}
@@ -0,0 +1,168 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddCanaryJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		jobIDs   []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "job followed by another job",
+			content: `jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest # default runner
+    steps:
+      - run: make
+
+  # Runs after the build
+  deploy:
+    needs: build
+    runs-on: ubuntu-latest
+`,
+			jobIDs: []string{"build"},
+			expected: `jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest # default runner
+    steps:
+      - run: make
+
+  build-slim:
+    name: Build (slim)
+    continue-on-error: true
+    runs-on: ubuntu-slim # default runner
+    steps:
+      - run: make
+
+  # Runs after the build
+  deploy:
+    needs: build
+    runs-on: ubuntu-latest
+`,
+		},
+		{
+			name: "last job without a trailing newline",
+			content: `on: push
+jobs:
+    test:
+        runs-on: "ubuntu-latest"
+        continue-on-error: false
+        steps:
+            - run: make test`,
+			jobIDs: []string{"test"},
+			expected: `on: push
+jobs:
+    test:
+        runs-on: "ubuntu-latest"
+        continue-on-error: false
+        steps:
+            - run: make test
+    test-slim:
+        runs-on: "ubuntu-slim"
+        continue-on-error: true
+        steps:
+            - run: make test`,
+		},
+		{
+			name:     "CRLF line endings and a top-level key after the jobs",
+			content:  "jobs:\r\n  lint:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: make lint\r\n\r\n# Cancel superseded runs\r\nconcurrency: ci\r\n",
+			jobIDs:   []string{"lint"},
+			expected: "jobs:\r\n  lint:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: make lint\r\n\r\n  lint-slim:\r\n    continue-on-error: true\r\n    runs-on: ubuntu-slim\r\n    steps:\r\n      - run: make lint\r\n\r\n# Cancel superseded runs\r\nconcurrency: ci\r\n",
+		},
+		{
+			name: "adjacent jobs",
+			content: `jobs:
+  a:
+    runs-on: ubuntu-latest
+  b:
+    runs-on: ubuntu-latest
+`,
+			jobIDs: []string{"a", "b"},
+			expected: `jobs:
+  a:
+    runs-on: ubuntu-latest
+  a-slim:
+    continue-on-error: true
+    runs-on: ubuntu-slim
+  b:
+    runs-on: ubuntu-latest
+  b-slim:
+    continue-on-error: true
+    runs-on: ubuntu-slim
+`,
+		},
+		{
+			name: "canary already exists",
+			content: `jobs:
+  build:
+    runs-on: ubuntu-latest
+  build-slim:
+    runs-on: ubuntu-slim
+    continue-on-error: true
+`,
+			jobIDs:  []string{"build"},
+			wantErr: true,
+		},
+		{
+			name: "runs-on is not ubuntu-latest",
+			content: `jobs:
+  build:
+    runs-on: [self-hosted, linux]
+`,
+			jobIDs:  []string{"build"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "workflow.yml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			failed, err := AddCanaryJobs(filePath, tt.jobIDs, "ubuntu-slim")
+			if err != nil {
+				t.Fatalf("AddCanaryJobs() unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if len(failed) == 0 {
+					t.Errorf("AddCanaryJobs() expected error for %v, got none", tt.jobIDs)
+				}
+				return
+			}
+			if len(failed) > 0 {
+				t.Fatalf("AddCanaryJobs() unexpected errors: %v", failed)
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read updated file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("AddCanaryJobs() result mismatch\ngot:\n%s\nwant:\n%s", data, tt.expected)
+			}
+
+			// The canaries are found by promote
+			wf, err := LoadWorkflow(filePath)
+			if err != nil {
+				t.Fatalf("LoadWorkflow() unexpected error: %v", err)
+			}
+			for _, jobID := range tt.jobIDs {
+				canary := wf.Jobs[jobID+CanarySuffix]
+				if canary == nil || !canary.IsUbuntuSlim() || !canary.ContinuesOnError() {
+					t.Errorf("job %s%s = %+v, want a canary on ubuntu-slim", jobID, CanarySuffix, canary)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrModified is returned by ApplyChanges when the file no longer has the
+// content the changes were computed from
+var ErrModified = errors.New("file has changed since the changes were planned")
+
+// Change is a replacement of the value Old with New at a 1-based line and
+// column of a workflow file, computed ahead of time by PlanRunnerLabel so that
+// it can be reviewed before ApplyChanges makes it
+type Change struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+// ContentHash returns the hex-encoded SHA-256 of a workflow file's content
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// PlanRunnerLabel returns the changes ReplaceRunnerLabel would make to the
+// workflow content data, keyed by job ID, without modifying anything. Jobs
+// whose runs-on cannot be edited are returned with their error instead.
+func PlanRunnerLabel(data []byte, jobIDs []string, from, to string) (map[string][]Change, map[string]error, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	changes := make(map[string][]Change)
+	failed := make(map[string]error)
+	for _, jobID := range jobIDs {
+		edits, err := runnerLabelEdits(data, root, jobID, from, to)
+		if err != nil {
+			failed[jobID] = err
+			continue
+		}
+		for _, e := range edits {
+			line, column := offsetPosition(data, e.offset)
+			changes[jobID] = append(changes[jobID], Change{
+				Line:   line,
+				Column: column,
+				Old:    string(data[e.offset : e.offset+e.length]),
+				New:    e.text,
+			})
+		}
+	}
+	return changes, failed, nil
+}
+
+// ApplyChanges makes changes planned by PlanRunnerLabel to a workflow file,
+// only if its content still has the SHA-256 hash (see ContentHash) it had
+// when they were planned; otherwise ErrModified is returned and the file is
+// left unchanged. The file is replaced atomically.
+func ApplyChanges(filePath, hash string, changes []Change) error {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	if ContentHash(data) != hash {
+		return fmt.Errorf("%s: %w", filePath, ErrModified)
+	}
+
+	edits := make([]textEdit, 0, len(changes))
+	for _, c := range changes {
+		offset, err := nodeOffset(data, &yaml.Node{Line: c.Line, Column: c.Column})
+		if err != nil {
+			return fmt.Errorf("invalid change in %s: %w", filePath, err)
+		}
+		if !bytes.HasPrefix(data[offset:], []byte(c.Old)) {
+			return fmt.Errorf("invalid change in %s: %q not found at line %d, column %d", filePath, c.Old, c.Line, c.Column)
+		}
+		edits = append(edits, textEdit{offset: offset, length: len(c.Old), text: c.New})
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return writeFileAtomic(filePath, applyEdits(data, edits))
+}
+
+// offsetPosition converts a byte offset in data into a 1-based line and
+// column, counting columns in characters like yaml.v3 (see nodeOffset)
+func offsetPosition(data []byte, offset int) (int, int) {
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return line, 1 + utf8.RuneCount(data[lineStart:offset])
+}
@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanRunnerLabel_ApplyChanges(t *testing.T) {
+	original := "# ジョブ\njobs:\n  lint:\n    runs-on: \"ubuntu-latest\" # runner\n  test:\n    runs-on: [self-hosted, ubuntu-latest]\n  docker:\n    runs-on: windows-latest\n"
+	want := "# ジョブ\njobs:\n  lint:\n    runs-on: \"ubuntu-slim\" # runner\n  test:\n    runs-on: [self-hosted, ubuntu-slim]\n  docker:\n    runs-on: windows-latest\n"
+
+	changes, failed, err := PlanRunnerLabel([]byte(original), []string{"lint", "test", "docker"}, "ubuntu-latest", "ubuntu-slim")
+	if err != nil {
+		t.Fatalf("PlanRunnerLabel() unexpected error: %v", err)
+	}
+	if failed["docker"] == nil || len(failed) != 1 {
+		t.Errorf("PlanRunnerLabel() failed = %v, want only docker", failed)
+	}
+	wantLint := Change{Line: 4, Column: 15, Old: "ubuntu-latest", New: "ubuntu-slim"}
+	if len(changes["lint"]) != 1 || changes["lint"][0] != wantLint {
+		t.Errorf("PlanRunnerLabel() lint changes = %+v, want %+v", changes["lint"], wantLint)
+	}
+	if len(changes["test"]) != 1 {
+		t.Fatalf("PlanRunnerLabel() test changes = %+v, want 1 change", changes["test"])
+	}
+
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	hash := ContentHash([]byte(original))
+	planned := append(changes["lint"], changes["test"]...)
+	if err := ApplyChanges(path, hash, planned); err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("ApplyChanges() result =\n%s\nwant:\n%s", got, want)
+	}
+
+	// The file no longer has the planned content
+	if err := ApplyChanges(path, hash, planned); !errors.Is(err, ErrModified) {
+		t.Errorf("ApplyChanges() of a modified file error = %v, want ErrModified", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("ApplyChanges() modified the file after failing:\n%s", got)
+	}
+}
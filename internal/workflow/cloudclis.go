@@ -0,0 +1,42 @@
+package workflow
+
+// cloudCLIs are the heavyweight cloud and infrastructure CLIs preinstalled on
+// ubuntu-latest. Jobs relying on them are the most common breakage after a
+// migration, as deployment jobs rarely install the CLIs they call.
+var cloudCLIs = map[string]bool{
+	"aws":       true,
+	"az":        true,
+	"azcopy":    true,
+	"bicep":     true,
+	"bq":        true,
+	"eksctl":    true,
+	"gcloud":    true,
+	"gsutil":    true,
+	"helm":      true,
+	"kind":      true,
+	"kubectl":   true,
+	"kustomize": true,
+	"minikube":  true,
+	"oc":        true,
+	"packer":    true,
+	"pulumi":    true,
+	"sam":       true,
+	"terraform": true,
+}
+
+// IsCloudCLI reports whether cmd is a cloud CLI preinstalled on ubuntu-latest
+// but missing in ubuntu-slim
+func IsCloudCLI(cmd string) bool {
+	return cloudCLIs[cmd] && IsMissingInSlim(cmd)
+}
+
+// CloudCLIEvidence returns the first use of each cloud CLI (e.g., aws,
+// kubectl, terraform) missing in ubuntu-slim that is neither provided by a
+// setup action nor installed earlier in the job. Pattern holds the command
+// name. These commands are also reported by MissingCommandEvidence.
+func (j *Job) CloudCLIEvidence() []Evidence {
+	if !j.IsUbuntuLatest() {
+		return nil
+	}
+	return j.uninstalledCommandEvidence(byName(IsCloudCLI))
+}
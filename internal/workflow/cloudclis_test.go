@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_CloudCLIEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *Job
+		want []string
+	}{
+		{
+			name: "preinstalled cloud CLIs",
+			job: &Job{RunsOn: "ubuntu-latest", Steps: []Step{
+				{Run: "gcloud auth configure-docker\nlsof -i :8080"},
+				{Run: "kubectl apply -f k8s/\nhelm upgrade --install app ./chart"},
+			}},
+			want: []string{"gcloud", "kubectl", "helm"},
+		},
+		{
+			name: "installed with setup actions",
+			job: &Job{RunsOn: "ubuntu-latest", Steps: []Step{
+				{Uses: "azure/setup-kubectl@v4"},
+				{Uses: "google-github-actions/setup-gcloud@v2"},
+				{Run: "gcloud container clusters get-credentials prod\nkubectl rollout status deploy/app"},
+			}},
+		},
+		{
+			name: "non-ubuntu-latest runner",
+			job:  &Job{RunsOn: "ubuntu-22.04", Steps: []Step{{Run: "kubectl get pods"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.job.CloudCLIEvidence() {
+				got = append(got, e.Pattern)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CloudCLIEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
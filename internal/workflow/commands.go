@@ -1,3982 +1,294 @@
 package workflow
 
-// ubuntuLatestCommands is a map of all commands available in ubuntu-latest runner.
-// This map is generated from ubuntu-latest runner's `compgen -c` command output.
-var ubuntuLatestCommands = map[string]bool{
-	"!":                                  true,
-	".":                                  true,
-	"7z":                                 true,
-	"7za":                                true,
-	"7zr":                                true,
-	":":                                  true,
-	"FileCheck-16":                       true,
-	"FileCheck-17":                       true,
-	"FileCheck-18":                       true,
-	"ModemManager":                       true,
-	"NF":                                 true,
-	"UnicodeNameMappingGenerator-16":     true,
-	"UnicodeNameMappingGenerator-17":     true,
-	"UnicodeNameMappingGenerator-18":     true,
-	"VGAuthService":                      true,
-	"Xvfb":                               true,
-	"[":                                  true,
-	"[[":                                 true,
-	"]]":                                 true,
-	"a2disconf":                          true,
-	"a2dismod":                           true,
-	"a2dissite":                          true,
-	"a2enconf":                           true,
-	"a2enmod":                            true,
-	"a2ensite":                           true,
-	"a2query":                            true,
-	"aa-enabled":                         true,
-	"aa-exec":                            true,
-	"aa-features-abi":                    true,
-	"aa-load":                            true,
-	"aa-remove-unknown":                  true,
-	"aa-status":                          true,
-	"aa-teardown":                        true,
-	"ab":                                 true,
-	"accessdb":                           true,
-	"aclocal":                            true,
-	"aclocal-1.16":                       true,
-	"acpidbg":                            true,
-	"activate-global-python-argcomplete": true,
-	"add-apt-repository":                 true,
-	"add-shell":                          true,
-	"addgnupghome":                       true,
-	"addgroup":                           true,
-	"addpart":                            true,
-	"addr2line":                          true,
-	"adduser":                            true,
-	"agentxtrap":                         true,
-	"agetty":                             true,
-	"alias":                              true,
-	"amdgpu-arch-16":                     true,
-	"amdgpu-arch-17":                     true,
-	"amdgpu-arch-18":                     true,
-	"analyze-build-16":                   true,
-	"analyze-build-17":                   true,
-	"analyze-build-18":                   true,
-	"ansible":                            true,
-	"ansible-config":                     true,
-	"ansible-console":                    true,
-	"ansible-doc":                        true,
-	"ansible-galaxy":                     true,
-	"ansible-inventory":                  true,
-	"ansible-playbook":                   true,
-	"ansible-pull":                       true,
-	"ansible-test":                       true,
-	"ansible-vault":                      true,
-	"ant":                                true,
-	"anthoscli":                          true,
-	"apache2":                            true,
-	"apache2ctl":                         true,
-	"apachectl":                          true,
-	"apparmor_parser":                    true,
-	"apparmor_status":                    true,
-	"appletviewer":                       true,
-	"applygnupgdefaults":                 true,
-	"apport-bug":                         true,
-	"apport-cli":                         true,
-	"apport-collect":                     true,
-	"apport-unpack":                      true,
-	"appstreamcli":                       true,
-	"apropos":                            true,
-	"apt":                                true,
-	"apt-add-repository":                 true,
-	"apt-cache":                          true,
-	"apt-cdrom":                          true,
-	"apt-config":                         true,
-	"apt-extracttemplates":               true,
-	"apt-ftparchive":                     true,
-	"apt-get":                            true,
-	"apt-key":                            true,
-	"apt-mark":                           true,
-	"apt-sortpkgs":                       true,
-	"ar":                                 true,
-	"arch":                               true,
-	"argdist-bpfcc":                      true,
-	"aria2c":                             true,
-	"arp":                                true,
-	"arpd":                               true,
-	"arptables":                          true,
-	"arptables-nft":                      true,
-	"arptables-nft-restore":              true,
-	"arptables-nft-save":                 true,
-	"arptables-restore":                  true,
-	"arptables-save":                     true,
-	"as":                                 true,
-	"asan_symbolize-16":                  true,
-	"asan_symbolize-17":                  true,
-	"asan_symbolize-18":                  true,
-	"aspell-autobuildhash":               true,
-	"autoconf":                           true,
-	"autoheader":                         true,
-	"autom4te":                           true,
-	"automake":                           true,
-	"automake-1.16":                      true,
-	"automat-visualize3":                 true,
-	"autoreconf":                         true,
-	"autoscan":                           true,
-	"autoupdate":                         true,
-	"awk":                                true,
-	"aws":                                true,
-	"aws_completer":                      true,
-	"az":                                 true,
-	"azcopy":                             true,
-	"azcopy10":                           true,
-	"b2sum":                              true,
-	"badblocks":                          true,
-	"base32":                             true,
-	"base64":                             true,
-	"basename":                           true,
-	"basenc":                             true,
-	"bash":                               true,
-	"bashbug":                            true,
-	"bashreadline-bpfcc":                 true,
-	"bashreadline.bt":                    true,
-	"bazel":                              true,
-	"bazelisk":                           true,
-	"bc":                                 true,
-	"bcache-super-show":                  true,
-	"bg":                                 true,
-	"bicep":                              true,
-	"bin-proxy":                          true,
-	"bind":                               true,
-	"bindsnoop-bpfcc":                    true,
-	"biolatency-bpfcc":                   true,
-	"biolatency-kp.bt":                   true,
-	"biolatency.bt":                      true,
-	"biolatpcts-bpfcc":                   true,
-	"biopattern-bpfcc":                   true,
-	"biosdecode":                         true,
-	"biosnoop-bpfcc":                     true,
-	"biosnoop.bt":                        true,
-	"biostacks.bt":                       true,
-	"biotop-bpfcc":                       true,
-	"bison":                              true,
-	"bison.yacc":                         true,
-	"bitesize-bpfcc":                     true,
-	"bitesize.bt":                        true,
-	"blkdeactivate":                      true,
-	"blkdiscard":                         true,
-	"blkid":                              true,
-	"blkzone":                            true,
-	"blockdev":                           true,
-	"boltctl":                            true,
-	"bpflist-bpfcc":                      true,
-	"bpftool":                            true,
-	"bpftrace":                           true,
-	"bpftrace-aotrt":                     true,
-	"bq":                                 true,
-	"break":                              true,
-	"bridge":                             true,
-	"brotli":                             true,
-	"browse":                             true,
-	"btrfs":                              true,
-	"btrfs-convert":                      true,
-	"btrfs-find-root":                    true,
-	"btrfs-image":                        true,
-	"btrfs-map-logical":                  true,
-	"btrfs-select-super":                 true,
-	"btrfsck":                            true,
-	"btrfsdist-bpfcc":                    true,
-	"btrfsslower-bpfcc":                  true,
-	"btrfstune":                          true,
-	"bugpoint-16":                        true,
-	"bugpoint-17":                        true,
-	"bugpoint-18":                        true,
-	"buildah":                            true,
-	"builtin":                            true,
-	"bundle3.2":                          true,
-	"bundler3.2":                         true,
-	"bunzip2":                            true,
-	"busctl":                             true,
-	"busybox":                            true,
-	"byobu":                              true,
-	"byobu-config":                       true,
-	"byobu-ctrl-a":                       true,
-	"byobu-disable":                      true,
-	"byobu-disable-prompt":               true,
-	"byobu-enable":                       true,
-	"byobu-enable-prompt":                true,
-	"byobu-export":                       true,
-	"byobu-janitor":                      true,
-	"byobu-keybindings":                  true,
-	"byobu-launch":                       true,
-	"byobu-launcher":                     true,
-	"byobu-launcher-install":             true,
-	"byobu-launcher-uninstall":           true,
-	"byobu-layout":                       true,
-	"byobu-prompt":                       true,
-	"byobu-quiet":                        true,
-	"byobu-reconnect-sockets":            true,
-	"byobu-screen":                       true,
-	"byobu-select-backend":               true,
-	"byobu-select-profile":               true,
-	"byobu-select-session":               true,
-	"byobu-shell":                        true,
-	"byobu-silent":                       true,
-	"byobu-status":                       true,
-	"byobu-status-detail":                true,
-	"byobu-tmux":                         true,
-	"byobu-ugraph":                       true,
-	"byobu-ulevel":                       true,
-	"bzcat":                              true,
-	"bzcmp":                              true,
-	"bzdiff":                             true,
-	"bzegrep":                            true,
-	"bzexe":                              true,
-	"bzfgrep":                            true,
-	"bzgrep":                             true,
-	"bzip2":                              true,
-	"bzip2recover":                       true,
-	"bzless":                             true,
-	"bzmore":                             true,
-	"c++":                                true,
-	"c++filt":                            true,
-	"c-index-test-16":                    true,
-	"c-index-test-17":                    true,
-	"c-index-test-18":                    true,
-	"c89":                                true,
-	"c89-gcc":                            true,
-	"c99":                                true,
-	"c99-gcc":                            true,
-	"c_rehash":                           true,
-	"cabal":                              true,
-	"cabal-3.16.0.0":                     true,
-	"cache_check":                        true,
-	"cache_dump":                         true,
-	"cache_metadata_size":                true,
-	"cache_repair":                       true,
-	"cache_restore":                      true,
-	"cache_writeback":                    true,
-	"cachestat-bpfcc":                    true,
-	"cachetop-bpfcc":                     true,
-	"caller":                             true,
-	"capable-bpfcc":                      true,
-	"capable.bt":                         true,
-	"capsh":                              true,
-	"captoinfo":                          true,
-	"cargo":                              true,
-	"cargo-clippy":                       true,
-	"cargo-fmt":                          true,
-	"cargo-miri":                         true,
-	"case":                               true,
-	"cat":                                true,
-	"catatonit":                          true,
-	"catman":                             true,
-	"cc":                                 true,
-	"ccache-swig":                        true,
-	"ccache-swig4.0":                     true,
-	"ccmake":                             true,
-	"cd":                                 true,
-	"certutil":                           true,
-	"cfdisk":                             true,
-	"cftp3":                              true,
-	"cgdisk":                             true,
-	"cgi-fcgi":                           true,
-	"chacl":                              true,
-	"chage":                              true,
-	"chardet":                            true,
-	"chardetect":                         true,
-	"chattr":                             true,
-	"chcon":                              true,
-	"chcpu":                              true,
-	"check_forensic":                     true,
-	"checkgid":                           true,
-	"chfn":                               true,
-	"chg":                                true,
-	"chgpasswd":                          true,
-	"chgrp":                              true,
-	"chktest":                            true,
-	"chmem":                              true,
-	"chmod":                              true,
-	"choom":                              true,
-	"chown":                              true,
-	"chpasswd":                           true,
-	"chromedriver":                       true,
-	"chromium":                           true,
-	"chromium-browser":                   true,
-	"chronyc":                            true,
-	"chronyd":                            true,
-	"chroot":                             true,
-	"chrt":                               true,
-	"chsh":                               true,
-	"chvt":                               true,
-	"cifs.idmap":                         true,
-	"cifs.upcall":                        true,
-	"cifscreds":                          true,
-	"cifsiostat":                         true,
-	"ckbcomp":                            true,
-	"ckeygen3":                           true,
-	"cksum":                              true,
-	"clang":                              true,
-	"clang++":                            true,
-	"clang++-16":                         true,
-	"clang++-17":                         true,
-	"clang++-18":                         true,
-	"clang-16":                           true,
-	"clang-17":                           true,
-	"clang-18":                           true,
-	"clang-apply-replacements-16":        true,
-	"clang-apply-replacements-17":        true,
-	"clang-apply-replacements-18":        true,
-	"clang-change-namespace-16":          true,
-	"clang-change-namespace-17":          true,
-	"clang-change-namespace-18":          true,
-	"clang-check-16":                     true,
-	"clang-check-17":                     true,
-	"clang-check-18":                     true,
-	"clang-cl-16":                        true,
-	"clang-cl-17":                        true,
-	"clang-cl-18":                        true,
-	"clang-cpp-16":                       true,
-	"clang-cpp-17":                       true,
-	"clang-cpp-18":                       true,
-	"clang-doc-16":                       true,
-	"clang-doc-17":                       true,
-	"clang-doc-18":                       true,
-	"clang-extdef-mapping-16":            true,
-	"clang-extdef-mapping-17":            true,
-	"clang-extdef-mapping-18":            true,
-	"clang-format":                       true,
-	"clang-format-16":                    true,
-	"clang-format-17":                    true,
-	"clang-format-18":                    true,
-	"clang-format-diff-16":               true,
-	"clang-format-diff-17":               true,
-	"clang-format-diff-18":               true,
-	"clang-include-cleaner-16":           true,
-	"clang-include-cleaner-17":           true,
-	"clang-include-cleaner-18":           true,
-	"clang-include-fixer-16":             true,
-	"clang-include-fixer-17":             true,
-	"clang-include-fixer-18":             true,
-	"clang-linker-wrapper-16":            true,
-	"clang-linker-wrapper-17":            true,
-	"clang-linker-wrapper-18":            true,
-	"clang-move-16":                      true,
-	"clang-move-17":                      true,
-	"clang-move-18":                      true,
-	"clang-offload-bundler-16":           true,
-	"clang-offload-bundler-17":           true,
-	"clang-offload-bundler-18":           true,
-	"clang-offload-packager-16":          true,
-	"clang-offload-packager-17":          true,
-	"clang-offload-packager-18":          true,
-	"clang-pseudo-16":                    true,
-	"clang-pseudo-17":                    true,
-	"clang-pseudo-18":                    true,
-	"clang-query-16":                     true,
-	"clang-query-17":                     true,
-	"clang-query-18":                     true,
-	"clang-refactor-16":                  true,
-	"clang-refactor-17":                  true,
-	"clang-refactor-18":                  true,
-	"clang-rename-16":                    true,
-	"clang-rename-17":                    true,
-	"clang-rename-18":                    true,
-	"clang-reorder-fields-16":            true,
-	"clang-reorder-fields-17":            true,
-	"clang-reorder-fields-18":            true,
-	"clang-repl-16":                      true,
-	"clang-repl-17":                      true,
-	"clang-repl-18":                      true,
-	"clang-scan-deps-16":                 true,
-	"clang-scan-deps-17":                 true,
-	"clang-scan-deps-18":                 true,
-	"clang-tblgen-16":                    true,
-	"clang-tblgen-17":                    true,
-	"clang-tblgen-18":                    true,
-	"clang-tidy":                         true,
-	"clang-tidy-16":                      true,
-	"clang-tidy-17":                      true,
-	"clang-tidy-18":                      true,
-	"clang-tidy-diff-16.py":              true,
-	"clang-tidy-diff-17.py":              true,
-	"clang-tidy-diff-18.py":              true,
-	"clear":                              true,
-	"clear_console":                      true,
-	"clhsdb":                             true,
-	"clippy-driver":                      true,
-	"cloud-id":                           true,
-	"cloud-init":                         true,
-	"cloud-init-per":                     true,
-	"clusterdb":                          true,
-	"cmake":                              true,
-	"cmake-gui":                          true,
-	"cmp":                                true,
-	"cmsutil":                            true,
-	"cobjnew-bpfcc":                      true,
-	"codepage":                           true,
-	"col":                                true,
-	"col1":                               true,
-	"col2":                               true,
-	"col3":                               true,
-	"col4":                               true,
-	"col5":                               true,
-	"col6":                               true,
-	"col7":                               true,
-	"col8":                               true,
-	"col9":                               true,
-	"colcrt":                             true,
-	"colrm":                              true,
-	"column":                             true,
-	"comm":                               true,
-	"command":                            true,
-	"commander":                          true,
-	"compactsnoop-bpfcc":                 true,
-	"compgen":                            true,
-	"complete":                           true,
-	"compopt":                            true,
-	"composer":                           true,
-	"conch3":                             true,
-	"conda":                              true,
-	"conmon":                             true,
-	"console":                            true,
-	"containerd":                         true,
-	"containerd-shim":                    true,
-	"containerd-shim-runc-v1":            true,
-	"containerd-shim-runc-v2":            true,
-	"continue":                           true,
-	"coproc":                             true,
-	"coredumpctl":                        true,
-	"corelist":                           true,
-	"corepack":                           true,
-	"count-16":                           true,
-	"count-17":                           true,
-	"count-18":                           true,
-	"cp":                                 true,
-	"cpack":                              true,
-	"cpan":                               true,
-	"cpan5.38-x86_64-linux-gnu":          true,
-	"cpgr":                               true,
-	"cpio":                               true,
-	"cpp":                                true,
-	"cpp-12":                             true,
-	"cpp-13":                             true,
-	"cpp-14":                             true,
-	"cppw":                               true,
-	"cpudist-bpfcc":                      true,
-	"cpupower":                           true,
-	"cpuunclaimed-bpfcc":                 true,
-	"cpuwalk.bt":                         true,
-	"createdb":                           true,
-	"createlang":                         true,
-	"createuser":                         true,
-	"criticalstat-bpfcc":                 true,
-	"crlutil":                            true,
-	"cron":                               true,
-	"crontab":                            true,
-	"crun":                               true,
-	"cryptdisks_start":                   true,
-	"cryptdisks_stop":                    true,
-	"cryptsetup":                         true,
-	"csplit":                             true,
-	"ctail":                              true,
-	"ctest":                              true,
-	"ctr":                                true,
-	"ctrlaltdel":                         true,
-	"ctstat":                             true,
-	"curl":                               true,
-	"cut":                                true,
-	"cvtsudoers":                         true,
-	"dash":                               true,
-	"date":                               true,
-	"dbslower-bpfcc":                     true,
-	"dbstat-bpfcc":                       true,
-	"dbus-cleanup-sockets":               true,
-	"dbus-daemon":                        true,
-	"dbus-monitor":                       true,
-	"dbus-run-session":                   true,
-	"dbus-send":                          true,
-	"dbus-update-activation-environment": true,
-	"dbus-uuidgen":                       true,
-	"dbxtool":                            true,
-	"dcb":                                true,
-	"dcsnoop-bpfcc":                      true,
-	"dcsnoop.bt":                         true,
-	"dcstat-bpfcc":                       true,
-	"dd":                                 true,
-	"deadlock-bpfcc":                     true,
-	"deallocvt":                          true,
-	"deb-systemd-helper":                 true,
-	"deb-systemd-invoke":                 true,
-	"debconf":                            true,
-	"debconf-apt-progress":               true,
-	"debconf-communicate":                true,
-	"debconf-copydb":                     true,
-	"debconf-escape":                     true,
-	"debconf-set-selections":             true,
-	"debconf-show":                       true,
-	"debian-distro-info":                 true,
-	"debugedit":                          true,
-	"debugfs":                            true,
-	"declare":                            true,
-	"delgroup":                           true,
-	"delpart":                            true,
-	"deluser":                            true,
-	"delv":                               true,
-	"depmod":                             true,
-	"derb":                               true,
-	"derdump":                            true,
-	"devlink":                            true,
-	"df":                                 true,
-	"dh_autotools-dev_restoreconfig":     true,
-	"dh_autotools-dev_updateconfig":      true,
-	"dh_bash-completion":                 true,
-	"dh_installtex":                      true,
-	"dh_installxmlcatalogs":              true,
-	"dhcpcd":                             true,
-	"diagtool-16":                        true,
-	"diagtool-17":                        true,
-	"diagtool-18":                        true,
-	"diff":                               true,
-	"diff3":                              true,
-	"dig":                                true,
-	"dir":                                true,
-	"dircolors":                          true,
-	"dirmngr":                            true,
-	"dirmngr-client":                     true,
-	"dirname":                            true,
-	"dirs":                               true,
-	"dirtop-bpfcc":                       true,
-	"disown":                             true,
-	"distro-info":                        true,
-	"dmesg":                              true,
-	"dmeventd":                           true,
-	"dmidecode":                          true,
-	"dmsetup":                            true,
-	"dmstats":                            true,
-	"dnsdomainname":                      true,
-	"do":                                 true,
-	"do-release-upgrade":                 true,
-	"docker":                             true,
-	"docker-credential-ecr-login":        true,
-	"docker-credential-gcloud":           true,
-	"docker-proxy":                       true,
-	"dockerd":                            true,
-	"domainname":                         true,
-	"done":                               true,
-	"dosfsck":                            true,
-	"dosfslabel":                         true,
-	"dotenv":                             true,
-	"dotnet":                             true,
-	"dpkg":                               true,
-	"dpkg-architecture":                  true,
-	"dpkg-buildapi":                      true,
-	"dpkg-buildflags":                    true,
-	"dpkg-buildpackage":                  true,
-	"dpkg-buildtree":                     true,
-	"dpkg-checkbuilddeps":                true,
-	"dpkg-deb":                           true,
-	"dpkg-distaddfile":                   true,
-	"dpkg-divert":                        true,
-	"dpkg-genbuildinfo":                  true,
-	"dpkg-genchanges":                    true,
-	"dpkg-gencontrol":                    true,
-	"dpkg-gensymbols":                    true,
-	"dpkg-maintscript-helper":            true,
-	"dpkg-mergechangelogs":               true,
-	"dpkg-name":                          true,
-	"dpkg-parsechangelog":                true,
-	"dpkg-preconfigure":                  true,
-	"dpkg-query":                         true,
-	"dpkg-realpath":                      true,
-	"dpkg-reconfigure":                   true,
-	"dpkg-scanpackages":                  true,
-	"dpkg-scansources":                   true,
-	"dpkg-shlibdeps":                     true,
-	"dpkg-source":                        true,
-	"dpkg-split":                         true,
-	"dpkg-statoverride":                  true,
-	"dpkg-trigger":                       true,
-	"dpkg-vendor":                        true,
-	"dropdb":                             true,
-	"droplang":                           true,
-	"dropuser":                           true,
-	"drsnoop-bpfcc":                      true,
-	"dsymutil-16":                        true,
-	"dsymutil-17":                        true,
-	"dsymutil-18":                        true,
-	"du":                                 true,
-	"dumpe2fs":                           true,
-	"dumpkeys":                           true,
-	"dwp":                                true,
-	"e2freefrag":                         true,
-	"e2fsck":                             true,
-	"e2image":                            true,
-	"e2label":                            true,
-	"e2mmpstatus":                        true,
-	"e2scrub":                            true,
-	"e2scrub_all":                        true,
-	"e2undo":                             true,
-	"e4crypt":                            true,
-	"e4defrag":                           true,
-	"eatmydata":                          true,
-	"ebtables":                           true,
-	"ebtables-nft":                       true,
-	"ebtables-nft-restore":               true,
-	"ebtables-nft-save":                  true,
-	"ebtables-restore":                   true,
-	"ebtables-save":                      true,
-	"ebtables-translate":                 true,
-	"ec2metadata":                        true,
-	"echo":                               true,
-	"ed":                                 true,
-	"editor":                             true,
-	"efibootdump":                        true,
-	"efibootmgr":                         true,
-	"egrep":                              true,
-	"eject":                              true,
-	"elfedit":                            true,
-	"elif":                               true,
-	"else":                               true,
-	"enable":                             true,
-	"enc2xs":                             true,
-	"encguess":                           true,
-	"encode_keychange":                   true,
-	"env":                                true,
-	"env_parallel":                       true,
-	"env_parallel.bash":                  true,
-	"env_parallel.csh":                   true,
-	"env_parallel.dash":                  true,
-	"env_parallel.fish":                  true,
-	"env_parallel.ksh":                   true,
-	"env_parallel.mksh":                  true,
-	"env_parallel.sh":                    true,
-	"env_parallel.tcsh":                  true,
-	"env_parallel.zsh":                   true,
-	"envsubst":                           true,
-	"ephemeral-disk-warning":             true,
-	"eqn":                                true,
-	"era_check":                          true,
-	"era_dump":                           true,
-	"era_invalidate":                     true,
-	"era_restore":                        true,
-	"erb":                                true,
-	"erb3.2":                             true,
-	"esac":                               true,
-	"escapesrc":                          true,
-	"ethtool":                            true,
-	"eval":                               true,
-	"ex":                                 true,
-	"exec":                               true,
-	"execsnoop-bpfcc":                    true,
-	"execsnoop.bt":                       true,
-	"exit":                               true,
-	"exitsnoop-bpfcc":                    true,
-	"expand":                             true,
-	"expiry":                             true,
-	"export":                             true,
-	"expr":                               true,
-	"ext4dist-bpfcc":                     true,
-	"ext4slower-bpfcc":                   true,
-	"extcheck":                           true,
-	"f77":                                true,
-	"f95":                                true,
-	"factor":                             true,
-	"faillock":                           true,
-	"faillog":                            true,
-	"faked-sysv":                         true,
-	"faked-tcp":                          true,
-	"fakeroot":                           true,
-	"fakeroot-sysv":                      true,
-	"fakeroot-tcp":                       true,
-	"fallocate":                          true,
-	"false":                              true,
-	"fastlane":                           true,
-	"fatlabel":                           true,
-	"fc":                                 true,
-	"fc-cache":                           true,
-	"fc-cat":                             true,
-	"fc-conflist":                        true,
-	"fc-list":                            true,
-	"fc-match":                           true,
-	"fc-pattern":                         true,
-	"fc-query":                           true,
-	"fc-scan":                            true,
-	"fc-validate":                        true,
-	"fcgistarter":                        true,
-	"fdisk":                              true,
-	"fg":                                 true,
-	"fgconsole":                          true,
-	"fgrep":                              true,
-	"fi":                                 true,
-	"file":                               true,
-	"filefrag":                           true,
-	"filegone-bpfcc":                     true,
-	"filelife-bpfcc":                     true,
-	"fileslower-bpfcc":                   true,
-	"filetop-bpfcc":                      true,
-	"finalrd":                            true,
-	"find":                               true,
-	"find-all-symbols-16":                true,
-	"find-all-symbols-17":                true,
-	"find-all-symbols-18":                true,
-	"find-debuginfo":                     true,
-	"findfs":                             true,
-	"findmnt":                            true,
-	"firefox":                            true,
-	"fixparts":                           true,
-	"fixproc":                            true,
-	"flex":                               true,
-	"flex++":                             true,
-	"flock":                              true,
-	"fmt":                                true,
-	"fold":                               true,
-	"for":                                true,
-	"free":                               true,
-	"fsadm":                              true,
-	"fsck":                               true,
-	"fsck.btrfs":                         true,
-	"fsck.cramfs":                        true,
-	"fsck.ext2":                          true,
-	"fsck.ext3":                          true,
-	"fsck.ext4":                          true,
-	"fsck.fat":                           true,
-	"fsck.minix":                         true,
-	"fsck.msdos":                         true,
-	"fsck.vfat":                          true,
-	"fsck.xfs":                           true,
-	"fsfreeze":                           true,
-	"fstab-decode":                       true,
-	"fstrim":                             true,
-	"ftp":                                true,
-	"funccount-bpfcc":                    true,
-	"funcinterval-bpfcc":                 true,
-	"funclatency-bpfcc":                  true,
-	"funcslower-bpfcc":                   true,
-	"function":                           true,
-	"funzip":                             true,
-	"fuse-overlayfs":                     true,
-	"fuser":                              true,
-	"fusermount":                         true,
-	"fusermount3":                        true,
-	"fwupdmgr":                           true,
-	"fwupdtool":                          true,
-	"g++":                                true,
-	"g++-12":                             true,
-	"g++-13":                             true,
-	"g++-14":                             true,
-	"gapplication":                       true,
-	"gawk":                               true,
-	"gawkbug":                            true,
-	"gcc":                                true,
-	"gcc-12":                             true,
-	"gcc-13":                             true,
-	"gcc-14":                             true,
-	"gcc-ar":                             true,
-	"gcc-ar-12":                          true,
-	"gcc-ar-13":                          true,
-	"gcc-ar-14":                          true,
-	"gcc-nm":                             true,
-	"gcc-nm-12":                          true,
-	"gcc-nm-13":                          true,
-	"gcc-nm-14":                          true,
-	"gcc-ranlib":                         true,
-	"gcc-ranlib-12":                      true,
-	"gcc-ranlib-13":                      true,
-	"gcc-ranlib-14":                      true,
-	"gcloud":                             true,
-	"gcloud-crc32c":                      true,
-	"gcov":                               true,
-	"gcov-12":                            true,
-	"gcov-13":                            true,
-	"gcov-14":                            true,
-	"gcov-dump":                          true,
-	"gcov-dump-12":                       true,
-	"gcov-dump-13":                       true,
-	"gcov-dump-14":                       true,
-	"gcov-tool":                          true,
-	"gcov-tool-12":                       true,
-	"gcov-tool-13":                       true,
-	"gcov-tool-14":                       true,
-	"gdbus":                              true,
-	"gdisk":                              true,
-	"geckodriver":                        true,
-	"gem":                                true,
-	"gem3.2":                             true,
-	"genbrk":                             true,
-	"gencat":                             true,
-	"genccode":                           true,
-	"gencfu":                             true,
-	"gencmn":                             true,
-	"gencnval":                           true,
-	"gendict":                            true,
-	"gendiff":                            true,
-	"genl":                               true,
-	"gennorm2":                           true,
-	"genrb":                              true,
-	"gensprep":                           true,
-	"geqn":                               true,
-	"getcap":                             true,
-	"getcifsacl":                         true,
-	"getconf":                            true,
-	"getent":                             true,
-	"getfacl":                            true,
-	"gethostlatency-bpfcc":               true,
-	"gethostlatency.bt":                  true,
-	"getkeycodes":                        true,
-	"getopt":                             true,
-	"getopts":                            true,
-	"getpcaps":                           true,
-	"getsubids":                          true,
-	"gettext":                            true,
-	"gettext.sh":                         true,
-	"getty":                              true,
-	"gfortran":                           true,
-	"gfortran-12":                        true,
-	"gfortran-13":                        true,
-	"gfortran-14":                        true,
-	"gh":                                 true,
-	"ghc":                                true,
-	"ghc-9.10":                           true,
-	"ghc-9.10.3":                         true,
-	"ghc-9.12":                           true,
-	"ghc-9.12.2":                         true,
-	"ghc-pkg":                            true,
-	"ghc-pkg-9.10":                       true,
-	"ghc-pkg-9.10.3":                     true,
-	"ghc-pkg-9.12":                       true,
-	"ghc-pkg-9.12.2":                     true,
-	"ghci":                               true,
-	"ghci-9.10":                          true,
-	"ghci-9.10.3":                        true,
-	"ghci-9.12":                          true,
-	"ghci-9.12.2":                        true,
-	"ghcup":                              true,
-	"ginstall-info":                      true,
-	"gio":                                true,
-	"gio-querymodules":                   true,
-	"git":                                true,
-	"git-clang-format-16":                true,
-	"git-clang-format-17":                true,
-	"git-clang-format-18":                true,
-	"git-credential-gcloud.sh":           true,
-	"git-ftp":                            true,
-	"git-lfs":                            true,
-	"git-receive-pack":                   true,
-	"git-shell":                          true,
-	"git-upload-archive":                 true,
-	"git-upload-pack":                    true,
-	"glib-compile-schemas":               true,
-	"gmake":                              true,
-	"gnome-www-browser":                  true,
-	"go":                                 true,
-	"gofmt":                              true,
-	"gold":                               true,
-	"google-chrome":                      true,
-	"google-chrome-stable":               true,
-	"gp-archive":                         true,
-	"gp-collect-app":                     true,
-	"gp-display-html":                    true,
-	"gp-display-src":                     true,
-	"gp-display-text":                    true,
-	"gpasswd":                            true,
-	"gpg":                                true,
-	"gpg-agent":                          true,
-	"gpg-connect-agent":                  true,
-	"gpg-wks-client":                     true,
-	"gpg2":                               true,
-	"gpgconf":                            true,
-	"gpgparsemail":                       true,
-	"gpgsm":                              true,
-	"gpgsplit":                           true,
-	"gpgtar":                             true,
-	"gpgv":                               true,
-	"gpic":                               true,
-	"gprof":                              true,
-	"gprofng":                            true,
-	"gradle":                             true,
-	"grep":                               true,
-	"gresource":                          true,
-	"groff":                              true,
-	"grog":                               true,
-	"grops":                              true,
-	"grotty":                             true,
-	"groupadd":                           true,
-	"groupdel":                           true,
-	"groupmems":                          true,
-	"groupmod":                           true,
-	"groups":                             true,
-	"growpart":                           true,
-	"grpck":                              true,
-	"grpconv":                            true,
-	"grpunconv":                          true,
-	"grub-bios-setup":                    true,
-	"grub-editenv":                       true,
-	"grub-file":                          true,
-	"grub-fstest":                        true,
-	"grub-glue-efi":                      true,
-	"grub-install":                       true,
-	"grub-kbdcomp":                       true,
-	"grub-macbless":                      true,
-	"grub-menulst2cfg":                   true,
-	"grub-mkconfig":                      true,
-	"grub-mkdevicemap":                   true,
-	"grub-mkfont":                        true,
-	"grub-mkimage":                       true,
-	"grub-mklayout":                      true,
-	"grub-mknetdir":                      true,
-	"grub-mkpasswd-pbkdf2":               true,
-	"grub-mkrelpath":                     true,
-	"grub-mkrescue":                      true,
-	"grub-mkstandalone":                  true,
-	"grub-mount":                         true,
-	"grub-ntldr-img":                     true,
-	"grub-probe":                         true,
-	"grub-reboot":                        true,
-	"grub-render-label":                  true,
-	"grub-script-check":                  true,
-	"grub-set-default":                   true,
-	"grub-syslinux2cfg":                  true,
-	"grunt":                              true,
-	"gsettings":                          true,
-	"gsutil":                             true,
-	"gtbl":                               true,
-	"gtk-update-icon-cache":              true,
-	"gulp":                               true,
-	"gunzip":                             true,
-	"gzexe":                              true,
-	"gzip":                               true,
-	"h2ph":                               true,
-	"h2xs":                               true,
-	"haddock":                            true,
-	"haddock-9.10":                       true,
-	"haddock-9.10.3":                     true,
-	"haddock-9.12":                       true,
-	"haddock-9.12.2":                     true,
-	"halt":                               true,
-	"hardirqs-bpfcc":                     true,
-	"hardlink":                           true,
-	"hash":                               true,
-	"haveged":                            true,
-	"hd":                                 true,
-	"hdparm":                             true,
-	"head":                               true,
-	"helm":                               true,
-	"help":                               true,
-	"helpztags":                          true,
-	"hexdump":                            true,
-	"hg":                                 true,
-	"hg-ssh":                             true,
-	"history":                            true,
-	"hmaptool-16":                        true,
-	"hmaptool-17":                        true,
-	"hmaptool-18":                        true,
-	"host":                               true,
-	"hostid":                             true,
-	"hostname":                           true,
-	"hostnamectl":                        true,
-	"hp2ps":                              true,
-	"hp2ps-9.10":                         true,
-	"hp2ps-9.10.3":                       true,
-	"hp2ps-9.12":                         true,
-	"hp2ps-9.12.2":                       true,
-	"hpc":                                true,
-	"hpc-9.10":                           true,
-	"hpc-9.10.3":                         true,
-	"hpc-9.12":                           true,
-	"hpc-9.12.2":                         true,
-	"hsc2hs":                             true,
-	"hsc2hs-9.10":                        true,
-	"hsc2hs-9.10.3":                      true,
-	"hsc2hs-9.12":                        true,
-	"hsc2hs-9.12.2":                      true,
-	"hsdb":                               true,
-	"htcacheclean":                       true,
-	"htdbm":                              true,
-	"htdigest":                           true,
-	"htop":                               true,
-	"htpasswd":                           true,
-	"httpclient":                         true,
-	"httpserv":                           true,
-	"httxt2dbm":                          true,
-	"hv_fcopy_daemon":                    true,
-	"hv_get_dhcp_info":                   true,
-	"hv_get_dns_info":                    true,
-	"hv_kvp_daemon":                      true,
-	"hv_set_ifconfig":                    true,
-	"hv_vss_daemon":                      true,
-	"hwasan_symbolize-16":                true,
-	"hwasan_symbolize-17":                true,
-	"hwasan_symbolize-18":                true,
-	"hwe-support-status":                 true,
-	"i386":                               true,
-	"ibd2sdi":                            true,
-	"iconv":                              true,
-	"iconvconfig":                        true,
-	"icuexportdata":                      true,
-	"icuinfo":                            true,
-	"icupkg":                             true,
-	"id":                                 true,
-	"idlj":                               true,
-	"if":                                 true,
-	"ifconfig":                           true,
-	"ifnames":                            true,
-	"in":                                 true,
-	"indexer":                            true,
-	"indextool":                          true,
-	"inetutils-telnet":                   true,
-	"info":                               true,
-	"infobrowser":                        true,
-	"infocmp":                            true,
-	"infotocap":                          true,
-	"init":                               true,
-	"inject-bpfcc":                       true,
-	"innochecksum":                       true,
-	"insmod":                             true,
-	"install":                            true,
-	"install-info":                       true,
-	"install-sgmlcatalog":                true,
-	"installkernel":                      true,
-	"instmodsh":                          true,
-	"integritysetup":                     true,
-	"intercept-build-16":                 true,
-	"intercept-build-17":                 true,
-	"intercept-build-18":                 true,
-	"invoke-rc.d":                        true,
-	"ionice":                             true,
-	"iostat":                             true,
-	"ip":                                 true,
-	"ip6tables":                          true,
-	"ip6tables-apply":                    true,
-	"ip6tables-legacy":                   true,
-	"ip6tables-legacy-restore":           true,
-	"ip6tables-legacy-save":              true,
-	"ip6tables-nft":                      true,
-	"ip6tables-nft-restore":              true,
-	"ip6tables-nft-save":                 true,
-	"ip6tables-restore":                  true,
-	"ip6tables-restore-translate":        true,
-	"ip6tables-save":                     true,
-	"ip6tables-translate":                true,
-	"ipcmk":                              true,
-	"ipcrm":                              true,
-	"ipcs":                               true,
-	"ipmaddr":                            true,
-	"iptables":                           true,
-	"iptables-apply":                     true,
-	"iptables-legacy":                    true,
-	"iptables-legacy-restore":            true,
-	"iptables-legacy-save":               true,
-	"iptables-nft":                       true,
-	"iptables-nft-restore":               true,
-	"iptables-nft-save":                  true,
-	"iptables-restore":                   true,
-	"iptables-restore-translate":         true,
-	"iptables-save":                      true,
-	"iptables-translate":                 true,
-	"iptables-xml":                       true,
-	"iptunnel":                           true,
-	"irb":                                true,
-	"irb3.2":                             true,
-	"ischroot":                           true,
-	"iscsi-iname":                        true,
-	"iscsi_discovery":                    true,
-	"iscsiadm":                           true,
-	"iscsid":                             true,
-	"iscsistart":                         true,
-	"isosize":                            true,
-	"ispell-autobuildhash":               true,
-	"ispell-wrapper":                     true,
-	"jaotc":                              true,
-	"jar":                                true,
-	"jarsigner":                          true,
-	"java":                               true,
-	"javac":                              true,
-	"javacalls-bpfcc":                    true,
-	"javadoc":                            true,
-	"javaflow-bpfcc":                     true,
-	"javagc-bpfcc":                       true,
-	"javah":                              true,
-	"javaobjnew-bpfcc":                   true,
-	"javap":                              true,
-	"javastat-bpfcc":                     true,
-	"javathreads-bpfcc":                  true,
-	"jcmd":                               true,
-	"jconsole":                           true,
-	"jdb":                                true,
-	"jdeprscan":                          true,
-	"jdeps":                              true,
-	"jexec":                              true,
-	"jfr":                                true,
-	"jhat":                               true,
-	"jhsdb":                              true,
-	"jimage":                             true,
-	"jinfo":                              true,
-	"jjs":                                true,
-	"jlink":                              true,
-	"jmap":                               true,
-	"jmespath.rb":                        true,
-	"jmod":                               true,
-	"jobs":                               true,
-	"join":                               true,
-	"journalctl":                         true,
-	"jpackage":                           true,
-	"jps":                                true,
-	"jq":                                 true,
-	"jrunscript":                         true,
-	"jsadebugd":                          true,
-	"jshell":                             true,
-	"json-patch-jsondiff":                true,
-	"json_pp":                            true,
-	"json_xs":                            true,
-	"jsondiff":                           true,
-	"jsonpatch":                          true,
-	"jsonpointer":                        true,
-	"jsonschema":                         true,
-	"jspawnhelper":                       true,
-	"jstack":                             true,
-	"jstat":                              true,
-	"jstatd":                             true,
-	"julia":                              true,
-	"jwebserver":                         true,
-	"kapt":                               true,
-	"kbd_mode":                           true,
-	"kbdinfo":                            true,
-	"kbdrate":                            true,
-	"kbxutil":                            true,
-	"keep-one-running":                   true,
-	"kernel-install":                     true,
-	"key.dns_resolver":                   true,
-	"keyctl":                             true,
-	"keytool":                            true,
-	"kill":                               true,
-	"killall":                            true,
-	"killall5":                           true,
-	"killsnoop-bpfcc":                    true,
-	"killsnoop.bt":                       true,
-	"kind":                               true,
-	"klockstat-bpfcc":                    true,
-	"kmod":                               true,
-	"kmodsign":                           true,
-	"kotlin":                             true,
-	"kotlinc":                            true,
-	"kotlinc-js":                         true,
-	"kotlinc-jvm":                        true,
-	"kpartx":                             true,
-	"kubectl":                            true,
-	"kubectl-convert":                    true,
-	"kustomize":                          true,
-	"kvmexit-bpfcc":                      true,
-	"landscape-sysinfo":                  true,
-	"last":                               true,
-	"lastb":                              true,
-	"lastlog":                            true,
-	"lcf":                                true,
-	"ld":                                 true,
-	"ld.bfd":                             true,
-	"ld.gold":                            true,
-	"ld.lld-16":                          true,
-	"ld.lld-17":                          true,
-	"ld.lld-18":                          true,
-	"ld.so":                              true,
-	"ld64.lld-16":                        true,
-	"ld64.lld-17":                        true,
-	"ld64.lld-18":                        true,
-	"ldattach":                           true,
-	"ldconfig":                           true,
-	"ldconfig.real":                      true,
-	"ldd":                                true,
-	"lerna":                              true,
-	"less":                               true,
-	"lessecho":                           true,
-	"lessfile":                           true,
-	"lesskey":                            true,
-	"lesspipe":                           true,
-	"let":                                true,
-	"lex":                                true,
-	"lexgrog":                            true,
-	"libnetcfg":                          true,
-	"libtoolize":                         true,
-	"link":                               true,
-	"linux-boot-prober":                  true,
-	"linux-check-removal":                true,
-	"linux-update-symlinks":              true,
-	"linux-version":                      true,
-	"linux32":                            true,
-	"linux64":                            true,
-	"llc-16":                             true,
-	"llc-17":                             true,
-	"llc-18":                             true,
-	"llcstat-bpfcc":                      true,
-	"lld-16":                             true,
-	"lld-17":                             true,
-	"lld-18":                             true,
-	"lld-link-16":                        true,
-	"lld-link-17":                        true,
-	"lld-link-18":                        true,
-	"lldb-18":                            true,
-	"lldb-argdumper-18":                  true,
-	"lldb-dap-18":                        true,
-	"lldb-instr-18":                      true,
-	"lldb-server-18":                     true,
-	"lli-16":                             true,
-	"lli-17":                             true,
-	"lli-18":                             true,
-	"lli-child-target-16":                true,
-	"lli-child-target-17":                true,
-	"lli-child-target-18":                true,
-	"llvm-PerfectShuffle-16":             true,
-	"llvm-PerfectShuffle-17":             true,
-	"llvm-PerfectShuffle-18":             true,
-	"llvm-addr2line-16":                  true,
-	"llvm-addr2line-17":                  true,
-	"llvm-addr2line-18":                  true,
-	"llvm-ar-16":                         true,
-	"llvm-ar-17":                         true,
-	"llvm-ar-18":                         true,
-	"llvm-as-16":                         true,
-	"llvm-as-17":                         true,
-	"llvm-as-18":                         true,
-	"llvm-bcanalyzer-16":                 true,
-	"llvm-bcanalyzer-17":                 true,
-	"llvm-bcanalyzer-18":                 true,
-	"llvm-bitcode-strip-16":              true,
-	"llvm-bitcode-strip-17":              true,
-	"llvm-bitcode-strip-18":              true,
-	"llvm-c-test-16":                     true,
-	"llvm-c-test-17":                     true,
-	"llvm-c-test-18":                     true,
-	"llvm-cat-16":                        true,
-	"llvm-cat-17":                        true,
-	"llvm-cat-18":                        true,
-	"llvm-cfi-verify-16":                 true,
-	"llvm-cfi-verify-17":                 true,
-	"llvm-cfi-verify-18":                 true,
-	"llvm-config-16":                     true,
-	"llvm-config-17":                     true,
-	"llvm-config-18":                     true,
-	"llvm-cov-16":                        true,
-	"llvm-cov-17":                        true,
-	"llvm-cov-18":                        true,
-	"llvm-cvtres-16":                     true,
-	"llvm-cvtres-17":                     true,
-	"llvm-cvtres-18":                     true,
-	"llvm-cxxdump-16":                    true,
-	"llvm-cxxdump-17":                    true,
-	"llvm-cxxdump-18":                    true,
-	"llvm-cxxfilt-16":                    true,
-	"llvm-cxxfilt-17":                    true,
-	"llvm-cxxfilt-18":                    true,
-	"llvm-cxxmap-16":                     true,
-	"llvm-cxxmap-17":                     true,
-	"llvm-cxxmap-18":                     true,
-	"llvm-debuginfo-analyzer-16":         true,
-	"llvm-debuginfo-analyzer-17":         true,
-	"llvm-debuginfo-analyzer-18":         true,
-	"llvm-debuginfod-16":                 true,
-	"llvm-debuginfod-17":                 true,
-	"llvm-debuginfod-18":                 true,
-	"llvm-debuginfod-find-16":            true,
-	"llvm-debuginfod-find-17":            true,
-	"llvm-debuginfod-find-18":            true,
-	"llvm-diff-16":                       true,
-	"llvm-diff-17":                       true,
-	"llvm-diff-18":                       true,
-	"llvm-dis-16":                        true,
-	"llvm-dis-17":                        true,
-	"llvm-dis-18":                        true,
-	"llvm-dlltool-16":                    true,
-	"llvm-dlltool-17":                    true,
-	"llvm-dlltool-18":                    true,
-	"llvm-dwarfdump-16":                  true,
-	"llvm-dwarfdump-17":                  true,
-	"llvm-dwarfdump-18":                  true,
-	"llvm-dwarfutil-16":                  true,
-	"llvm-dwarfutil-17":                  true,
-	"llvm-dwarfutil-18":                  true,
-	"llvm-dwp-16":                        true,
-	"llvm-dwp-17":                        true,
-	"llvm-dwp-18":                        true,
-	"llvm-exegesis-16":                   true,
-	"llvm-exegesis-17":                   true,
-	"llvm-exegesis-18":                   true,
-	"llvm-extract-16":                    true,
-	"llvm-extract-17":                    true,
-	"llvm-extract-18":                    true,
-	"llvm-gsymutil-16":                   true,
-	"llvm-gsymutil-17":                   true,
-	"llvm-gsymutil-18":                   true,
-	"llvm-ifs-16":                        true,
-	"llvm-ifs-17":                        true,
-	"llvm-ifs-18":                        true,
-	"llvm-install-name-tool-16":          true,
-	"llvm-install-name-tool-17":          true,
-	"llvm-install-name-tool-18":          true,
-	"llvm-jitlink-16":                    true,
-	"llvm-jitlink-17":                    true,
-	"llvm-jitlink-18":                    true,
-	"llvm-jitlink-executor-16":           true,
-	"llvm-jitlink-executor-17":           true,
-	"llvm-jitlink-executor-18":           true,
-	"llvm-lib-16":                        true,
-	"llvm-lib-17":                        true,
-	"llvm-lib-18":                        true,
-	"llvm-libtool-darwin-16":             true,
-	"llvm-libtool-darwin-17":             true,
-	"llvm-libtool-darwin-18":             true,
-	"llvm-link-16":                       true,
-	"llvm-link-17":                       true,
-	"llvm-link-18":                       true,
-	"llvm-lipo-16":                       true,
-	"llvm-lipo-17":                       true,
-	"llvm-lipo-18":                       true,
-	"llvm-lto-16":                        true,
-	"llvm-lto-17":                        true,
-	"llvm-lto-18":                        true,
-	"llvm-lto2-16":                       true,
-	"llvm-lto2-17":                       true,
-	"llvm-lto2-18":                       true,
-	"llvm-mc-16":                         true,
-	"llvm-mc-17":                         true,
-	"llvm-mc-18":                         true,
-	"llvm-mca-16":                        true,
-	"llvm-mca-17":                        true,
-	"llvm-mca-18":                        true,
-	"llvm-ml-16":                         true,
-	"llvm-ml-17":                         true,
-	"llvm-ml-18":                         true,
-	"llvm-modextract-16":                 true,
-	"llvm-modextract-17":                 true,
-	"llvm-modextract-18":                 true,
-	"llvm-mt-16":                         true,
-	"llvm-mt-17":                         true,
-	"llvm-mt-18":                         true,
-	"llvm-nm-16":                         true,
-	"llvm-nm-17":                         true,
-	"llvm-nm-18":                         true,
-	"llvm-objcopy-16":                    true,
-	"llvm-objcopy-17":                    true,
-	"llvm-objcopy-18":                    true,
-	"llvm-objdump-16":                    true,
-	"llvm-objdump-17":                    true,
-	"llvm-objdump-18":                    true,
-	"llvm-opt-report-16":                 true,
-	"llvm-opt-report-17":                 true,
-	"llvm-opt-report-18":                 true,
-	"llvm-otool-16":                      true,
-	"llvm-otool-17":                      true,
-	"llvm-otool-18":                      true,
-	"llvm-pdbutil-16":                    true,
-	"llvm-pdbutil-17":                    true,
-	"llvm-pdbutil-18":                    true,
-	"llvm-profdata-16":                   true,
-	"llvm-profdata-17":                   true,
-	"llvm-profdata-18":                   true,
-	"llvm-profgen-16":                    true,
-	"llvm-profgen-17":                    true,
-	"llvm-profgen-18":                    true,
-	"llvm-ranlib-16":                     true,
-	"llvm-ranlib-17":                     true,
-	"llvm-ranlib-18":                     true,
-	"llvm-rc-16":                         true,
-	"llvm-rc-17":                         true,
-	"llvm-rc-18":                         true,
-	"llvm-readelf-16":                    true,
-	"llvm-readelf-17":                    true,
-	"llvm-readelf-18":                    true,
-	"llvm-readobj-16":                    true,
-	"llvm-readobj-17":                    true,
-	"llvm-readobj-18":                    true,
-	"llvm-readtapi-18":                   true,
-	"llvm-reduce-16":                     true,
-	"llvm-reduce-17":                     true,
-	"llvm-reduce-18":                     true,
-	"llvm-remark-size-diff-16":           true,
-	"llvm-remark-size-diff-17":           true,
-	"llvm-remarkutil-16":                 true,
-	"llvm-remarkutil-17":                 true,
-	"llvm-remarkutil-18":                 true,
-	"llvm-rtdyld-16":                     true,
-	"llvm-rtdyld-17":                     true,
-	"llvm-rtdyld-18":                     true,
-	"llvm-sim-16":                        true,
-	"llvm-sim-17":                        true,
-	"llvm-sim-18":                        true,
-	"llvm-size-16":                       true,
-	"llvm-size-17":                       true,
-	"llvm-size-18":                       true,
-	"llvm-split-16":                      true,
-	"llvm-split-17":                      true,
-	"llvm-split-18":                      true,
-	"llvm-stress-16":                     true,
-	"llvm-stress-17":                     true,
-	"llvm-stress-18":                     true,
-	"llvm-strings-16":                    true,
-	"llvm-strings-17":                    true,
-	"llvm-strings-18":                    true,
-	"llvm-strip-16":                      true,
-	"llvm-strip-17":                      true,
-	"llvm-strip-18":                      true,
-	"llvm-symbolizer-16":                 true,
-	"llvm-symbolizer-17":                 true,
-	"llvm-symbolizer-18":                 true,
-	"llvm-tapi-diff-16":                  true,
-	"llvm-tapi-diff-17":                  true,
-	"llvm-tblgen-16":                     true,
-	"llvm-tblgen-17":                     true,
-	"llvm-tblgen-18":                     true,
-	"llvm-tli-checker-16":                true,
-	"llvm-tli-checker-17":                true,
-	"llvm-tli-checker-18":                true,
-	"llvm-undname-16":                    true,
-	"llvm-undname-17":                    true,
-	"llvm-undname-18":                    true,
-	"llvm-windres-16":                    true,
-	"llvm-windres-17":                    true,
-	"llvm-windres-18":                    true,
-	"llvm-xray-16":                       true,
-	"llvm-xray-17":                       true,
-	"llvm-xray-18":                       true,
-	"ln":                                 true,
-	"lnstat":                             true,
-	"loadkeys":                           true,
-	"loads.bt":                           true,
-	"loadunimap":                         true,
-	"local":                              true,
-	"locale":                             true,
-	"locale-check":                       true,
-	"locale-gen":                         true,
-	"localectl":                          true,
-	"localedef":                          true,
-	"logger":                             true,
-	"login":                              true,
-	"loginctl":                           true,
-	"logname":                            true,
-	"logout":                             true,
-	"logresolve":                         true,
-	"logrotate":                          true,
-	"logsave":                            true,
-	"look":                               true,
-	"losetup":                            true,
-	"lowntfs-3g":                         true,
-	"ls":                                 true,
-	"lsattr":                             true,
-	"lsb_release":                        true,
-	"lsblk":                              true,
-	"lscpu":                              true,
-	"lshw":                               true,
-	"lsinitramfs":                        true,
-	"lsipc":                              true,
-	"lslocks":                            true,
-	"lslogins":                           true,
-	"lsmem":                              true,
-	"lsmod":                              true,
-	"lsns":                               true,
-	"lsof":                               true,
-	"lspci":                              true,
-	"lspgpot":                            true,
-	"lspower":                            true,
-	"lsscsi":                             true,
-	"lsusb":                              true,
-	"lsvmbus":                            true,
-	"lto-dump":                           true,
-	"lto-dump-12":                        true,
-	"lto-dump-13":                        true,
-	"lto-dump-14":                        true,
-	"luksformat":                         true,
-	"lvchange":                           true,
-	"lvconvert":                          true,
-	"lvcreate":                           true,
-	"lvdisplay":                          true,
-	"lvextend":                           true,
-	"lvm":                                true,
-	"lvmconfig":                          true,
-	"lvmdiskscan":                        true,
-	"lvmdump":                            true,
-	"lvmpolld":                           true,
-	"lvmsadc":                            true,
-	"lvmsar":                             true,
-	"lvreduce":                           true,
-	"lvremove":                           true,
-	"lvrename":                           true,
-	"lvresize":                           true,
-	"lvs":                                true,
-	"lvscan":                             true,
-	"lxc":                                true,
-	"lxd":                                true,
-	"lz4":                                true,
-	"lz4c":                               true,
-	"lz4cat":                             true,
-	"lzcat":                              true,
-	"lzcmp":                              true,
-	"lzdiff":                             true,
-	"lzegrep":                            true,
-	"lzfgrep":                            true,
-	"lzgrep":                             true,
-	"lzless":                             true,
-	"lzma":                               true,
-	"lzmainfo":                           true,
-	"lzmore":                             true,
-	"m4":                                 true,
-	"mailmail3":                          true,
-	"make":                               true,
-	"make-bcache":                        true,
-	"make-first-existing-target":         true,
-	"make-ssl-cert":                      true,
-	"makeconv":                           true,
-	"makeinfo":                           true,
-	"man":                                true,
-	"man-recode":                         true,
-	"mandb":                              true,
-	"manifest":                           true,
-	"manpath":                            true,
-	"mapfile":                            true,
-	"mapscrn":                            true,
-	"markdown-it":                        true,
-	"match_file":                         true,
-	"mawk":                               true,
-	"mbim-network":                       true,
-	"mbimcli":                            true,
-	"mcookie":                            true,
-	"md5sum":                             true,
-	"md5sum.textutils":                   true,
-	"mdadm":                              true,
-	"mdflush-bpfcc":                      true,
-	"mdflush.bt":                         true,
-	"mdig":                               true,
-	"mdmon":                              true,
-	"mediainfo":                          true,
-	"memhog":                             true,
-	"memleak-bpfcc":                      true,
-	"mesg":                               true,
-	"microsoft-edge":                     true,
-	"microsoft-edge-stable":              true,
-	"migrate-pubring-from-classic-gpg":   true,
-	"migratepages":                       true,
-	"migspeed":                           true,
-	"mii-tool":                           true,
-	"minikube":                           true,
-	"mk_modmap":                          true,
-	"mkdir":                              true,
-	"mkdosfs":                            true,
-	"mke2fs":                             true,
-	"mkfifo":                             true,
-	"mkfs":                               true,
-	"mkfs.bfs":                           true,
-	"mkfs.btrfs":                         true,
-	"mkfs.cramfs":                        true,
-	"mkfs.ext2":                          true,
-	"mkfs.ext3":                          true,
-	"mkfs.ext4":                          true,
-	"mkfs.fat":                           true,
-	"mkfs.minix":                         true,
-	"mkfs.msdos":                         true,
-	"mkfs.ntfs":                          true,
-	"mkfs.vfat":                          true,
-	"mkfs.xfs":                           true,
-	"mkhomedir_helper":                   true,
-	"mkinitramfs":                        true,
-	"mklost+found":                       true,
-	"mknod":                              true,
-	"mkntfs":                             true,
-	"mksquashfs":                         true,
-	"mkswap":                             true,
-	"mktemp":                             true,
-	"mlock":                              true,
-	"mmcli":                              true,
-	"modinfo":                            true,
-	"modprobe":                           true,
-	"modularize-16":                      true,
-	"modularize-17":                      true,
-	"modularize-18":                      true,
-	"modutil":                            true,
-	"mokutil":                            true,
-	"more":                               true,
-	"mount":                              true,
-	"mount.cifs":                         true,
-	"mount.fuse":                         true,
-	"mount.fuse3":                        true,
-	"mount.lowntfs-3g":                   true,
-	"mount.ntfs":                         true,
-	"mount.ntfs-3g":                      true,
-	"mount.smb3":                         true,
-	"mountpoint":                         true,
-	"mountsnoop-bpfcc":                   true,
-	"mpathpersist":                       true,
-	"mpstat":                             true,
-	"msedgedriver":                       true,
-	"mt":                                 true,
-	"mt-gnu":                             true,
-	"mtr":                                true,
-	"mtr-packet":                         true,
-	"multipath":                          true,
-	"multipathc":                         true,
-	"multipathd":                         true,
-	"mv":                                 true,
-	"mvn":                                true,
-	"my_print_defaults":                  true,
-	"myisam_ftdump":                      true,
-	"myisamchk":                          true,
-	"myisamlog":                          true,
-	"myisampack":                         true,
-	"mysql":                              true,
-	"mysql_config":                       true,
-	"mysql_config_editor":                true,
-	"mysql_migrate_keyring":              true,
-	"mysql_secure_installation":          true,
-	"mysql_ssl_rsa_setup":                true,
-	"mysql_tzinfo_to_sql":                true,
-	"mysql_upgrade":                      true,
-	"mysqladmin":                         true,
-	"mysqlanalyze":                       true,
-	"mysqlbinlog":                        true,
-	"mysqlcheck":                         true,
-	"mysqld":                             true,
-	"mysqld_multi":                       true,
-	"mysqld_qslower-bpfcc":               true,
-	"mysqld_safe":                        true,
-	"mysqldump":                          true,
-	"mysqldumpslow":                      true,
-	"mysqlimport":                        true,
-	"mysqloptimize":                      true,
-	"mysqlpump":                          true,
-	"mysqlrepair":                        true,
-	"mysqlreport":                        true,
-	"mysqlshow":                          true,
-	"mysqlslap":                          true,
-	"n":                                  true,
-	"namei":                              true,
-	"nameif":                             true,
-	"nano":                               true,
-	"naptime.bt":                         true,
-	"native2ascii":                       true,
-	"nawk":                               true,
-	"nbgv":                               true,
-	"nc":                                 true,
-	"nc.openbsd":                         true,
-	"ncurses6-config":                    true,
-	"ncursesw6-config":                   true,
-	"needrestart":                        true,
-	"neqn":                               true,
-	"netaddr":                            true,
-	"netcat":                             true,
-	"netplan":                            true,
-	"netqtop-bpfcc":                      true,
-	"netstat":                            true,
-	"networkctl":                         true,
-	"networkd-dispatcher":                true,
-	"newgidmap":                          true,
-	"newgrp":                             true,
-	"newman":                             true,
-	"newuidmap":                          true,
-	"newusers":                           true,
-	"nfnl_osf":                           true,
-	"nfsdist-bpfcc":                      true,
-	"nfsslower-bpfcc":                    true,
-	"nft":                                true,
-	"ngettext":                           true,
-	"nginx":                              true,
-	"nice":                               true,
-	"niceload":                           true,
-	"ninja":                              true,
-	"nisdomainname":                      true,
-	"nl":                                 true,
-	"nm":                                 true,
-	"node":                               true,
-	"nodegc-bpfcc":                       true,
-	"nodestat-bpfcc":                     true,
-	"nohup":                              true,
-	"nologin":                            true,
-	"not-16":                             true,
-	"not-17":                             true,
-	"not-18":                             true,
-	"npm":                                true,
-	"nproc":                              true,
-	"npx":                                true,
-	"nroff":                              true,
-	"nsenter":                            true,
-	"nslookup":                           true,
-	"nss-addbuiltin":                     true,
-	"nss-dbtest":                         true,
-	"nss-pp":                             true,
-	"nstat":                              true,
-	"nsupdate":                           true,
-	"ntfs-3g":                            true,
-	"ntfs-3g.probe":                      true,
-	"ntfscat":                            true,
-	"ntfsclone":                          true,
-	"ntfscluster":                        true,
-	"ntfscmp":                            true,
-	"ntfscp":                             true,
-	"ntfsdecrypt":                        true,
-	"ntfsfallocate":                      true,
-	"ntfsfix":                            true,
-	"ntfsinfo":                           true,
-	"ntfslabel":                          true,
-	"ntfsls":                             true,
-	"ntfsmove":                           true,
-	"ntfsrecover":                        true,
-	"ntfsresize":                         true,
-	"ntfssecaudit":                       true,
-	"ntfstruncate":                       true,
-	"ntfsundelete":                       true,
-	"ntfsusermap":                        true,
-	"ntfswipe":                           true,
-	"numactl":                            true,
-	"numastat":                           true,
-	"numfmt":                             true,
-	"nvme":                               true,
-	"nvptx-arch-16":                      true,
-	"nvptx-arch-17":                      true,
-	"nvptx-arch-18":                      true,
-	"obj2yaml-16":                        true,
-	"obj2yaml-17":                        true,
-	"obj2yaml-18":                        true,
-	"objcopy":                            true,
-	"objdump":                            true,
-	"ocspclnt":                           true,
-	"od":                                 true,
-	"oem-getlogs":                        true,
-	"offcputime-bpfcc":                   true,
-	"offwaketime-bpfcc":                  true,
-	"on_ac_power":                        true,
-	"oomkill-bpfcc":                      true,
-	"oomkill.bt":                         true,
-	"open":                               true,
-	"opensnoop-bpfcc":                    true,
-	"opensnoop.bt":                       true,
-	"openssl":                            true,
-	"openvt":                             true,
-	"opt-16":                             true,
-	"opt-17":                             true,
-	"opt-18":                             true,
-	"orbd":                               true,
-	"os-prober":                          true,
-	"overlayroot-chroot":                 true,
-	"ownership":                          true,
-	"p11-kit":                            true,
-	"p7content":                          true,
-	"p7env":                              true,
-	"p7sign":                             true,
-	"p7verify":                           true,
-	"p7zip":                              true,
-	"pack200":                            true,
-	"packer":                             true,
-	"pager":                              true,
-	"pam-auth-update":                    true,
-	"pam_extrausers_chkpwd":              true,
-	"pam_extrausers_update":              true,
-	"pam_getenv":                         true,
-	"pam_namespace_helper":               true,
-	"pam_timestamp_check":                true,
-	"parallel":                           true,
-	"parcat":                             true,
-	"parcel":                             true,
-	"parset":                             true,
-	"parsort":                            true,
-	"parted":                             true,
-	"partprobe":                          true,
-	"partx":                              true,
-	"passt":                              true,
-	"passt.avx2":                         true,
-	"passwd":                             true,
-	"pasta":                              true,
-	"pasta.avx2":                         true,
-	"paste":                              true,
-	"pastebinit":                         true,
-	"patch":                              true,
-	"patchelf":                           true,
-	"pathchk":                            true,
-	"pbget":                              true,
-	"pbput":                              true,
-	"pbputs":                             true,
-	"pcre2-config":                       true,
-	"pdata_tools":                        true,
-	"pdb3":                               true,
-	"pdb3.12":                            true,
-	"pdftexi2dvi":                        true,
-	"pear":                               true,
-	"peardev":                            true,
-	"pecl":                               true,
-	"peekfd":                             true,
-	"perf":                               true,
-	"perl":                               true,
-	"perl5.38-x86_64-linux-gnu":          true,
-	"perl5.38.2":                         true,
-	"perlbug":                            true,
-	"perlcalls-bpfcc":                    true,
-	"perldoc":                            true,
-	"perlflow-bpfcc":                     true,
-	"perlivp":                            true,
-	"perlstat-bpfcc":                     true,
-	"perlthanks":                         true,
-	"perror":                             true,
-	"pg_archivecleanup":                  true,
-	"pg_backupcluster":                   true,
-	"pg_basebackup":                      true,
-	"pg_config":                          true,
-	"pg_config.libpq-dev":                true,
-	"pg_conftool":                        true,
-	"pg_createcluster":                   true,
-	"pg_ctlcluster":                      true,
-	"pg_dropcluster":                     true,
-	"pg_dump":                            true,
-	"pg_dumpall":                         true,
-	"pg_isready":                         true,
-	"pg_lsclusters":                      true,
-	"pg_receivewal":                      true,
-	"pg_receivexlog":                     true,
-	"pg_recvlogical":                     true,
-	"pg_renamecluster":                   true,
-	"pg_restore":                         true,
-	"pg_restorecluster":                  true,
-	"pg_updatedicts":                     true,
-	"pg_upgradecluster":                  true,
-	"pg_virtualenv":                      true,
-	"pgbench":                            true,
-	"pgrep":                              true,
-	"phar":                               true,
-	"phar.phar":                          true,
-	"phar.phar8.3":                       true,
-	"phar8.3":                            true,
-	"phar8.3.phar":                       true,
-	"php":                                true,
-	"php-cgi":                            true,
-	"php-cgi8.3":                         true,
-	"php-config":                         true,
-	"php-config8.3":                      true,
-	"php-fpm8.3":                         true,
-	"php8.3":                             true,
-	"phpcalls-bpfcc":                     true,
-	"phpdbg":                             true,
-	"phpdbg8.3":                          true,
-	"phpdismod":                          true,
-	"phpenmod":                           true,
-	"phpflow-bpfcc":                      true,
-	"phpize":                             true,
-	"phpize8.3":                          true,
-	"phpquery":                           true,
-	"phpstat-bpfcc":                      true,
-	"phpunit":                            true,
-	"pic":                                true,
-	"pico":                               true,
-	"piconv":                             true,
-	"pidof":                              true,
-	"pidpersec-bpfcc":                    true,
-	"pidpersec.bt":                       true,
-	"pidstat":                            true,
-	"pidwait":                            true,
-	"pigz":                               true,
-	"pinentry":                           true,
-	"pinentry-curses":                    true,
-	"ping":                               true,
-	"ping4":                              true,
-	"ping6":                              true,
-	"pinky":                              true,
-	"pip":                                true,
-	"pip3":                               true,
-	"pip3.12":                            true,
-	"pipx":                               true,
-	"pivot_root":                         true,
-	"pk12util":                           true,
-	"pk1sign":                            true,
-	"pkaction":                           true,
-	"pkcheck":                            true,
-	"pkcon":                              true,
-	"pkg-config":                         true,
-	"pkgconf":                            true,
-	"pkgdata":                            true,
-	"pkill":                              true,
-	"pkmon":                              true,
-	"pkttyagent":                         true,
-	"pl2pm":                              true,
-	"pldd":                               true,
-	"plipconfig":                         true,
-	"plymouth":                           true,
-	"plymouthd":                          true,
-	"pmap":                               true,
-	"pod2html":                           true,
-	"pod2man":                            true,
-	"pod2texi":                           true,
-	"pod2text":                           true,
-	"pod2usage":                          true,
-	"podchecker":                         true,
-	"podman":                             true,
-	"podmansh":                           true,
-	"policytool":                         true,
-	"pollinate":                          true,
-	"popd":                               true,
-	"poweroff":                           true,
-	"pp-trace-16":                        true,
-	"pp-trace-17":                        true,
-	"pp-trace-18":                        true,
-	"ppchcalls-bpfcc":                    true,
-	"pr":                                 true,
-	"preconv":                            true,
-	"printenv":                           true,
-	"printf":                             true,
-	"prlimit":                            true,
-	"pro":                                true,
-	"profile-bpfcc":                      true,
-	"prove":                              true,
-	"prtstat":                            true,
-	"ps":                                 true,
-	"psfaddtable":                        true,
-	"psfgettable":                        true,
-	"psfstriptable":                      true,
-	"psfxtable":                          true,
-	"pslog":                              true,
-	"psql":                               true,
-	"pstree":                             true,
-	"pstree.x11":                         true,
-	"ptar":                               true,
-	"ptardiff":                           true,
-	"ptargrep":                           true,
-	"ptx":                                true,
-	"pulumi":                             true,
-	"pulumi-analyzer-policy":             true,
-	"pulumi-analyzer-policy-python":      true,
-	"pulumi-language-dotnet":             true,
-	"pulumi-language-go":                 true,
-	"pulumi-language-java":               true,
-	"pulumi-language-nodejs":             true,
-	"pulumi-language-python":             true,
-	"pulumi-language-python-exec":        true,
-	"pulumi-language-yaml":               true,
-	"pulumi-resource-pulumi-nodejs":      true,
-	"pulumi-resource-pulumi-python":      true,
-	"pulumi-watch":                       true,
-	"purge-old-kernels":                  true,
-	"pushd":                              true,
-	"pvchange":                           true,
-	"pvck":                               true,
-	"pvcreate":                           true,
-	"pvdisplay":                          true,
-	"pvmove":                             true,
-	"pvremove":                           true,
-	"pvresize":                           true,
-	"pvs":                                true,
-	"pvscan":                             true,
-	"pwck":                               true,
-	"pwconv":                             true,
-	"pwd":                                true,
-	"pwdecrypt":                          true,
-	"pwdx":                               true,
-	"pwhistory_helper":                   true,
-	"pwsh":                               true,
-	"pwunconv":                           true,
-	"py3clean":                           true,
-	"py3compile":                         true,
-	"py3versions":                        true,
-	"pybabel":                            true,
-	"pybabel-python3":                    true,
-	"pydoc":                              true,
-	"pydoc3":                             true,
-	"pydoc3.12":                          true,
-	"pygettext3":                         true,
-	"pygettext3.12":                      true,
-	"pygmentize":                         true,
-	"pyhtmlizer3":                        true,
-	"pyserial-miniterm":                  true,
-	"pyserial-ports":                     true,
-	"python":                             true,
-	"python-argcomplete-check-easy-install-script": true,
-	"python3":                            true,
-	"python3-config":                     true,
-	"python3.12":                         true,
-	"python3.12-config":                  true,
-	"pythoncalls-bpfcc":                  true,
-	"pythonflow-bpfcc":                   true,
-	"pythongc-bpfcc":                     true,
-	"pythonstat-bpfcc":                   true,
-	"pzstd":                              true,
-	"qmi-firmware-update":                true,
-	"qmi-network":                        true,
-	"qmicli":                             true,
-	"qrap":                               true,
-	"racc3.2":                            true,
-	"rake":                               true,
-	"rake3.2":                            true,
-	"ranlib":                             true,
-	"rarp":                               true,
-	"rbash":                              true,
-	"rbs3.2":                             true,
-	"rdbg3.2":                            true,
-	"rdma":                               true,
-	"rdmaucma-bpfcc":                     true,
-	"rdoc":                               true,
-	"rdoc3.2":                            true,
-	"read":                               true,
-	"readahead-bpfcc":                    true,
-	"readarray":                          true,
-	"readelf":                            true,
-	"readlink":                           true,
-	"readonly":                           true,
-	"readprofile":                        true,
-	"realpath":                           true,
-	"reboot":                             true,
-	"red":                                true,
-	"register-python-argcomplete":        true,
-	"reindexdb":                          true,
-	"remove-default-ispell":              true,
-	"remove-default-wordlist":            true,
-	"remove-shell":                       true,
-	"rename.ul":                          true,
-	"renice":                             true,
-	"request-key":                        true,
-	"rescan-scsi-bus.sh":                 true,
-	"reset":                              true,
-	"reset-trace-bpfcc":                  true,
-	"resize2fs":                          true,
-	"resizecons":                         true,
-	"resizepart":                         true,
-	"resolvconf":                         true,
-	"resolvectl":                         true,
-	"return":                             true,
-	"rev":                                true,
-	"rgrep":                              true,
-	"ri":                                 true,
-	"ri3.2":                              true,
-	"rls":                                true,
-	"rm":                                 true,
-	"rmdir":                              true,
-	"rmic":                               true,
-	"rmid":                               true,
-	"rmiregistry":                        true,
-	"rmmod":                              true,
-	"rmt":                                true,
-	"rmt-tar":                            true,
-	"rnano":                              true,
-	"rotatelogs":                         true,
-	"rougify":                            true,
-	"route":                              true,
-	"routel":                             true,
-	"rpcgen":                             true,
-	"rpm":                                true,
-	"rpm2archive":                        true,
-	"rpm2cpio":                           true,
-	"rpmbuild":                           true,
-	"rpmdb":                              true,
-	"rpmgraph":                           true,
-	"rpmkeys":                            true,
-	"rpmlua":                             true,
-	"rpmquery":                           true,
-	"rpmsign":                            true,
-	"rpmspec":                            true,
-	"rpmverify":                          true,
-	"rrsync":                             true,
-	"rsaperf":                            true,
-	"rsync":                              true,
-	"rsync-ssl":                          true,
-	"rsyslogd":                           true,
-	"rtacct":                             true,
-	"rtcwake":                            true,
-	"rtla":                               true,
-	"rtmon":                              true,
-	"rtstat":                             true,
-	"ruby":                               true,
-	"ruby3.2":                            true,
-	"rubycalls-bpfcc":                    true,
-	"rubyflow-bpfcc":                     true,
-	"rubygc-bpfcc":                       true,
-	"rubyobjnew-bpfcc":                   true,
-	"rubystat-bpfcc":                     true,
-	"run-clang-tidy":                     true,
-	"run-clang-tidy-16":                  true,
-	"run-clang-tidy-16.py":               true,
-	"run-clang-tidy-17":                  true,
-	"run-clang-tidy-17.py":               true,
-	"run-clang-tidy-18":                  true,
-	"run-clang-tidy-18.py":               true,
-	"run-one":                            true,
-	"run-one-constantly":                 true,
-	"run-one-until-failure":              true,
-	"run-one-until-success":              true,
-	"run-parts":                          true,
-	"run-this-one":                       true,
-	"runc":                               true,
-	"runcon":                             true,
-	"runghc":                             true,
-	"runghc-9.10":                        true,
-	"runghc-9.10.3":                      true,
-	"runghc-9.12":                        true,
-	"runghc-9.12.2":                      true,
-	"runhaskell":                         true,
-	"runhaskell-9.10":                    true,
-	"runhaskell-9.10.3":                  true,
-	"runhaskell-9.12":                    true,
-	"runhaskell-9.12.2":                  true,
-	"runlevel":                           true,
-	"runqlat-bpfcc":                      true,
-	"runqlat.bt":                         true,
-	"runqlen-bpfcc":                      true,
-	"runqlen.bt":                         true,
-	"runqslower-bpfcc":                   true,
-	"runuser":                            true,
-	"rust-analyzer":                      true,
-	"rust-gdb":                           true,
-	"rust-gdbgui":                        true,
-	"rust-lldb":                          true,
-	"rustc":                              true,
-	"rustdoc":                            true,
-	"rustfmt":                            true,
-	"rustup":                             true,
-	"rview":                              true,
-	"rvim":                               true,
-	"sadf":                               true,
-	"sam":                                true,
-	"sancov-16":                          true,
-	"sancov-17":                          true,
-	"sancov-18":                          true,
-	"sanstats-16":                        true,
-	"sanstats-17":                        true,
-	"sanstats-18":                        true,
-	"sar":                                true,
-	"sar.sysstat":                        true,
-	"savelog":                            true,
-	"sbattach":                           true,
-	"sbkeysync":                          true,
-	"sbsiglist":                          true,
-	"sbsign":                             true,
-	"sbvarsign":                          true,
-	"sbverify":                           true,
-	"scalar":                             true,
-	"scan-build-16":                      true,
-	"scan-build-17":                      true,
-	"scan-build-18":                      true,
-	"scan-build-py-16":                   true,
-	"scan-build-py-17":                   true,
-	"scan-build-py-18":                   true,
-	"scan-view-16":                       true,
-	"scan-view-17":                       true,
-	"scan-view-18":                       true,
-	"scandeps":                           true,
-	"schemagen":                          true,
-	"scp":                                true,
-	"screen":                             true,
-	"screendump":                         true,
-	"script":                             true,
-	"scriptlive":                         true,
-	"scriptreplay":                       true,
-	"scsi_logging_level":                 true,
-	"scsi_mandat":                        true,
-	"scsi_readcap":                       true,
-	"scsi_ready":                         true,
-	"scsi_satl":                          true,
-	"scsi_start":                         true,
-	"scsi_stop":                          true,
-	"scsi_temperature":                   true,
-	"sdiff":                              true,
-	"searchd":                            true,
-	"sed":                                true,
-	"select":                             true,
-	"select-default-ispell":              true,
-	"select-default-iwrap":               true,
-	"select-default-wordlist":            true,
-	"select-editor":                      true,
-	"selfserv":                           true,
-	"sem":                                true,
-	"sensible-browser":                   true,
-	"sensible-editor":                    true,
-	"sensible-pager":                     true,
-	"sensible-terminal":                  true,
-	"sepdebugcrcfix":                     true,
-	"seq":                                true,
-	"serialver":                          true,
-	"servertool":                         true,
-	"service":                            true,
-	"session-manager-plugin":             true,
-	"set":                                true,
-	"setarch":                            true,
-	"setcap":                             true,
-	"setcifsacl":                         true,
-	"setfacl":                            true,
-	"setfont":                            true,
-	"setkeycodes":                        true,
-	"setleds":                            true,
-	"setlogcons":                         true,
-	"setmetamode":                        true,
-	"setpci":                             true,
-	"setpriv":                            true,
-	"setsid":                             true,
-	"setterm":                            true,
-	"setuids.bt":                         true,
-	"setupcon":                           true,
-	"setvesablank":                       true,
-	"setvtrgb":                           true,
-	"setxkbmap":                          true,
-	"sfdisk":                             true,
-	"sftp":                               true,
-	"sg":                                 true,
-	"sg_bg_ctl":                          true,
-	"sg_compare_and_write":               true,
-	"sg_copy_results":                    true,
-	"sg_dd":                              true,
-	"sg_decode_sense":                    true,
-	"sg_emc_trespass":                    true,
-	"sg_format":                          true,
-	"sg_get_config":                      true,
-	"sg_get_elem_status":                 true,
-	"sg_get_lba_status":                  true,
-	"sg_ident":                           true,
-	"sg_inq":                             true,
-	"sg_logs":                            true,
-	"sg_luns":                            true,
-	"sg_map":                             true,
-	"sg_map26":                           true,
-	"sg_modes":                           true,
-	"sg_opcodes":                         true,
-	"sg_persist":                         true,
-	"sg_prevent":                         true,
-	"sg_raw":                             true,
-	"sg_rbuf":                            true,
-	"sg_rdac":                            true,
-	"sg_read":                            true,
-	"sg_read_attr":                       true,
-	"sg_read_block_limits":               true,
-	"sg_read_buffer":                     true,
-	"sg_read_long":                       true,
-	"sg_readcap":                         true,
-	"sg_reassign":                        true,
-	"sg_referrals":                       true,
-	"sg_rep_pip":                         true,
-	"sg_rep_zones":                       true,
-	"sg_requests":                        true,
-	"sg_reset":                           true,
-	"sg_reset_wp":                        true,
-	"sg_rmsn":                            true,
-	"sg_rtpg":                            true,
-	"sg_safte":                           true,
-	"sg_sanitize":                        true,
-	"sg_sat_identify":                    true,
-	"sg_sat_phy_event":                   true,
-	"sg_sat_read_gplog":                  true,
-	"sg_sat_set_features":                true,
-	"sg_scan":                            true,
-	"sg_seek":                            true,
-	"sg_senddiag":                        true,
-	"sg_ses":                             true,
-	"sg_ses_microcode":                   true,
-	"sg_start":                           true,
-	"sg_stpg":                            true,
-	"sg_stream_ctl":                      true,
-	"sg_sync":                            true,
-	"sg_test_rwbuf":                      true,
-	"sg_timestamp":                       true,
-	"sg_turs":                            true,
-	"sg_unmap":                           true,
-	"sg_verify":                          true,
-	"sg_vpd":                             true,
-	"sg_wr_mode":                         true,
-	"sg_write_buffer":                    true,
-	"sg_write_long":                      true,
-	"sg_write_same":                      true,
-	"sg_write_verify":                    true,
-	"sg_write_x":                         true,
-	"sg_xcopy":                           true,
-	"sg_zone":                            true,
-	"sgdisk":                             true,
-	"sginfo":                             true,
-	"sgm_dd":                             true,
-	"sgp_dd":                             true,
-	"sh":                                 true,
-	"sha1sum":                            true,
-	"sha224sum":                          true,
-	"sha256sum":                          true,
-	"sha384sum":                          true,
-	"sha512sum":                          true,
-	"shadowconfig":                       true,
-	"shasum":                             true,
-	"shellcheck":                         true,
-	"shift":                              true,
-	"shlibsign":                          true,
-	"shmsnoop-bpfcc":                     true,
-	"shopt":                              true,
-	"showconsolefont":                    true,
-	"showkey":                            true,
-	"shred":                              true,
-	"shtool":                             true,
-	"shtoolize":                          true,
-	"shuf":                               true,
-	"shutdown":                           true,
-	"signtool":                           true,
-	"signver":                            true,
-	"size":                               true,
-	"skill":                              true,
-	"skopeo":                             true,
-	"slabratetop-bpfcc":                  true,
-	"slabtop":                            true,
-	"slattach":                           true,
-	"sleep":                              true,
-	"slirp4netns":                        true,
-	"slogin":                             true,
-	"smb2-quota":                         true,
-	"smbinfo":                            true,
-	"snap":                               true,
-	"snapctl":                            true,
-	"snapfuse":                           true,
-	"snice":                              true,
-	"snmp-bridge-mib":                    true,
-	"snmpbulkget":                        true,
-	"snmpbulkwalk":                       true,
-	"snmpcheck":                          true,
-	"snmpconf":                           true,
-	"snmpdelta":                          true,
-	"snmpdf":                             true,
-	"snmpget":                            true,
-	"snmpgetnext":                        true,
-	"snmpinform":                         true,
-	"snmpnetstat":                        true,
-	"snmpping":                           true,
-	"snmpps":                             true,
-	"snmpset":                            true,
-	"snmpstatus":                         true,
-	"snmptable":                          true,
-	"snmptest":                           true,
-	"snmptls":                            true,
-	"snmptranslate":                      true,
-	"snmptrap":                           true,
-	"snmpusm":                            true,
-	"snmpvacm":                           true,
-	"snmpwalk":                           true,
-	"soelim":                             true,
-	"sofdsnoop-bpfcc":                    true,
-	"softirqs-bpfcc":                     true,
-	"solisten-bpfcc":                     true,
-	"sort":                               true,
-	"sos":                                true,
-	"sos-collector":                      true,
-	"sosreport":                          true,
-	"source":                             true,
-	"spelldump":                          true,
-	"splain":                             true,
-	"split":                              true,
-	"split-file-16":                      true,
-	"split-file-17":                      true,
-	"split-file-18":                      true,
-	"split-logfile":                      true,
-	"splitfont":                          true,
-	"sqfscat":                            true,
-	"sqfstar":                            true,
-	"sql":                                true,
-	"sqlite3":                            true,
-	"ss":                                 true,
-	"ssh":                                true,
-	"ssh-add":                            true,
-	"ssh-agent":                          true,
-	"ssh-argv0":                          true,
-	"ssh-copy-id":                        true,
-	"ssh-import-id":                      true,
-	"ssh-import-id-gh":                   true,
-	"ssh-import-id-lp":                   true,
-	"ssh-keygen":                         true,
-	"ssh-keyscan":                        true,
-	"sshd":                               true,
-	"sshpass":                            true,
-	"ssllatency.bt":                      true,
-	"sslsniff-bpfcc":                     true,
-	"sslsnoop.bt":                        true,
-	"ssltap":                             true,
-	"stack":                              true,
-	"stackcount-bpfcc":                   true,
-	"start-stop-daemon":                  true,
-	"stat":                               true,
-	"static-sh":                          true,
-	"statsnoop-bpfcc":                    true,
-	"statsnoop.bt":                       true,
-	"stdbuf":                             true,
-	"strace":                             true,
-	"strace-log-merge":                   true,
-	"streamzip":                          true,
-	"strings":                            true,
-	"strip":                              true,
-	"strsclnt":                           true,
-	"stty":                               true,
-	"su":                                 true,
-	"sudo":                               true,
-	"sudo_logsrvd":                       true,
-	"sudo_sendlog":                       true,
-	"sudoedit":                           true,
-	"sudoreplay":                         true,
-	"sulogin":                            true,
-	"sum":                                true,
-	"suspend":                            true,
-	"swapin.bt":                          true,
-	"swaplabel":                          true,
-	"swapoff":                            true,
-	"swapon":                             true,
-	"swift":                              true,
-	"swiftc":                             true,
-	"swig":                               true,
-	"swig4.0":                            true,
-	"switch_root":                        true,
-	"symkeyutil":                         true,
-	"sync":                               true,
-	"syncsnoop-bpfcc":                    true,
-	"syncsnoop.bt":                       true,
-	"syntax_suggest3.2":                  true,
-	"syscount-bpfcc":                     true,
-	"syscount.bt":                        true,
-	"sysctl":                             true,
-	"systemctl":                          true,
-	"systemd":                            true,
-	"systemd-ac-power":                   true,
-	"systemd-analyze":                    true,
-	"systemd-ask-password":               true,
-	"systemd-cat":                        true,
-	"systemd-cgls":                       true,
-	"systemd-cgtop":                      true,
-	"systemd-confext":                    true,
-	"systemd-creds":                      true,
-	"systemd-cryptenroll":                true,
-	"systemd-cryptsetup":                 true,
-	"systemd-delta":                      true,
-	"systemd-detect-virt":                true,
-	"systemd-escape":                     true,
-	"systemd-firstboot":                  true,
-	"systemd-hwdb":                       true,
-	"systemd-id128":                      true,
-	"systemd-inhibit":                    true,
-	"systemd-machine-id-setup":           true,
-	"systemd-mount":                      true,
-	"systemd-notify":                     true,
-	"systemd-path":                       true,
-	"systemd-repart":                     true,
-	"systemd-run":                        true,
-	"systemd-socket-activate":            true,
-	"systemd-stdio-bridge":               true,
-	"systemd-sysext":                     true,
-	"systemd-sysusers":                   true,
-	"systemd-tmpfiles":                   true,
-	"systemd-tty-ask-password-agent":     true,
-	"systemd-umount":                     true,
-	"tabs":                               true,
-	"tac":                                true,
-	"tail":                               true,
-	"tapestat":                           true,
-	"tar":                                true,
-	"tarcat":                             true,
-	"taskset":                            true,
-	"tbl":                                true,
-	"tc":                                 true,
-	"tclcalls-bpfcc":                     true,
-	"tclflow-bpfcc":                      true,
-	"tclobjnew-bpfcc":                    true,
-	"tclsh":                              true,
-	"tclsh8.6":                           true,
-	"tclstat-bpfcc":                      true,
-	"tcpaccept-bpfcc":                    true,
-	"tcpaccept.bt":                       true,
-	"tcpcong-bpfcc":                      true,
-	"tcpconnect-bpfcc":                   true,
-	"tcpconnect.bt":                      true,
-	"tcpconnlat-bpfcc":                   true,
-	"tcpdrop-bpfcc":                      true,
-	"tcpdrop.bt":                         true,
-	"tcpdump":                            true,
-	"tcplife-bpfcc":                      true,
-	"tcplife.bt":                         true,
-	"tcpretrans-bpfcc":                   true,
-	"tcpretrans.bt":                      true,
-	"tcprtt-bpfcc":                       true,
-	"tcpstates-bpfcc":                    true,
-	"tcpsubnet-bpfcc":                    true,
-	"tcpsynbl-bpfcc":                     true,
-	"tcpsynbl.bt":                        true,
-	"tcptop-bpfcc":                       true,
-	"tcptracer-bpfcc":                    true,
-	"tee":                                true,
-	"telinit":                            true,
-	"telnet":                             true,
-	"tempfile":                           true,
-	"terminal-notifier":                  true,
-	"test":                               true,
-	"texi2any":                           true,
-	"texi2dvi":                           true,
-	"texi2pdf":                           true,
-	"texindex":                           true,
-	"then":                               true,
-	"thin_check":                         true,
-	"thin_delta":                         true,
-	"thin_dump":                          true,
-	"thin_ls":                            true,
-	"thin_metadata_size":                 true,
-	"thin_repair":                        true,
-	"thin_restore":                       true,
-	"thin_rmap":                          true,
-	"thin_trim":                          true,
-	"threadsnoop-bpfcc":                  true,
-	"threadsnoop.bt":                     true,
-	"tic":                                true,
-	"time":                               true,
-	"timedatectl":                        true,
-	"timeout":                            true,
-	"times":                              true,
-	"tipc":                               true,
-	"tkconch3":                           true,
-	"tload":                              true,
-	"tmux":                               true,
-	"tnameserv":                          true,
-	"tnftp":                              true,
-	"toe":                                true,
-	"top":                                true,
-	"touch":                              true,
-	"tplist-bpfcc":                       true,
-	"tput":                               true,
-	"tr":                                 true,
-	"trace-bpfcc":                        true,
-	"trace-cmd":                          true,
-	"tracepath":                          true,
-	"trap":                               true,
-	"tree":                               true,
-	"trial3":                             true,
-	"troff":                              true,
-	"true":                               true,
-	"truncate":                           true,
-	"trust":                              true,
-	"tsc":                                true,
-	"tset":                               true,
-	"tsort":                              true,
-	"tsserver":                           true,
-	"tstclnt":                            true,
-	"tty":                                true,
-	"ttysnoop-bpfcc":                     true,
-	"tune2fs":                            true,
-	"turbostat":                          true,
-	"twist3":                             true,
-	"twistd3":                            true,
-	"txixml2texi":                        true,
-	"type":                               true,
-	"typeprof3.2":                        true,
-	"typeset":                            true,
-	"tzselect":                           true,
-	"ua":                                 true,
-	"ubuntu-advantage":                   true,
-	"ubuntu-bug":                         true,
-	"ubuntu-distro-info":                 true,
-	"ubuntu-security-status":             true,
-	"ucalls":                             true,
-	"ucf":                                true,
-	"ucfq":                               true,
-	"ucfr":                               true,
-	"uclampset":                          true,
-	"uconv":                              true,
-	"udevadm":                            true,
-	"udisksctl":                          true,
-	"uflow":                              true,
-	"ufw":                                true,
-	"ugc":                                true,
-	"ul":                                 true,
-	"ulimit":                             true,
-	"umask":                              true,
-	"umount":                             true,
-	"umount.udisks2":                     true,
-	"unalias":                            true,
-	"uname":                              true,
-	"uncompress":                         true,
-	"undump.bt":                          true,
-	"unexpand":                           true,
-	"unicode_start":                      true,
-	"unicode_stop":                       true,
-	"uniq":                               true,
-	"unix_chkpwd":                        true,
-	"unix_update":                        true,
-	"unlink":                             true,
-	"unlz4":                              true,
-	"unlzma":                             true,
-	"unmkinitramfs":                      true,
-	"unpack200":                          true,
-	"unpigz":                             true,
-	"unset":                              true,
-	"unshare":                            true,
-	"unsquashfs":                         true,
-	"until":                              true,
-	"unxz":                               true,
-	"unzip":                              true,
-	"unzipsfx":                           true,
-	"unzstd":                             true,
-	"uobjnew":                            true,
-	"update-alternatives":                true,
-	"update-ca-certificates":             true,
-	"update-catalog":                     true,
-	"update-default-aspell":              true,
-	"update-default-ispell":              true,
-	"update-default-wordlist":            true,
-	"update-dictcommon-aspell":           true,
-	"update-dictcommon-hunspell":         true,
-	"update-fmtutil":                     true,
-	"update-grub":                        true,
-	"update-grub-gfxpayload":             true,
-	"update-grub2":                       true,
-	"update-icon-caches":                 true,
-	"update-ieee-data":                   true,
-	"update-info-dir":                    true,
-	"update-initramfs":                   true,
-	"update-java-alternatives":           true,
-	"update-language":                    true,
-	"update-language-dat":                true,
-	"update-language-def":                true,
-	"update-language-lua":                true,
-	"update-locale":                      true,
-	"update-mime-database":               true,
-	"update-passwd":                      true,
-	"update-pciids":                      true,
-	"update-perl-sax-parsers":            true,
-	"update-rc.d":                        true,
-	"update-secureboot-policy":           true,
-	"update-shells":                      true,
-	"update-texmf":                       true,
-	"update-texmf-config":                true,
-	"update-tl-stacked-conffile":         true,
-	"update-updmap":                      true,
-	"update-xmlcatalog":                  true,
-	"update_rubygems":                    true,
-	"upgrade-from-grub-legacy":           true,
-	"uptime":                             true,
-	"upx":                                true,
-	"upx-ucl":                            true,
-	"usb-devices":                        true,
-	"usb_modeswitch":                     true,
-	"usb_modeswitch_dispatcher":          true,
-	"usbhid-dump":                        true,
-	"usbip":                              true,
-	"usbipd":                             true,
-	"usbreset":                           true,
-	"useradd":                            true,
-	"userdel":                            true,
-	"usermod":                            true,
-	"userpath":                           true,
-	"users":                              true,
-	"ustat":                              true,
-	"uthreads":                           true,
-	"utmpdump":                           true,
-	"uuidd":                              true,
-	"uuidgen":                            true,
-	"uuidparse":                          true,
-	"vacuumdb":                           true,
-	"vacuumlo":                           true,
-	"validlocale":                        true,
-	"varlinkctl":                         true,
-	"vcpkg":                              true,
-	"vcs-run":                            true,
-	"vcstime":                            true,
-	"vdir":                               true,
-	"vdpa":                               true,
-	"verify-uselistorder-16":             true,
-	"verify-uselistorder-17":             true,
-	"verify-uselistorder-18":             true,
-	"veritysetup":                        true,
-	"vfscount-bpfcc":                     true,
-	"vfscount.bt":                        true,
-	"vfsstat-bpfcc":                      true,
-	"vfsstat.bt":                         true,
-	"vfychain":                           true,
-	"vfyserv":                            true,
-	"vgcfgbackup":                        true,
-	"vgcfgrestore":                       true,
-	"vgchange":                           true,
-	"vgck":                               true,
-	"vgconvert":                          true,
-	"vgcreate":                           true,
-	"vgdisplay":                          true,
-	"vgexport":                           true,
-	"vgextend":                           true,
-	"vgimport":                           true,
-	"vgimportclone":                      true,
-	"vgmerge":                            true,
-	"vgmknodes":                          true,
-	"vgreduce":                           true,
-	"vgremove":                           true,
-	"vgrename":                           true,
-	"vgs":                                true,
-	"vgscan":                             true,
-	"vgsplit":                            true,
-	"vi":                                 true,
-	"view":                               true,
-	"vigpg":                              true,
-	"vigr":                               true,
-	"vim":                                true,
-	"vim.basic":                          true,
-	"vim.tiny":                           true,
-	"vimdiff":                            true,
-	"vimtutor":                           true,
-	"vipw":                               true,
-	"virtiostat-bpfcc":                   true,
-	"visudo":                             true,
-	"vm-support":                         true,
-	"vmhgfs-fuse":                        true,
-	"vmstat":                             true,
-	"vmtoolsd":                           true,
-	"vmware-alias-import":                true,
-	"vmware-checkvm":                     true,
-	"vmware-hgfsclient":                  true,
-	"vmware-namespace-cmd":               true,
-	"vmware-rpctool":                     true,
-	"vmware-toolbox-cmd":                 true,
-	"vmware-vgauth-cmd":                  true,
-	"vmware-vmblock-fuse":                true,
-	"vmware-xferlogs":                    true,
-	"vpddecode":                          true,
-	"w":                                  true,
-	"waagent":                            true,
-	"waagent2.0":                         true,
-	"wait":                               true,
-	"wakeuptime-bpfcc":                   true,
-	"wall":                               true,
-	"wasm-ld-16":                         true,
-	"wasm-ld-17":                         true,
-	"wasm-ld-18":                         true,
-	"watch":                              true,
-	"watchgnupg":                         true,
-	"wc":                                 true,
-	"wdctl":                              true,
-	"webpack":                            true,
-	"webpack-cli":                        true,
-	"wget":                               true,
-	"whatis":                             true,
-	"whereis":                            true,
-	"which":                              true,
-	"which.debianutils":                  true,
-	"while":                              true,
-	"whiptail":                           true,
-	"who":                                true,
-	"whoami":                             true,
-	"wifi-status":                        true,
-	"wipefs":                             true,
-	"wish":                               true,
-	"wish8.6":                            true,
-	"wordbreaker":                        true,
-	"write":                              true,
-	"writeback.bt":                       true,
-	"wsgen":                              true,
-	"wsimport":                           true,
-	"ww":                                 true,
-	"x-www-browser":                      true,
-	"x86_64":                             true,
-	"x86_64-linux-gnu-addr2line":         true,
-	"x86_64-linux-gnu-ar":                true,
-	"x86_64-linux-gnu-as":                true,
-	"x86_64-linux-gnu-c++filt":           true,
-	"x86_64-linux-gnu-cpp":               true,
-	"x86_64-linux-gnu-cpp-12":            true,
-	"x86_64-linux-gnu-cpp-13":            true,
-	"x86_64-linux-gnu-cpp-14":            true,
-	"x86_64-linux-gnu-dwp":               true,
-	"x86_64-linux-gnu-elfedit":           true,
-	"x86_64-linux-gnu-g++":               true,
-	"x86_64-linux-gnu-g++-12":            true,
-	"x86_64-linux-gnu-g++-13":            true,
-	"x86_64-linux-gnu-g++-14":            true,
-	"x86_64-linux-gnu-gcc":               true,
-	"x86_64-linux-gnu-gcc-12":            true,
-	"x86_64-linux-gnu-gcc-13":            true,
-	"x86_64-linux-gnu-gcc-14":            true,
-	"x86_64-linux-gnu-gcc-ar":            true,
-	"x86_64-linux-gnu-gcc-ar-12":         true,
-	"x86_64-linux-gnu-gcc-ar-13":         true,
-	"x86_64-linux-gnu-gcc-ar-14":         true,
-	"x86_64-linux-gnu-gcc-nm":            true,
-	"x86_64-linux-gnu-gcc-nm-12":         true,
-	"x86_64-linux-gnu-gcc-nm-13":         true,
-	"x86_64-linux-gnu-gcc-nm-14":         true,
-	"x86_64-linux-gnu-gcc-ranlib":        true,
-	"x86_64-linux-gnu-gcc-ranlib-12":     true,
-	"x86_64-linux-gnu-gcc-ranlib-13":     true,
-	"x86_64-linux-gnu-gcc-ranlib-14":     true,
-	"x86_64-linux-gnu-gcov":              true,
-	"x86_64-linux-gnu-gcov-12":           true,
-	"x86_64-linux-gnu-gcov-13":           true,
-	"x86_64-linux-gnu-gcov-14":           true,
-	"x86_64-linux-gnu-gcov-dump":         true,
-	"x86_64-linux-gnu-gcov-dump-12":      true,
-	"x86_64-linux-gnu-gcov-dump-13":      true,
-	"x86_64-linux-gnu-gcov-dump-14":      true,
-	"x86_64-linux-gnu-gcov-tool":         true,
-	"x86_64-linux-gnu-gcov-tool-12":      true,
-	"x86_64-linux-gnu-gcov-tool-13":      true,
-	"x86_64-linux-gnu-gcov-tool-14":      true,
-	"x86_64-linux-gnu-gfortran":          true,
-	"x86_64-linux-gnu-gfortran-12":       true,
-	"x86_64-linux-gnu-gfortran-13":       true,
-	"x86_64-linux-gnu-gfortran-14":       true,
-	"x86_64-linux-gnu-gold":              true,
-	"x86_64-linux-gnu-gp-archive":        true,
-	"x86_64-linux-gnu-gp-collect-app":    true,
-	"x86_64-linux-gnu-gp-display-html":   true,
-	"x86_64-linux-gnu-gp-display-src":    true,
-	"x86_64-linux-gnu-gp-display-text":   true,
-	"x86_64-linux-gnu-gprof":             true,
-	"x86_64-linux-gnu-gprofng":           true,
-	"x86_64-linux-gnu-ld":                true,
-	"x86_64-linux-gnu-ld.bfd":            true,
-	"x86_64-linux-gnu-ld.gold":           true,
-	"x86_64-linux-gnu-lto-dump":          true,
-	"x86_64-linux-gnu-lto-dump-12":       true,
-	"x86_64-linux-gnu-lto-dump-13":       true,
-	"x86_64-linux-gnu-lto-dump-14":       true,
-	"x86_64-linux-gnu-nm":                true,
-	"x86_64-linux-gnu-objcopy":           true,
-	"x86_64-linux-gnu-objdump":           true,
-	"x86_64-linux-gnu-pkg-config":        true,
-	"x86_64-linux-gnu-pkgconf":           true,
-	"x86_64-linux-gnu-python3-config":    true,
-	"x86_64-linux-gnu-python3.12-config": true,
-	"x86_64-linux-gnu-ranlib":            true,
-	"x86_64-linux-gnu-readelf":           true,
-	"x86_64-linux-gnu-size":              true,
-	"x86_64-linux-gnu-strings":           true,
-	"x86_64-linux-gnu-strip":             true,
-	"x86_energy_perf_policy":             true,
-	"xargs":                              true,
-	"xauth":                              true,
-	"xcodeproj":                          true,
-	"xcpretty":                           true,
-	"xcpretty-travis-formatter":          true,
-	"xdg-desktop-icon":                   true,
-	"xdg-desktop-menu":                   true,
-	"xdg-email":                          true,
-	"xdg-icon-resource":                  true,
-	"xdg-mime":                           true,
-	"xdg-open":                           true,
-	"xdg-screensaver":                    true,
-	"xdg-settings":                       true,
-	"xdg-user-dir":                       true,
-	"xdg-user-dirs-update":               true,
-	"xfs_admin":                          true,
-	"xfs_bmap":                           true,
-	"xfs_copy":                           true,
-	"xfs_db":                             true,
-	"xfs_estimate":                       true,
-	"xfs_freeze":                         true,
-	"xfs_fsr":                            true,
-	"xfs_growfs":                         true,
-	"xfs_info":                           true,
-	"xfs_io":                             true,
-	"xfs_logprint":                       true,
-	"xfs_mdrestore":                      true,
-	"xfs_metadump":                       true,
-	"xfs_mkfile":                         true,
-	"xfs_ncheck":                         true,
-	"xfs_quota":                          true,
-	"xfs_repair":                         true,
-	"xfs_rtcp":                           true,
-	"xfs_scrub":                          true,
-	"xfs_scrub_all":                      true,
-	"xfs_spaceman":                       true,
-	"xfsdist-bpfcc":                      true,
-	"xfsdist.bt":                         true,
-	"xfsslower-bpfcc":                    true,
-	"xjc":                                true,
-	"xkbbell":                            true,
-	"xkbcomp":                            true,
-	"xkbevd":                             true,
-	"xkbprint":                           true,
-	"xkbvleds":                           true,
-	"xkbwatch":                           true,
-	"xml2-config":                        true,
-	"xsubpp":                             true,
-	"xtables-legacy-multi":               true,
-	"xtables-monitor":                    true,
-	"xtables-nft-multi":                  true,
-	"xvfb-run":                           true,
-	"xxd":                                true,
-	"xz":                                 true,
-	"xzcat":                              true,
-	"xzcmp":                              true,
-	"xzdiff":                             true,
-	"xzegrep":                            true,
-	"xzfgrep":                            true,
-	"xzgrep":                             true,
-	"xzless":                             true,
-	"xzmore":                             true,
-	"yacc":                               true,
-	"yaml-bench-16":                      true,
-	"yaml-bench-17":                      true,
-	"yaml-bench-18":                      true,
-	"yaml2obj-16":                        true,
-	"yaml2obj-17":                        true,
-	"yaml2obj-18":                        true,
-	"yamllint":                           true,
-	"yarn":                               true,
-	"yarnpkg":                            true,
-	"yes":                                true,
-	"ypdomainname":                       true,
-	"yq":                                 true,
-	"zcat":                               true,
-	"zcmp":                               true,
-	"zdiff":                              true,
-	"zdump":                              true,
-	"zegrep":                             true,
-	"zerofree":                           true,
-	"zfgrep":                             true,
-	"zforce":                             true,
-	"zfsdist-bpfcc":                      true,
-	"zfsslower-bpfcc":                    true,
-	"zgrep":                              true,
-	"zic":                                true,
-	"zip":                                true,
-	"zipcloak":                           true,
-	"zipdetails":                         true,
-	"zipgrep":                            true,
-	"zipinfo":                            true,
-	"zipnote":                            true,
-	"zipsplit":                           true,
-	"zless":                              true,
-	"zmore":                              true,
-	"znew":                               true,
-	"zramctl":                            true,
-	"zstd":                               true,
-	"zstdcat":                            true,
-	"zstdgrep":                           true,
-	"zstdless":                           true,
-	"zstdmt":                             true,
-	"zsync":                              true,
-	"zsyncmake":                          true,
-	"{":                                  true,
-	"}":                                  true,
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CommandsFileName is the file name of the command dataset written by
+// "gh slimify db update" in the state directory
+const CommandsFileName = "commands.json"
+
+// CommandsSchema is the version of the format of command datasets read by
+// this version of gh-slimify
+const CommandsSchema = 1
+
+// Runner images of command datasets
+const (
+	ImageLatest = "ubuntu-latest"
+	ImageSlim   = "ubuntu-slim"
+)
+
+// embeddedCommands is the command dataset built into gh-slimify. It is
+// regenerated with "gh slimify db update -o internal/workflow/commands.json".
+//
+//go:embed commands.json
+var embeddedCommands []byte
+
+// CommandsDB is a dataset of the commands available on ubuntu-latest and
+// ubuntu-slim, which missing commands are detected with
+type CommandsDB struct {
+	Schema  int                       `json:"schema"`  // Version of the format (CommandsSchema)
+	Version string                    `json:"version"` // Date the dataset was generated (YYYY-MM-DD)
+	Images  map[string]*ImageCommands `json:"images"`  // Keyed by image (ImageLatest, ImageSlim)
+}
+
+// ImageCommands are the commands available on a runner image: the output of
+// `compgen -c` on a runner, and the commands of the tools listed in the
+// software report of an actions/runner-images release
+type ImageCommands struct {
+	Commands []string          `json:"commands"`
+	Release  string            `json:"release,omitempty"` // Tag of the release Tools come from
+	Tools    map[string]string `json:"tools,omitempty"`   // Versions of the tools, keyed by command
+}
+
+// commands is the dataset in use, and ubuntuLatestCommands and
+// ubuntuSlimCommands the commands available on its images
+var (
+	commands             *CommandsDB
+	ubuntuLatestCommands map[string]bool
+	ubuntuSlimCommands   map[string]bool
+)
+
+func init() {
+	db, err := ParseCommandsDB(embeddedCommands)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded command dataset: %v", err))
+	}
+	useCommandsDB(db)
+}
+
+// ParseCommandsDB parses a command dataset, which must have both images
+func ParseCommandsDB(data []byte) (*CommandsDB, error) {
+	var db CommandsDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	if db.Schema != CommandsSchema {
+		return nil, fmt.Errorf("unsupported schema %d (this version of gh-slimify reads schema %d)", db.Schema, CommandsSchema)
+	}
+	for _, image := range []string{ImageLatest, ImageSlim} {
+		if db.Images[image] == nil || len(db.Images[image].Commands) == 0 {
+			return nil, fmt.Errorf("no commands for %s", image)
+		}
+	}
+	return &db, nil
+}
+
+// LoadCommandsFile replaces the command dataset in use with the one in path
+// (--commands-file, or the dataset written by "gh slimify db update")
+func LoadCommandsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read command dataset: %w", err)
+	}
+	db, err := ParseCommandsDB(data)
+	if err != nil {
+		return fmt.Errorf("invalid command dataset %s: %w", path, err)
+	}
+	useCommandsDB(db)
+	return nil
+}
+
+// Commands returns a copy of the command dataset in use
+func Commands() *CommandsDB {
+	db := &CommandsDB{Schema: commands.Schema, Version: commands.Version, Images: make(map[string]*ImageCommands)}
+	for image, ic := range commands.Images {
+		copied := *ic
+		copied.Commands = append([]string(nil), ic.Commands...)
+		copied.Tools = make(map[string]string, len(ic.Tools))
+		for cmd, version := range ic.Tools {
+			copied.Tools[cmd] = version
+		}
+		db.Images[image] = &copied
+	}
+	return db
+}
+
+func useCommandsDB(db *CommandsDB) {
+	commands = db
+	ubuntuLatestCommands = db.Images[ImageLatest].set()
+	ubuntuSlimCommands = db.Images[ImageSlim].set()
+}
+
+// set returns the commands and tools of the image as a set
+func (ic *ImageCommands) set() map[string]bool {
+	set := make(map[string]bool, len(ic.Commands)+len(ic.Tools))
+	for _, cmd := range ic.Commands {
+		set[cmd] = true
+	}
+	for cmd := range ic.Tools {
+		set[cmd] = true
+	}
+	return set
+}
+
+// Marshal encodes the dataset as indented JSON, with sorted commands so that
+// updates produce small diffs
+func (db *CommandsDB) Marshal() ([]byte, error) {
+	for _, ic := range db.Images {
+		sort.Strings(ic.Commands)
+	}
+	data, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// toolCommands maps the names of tools in runner-images software reports,
+// lowercased, to their commands, for names that are not the command
+var toolCommands = map[string][]string{
+	"7-zip":                          {"7z", "7za", "7zr"},
+	"aws cli session manager plugin": {"session-manager-plugin"},
+	"aws cli":                        {"aws"},
+	"aws sam cli":                    {"sam"},
+	"azure cli":                      {"az"},
+	"azure devops cli extension":     nil,
+	"docker compose v2":              nil,
+	"docker-buildx":                  nil,
+	"docker-moby client":             {"docker"},
+	"docker-moby server":             {"dockerd"},
+	"git lfs":                        {"git-lfs"},
+	"github cli":                     {"gh"},
+	"gnu c++":                        {"g++"},
+	"gnu fortran":                    {"gfortran"},
+	"go":                             {"go", "gofmt"},
+	"google cloud cli":               {"gcloud", "gsutil"},
+	"homebrew":                       {"brew"},
+	"java":                           {"java", "javac"},
+	"kotlin":                         {"kotlin", "kotlinc"},
+	"leiningen":                      {"lein"},
+	"maven":                          {"mvn"},
+	"mercurial":                      {"hg"},
+	"miniconda":                      {"conda"},
+	"mysql":                          {"mysql", "mysqldump"},
+	"node.js":                        {"node"},
+	"openshift cli":                  {"oc"},
+	"postgresql":                     {"psql", "pg_dump"},
+	"powershell":                     {"pwsh"},
+	"python":                         {"python", "python3"},
+	"ruby":                           {"ruby", "gem"},
+	"sqlite":                         {"sqlite3"},
+}
+
+// commandName matches tool names that are their command
+var commandName = regexp.MustCompile(`^[a-z0-9][a-z0-9._+-]*$`)
+
+// SoftwareReportTools returns the versions of the tools listed in a
+// runner-images software report (the JSON report attached to its releases),
+// keyed by command. Tools are the ToolName values found anywhere in the
+// report, with their Version or Versions; names that are not a command and
+// have no known command (e.g., "Docker Compose v2") are skipped, and "X CLI"
+// names are taken as the command x.
+func SoftwareReportTools(report []byte) (map[string]string, error) {
+	var root any
+	if err := json.Unmarshal(report, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse software report: %w", err)
+	}
+	type tool struct{ name, version string }
+	var found []tool
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if name, ok := v["ToolName"].(string); ok {
+				t := tool{name: name}
+				if version, ok := v["Version"].(string); ok {
+					t.version = version
+				} else if versions, ok := v["Versions"].([]any); ok {
+					var list []string
+					for _, v := range versions {
+						if s, ok := v.(string); ok {
+							list = append(list, s)
+						}
+					}
+					t.version = strings.Join(list, ", ")
+				}
+				found = append(found, t)
+			}
+			// Walk in key order so that duplicate tools are resolved the same way
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(root)
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no tools found in software report")
+	}
+
+	tools := make(map[string]string)
+	for _, t := range found {
+		name := strings.ToLower(strings.TrimSpace(t.name))
+		cmds, known := toolCommands[name]
+		switch {
+		case known:
+		case commandName.MatchString(name):
+			cmds = []string{name}
+		case strings.HasSuffix(name, " cli") && commandName.MatchString(strings.TrimSuffix(name, " cli")):
+			cmds = []string{strings.TrimSuffix(name, " cli")}
+		}
+		for _, cmd := range cmds {
+			if _, ok := tools[cmd]; !ok {
+				tools[cmd] = t.version
+			}
+		}
+	}
+	return tools, nil
+}
+
+// CommandInfo describes the availability of a command on ubuntu-latest and
+// ubuntu-slim. Versions are known for the tools of software reports.
+type CommandInfo struct {
+	Name          string `json:"name"`
+	Latest        bool   `json:"ubuntu_latest"`
+	Slim          bool   `json:"ubuntu_slim"`
+	LatestVersion string `json:"ubuntu_latest_version,omitempty"`
+	SlimVersion   string `json:"ubuntu_slim_version,omitempty"`
+	Installer     string `json:"installer,omitempty"` // How to install it on ubuntu-slim, if missing there
+}
+
+// LookupCommand returns the availability of a command in the dataset in use
+func LookupCommand(name string) CommandInfo {
+	info := CommandInfo{
+		Name:          name,
+		Latest:        ubuntuLatestCommands[name],
+		Slim:          ubuntuSlimCommands[name],
+		LatestVersion: commands.Images[ImageLatest].Tools[name],
+		SlimVersion:   commands.Images[ImageSlim].Tools[name],
+	}
+	if !info.Slim {
+		info.Installer = Installer(name)
+	}
+	return info
 }
 
-// ubuntuSlimCommands is a map of all commands available in ubuntu-slim runner.
-// This map is generated from ubuntu-slim runner's `compgen -c` command output.
-var ubuntuSlimCommands = map[string]bool{
-	"!":                                  true,
-	".":                                  true,
-	":":                                  true,
-	"GET":                                true,
-	"HEAD":                               true,
-	"JSONStream":                         true,
-	"POST":                               true,
-	"[":                                  true,
-	"[[":                                 true,
-	"]]":                                 true,
-	"acorn":                              true,
-	"activate-global-python-argcomplete": true,
-	"add-apt-repository":                 true,
-	"add-shell":                          true,
-	"addgnupghome":                       true,
-	"addgroup":                           true,
-	"addpart":                            true,
-	"addr2line":                          true,
-	"adduser":                            true,
-	"agetty":                             true,
-	"alias":                              true,
-	"applygnupgdefaults":                 true,
-	"appres":                             true,
-	"appstreamcli":                       true,
-	"apt":                                true,
-	"apt-add-repository":                 true,
-	"apt-cache":                          true,
-	"apt-cdrom":                          true,
-	"apt-config":                         true,
-	"apt-get":                            true,
-	"apt-key":                            true,
-	"apt-mark":                           true,
-	"ar":                                 true,
-	"arborist":                           true,
-	"arch":                               true,
-	"as":                                 true,
-	"awk":                                true,
-	"aws":                                true,
-	"aws_completer":                      true,
-	"az":                                 true,
-	"b2sum":                              true,
-	"babeljs":                            true,
-	"babeljs-7":                          true,
-	"babeljs-7-external-helpers":         true,
-	"babeljs-7-node":                     true,
-	"babeljs-7-parser":                   true,
-	"babeljs-external-helpers":           true,
-	"babeljs-node":                       true,
-	"babeljs-parser":                     true,
-	"badblocks":                          true,
-	"base32":                             true,
-	"base64":                             true,
-	"basename":                           true,
-	"basenc":                             true,
-	"bash":                               true,
-	"bashbug":                            true,
-	"bc":                                 true,
-	"bg":                                 true,
-	"bind":                               true,
-	"blkdeactivate":                      true,
-	"blkdiscard":                         true,
-	"blkid":                              true,
-	"blkzone":                            true,
-	"blockdev":                           true,
-	"break":                              true,
-	"browse":                             true,
-	"browserslist":                       true,
-	"builtin":                            true,
-	"bunzip2":                            true,
-	"busctl":                             true,
-	"bzcat":                              true,
-	"bzcmp":                              true,
-	"bzdiff":                             true,
-	"bzegrep":                            true,
-	"bzexe":                              true,
-	"bzfgrep":                            true,
-	"bzgrep":                             true,
-	"bzip2":                              true,
-	"bzip2recover":                       true,
-	"bzless":                             true,
-	"bzmore":                             true,
-	"c++":                                true,
-	"c++filt":                            true,
-	"c89":                                true,
-	"c89-gcc":                            true,
-	"c99":                                true,
-	"c99-gcc":                            true,
-	"c_rehash":                           true,
-	"caller":                             true,
-	"capsh":                              true,
-	"captoinfo":                          true,
-	"case":                               true,
-	"cat":                                true,
-	"cbor":                               true,
-	"cbor2comment":                       true,
-	"cbor2diag":                          true,
-	"cbor2js":                            true,
-	"cbor2json":                          true,
-	"cc":                                 true,
-	"cd":                                 true,
-	"chacl":                              true,
-	"chage":                              true,
-	"chattr":                             true,
-	"chcon":                              true,
-	"chcpu":                              true,
-	"chfn":                               true,
-	"chgpasswd":                          true,
-	"chgrp":                              true,
-	"chmem":                              true,
-	"chmod":                              true,
-	"choom":                              true,
-	"chown":                              true,
-	"chpasswd":                           true,
-	"chroot":                             true,
-	"chrt":                               true,
-	"chsh":                               true,
-	"cifsiostat":                         true,
-	"cksum":                              true,
-	"clear":                              true,
-	"clear_console":                      true,
-	"cmp":                                true,
-	"comm":                               true,
-	"command":                            true,
-	"compgen":                            true,
-	"complete":                           true,
-	"compopt":                            true,
-	"continue":                           true,
-	"coproc":                             true,
-	"corelist":                           true,
-	"corepack":                           true,
-	"cp":                                 true,
-	"cpan":                               true,
-	"cpan5.38-x86_64-linux-gnu":          true,
-	"cpgr":                               true,
-	"cpp":                                true,
-	"cpp-13":                             true,
-	"cppw":                               true,
-	"csplit":                             true,
-	"css-beautify":                       true,
-	"css-beautify-js":                    true,
-	"ctrlaltdel":                         true,
-	"curl":                               true,
-	"cut":                                true,
-	"cvtsudoers":                         true,
-	"dash":                               true,
-	"date":                               true,
-	"dbus-cleanup-sockets":               true,
-	"dbus-daemon":                        true,
-	"dbus-monitor":                       true,
-	"dbus-run-session":                   true,
-	"dbus-send":                          true,
-	"dbus-update-activation-environment": true,
-	"dbus-uuidgen":                       true,
-	"dd":                                 true,
-	"deb-systemd-helper":                 true,
-	"deb-systemd-invoke":                 true,
-	"debconf":                            true,
-	"debconf-apt-progress":               true,
-	"debconf-communicate":                true,
-	"debconf-copydb":                     true,
-	"debconf-escape":                     true,
-	"debconf-set-selections":             true,
-	"debconf-show":                       true,
-	"debugfs":                            true,
-	"declare":                            true,
-	"delgroup":                           true,
-	"delpart":                            true,
-	"deluser":                            true,
-	"df":                                 true,
-	"dh_installxmlcatalogs":              true,
-	"dh_perl_openssl":                    true,
-	"diff":                               true,
-	"diff3":                              true,
-	"dir":                                true,
-	"dircolors":                          true,
-	"dirmngr":                            true,
-	"dirmngr-client":                     true,
-	"dirname":                            true,
-	"dirs":                               true,
-	"disown":                             true,
-	"dmesg":                              true,
-	"dmsetup":                            true,
-	"dmstats":                            true,
-	"dnsdomainname":                      true,
-	"do":                                 true,
-	"domainname":                         true,
-	"done":                               true,
-	"dpkg":                               true,
-	"dpkg-architecture":                  true,
-	"dpkg-buildapi":                      true,
-	"dpkg-buildflags":                    true,
-	"dpkg-buildpackage":                  true,
-	"dpkg-buildtree":                     true,
-	"dpkg-checkbuilddeps":                true,
-	"dpkg-deb":                           true,
-	"dpkg-distaddfile":                   true,
-	"dpkg-divert":                        true,
-	"dpkg-genbuildinfo":                  true,
-	"dpkg-genchanges":                    true,
-	"dpkg-gencontrol":                    true,
-	"dpkg-gensymbols":                    true,
-	"dpkg-maintscript-helper":            true,
-	"dpkg-mergechangelogs":               true,
-	"dpkg-name":                          true,
-	"dpkg-parsechangelog":                true,
-	"dpkg-preconfigure":                  true,
-	"dpkg-query":                         true,
-	"dpkg-realpath":                      true,
-	"dpkg-reconfigure":                   true,
-	"dpkg-scanpackages":                  true,
-	"dpkg-scansources":                   true,
-	"dpkg-shlibdeps":                     true,
-	"dpkg-source":                        true,
-	"dpkg-split":                         true,
-	"dpkg-statoverride":                  true,
-	"dpkg-trigger":                       true,
-	"dpkg-vendor":                        true,
-	"du":                                 true,
-	"dumpe2fs":                           true,
-	"dwp":                                true,
-	"e2freefrag":                         true,
-	"e2fsck":                             true,
-	"e2image":                            true,
-	"e2label":                            true,
-	"e2mmpstatus":                        true,
-	"e2scrub":                            true,
-	"e2scrub_all":                        true,
-	"e2undo":                             true,
-	"e4crypt":                            true,
-	"e4defrag":                           true,
-	"echo":                               true,
-	"editres":                            true,
-	"egrep":                              true,
-	"elfedit":                            true,
-	"elif":                               true,
-	"else":                               true,
-	"enable":                             true,
-	"enc2xs":                             true,
-	"encguess":                           true,
-	"env":                                true,
-	"env_parallel":                       true,
-	"env_parallel.bash":                  true,
-	"env_parallel.csh":                   true,
-	"env_parallel.dash":                  true,
-	"env_parallel.fish":                  true,
-	"env_parallel.ksh":                   true,
-	"env_parallel.mksh":                  true,
-	"env_parallel.sh":                    true,
-	"env_parallel.tcsh":                  true,
-	"env_parallel.zsh":                   true,
-	"esac":                               true,
-	"escodegen":                          true,
-	"esgenerate":                         true,
-	"eslint":                             true,
-	"esparse":                            true,
-	"esvalidate":                         true,
-	"eval":                               true,
-	"exec":                               true,
-	"exit":                               true,
-	"expand":                             true,
-	"expiry":                             true,
-	"export":                             true,
-	"expr":                               true,
-	"factor":                             true,
-	"faillock":                           true,
-	"faillog":                            true,
-	"faked-sysv":                         true,
-	"faked-tcp":                          true,
-	"fakeroot":                           true,
-	"fakeroot-sysv":                      true,
-	"fakeroot-tcp":                       true,
-	"fallocate":                          true,
-	"false":                              true,
-	"fc":                                 true,
-	"fg":                                 true,
-	"fgrep":                              true,
-	"fi":                                 true,
-	"filefrag":                           true,
-	"find":                               true,
-	"findfs":                             true,
-	"findmnt":                            true,
-	"flock":                              true,
-	"fmt":                                true,
-	"fold":                               true,
-	"for":                                true,
-	"free":                               true,
-	"fsck":                               true,
-	"fsck.cramfs":                        true,
-	"fsck.ext2":                          true,
-	"fsck.ext3":                          true,
-	"fsck.ext4":                          true,
-	"fsck.minix":                         true,
-	"fsfreeze":                           true,
-	"fstab-decode":                       true,
-	"fstrim":                             true,
-	"function":                           true,
-	"funzip":                             true,
-	"g++":                                true,
-	"g++-13":                             true,
-	"gapplication":                       true,
-	"gcc":                                true,
-	"gcc-13":                             true,
-	"gcc-ar":                             true,
-	"gcc-ar-13":                          true,
-	"gcc-nm":                             true,
-	"gcc-nm-13":                          true,
-	"gcc-ranlib":                         true,
-	"gcc-ranlib-13":                      true,
-	"gcov":                               true,
-	"gcov-13":                            true,
-	"gcov-dump":                          true,
-	"gcov-dump-13":                       true,
-	"gcov-tool":                          true,
-	"gcov-tool-13":                       true,
-	"gdbus":                              true,
-	"gdk-pixbuf-csource":                 true,
-	"gdk-pixbuf-pixdata":                 true,
-	"gdk-pixbuf-thumbnailer":             true,
-	"gencat":                             true,
-	"geo2svg":                            true,
-	"geograticule":                       true,
-	"geoproject":                         true,
-	"geoquantize":                        true,
-	"geostitch":                          true,
-	"getcap":                             true,
-	"getconf":                            true,
-	"getent":                             true,
-	"getfacl":                            true,
-	"getopt":                             true,
-	"getopts":                            true,
-	"getpcaps":                           true,
-	"getty":                              true,
-	"gh":                                 true,
-	"gio":                                true,
-	"gio-querymodules":                   true,
-	"git":                                true,
-	"git-receive-pack":                   true,
-	"git-shell":                          true,
-	"git-upload-archive":                 true,
-	"git-upload-pack":                    true,
-	"glib-compile-schemas":               true,
-	"gmake":                              true,
-	"gold":                               true,
-	"gp-archive":                         true,
-	"gp-collect-app":                     true,
-	"gp-display-html":                    true,
-	"gp-display-src":                     true,
-	"gp-display-text":                    true,
-	"gpasswd":                            true,
-	"gpg":                                true,
-	"gpg-agent":                          true,
-	"gpg-connect-agent":                  true,
-	"gpg-wks-client":                     true,
-	"gpgconf":                            true,
-	"gpgparsemail":                       true,
-	"gpgsm":                              true,
-	"gpgsplit":                           true,
-	"gpgtar":                             true,
-	"gpgv":                               true,
-	"gprof":                              true,
-	"gprofng":                            true,
-	"grep":                               true,
-	"gresource":                          true,
-	"groupadd":                           true,
-	"groupdel":                           true,
-	"groupmems":                          true,
-	"groupmod":                           true,
-	"groups":                             true,
-	"grpck":                              true,
-	"grpconv":                            true,
-	"grpunconv":                          true,
-	"grunt":                              true,
-	"gsettings":                          true,
-	"gulp":                               true,
-	"gunzip":                             true,
-	"gyp":                                true,
-	"gzexe":                              true,
-	"gzip":                               true,
-	"h2ph":                               true,
-	"h2xs":                               true,
-	"halt":                               true,
-	"handlebars":                         true,
-	"hardlink":                           true,
-	"hash":                               true,
-	"head":                               true,
-	"help":                               true,
-	"history":                            true,
-	"hostid":                             true,
-	"hostname":                           true,
-	"hostnamectl":                        true,
-	"html-beautify":                      true,
-	"html-beautify-js":                   true,
-	"i386":                               true,
-	"iceauth":                            true,
-	"iconv":                              true,
-	"iconvconfig":                        true,
-	"id":                                 true,
-	"if":                                 true,
-	"in":                                 true,
-	"infocmp":                            true,
-	"infotocap":                          true,
-	"init":                               true,
-	"initctl":                            true,
-	"install":                            true,
-	"install-sgmlcatalog":                true,
-	"installkernel":                      true,
-	"instmodsh":                          true,
-	"invoke-rc.d":                        true,
-	"ionice":                             true,
-	"iostat":                             true,
-	"ipcmk":                              true,
-	"ipcrm":                              true,
-	"ipcs":                               true,
-	"ischroot":                           true,
-	"isosize":                            true,
-	"istanbul":                           true,
-	"jobs":                               true,
-	"join":                               true,
-	"journalctl":                         true,
-	"jq":                                 true,
-	"js":                                 true,
-	"js-beautify":                        true,
-	"js-beautify-js":                     true,
-	"js-yaml":                            true,
-	"js2cbor":                            true,
-	"jsdoc2":                             true,
-	"jsesc":                              true,
-	"json2cbor":                          true,
-	"json2module":                        true,
-	"json5":                              true,
-	"json_pp":                            true,
-	"kbxutil":                            true,
-	"kernel-install":                     true,
-	"kill":                               true,
-	"killall5":                           true,
-	"last":                               true,
-	"lastb":                              true,
-	"lastlog":                            true,
-	"lcf":                                true,
-	"ld":                                 true,
-	"ld.bfd":                             true,
-	"ld.gold":                            true,
-	"ld.so":                              true,
-	"ldattach":                           true,
-	"ldconfig":                           true,
-	"ldconfig.real":                      true,
-	"ldd":                                true,
-	"lerna":                              true,
-	"less":                               true,
-	"lessecho":                           true,
-	"lessfile":                           true,
-	"lesskey":                            true,
-	"lesspipe":                           true,
-	"let":                                true,
-	"libnetcfg":                          true,
-	"link":                               true,
-	"linux32":                            true,
-	"linux64":                            true,
-	"listres":                            true,
-	"ln":                                 true,
-	"local":                              true,
-	"locale":                             true,
-	"locale-check":                       true,
-	"localectl":                          true,
-	"localedef":                          true,
-	"logger":                             true,
-	"login":                              true,
-	"loginctl":                           true,
-	"logname":                            true,
-	"logout":                             true,
-	"logsave":                            true,
-	"loose-envify":                       true,
-	"losetup":                            true,
-	"ls":                                 true,
-	"lsattr":                             true,
-	"lsb_release":                        true,
-	"lsblk":                              true,
-	"lscpu":                              true,
-	"lsipc":                              true,
-	"lslocks":                            true,
-	"lslogins":                           true,
-	"lsmem":                              true,
-	"lsns":                               true,
-	"lspgpot":                            true,
-	"lto-dump":                           true,
-	"lto-dump-13":                        true,
-	"lwp-download":                       true,
-	"lwp-dump":                           true,
-	"lwp-mirror":                         true,
-	"lwp-request":                        true,
-	"lzcat":                              true,
-	"lzcmp":                              true,
-	"lzdiff":                             true,
-	"lzegrep":                            true,
-	"lzfgrep":                            true,
-	"lzgrep":                             true,
-	"lzless":                             true,
-	"lzma":                               true,
-	"lzmainfo":                           true,
-	"lzmore":                             true,
-	"make":                               true,
-	"make-first-existing-target":         true,
-	"man":                                true,
-	"mapfile":                            true,
-	"mawk":                               true,
-	"mcookie":                            true,
-	"md5sum":                             true,
-	"md5sum.textutils":                   true,
-	"memusage":                           true,
-	"memusagestat":                       true,
-	"mesa-overlay-control.py":            true,
-	"mesg":                               true,
-	"migrate-pubring-from-classic-gpg":   true,
-	"mimeopen":                           true,
-	"mimetype":                           true,
-	"mkdir":                              true,
-	"mke2fs":                             true,
-	"mkfifo":                             true,
-	"mkfs":                               true,
-	"mkfs.bfs":                           true,
-	"mkfs.cramfs":                        true,
-	"mkfs.ext2":                          true,
-	"mkfs.ext3":                          true,
-	"mkfs.ext4":                          true,
-	"mkfs.minix":                         true,
-	"mkhomedir_helper":                   true,
-	"mklost+found":                       true,
-	"mknod":                              true,
-	"mkswap":                             true,
-	"mktemp":                             true,
-	"more":                               true,
-	"mount":                              true,
-	"mountpoint":                         true,
-	"mpstat":                             true,
-	"mtrace":                             true,
-	"mv":                                 true,
-	"n":                                  true,
-	"namei":                              true,
-	"nanoid":                             true,
-	"nawk":                               true,
-	"networkctl":                         true,
-	"networkd-dispatcher":                true,
-	"newgrp":                             true,
-	"newman":                             true,
-	"newusers":                           true,
-	"nice":                               true,
-	"niceload":                           true,
-	"nisdomainname":                      true,
-	"nl":                                 true,
-	"nm":                                 true,
-	"node":                               true,
-	"node-coveralls":                     true,
-	"node-gyp":                           true,
-	"nodejs":                             true,
-	"nohup":                              true,
-	"nologin":                            true,
-	"notify-send":                        true,
-	"npm":                                true,
-	"npm-arborist":                       true,
-	"nproc":                              true,
-	"npx":                                true,
-	"nsenter":                            true,
-	"numfmt":                             true,
-	"nyc":                                true,
-	"objcopy":                            true,
-	"objdump":                            true,
-	"od":                                 true,
-	"open":                               true,
-	"opener":                             true,
-	"openssl":                            true,
-	"pacote":                             true,
-	"pager":                              true,
-	"pam-auth-update":                    true,
-	"pam_extrausers_chkpwd":              true,
-	"pam_extrausers_update":              true,
-	"pam_getenv":                         true,
-	"pam_namespace_helper":               true,
-	"pam_timestamp_check":                true,
-	"parallel":                           true,
-	"parcat":                             true,
-	"parcel":                             true,
-	"parset":                             true,
-	"parsort":                            true,
-	"partx":                              true,
-	"passwd":                             true,
-	"paste":                              true,
-	"patch":                              true,
-	"pathchk":                            true,
-	"pdb3":                               true,
-	"pdb3.12":                            true,
-	"perl":                               true,
-	"perl5.38-x86_64-linux-gnu":          true,
-	"perl5.38.2":                         true,
-	"perlbug":                            true,
-	"perldoc":                            true,
-	"perlivp":                            true,
-	"perlthanks":                         true,
-	"pgrep":                              true,
-	"piconv":                             true,
-	"pidof":                              true,
-	"pidstat":                            true,
-	"pidwait":                            true,
-	"pinentry":                           true,
-	"pinentry-curses":                    true,
-	"pinky":                              true,
-	"pip":                                true,
-	"pip3":                               true,
-	"pip3.12":                            true,
-	"pipx":                               true,
-	"pivot_root":                         true,
-	"pkaction":                           true,
-	"pkcheck":                            true,
-	"pkcon":                              true,
-	"pkill":                              true,
-	"pkmon":                              true,
-	"pkttyagent":                         true,
-	"pl2pm":                              true,
-	"pldd":                               true,
-	"pmap":                               true,
-	"pod2html":                           true,
-	"pod2man":                            true,
-	"pod2text":                           true,
-	"pod2usage":                          true,
-	"podchecker":                         true,
-	"policy-rc.d":                        true,
-	"popd":                               true,
-	"poweroff":                           true,
-	"pr":                                 true,
-	"printenv":                           true,
-	"printf":                             true,
-	"prlimit":                            true,
-	"prove":                              true,
-	"ps":                                 true,
-	"ptar":                               true,
-	"ptardiff":                           true,
-	"ptargrep":                           true,
-	"ptx":                                true,
-	"pushd":                              true,
-	"pwck":                               true,
-	"pwconv":                             true,
-	"pwd":                                true,
-	"pwdx":                               true,
-	"pwhistory_helper":                   true,
-	"pwunconv":                           true,
-	"py3clean":                           true,
-	"py3compile":                         true,
-	"py3versions":                        true,
-	"pydoc3":                             true,
-	"pydoc3.12":                          true,
-	"pygettext3":                         true,
-	"pygettext3.12":                      true,
-	"python-argcomplete-check-easy-install-script": true,
-	"python3":                            true,
-	"python3-config":                     true,
-	"python3.12":                         true,
-	"python3.12-config":                  true,
-	"qrcode-terminal":                    true,
-	"ranlib":                             true,
-	"rbash":                              true,
-	"read":                               true,
-	"readarray":                          true,
-	"readelf":                            true,
-	"readlink":                           true,
-	"readonly":                           true,
-	"readprofile":                        true,
-	"realpath":                           true,
-	"reboot":                             true,
-	"register-python-argcomplete":        true,
-	"regjsparser":                        true,
-	"remove-shell":                       true,
-	"rename.ul":                          true,
-	"renice":                             true,
-	"reset":                              true,
-	"resize2fs":                          true,
-	"resizepart":                         true,
-	"resolvconf":                         true,
-	"resolvectl":                         true,
-	"return":                             true,
-	"rev":                                true,
-	"rgrep":                              true,
-	"rimraf":                             true,
-	"rm":                                 true,
-	"rmdir":                              true,
-	"rmt":                                true,
-	"rmt-tar":                            true,
-	"rpcgen":                             true,
-	"rtcwake":                            true,
-	"run-parts":                          true,
-	"runcon":                             true,
-	"runlevel":                           true,
-	"runuser":                            true,
-	"sadf":                               true,
-	"sam":                                true,
-	"sar":                                true,
-	"sar.sysstat":                        true,
-	"savelog":                            true,
-	"scalar":                             true,
-	"scp":                                true,
-	"script":                             true,
-	"scriptlive":                         true,
-	"scriptreplay":                       true,
-	"sdiff":                              true,
-	"sed":                                true,
-	"select":                             true,
-	"select-editor":                      true,
-	"sem":                                true,
-	"semver":                             true,
-	"sensible-browser":                   true,
-	"sensible-editor":                    true,
-	"sensible-pager":                     true,
-	"sensible-terminal":                  true,
-	"seq":                                true,
-	"service":                            true,
-	"session-manager-plugin":             true,
-	"sessreg":                            true,
-	"set":                                true,
-	"setarch":                            true,
-	"setcap":                             true,
-	"setfacl":                            true,
-	"setpriv":                            true,
-	"setsid":                             true,
-	"setterm":                            true,
-	"sftp":                               true,
-	"sg":                                 true,
-	"sh":                                 true,
-	"sha1sum":                            true,
-	"sha224sum":                          true,
-	"sha256sum":                          true,
-	"sha384sum":                          true,
-	"sha512sum":                          true,
-	"shadowconfig":                       true,
-	"shasum":                             true,
-	"shift":                              true,
-	"shopt":                              true,
-	"showrgb":                            true,
-	"shred":                              true,
-	"shuf":                               true,
-	"shutdown":                           true,
-	"size":                               true,
-	"skill":                              true,
-	"slabtop":                            true,
-	"sleep":                              true,
-	"slogin":                             true,
-	"snice":                              true,
-	"sort":                               true,
-	"sotruss":                            true,
-	"source":                             true,
-	"splain":                             true,
-	"split":                              true,
-	"sprof":                              true,
-	"sql":                                true,
-	"ssh":                                true,
-	"ssh-add":                            true,
-	"ssh-agent":                          true,
-	"ssh-argv0":                          true,
-	"ssh-copy-id":                        true,
-	"ssh-keygen":                         true,
-	"ssh-keyscan":                        true,
-	"start-stop-daemon":                  true,
-	"stat":                               true,
-	"stdbuf":                             true,
-	"streamzip":                          true,
-	"strings":                            true,
-	"strip":                              true,
-	"stty":                               true,
-	"su":                                 true,
-	"sudo":                               true,
-	"sudo_logsrvd":                       true,
-	"sudo_sendlog":                       true,
-	"sudoedit":                           true,
-	"sudoreplay":                         true,
-	"sulogin":                            true,
-	"sum":                                true,
-	"suspend":                            true,
-	"swaplabel":                          true,
-	"swapoff":                            true,
-	"swapon":                             true,
-	"switch_root":                        true,
-	"sync":                               true,
-	"sysctl":                             true,
-	"systemctl":                          true,
-	"systemd":                            true,
-	"systemd-ac-power":                   true,
-	"systemd-analyze":                    true,
-	"systemd-ask-password":               true,
-	"systemd-cat":                        true,
-	"systemd-cgls":                       true,
-	"systemd-cgtop":                      true,
-	"systemd-confext":                    true,
-	"systemd-creds":                      true,
-	"systemd-cryptenroll":                true,
-	"systemd-cryptsetup":                 true,
-	"systemd-delta":                      true,
-	"systemd-detect-virt":                true,
-	"systemd-escape":                     true,
-	"systemd-firstboot":                  true,
-	"systemd-id128":                      true,
-	"systemd-inhibit":                    true,
-	"systemd-machine-id-setup":           true,
-	"systemd-mount":                      true,
-	"systemd-notify":                     true,
-	"systemd-path":                       true,
-	"systemd-repart":                     true,
-	"systemd-run":                        true,
-	"systemd-socket-activate":            true,
-	"systemd-stdio-bridge":               true,
-	"systemd-sysext":                     true,
-	"systemd-sysusers":                   true,
-	"systemd-tmpfiles":                   true,
-	"systemd-tty-ask-password-agent":     true,
-	"systemd-umount":                     true,
-	"tabs":                               true,
-	"tac":                                true,
-	"tail":                               true,
-	"tap":                                true,
-	"tap-mocha-reporter":                 true,
-	"tap-parser":                         true,
-	"tape":                               true,
-	"tapestat":                           true,
-	"tar":                                true,
-	"tarcat":                             true,
-	"taskset":                            true,
-	"tee":                                true,
-	"telinit":                            true,
-	"tempfile":                           true,
-	"terser":                             true,
-	"test":                               true,
-	"then":                               true,
-	"tic":                                true,
-	"time":                               true,
-	"timedatectl":                        true,
-	"timeout":                            true,
-	"times":                              true,
-	"tload":                              true,
-	"toe":                                true,
-	"top":                                true,
-	"touch":                              true,
-	"tput":                               true,
-	"tr":                                 true,
-	"trap":                               true,
-	"true":                               true,
-	"truncate":                           true,
-	"tsc":                                true,
-	"tset":                               true,
-	"tsort":                              true,
-	"tsserver":                           true,
-	"tty":                                true,
-	"tune2fs":                            true,
-	"type":                               true,
-	"typeset":                            true,
-	"tzselect":                           true,
-	"ucf":                                true,
-	"ucfq":                               true,
-	"ucfr":                               true,
-	"uclampset":                          true,
-	"ulimit":                             true,
-	"umask":                              true,
-	"umount":                             true,
-	"unalias":                            true,
-	"uname":                              true,
-	"unattended-upgrade":                 true,
-	"unattended-upgrades":                true,
-	"uncompress":                         true,
-	"unexpand":                           true,
-	"uniq":                               true,
-	"unix_chkpwd":                        true,
-	"unix_update":                        true,
-	"unlink":                             true,
-	"unlzma":                             true,
-	"unminimize":                         true,
-	"unset":                              true,
-	"unshare":                            true,
-	"until":                              true,
-	"unxz":                               true,
-	"unzip":                              true,
-	"unzipsfx":                           true,
-	"update-alternatives":                true,
-	"update-ca-certificates":             true,
-	"update-catalog":                     true,
-	"update-mime-database":               true,
-	"update-passwd":                      true,
-	"update-rc.d":                        true,
-	"update-shells":                      true,
-	"update-xmlcatalog":                  true,
-	"uptime":                             true,
-	"useradd":                            true,
-	"userdel":                            true,
-	"usermod":                            true,
-	"userpath":                           true,
-	"users":                              true,
-	"utmpdump":                           true,
-	"varlinkctl":                         true,
-	"vdir":                               true,
-	"viewres":                            true,
-	"vigr":                               true,
-	"vipw":                               true,
-	"visudo":                             true,
-	"vl2pdf":                             true,
-	"vl2png":                             true,
-	"vl2svg":                             true,
-	"vl2vg":                              true,
-	"vmstat":                             true,
-	"w":                                  true,
-	"wait":                               true,
-	"wall":                               true,
-	"watch":                              true,
-	"watchgnupg":                         true,
-	"wc":                                 true,
-	"wdctl":                              true,
-	"webpack":                            true,
-	"webpack-cli":                        true,
-	"wget":                               true,
-	"whereis":                            true,
-	"which":                              true,
-	"which.debianutils":                  true,
-	"while":                              true,
-	"who":                                true,
-	"whoami":                             true,
-	"wipefs":                             true,
-	"wscat":                              true,
-	"x-terminal-emulator":                true,
-	"x86_64":                             true,
-	"x86_64-linux-gnu-addr2line":         true,
-	"x86_64-linux-gnu-ar":                true,
-	"x86_64-linux-gnu-as":                true,
-	"x86_64-linux-gnu-c++filt":           true,
-	"x86_64-linux-gnu-cpp":               true,
-	"x86_64-linux-gnu-cpp-13":            true,
-	"x86_64-linux-gnu-dwp":               true,
-	"x86_64-linux-gnu-elfedit":           true,
-	"x86_64-linux-gnu-g++":               true,
-	"x86_64-linux-gnu-g++-13":            true,
-	"x86_64-linux-gnu-gcc":               true,
-	"x86_64-linux-gnu-gcc-13":            true,
-	"x86_64-linux-gnu-gcc-ar":            true,
-	"x86_64-linux-gnu-gcc-ar-13":         true,
-	"x86_64-linux-gnu-gcc-nm":            true,
-	"x86_64-linux-gnu-gcc-nm-13":         true,
-	"x86_64-linux-gnu-gcc-ranlib":        true,
-	"x86_64-linux-gnu-gcc-ranlib-13":     true,
-	"x86_64-linux-gnu-gcov":              true,
-	"x86_64-linux-gnu-gcov-13":           true,
-	"x86_64-linux-gnu-gcov-dump":         true,
-	"x86_64-linux-gnu-gcov-dump-13":      true,
-	"x86_64-linux-gnu-gcov-tool":         true,
-	"x86_64-linux-gnu-gcov-tool-13":      true,
-	"x86_64-linux-gnu-gold":              true,
-	"x86_64-linux-gnu-gp-archive":        true,
-	"x86_64-linux-gnu-gp-collect-app":    true,
-	"x86_64-linux-gnu-gp-display-html":   true,
-	"x86_64-linux-gnu-gp-display-src":    true,
-	"x86_64-linux-gnu-gp-display-text":   true,
-	"x86_64-linux-gnu-gprof":             true,
-	"x86_64-linux-gnu-gprofng":           true,
-	"x86_64-linux-gnu-ld":                true,
-	"x86_64-linux-gnu-ld.bfd":            true,
-	"x86_64-linux-gnu-ld.gold":           true,
-	"x86_64-linux-gnu-lto-dump":          true,
-	"x86_64-linux-gnu-lto-dump-13":       true,
-	"x86_64-linux-gnu-nm":                true,
-	"x86_64-linux-gnu-objcopy":           true,
-	"x86_64-linux-gnu-objdump":           true,
-	"x86_64-linux-gnu-python3-config":    true,
-	"x86_64-linux-gnu-python3.12-config": true,
-	"x86_64-linux-gnu-ranlib":            true,
-	"x86_64-linux-gnu-readelf":           true,
-	"x86_64-linux-gnu-size":              true,
-	"x86_64-linux-gnu-strings":           true,
-	"x86_64-linux-gnu-strip":             true,
-	"xargs":                              true,
-	"xauth":                              true,
-	"xcmsdb":                             true,
-	"xdg-desktop-icon":                   true,
-	"xdg-desktop-menu":                   true,
-	"xdg-email":                          true,
-	"xdg-icon-resource":                  true,
-	"xdg-mime":                           true,
-	"xdg-open":                           true,
-	"xdg-screensaver":                    true,
-	"xdg-settings":                       true,
-	"xdg-user-dir":                       true,
-	"xdg-user-dirs-update":               true,
-	"xdpyinfo":                           true,
-	"xdriinfo":                           true,
-	"xev":                                true,
-	"xfd":                                true,
-	"xfontsel":                           true,
-	"xgamma":                             true,
-	"xhost":                              true,
-	"xkeystone":                          true,
-	"xkill":                              true,
-	"xlsatoms":                           true,
-	"xlsclients":                         true,
-	"xlsfonts":                           true,
-	"xmessage":                           true,
-	"xmodmap":                            true,
-	"xprop":                              true,
-	"xrandr":                             true,
-	"xrdb":                               true,
-	"xrefresh":                           true,
-	"xset":                               true,
-	"xsetmode":                           true,
-	"xsetpointer":                        true,
-	"xsetroot":                           true,
-	"xstdcmap":                           true,
-	"xsubpp":                             true,
-	"xvidtune":                           true,
-	"xvinfo":                             true,
-	"xwininfo":                           true,
-	"xz":                                 true,
-	"xzcat":                              true,
-	"xzcmp":                              true,
-	"xzdiff":                             true,
-	"xzegrep":                            true,
-	"xzfgrep":                            true,
-	"xzgrep":                             true,
-	"xzless":                             true,
-	"xzmore":                             true,
-	"yarn":                               true,
-	"yarnpkg":                            true,
-	"yes":                                true,
-	"ypdomainname":                       true,
-	"yq":                                 true,
-	"zcat":                               true,
-	"zcmp":                               true,
-	"zdiff":                              true,
-	"zdump":                              true,
-	"zegrep":                             true,
-	"zfgrep":                             true,
-	"zforce":                             true,
-	"zgrep":                              true,
-	"zic":                                true,
-	"zipdetails":                         true,
-	"zipgrep":                            true,
-	"zipinfo":                            true,
-	"zless":                              true,
-	"zmore":                              true,
-	"znew":                               true,
-	"zramctl":                            true,
-	"zutty":                              true,
-	"{":                                  true,
-	"}":                                  true,
+// MissingCommands returns the commands available on ubuntu-latest but not on
+// ubuntu-slim, sorted by name
+func MissingCommands() []CommandInfo {
+	var missing []CommandInfo
+	for name := range ubuntuLatestCommands {
+		if IsMissingInSlim(name) {
+			missing = append(missing, LookupCommand(name))
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+	return missing
 }
 
 // IsMissingInSlim checks if a command exists in ubuntu-latest but not in ubuntu-slim.
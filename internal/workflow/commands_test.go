@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCommandsFile(t *testing.T) {
+	defer useCommandsDB(Commands())
+
+	if !IsMissingInSlim("lsof") {
+		t.Fatal("IsMissingInSlim(lsof) = false with the built-in dataset, want true")
+	}
+
+	db := Commands()
+	db.Images[ImageSlim].Release = "ubuntu-slim/20261001.1"
+	db.Images[ImageSlim].Tools = map[string]string{"lsof": "4.95.0"}
+	data, err := db.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), CommandsFileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+	if err := LoadCommandsFile(path); err != nil {
+		t.Fatalf("LoadCommandsFile() unexpected error: %v", err)
+	}
+	if IsMissingInSlim("lsof") {
+		t.Error("IsMissingInSlim(lsof) = true, want false once the slim image lists it as a tool")
+	}
+
+	for name, content := range map[string]string{
+		"newer schema":   `{"schema": 2, "images": {}}`,
+		"missing image":  `{"schema": 1, "images": {"ubuntu-latest": {"commands": ["ls"]}}}`,
+		"not a dataset":  `[]`,
+		"invalid syntax": `{`,
+	} {
+		if _, err := ParseCommandsDB([]byte(content)); err == nil {
+			t.Errorf("ParseCommandsDB(%s) expected an error", name)
+		}
+	}
+}
+
+func TestSoftwareReportTools(t *testing.T) {
+	report := `{
+  "NodeType": "HeaderNode",
+  "Title": "Ubuntu 24.04",
+  "Children": [
+    {"NodeType": "HeaderNode", "Title": "Tools", "Children": [
+      {"NodeType": "ToolVersionNode", "ToolName": "Kubectl", "Version": "1.31.0"},
+      {"NodeType": "ToolVersionNode", "ToolName": "Azure CLI", "Version": "2.64.0"},
+      {"NodeType": "ToolVersionNode", "ToolName": "Vercel CLI", "Version": "37.4.2"},
+      {"NodeType": "ToolVersionNode", "ToolName": "Docker Compose v2", "Version": "2.27.1"},
+      {"NodeType": "ToolVersionsListNode", "ToolName": "Go", "Versions": ["1.22.6", "1.23.0"]}
+    ]}
+  ]
+}`
+	got, err := SoftwareReportTools([]byte(report))
+	if err != nil {
+		t.Fatalf("SoftwareReportTools() unexpected error: %v", err)
+	}
+	want := map[string]string{"az": "2.64.0", "go": "1.22.6, 1.23.0", "gofmt": "1.22.6, 1.23.0", "kubectl": "1.31.0", "vercel": "37.4.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SoftwareReportTools() = %v, want %v", got, want)
+	}
+
+	if _, err := SoftwareReportTools([]byte(`{"Title": "empty"}`)); err == nil {
+		t.Error("SoftwareReportTools() expected an error for a report without tools")
+	}
+}
+
+func TestLookupCommand(t *testing.T) {
+	defer useCommandsDB(Commands())
+	db := Commands()
+	db.Images[ImageLatest].Tools = map[string]string{"kubectl": "1.31.0"}
+	useCommandsDB(db)
+
+	lsof := LookupCommand("lsof")
+	if !lsof.Latest || lsof.Slim || lsof.Installer != "apt-get install lsof" {
+		t.Errorf("LookupCommand(lsof) = %+v, want missing in slim with an apt-get installer", lsof)
+	}
+	if kubectl := LookupCommand("kubectl"); kubectl.LatestVersion != "1.31.0" {
+		t.Errorf("LookupCommand(kubectl).LatestVersion = %q, want 1.31.0", kubectl.LatestVersion)
+	}
+	if bash := LookupCommand("bash"); !bash.Latest || !bash.Slim || bash.Installer != "" {
+		t.Errorf("LookupCommand(bash) = %+v, want available on both images", bash)
+	}
+
+	missing := MissingCommands()
+	found := false
+	for i, c := range missing {
+		if i > 0 && missing[i-1].Name >= c.Name {
+			t.Fatalf("MissingCommands() not sorted at %s", c.Name)
+		}
+		if c.Slim || !c.Latest {
+			t.Errorf("MissingCommands() includes %+v", c)
+		}
+		found = found || c.Name == "lsof"
+	}
+	if !found {
+		t.Error("MissingCommands() does not include lsof")
+	}
+}
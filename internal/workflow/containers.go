@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ContainerTool is an entry of the container-tooling catalog: a tool that needs
+// a container daemon or a rootless container runtime, neither of which is
+// available on ubuntu-slim.
+type ContainerTool struct {
+	Name string
+	// Commands are regular expressions matched against run commands. They must
+	// match from the command name (e.g., `\bpodman\b`), not from an argument.
+	Commands []string
+	// Actions are prefixes of uses: values (e.g., "docker" for "docker://alpine"
+	// and "docker/build-push-action@v6")
+	Actions []string
+}
+
+// containerTool is a ContainerTool with its command patterns compiled
+type containerTool struct {
+	name     string
+	commands []*regexp.Regexp
+	actions  []string
+}
+
+// defaultContainerTools is the built-in container-tooling catalog
+var defaultContainerTools = []ContainerTool{
+	{
+		Name: "docker",
+		Commands: []string{
+			`\bdocker[\s-](?:build|run|exec|ps|pull|push|tag|login)\b`,
+			`\bdocker-compose\b`,
+			`\bdocker\s+compose\b`,
+			`\bdocker\s+buildx\b`,
+		},
+		Actions: []string{"docker"},
+	},
+	{
+		Name:     "podman",
+		Commands: []string{`\bpodman(?:-compose)?\b`},
+		Actions:  []string{"redhat-actions/podman-login"},
+	},
+	{
+		Name:     "buildah",
+		Commands: []string{`\bbuildah\b`},
+		Actions:  []string{"redhat-actions/buildah-build", "redhat-actions/push-to-registry"},
+	},
+	{
+		Name:     "nerdctl",
+		Commands: []string{`\bnerdctl\b`},
+	},
+	{
+		Name:     "skopeo",
+		Commands: []string{`\bskopeo\b`},
+	},
+	{
+		Name:     "kaniko",
+		Commands: []string{`/kaniko/executor\b`},
+		Actions:  []string{"int128/kaniko-action", "aevea/action-kaniko"},
+	},
+}
+
+// containerTools is the catalog in effect: the defaults plus tools added with AddContainerTools
+var containerTools = mustCompileContainerTools(defaultContainerTools)
+
+// AddContainerTools extends the container-tooling catalog, e.g., with tools
+// from the configuration file. Entries with the name of a known tool add
+// patterns and prefixes to it.
+func AddContainerTools(tools ...ContainerTool) error {
+	compiled, err := compileContainerTools(tools)
+	if err != nil {
+		return err
+	}
+	for _, t := range compiled {
+		merged := false
+		for i := range containerTools {
+			if containerTools[i].name == t.name {
+				containerTools[i].commands = append(containerTools[i].commands, t.commands...)
+				containerTools[i].actions = append(containerTools[i].actions, t.actions...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			containerTools = append(containerTools, t)
+		}
+	}
+	return nil
+}
+
+// compileContainerTools compiles the command patterns of catalog entries
+func compileContainerTools(tools []ContainerTool) ([]containerTool, error) {
+	compiled := make([]containerTool, 0, len(tools))
+	for _, t := range tools {
+		if t.Name == "" {
+			return nil, fmt.Errorf("container tool without a name")
+		}
+		c := containerTool{name: t.Name, actions: t.Actions}
+		for _, pattern := range t.Commands {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid command pattern for container tool %s: %w", t.Name, err)
+			}
+			c.commands = append(c.commands, re)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func mustCompileContainerTools(tools []ContainerTool) []containerTool {
+	compiled, err := compileContainerTools(tools)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
@@ -0,0 +1,39 @@
+package workflow
+
+import "testing"
+
+func TestAddContainerTools(t *testing.T) {
+	saved := containerTools
+	t.Cleanup(func() { containerTools = saved })
+	containerTools = append([]containerTool(nil), saved...)
+
+	job := &Job{Steps: []Step{{Run: "finch build -t app ."}, {Uses: "my-org/image-build@v1"}}}
+	if job.HasDockerCommands() || job.HasContainerActions() {
+		t.Fatalf("finch should not be detected before it is added")
+	}
+
+	err := AddContainerTools(ContainerTool{Name: "finch", Commands: []string{`\bfinch\s+(?:build|run)\b`}, Actions: []string{"my-org/image-build"}})
+	if err != nil {
+		t.Fatalf("AddContainerTools() error = %v", err)
+	}
+	evidence := job.DockerCommandEvidence()
+	if len(evidence) != 1 || evidence[0].Tool != "finch" {
+		t.Errorf("DockerCommandEvidence() = %+v, want a finch command", evidence)
+	}
+	if !job.HasContainerActions() {
+		t.Errorf("HasContainerActions() = false, want true for my-org/image-build")
+	}
+
+	// Patterns of a known tool are merged into it
+	if err := AddContainerTools(ContainerTool{Name: "podman", Commands: []string{`\bpodman-remote\b`}}); err != nil {
+		t.Fatalf("AddContainerTools() error = %v", err)
+	}
+	evidence = (&Job{Steps: []Step{{Run: "podman-remote ps"}}}).DockerCommandEvidence()
+	if len(evidence) != 1 || evidence[0].Tool != "podman" {
+		t.Errorf("DockerCommandEvidence() = %+v, want a podman command", evidence)
+	}
+
+	if err := AddContainerTools(ContainerTool{Name: "broken", Commands: []string{`(`}}); err == nil {
+		t.Errorf("AddContainerTools() with an invalid pattern should fail")
+	}
+}
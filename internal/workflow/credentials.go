@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretReferencePattern matches secret references inside expressions (e.g., "${{ secrets.NPM_TOKEN }}")
+var secretReferencePattern = regexp.MustCompile(`\bsecrets\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// CredentialUsage returns human-readable reasons why a job handles credentials:
+// passing secrets to a reusable workflow, requesting an OIDC token
+// (id-token: write), or referencing repository/organization secrets.
+// workflowPermissions is the workflow-level permissions block, which applies
+// when the job does not define its own permissions.
+// Returns nil if the job does not handle credentials.
+func (j *Job) CredentialUsage(workflowPermissions interface{}) []string {
+	var usage []string
+
+	if j.Secrets != nil {
+		if s, ok := j.Secrets.(string); ok && s == "inherit" {
+			usage = append(usage, "inherits secrets in reusable workflow call")
+		} else {
+			usage = append(usage, "passes secrets to reusable workflow")
+		}
+	}
+
+	permissions := j.Permissions
+	if permissions == nil {
+		permissions = workflowPermissions
+	}
+	if grantsIDTokenWrite(permissions) {
+		usage = append(usage, "requests OIDC token (id-token: write)")
+	}
+
+	if secrets := j.referencedSecrets(); len(secrets) > 0 {
+		refs := make([]string, len(secrets))
+		for i, name := range secrets {
+			refs[i] = "secrets." + name
+		}
+		usage = append(usage, fmt.Sprintf("references %s", strings.Join(refs, ", ")))
+	}
+
+	return usage
+}
+
+// grantsIDTokenWrite reports whether a permissions block grants id-token: write.
+// "write-all" grants every permission, including id-token.
+func grantsIDTokenWrite(permissions interface{}) bool {
+	switch v := permissions.(type) {
+	case string:
+		return v == "write-all"
+	case map[string]any:
+		level, _ := v["id-token"].(string)
+		return level == "write"
+	default:
+		return false
+	}
+}
+
+// referencedSecrets returns the sorted names of secrets referenced by the job's
+// environment and steps. GITHUB_TOKEN is excluded since it is available to every job.
+func (j *Job) referencedSecrets() []string {
+	seen := make(map[string]bool)
+	collect := func(s string) {
+		for _, m := range secretReferencePattern.FindAllStringSubmatch(s, -1) {
+			if m[1] != "GITHUB_TOKEN" {
+				seen[m[1]] = true
+			}
+		}
+	}
+
+	walkStrings(j.Env, collect)
+	for _, step := range j.Steps {
+		collect(step.Run)
+		walkStrings(step.With, collect)
+		walkStrings(step.Env, collect)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkStrings calls fn for every string value found in v, descending into maps and slices.
+func walkStrings(v interface{}, fn func(string)) {
+	switch val := v.(type) {
+	case string:
+		fn(val)
+	case map[string]interface{}:
+		for _, item := range val {
+			walkStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range val {
+			walkStrings(item, fn)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_CredentialUsage(t *testing.T) {
+	tests := []struct {
+		name                string
+		job                 *Job
+		workflowPermissions interface{}
+		expected            []string
+	}{
+		{
+			name:     "no credentials",
+			job:      &Job{Steps: []Step{{Run: "echo ${{ github.sha }}"}}},
+			expected: nil,
+		},
+		{
+			name:     "GITHUB_TOKEN is not a credential",
+			job:      &Job{Steps: []Step{{Env: map[string]interface{}{"GH_TOKEN": "${{ secrets.GITHUB_TOKEN }}"}}}},
+			expected: nil,
+		},
+		{
+			name: "secret references in run, with and env",
+			job: &Job{
+				Env: map[string]interface{}{"A": "${{ secrets.ZED }}"},
+				Steps: []Step{
+					{Run: "npm publish --token ${{ secrets.NPM_TOKEN }}"},
+					{With: map[string]interface{}{"password": "${{ secrets.REGISTRY_PASSWORD }}"}},
+				},
+			},
+			expected: []string{"references secrets.NPM_TOKEN, secrets.REGISTRY_PASSWORD, secrets.ZED"},
+		},
+		{
+			name:     "inherited secrets",
+			job:      &Job{Secrets: "inherit"},
+			expected: []string{"inherits secrets in reusable workflow call"},
+		},
+		{
+			name:     "explicit secrets",
+			job:      &Job{Secrets: map[string]any{"token": "${{ secrets.TOKEN }}"}},
+			expected: []string{"passes secrets to reusable workflow"},
+		},
+		{
+			name:     "job-level id-token write",
+			job:      &Job{Permissions: map[string]any{"id-token": "write", "contents": "read"}},
+			expected: []string{"requests OIDC token (id-token: write)"},
+		},
+		{
+			name:                "workflow-level id-token write",
+			job:                 &Job{},
+			workflowPermissions: map[string]any{"id-token": "write"},
+			expected:            []string{"requests OIDC token (id-token: write)"},
+		},
+		{
+			name:                "job permissions override workflow permissions",
+			job:                 &Job{Permissions: map[string]any{"contents": "read"}},
+			workflowPermissions: map[string]any{"id-token": "write"},
+			expected:            nil,
+		},
+		{
+			name:                "write-all grants id-token",
+			job:                 &Job{},
+			workflowPermissions: "write-all",
+			expected:            []string{"requests OIDC token (id-token: write)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.job.CredentialUsage(tt.workflowPermissions)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CredentialUsage() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package workflow
+
+import "strings"
+
+// daemonCommands maps the commands starting daemons usually run as service
+// containers to the package providing them
+var daemonCommands = map[string]string{
+	"elasticsearch":   "elasticsearch",
+	"memcached":       "memcached",
+	"mongod":          "mongodb",
+	"mysqld":          "mysql-server",
+	"mysqld_safe":     "mysql-server",
+	"postgres":        "postgresql",
+	"rabbitmq-server": "rabbitmq-server",
+	"redis-server":    "redis-server",
+}
+
+// daemonControls maps the commands controlling a daemon to the package
+// providing them. They start it with a start argument (e.g., pg_ctl start).
+var daemonControls = map[string]string{
+	"mysql.server":  "mysql-server",
+	"pg_ctl":        "postgresql",
+	"pg_ctlcluster": "postgresql",
+}
+
+// serviceManagers are the commands starting system services by name
+// (e.g., sudo systemctl start postgresql)
+var serviceManagers = map[string]bool{
+	"service":   true,
+	"systemctl": true,
+}
+
+// ManualServiceEvidence returns the steps starting daemons usually provided
+// as service containers: daemons run directly (redis-server &, mysqld,
+// mongod), started with their control command (pg_ctl start), or started as
+// system services (service mysql start, systemctl start postgresql,
+// /etc/init.d/redis-server start). These rely on packages preinstalled on
+// ubuntu-latest but not necessarily on ubuntu-slim, and on an init system.
+// Daemons whose package is installed earlier in the job are not reported.
+// Pattern holds the daemon or service name.
+func (j *Job) ManualServiceEvidence() []Evidence {
+	var evidence []Evidence
+	installed := make(map[string]bool)
+	for _, step := range j.expandedSteps() {
+		var prev *shellCommand
+		for _, cmd := range stepCommands(step) {
+			if service, pkg := startedService(cmd); service != "" && !installed[service] && !installed[pkg] {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: cmd.Line(), Pattern: service, LineNumber: commandLineNumber(step, cmd)})
+			}
+			commands, _ := installedCommands(cmd, prev)
+			for _, c := range commands {
+				installed[c] = true
+			}
+			prev = &cmd
+		}
+	}
+	return evidence
+}
+
+// startedService returns the daemon or service cmd starts and the package
+// providing it, or "" if cmd starts none
+func startedService(cmd shellCommand) (string, string) {
+	i := cmd.commandIndex()
+	if i < 0 {
+		return "", ""
+	}
+	name, args := cmd.Name(), cmd.Words[i+1:]
+	if pkg, ok := daemonCommands[name]; ok {
+		return name, pkg
+	}
+	if pkg, ok := daemonControls[name]; ok && hasStartArg(args) {
+		return name, pkg
+	}
+	if strings.HasPrefix(cmd.Words[i], "/etc/init.d/") && hasStartArg(args) {
+		return name, name
+	}
+	if !serviceManagers[name] {
+		return "", ""
+	}
+	// service <name> start, systemctl start <name>
+	var operands []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			operands = append(operands, arg)
+		}
+	}
+	switch {
+	case name == "service" && len(operands) >= 2 && isStart(operands[1]):
+		return operands[0], operands[0]
+	case name == "systemctl" && len(operands) >= 2 && isStart(operands[0]):
+		service := strings.TrimSuffix(operands[1], ".service")
+		return service, service
+	case name == "systemctl" && len(operands) >= 2 && operands[0] == "enable" && hasArg(args, "--now"):
+		service := strings.TrimSuffix(operands[1], ".service")
+		return service, service
+	}
+	return "", ""
+}
+
+// hasStartArg reports whether args include an argument starting a daemon
+func hasStartArg(args []string) bool {
+	for _, arg := range args {
+		if isStart(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStart reports whether arg starts a daemon (start or restart)
+func isStart(arg string) bool {
+	return arg == "start" || arg == "restart"
+}
+
+// hasArg reports whether args include arg
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
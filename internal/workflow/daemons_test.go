@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_ManualServiceEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *Job
+		want []string
+	}{
+		{
+			name: "daemons run directly",
+			job: &Job{Steps: []Step{
+				{Run: "redis-server --daemonize yes\nmongod --fork --logpath /tmp/mongod.log &"},
+				{Run: "sudo -u postgres pg_ctl -D /tmp/pg start\npg_ctl status"},
+			}},
+			want: []string{"redis-server", "mongod", "pg_ctl"},
+		},
+		{
+			name: "system services",
+			job: &Job{Steps: []Step{
+				{Run: "sudo systemctl start postgresql.service\nsudo service mysql start\nsudo /etc/init.d/memcached restart"},
+				{Run: "sudo systemctl enable --now redis-server\nsystemctl status postgresql\nservice --status-all"},
+			}},
+			want: []string{"postgresql", "mysql", "memcached", "redis-server"},
+		},
+		{
+			name: "installed earlier in the job",
+			job: &Job{Steps: []Step{
+				{Run: "sudo apt-get install -y redis-server postgresql"},
+				{Run: "redis-server &\nsudo service postgresql start\nsudo service mysql start"},
+			}},
+			want: []string{"mysql"},
+		},
+		{
+			name: "no daemons",
+			job:  &Job{Steps: []Step{{Run: "redis-cli ping\npsql -c 'select 1'"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.job.ManualServiceEvidence() {
+				got = append(got, e.Pattern)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ManualServiceEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DispatchInput is an input of the workflow_dispatch or workflow_call trigger
+type DispatchInput struct {
+	Type    string      `yaml:"type"`
+	Default interface{} `yaml:"default"`
+	Options []string    `yaml:"options"` // Values of a choice input
+}
+
+// DefaultValue returns the input's default value, or "" if it has none
+func (in *DispatchInput) DefaultValue() string {
+	if in.Default == nil {
+		return ""
+	}
+	return fmt.Sprint(in.Default)
+}
+
+// Values returns the values the input can take: the options of a choice
+// input (plus the default if it is not among them), or the default otherwise.
+// Returns nil if the values cannot be enumerated.
+func (in *DispatchInput) Values() []string {
+	def := in.DefaultValue()
+	values := append([]string(nil), in.Options...)
+	if def != "" && !contains(values, def) {
+		values = append(values, def)
+	}
+	return values
+}
+
+// parseInputs returns the inputs of a trigger (workflow_dispatch or
+// workflow_call) of a workflow's on: value
+func parseInputs(on any, trigger string) map[string]*DispatchInput {
+	triggers, ok := on.(map[string]any)
+	if !ok {
+		return nil
+	}
+	event, ok := triggers[trigger].(map[string]any)
+	if !ok {
+		return nil
+	}
+	data, err := yaml.Marshal(event["inputs"])
+	if err != nil {
+		return nil
+	}
+	var inputs map[string]*DispatchInput
+	if err := yaml.Unmarshal(data, &inputs); err != nil {
+		return nil
+	}
+	return inputs
+}
+
+// Input returns the workflow_dispatch or workflow_call input with the given
+// name, or nil if neither trigger has it. The default of a workflow_call
+// input is added to the values of a workflow_dispatch input of the same name.
+func (w *Workflow) Input(name string) *DispatchInput {
+	dispatch, call := w.DispatchInputs[name], w.CallInputs[name]
+	switch {
+	case dispatch == nil:
+		return call
+	case call == nil || call.DefaultValue() == "" || contains(dispatch.Values(), call.DefaultValue()):
+		return dispatch
+	}
+	merged := *dispatch
+	merged.Options = append(append([]string(nil), dispatch.Values()...), call.DefaultValue())
+	return &merged
+}
+
+// inputExpressionPattern matches an expression that is a single input (e.g., ${{ inputs.runner }})
+var inputExpressionPattern = regexp.MustCompile(`^\$\{\{\s*(?:github\.event\.)?inputs\.([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}$`)
+
+// RunnerInput returns the name of the input that runs-on is set to
+// (runs-on: ${{ inputs.runner }}), or false if runs-on is not a single input
+func (j *Job) RunnerInput() (string, bool) {
+	s, ok := j.RunsOn.(string)
+	if !ok {
+		return "", false
+	}
+	m := inputExpressionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// variableExpressionPattern matches an expression that is a single
+// configuration variable (e.g., ${{ vars.DEFAULT_RUNNER }})
+var variableExpressionPattern = regexp.MustCompile(`^\$\{\{\s*vars\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+// RunnerVariable returns the name of the configuration variable that runs-on
+// is set to (runs-on: ${{ vars.DEFAULT_RUNNER }}), or false if runs-on is not
+// a single variable
+func (j *Job) RunnerVariable() (string, bool) {
+	s, ok := j.RunsOn.(string)
+	if !ok {
+		return "", false
+	}
+	m := variableExpressionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// dispatchTrigger is the trigger that runs a workflow on demand
+const dispatchTrigger = "workflow_dispatch"
+
+// AddDispatchTrigger adds the workflow_dispatch trigger to the on: value of
+// a workflow's content, so that it can be run on demand, and reports whether
+// it was added: content that already has it is returned unchanged. on: may be
+// a single event, a list of events, or a block mapping of events.
+func AddDispatchTrigger(data []byte) ([]byte, bool, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, false, err
+	}
+	_, on := mappingValue(root, "on")
+	if on == nil {
+		return nil, false, fmt.Errorf("workflow has no on: triggers")
+	}
+	if hasTrigger(on, dispatchTrigger) {
+		return data, false, nil
+	}
+
+	edit, err := dispatchTriggerEdit(data, on)
+	if err != nil {
+		return nil, false, err
+	}
+	updated := applyEdits(data, []textEdit{edit})
+	if root, err := parseDocument(updated); err != nil {
+		return nil, false, fmt.Errorf("result is not valid YAML: %w", err)
+	} else if _, on := mappingValue(root, "on"); !hasTrigger(on, dispatchTrigger) {
+		return nil, false, fmt.Errorf("failed to add the %s trigger", dispatchTrigger)
+	}
+	return updated, true, nil
+}
+
+// hasTrigger reports whether an on: value node has the given event
+func hasTrigger(on *yaml.Node, event string) bool {
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return on.Value == event
+	case yaml.SequenceNode:
+		for _, n := range on.Content {
+			if n.Value == event {
+				return true
+			}
+		}
+	case yaml.MappingNode:
+		k, _ := mappingValue(on, event)
+		return k != nil
+	}
+	return false
+}
+
+// dispatchTriggerEdit returns the edit adding workflow_dispatch to an on:
+// value node
+func dispatchTriggerEdit(data []byte, on *yaml.Node) (textEdit, error) {
+	switch {
+	case on.Kind == yaml.ScalarNode && on.Style == 0:
+		// on: push → on: [push, workflow_dispatch]
+		offset, err := nodeOffset(data, on)
+		if err != nil {
+			return textEdit{}, err
+		}
+		return textEdit{offset: offset, length: len(on.Value), text: "[" + on.Value + ", " + dispatchTrigger + "]"}, nil
+	case on.Kind == yaml.SequenceNode && on.Style == yaml.FlowStyle && len(on.Content) > 0:
+		// on: [push, pull_request] → on: [push, pull_request, workflow_dispatch]
+		last := on.Content[len(on.Content)-1]
+		if last.Style != 0 {
+			break
+		}
+		offset, err := nodeOffset(data, last)
+		if err != nil {
+			return textEdit{}, err
+		}
+		return textEdit{offset: offset + len(last.Value), text: ", " + dispatchTrigger}, nil
+	case (on.Kind == yaml.SequenceNode || on.Kind == yaml.MappingNode) && on.Style != yaml.FlowStyle && len(on.Content) > 0:
+		// A block list item or mapping key before the first one, indented like it
+		first := on.Content[0]
+		offset, err := nodeOffset(data, first)
+		if err != nil {
+			return textEdit{}, err
+		}
+		start := strings.LastIndexByte(string(data[:offset]), '\n') + 1
+		prefix := string(data[start:offset])
+		if on.Kind == yaml.SequenceNode && strings.TrimSpace(prefix) != "-" || on.Kind == yaml.MappingNode && strings.TrimSpace(prefix) != "" {
+			return textEdit{}, fmt.Errorf("the first trigger at line %d does not start its line", first.Line)
+		}
+		text := prefix + dispatchTrigger
+		if on.Kind == yaml.MappingNode {
+			text += ":"
+		}
+		return textEdit{offset: start, text: text + newline(data)}, nil
+	}
+	return textEdit{}, fmt.Errorf("on: at line %d cannot be edited", on.Line)
+}
@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadWorkflow_DispatchInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on:
+  push:
+  workflow_dispatch:
+    inputs:
+      runner:
+        type: choice
+        default: ubuntu-latest
+        options: [ubuntu-latest, windows-latest]
+      image:
+        default: ubuntu-22.04
+jobs:
+  build:
+    runs-on: ${{ inputs.runner }}
+    steps:
+      - run: echo hi
+  legacy:
+    runs-on: ${{ github.event.inputs.image }}
+    steps:
+      - run: echo hi
+  matrix:
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: echo hi
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error = %v", err)
+	}
+
+	runner := wf.DispatchInputs["runner"]
+	if runner == nil || strings.Join(runner.Values(), ",") != "ubuntu-latest,windows-latest" || runner.DefaultValue() != "ubuntu-latest" {
+		t.Errorf("runner input = %+v, want choice of ubuntu-latest, windows-latest", runner)
+	}
+	if image := wf.DispatchInputs["image"]; image == nil || strings.Join(image.Values(), ",") != "ubuntu-22.04" {
+		t.Errorf("image input = %+v, want default ubuntu-22.04", image)
+	}
+
+	tests := map[string]string{"build": "runner", "legacy": "image", "matrix": ""}
+	for jobID, want := range tests {
+		got, ok := wf.Jobs[jobID].RunnerInput()
+		if got != want || ok != (want != "") {
+			t.Errorf("%s: RunnerInput() = %q, %v, want %q", jobID, got, ok, want)
+		}
+	}
+}
+
+func TestLoadWorkflow_CallInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reusable.yml")
+	content := `on:
+  workflow_call:
+    inputs:
+      runner:
+        type: string
+        default: ubuntu-latest
+      os:
+        type: string
+        default: ubuntu-latest
+  workflow_dispatch:
+    inputs:
+      os:
+        type: choice
+        default: windows-latest
+        options: [windows-latest, macos-latest]
+jobs:
+  build:
+    runs-on: ${{ inputs.runner }}
+    steps:
+      - run: echo hi
+  configured:
+    runs-on: ${{ vars.DEFAULT_RUNNER }}
+    steps:
+      - run: echo hi
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error = %v", err)
+	}
+
+	if runner := wf.Input("runner"); runner == nil || strings.Join(runner.Values(), ",") != "ubuntu-latest" {
+		t.Errorf("Input(runner) = %+v, want the workflow_call default ubuntu-latest", runner)
+	}
+	// The workflow_call default is one more value of the workflow_dispatch input
+	if os := wf.Input("os"); os == nil || strings.Join(os.Values(), ",") != "windows-latest,macos-latest,ubuntu-latest" || os.DefaultValue() != "windows-latest" {
+		t.Errorf("Input(os) = %+v, want windows-latest (default), macos-latest, ubuntu-latest", os)
+	}
+	if in := wf.Input("missing"); in != nil {
+		t.Errorf("Input(missing) = %+v, want nil", in)
+	}
+
+	if name, ok := wf.Jobs["configured"].RunnerVariable(); !ok || name != "DEFAULT_RUNNER" {
+		t.Errorf("configured: RunnerVariable() = %q, %v, want DEFAULT_RUNNER", name, ok)
+	}
+	if name, ok := wf.Jobs["build"].RunnerVariable(); ok {
+		t.Errorf("build: RunnerVariable() = %q, want none", name)
+	}
+}
+
+func TestAddDispatchTrigger(t *testing.T) {
+	jobs := "jobs:\n  build:\n    runs-on: ubuntu-latest\n"
+	tests := []struct {
+		name    string
+		on      string
+		want    string
+		added   bool
+		wantErr bool
+	}{
+		{name: "single event", on: "on: push # CI\n", want: "on: [push, workflow_dispatch] # CI\n", added: true},
+		{name: "flow list", on: "on: [push, pull_request]\n", want: "on: [push, pull_request, workflow_dispatch]\n", added: true},
+		{name: "block list", on: "on:\n  - push\n", want: "on:\n  - workflow_dispatch\n  - push\n", added: true},
+		{
+			name:  "block mapping",
+			on:    "on:\n  push:\n    branches: [main]\n",
+			want:  "on:\n  workflow_dispatch:\n  push:\n    branches: [main]\n",
+			added: true,
+		},
+		{name: "already dispatchable", on: "on:\n  workflow_dispatch:\n", want: "on:\n  workflow_dispatch:\n"},
+		{name: "flow mapping", on: "on: {push: {branches: [main]}}\n", wantErr: true},
+		{name: "no triggers", on: "name: CI\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, added, err := AddDispatchTrigger([]byte(tt.on + jobs))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AddDispatchTrigger() = %q, expected an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddDispatchTrigger() error: %v", err)
+			}
+			if string(got) != tt.want+jobs || added != tt.added {
+				t.Errorf("AddDispatchTrigger() = %q, %v, want %q, %v", got, added, tt.want+jobs, tt.added)
+			}
+		})
+	}
+}
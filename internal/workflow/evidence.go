@@ -0,0 +1,49 @@
+package workflow
+
+import "strings"
+
+// Evidence locates what triggered a migration check, for auditing decisions
+type Evidence struct {
+	// Step identifies the step (its name, uses: value, or first line of run:)
+	Step string `json:"step,omitempty"`
+	// Source is the repository script, Makefile target, or local action the
+	// match comes from, when it is not in the workflow file itself
+	Source string `json:"source,omitempty"`
+	// Line is the matched command, uses: value, or YAML value
+	Line string `json:"line,omitempty"`
+	// Pattern is the pattern, prefix, or name that matched
+	Pattern string `json:"pattern,omitempty"`
+	// Tool is the container tool the pattern belongs to (e.g., podman)
+	Tool string `json:"tool,omitempty"`
+	// LineNumber is the line of the workflow file the match is on: the line
+	// of the command in the run: script (or of the command running the
+	// script the match comes from), or of the step. 0 if unknown.
+	LineNumber int `json:"line_number,omitempty"`
+}
+
+// maxStepLabelLength limits step labels derived from run: scripts
+const maxStepLabelLength = 60
+
+// stepLabel returns a human-readable identifier for a step
+func stepLabel(step Step) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	if step.Uses != "" {
+		return step.Uses
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(step.Run), "\n")
+	if runes := []rune(line); len(runes) > maxStepLabelLength {
+		line = string(runes[:maxStepLabelLength]) + "..."
+	}
+	return line
+}
+
+// commandLineNumber returns the line of the workflow file a command of a
+// step's run: script is on, or the line of the step if it is not known
+func commandLineNumber(step Step, cmd shellCommand) int {
+	if step.RunLine > 0 && cmd.Offset >= 0 {
+		return step.RunLine + cmd.Offset
+	}
+	return step.Pos.Line
+}
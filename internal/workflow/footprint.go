@@ -0,0 +1,278 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FootprintLimits are the thresholds of the disk and memory footprint
+// heuristics (see DiskFootprintEvidence and MemoryFootprintEvidence)
+type FootprintLimits struct {
+	// MaxHeap is the largest heap, in bytes, a job may give the JVM (-Xmx) or
+	// Node.js (--max-old-space-size) without being reported
+	MaxHeap int64
+	// HeavyPaths are cache paths growing to several GB, reported when restored
+	// with actions/cache (e.g., "~/.gradle/caches")
+	HeavyPaths []string
+	// HeavyCommands are build tools whose builds need much memory (e.g., gradle)
+	HeavyCommands []string
+}
+
+// DefaultMaxHeap is the default FootprintLimits.MaxHeap
+const DefaultMaxHeap = 3 << 30
+
+// defaultHeavyPaths are the caches of the package managers and build tools
+// known to grow to several GB
+var defaultHeavyPaths = []string{
+	"~/.android",
+	"~/.cache/bazel",
+	"~/.cache/bazelisk",
+	"~/.gradle",
+	"~/.konan",
+	"~/.m2",
+	"/usr/local/lib/android",
+}
+
+// defaultHeavyCommands are the Android, Bazel, and Gradle build tools
+var defaultHeavyCommands = []string{
+	"avdmanager",
+	"bazel",
+	"bazelisk",
+	"emulator",
+	"gradle",
+	"gradlew",
+	"sdkmanager",
+}
+
+// heavyActions are prefixes of uses: values of actions setting up Android,
+// Bazel, and Gradle builds
+var heavyActions = []string{
+	"android-actions/setup-android",
+	"reactivecircus/android-emulator-runner",
+	"gradle/actions/setup-gradle",
+	"gradle/gradle-build-action",
+	"bazelbuild/setup-bazelisk",
+	"bazel-contrib/setup-bazel",
+}
+
+// footprintLimits are the limits in effect, set with SetFootprintLimits
+var footprintLimits = FootprintLimits{
+	MaxHeap:       DefaultMaxHeap,
+	HeavyPaths:    defaultHeavyPaths,
+	HeavyCommands: defaultHeavyCommands,
+}
+
+// SetFootprintLimits sets the thresholds of the footprint heuristics, e.g.,
+// from the configuration file. A zero MaxHeap keeps the default, and heavy
+// paths and commands are added to the built-in ones.
+func SetFootprintLimits(limits FootprintLimits) error {
+	if limits.MaxHeap < 0 {
+		return fmt.Errorf("max heap must not be negative")
+	}
+	for _, p := range limits.HeavyPaths {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("empty heavy path")
+		}
+	}
+	for _, c := range limits.HeavyCommands {
+		if strings.TrimSpace(c) == "" {
+			return fmt.Errorf("empty heavy command")
+		}
+	}
+	if limits.MaxHeap > 0 {
+		footprintLimits.MaxHeap = limits.MaxHeap
+	}
+	footprintLimits.HeavyPaths = append(footprintLimits.HeavyPaths, limits.HeavyPaths...)
+	footprintLimits.HeavyCommands = append(footprintLimits.HeavyCommands, limits.HeavyCommands...)
+	return nil
+}
+
+// sizePattern matches a size with an optional binary unit (e.g., "4g", "512M")
+var sizePattern = regexp.MustCompile(`^(\d+)([kKmMgG]?)$`)
+
+// ParseSize parses a size like the JVM's -Xmx does: a number of bytes,
+// optionally followed by k, m, or g (e.g., "512m", "4g")
+func ParseSize(s string) (int64, error) {
+	m := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q (e.g., 512m, 4g)", s)
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		n <<= 10
+	case "m":
+		n <<= 20
+	case "g":
+		n <<= 30
+	}
+	return n, nil
+}
+
+// heapPatterns match the heap sizes given to the JVM (-Xmx4g, also in
+// org.gradle.jvmargs) and Node.js (--max-old-space-size=4096, in MB)
+var (
+	jvmHeapPattern  = regexp.MustCompile(`-Xmx(\d+[kKmMgG]?)\b`)
+	nodeHeapPattern = regexp.MustCompile(`--max-old-space-size[= ](\d+)\b`)
+)
+
+// largeHeap returns the first heap size in s above the maximum heap, as
+// written (e.g., "-Xmx6g"), or "" if there is none
+func largeHeap(s string) string {
+	for _, m := range jvmHeapPattern.FindAllStringSubmatch(s, -1) {
+		if n, err := ParseSize(m[1]); err == nil && n > footprintLimits.MaxHeap {
+			return m[0]
+		}
+	}
+	for _, m := range nodeHeapPattern.FindAllStringSubmatch(s, -1) {
+		if n, err := strconv.ParseInt(m[1], 10, 64); err == nil && n<<20 > footprintLimits.MaxHeap {
+			return m[0]
+		}
+	}
+	return ""
+}
+
+// DiskFootprintEvidence returns the places where the job is likely to fill
+// the smaller disk of ubuntu-slim: checkouts with Git LFS or submodules
+// (actions/checkout with lfs: or submodules:, git lfs pull, git submodule
+// update, git clone --recurse-submodules), downloads of all the run's
+// artifacts (actions/download-artifact without name: or pattern:), and
+// caches restored from heavy paths (see FootprintLimits.HeavyPaths). Pattern
+// holds what matched (e.g., "lfs", "~/.gradle").
+func (j *Job) DiskFootprintEvidence() []Evidence {
+	var evidence []Evidence
+	for _, step := range j.expandedSteps() {
+		action, _, _ := strings.Cut(step.Uses, "@")
+		switch {
+		case action == "actions/checkout":
+			for _, key := range []string{"lfs", "submodules"} {
+				if value := fmt.Sprint(step.With[key]); step.With[key] != nil && value != "false" {
+					evidence = append(evidence, Evidence{Step: stepLabel(step), Line: key + ": " + value, Pattern: key, LineNumber: step.Pos.Line})
+				}
+			}
+		case action == "actions/download-artifact":
+			if step.With["name"] == nil && step.With["pattern"] == nil && step.With["artifact-ids"] == nil {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Line: step.Uses, Pattern: "all artifacts", LineNumber: step.Pos.Line})
+			}
+		case action == "actions/cache" || action == "actions/cache/restore":
+			paths, _ := step.With["path"].(string)
+			for _, path := range strings.Split(paths, "\n") {
+				if heavy := heavyPath(path); heavy != "" {
+					evidence = append(evidence, Evidence{Step: stepLabel(step), Line: "path: " + strings.TrimSpace(path), Pattern: heavy, LineNumber: step.Pos.Line})
+				}
+			}
+		}
+
+		for _, cmd := range stepCommands(step) {
+			if pattern := gitCheckoutFootprint(cmd); pattern != "" {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: cmd.Line(), Pattern: pattern, LineNumber: commandLineNumber(step, cmd)})
+			}
+		}
+	}
+	return evidence
+}
+
+// heavyPath returns the heavy path a cache path is in, or "" if there is none
+func heavyPath(path string) string {
+	path = strings.TrimSpace(path)
+	for _, home := range []string{"$HOME/", "${HOME}/", "${{ env.HOME }}/"} {
+		if strings.HasPrefix(path, home) {
+			path = "~/" + strings.TrimPrefix(path, home)
+		}
+	}
+	for _, heavy := range footprintLimits.HeavyPaths {
+		heavy = strings.TrimSuffix(heavy, "/")
+		if path == heavy || strings.HasPrefix(path, heavy+"/") {
+			return heavy
+		}
+	}
+	return ""
+}
+
+// gitCheckoutFootprint returns "lfs" or "submodules" if cmd fetches Git LFS
+// objects or submodules, or "" otherwise
+func gitCheckoutFootprint(cmd shellCommand) string {
+	i := cmd.commandIndex()
+	if cmd.Name() != "git" || len(cmd.Words) < i+2 {
+		return ""
+	}
+	switch args := cmd.Words[i+1:]; args[0] {
+	case "lfs":
+		if len(args) > 1 && (args[1] == "pull" || args[1] == "fetch" || args[1] == "checkout") {
+			return "lfs"
+		}
+	case "submodule":
+		if len(args) > 1 && args[1] == "update" {
+			return "submodules"
+		}
+	case "clone", "pull", "fetch":
+		for _, arg := range args[1:] {
+			if arg == "--recursive" || (strings.HasPrefix(arg, "--recurse-submodules") && arg != "--recurse-submodules=no") {
+				return "submodules"
+			}
+		}
+	}
+	return ""
+}
+
+// MemoryFootprintEvidence returns the places where the job is likely to
+// exceed the smaller memory of ubuntu-slim: Android, Bazel, and Gradle builds
+// (the first use of each build tool of FootprintLimits.HeavyCommands, and
+// actions setting them up), and heaps above FootprintLimits.MaxHeap given to
+// the JVM or Node.js in commands or environment variables (e.g., GRADLE_OPTS:
+// -Xmx6g). Pattern holds what matched (e.g., "gradlew", "-Xmx6g").
+func (j *Job) MemoryFootprintEvidence() []Evidence {
+	heavy := make(map[string]bool)
+	for _, c := range footprintLimits.HeavyCommands {
+		heavy[c] = true
+	}
+
+	evidence := envHeapEvidence(j.Env, "", j.LineStart)
+	seen := make(map[string]bool)
+	for _, step := range j.expandedSteps() {
+		for _, prefix := range heavyActions {
+			if strings.HasPrefix(step.Uses, prefix) && !seen[prefix] {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Line: step.Uses, Pattern: prefix, LineNumber: step.Pos.Line})
+				seen[prefix] = true
+			}
+		}
+		evidence = append(evidence, envHeapEvidence(step.Env, stepLabel(step), step.Pos.Line)...)
+
+		for _, cmd := range stepCommands(step) {
+			line := cmd.Line()
+			if name := cmd.Name(); heavy[name] && !seen[name] {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: line, Pattern: name, LineNumber: commandLineNumber(step, cmd)})
+				seen[name] = true
+			}
+			if heap := largeHeap(line); heap != "" {
+				evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: line, Pattern: heap, LineNumber: commandLineNumber(step, cmd)})
+			}
+		}
+	}
+	return evidence
+}
+
+// envHeapEvidence returns the environment variables giving a heap above the
+// maximum heap (e.g., JAVA_OPTS, GRADLE_OPTS, NODE_OPTIONS), in name order
+func envHeapEvidence(env map[string]interface{}, step string, line int) []Evidence {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var evidence []Evidence
+	for _, name := range names {
+		value := fmt.Sprint(env[name])
+		if heap := largeHeap(value); heap != "" {
+			evidence = append(evidence, Evidence{Step: step, Line: name + ": " + value, Pattern: heap, LineNumber: line})
+		}
+	}
+	return evidence
+}
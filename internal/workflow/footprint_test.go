@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_DiskFootprintEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *Job
+		want []string
+	}{
+		{
+			name: "LFS and submodule checkouts",
+			job: &Job{Steps: []Step{
+				{Uses: "actions/checkout@v4", With: map[string]interface{}{"lfs": true, "submodules": "recursive"}},
+				{Run: "git lfs pull\ngit submodule update --init\ngit clone --recurse-submodules https://github.com/octo/app"},
+			}},
+			want: []string{"lfs", "submodules", "lfs", "submodules", "submodules"},
+		},
+		{
+			name: "downloads of all artifacts",
+			job: &Job{Steps: []Step{
+				{Uses: "actions/download-artifact@v4"},
+				{Uses: "actions/download-artifact@v4", With: map[string]interface{}{"name": "dist"}},
+			}},
+			want: []string{"all artifacts"},
+		},
+		{
+			name: "heavy cache paths",
+			job: &Job{Steps: []Step{
+				{Uses: "actions/cache@v4", With: map[string]interface{}{"path": "~/.gradle/caches\n~/.gradle/wrapper\nnode_modules\n"}},
+				{Uses: "actions/cache/restore@v4", With: map[string]interface{}{"path": "$HOME/.m2/repository"}},
+			}},
+			want: []string{"~/.gradle", "~/.gradle", "~/.m2"},
+		},
+		{
+			name: "light job",
+			job: &Job{Steps: []Step{
+				{Uses: "actions/checkout@v4", With: map[string]interface{}{"lfs": false, "fetch-depth": 0}},
+				{Uses: "actions/cache@v4", With: map[string]interface{}{"path": "~/.npm"}},
+				{Run: "git fetch origin main\ngit submodule status"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.job.DiskFootprintEvidence() {
+				got = append(got, e.Pattern)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiskFootprintEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJob_MemoryFootprintEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *Job
+		want []string
+	}{
+		{
+			name: "Android build",
+			job: &Job{Steps: []Step{
+				{Uses: "android-actions/setup-android@v3"},
+				{Run: "sdkmanager 'platforms;android-34'\n./gradlew assembleRelease\n./gradlew test"},
+			}},
+			want: []string{"android-actions/setup-android", "sdkmanager", "gradlew"},
+		},
+		{
+			name: "large heaps",
+			job: &Job{
+				Env: map[string]interface{}{"JAVA_OPTS": "-Xms1g -Xmx8g"},
+				Steps: []Step{
+					{Run: "node --max-old-space-size=8192 build.js", Env: map[string]interface{}{"NODE_OPTIONS": "--max-old-space-size=2048"}},
+					{Run: "java -Xmx512m -jar app.jar"},
+				},
+			},
+			want: []string{"-Xmx8g", "--max-old-space-size=8192"},
+		},
+		{
+			name: "light job",
+			job:  &Job{Steps: []Step{{Uses: "actions/setup-node@v4"}, {Run: "npm ci\nnpm test"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.job.MemoryFootprintEvidence() {
+				got = append(got, e.Pattern)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MemoryFootprintEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFootprintLimits(t *testing.T) {
+	saved := footprintLimits
+	t.Cleanup(func() { footprintLimits = saved })
+
+	job := &Job{Steps: []Step{
+		{Uses: "actions/cache@v4", With: map[string]interface{}{"path": "~/.cache/buck"}},
+		{Run: "buck2 build //...\njava -Xmx2g -jar app.jar"},
+	}}
+	if len(job.DiskFootprintEvidence()) > 0 || len(job.MemoryFootprintEvidence()) > 0 {
+		t.Fatalf("the job should not be reported with the default limits")
+	}
+
+	if err := SetFootprintLimits(FootprintLimits{MaxHeap: 1 << 30, HeavyPaths: []string{"~/.cache/buck/"}, HeavyCommands: []string{"buck2"}}); err != nil {
+		t.Fatalf("SetFootprintLimits() error = %v", err)
+	}
+	if e := job.DiskFootprintEvidence(); len(e) != 1 || e[0].Pattern != "~/.cache/buck" {
+		t.Errorf("DiskFootprintEvidence() = %+v, want ~/.cache/buck", e)
+	}
+	var got []string
+	for _, e := range job.MemoryFootprintEvidence() {
+		got = append(got, e.Pattern)
+	}
+	if want := []string{"buck2", "-Xmx2g"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MemoryFootprintEvidence() = %v, want %v", got, want)
+	}
+
+	if err := SetFootprintLimits(FootprintLimits{MaxHeap: -1}); err == nil {
+		t.Errorf("SetFootprintLimits() with a negative max heap should fail")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"4g", 4 << 30},
+		{"512M", 512 << 20},
+		{"64k", 64 << 10},
+		{"1024", 1024},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "4gb", "-1g", "g"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) expected error, got nil", in)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ignoreMarker starts an ignore directive comment
+const ignoreMarker = "slimify:ignore"
+
+// IgnoreDirective is a "# slimify:ignore" comment on the line of a job key or
+// on the lines just above it, which keeps the job from being migrated. It can
+// expire and give a reason:
+//
+//	# slimify:ignore until=2025-06-01 reason=flaky on ubuntu-slim
+//	lint:
+type IgnoreDirective struct {
+	Line int `json:"line"`
+	// Until is the last day (YYYY-MM-DD, UTC) the directive applies, or
+	// empty if it does not expire
+	Until  string `json:"until,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Error is why the directive is invalid (e.g., a malformed date). Invalid
+	// directives do not ignore the job.
+	Error string `json:"error,omitempty"`
+}
+
+// Active reports whether the directive ignores its job at time now: it is
+// valid and has not expired
+func (d *IgnoreDirective) Active(now time.Time) bool {
+	if d.Error != "" {
+		return false
+	}
+	if d.Until == "" {
+		return true
+	}
+	until, err := time.Parse(time.DateOnly, d.Until)
+	return err == nil && now.Before(until.AddDate(0, 0, 1))
+}
+
+// jobIgnoreDirective returns the ignore directive in the comments of a job's
+// key node, or nil if there is none. lines are the lines of the workflow file.
+func jobIgnoreDirective(key *yaml.Node, lines []string) *IgnoreDirective {
+	if key == nil {
+		return nil
+	}
+	comments := strings.Split(key.HeadComment, "\n")
+	comments = append(comments, key.LineComment)
+	for _, comment := range comments {
+		text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(comment), "#"))
+		if text != ignoreMarker && !strings.HasPrefix(text, ignoreMarker+" ") {
+			continue
+		}
+		d := parseIgnoreDirective(strings.TrimPrefix(text, ignoreMarker))
+		d.Line = commentLine(lines, key.Line, strings.TrimSpace(comment))
+		return d
+	}
+	return nil
+}
+
+// parseIgnoreDirective parses the options of an ignore directive: until=DATE
+// and reason=TEXT, where the reason extends to the end of the comment
+func parseIgnoreDirective(options string) *IgnoreDirective {
+	d := &IgnoreDirective{}
+	rest := strings.TrimSpace(options)
+	for rest != "" {
+		if reason, ok := strings.CutPrefix(rest, "reason="); ok {
+			d.Reason = strings.Trim(strings.TrimSpace(reason), `"'`)
+			break
+		}
+		field, remaining, _ := strings.Cut(rest, " ")
+		rest = strings.TrimSpace(remaining)
+		key, value, ok := strings.Cut(field, "=")
+		switch {
+		case ok && key == "until":
+			if _, err := time.Parse(time.DateOnly, value); err != nil {
+				d.Error = fmt.Sprintf("invalid until date %q (expected YYYY-MM-DD)", value)
+				continue
+			}
+			d.Until = value
+		default:
+			d.Error = fmt.Sprintf("unknown option %q (expected until=YYYY-MM-DD or reason=...)", field)
+		}
+	}
+	return d
+}
+
+// commentLine returns the line number of a comment found on the key's line or
+// above it, or the key's line if it is not found
+func commentLine(lines []string, keyLine int, comment string) int {
+	for line := keyLine; line >= 1 && line <= len(lines); line-- {
+		if strings.Contains(lines[line-1], comment) {
+			return line
+		}
+	}
+	return keyLine
+}
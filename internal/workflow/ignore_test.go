@@ -0,0 +1,72 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadWorkflow_IgnoreDirectives(t *testing.T) {
+	content := `on: push
+jobs:
+  # Unrelated comment
+  # slimify:ignore until=2025-06-01 reason=flaky on ubuntu-slim
+  lint:
+    runs-on: ubuntu-latest
+  test: # slimify:ignore reason="needs docker until #123 is fixed"
+    runs-on: ubuntu-latest
+  build:
+    runs-on: ubuntu-latest
+  e2e: # slimify:ignore until=06/01/2025
+    runs-on: ubuntu-latest
+  deploy: # slimify:ignored
+    runs-on: ubuntu-latest
+`
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		jobID string
+		want  *IgnoreDirective
+	}{
+		{"lint", &IgnoreDirective{Line: 4, Until: "2025-06-01", Reason: "flaky on ubuntu-slim"}},
+		{"test", &IgnoreDirective{Line: 7, Reason: "needs docker until #123 is fixed"}},
+		{"build", nil},
+		{"e2e", &IgnoreDirective{Line: 11, Error: `invalid until date "06/01/2025" (expected YYYY-MM-DD)`}},
+		{"deploy", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jobID, func(t *testing.T) {
+			got := wf.Jobs[tt.jobID].Ignore
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Ignore = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if line := wf.Jobs["test"].LineStart; line != 8 {
+		t.Errorf("LineStart of a job key with a comment = %d, want 8", line)
+	}
+}
+
+func TestIgnoreDirective_Active(t *testing.T) {
+	d := &IgnoreDirective{Until: "2025-06-01"}
+	if !d.Active(time.Date(2025, 6, 1, 23, 59, 0, 0, time.UTC)) {
+		t.Error("Active() = false on the until date, want true")
+	}
+	if d.Active(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Active() = true after the until date, want false")
+	}
+	if !(&IgnoreDirective{}).Active(time.Now()) {
+		t.Error("Active() = false for a directive without expiry, want true")
+	}
+	if (&IgnoreDirective{Error: "unknown option"}).Active(time.Now()) {
+		t.Error("Active() = true for an invalid directive, want false")
+	}
+}
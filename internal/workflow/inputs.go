@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScriptInput names the with: inputs of an action that take a shell script
+// the action runs (e.g., the script input of an SSH action). Commands in these
+// inputs are analyzed like the commands of run: steps.
+type ScriptInput struct {
+	// Action is the action, without a version (e.g., "appleboy/ssh-action")
+	Action string
+	// Inputs are the with: keys holding scripts (e.g., "script")
+	Inputs []string
+}
+
+// scriptInputs are the script inputs in effect, added with AddScriptInputs.
+// None are analyzed by default.
+var scriptInputs []ScriptInput
+
+// AddScriptInputs adds actions whose with: inputs are analyzed as shell
+// scripts, e.g., from the configuration file
+func AddScriptInputs(inputs ...ScriptInput) error {
+	for _, in := range inputs {
+		if in.Action == "" {
+			return fmt.Errorf("script input without an action")
+		}
+		if len(in.Inputs) == 0 {
+			return fmt.Errorf("script input for %s without inputs", in.Action)
+		}
+	}
+	scriptInputs = append(scriptInputs, inputs...)
+	return nil
+}
+
+// stepScriptInputs returns the with: keys of a step that hold scripts, for
+// the action it uses
+func stepScriptInputs(uses string) []string {
+	if uses == "" {
+		return nil
+	}
+	var keys []string
+	for _, in := range scriptInputs {
+		if uses == in.Action || strings.HasPrefix(uses, in.Action+"@") {
+			keys = append(keys, in.Inputs...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,35 @@
+package workflow
+
+import "testing"
+
+func TestAddScriptInputs(t *testing.T) {
+	saved := scriptInputs
+	t.Cleanup(func() { scriptInputs = saved })
+	scriptInputs = nil
+
+	job := &Job{
+		RunsOn: "ubuntu-latest",
+		Steps: []Step{
+			{Name: "Deploy", Uses: "appleboy/ssh-action@v1", With: map[string]interface{}{"host": "example.com", "script": "cd app\ndocker compose up -d"}},
+			{Uses: "appleboy/ssh-action-fork@v1", With: map[string]interface{}{"script": "docker build ."}},
+		},
+	}
+	if job.HasDockerCommands() || len(job.GetMissingCommands()) > 0 {
+		t.Fatalf("with: inputs should not be analyzed before they are added")
+	}
+
+	if err := AddScriptInputs(ScriptInput{Action: "appleboy/ssh-action", Inputs: []string{"script"}}); err != nil {
+		t.Fatalf("AddScriptInputs() error = %v", err)
+	}
+	evidence := job.DockerCommandEvidence()
+	if len(evidence) != 1 || evidence[0].Source != "with: script" || evidence[0].Line != "docker compose up -d" {
+		t.Errorf("DockerCommandEvidence() = %+v, want the docker command of the Deploy script", evidence)
+	}
+	if missing := job.GetMissingCommands(); len(missing) != 1 || missing[0] != "docker" {
+		t.Errorf("GetMissingCommands() = %v, want [docker]", missing)
+	}
+
+	if err := AddScriptInputs(ScriptInput{Action: "appleboy/ssh-action"}); err == nil {
+		t.Errorf("AddScriptInputs() without inputs should fail")
+	}
+}
@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// packageCommands maps packages whose commands differ from the package name
+// to the commands they provide. Packages not listed here are assumed to provide
+// a command of the same name (e.g., apt-get install lsof provides lsof).
+var packageCommands = map[string][]string{
+	// apt
+	"postgresql-client":    {"psql", "pg_dump", "pg_restore"},
+	"mysql-client":         {"mysql", "mysqldump"},
+	"default-mysql-client": {"mysql", "mysqldump"},
+	"mariadb-client":       {"mysql", "mariadb", "mysqldump"},
+	"dnsutils":             {"dig", "nslookup", "nsupdate"},
+	"bind9-dnsutils":       {"dig", "nslookup", "nsupdate"},
+	"iproute2":             {"ip", "ss", "tc"},
+	"net-tools":            {"netstat", "ifconfig", "route"},
+	"iputils-ping":         {"ping"},
+	"openssh-client":       {"ssh", "scp", "sftp", "ssh-keygen"},
+	"build-essential":      {"gcc", "g++", "make"},
+	"google-cloud-cli":     {"gcloud", "gsutil"},
+	"google-cloud-sdk":     {"gcloud", "gsutil"},
+	"ripgrep":              {"rg"},
+	"p7zip-full":           {"7z"},
+	"imagemagick":          {"convert", "identify", "magick"},
+	"powershell":           {"pwsh"},
+	"xvfb":                 {"xvfb-run", "Xvfb"},
+	"netcat-openbsd":       {"nc"},
+	"aria2":                {"aria2c"},
+	"upx-ucl":              {"upx"},
+	"ninja-build":          {"ninja"},
+	"maven":                {"mvn"},
+	// pip, npm, cargo
+	"awscli":       {"aws"},
+	"aws-sam-cli":  {"sam"},
+	"azure-cli":    {"az"},
+	"httpie":       {"http", "https"},
+	"@angular/cli": {"ng"},
+	"typescript":   {"tsc"},
+}
+
+// packageInstallers maps package manager commands to the subcommands that install packages
+var packageInstallers = map[string][]string{
+	"apt-get": {"install"},
+	"apt":     {"install"},
+	"yum":     {"install"},
+	"dnf":     {"install"},
+	"apk":     {"add"},
+	"brew":    {"install"},
+	"snap":    {"install"},
+	"pip":     {"install"},
+	"pip3":    {"install"},
+	"pipx":    {"install"},
+	"uv":      {"tool"},
+	"npm":     {"install", "i", "add"},
+	"yarn":    {"global"},
+	"pnpm":    {"add"},
+	"gem":     {"install"},
+	"cargo":   {"install"},
+	"go":      {"install"},
+}
+
+// scriptDownloaders are commands that download installer scripts to pipe into a shell
+var scriptDownloaders = map[string]bool{"curl": true, "wget": true}
+
+// installedCommands returns the commands installed by cmd, e.g., ["lsof", "tree"]
+// for "sudo apt-get install -y lsof tree". prev is the preceding command, used to
+// recognize installer scripts piped into a shell (curl ... | sh), for which the
+// downloaded URL is returned instead since the installed commands are unknown.
+func installedCommands(cmd shellCommand, prev *shellCommand) (commands []string, installerURL string) {
+	i := cmd.commandIndex()
+	if i < 0 {
+		return nil, ""
+	}
+	name := normalizeCommand(cmd.Words[i])
+	args := cmd.Words[i+1:]
+
+	if shellInterpreters[name] && cmd.Piped && prev != nil && scriptDownloaders[prev.Name()] {
+		for _, arg := range prev.Words {
+			if strings.Contains(arg, "://") {
+				return nil, arg
+			}
+		}
+		return nil, ""
+	}
+
+	subcommands, ok := packageInstallers[name]
+	if !ok {
+		return nil, ""
+	}
+
+	// Find the install subcommand, skipping global options (e.g., apt-get -qq install)
+	k := 0
+	for k < len(args) && strings.HasPrefix(args[k], "-") {
+		k++
+	}
+	if k >= len(args) || !contains(subcommands, args[k]) {
+		return nil, ""
+	}
+	args = args[k+1:]
+	// "uv tool install ruff", "yarn global add eslint"
+	if (name == "uv" && len(args) > 0 && args[0] == "install") || (name == "yarn" && len(args) > 0 && args[0] == "add") {
+		args = args[1:]
+	}
+	// Only global npm installs put commands on PATH
+	if (name == "npm" || name == "pnpm") && !contains(args, "-g") && !contains(args, "--global") {
+		return nil, ""
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.Contains(arg, "$") {
+			continue
+		}
+		commands = append(commands, packageProvidedCommands(name, arg)...)
+	}
+	return commands, ""
+}
+
+// packageProvidedCommands returns the commands provided by a package argument
+// of a package manager (e.g., "lsof=4.95" → lsof, "github.com/x/tool/cmd/foo@latest" → foo)
+func packageProvidedCommands(installer, pkg string) []string {
+	switch installer {
+	case "go":
+		pkg, _, _ = strings.Cut(pkg, "@")
+		return []string{path.Base(strings.TrimSuffix(pkg, "/..."))}
+	case "npm", "pnpm", "yarn":
+		// Strip the version, keeping scoped package names (@scope/name@1.0)
+		if at := strings.LastIndex(pkg, "@"); at > 0 {
+			pkg = pkg[:at]
+		}
+	default:
+		// Strip versions and extras: lsof=4.95, black==24.1, ruff>=0.4, httpie[socks], pkg:amd64
+		if cut := strings.IndexAny(pkg, "=<>~[:"); cut > 0 {
+			pkg = pkg[:cut]
+		}
+	}
+	if commands, ok := packageCommands[pkg]; ok {
+		return commands
+	}
+	// Unscoped name of npm packages (@scope/name → name)
+	return []string{path.Base(pkg)}
+}
+
+// installedByScript reports whether a command was likely installed by one of the
+// installer scripts, judged by the command name being a segment of the script
+// URL, or a word of one (e.g., helm for
+// https://raw.githubusercontent.com/helm/helm/main/scripts/get-helm-3). Command
+// names merely contained in a word (e.g., go in golang) do not match.
+func installedByScript(command string, installerURLs []string) bool {
+	command = strings.ToLower(command)
+	for _, rawURL := range installerURLs {
+		for _, name := range urlNames(rawURL) {
+			if name == command {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// urlNames returns the names a URL is made of, lowercased: the labels of its
+// host, its path segments with and without their extension, and the words of
+// these segments (e.g., get-helm-3 → get-helm-3, get, helm, and 3)
+func urlNames(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	names := strings.Split(strings.ToLower(u.Hostname()), ".")
+	for _, segment := range strings.Split(strings.ToLower(u.Path), "/") {
+		if segment == "" {
+			continue
+		}
+		names = append(names, segment, strings.TrimSuffix(segment, path.Ext(segment)))
+		names = append(names, strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})...)
+	}
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
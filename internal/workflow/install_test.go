@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_GetMissingCommands_InstalledEarlier(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  []string
+	}{
+		{
+			name: "apt-get install in an earlier step",
+			steps: []Step{
+				{Run: "sudo apt-get update && sudo apt-get install -y --no-install-recommends lsof tree=2.1.1-2"},
+				{Run: "lsof -i :8080\ntree ."},
+			},
+			want: nil,
+		},
+		{
+			name: "used before it is installed",
+			steps: []Step{
+				{Run: "lsof -i :8080"},
+				{Run: "sudo apt-get install -y lsof"},
+			},
+			want: []string{"lsof"},
+		},
+		{
+			name: "package providing a differently named command",
+			steps: []Step{
+				{Run: "sudo apt install -y postgresql-client dnsutils"},
+				{Run: "psql -c 'select 1'; dig example.com"},
+			},
+			want: nil,
+		},
+		{
+			name: "installer script piped into a shell",
+			steps: []Step{
+				{Run: "curl -fsSL https://raw.githubusercontent.com/helm/helm/main/scripts/get-helm-3 | bash"},
+				{Run: "helm version"},
+			},
+			want: nil,
+		},
+		{
+			name: "installer script for another tool",
+			steps: []Step{
+				{Run: "curl -fsSL https://get.example.com/tool | sh"},
+				{Run: "helm version"},
+			},
+			want: []string{"helm"},
+		},
+		{
+			name: "short command names in words of the script URL",
+			steps: []Step{
+				{Run: "curl -fsSL https://storage.googleapis.com/golang/scripts/install-tools.sh | bash"},
+				{Run: "ip addr\ngo version"},
+			},
+			want: []string{"ip", "go"},
+		},
+		{
+			name: "installer script named after the command",
+			steps: []Step{
+				{Run: "curl -fsSL https://get.example.com/uv/install.sh | sh"},
+				{Run: "curl -fsSL https://deno.land/install.sh | sh"},
+				{Run: "uv sync\ndeno test"},
+			},
+			want: nil,
+		},
+		{
+			name: "go install",
+			steps: []Step{
+				{Uses: "actions/setup-go@v5"},
+				{Run: "go install sigs.k8s.io/kind/cmd/kubectl@latest"},
+				{Run: "kubectl version"},
+			},
+			want: nil,
+		},
+		{
+			name: "local npm install does not put commands on PATH",
+			steps: []Step{
+				{Run: "npm install helm"},
+				{Run: "helm version"},
+			},
+			want: []string{"helm"},
+		},
+		{
+			name: "mentioned in a string only",
+			steps: []Step{
+				{Run: `echo "apt-get install lsof"`},
+				{Run: "lsof -i"},
+			},
+			want: []string{"lsof"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{RunsOn: "ubuntu-latest", Steps: tt.steps}
+			got := job.GetMissingCommands()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMissingCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageProvidedCommands(t *testing.T) {
+	tests := []struct {
+		installer string
+		pkg       string
+		want      []string
+	}{
+		{"apt-get", "lsof=4.95.0-1", []string{"lsof"}},
+		{"apt-get", "net-tools", []string{"netstat", "ifconfig", "route"}},
+		{"pip", "awscli>=1.32", []string{"aws"}},
+		{"pip", "httpie[socks]", []string{"http", "https"}},
+		{"npm", "@angular/cli@17", []string{"ng"}},
+		{"npm", "@scope/tool", []string{"tool"}},
+		{"go", "golang.org/x/tools/cmd/goimports@v0.20.0", []string{"goimports"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.installer+" "+tt.pkg, func(t *testing.T) {
+			if got := packageProvidedCommands(tt.installer, tt.pkg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packageProvidedCommands(%q, %q) = %v, want %v", tt.installer, tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -16,105 +17,156 @@ import (
 // References:
 // - https://github.com/marketplace?query=setup&verification=verified_creator&type=actions
 var setupActionCommands = map[string][]string{
-	"actions/setup-go":              {"go"},
-	"actions/setup-node":            {"node", "npm", "npx"},
-	"actions/setup-python":          {"python", "python3", "pip", "pip3"},
-	"actions/setup-java":            {"java", "javac", "mvn", "gradle"},
-	"actions/setup-dotnet":          {"dotnet"},
-	"actions/setup-ruby":            {"ruby", "gem"},
-	"hashicorp/setup-terraform":     {"terraform"},
-	"hashicorp/setup-packer":        {"packer"},
-	"oven-sh/setup-bun":             {"bun"},
-	"astral-sh/setup-uv":            {"uv"},
-	"erlef/setup-beam":              {"erl", "elixir", "mix", "rebar3", "hex"},
-	"microsoft/setup-msbuild":       {"msbuild"},
-	"denoland/setup-deno":           {"deno"},
-	"jfrog/setup-jfrog-cli":         {"jfrog"},
-	"supabase/setup-cli":            {"supabase"},
-	"aws-actions/setup-sam":         {"sam"},
-	"gruntwork-io/setup-terragrunt": {"terragrunt"},
-	"pdm-project/setup-pdm":         {"pdm"},
+	"actions/setup-go":                   {"go", "gofmt"},
+	"actions/setup-node":                 {"node", "npm", "npx"},
+	"actions/setup-python":               {"python", "python3", "pip", "pip3"},
+	"actions/setup-java":                 {"java", "javac", "mvn", "gradle"},
+	"actions/setup-dotnet":               {"dotnet"},
+	"actions/setup-ruby":                 {"ruby", "gem"},
+	"hashicorp/setup-terraform":          {"terraform"},
+	"hashicorp/setup-packer":             {"packer"},
+	"oven-sh/setup-bun":                  {"bun"},
+	"astral-sh/setup-uv":                 {"uv"},
+	"erlef/setup-beam":                   {"erl", "elixir", "mix", "rebar3", "hex"},
+	"microsoft/setup-msbuild":            {"msbuild"},
+	"denoland/setup-deno":                {"deno"},
+	"jfrog/setup-jfrog-cli":              {"jfrog"},
+	"supabase/setup-cli":                 {"supabase"},
+	"aws-actions/setup-sam":              {"sam"},
+	"gruntwork-io/setup-terragrunt":      {"terragrunt"},
+	"pdm-project/setup-pdm":              {"pdm"},
+	"ruby/setup-ruby":                    {"ruby", "gem", "bundle"},
+	"azure/setup-kubectl":                {"kubectl"},
+	"azure/setup-helm":                   {"helm"},
+	"google-github-actions/setup-gcloud": {"gcloud", "gsutil"},
+	"dtolnay/rust-toolchain":             {"rustc", "cargo", "rustup"},
+	"shivammathur/setup-php":             {"php", "composer"},
+	"julia-actions/setup-julia":          {"julia"},
+	"conda-incubator/setup-miniconda":    {"conda"},
 }
 
-var (
-	// containerCommandPatterns lists regex patterns that match container commands
-	// Each pattern is compiled and checked against run commands.
-	// Future additions could include: podman commands, containerd commands, etc.
-	containerCommandPatterns = []*regexp.Regexp{
-		regexp.MustCompile(`\bdocker[\s-](?:build|run|exec|ps|pull|push|tag|login)\b`),
-		regexp.MustCompile(`\bdocker-compose\b`),
-		regexp.MustCompile(`\bdocker\s+compose\b`),
-	}
-
-	// containerActionPrefixes lists prefixes that indicate container-based GitHub Actions
-	// This covers:
-	// - docker:// image syntax (e.g., "docker://alpine:latest")
-	// - docker/ organization actions (e.g., "docker/build-push-action@v6")
-	// Future additions could include: "container://", "podman/", etc.
-	containerActionPrefixes = []string{"docker"}
-)
-
-// IsUbuntuLatest checks if a job runs on ubuntu-latest
+// IsUbuntuLatest checks if a job runs on ubuntu-latest, or on the source
+// label set with SetRunnerLabels. runs-on can be a label, a list of labels, or
+// a runner group with labels; self-hosted runners never count, even with an
+// ubuntu-latest label.
 func (j *Job) IsUbuntuLatest() bool {
-	if j.RunsOn == nil {
-		return false
-	}
+	return j.hasRunnerLabel(sourceLabel) && !j.IsSelfHosted()
+}
 
-	switch v := j.RunsOn.(type) {
-	case string:
-		return v == "ubuntu-latest"
-	case []any:
-		// runs-on can be a matrix or array
-		for _, item := range v {
-			if str, ok := item.(string); ok && str == "ubuntu-latest" {
-				return true
+// HasDockerCommands checks if a job uses Docker commands
+// It checks if the job uses any container commands in the run commands, including
+// repository scripts and Makefile targets invoked by them (e.g., "./scripts/build.sh", "make e2e").
+// Matches the commands of the container-tooling catalog (see containers.go),
+// like "docker build", "docker-compose", "sudo docker run", "podman build", etc.
+func (j *Job) HasDockerCommands() bool {
+	return len(j.DockerCommandEvidence()) > 0
+}
+
+// DockerCommandEvidence returns the commands that make HasDockerCommands true,
+// with the step and script they come from, and the pattern and tool they matched
+func (j *Job) DockerCommandEvidence() []Evidence {
+	var evidence []Evidence
+	for _, step := range j.expandedSteps() {
+		for _, cmd := range stepCommands(step) {
+			if tool, pattern := containerCommandMatch(cmd); pattern != nil {
+				evidence = append(evidence, Evidence{
+					Step:       stepLabel(step),
+					Source:     cmd.Source,
+					Line:       cmd.Line(),
+					Pattern:    pattern.String(),
+					Tool:       tool,
+					LineNumber: commandLineNumber(step, cmd),
+				})
 			}
 		}
-		return false
-	default:
-		return false
 	}
+	return evidence
 }
 
-// HasDockerCommands checks if a job uses Docker commands
-// It checks if the job uses any Docker commands in the run commands.
-// Matches patterns like "docker build", "docker-compose", "sudo docker run", etc.
-func (j *Job) HasDockerCommands() bool {
-	for _, step := range j.Steps {
-		if step.Run == "" {
-			continue
+// ScriptUsesContainerCommands reports whether a shell script uses container commands
+// (e.g., "docker build", "podman run") of the container-tooling catalog.
+// The script is parsed into commands, so mentions of docker in comments, strings,
+// and heredocs do not count, while commands in subshells, functions, loops, and
+// command substitutions do.
+func ScriptUsesContainerCommands(script string) bool {
+	for _, cmd := range parseShellCommands(script) {
+		if _, pattern := containerCommandMatch(cmd); pattern != nil {
+			return true
 		}
+	}
+	return false
+}
 
-		runLower := strings.ToLower(step.Run)
-		// Check if run command matches any container command pattern
-		for _, pattern := range containerCommandPatterns {
-			if pattern.MatchString(runLower) {
-				return true
+// containerCommandMatch returns the container tool and command pattern
+// matching the command, or a nil pattern if it is not a container command
+func containerCommandMatch(cmd shellCommand) (string, *regexp.Regexp) {
+	// Patterns are matched with the command name normalized (docker build) and
+	// as written, for tools identified by their path (/kaniko/executor)
+	lines := []string{strings.ToLower(cmd.Line())}
+	if i := cmd.commandIndex(); i >= 0 && strings.Contains(cmd.Words[i], "/") {
+		lines = append(lines, strings.ToLower(strings.Join(cmd.Words[i:], " ")))
+	}
+	// Patterns must match from the command name, not from an argument
+	for _, tool := range containerTools {
+		for _, pattern := range tool.commands {
+			for _, line := range lines {
+				if loc := pattern.FindStringIndex(line); loc != nil && loc[0] == 0 {
+					return tool.name, pattern
+				}
 			}
 		}
 	}
-	return false
+	return "", nil
 }
 
 // HasContainerActions checks if a job uses container-based GitHub Actions
-// It detects actions that use container prefixes defined in containerActionPrefixes:
+// It detects actions whose uses: value starts with an action prefix of the
+// container-tooling catalog, for example:
 // - docker:// image syntax (e.g., "docker://alpine:latest")
 // - docker/ organization actions (e.g., "docker/build-push-action@v6")
-// Future container tools can be added by extending containerActionPrefixes.
+// - actions running buildah or podman (e.g., "redhat-actions/buildah-build@v2")
+// Local actions (uses: ./path) are inspected via their action.yml: Docker
+// actions are container-based, and composite action steps are checked as well.
 func (j *Job) HasContainerActions() bool {
-	for _, step := range j.Steps {
+	return len(j.ContainerActionEvidence()) > 0
+}
+
+// ContainerActionEvidence returns the uses: values that make HasContainerActions true,
+// with the container action prefix (and its tool) or local action metadata they matched
+func (j *Job) ContainerActionEvidence() []Evidence {
+	var evidence []Evidence
+
+	// Local actions with runs.using: docker run in a container as well
+	for _, path := range j.dockerLocalActions() {
+		evidence = append(evidence, Evidence{
+			Line:    "./" + filepath.ToSlash(path),
+			Source:  filepath.ToSlash(path),
+			Pattern: "runs.using: docker",
+		})
+	}
+
+	for _, step := range j.expandedSteps() {
 		if step.Uses == "" {
 			continue
 		}
-		uses := step.Uses
-		// Check if uses starts with any container action prefix
-		for _, prefix := range containerActionPrefixes {
+		if tool, prefix := containerActionMatch(step.Uses); prefix != "" {
+			evidence = append(evidence, Evidence{Step: stepLabel(step), Line: step.Uses, Pattern: prefix, Tool: tool, LineNumber: step.Pos.Line})
+		}
+	}
+	return evidence
+}
+
+// containerActionMatch returns the container tool and action prefix matching
+// a uses: value, or an empty prefix if it is not a container-based action
+func containerActionMatch(uses string) (string, string) {
+	for _, tool := range containerTools {
+		for _, prefix := range tool.actions {
 			if strings.HasPrefix(uses, prefix) {
-				return true
+				return tool.name, prefix
 			}
 		}
 	}
-	return false
+	return "", ""
 }
 
 // HasServices checks if a job uses services
@@ -125,6 +177,44 @@ func (j *Job) HasServices() bool {
 	return j.Services != nil
 }
 
+// ServiceImages returns the service containers defined in the job, keyed by
+// service name. The value is the image reference (e.g., "postgres:14"), or an
+// empty string when the image cannot be determined (e.g., it is an expression).
+// Returns nil if the job has no services mapping.
+func (j *Job) ServiceImages() map[string]string {
+	services, ok := j.Services.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	images := make(map[string]string, len(services))
+	for name, def := range services {
+		image := ""
+		if m, ok := def.(map[string]any); ok {
+			if s, ok := m["image"].(string); ok {
+				image = s
+			}
+		}
+		images[name] = image
+	}
+	return images
+}
+
+// IsReusableWorkflowCall reports whether the job calls a reusable workflow via jobs.<id>.uses
+func (j *Job) IsReusableWorkflowCall() bool {
+	return j.Uses != ""
+}
+
+// LocalReusableWorkflow returns the cleaned path of a reusable workflow in the
+// same repository (e.g., ".github/workflows/build.yml" for "./.github/workflows/build.yml").
+// Returns false if the job does not call a local reusable workflow.
+func (j *Job) LocalReusableWorkflow() (string, bool) {
+	if !strings.HasPrefix(j.Uses, "./") {
+		return "", false
+	}
+	return filepath.Clean(strings.TrimPrefix(j.Uses, "./")), true
+}
+
 // HasContainer checks if a job uses the container: syntax
 // Jobs with container: run steps inside a Docker container, which requires
 // access to the Docker daemon. Since ubuntu-slim runs itself inside a container
@@ -135,205 +225,110 @@ func (j *Job) HasContainer() bool {
 
 // GetMissingCommands extracts commands from job steps and returns a list of commands
 // that exist in ubuntu-latest but are missing in ubuntu-slim.
-// It parses shell commands from step.Run fields and checks them against the
-// missing commands list.
+// It parses shell commands from step.Run fields (including steps of local
+// composite actions) and checks them against the missing commands list.
 // Commands provided by setup actions (e.g., setup-go provides "go") are excluded
 // from the missing commands list since they will be available after the setup action runs.
+// Commands installed earlier in the job (e.g., "sudo apt-get install -y lsof",
+// "pip install awscli", or an installer script piped into sh) are excluded as well.
+// Repository scripts and Makefile targets invoked by steps are analyzed too.
 func (j *Job) GetMissingCommands() []string {
+	var missingCommands []string
+	for _, e := range j.MissingCommandEvidence() {
+		missingCommands = append(missingCommands, e.Pattern)
+	}
+	return missingCommands
+}
+
+// MissingCommandEvidence returns the first use of each command reported by
+// GetMissingCommands, in the same order. Pattern holds the command name.
+func (j *Job) MissingCommandEvidence() []Evidence {
 	if !j.IsUbuntuLatest() {
 		// Only check commands for ubuntu-latest jobs
 		return nil
 	}
+	return j.uninstalledCommandEvidence(byName(IsMissingInSlim))
+}
+
+// RequiredCommandEvidence returns the first use of every command the job runs
+// that is neither provided by a setup action nor installed earlier in the job,
+// i.e., the commands the runner image must provide, whatever runner the job
+// runs on. Commands run by a relative path (e.g., ./gradlew), which are files
+// of the repository, and from a variable (e.g., $CC) are left out. Pattern
+// holds the command name.
+func (j *Job) RequiredCommandEvidence() []Evidence {
+	return j.uninstalledCommandEvidence(func(cmd shellCommand) bool {
+		word := cmd.Words[cmd.commandIndex()]
+		return !strings.Contains(word, "$") && (!strings.Contains(word, "/") || strings.HasPrefix(word, "/"))
+	})
+}
+
+// byName adapts a match on command names to uninstalledCommandEvidence
+func byName(match func(cmd string) bool) func(cmd shellCommand) bool {
+	return func(cmd shellCommand) bool { return match(cmd.Name()) }
+}
 
+// uninstalledCommandEvidence returns the first use of each command matching
+// match that is neither provided by a setup action nor installed earlier in
+// the job. Pattern holds the command name.
+func (j *Job) uninstalledCommandEvidence(match func(cmd shellCommand) bool) []Evidence {
 	// Collect commands provided by setup actions in this job
 	setupProvidedCommands := j.getSetupProvidedCommands()
 
-	var missingCommands []string
+	var evidence []Evidence
 	seen := make(map[string]bool)
 
-	for _, step := range j.Steps {
-		if step.Run == "" {
-			continue
-		}
+	// Commands installed by earlier commands in the job
+	installed := make(map[string]bool)
+	var installerURLs []string
 
-		commands := extractCommands(step.Run)
-		for _, cmd := range commands {
+	for _, step := range j.expandedSteps() {
+		var prev *shellCommand
+		for _, cmd := range stepCommands(step) {
 			// Normalize command name (remove path, keep only basename)
-			cmdName := normalizeCommand(cmd)
-			if cmdName == "" {
-				continue
+			cmdName := cmd.Name()
+
+			// Skip if command is provided by a setup action or installed earlier
+			provided := setupProvidedCommands[cmdName] || installed[cmdName] || installedByScript(cmdName, installerURLs)
+
+			// Check if command matches and is not already added
+			if cmdName != "" && !provided && match(cmd) && !seen[cmdName] {
+				evidence = append(evidence, Evidence{
+					Step:       stepLabel(step),
+					Source:     cmd.Source,
+					Line:       cmd.Line(),
+					Pattern:    cmdName,
+					LineNumber: commandLineNumber(step, cmd),
+				})
+				seen[cmdName] = true
 			}
 
-			// Skip if command is provided by a setup action
-			if setupProvidedCommands[cmdName] {
-				continue
+			commands, installerURL := installedCommands(cmd, prev)
+			for _, c := range commands {
+				installed[c] = true
 			}
-
-			// Check if command is missing in slim and not already added
-			if IsMissingInSlim(cmdName) && !seen[cmdName] {
-				missingCommands = append(missingCommands, cmdName)
-				seen[cmdName] = true
+			if installerURL != "" {
+				installerURLs = append(installerURLs, installerURL)
 			}
+			prev = &cmd
 		}
 	}
 
-	return missingCommands
+	return evidence
 }
 
 // getSetupProvidedCommands returns a map of commands that are provided by setup actions
 // in this job. The map keys are command names, and values are always true.
 func (j *Job) getSetupProvidedCommands() map[string]bool {
 	providedCommands := make(map[string]bool)
-
-	for _, step := range j.Steps {
-		if step.Uses == "" {
-			continue
-		}
-
-		// Check if this step uses a setup action
-		// Setup actions typically follow the pattern: actions/setup-<lang>@<version>
-		// We match the base action name without version
-		for actionPrefix, commands := range setupActionCommands {
-			if strings.HasPrefix(step.Uses, actionPrefix) {
-				// This setup action provides these commands
-				for _, cmd := range commands {
-					providedCommands[cmd] = true
-				}
-			}
+	for _, action := range j.SetupActions() {
+		for _, cmd := range action.Commands {
+			providedCommands[cmd] = true
 		}
 	}
-
 	return providedCommands
 }
 
-// extractCommands extracts command names from a shell script string.
-// It handles multi-line scripts, comments, variable assignments, and common shell constructs.
-func extractCommands(script string) []string {
-	var commands []string
-	lines := strings.Split(script, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Skip comment lines
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Handle shebang
-		if strings.HasPrefix(line, "#!") {
-			continue
-		}
-
-		// Extract commands before pipe, redirect, or logical operators
-		parts := splitCommandLine(line)
-		for _, part := range parts {
-			cmd := extractCommandFromPart(part)
-			if cmd != "" {
-				commands = append(commands, cmd)
-			}
-		}
-	}
-
-	return commands
-}
-
-// splitCommandLine splits a command line by pipe, redirect, and logical operators
-// while preserving the command parts.
-func splitCommandLine(line string) []string {
-	// Split by |, &&, ||, ;, >, <, >>, <<
-	// Simple approach: split by these operators
-	parts := []string{line}
-	separators := []string{"|", "&&", "||", ";", ">>", "<<", ">", "<"}
-
-	for _, sep := range separators {
-		var newParts []string
-		for _, part := range parts {
-			split := strings.Split(part, sep)
-			for i, s := range split {
-				s = strings.TrimSpace(s)
-				if s != "" {
-					if i == 0 {
-						newParts = append(newParts, s)
-					} else {
-						// For subsequent parts after separator, add them separately
-						newParts = append(newParts, s)
-					}
-				}
-			}
-		}
-		parts = newParts
-	}
-
-	return parts
-}
-
-// extractCommandFromPart extracts the command name from a command part.
-// It handles prefixes like sudo, env, time, etc.
-func extractCommandFromPart(part string) string {
-	part = strings.TrimSpace(part)
-	if part == "" {
-		return ""
-	}
-
-	// Handle variable assignments (VAR=value command)
-	// Split by space first to handle cases like "VAR=value command"
-	fields := strings.Fields(part)
-	if len(fields) == 0 {
-		return ""
-	}
-
-	// Find the first field that doesn't contain = (the actual command)
-	startIndex := 0
-	for startIndex < len(fields) {
-		if !strings.Contains(fields[startIndex], "=") {
-			break
-		}
-		startIndex++
-	}
-
-	if startIndex >= len(fields) {
-		// All fields contain =, no command found
-		return ""
-	}
-
-	part = strings.Join(fields[startIndex:], " ")
-
-	// Re-extract fields after handling variable assignments
-	fields = strings.Fields(part)
-	if len(fields) == 0 {
-		return ""
-	}
-
-	// Common prefixes to skip
-	prefixes := []string{"sudo", "env", "time", "nohup", "setsid", "stdbuf"}
-	cmdStartIndex := 0
-
-	for cmdStartIndex < len(fields) {
-		field := fields[cmdStartIndex]
-		found := false
-		for _, prefix := range prefixes {
-			if field == prefix {
-				cmdStartIndex++
-				found = true
-				break
-			}
-		}
-		if !found {
-			break
-		}
-	}
-
-	if cmdStartIndex >= len(fields) {
-		return ""
-	}
-
-	return fields[cmdStartIndex]
-}
-
 // normalizeCommand normalizes a command name by removing path components.
 // It returns only the basename of the command.
 func normalizeCommand(cmd string) string {
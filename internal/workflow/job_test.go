@@ -69,6 +69,34 @@ func TestJob_IsUbuntuLatest_EdgeCases(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "runner group with ubuntu-latest",
+			job: &Job{
+				RunsOn: map[string]interface{}{"group": "larger", "labels": []interface{}{"ubuntu-latest"}},
+			},
+			expected: true,
+		},
+		{
+			name: "runner group with a label string",
+			job: &Job{
+				RunsOn: map[string]interface{}{"group": "larger", "labels": "ubuntu-latest"},
+			},
+			expected: true,
+		},
+		{
+			name: "self-hosted runner labeled ubuntu-latest",
+			job: &Job{
+				RunsOn: []interface{}{"self-hosted", "ubuntu-latest"},
+			},
+			expected: false,
+		},
+		{
+			name: "runner group of self-hosted runners",
+			job: &Job{
+				RunsOn: map[string]interface{}{"group": "private", "labels": []interface{}{"Self-Hosted", "ubuntu-latest"}},
+			},
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,7 +134,7 @@ func TestJob_HasDockerCommands_EdgeCases(t *testing.T) {
 			job: &Job{
 				Steps: []Step{{Run: "# docker build should not match"}},
 			},
-			expected: true, // Current implementation detects docker in comments too
+			expected: false,
 		},
 		{
 			name: "docker command with prefix",
@@ -1081,3 +1109,25 @@ func findWorkspaceRoot(t *testing.T) string {
 		dir = parent
 	}
 }
+
+func TestJob_RequiredCommandEvidence(t *testing.T) {
+	job := &Job{
+		RunsOn: "ubuntu-slim",
+		Steps: []Step{
+			{Uses: "actions/setup-go@v5"},
+			{Run: "go build ./...\nmake test | tee out.log"},
+			{Run: "sudo apt-get install -y lsof\nlsof -i :8080"},
+			{Run: "./gradlew build\n/usr/bin/jq . out.json\n$CC main.c\nmake lint"},
+		},
+	}
+	evidence := job.RequiredCommandEvidence()
+	want := []string{"make", "tee", "apt-get", "jq"}
+	if len(evidence) != len(want) {
+		t.Fatalf("RequiredCommandEvidence() = %+v, want %v", evidence, want)
+	}
+	for i, e := range evidence {
+		if e.Pattern != want[i] {
+			t.Errorf("RequiredCommandEvidence()[%d] = %s, want %s", i, e.Pattern, want[i])
+		}
+	}
+}
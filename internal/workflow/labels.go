@@ -0,0 +1,176 @@
+package workflow
+
+import (
+	"strings"
+	"unicode"
+)
+
+// knownRunnerLabels are the labels of GitHub-hosted runners and the default
+// label of self-hosted runners. runs-on values close to one of them are
+// reported as likely typos, since jobs with an unknown label wait for a
+// runner forever.
+var knownRunnerLabels = []string{
+	"ubuntu-latest", "ubuntu-slim", "ubuntu-24.04", "ubuntu-22.04",
+	"ubuntu-24.04-arm", "ubuntu-22.04-arm",
+	"windows-latest", "windows-2025", "windows-2022", "windows-11-arm",
+	"macos-latest", "macos-15", "macos-14", "macos-13", "macos-15-intel",
+	"macos-latest-large", "macos-15-large", "macos-14-large", "macos-13-large",
+	"macos-latest-xlarge", "macos-15-xlarge", "macos-14-xlarge", "macos-13-xlarge",
+	"self-hosted",
+}
+
+// IsHostedLabel reports whether label is the label of a standard GitHub-hosted
+// runner (e.g., ubuntu-slim), ignoring case
+func IsHostedLabel(label string) bool {
+	for _, known := range knownRunnerLabels {
+		if known != "self-hosted" && strings.EqualFold(label, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelTypo is a runs-on label that looks like a typo of a known label
+type LabelTypo struct {
+	Label      string `json:"label"`
+	Suggestion string `json:"suggestion"` // The known label it is closest to
+}
+
+// RunnerLabelTypos returns the runs-on labels of the job that look like
+// typos of known runner labels (e.g., "ubuntu-lastest" or "ubuntu_latest")
+func (j *Job) RunnerLabelTypos() []LabelTypo {
+	var typos []LabelTypo
+	for _, label := range j.RunnerLabels() {
+		if suggestion := suggestRunnerLabel(label); suggestion != "" {
+			typos = append(typos, LabelTypo{Label: label, Suggestion: suggestion})
+		}
+	}
+	return typos
+}
+
+// IsSelfHosted reports whether runs-on selects self-hosted runners: the
+// self-hosted label, alone, in a list, or in the labels of a runner group
+func (j *Job) IsSelfHosted() bool {
+	for _, label := range j.RunnerLabels() {
+		if strings.EqualFold(label, "self-hosted") {
+			return true
+		}
+	}
+	return false
+}
+
+// RunnerGroup returns the runner group of runs-on
+// (runs-on: { group: my-group, labels: [...] }), or "" if it has none
+func (j *Job) RunnerGroup() string {
+	m, ok := j.RunsOn.(map[string]any)
+	if !ok {
+		return ""
+	}
+	group, _ := m["group"].(string)
+	return group
+}
+
+// hasRunnerLabel reports whether label is one of the labels of runs-on
+func (j *Job) hasRunnerLabel(label string) bool {
+	for _, l := range j.RunnerLabels() {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// RunnerLabels returns the labels of runs-on, given as a string, a list, or
+// a mapping with group and labels
+func (j *Job) RunnerLabels() []string {
+	runsOn := j.RunsOn
+	if m, ok := runsOn.(map[string]any); ok {
+		runsOn = m["labels"]
+	}
+	switch v := runsOn.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var labels []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				labels = append(labels, s)
+			}
+		}
+		return labels
+	}
+	return nil
+}
+
+// suggestRunnerLabel returns the known label that label is probably a typo
+// of, or "" if it is known or not close to any known label. Labels are
+// compared case-insensitively, like GitHub does. Labels that only differ in
+// their digits (e.g., "ubuntu-20.04" or "macos-16") are other versions rather
+// than typos. The labels set with SetRunnerLabels are known. Expressions are
+// not evaluated.
+func suggestRunnerLabel(label string) string {
+	lower := strings.ToLower(strings.TrimSpace(label))
+	if lower == "" || strings.Contains(lower, "${{") {
+		return ""
+	}
+	labels := append([]string{strings.ToLower(sourceLabel), strings.ToLower(targetLabel)}, knownRunnerLabels...)
+	for _, known := range labels {
+		if lower == known {
+			return ""
+		}
+	}
+
+	best, bestDistance := "", 0
+	for _, known := range labels {
+		if withoutDigits(lower) == withoutDigits(known) {
+			continue
+		}
+		maxDistance := 1
+		if len(known) >= 10 {
+			maxDistance = 2
+		}
+		if d := editDistance(lower, known); d <= maxDistance && (best == "" || d < bestDistance) {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// withoutDigits returns s with its digits removed
+func withoutDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// editDistance returns the Damerau-Levenshtein distance (optimal string
+// alignment) between a and b: the number of insertions, deletions,
+// substitutions, and transpositions of adjacent characters turning a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between the first i runes of a and the first j runes of b
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,59 @@
+package workflow
+
+import "testing"
+
+func TestSuggestRunnerLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"ubuntu-lastest", "ubuntu-latest"},
+		{"ubuntu_latest", "ubuntu-latest"},
+		{"ubunut-latest", "ubuntu-latest"},
+		{"ubuntu-latset", "ubuntu-latest"},
+		{"windows-latests", "windows-latest"},
+		{"macos-lates", "macos-latest"},
+		{"self_hosted", "self-hosted"},
+		{"ubuntu-latest", ""},
+		{"Ubuntu-Latest", ""},
+		{"ubuntu-20.04", ""},
+		{"macos-16", ""},
+		{"ubuntu-latest-8-cores", ""},
+		{"linux", ""},
+		{"gpu", ""},
+		{"${{ matrix.os }}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := suggestRunnerLabel(tt.label); got != tt.want {
+				t.Errorf("suggestRunnerLabel(%q) = %q, want %q", tt.label, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJob_RunnerLabelTypos(t *testing.T) {
+	tests := []struct {
+		name   string
+		runsOn any
+		want   []LabelTypo
+	}{
+		{"string", "ubuntu-lastest", []LabelTypo{{Label: "ubuntu-lastest", Suggestion: "ubuntu-latest"}}},
+		{"list", []any{"self_hosted", "linux"}, []LabelTypo{{Label: "self_hosted", Suggestion: "self-hosted"}}},
+		{"group and labels", map[string]any{"group": "runners", "labels": "ubuntu_latest"}, []LabelTypo{{Label: "ubuntu_latest", Suggestion: "ubuntu-latest"}}},
+		{"known", "ubuntu-latest", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Job{RunsOn: tt.runsOn}).RunnerLabelTypos()
+			if len(got) != len(tt.want) {
+				t.Fatalf("RunnerLabelTypos() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("RunnerLabelTypos()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
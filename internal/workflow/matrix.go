@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"gopkg.in/yaml.v3"
+)
+
+// MatrixKey is the matrix variable added by AddRunnerMatrix
+const MatrixKey = "runner"
+
+// AddRunnerMatrix makes jobs run on both ubuntu-latest and newRunsOn instead of
+// switching them: runs-on: ubuntu-latest becomes runs-on: ${{ matrix.runner }},
+// and runner: [ubuntu-latest, <newRunsOn>] is added to the job's
+// strategy.matrix. A strategy block (with fail-fast: false, so that a failure
+// on one runner does not cancel the other) is created if the job has none.
+// ubuntu-latest stands for the source label set with SetRunnerLabels.
+// Like UpdateRunsOnJobs, all jobs are updated in a single atomic write, and
+// jobs that cannot be edited are returned with their error, keyed by job ID.
+func AddRunnerMatrix(filePath string, jobIDs []string, newRunsOn string) (map[string]error, error) {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML %s: %w", filePath, err)
+	}
+
+	failed := make(map[string]error)
+	var edits []textEdit
+	for _, jobID := range jobIDs {
+		jobEdits, err := runnerMatrixEdits(data, root, jobID, sourceLabel, newRunsOn)
+		if err != nil {
+			failed[jobID] = err
+			continue
+		}
+		edits = append(edits, jobEdits...)
+	}
+	if len(edits) == 0 {
+		return failed, nil
+	}
+
+	updated := applyEdits(data, edits)
+	if _, err := parseDocument(updated); err != nil {
+		return nil, fmt.Errorf("failed to update %s: result is not valid YAML: %w", filePath, err)
+	}
+	if err := writeFileAtomic(filePath, updated); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// runnerMatrixEdits returns the edits replacing runs-on: from with a matrix of
+// the runners from and to
+func runnerMatrixEdits(data []byte, root *yaml.Node, jobID, from, to string) ([]textEdit, error) {
+	jobKey, job := jobNode(root, jobID)
+	if job == nil {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	if job.Kind != yaml.MappingNode || job.Style == yaml.FlowStyle {
+		return nil, fmt.Errorf("job %s is not a block mapping", jobID)
+	}
+	runsOnKey, runsOn := mappingValue(job, "runs-on")
+	if runsOn == nil {
+		return nil, fmt.Errorf("runs-on not found")
+	}
+	if runsOn.Kind != yaml.ScalarNode || runsOn.Value != from {
+		return nil, fmt.Errorf("runs-on is not %s", from)
+	}
+
+	// Indentation of the job's keys, and of one nesting level
+	indent := runsOnKey.Column - 1
+	unit := runsOnKey.Column - jobKey.Column
+	if unit <= 0 {
+		return nil, fmt.Errorf("cannot determine the indentation of job %s", jobID)
+	}
+	runners := fmt.Sprintf("%s: [%s, %s]", MatrixKey, from, to)
+	nl := newline(data)
+
+	edit, err := scalarValueEdit(data, runsOn, "${{ matrix."+MatrixKey+" }}")
+	if err != nil {
+		return nil, err
+	}
+	edits := []textEdit{edit}
+
+	strategyKey, strategy := mappingValue(job, "strategy")
+	if strategyKey == nil {
+		// Add a strategy block before runs-on
+		block := pad(indent) + "strategy:" + nl +
+			pad(indent+unit) + "fail-fast: false" + nl +
+			pad(indent+unit) + "matrix:" + nl +
+			pad(indent+2*unit) + runners + nl
+		insert, err := lineInsertion(data, runsOnKey, block)
+		if err != nil {
+			return nil, err
+		}
+		return append(edits, insert), nil
+	}
+	if strategy.Kind != yaml.MappingNode || strategy.Style == yaml.FlowStyle || len(strategy.Content) == 0 {
+		return nil, fmt.Errorf("strategy is not a block mapping")
+	}
+
+	matrixKey, matrix := mappingValue(strategy, "matrix")
+	if matrixKey == nil {
+		// Add a matrix to the existing strategy
+		first := strategy.Content[0]
+		block := pad(first.Column-1) + "matrix:" + nl + pad(first.Column-1+unit) + runners + nl
+		insert, err := lineInsertion(data, first, block)
+		if err != nil {
+			return nil, err
+		}
+		return append(edits, insert), nil
+	}
+	if matrix.Kind != yaml.MappingNode || matrix.Style == yaml.FlowStyle || len(matrix.Content) == 0 {
+		return nil, fmt.Errorf("strategy.matrix is not a block mapping")
+	}
+	if key, _ := mappingValue(matrix, MatrixKey); key != nil {
+		return nil, fmt.Errorf("strategy.matrix already defines %s", MatrixKey)
+	}
+
+	// Add the runners to the existing matrix
+	first := matrix.Content[0]
+	insert, err := lineInsertion(data, first, pad(first.Column-1)+runners+nl)
+	if err != nil {
+		return nil, err
+	}
+	return append(edits, insert), nil
+}
+
+// lineInsertion returns an edit inserting text at the start of the line of a
+// node, which must be the first node on its line (e.g., a block mapping key)
+func lineInsertion(data []byte, n *yaml.Node, text string) (textEdit, error) {
+	offset, err := nodeOffset(data, n)
+	if err != nil {
+		return textEdit{}, err
+	}
+	start := strings.LastIndexByte(string(data[:offset]), '\n') + 1
+	if strings.TrimSpace(string(data[start:offset])) != "" {
+		return textEdit{}, fmt.Errorf("%s at line %d is not on its own line", n.Value, n.Line)
+	}
+	return textEdit{offset: start, text: text}, nil
+}
+
+// pad returns n spaces
+func pad(n int) string {
+	return strings.Repeat(" ", n)
+}
@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddRunnerMatrix(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "job without strategy",
+			content: `jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest # default runner
+    steps:
+      - run: make
+`,
+			expected: `jobs:
+  build:
+    name: Build
+    strategy:
+      fail-fast: false
+      matrix:
+        runner: [ubuntu-latest, ubuntu-slim]
+    runs-on: ${{ matrix.runner }} # default runner
+    steps:
+      - run: make
+`,
+		},
+		{
+			name: "four-space indentation and quoted runs-on",
+			content: `jobs:
+    build:
+        runs-on: "ubuntu-latest"
+        steps:
+            - run: make
+`,
+			expected: `jobs:
+    build:
+        strategy:
+            fail-fast: false
+            matrix:
+                runner: [ubuntu-latest, ubuntu-slim]
+        runs-on: "${{ matrix.runner }}"
+        steps:
+            - run: make
+`,
+		},
+		{
+			name:     "CRLF line endings",
+			content:  "jobs:\r\n  build:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: make\r\n",
+			expected: "jobs:\r\n  build:\r\n    strategy:\r\n      fail-fast: false\r\n      matrix:\r\n        runner: [ubuntu-latest, ubuntu-slim]\r\n    runs-on: ${{ matrix.runner }}\r\n    steps:\r\n      - run: make\r\n",
+		},
+		{
+			name: "existing matrix",
+			content: `jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        go: ['1.22', '1.23']
+    steps:
+      - run: go test ./...
+`,
+			expected: `jobs:
+  test:
+    runs-on: ${{ matrix.runner }}
+    strategy:
+      matrix:
+        runner: [ubuntu-latest, ubuntu-slim]
+        go: ['1.22', '1.23']
+    steps:
+      - run: go test ./...
+`,
+		},
+		{
+			name: "strategy without matrix",
+			content: `jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      max-parallel: 1
+    steps:
+      - run: make test
+`,
+			expected: `jobs:
+  test:
+    runs-on: ${{ matrix.runner }}
+    strategy:
+      matrix:
+        runner: [ubuntu-latest, ubuntu-slim]
+      max-parallel: 1
+    steps:
+      - run: make test
+`,
+		},
+		{
+			name: "matrix already defines runner",
+			content: `jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        runner: [a, b]
+`,
+			wantErr: true,
+		},
+		{
+			name: "matrix from an expression",
+			content: `jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+`,
+			wantErr: true,
+		},
+		{
+			name: "runs-on is not ubuntu-latest",
+			content: `jobs:
+  test:
+    runs-on: [self-hosted, linux]
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "workflow.yml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			var jobID string
+			wf, err := LoadWorkflow(filePath)
+			if err != nil {
+				t.Fatalf("LoadWorkflow() unexpected error: %v", err)
+			}
+			for id := range wf.Jobs {
+				jobID = id
+			}
+
+			failed, err := AddRunnerMatrix(filePath, []string{jobID}, "ubuntu-slim")
+			if err != nil {
+				t.Fatalf("AddRunnerMatrix() unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if failed[jobID] == nil {
+					t.Errorf("AddRunnerMatrix() expected error for job %s, got none", jobID)
+				}
+				return
+			}
+			if failed[jobID] != nil {
+				t.Fatalf("AddRunnerMatrix() unexpected error for job %s: %v", jobID, failed[jobID])
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read updated file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("AddRunnerMatrix() result mismatch\ngot:\n%s\nwant:\n%s", data, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModifiedJobs compares two versions of a workflow's content and returns the
+// IDs of the jobs added in after, and of the jobs whose definition differs
+// from before, in ID order. before is nil for a workflow added in after.
+// Definitions are compared once parsed, so comment and formatting changes do
+// not modify a job, and changes outside the jobs (e.g., to the workflow-level
+// env) modify none.
+func ModifiedJobs(before, after []byte) (added, modified []string, err error) {
+	oldJobs, err := jobDefinitions(before)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the previous version: %w", err)
+	}
+	newJobs, err := jobDefinitions(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, job := range newJobs {
+		old, ok := oldJobs[id]
+		switch {
+		case !ok:
+			added = append(added, id)
+		case !reflect.DeepEqual(old, job):
+			modified = append(modified, id)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(modified)
+	return added, modified, nil
+}
+
+// jobDefinitions returns the parsed jobs of a workflow's content, keyed by ID
+func jobDefinitions(data []byte) (map[string]any, error) {
+	var workflowData map[string]any
+	if err := yaml.Unmarshal(data, &workflowData); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	jobs, _ := workflowData["jobs"].(map[string]any)
+	return jobs, nil
+}
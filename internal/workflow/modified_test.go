@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModifiedJobs(t *testing.T) {
+	before := `on: push
+env:
+  CI: "true"
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+`
+	after := `on: push
+env:
+  CI: "false"
+jobs:
+  # Reformatted and commented, not modified
+  lint:
+    runs-on: "ubuntu-latest"
+    steps: [{run: make lint}]
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+      - run: make coverage
+  docs:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make docs
+`
+	added, modified, err := ModifiedJobs([]byte(before), []byte(after))
+	if err != nil {
+		t.Fatalf("ModifiedJobs() unexpected error: %v", err)
+	}
+	if want := []string{"docs"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("ModifiedJobs() added = %v, want %v", added, want)
+	}
+	if want := []string{"test"}; !reflect.DeepEqual(modified, want) {
+		t.Errorf("ModifiedJobs() modified = %v, want %v", modified, want)
+	}
+
+	// A new workflow adds every job
+	added, modified, err = ModifiedJobs(nil, []byte(before))
+	if err != nil {
+		t.Fatalf("ModifiedJobs() unexpected error: %v", err)
+	}
+	if want := []string{"build", "lint", "test"}; !reflect.DeepEqual(added, want) || modified != nil {
+		t.Errorf("ModifiedJobs() of a new workflow = %v, %v, want %v, nil", added, modified, want)
+	}
+
+	if _, _, err := ModifiedJobs(nil, []byte("jobs: [")); err == nil {
+		t.Errorf("ModifiedJobs() with invalid YAML expected error, got nil")
+	}
+}
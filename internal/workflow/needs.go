@@ -0,0 +1,49 @@
+package workflow
+
+// Dependencies returns the IDs of the jobs the job needs (needs: build, or
+// needs: [build, test]), in the order they are listed
+func (j *Job) Dependencies() []string {
+	switch v := j.Needs.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var ids []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				ids = append(ids, s)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// Dependents returns the IDs of the jobs of the workflow that need the job,
+// directly or through other jobs, in the order they appear in the file. They
+// start once the job completes, so migrating it changes when they run.
+func (w *Workflow) Dependents(jobID string) []string {
+	downstream := map[string]bool{jobID: true}
+	// Repeat until no job is added, as needs: can refer to jobs later in the file
+	for added := true; added; {
+		added = false
+		for id, job := range w.Jobs {
+			if downstream[id] {
+				continue
+			}
+			for _, dep := range job.Dependencies() {
+				if downstream[dep] {
+					downstream[id], added = true, true
+					break
+				}
+			}
+		}
+	}
+
+	var ids []string
+	for _, id := range w.JobIDs() {
+		if id != jobID && downstream[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
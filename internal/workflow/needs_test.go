@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorkflow_Dependents(t *testing.T) {
+	wf := &Workflow{Jobs: map[string]*Job{
+		"build":   {LineStart: 1},
+		"test":    {LineStart: 2, Needs: "build"},
+		"lint":    {LineStart: 3},
+		"deploy":  {LineStart: 5, Needs: []any{"test", "lint"}},
+		"release": {LineStart: 6, Needs: []any{"deploy"}},
+	}}
+
+	if got, want := wf.Jobs["deploy"].Dependencies(), []string{"test", "lint"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies() = %v, want %v", got, want)
+	}
+	tests := map[string][]string{
+		"build":   {"test", "deploy", "release"},
+		"lint":    {"deploy", "release"},
+		"release": nil,
+	}
+	for jobID, want := range tests {
+		if got := wf.Dependents(jobID); !reflect.DeepEqual(got, want) {
+			t.Errorf("Dependents(%s) = %v, want %v", jobID, got, want)
+		}
+	}
+}
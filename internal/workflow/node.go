@@ -0,0 +1,172 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseDocument parses YAML data and returns the top-level mapping node.
+func parseDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("empty YAML document")
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top-level YAML node is not a mapping")
+	}
+	return root, nil
+}
+
+// mappingValue returns the key and value nodes for key in a mapping node.
+// Returns nil nodes if the key is not present or m is not a mapping.
+func mappingValue(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// resolveAlias returns the node an alias refers to, or n itself
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// lookupValue is mappingValue for reading values: it follows aliases and
+// merge keys (<<: *defaults), so the nodes returned may be defined elsewhere
+// in the document. The value node is resolved if it is an alias.
+func lookupValue(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	return lookupValueDepth(m, key, 0)
+}
+
+// maxMergeDepth limits how deeply merge keys are followed
+const maxMergeDepth = 8
+
+func lookupValueDepth(m *yaml.Node, key string, depth int) (*yaml.Node, *yaml.Node) {
+	m = resolveAlias(m)
+	if k, v := mappingValue(m, key); k != nil {
+		return k, resolveAlias(v)
+	}
+	_, merge := mappingValue(m, "<<")
+	merge = resolveAlias(merge)
+	if merge == nil || depth >= maxMergeDepth {
+		return nil, nil
+	}
+	sources := []*yaml.Node{merge}
+	if merge.Kind == yaml.SequenceNode {
+		sources = merge.Content
+	}
+	for _, source := range sources {
+		if k, v := lookupValueDepth(source, key, depth+1); k != nil {
+			return k, v
+		}
+	}
+	return nil, nil
+}
+
+// jobNode returns the key and value nodes of a job in the top-level mapping.
+func jobNode(root *yaml.Node, jobID string) (*yaml.Node, *yaml.Node) {
+	_, jobs := mappingValue(root, "jobs")
+	return mappingValue(jobs, jobID)
+}
+
+// textEdit replaces length bytes at offset with text.
+type textEdit struct {
+	offset int
+	length int
+	text   string
+}
+
+// applyEdits applies non-overlapping edits to data and returns the result.
+// Edits are applied from the end of the data so that offsets stay valid.
+func applyEdits(data []byte, edits []textEdit) []byte {
+	sorted := make([]textEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].offset > sorted[j].offset })
+
+	out := append([]byte(nil), data...)
+	for _, e := range sorted {
+		var buf bytes.Buffer
+		buf.Write(out[:e.offset])
+		buf.WriteString(e.text)
+		buf.Write(out[e.offset+e.length:])
+		out = buf.Bytes()
+	}
+	return out
+}
+
+// nodeOffset converts a node's 1-based line and column into a byte offset in data.
+// yaml.v3 reports columns in characters, so multi-byte runes are accounted for.
+func nodeOffset(data []byte, n *yaml.Node) (int, error) {
+	offset := 0
+	for line := 1; line < n.Line; line++ {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is out of range", n.Line)
+		}
+		offset += i + 1
+	}
+	for col := 1; col < n.Column; col++ {
+		if offset >= len(data) || data[offset] == '\n' {
+			return 0, fmt.Errorf("column %d is out of range on line %d", n.Column, n.Line)
+		}
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return offset, nil
+}
+
+// scalarValueEdit returns an edit replacing the value of a scalar node with
+// newValue while preserving its quoting style and any surrounding comments.
+func scalarValueEdit(data []byte, n *yaml.Node, newValue string) (textEdit, error) {
+	offset, err := nodeOffset(data, n)
+	if err != nil {
+		return textEdit{}, err
+	}
+
+	switch n.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		// Block scalars start at the indicator (| or >); the value follows on the next line
+		i := bytes.Index(data[offset:], []byte(n.Value))
+		if i < 0 {
+			return textEdit{}, fmt.Errorf("value %q not found at line %d", n.Value, n.Line)
+		}
+		offset += i
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		// Skip the opening quote and keep the quotes in place
+		offset++
+	}
+
+	if !bytes.HasPrefix(data[offset:], []byte(n.Value)) {
+		return textEdit{}, fmt.Errorf("value %q not found at line %d", n.Value, n.Line)
+	}
+	return textEdit{offset: offset, length: len(n.Value), text: newValue}, nil
+}
+
+// aliasEdit returns the edit replacing an alias (*name) with newValue
+func aliasEdit(data []byte, n *yaml.Node, newValue string) (textEdit, error) {
+	offset, err := nodeOffset(data, n)
+	if err != nil {
+		return textEdit{}, err
+	}
+	alias := "*" + n.Value
+	if !bytes.HasPrefix(data[offset:], []byte(alias)) {
+		return textEdit{}, fmt.Errorf("alias %s not found at line %d", alias, n.Line)
+	}
+	return textEdit{offset: offset, length: len(alias), text: newValue}, nil
+}
@@ -0,0 +1,180 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsWorkflowFile reports whether path has the extension of a workflow file
+// (.yml or .yaml)
+func IsWorkflowFile(path string) bool {
+	return strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+}
+
+// ExpandPaths expands workflow file arguments: directories are replaced by
+// the workflow files they contain (recursively), and glob patterns by the
+// files they match, where "**" matches any number of directories (e.g.,
+// "ci/**/*.yml"). Other paths are kept as is, so that missing files are
+// reported when they are loaded. Each expansion is sorted, and files given
+// more than once are only returned the first time. Patterns and directories
+// without workflow files are errors.
+func ExpandPaths(paths []string) ([]string, error) {
+	var expanded []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if key := filepath.Clean(p); !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, p)
+		}
+	}
+
+	for _, p := range paths {
+		var matches []string
+		var err error
+		switch {
+		case hasGlobMeta(p):
+			matches, err = globWorkflows(p)
+			if err == nil && len(matches) == 0 {
+				err = fmt.Errorf("no workflow files match %s", p)
+			}
+		case isDir(p):
+			matches, err = dirWorkflows(p)
+			if err == nil && len(matches) == 0 {
+				err = fmt.Errorf("no workflow files in directory %s", p)
+			}
+		default:
+			add(p)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	return expanded, nil
+}
+
+// hasGlobMeta reports whether p contains glob metacharacters
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+func isDir(p string) bool {
+	if source != nil {
+		// Listing a file returns the file itself
+		files, err := source.Files(p)
+		return err == nil && len(files) > 0 && filepath.Clean(files[0]) != filepath.Clean(p)
+	}
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+// dirWorkflows returns the workflow files in dir and its subdirectories
+func dirWorkflows(dir string) ([]string, error) {
+	all, err := repoFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow files in %s: %w", dir, err)
+	}
+	var files []string
+	for _, p := range all {
+		if IsWorkflowFile(p) {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// globWorkflows returns the workflow files matching pattern. The directory
+// tree is walked from the longest directory prefix without metacharacters.
+func globWorkflows(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	segments := strings.Split(pattern, "/")
+	if err := CheckPattern(pattern); err != nil {
+		return nil, err
+	}
+
+	root := "."
+	i := 0
+	for ; i < len(segments)-1 && !hasGlobMeta(segments[i]); i++ {
+	}
+	if i > 0 {
+		root = strings.Join(segments[:i], "/")
+		if root == "" {
+			root = "/"
+		}
+	}
+	if !isDir(root) {
+		return nil, nil
+	}
+
+	all, err := repoFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s: %w", pattern, err)
+	}
+	var files []string
+	for _, p := range all {
+		if !IsWorkflowFile(p) {
+			continue
+		}
+		rel := filepath.ToSlash(p)
+		if root == "." {
+			rel = strings.TrimPrefix(rel, "./")
+		}
+		if matchSegments(segments, strings.Split(rel, "/")) {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// CheckPattern returns an error if pattern is not a valid glob pattern
+func CheckPattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	return nil
+}
+
+// MatchPath reports whether p matches the glob pattern, where "**" matches any
+// number of directories. Patterns without a slash are matched against the
+// base name of p (e.g., "release-*.yml" matches ".github/workflows/release-v2.yml").
+func MatchPath(pattern, p string) bool {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	p = filepath.ToSlash(filepath.Clean(p))
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" pattern segment matches zero or more path segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
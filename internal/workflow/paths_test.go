@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		".github/workflows/ci.yml",
+		".github/workflows/release.yaml",
+		".github/workflows/README.md",
+		"ci/build.yml",
+		"ci/nested/deploy.yml",
+		"ci/nested/deeper/test.yaml",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("on: push\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(originalWd)
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "files are kept as is",
+			paths: []string{".github/workflows/ci.yml", "missing.yml"},
+			want:  []string{".github/workflows/ci.yml", "missing.yml"},
+		},
+		{
+			name:  "directory",
+			paths: []string{"./ci/"},
+			want:  []string{"ci/build.yml", "ci/nested/deeper/test.yaml", "ci/nested/deploy.yml"},
+		},
+		{
+			name:  "glob",
+			paths: []string{".github/workflows/*"},
+			want:  []string{".github/workflows/ci.yml", ".github/workflows/release.yaml"},
+		},
+		{
+			name:  "double star matches any number of directories",
+			paths: []string{"ci/**/*.yml"},
+			want:  []string{"ci/build.yml", "ci/nested/deploy.yml"},
+		},
+		{
+			name:  "double star from the working directory",
+			paths: []string{"**/test.yaml"},
+			want:  []string{"ci/nested/deeper/test.yaml"},
+		},
+		{
+			name:  "duplicates are removed",
+			paths: []string{".github/workflows/ci.yml", ".github/workflows/*.yml", "./.github/workflows/ci.yml"},
+			want:  []string{".github/workflows/ci.yml"},
+		},
+		{
+			name:    "pattern without matches",
+			paths:   []string{"ci/*.json"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			paths:   []string{"ci/[.yml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPaths(tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"release-*.yml", ".github/workflows/release-v2.yml", true},
+		{"release-*.yml", ".github/workflows/ci.yml", false},
+		{".github/workflows/deploy.yml", "./.github/workflows/deploy.yml", true},
+		{".github/workflows/*.yml", "ci/deploy.yml", false},
+		{"ci/**/*.yml", "ci/nested/deploy.yml", true},
+		{"**/deploy.yml", "ci/deploy.yml", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"gopkg.in/yaml.v3"
+)
+
+// IsUbuntuSlim checks if a job runs on ubuntu-slim, or on the target label
+// set with SetRunnerLabels
+func (j *Job) IsUbuntuSlim() bool {
+	return j.hasRunnerLabel(targetLabel)
+}
+
+// ContinuesOnError reports whether the job sets continue-on-error: true.
+// Expressions are not evaluated and count as false.
+func (j *Job) ContinuesOnError() bool {
+	switch v := j.ContinueOnError.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// PromoteJob replaces the job twinID with the job canaryID in a workflow file.
+// The twin is removed, and the canary drops continue-on-error and takes over the
+// twin's job ID and name, so needs: references and status check names keep
+// working. needs: references to the canary are updated to the new ID.
+// Comments and formatting of the rest of the file are preserved.
+func PromoteJob(filePath, canaryID, twinID string) error {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	updated, err := promoteJob(data, canaryID, twinID)
+	if err != nil {
+		return fmt.Errorf("failed to promote job %s over %s in %s: %w", canaryID, twinID, filePath, err)
+	}
+
+	return writeFileAtomic(filePath, updated)
+}
+
+// promoteJob returns the file content with the job twinID replaced by canaryID
+func promoteJob(data []byte, canaryID, twinID string) ([]byte, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	_, jobs := mappingValue(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode || jobs.Style == yaml.FlowStyle {
+		return nil, fmt.Errorf("jobs is not a block mapping")
+	}
+	canaryKey, canary := mappingValue(jobs, canaryID)
+	if canary == nil {
+		return nil, fmt.Errorf("job %s not found", canaryID)
+	}
+	twinKey, twin := mappingValue(jobs, twinID)
+	if twin == nil {
+		return nil, fmt.Errorf("job %s not found", twinID)
+	}
+	if canary.Kind != yaml.MappingNode || canary.Style == yaml.FlowStyle || len(canary.Content) == 0 {
+		return nil, fmt.Errorf("job %s is not a block mapping", canaryID)
+	}
+
+	var edits []textEdit
+
+	// Remove the twin job
+	edits = append(edits, jobBlockDeletion(data, root, jobs, twinKey))
+
+	// Make the canary's failures fail the workflow
+	if key, value := mappingValue(canary, "continue-on-error"); key != nil {
+		edit, err := keyLineDeletion(data, key, value)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+
+	// Take over the twin's job ID
+	edit, err := scalarValueEdit(data, canaryKey, twinID)
+	if err != nil {
+		return nil, err
+	}
+	edits = append(edits, edit)
+
+	// Take over the twin's name, which is the status check name
+	nameEdits, err := nameTakeover(data, canary, twin)
+	if err != nil {
+		return nil, err
+	}
+	edits = append(edits, nameEdits...)
+
+	// Point needs: references to the canary at its new ID
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		if jobs.Content[i] == twinKey {
+			continue
+		}
+		_, needs := mappingValue(jobs.Content[i+1], "needs")
+		nodes := needsNodes(needs)
+		needsTwin := false
+		for _, n := range nodes {
+			needsTwin = needsTwin || n.Value == twinID
+		}
+		for _, n := range nodes {
+			if n.Value != canaryID {
+				continue
+			}
+			// Jobs needing both keep a single reference
+			edit, err := scalarValueEdit(data, n, twinID)
+			if needsTwin {
+				edit, err = sequenceItemDeletion(data, needs, n)
+			}
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit)
+		}
+	}
+
+	out := applyEdits(data, edits)
+	if _, err := parseDocument(out); err != nil {
+		return nil, fmt.Errorf("result is not valid YAML: %w", err)
+	}
+	return out, nil
+}
+
+// nameTakeover returns the edits giving the canary the twin's name: line,
+// or removing the canary's name: line if the twin has none
+func nameTakeover(data []byte, canary, twin *yaml.Node) ([]textEdit, error) {
+	canaryNameKey, canaryName := mappingValue(canary, "name")
+	twinNameKey, twinName := mappingValue(twin, "name")
+
+	if twinNameKey == nil {
+		if canaryNameKey == nil {
+			return nil, nil
+		}
+		edit, err := keyLineDeletion(data, canaryNameKey, canaryName)
+		if err != nil {
+			return nil, err
+		}
+		return []textEdit{edit}, nil
+	}
+
+	if twinName.Line != twinNameKey.Line || twinName.Style == yaml.LiteralStyle || twinName.Style == yaml.FoldedStyle {
+		return nil, fmt.Errorf("name of the job at line %d must be a single-line scalar", twinNameKey.Line)
+	}
+	// Copy the twin's name: line verbatim to keep its quoting
+	start, err := nodeOffset(data, twinNameKey)
+	if err != nil {
+		return nil, err
+	}
+	nameLine := string(data[start:lineEnd(data, start)])
+
+	if canaryNameKey == nil {
+		// Insert the name before the canary's first key, other than the
+		// continue-on-error line that is removed
+		first := canary.Content[0]
+		if first.Value == "continue-on-error" && len(canary.Content) > 2 {
+			first = canary.Content[2]
+		}
+		offset, err := nodeOffset(data, first)
+		if err != nil {
+			return nil, err
+		}
+		return []textEdit{{offset: offset, text: nameLine + "\n" + strings.Repeat(" ", first.Column-1)}}, nil
+	}
+
+	if canaryName.Line != canaryNameKey.Line || canaryName.Style == yaml.LiteralStyle || canaryName.Style == yaml.FoldedStyle {
+		return nil, fmt.Errorf("name of the job at line %d must be a single-line scalar", canaryNameKey.Line)
+	}
+	offset, err := nodeOffset(data, canaryNameKey)
+	if err != nil {
+		return nil, err
+	}
+	return []textEdit{{offset: offset, length: lineEnd(data, offset) - offset, text: nameLine}}, nil
+}
+
+// needsNodes returns the job ID scalars of a needs: value
+func needsNodes(needs *yaml.Node) []*yaml.Node {
+	if needs == nil {
+		return nil
+	}
+	switch needs.Kind {
+	case yaml.ScalarNode:
+		return []*yaml.Node{needs}
+	case yaml.SequenceNode:
+		var nodes []*yaml.Node
+		for _, n := range needs.Content {
+			if n.Kind == yaml.ScalarNode {
+				nodes = append(nodes, n)
+			}
+		}
+		return nodes
+	}
+	return nil
+}
+
+// sequenceItemDeletion returns an edit removing a scalar item of a sequence.
+// Block sequence items are removed with their line, flow sequence items with
+// the separating comma.
+func sequenceItemDeletion(data []byte, seq, item *yaml.Node) (textEdit, error) {
+	i := 0
+	for i < len(seq.Content) && seq.Content[i] != item {
+		i++
+	}
+	if seq.Style != yaml.FlowStyle {
+		offset, err := nodeOffset(data, item)
+		if err != nil {
+			return textEdit{}, err
+		}
+		start := bytes.LastIndexByte(data[:offset], '\n') + 1
+		if prefix := bytes.TrimSpace(data[start:offset]); string(prefix) != "-" {
+			return textEdit{}, fmt.Errorf("sequence item at line %d is not on its own line", item.Line)
+		}
+		end := lineEnd(data, offset)
+		if end < len(data) {
+			end++ // Include the newline
+		}
+		return textEdit{offset: start, length: end - start}, nil
+	}
+
+	// Remove from the end of the previous item, or up to the next item for the first
+	if i > 0 {
+		start, err := scalarEnd(data, seq.Content[i-1])
+		if err != nil {
+			return textEdit{}, err
+		}
+		end, err := scalarEnd(data, item)
+		if err != nil {
+			return textEdit{}, err
+		}
+		return textEdit{offset: start, length: end - start}, nil
+	}
+	if len(seq.Content) == 1 {
+		return textEdit{}, fmt.Errorf("cannot remove the only item of the sequence at line %d", seq.Line)
+	}
+	start, err := nodeOffset(data, item)
+	if err != nil {
+		return textEdit{}, err
+	}
+	end, err := nodeOffset(data, seq.Content[1])
+	if err != nil {
+		return textEdit{}, err
+	}
+	return textEdit{offset: start, length: end - start}, nil
+}
+
+// scalarEnd returns the offset just after a single-line scalar, including its closing quote
+func scalarEnd(data []byte, n *yaml.Node) (int, error) {
+	edit, err := scalarValueEdit(data, n, n.Value)
+	if err != nil {
+		return 0, err
+	}
+	end := edit.offset + edit.length
+	if n.Style == yaml.DoubleQuotedStyle || n.Style == yaml.SingleQuotedStyle {
+		end++
+	}
+	return end, nil
+}
+
+// jobBlockDeletion returns an edit removing a job from the jobs mapping,
+// from its key line up to the next job or top-level key. Comments directly
+// above the next key belong to it and are kept.
+func jobBlockDeletion(data []byte, root, jobs, key *yaml.Node) textEdit {
+	next := 0
+	for i := 0; i+2 < len(jobs.Content); i += 2 {
+		if jobs.Content[i] == key {
+			next = jobs.Content[i+2].Line
+		}
+	}
+	if next == 0 {
+		// The last job ends at the next top-level key, if any
+		for i := 0; i < len(root.Content); i += 2 {
+			if root.Content[i].Line > key.Line {
+				next = root.Content[i].Line
+				break
+			}
+		}
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	start := lineOffset(lines, key.Line)
+	if next == 0 {
+		// Drop the blank lines that would be left at the end of the file
+		for line := key.Line - 1; line >= 1 && len(bytes.TrimSpace(lines[line-1])) == 0; line-- {
+			start = lineOffset(lines, line)
+		}
+		return textEdit{offset: start, length: len(data) - start}
+	}
+
+	for next-1 > key.Line {
+		line := lines[next-2]
+		trimmed := bytes.TrimLeft(line, " \t")
+		if !bytes.HasPrefix(trimmed, []byte("#")) || len(line)-len(trimmed) > key.Column-1 {
+			break
+		}
+		next--
+	}
+	return textEdit{offset: start, length: lineOffset(lines, next) - start}
+}
+
+// keyLineDeletion returns an edit removing the line holding a key with a
+// single-line scalar value (e.g., "continue-on-error: true")
+func keyLineDeletion(data []byte, key, value *yaml.Node) (textEdit, error) {
+	if value.Kind != yaml.ScalarNode || value.Line != key.Line || value.Style == yaml.LiteralStyle || value.Style == yaml.FoldedStyle {
+		return textEdit{}, fmt.Errorf("%s at line %d must be a single-line scalar", key.Value, key.Line)
+	}
+	offset, err := nodeOffset(data, key)
+	if err != nil {
+		return textEdit{}, err
+	}
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	if len(bytes.TrimSpace(data[start:offset])) != 0 {
+		return textEdit{}, fmt.Errorf("%s at line %d is not on its own line", key.Value, key.Line)
+	}
+	end := lineEnd(data, offset)
+	if end < len(data) {
+		end++ // Include the newline
+	}
+	return textEdit{offset: start, length: end - start}, nil
+}
+
+// lineEnd returns the offset of the newline ending the line containing offset,
+// or the length of data on the last line
+func lineEnd(data []byte, offset int) int {
+	if i := bytes.IndexByte(data[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(data)
+}
+
+// lineOffset returns the byte offset of a 1-based line given the lines of a file
+// split after each newline
+func lineOffset(lines [][]byte, line int) int {
+	offset := 0
+	for i := 0; i < line-1 && i < len(lines); i++ {
+		offset += len(lines[i])
+	}
+	return offset
+}
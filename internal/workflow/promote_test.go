@@ -0,0 +1,177 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPromoteJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		canary   string
+		twin     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:   "canary after its twin",
+			canary: "build-slim",
+			twin:   "build",
+			content: `on: push
+jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+
+  # Trying ubuntu-slim
+  build-slim:
+    name: Build (slim)
+    runs-on: ubuntu-slim
+    continue-on-error: true
+    steps:
+      - run: make
+
+  deploy:
+    needs: [build, build-slim]
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`,
+			expected: `on: push
+jobs:
+  # Trying ubuntu-slim
+  build:
+    name: Build
+    runs-on: ubuntu-slim
+    steps:
+      - run: make
+
+  deploy:
+    needs: [build]
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`,
+		},
+		{
+			name:   "twin is the last job",
+			canary: "build-slim",
+			twin:   "build",
+			content: `jobs:
+  build-slim:
+    continue-on-error: true
+    runs-on: ubuntu-slim
+    steps:
+      - run: make
+
+  release:
+    needs:
+      - build
+      - build-slim
+    runs-on: ubuntu-latest
+
+  build:
+    name: "Build: all"
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`,
+			expected: `jobs:
+  build:
+    name: "Build: all"
+    runs-on: ubuntu-slim
+    steps:
+      - run: make
+
+  release:
+    needs:
+      - build
+    runs-on: ubuntu-latest
+`,
+		},
+		{
+			name:   "canary name dropped when the twin has none",
+			canary: "test-slim",
+			twin:   "test",
+			content: `jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...
+  test-slim:
+    name: test (slim)
+    runs-on: ubuntu-slim
+    continue-on-error: true # canary
+    steps:
+      - run: go test ./...
+permissions: read-all
+`,
+			expected: `jobs:
+  test:
+    runs-on: ubuntu-slim
+    steps:
+      - run: go test ./...
+permissions: read-all
+`,
+		},
+		{
+			name:    "twin not found",
+			canary:  "build-slim",
+			twin:    "build",
+			content: "jobs:\n  build-slim:\n    runs-on: ubuntu-slim\n",
+			wantErr: true,
+		},
+		{
+			name:    "continue-on-error in a flow mapping",
+			canary:  "build-slim",
+			twin:    "build",
+			content: "jobs:\n  build:\n    runs-on: ubuntu-latest\n  build-slim: {runs-on: ubuntu-slim, continue-on-error: true}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "workflow.yml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			err := PromoteJob(filePath, tt.canary, tt.twin)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("PromoteJob() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PromoteJob() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read updated file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("PromoteJob() result mismatch\ngot:\n%s\nwant:\n%s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJob_ContinuesOnError(t *testing.T) {
+	for _, v := range []interface{}{true, "true"} {
+		if job := (&Job{ContinueOnError: v}); !job.ContinuesOnError() {
+			t.Errorf("ContinuesOnError() = false for %#v, want true", v)
+		}
+	}
+	for _, v := range []interface{}{nil, false, "${{ matrix.experimental }}"} {
+		if job := (&Job{ContinueOnError: v}); job.ContinuesOnError() {
+			t.Errorf("ContinuesOnError() = true for %#v, want false", v)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultSourceLabel is the runner label jobs are migrated from
+	DefaultSourceLabel = "ubuntu-latest"
+	// DefaultTargetLabel is the runner label jobs are migrated to
+	DefaultTargetLabel = "ubuntu-slim"
+	// DefaultArmLabel is the runner label jobs are migrated to when they are
+	// evaluated for ARM runners
+	DefaultArmLabel = "ubuntu-24.04-arm"
+)
+
+var (
+	sourceLabel = DefaultSourceLabel
+	targetLabel = DefaultTargetLabel
+)
+
+// SetRunnerLabels changes the runner labels jobs are migrated from and to
+// (e.g., to migrate ubuntu-24.04 jobs, or to target an organization's own
+// label). Jobs are evaluated as candidates if they run on from, and
+// UpdateRunsOnJobs and AddRunnerMatrix replace from. Empty labels keep the
+// defaults.
+func SetRunnerLabels(from, to string) error {
+	if from == "" {
+		from = DefaultSourceLabel
+	}
+	if to == "" {
+		to = DefaultTargetLabel
+	}
+	for _, label := range []string{from, to} {
+		if strings.ContainsAny(label, " \t\n,:[]{}\"'") || strings.Contains(label, "${{") {
+			return fmt.Errorf("invalid runner label %q", label)
+		}
+	}
+	if strings.EqualFold(from, to) {
+		return fmt.Errorf("the source and target runner labels are both %s", from)
+	}
+	sourceLabel, targetLabel = from, to
+	return nil
+}
+
+// SourceLabel returns the runner label jobs are migrated from (ubuntu-latest
+// unless changed with SetRunnerLabels)
+func SourceLabel() string {
+	return sourceLabel
+}
+
+// TargetLabel returns the runner label jobs are migrated to (ubuntu-slim
+// unless changed with SetRunnerLabels)
+func TargetLabel() string {
+	return targetLabel
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import "testing"
+
+func TestSetRunnerLabels(t *testing.T) {
+	defer SetRunnerLabels("", "")
+
+	if err := SetRunnerLabels("ubuntu-24.04", "ubuntu-slim-internal"); err != nil {
+		t.Fatalf("SetRunnerLabels() unexpected error: %v", err)
+	}
+	if SourceLabel() != "ubuntu-24.04" || TargetLabel() != "ubuntu-slim-internal" {
+		t.Errorf("labels = %s -> %s, want ubuntu-24.04 -> ubuntu-slim-internal", SourceLabel(), TargetLabel())
+	}
+
+	pinned := &Job{RunsOn: "ubuntu-24.04"}
+	latest := &Job{RunsOn: "ubuntu-latest"}
+	internal := &Job{RunsOn: []any{"ubuntu-slim-internal"}}
+	if !pinned.IsUbuntuLatest() || latest.IsUbuntuLatest() {
+		t.Error("IsUbuntuLatest() should match the source label only")
+	}
+	if !internal.IsUbuntuSlim() {
+		t.Error("IsUbuntuSlim() should match the target label")
+	}
+	if typos := internal.RunnerLabelTypos(); len(typos) != 0 {
+		t.Errorf("RunnerLabelTypos() = %v, want the target label to be known", typos)
+	}
+
+	for _, labels := range [][2]string{{"ubuntu-latest", "UBUNTU-LATEST"}, {"ubuntu-latest", "${{ matrix.os }}"}, {"a b", "ubuntu-slim"}} {
+		if err := SetRunnerLabels(labels[0], labels[1]); err == nil {
+			t.Errorf("SetRunnerLabels(%q, %q) expected an error", labels[0], labels[1])
+		}
+	}
+
+	if err := SetRunnerLabels("", ""); err != nil || SourceLabel() != DefaultSourceLabel || TargetLabel() != DefaultTargetLabel {
+		t.Errorf("SetRunnerLabels(\"\", \"\") should restore the defaults, got %s -> %s (%v)", SourceLabel(), TargetLabel(), err)
+	}
+}
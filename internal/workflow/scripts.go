@@ -0,0 +1,393 @@
+package workflow
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// workspacePrefixes are prefixes of script paths that refer to the repository root
+var workspacePrefixes = []string{
+	"${{ github.workspace }}/",
+	"${{github.workspace}}/",
+	"$GITHUB_WORKSPACE/",
+	"${GITHUB_WORKSPACE}/",
+}
+
+// maxScriptDepth limits how deeply scripts calling other scripts are followed
+const maxScriptDepth = 8
+
+// stepCommands returns the commands run by a step's run: script, with the
+// commands of repository scripts and Makefile targets it invokes inlined after
+// the invoking command (e.g., "./scripts/test.sh", "bash ci/build.sh", "make e2e").
+// Scripts of non-POSIX shells (shell: pwsh, python, cmd) are skipped.
+// Commands in the script inputs of the step's action (see AddScriptInputs)
+// follow, with the input as their source (e.g., "with: script").
+func stepCommands(step Step) []shellCommand {
+	var commands []shellCommand
+	if step.Run != "" && isPOSIXShell(step.Shell) {
+		commands = expandRepoScripts(parseShellCommands(step.Run), step.WorkingDirectory, make(map[string]bool), 0)
+	}
+	for _, key := range stepScriptInputs(step.Uses) {
+		script, ok := step.With[key].(string)
+		if !ok || script == "" {
+			continue
+		}
+		inputCommands := expandRepoScripts(parseShellCommands(script), step.WorkingDirectory, make(map[string]bool), 0)
+		for i := range inputCommands {
+			inputCommands[i].Offset = -1
+		}
+		commands = append(commands, withSource(inputCommands, "with: "+key)...)
+	}
+	return commands
+}
+
+// expandRepoScripts inlines the commands of repository scripts and Makefile
+// targets invoked by commands. dir is the working directory relative to the
+// repository root. visited holds the scripts and targets already expanded.
+func expandRepoScripts(commands []shellCommand, dir string, visited map[string]bool, depth int) []shellCommand {
+	if depth >= maxScriptDepth {
+		return commands
+	}
+
+	var out []shellCommand
+	for _, cmd := range commands {
+		out = append(out, cmd)
+		inlined := len(out)
+
+		i := cmd.commandIndex()
+		if i < 0 {
+			continue
+		}
+		name := normalizeCommand(cmd.Words[i])
+		args := cmd.Words[i+1:]
+
+		switch {
+		case name == "make" || name == "gmake":
+			out = append(out, makeCommands(args, dir, visited, depth)...)
+		case shellInterpreters[name] || name == "source" || name == ".":
+			if script := scriptArgument(args); script != "" {
+				out = append(out, scriptCommands(script, dir, true, visited, depth)...)
+			}
+		case strings.Contains(cmd.Words[i], "/"):
+			out = append(out, scriptCommands(cmd.Words[i], dir, false, visited, depth)...)
+		}
+		// Inlined commands are located at the command invoking them
+		for k := inlined; k < len(out); k++ {
+			out[k].Offset = cmd.Offset
+		}
+	}
+	return out
+}
+
+// scriptArgument returns the script path passed to a shell (bash -e ci/build.sh),
+// or "" if the shell runs a -c script or reads from stdin
+func scriptArgument(args []string) string {
+	for k, arg := range args {
+		if arg == "--" {
+			if k+1 < len(args) {
+				return args[k+1]
+			}
+			return ""
+		}
+		if strings.HasPrefix(arg, "-") {
+			if !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c") {
+				return ""
+			}
+			// -o option takes an argument
+			if arg == "-o" || arg == "+o" {
+				return ""
+			}
+			continue
+		}
+		return arg
+	}
+	return ""
+}
+
+// repoPath resolves a path used in a script relative to dir, returning the
+// path relative to the repository root, or false if it is outside the repository
+// or not a regular file
+func repoPath(path, dir string) (string, bool) {
+	for _, prefix := range workspacePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+			dir = ""
+			break
+		}
+	}
+	if path == "" || filepath.IsAbs(path) || strings.ContainsAny(path, "$`*?") {
+		return "", false
+	}
+
+	resolved := filepath.Join(dir, path)
+	if !filepath.IsLocal(resolved) {
+		return "", false
+	}
+	if !isRepoFile(resolved) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// scriptCommands returns the commands of a repository script. Unless
+// viaShell is true (bash script.sh), only shell scripts are read: files with a
+// .sh or .bash extension, or a shebang naming a shell.
+func scriptCommands(path, dir string, viaShell bool, visited map[string]bool, depth int) []shellCommand {
+	resolved, ok := repoPath(path, dir)
+	if !ok || visited[resolved] {
+		return nil
+	}
+
+	data, err := readRepoFile(resolved)
+	if err != nil {
+		return nil
+	}
+	if !viaShell && !isShellScript(resolved, data) {
+		return nil
+	}
+
+	visited[resolved] = true
+	return expandRepoScripts(withSource(parseShellCommands(string(data)), filepath.ToSlash(resolved)), dir, visited, depth+1)
+}
+
+// withSource sets the source of commands that do not have one yet
+func withSource(commands []shellCommand, source string) []shellCommand {
+	for i := range commands {
+		if commands[i].Source == "" {
+			commands[i].Source = source
+		}
+	}
+	return commands
+}
+
+// isShellScript reports whether a file is a shell script, judged by its
+// extension or shebang line
+func isShellScript(path string, data []byte) bool {
+	switch filepath.Ext(path) {
+	case ".sh", ".bash":
+		return true
+	}
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if !strings.HasPrefix(firstLine, "#!") {
+		return false
+	}
+	for _, field := range strings.Fields(strings.TrimPrefix(firstLine, "#!")) {
+		if shellInterpreters[filepath.Base(field)] {
+			return true
+		}
+	}
+	return false
+}
+
+// makeOptionsWithArgument are make options that take a separate argument
+var makeOptionsWithArgument = map[string]bool{
+	"-I": true, "-o": true, "-W": true, "--include-dir": true,
+}
+
+// makeCommands returns the recipe commands run by "make args" in dir,
+// including the recipes of prerequisites
+func makeCommands(args []string, dir string, visited map[string]bool, depth int) []shellCommand {
+	makeDir := dir
+	var makefile string
+	var targets []string
+	for k := 0; k < len(args); k++ {
+		arg := args[k]
+		switch {
+		case arg == "-C" || arg == "--directory" || arg == "-f" || arg == "--file" || arg == "--makefile":
+			if k+1 >= len(args) {
+				break
+			}
+			k++
+			if arg == "-C" || arg == "--directory" {
+				makeDir = filepath.Join(makeDir, args[k])
+			} else {
+				makefile = args[k]
+			}
+		case strings.HasPrefix(arg, "-C") && len(arg) > 2:
+			makeDir = filepath.Join(makeDir, arg[2:])
+		case strings.HasPrefix(arg, "--directory="):
+			makeDir = filepath.Join(makeDir, strings.TrimPrefix(arg, "--directory="))
+		case strings.HasPrefix(arg, "-f") && len(arg) > 2:
+			makefile = arg[2:]
+		case strings.HasPrefix(arg, "--file=") || strings.HasPrefix(arg, "--makefile="):
+			_, makefile, _ = strings.Cut(arg, "=")
+		case strings.HasPrefix(arg, "-"):
+			if makeOptionsWithArgument[arg] {
+				k++
+			}
+		case strings.Contains(arg, "="):
+			// Variable override: make VERSION=1.0
+		default:
+			targets = append(targets, arg)
+		}
+	}
+
+	var mf *makefileRules
+	if makefile != "" {
+		mf = loadMakefile(filepath.Join(makeDir, makefile))
+	} else {
+		for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+			if mf = loadMakefile(filepath.Join(makeDir, name)); mf != nil {
+				break
+			}
+		}
+	}
+	if mf == nil {
+		return nil
+	}
+	if len(targets) == 0 && mf.defaultTarget != "" {
+		targets = []string{mf.defaultTarget}
+	}
+
+	var commands []shellCommand
+	var visit func(target string)
+	visit = func(target string) {
+		key := mf.path + ":" + target
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		rule, ok := mf.rules[target]
+		if !ok {
+			return
+		}
+		for _, prereq := range rule.prerequisites {
+			visit(prereq)
+		}
+		source := filepath.ToSlash(mf.path) + " (make " + target + ")"
+		for _, line := range rule.recipe {
+			commands = append(commands, withSource(parseShellCommands(mf.expand(line)), source)...)
+		}
+	}
+	for _, target := range targets {
+		visit(target)
+	}
+
+	// Recipes run in the Makefile's directory
+	return expandRepoScripts(commands, makeDir, visited, depth+1)
+}
+
+// makefileRules is the parts of a Makefile needed to find the commands a target runs
+type makefileRules struct {
+	path          string
+	defaultTarget string
+	rules         map[string]*makeRule
+	vars          map[string]string
+}
+
+// makeRule is an explicit Makefile rule
+type makeRule struct {
+	prerequisites []string
+	recipe        []string
+}
+
+var (
+	// makeAssignmentPattern matches variable assignments (VAR = value, VAR := value, VAR ?= value, VAR += value)
+	makeAssignmentPattern = regexp.MustCompile(`^(?:export\s+|override\s+)?([A-Za-z_][A-Za-z0-9_.-]*)\s*(?:\?|:|::|:::|\+|!)?=\s*(.*)$`)
+	// makeVariablePattern matches variable references $(VAR) and ${VAR}
+	makeVariablePattern = regexp.MustCompile(`\$[({]([A-Za-z_][A-Za-z0-9_.-]*)[)}]`)
+)
+
+// loadMakefile parses a Makefile in the repository, returning nil if it cannot be read
+func loadMakefile(path string) *makefileRules {
+	resolved, ok := repoPath(path, "")
+	if !ok {
+		return nil
+	}
+	data, err := readRepoFile(resolved)
+	if err != nil {
+		return nil
+	}
+
+	mf := &makefileRules{
+		path:  resolved,
+		rules: make(map[string]*makeRule),
+		vars:  map[string]string{"MAKE": "make"},
+	}
+
+	var current []*makeRule
+	var pending string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Join continued lines
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line = pending + line
+		pending = ""
+
+		if strings.HasPrefix(line, "\t") {
+			for _, rule := range current {
+				rule.recipe = append(rule.recipe, strings.TrimPrefix(line, "\t"))
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if m := makeAssignmentPattern.FindStringSubmatch(trimmed); m != nil {
+			mf.vars[m[1]] = m[2]
+			current = nil
+			continue
+		}
+
+		targetsPart, rest, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			// Directives (include, ifeq, endif, ...) keep collecting the
+			// current rules' recipes across conditional branches
+			continue
+		}
+		rest = strings.TrimPrefix(rest, ":")
+		prereqs, inlineRecipe, _ := strings.Cut(rest, ";")
+
+		current = nil
+		for _, target := range strings.Fields(targetsPart) {
+			if strings.Contains(target, "%") {
+				continue
+			}
+			if mf.defaultTarget == "" && !strings.HasPrefix(target, ".") {
+				mf.defaultTarget = target
+			}
+			rule, ok := mf.rules[target]
+			if !ok {
+				rule = &makeRule{}
+				mf.rules[target] = rule
+			}
+			rule.prerequisites = append(rule.prerequisites, strings.Fields(prereqs)...)
+			if inlineRecipe = strings.TrimSpace(inlineRecipe); inlineRecipe != "" {
+				rule.recipe = append(rule.recipe, inlineRecipe)
+			}
+			current = append(current, rule)
+		}
+	}
+	return mf
+}
+
+// expand prepares a recipe line for shell parsing: it strips the @, -, and +
+// prefixes, expands Makefile variables, and unescapes $$
+func (mf *makefileRules) expand(line string) string {
+	line = strings.TrimLeft(strings.TrimSpace(line), "@-+")
+	// Expand a few levels of nested variables
+	for range 4 {
+		expanded := makeVariablePattern.ReplaceAllStringFunc(line, func(ref string) string {
+			name := makeVariablePattern.FindStringSubmatch(ref)[1]
+			if value, ok := mf.vars[name]; ok {
+				return value
+			}
+			return ref
+		})
+		if expanded == line {
+			break
+		}
+		line = expanded
+	}
+	return strings.ReplaceAll(line, "$$", "$")
+}
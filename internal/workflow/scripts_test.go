@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeRepoFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJob_RepoScripts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeRepoFiles(t, map[string]string{
+		"scripts/build.sh":  "#!/usr/bin/env bash\nset -euo pipefail\nsource scripts/lib.sh\n",
+		"scripts/lib.sh":    "build_image() { docker build -t app .; }\nbuild_image\n",
+		"scripts/check":     "#!/bin/sh\nlsof -i :8080\n",
+		"scripts/loop.sh":   "./scripts/loop.sh\ntree .\n",
+		"scripts/tool.py":   "#!/usr/bin/env python3\nprint('docker build')\n",
+		"ci/test.sh":        "echo testing\n",
+		"web/run.sh":        "docker compose up -d\n",
+		"Makefile":          "DOCKER ?= docker\n\n.PHONY: all e2e lint\nall: lint\n\ne2e: image\n\t@echo running e2e\n\nimage:\n\t$(DOCKER) build -t app .\n\nlint:\n\t-tree src\n",
+		"web/Makefile":      "test: ; helm lint chart\n",
+		"nested/Makefile":   "build:\n\t$(MAKE) -C .. lint\n",
+		"broken/Makefile":   "all:\n\t$(MAKE) all\n",
+		"docs/not-a-script": "docker build .\n",
+	})
+
+	tests := []struct {
+		name        string
+		step        Step
+		wantDocker  bool
+		wantMissing []string
+	}{
+		{
+			name:        "script sourcing a library",
+			step:        Step{Run: "./scripts/build.sh"},
+			wantDocker:  true,
+			wantMissing: []string{"docker"},
+		},
+		{
+			name:        "script run with an explicit shell",
+			step:        Step{Run: "bash -e scripts/build.sh --release"},
+			wantDocker:  true,
+			wantMissing: []string{"docker"},
+		},
+		{
+			name:        "script without extension but with a shebang",
+			step:        Step{Run: "scripts/check"},
+			wantMissing: []string{"lsof"},
+		},
+		{
+			name:        "recursive script",
+			step:        Step{Run: "./scripts/loop.sh"},
+			wantMissing: []string{"tree"},
+		},
+		{
+			name: "non-shell script",
+			step: Step{Run: "./scripts/tool.py"},
+		},
+		{
+			name: "file that is not a script",
+			step: Step{Run: "./docs/not-a-script"},
+		},
+		{
+			name:        "workspace-relative path",
+			step:        Step{Run: "${{ github.workspace }}/scripts/build.sh"},
+			wantDocker:  true,
+			wantMissing: []string{"docker"},
+		},
+		{
+			name:        "working directory",
+			step:        Step{Run: "./run.sh", WorkingDirectory: "web"},
+			wantDocker:  true,
+			wantMissing: []string{"docker"},
+		},
+		{
+			name:        "make target with prerequisites and variables",
+			step:        Step{Run: "make e2e"},
+			wantDocker:  true,
+			wantMissing: []string{"docker"},
+		},
+		{
+			name:        "make default target",
+			step:        Step{Run: "make"},
+			wantMissing: []string{"tree"},
+		},
+		{
+			name:        "make in another directory with an inline recipe",
+			step:        Step{Run: "make -C web test"},
+			wantMissing: []string{"helm"},
+		},
+		{
+			name:        "recursive make",
+			step:        Step{Run: "make -C nested build"},
+			wantMissing: []string{"tree"},
+		},
+		{
+			name: "make target calling itself",
+			step: Step{Run: "make -C broken"},
+		},
+		{
+			name: "missing script",
+			step: Step{Run: "./scripts/missing.sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{RunsOn: "ubuntu-latest", Steps: []Step{tt.step}}
+			if got := job.HasDockerCommands(); got != tt.wantDocker {
+				t.Errorf("HasDockerCommands() = %v, want %v", got, tt.wantDocker)
+			}
+			if got := job.GetMissingCommands(); !reflect.DeepEqual(got, tt.wantMissing) {
+				t.Errorf("GetMissingCommands() = %v, want %v", got, tt.wantMissing)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fchimpan/gh-slimify/internal/readonly"
+	"gopkg.in/yaml.v3"
+)
+
+// aptPackages maps commands missing in ubuntu-slim to the apt package that
+// installs them, for commands whose package is known
+var aptPackages = map[string]string{
+	"7z":         "p7zip-full",
+	"Xvfb":       "xvfb",
+	"ant":        "ant",
+	"aria2c":     "aria2",
+	"autoconf":   "autoconf",
+	"brotli":     "brotli",
+	"clang":      "clang",
+	"cmake":      "cmake",
+	"dig":        "dnsutils",
+	"file":       "file",
+	"git-lfs":    "git-lfs",
+	"haveged":    "haveged",
+	"htop":       "htop",
+	"ip":         "iproute2",
+	"lsof":       "lsof",
+	"lz4":        "lz4",
+	"mvn":        "maven",
+	"mysql":      "mysql-client",
+	"mysqldump":  "mysql-client",
+	"nc":         "netcat-openbsd",
+	"netstat":    "net-tools",
+	"ninja":      "ninja-build",
+	"nslookup":   "dnsutils",
+	"pg_dump":    "postgresql-client",
+	"pigz":       "pigz",
+	"ping":       "iputils-ping",
+	"pkg-config": "pkg-config",
+	"psql":       "postgresql-client",
+	"rsync":      "rsync",
+	"shellcheck": "shellcheck",
+	"sqlite3":    "sqlite3",
+	"ss":         "iproute2",
+	"sshpass":    "sshpass",
+	"strace":     "strace",
+	"tree":       "tree",
+	"upx":        "upx-ucl",
+	"xvfb-run":   "xvfb",
+	"yamllint":   "yamllint",
+	"zip":        "zip",
+	"zstd":       "zstd",
+}
+
+// setupActionSteps maps commands missing in ubuntu-slim to the official setup
+// action that installs them, with the inputs selecting the version
+// preinstalled on ubuntu-latest
+var setupActionSteps = map[string]SetupStep{
+	"cargo":     setupRust,
+	"composer":  setupPHP,
+	"conda":     {Uses: "conda-incubator/setup-miniconda@v3", With: [][2]string{{"miniconda-version", "latest"}}},
+	"dotnet":    {Uses: "actions/setup-dotnet@v4", With: [][2]string{{"dotnet-version", "8.0.x"}}},
+	"gcloud":    {Uses: "google-github-actions/setup-gcloud@v2"},
+	"gem":       setupRuby,
+	"go":        setupGo,
+	"gofmt":     setupGo,
+	"gradle":    setupJava,
+	"helm":      {Uses: "azure/setup-helm@v4"},
+	"java":      setupJava,
+	"javac":     setupJava,
+	"julia":     {Uses: "julia-actions/setup-julia@v2", With: [][2]string{{"version", "1"}}},
+	"kubectl":   {Uses: "azure/setup-kubectl@v4"},
+	"packer":    {Uses: "hashicorp/setup-packer@v3"},
+	"php":       setupPHP,
+	"python":    {Uses: "actions/setup-python@v5", With: [][2]string{{"python-version", "3.x"}}},
+	"ruby":      setupRuby,
+	"rustc":     setupRust,
+	"rustup":    setupRust,
+	"terraform": {Uses: "hashicorp/setup-terraform@v3"},
+}
+
+// Setup action steps shared by the commands of a toolchain
+var (
+	setupGo   = SetupStep{Uses: "actions/setup-go@v5", With: [][2]string{{"go-version", "stable"}}}
+	setupJava = SetupStep{Uses: "actions/setup-java@v4", With: [][2]string{{"distribution", "temurin"}, {"java-version", "17"}}}
+	setupPHP  = SetupStep{Uses: "shivammathur/setup-php@v2", With: [][2]string{{"php-version", "8.3"}}}
+	setupRuby = SetupStep{Uses: "ruby/setup-ruby@v1", With: [][2]string{{"ruby-version", "3.2"}}}
+	setupRust = SetupStep{Uses: "dtolnay/rust-toolchain@stable"}
+)
+
+// SetupActions returns the setup actions (uses: values with their version)
+// installing the given missing commands, once each in the order of the
+// commands. Commands without a setup action are skipped.
+func SetupActions(commands []string) []string {
+	var actions []string
+	seen := make(map[string]bool)
+	for _, cmd := range commands {
+		step, ok := setupActionSteps[cmd]
+		if !ok || seen[step.Uses] {
+			continue
+		}
+		seen[step.Uses] = true
+		actions = append(actions, step.Uses)
+	}
+	return actions
+}
+
+// Installer describes how to install a command missing in ubuntu-slim: its
+// setup action (e.g., "actions/setup-go@v5") or apt package (e.g., "apt-get
+// install lsof"), or returns "" if neither is known
+func Installer(cmd string) string {
+	if step, ok := setupActionSteps[cmd]; ok {
+		return step.Uses
+	}
+	if pkg, ok := aptPackages[cmd]; ok {
+		return "apt-get install " + pkg
+	}
+	return ""
+}
+
+// SetupStep is a step inserted by AddSetupSteps to install commands missing
+// in ubuntu-slim: a setup action (Uses and With) or a command (Run)
+type SetupStep struct {
+	Name     string      `json:"name,omitempty"`
+	Uses     string      `json:"uses,omitempty"`
+	With     [][2]string `json:"with,omitempty"` // Inputs in order, as name/value pairs
+	Run      string      `json:"run,omitempty"`
+	Commands []string    `json:"commands"` // Missing commands the step installs
+}
+
+// PlanSetupSteps returns the steps installing the given missing commands: one
+// apt-get install step for the commands with a known package, and a setup
+// action step per toolchain. Commands without a known installer are returned
+// as unknown.
+func PlanSetupSteps(commands []string) (steps []SetupStep, unknown []string) {
+	var aptCommands, packages []string
+	seenPackages := make(map[string]bool)
+	actions := make(map[string]int) // Index of the step of each action
+	for _, cmd := range commands {
+		if step, ok := setupActionSteps[cmd]; ok {
+			if i, ok := actions[step.Uses]; ok {
+				steps[i].Commands = append(steps[i].Commands, cmd)
+				continue
+			}
+			step.Commands = []string{cmd}
+			actions[step.Uses] = len(steps)
+			steps = append(steps, step)
+			continue
+		}
+		pkg, ok := aptPackages[cmd]
+		if !ok {
+			unknown = append(unknown, cmd)
+			continue
+		}
+		aptCommands = append(aptCommands, cmd)
+		if !seenPackages[pkg] {
+			seenPackages[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) > 0 {
+		sort.Strings(packages)
+		apt := SetupStep{
+			Name:     "Install commands missing in ubuntu-slim",
+			Run:      "sudo apt-get update && sudo apt-get install -y --no-install-recommends " + strings.Join(packages, " "),
+			Commands: aptCommands,
+		}
+		steps = append([]SetupStep{apt}, steps...)
+	}
+	return steps, unknown
+}
+
+// lines returns the YAML lines of the step as a sequence item, with the
+// dash indented by indent spaces
+func (s SetupStep) lines(indent int) []string {
+	item := pad(indent) + "- "
+	body := pad(indent + 2)
+	var lines []string
+	add := func(line string) {
+		if len(lines) == 0 {
+			lines = append(lines, item+line)
+		} else {
+			lines = append(lines, body+line)
+		}
+	}
+	if s.Name != "" {
+		add("name: " + s.Name)
+	}
+	if s.Uses != "" {
+		add("uses: " + s.Uses)
+	}
+	if len(s.With) > 0 {
+		add("with:")
+		for _, in := range s.With {
+			lines = append(lines, body+"  "+in[0]+": "+quoteScalar(in[1]))
+		}
+	}
+	if s.Run != "" {
+		add("run: " + s.Run)
+	}
+	return lines
+}
+
+// quoteScalar returns v as a YAML scalar, double-quoted if YAML would not
+// read it as a string (e.g., java-version: "17")
+func quoteScalar(v string) string {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(v), &n); err != nil || len(n.Content) == 0 || n.Content[0].Tag != "!!str" {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+// AddSetupSteps inserts steps at the top of jobs, keyed by job ID, so that
+// the commands they install are available to the following steps. Like
+// UpdateRunsOnJobs, all jobs are updated in a single atomic write, and jobs
+// that cannot be edited are returned with their error, keyed by job ID.
+func AddSetupSteps(filePath string, steps map[string][]SetupStep) (map[string]error, error) {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML %s: %w", filePath, err)
+	}
+
+	jobIDs := make([]string, 0, len(steps))
+	for jobID := range steps {
+		jobIDs = append(jobIDs, jobID)
+	}
+	sort.Strings(jobIDs)
+
+	failed := make(map[string]error)
+	var edits []textEdit
+	for _, jobID := range jobIDs {
+		edit, err := setupStepsEdit(data, root, jobID, steps[jobID])
+		if err != nil {
+			failed[jobID] = err
+			continue
+		}
+		edits = append(edits, edit)
+	}
+	if len(edits) == 0 {
+		return failed, nil
+	}
+
+	updated := applyEdits(data, edits)
+	if _, err := parseDocument(updated); err != nil {
+		return nil, fmt.Errorf("failed to update %s: result is not valid YAML: %w", filePath, err)
+	}
+	if err := writeFileAtomic(filePath, updated); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// setupStepsEdit returns the edit inserting steps before the first step of a
+// job, indented like it
+func setupStepsEdit(data []byte, root *yaml.Node, jobID string, steps []SetupStep) (textEdit, error) {
+	_, job := jobNode(root, jobID)
+	if job == nil {
+		return textEdit{}, fmt.Errorf("job %s not found", jobID)
+	}
+	_, jobSteps := mappingValue(job, "steps")
+	if jobSteps == nil || jobSteps.Kind != yaml.SequenceNode || jobSteps.Style == yaml.FlowStyle || len(jobSteps.Content) == 0 {
+		return textEdit{}, fmt.Errorf("steps of job %s are not a block sequence", jobID)
+	}
+
+	// The first step starts after its dash: "      - uses: ..."
+	first := jobSteps.Content[0]
+	offset, err := nodeOffset(data, first)
+	if err != nil {
+		return textEdit{}, err
+	}
+	start := strings.LastIndexByte(string(data[:offset]), '\n') + 1
+	prefix := string(data[start:offset])
+	indent := strings.Index(prefix, "-")
+	if indent < 0 || strings.TrimSpace(prefix) != "-" {
+		return textEdit{}, fmt.Errorf("the first step of job %s at line %d does not start its line", jobID, first.Line)
+	}
+
+	nl := newline(data)
+	var text strings.Builder
+	for _, s := range steps {
+		for _, line := range s.lines(indent) {
+			text.WriteString(line + nl)
+		}
+	}
+	return textEdit{offset: start, text: text.String()}, nil
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanSetupSteps(t *testing.T) {
+	steps, unknown := PlanSetupSteps([]string{"lsof", "java", "psql", "javac", "zstd", "frobnicate", "pg_dump"})
+	if !reflect.DeepEqual(unknown, []string{"frobnicate"}) {
+		t.Errorf("unknown = %v, want [frobnicate]", unknown)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("PlanSetupSteps() returned %d steps, want an apt-get step and a setup-java step: %+v", len(steps), steps)
+	}
+	apt, java := steps[0], steps[1]
+	if want := "sudo apt-get update && sudo apt-get install -y --no-install-recommends lsof postgresql-client zstd"; apt.Run != want {
+		t.Errorf("apt-get step run = %q, want %q", apt.Run, want)
+	}
+	if !reflect.DeepEqual(apt.Commands, []string{"lsof", "psql", "zstd", "pg_dump"}) {
+		t.Errorf("apt-get step commands = %v", apt.Commands)
+	}
+	if java.Uses != "actions/setup-java@v4" || !reflect.DeepEqual(java.Commands, []string{"java", "javac"}) {
+		t.Errorf("setup-java step = %+v, want one step for java and javac", java)
+	}
+
+	if steps, unknown := PlanSetupSteps([]string{"frobnicate"}); len(steps) != 0 || len(unknown) != 1 {
+		t.Errorf("PlanSetupSteps(frobnicate) = %v, %v, want no steps", steps, unknown)
+	}
+
+	// The inserted steps must be recognized as installing the commands
+	for cmd := range aptPackages {
+		steps, _ := PlanSetupSteps([]string{cmd})
+		job := &Job{Steps: []Step{{Run: steps[0].Run}, {Run: cmd + " --version"}}}
+		if missing := job.GetMissingCommands(); len(missing) != 0 {
+			t.Errorf("%s is still missing after the apt-get step: %v", cmd, missing)
+		}
+	}
+	for cmd, step := range setupActionSteps {
+		job := &Job{Steps: []Step{{Uses: step.Uses}, {Run: cmd + " --version"}}}
+		if missing := job.GetMissingCommands(); len(missing) != 0 {
+			t.Errorf("%s is still missing after %s: %v", cmd, step.Uses, missing)
+		}
+	}
+}
+
+func TestSetupActions(t *testing.T) {
+	got := SetupActions([]string{"lsof", "go", "javac", "gofmt", "java"})
+	want := []string{"actions/setup-go@v5", "actions/setup-java@v4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SetupActions() = %v, want %v", got, want)
+	}
+}
+
+func TestAddSetupSteps(t *testing.T) {
+	content := `jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: java -version && lsof -i :8080
+  lint:
+    runs-on: ubuntu-latest
+    steps: [{run: make lint}]
+`
+	expected := `jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Install commands missing in ubuntu-slim
+        run: sudo apt-get update && sudo apt-get install -y --no-install-recommends lsof
+      - uses: actions/setup-java@v4
+        with:
+          distribution: temurin
+          java-version: "17"
+      - uses: actions/checkout@v4
+      - run: java -version && lsof -i :8080
+  lint:
+    runs-on: ubuntu-latest
+    steps: [{run: make lint}]
+`
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	testSteps, _ := PlanSetupSteps([]string{"java", "lsof"})
+	lintSteps, _ := PlanSetupSteps([]string{"zstd"})
+	failed, err := AddSetupSteps(filePath, map[string][]SetupStep{"test": testSteps, "lint": lintSteps})
+	if err != nil {
+		t.Fatalf("AddSetupSteps() unexpected error: %v", err)
+	}
+	if failed["test"] != nil {
+		t.Errorf("AddSetupSteps() unexpected error for job test: %v", failed["test"])
+	}
+	if failed["lint"] == nil {
+		t.Error("AddSetupSteps() expected an error for the flow sequence of job lint")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+	if string(data) != expected {
+		t.Errorf("AddSetupSteps() result mismatch\ngot:\n%s\nwant:\n%s", data, expected)
+	}
+
+	wf, err := LoadWorkflow(filePath)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() unexpected error: %v", err)
+	}
+	if missing := wf.Jobs["test"].GetMissingCommands(); len(missing) != 0 {
+		t.Errorf("GetMissingCommands() after AddSetupSteps = %v, want none", missing)
+	}
+}
@@ -0,0 +1,671 @@
+package workflow
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// shellCommand is a simple command parsed from a shell script.
+// Words are the command's words with quotes removed; redirections,
+// variable assignments before the command, and reserved words are not included.
+type shellCommand struct {
+	Words []string
+	// Piped reports whether the command reads the output of the previous
+	// command in a pipeline (e.g., sh in "curl -fsSL https://example.com | sh")
+	Piped bool
+	// Source is the repository script or Makefile target the command comes
+	// from, or empty for commands in the step's own run: script
+	Source string
+	// Offset is the line of the script the command starts on, counted from
+	// 0. Commands of substitutions, sh -c scripts, and repository scripts
+	// have the line of the command running them; -1 means unknown.
+	Offset int
+}
+
+// commandPrefixes are commands that run the command given in their arguments.
+// The value lists options that take a separate argument (e.g., sudo -u user).
+var commandPrefixes = map[string]map[string]bool{
+	"sudo":   {"-u": true, "-g": true, "-C": true, "-D": true, "-h": true, "-p": true, "-r": true, "-t": true, "-U": true},
+	"env":    {"-u": true, "-C": true},
+	"time":   {"-f": true, "-o": true},
+	"nohup":  {},
+	"setsid": {},
+	"stdbuf": {},
+}
+
+// shellInterpreters are shells whose -c argument is parsed as a script
+var shellInterpreters = map[string]bool{"sh": true, "bash": true, "dash": true, "zsh": true}
+
+// isPOSIXShell reports whether the shell of a step (its shell: value, or ""
+// for the default bash) runs POSIX shell scripts, whose commands can be
+// parsed. Scripts of other shells (pwsh, powershell, python, cmd) are not.
+func isPOSIXShell(shell string) bool {
+	fields := strings.Fields(shell)
+	if len(fields) == 0 {
+		return true
+	}
+	return shellInterpreters[path.Base(fields[0])]
+}
+
+// shellReservedWords are reserved words that can appear in command position
+// and do not name a command
+var shellReservedWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true,
+	"!": true, "{": true, "}": true, "esac": true,
+}
+
+// assignmentPattern matches the NAME= (or NAME+=) prefix of a variable assignment
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\+?=`)
+
+// variableReferencePattern matches a word that is a single variable expansion ($NAME or ${NAME})
+var variableReferencePattern = regexp.MustCompile(`^\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\})$`)
+
+// maxShellNesting limits recursion into command substitutions, sh -c scripts,
+// and variables holding commands
+const maxShellNesting = 8
+
+// commandIndex returns the index of the command name in Words, skipping
+// prefixes such as sudo and env along with their options and assignments.
+// Returns -1 if there is no command (e.g., "sudo" alone).
+func (c shellCommand) commandIndex() int {
+	i := 0
+	for i < len(c.Words) {
+		argOptions, ok := commandPrefixes[normalizeCommand(c.Words[i])]
+		if !ok {
+			return i
+		}
+		i++
+		for i < len(c.Words) {
+			word := c.Words[i]
+			if word == "--" {
+				i++
+				break
+			}
+			if strings.HasPrefix(word, "-") {
+				i++
+				if argOptions[word] {
+					i++
+				}
+				continue
+			}
+			if assignmentPattern.MatchString(word) {
+				i++
+				continue
+			}
+			break
+		}
+	}
+	return -1
+}
+
+// Name returns the basename of the command run by c, or "" if there is none
+func (c shellCommand) Name() string {
+	i := c.commandIndex()
+	if i < 0 {
+		return ""
+	}
+	return normalizeCommand(c.Words[i])
+}
+
+// Line returns the command name followed by its arguments, separated by spaces
+// (e.g., "docker build -t app ." for "sudo /usr/bin/docker build -t app .")
+func (c shellCommand) Line() string {
+	i := c.commandIndex()
+	if i < 0 {
+		return ""
+	}
+	words := append([]string{normalizeCommand(c.Words[i])}, c.Words[i+1:]...)
+	return strings.Join(words, " ")
+}
+
+// parseShellCommands parses a shell script into the simple commands it runs,
+// in order. It understands quoting, comments, heredocs, pipelines and lists,
+// compound commands (if, for, while, case, subshells, brace groups), function
+// definitions, command and process substitution, sh -c scripts, and variables
+// assigned a command in the same script (CMD='docker build'; $CMD).
+// Parsing is best effort: malformed scripts never fail, they just yield fewer commands.
+func parseShellCommands(script string) []shellCommand {
+	return newShellParser(script, 0).parse()
+}
+
+type shellTokenKind int
+
+const (
+	shellEOF      shellTokenKind = iota
+	shellWord                    // a word, with quotes removed
+	shellOperator                // newline, ;, &, |, &&, ||, ;;, (, ), etc.
+	shellRedirect                // <, >, >>, <<, <<-, <<<, >&, etc.
+)
+
+type shellToken struct {
+	kind   shellTokenKind
+	text   string
+	quoted bool // the word contained quotes or escapes
+	// quoteAt is the offset in text where quoting started, or -1 if unquoted.
+	// NAME='value' is still an assignment since its name is unquoted.
+	quoteAt int
+	start   int // Offset of the token in the script
+}
+
+// isAssignment reports whether the token is a variable assignment (NAME=value)
+func (t shellToken) isAssignment() bool {
+	loc := assignmentPattern.FindStringIndex(t.text)
+	return loc != nil && (t.quoteAt < 0 || loc[1] <= t.quoteAt)
+}
+
+// shellOperators lists control and redirection operators, longest first
+var shellOperators = []string{
+	";;&", "&>>", "<<-", "<<<",
+	"&&", "||", ";;", ";&", "|&", "&>", ">>", "<<", ">&", "<&", "<>", ">|",
+	"\n", ";", "&", "|", "(", ")", "<", ">",
+}
+
+// shellHeredoc is a heredoc whose body starts after the next newline
+type shellHeredoc struct {
+	delimiter string
+	stripTabs bool
+}
+
+// shellParser tokenizes and parses a shell script
+type shellParser struct {
+	src      []rune
+	pos      int
+	depth    int
+	heredocs []shellHeredoc
+	subs     []string // scripts of substitutions in the current word
+	vars     map[string]string
+	// line is the line of the script at offset lineAt, counted from 0
+	line, lineAt int
+}
+
+func newShellParser(script string, depth int) *shellParser {
+	return &shellParser{src: []rune(script), depth: depth, vars: make(map[string]string)}
+}
+
+// parse parses the whole script
+func (p *shellParser) parse() []shellCommand {
+	var commands []shellCommand
+	var words []string
+	offset := 0 // Line of the first word
+
+	// Compound command state
+	skipUntilSeparator := false // for/select header
+	skipUntil := ""             // skip words until this word ("in" for case, "]]" for [[)
+	skipFunctionName := false
+	casePattern := false
+	caseDepth := 0
+	piped := false
+
+	finish := func() {
+		if len(words) > 0 {
+			commands = append(commands, p.expand(shellCommand{Words: words, Piped: piped, Offset: offset})...)
+		}
+		words = nil
+		piped = false
+	}
+
+	for {
+		tok := p.next()
+		// Substitutions run before the command containing them
+		for _, sub := range p.subs {
+			commands = append(commands, p.nested(sub, p.lineOf(tok.start))...)
+		}
+		p.subs = nil
+
+		switch tok.kind {
+		case shellEOF:
+			finish()
+			return commands
+
+		case shellRedirect:
+			target := p.next()
+			for _, sub := range p.subs {
+				commands = append(commands, p.nested(sub, p.lineOf(target.start))...)
+			}
+			p.subs = nil
+			if (tok.text == "<<" || tok.text == "<<-") && target.kind == shellWord {
+				p.heredocs = append(p.heredocs, shellHeredoc{delimiter: target.text, stripTabs: tok.text == "<<-"})
+			}
+
+		case shellOperator:
+			switch tok.text {
+			case "(":
+				// name() { ...; } defines a function; the name is not a command
+				if len(words) == 1 {
+					words = nil
+					continue
+				}
+				finish()
+			case ")":
+				finish()
+				if casePattern {
+					casePattern = false
+				}
+			case ";;", ";&", ";;&":
+				finish()
+				if caseDepth > 0 {
+					casePattern = true
+				}
+			case "|", "|&":
+				finish()
+				piped = true
+			default:
+				finish()
+			}
+			skipUntilSeparator = false
+			skipFunctionName = false
+
+		case shellWord:
+			switch {
+			case casePattern:
+				if tok.text == "esac" && !tok.quoted {
+					casePattern = false
+					caseDepth--
+				}
+				continue
+			case skipUntil != "":
+				if tok.text == skipUntil && !tok.quoted {
+					if skipUntil == "in" {
+						casePattern = true
+						caseDepth++
+					}
+					skipUntil = ""
+				}
+				continue
+			case skipUntilSeparator:
+				continue
+			case skipFunctionName:
+				skipFunctionName = false
+				continue
+			}
+
+			if len(words) == 0 && !tok.quoted {
+				switch {
+				case shellReservedWords[tok.text]:
+					if tok.text == "esac" && caseDepth > 0 {
+						caseDepth--
+					}
+					continue
+				case tok.text == "for" || tok.text == "select":
+					skipUntilSeparator = true
+					continue
+				case tok.text == "case":
+					skipUntil = "in"
+					continue
+				case tok.text == "[[":
+					skipUntil = "]]"
+					continue
+				case tok.text == "function":
+					skipFunctionName = true
+					continue
+				case strings.HasPrefix(tok.text, "(("):
+					// Arithmetic command: ((i++))
+					continue
+				}
+			}
+			// Assignments before the command name are remembered so that
+			// commands held in variables can be expanded
+			if len(words) == 0 && tok.isAssignment() {
+				name, value, _ := strings.Cut(tok.text, "=")
+				p.vars[strings.TrimSuffix(name, "+")] = value
+				continue
+			}
+			if len(words) == 0 {
+				offset = p.lineOf(tok.start)
+			}
+			words = append(words, tok.text)
+		}
+	}
+}
+
+// expand returns the commands run by c: c itself, plus the script of sh -c,
+// or the command held by a variable when c runs $VAR
+func (p *shellParser) expand(c shellCommand) []shellCommand {
+	if m := variableReferencePattern.FindStringSubmatch(c.Words[0]); m != nil {
+		name := m[1] + m[2]
+		if value, ok := p.vars[name]; ok {
+			commands := p.nested(strings.Join(append([]string{value}, c.Words[1:]...), " "), c.Offset)
+			if len(commands) > 0 {
+				commands[0].Piped = c.Piped
+			}
+			return commands
+		}
+	}
+
+	commands := []shellCommand{c}
+	i := c.commandIndex()
+	if i < 0 || !shellInterpreters[normalizeCommand(c.Words[i])] {
+		return commands
+	}
+	for k := i + 1; k < len(c.Words)-1; k++ {
+		word := c.Words[k]
+		if !strings.HasPrefix(word, "-") {
+			break
+		}
+		if !strings.HasPrefix(word, "--") && strings.Contains(word, "c") {
+			commands = append(commands, p.nested(c.Words[k+1], c.Offset)...)
+			break
+		}
+	}
+	return commands
+}
+
+// nested parses a script embedded in the current one at line offset
+func (p *shellParser) nested(script string, offset int) []shellCommand {
+	if p.depth >= maxShellNesting {
+		return nil
+	}
+	child := newShellParser(script, p.depth+1)
+	for name, value := range p.vars {
+		child.vars[name] = value
+	}
+	commands := child.parse()
+	for i := range commands {
+		commands[i].Offset = offset
+	}
+	return commands
+}
+
+// lineOf returns the line of the script at offset pos, counted from 0.
+// Offsets must not decrease between calls.
+func (p *shellParser) lineOf(pos int) int {
+	for ; p.lineAt < pos && p.lineAt < len(p.src); p.lineAt++ {
+		if p.src[p.lineAt] == '\n' {
+			p.line++
+		}
+	}
+	return p.line
+}
+
+// next returns the next token
+func (p *shellParser) next() shellToken {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\\' && p.peek(1) == '\n':
+			p.pos += 2
+		case c == '#':
+			// Comments run to the end of the line
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			start := p.pos
+			tok := p.token()
+			tok.start = start
+			return tok
+		}
+	}
+	return shellToken{kind: shellEOF, start: p.pos}
+}
+
+// token reads a token starting at a non-blank character
+func (p *shellParser) token() shellToken {
+	c := p.src[p.pos]
+
+	// Process substitution <(...) and >(...) is a word
+	if (c == '<' || c == '>') && p.peek(1) == '(' {
+		p.pos++
+		p.subs = append(p.subs, p.readBalanced('(', ')'))
+		return shellToken{kind: shellWord, text: string(c) + "(...)", quoteAt: -1}
+	}
+
+	// Arithmetic command ((...)) is a single word
+	if p.hasPrefix("((") {
+		return shellToken{kind: shellWord, text: "((" + p.readBalanced('(', ')') + ")", quoteAt: -1}
+	}
+
+	for _, op := range shellOperators {
+		if p.hasPrefix(op) {
+			p.pos += len([]rune(op))
+			if op == "\n" {
+				p.skipHeredocs()
+			}
+			if strings.ContainsAny(op, "<>") {
+				return shellToken{kind: shellRedirect, text: op}
+			}
+			return shellToken{kind: shellOperator, text: op}
+		}
+	}
+
+	word, quoteAt := p.readWord()
+	// File descriptor numbers belong to the redirection (2>&1, 2>/dev/null)
+	if quoteAt < 0 && isDigits(word) && p.pos < len(p.src) && (p.src[p.pos] == '<' || p.src[p.pos] == '>') {
+		return p.token()
+	}
+	return shellToken{kind: shellWord, text: word, quoted: quoteAt >= 0, quoteAt: quoteAt}
+}
+
+// readWord reads a word, removing quotes and recording substitutions.
+// It returns the word and the offset where quoting started (-1 if unquoted).
+func (p *shellParser) readWord() (string, int) {
+	var b strings.Builder
+	quoteAt := -1
+	quote := func() {
+		if quoteAt < 0 {
+			quoteAt = b.Len()
+		}
+	}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\r', '\n', ';', '&', '|', '<', '>', ')':
+			return b.String(), quoteAt
+		case '(':
+			// Array assignment: arr=(a b c)
+			if assignmentPattern.MatchString(b.String()) && strings.HasSuffix(b.String(), "=") {
+				b.WriteString("(" + p.readBalanced('(', ')') + ")")
+				continue
+			}
+			return b.String(), quoteAt
+		case '\\':
+			quote()
+			if p.peek(1) == '\n' {
+				p.pos += 2
+				continue
+			}
+			if p.pos+1 < len(p.src) {
+				b.WriteRune(p.src[p.pos+1])
+			}
+			p.pos += 2
+		case '\'':
+			quote()
+			p.pos++
+			for p.pos < len(p.src) && p.src[p.pos] != '\'' {
+				b.WriteRune(p.src[p.pos])
+				p.pos++
+			}
+			p.pos++
+		case '"':
+			quote()
+			p.pos++
+			p.readDoubleQuoted(&b)
+		case '`':
+			p.pos++
+			p.subs = append(p.subs, p.readBackquoted())
+			b.WriteString("`...`")
+		case '$':
+			p.readDollar(&b)
+		default:
+			b.WriteRune(c)
+			p.pos++
+		}
+	}
+	return b.String(), quoteAt
+}
+
+// readDoubleQuoted reads the rest of a double-quoted string after the opening quote
+func (p *shellParser) readDoubleQuoted(b *strings.Builder) {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return
+		case '\\':
+			next := p.peek(1)
+			switch next {
+			case '"', '\\', '$', '`':
+				b.WriteRune(next)
+				p.pos += 2
+			case '\n':
+				p.pos += 2
+			default:
+				b.WriteRune(c)
+				p.pos++
+			}
+		case '`':
+			p.pos++
+			p.subs = append(p.subs, p.readBackquoted())
+			b.WriteString("`...`")
+		case '$':
+			p.readDollar(b)
+		default:
+			b.WriteRune(c)
+			p.pos++
+		}
+	}
+}
+
+// readDollar reads an expansion starting with $. Command substitutions are
+// recorded in p.subs; parameter and arithmetic expansions are kept as written.
+func (p *shellParser) readDollar(b *strings.Builder) {
+	switch p.peek(1) {
+	case '(':
+		p.pos++
+		if p.peek(1) == '(' {
+			b.WriteString("$(" + p.readBalanced('(', ')') + ")")
+			return
+		}
+		p.subs = append(p.subs, p.readBalanced('(', ')'))
+		b.WriteString("$(...)")
+	case '{':
+		p.pos++
+		b.WriteString("${" + p.readBalanced('{', '}') + "}")
+	default:
+		b.WriteRune('$')
+		p.pos++
+	}
+}
+
+// readBalanced reads from an opening delimiter at p.pos to its matching closing
+// delimiter, skipping quoted text, and returns the text in between
+func (p *shellParser) readBalanced(open, close rune) string {
+	p.pos++ // opening delimiter
+	start := p.pos
+	depth := 1
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '\\':
+			p.pos += 2
+			continue
+		case '\'':
+			if open == '(' {
+				p.pos++
+				for p.pos < len(p.src) && p.src[p.pos] != '\'' {
+					p.pos++
+				}
+			}
+		case '"':
+			p.pos++
+			for p.pos < len(p.src) && p.src[p.pos] != '"' {
+				if p.src[p.pos] == '\\' {
+					p.pos++
+				}
+				p.pos++
+			}
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				text := string(p.src[start:p.pos])
+				p.pos++
+				return text
+			}
+		}
+		p.pos++
+	}
+	p.pos = len(p.src)
+	return string(p.src[start:])
+}
+
+// readBackquoted reads a `...` command substitution after the opening backquote
+func (p *shellParser) readBackquoted() string {
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '`' {
+			p.pos++
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.src) {
+			next := p.src[p.pos+1]
+			if next == '`' || next == '\\' || next == '$' {
+				b.WriteRune(next)
+				p.pos += 2
+				continue
+			}
+		}
+		b.WriteRune(c)
+		p.pos++
+	}
+	return b.String()
+}
+
+// skipHeredocs skips the bodies of pending heredocs after a newline.
+// Heredoc bodies are input data, not commands.
+func (p *shellParser) skipHeredocs() {
+	for _, heredoc := range p.heredocs {
+		for p.pos < len(p.src) {
+			end := p.pos
+			for end < len(p.src) && p.src[end] != '\n' {
+				end++
+			}
+			line := string(p.src[p.pos:end])
+			p.pos = min(end+1, len(p.src))
+			if heredoc.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if strings.TrimRight(line, "\r") == heredoc.delimiter {
+				break
+			}
+		}
+	}
+	p.heredocs = nil
+}
+
+func (p *shellParser) peek(offset int) rune {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+func (p *shellParser) hasPrefix(s string) bool {
+	i := p.pos
+	for _, r := range s {
+		if i >= len(p.src) || p.src[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
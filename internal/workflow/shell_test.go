@@ -0,0 +1,252 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseShellCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "pipeline and lists",
+			script: "lsof -i :8080 | grep LISTEN && echo ok || exit 1; true",
+			want:   []string{"lsof", "grep", "echo", "exit", "true"},
+		},
+		{
+			name:   "comments",
+			script: "# docker build\necho hi # tree",
+			want:   []string{"echo"},
+		},
+		{
+			name:   "quoted arguments",
+			script: `echo "lsof | docker build" 'tree; nvm'`,
+			want:   []string{"echo"},
+		},
+		{
+			name:   "heredoc body",
+			script: "cat <<EOF > out.txt\ndocker build .\nlsof\nEOF\ntree",
+			want:   []string{"cat", "tree"},
+		},
+		{
+			name:   "indented heredoc with quoted delimiter",
+			script: "cat <<-'END'\n\tnvm install\n\tEND\necho done",
+			want:   []string{"cat", "echo"},
+		},
+		{
+			name:   "subshell and brace group",
+			script: "(cd web && npm ci)\n{ lsof; tree; } > log",
+			want:   []string{"cd", "npm", "lsof", "tree"},
+		},
+		{
+			name:   "function definition",
+			script: "build() {\n  make all\n}\nfunction deploy {\n  helm upgrade app\n}\nbuild",
+			want:   []string{"make", "helm", "build"},
+		},
+		{
+			name:   "loops and conditionals",
+			script: "for f in a b; do\n  tree $f\ndone\nwhile true; do sleep 1; done\nif [ -f x ]; then lsof; elif [[ -d y ]]; then nvm use; else echo no; fi",
+			want:   []string{"tree", "true", "sleep", "[", "lsof", "nvm", "echo"},
+		},
+		{
+			name:   "case statement",
+			script: "case \"$OS\" in\n  linux|darwin) lsof ;;\n  *) tree ;;\nesac\necho done",
+			want:   []string{"lsof", "tree", "echo"},
+		},
+		{
+			name:   "command substitution",
+			script: "VERSION=$(git describe --tags)\necho \"`date`\" $(lsof -t -i :80)",
+			want:   []string{"git", "date", "lsof", "echo"},
+		},
+		{
+			name:   "prefixes, assignments and redirections",
+			script: "FOO=1 sudo -u root env BAR=2 /usr/bin/lsof 2>&1 >/dev/null",
+			want:   []string{"/usr/bin/lsof"},
+		},
+		{
+			name:   "sh -c script",
+			script: `bash -ec "lsof -i && tree"`,
+			want:   []string{"bash", "lsof", "tree"},
+		},
+		{
+			name:   "command held in a variable",
+			script: "CMD='lsof -i'\n$CMD :8080",
+			want:   []string{"lsof"},
+		},
+		{
+			name:   "line continuation and arithmetic",
+			script: "tree \\\n  -L 2\n((i++))\nfor ((i=0; i<3; i++)); do echo $i; done",
+			want:   []string{"tree", "echo"},
+		},
+		{
+			name:   "unterminated quote",
+			script: `echo "unterminated`,
+			want:   []string{"echo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cmd := range parseShellCommands(tt.script) {
+				if i := cmd.commandIndex(); i >= 0 {
+					got = append(got, cmd.Words[i])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseShellCommands() commands = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptUsesContainerCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{name: "docker build", script: "docker build -t app .", want: true},
+		{name: "docker compose in subshell", script: "(cd deploy && docker compose up -d)", want: true},
+		{name: "docker-compose in function", script: "up() { docker-compose up; }\nup", want: true},
+		{name: "docker in command substitution", script: `ID=$(docker run -d nginx)`, want: true},
+		{name: "docker in loop", script: "for i in 1 2; do docker pull img:$i; done", want: true},
+		{name: "docker via sh -c", script: `sh -c 'docker push app'`, want: true},
+		{name: "docker by absolute path", script: "sudo /usr/bin/docker login", want: true},
+		{name: "docker in comment", script: "# docker build .\necho hi", want: false},
+		{name: "docker in double-quoted string", script: `echo "run docker build later"`, want: false},
+		{name: "docker as an argument", script: "echo docker build", want: false},
+		{name: "docker in heredoc", script: "cat <<EOF\ndocker build .\nEOF", want: false},
+		{name: "docker version check", script: "docker --version", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScriptUsesContainerCommands(tt.script); got != tt.want {
+				t.Errorf("ScriptUsesContainerCommands(%q) = %v, want %v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepShell(t *testing.T) {
+	for shell, want := range map[string]bool{
+		"":                  true,
+		"bash":              true,
+		"sh -e {0}":         true,
+		"/usr/bin/bash {0}": true,
+		"pwsh":              false,
+		"powershell":        false,
+		"python":            false,
+		"cmd":               false,
+		"perl {0}":          false,
+	} {
+		if got := isPOSIXShell(shell); got != want {
+			t.Errorf("isPOSIXShell(%q) = %v, want %v", shell, got, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+defaults:
+  run:
+    shell: pwsh
+jobs:
+  windows-style:
+    runs-on: ubuntu-latest
+    steps:
+      - run: Get-ChildItem | Select-Object Name
+      - run: lsof -i :8080
+        shell: bash
+  python:
+    runs-on: ubuntu-latest
+    defaults:
+      run:
+        shell: python
+    steps:
+      - run: |
+          import subprocess
+          print("lsof")
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error = %v", err)
+	}
+
+	if got := stepCommands(wf.Jobs["windows-style"].Steps[0]); len(got) != 0 {
+		t.Errorf("stepCommands() of a pwsh step = %v, want none", got)
+	}
+	if got := wf.Jobs["python"].Steps[0].Shell; got != "python" {
+		t.Errorf("python step shell = %q, want the job default python", got)
+	}
+	if got := wf.Jobs["windows-style"].GetMissingCommands(); !reflect.DeepEqual(got, []string{"lsof"}) {
+		t.Errorf("windows-style GetMissingCommands() = %v, want [lsof] from the bash step only", got)
+	}
+	if got := wf.Jobs["python"].GetMissingCommands(); len(got) != 0 {
+		t.Errorf("python GetMissingCommands() = %v, want none", got)
+	}
+}
+
+func TestParseShellCommandsOffset(t *testing.T) {
+	script := "echo start\n# comment\ndocker build \\\n  -t app .\ncat <<EOF\ndocker ps\nEOF\nx=$(podman ps) && lsof -i :80\n"
+	want := map[string]int{"echo": 0, "docker": 2, "cat": 4, "podman": 7, "lsof": 7}
+	for _, cmd := range parseShellCommands(script) {
+		if offset, ok := want[cmd.Name()]; !ok || cmd.Offset != offset {
+			t.Errorf("%s: Offset = %d, want %d", cmd.Line(), cmd.Offset, offset)
+		}
+	}
+}
+
+func TestEvidenceLineNumber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	content := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: docker/login-action@v3
+      - name: Build
+        run: |
+          echo start
+          docker build -t app .
+      - run: lsof -i :80
+      - run: >
+          podman ps
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error = %v", err)
+	}
+	job := wf.Jobs["build"]
+
+	lines := func(evidence []Evidence) map[string]int {
+		got := make(map[string]int)
+		for _, e := range evidence {
+			got[e.Line] = e.LineNumber
+		}
+		return got
+	}
+	// Folded scripts are located at their step
+	want := map[string]int{"docker build -t app .": 10, "podman ps": 12}
+	if got := lines(job.DockerCommandEvidence()); !reflect.DeepEqual(got, want) {
+		t.Errorf("DockerCommandEvidence() lines = %v, want %v", got, want)
+	}
+	want = map[string]int{"docker/login-action@v3": 6}
+	if got := lines(job.ContainerActionEvidence()); !reflect.DeepEqual(got, want) {
+		t.Errorf("ContainerActionEvidence() lines = %v, want %v", got, want)
+	}
+	if got := lines(job.MissingCommandEvidence()); got["lsof -i :80"] != 11 {
+		t.Errorf("MissingCommandEvidence() lines = %v, want lsof on line 11", got)
+	}
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Source reads the repository files workflows are loaded from (workflows,
+// local actions, scripts, manifests) from somewhere other than the working
+// tree, e.g., a git ref. Paths are relative to the current directory, like
+// the paths of the working tree.
+type Source interface {
+	// ReadFile returns the content of the file at path
+	ReadFile(path string) ([]byte, error)
+	// Files returns the files under dir and its subdirectories
+	Files(dir string) ([]string, error)
+}
+
+// source is the Source set by SetSource, or nil to read the working tree
+var source Source
+
+// SetSource makes workflows and the files they use be read from s instead of
+// the working tree. A nil s restores the working tree. Functions modifying
+// workflows (e.g., UpdateRunsOnJobs) always edit the working tree.
+func SetSource(s Source) {
+	source = s
+}
+
+// readRepoFile reads a file of the repository from the working tree or the
+// Source set by SetSource
+func readRepoFile(path string) ([]byte, error) {
+	if source != nil {
+		return source.ReadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
+// isRepoFile reports whether path is a regular file of the repository
+func isRepoFile(path string) bool {
+	if source != nil {
+		_, err := source.ReadFile(path)
+		return err == nil
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// repoFiles returns the files under dir and its subdirectories, from the
+// working tree or the Source set by SetSource. It returns an error wrapping
+// fs.ErrNotExist if dir does not exist in the working tree.
+func repoFiles(dir string) ([]string, error) {
+	if source != nil {
+		return source.Files(dir)
+	}
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// mapSource is a Source of files held in memory
+type mapSource map[string]string
+
+func (s mapSource) ReadFile(path string) ([]byte, error) {
+	data, ok := s[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+	return []byte(data), nil
+}
+
+func (s mapSource) Files(dir string) ([]string, error) {
+	var files []string
+	for p := range s {
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func TestSetSource(t *testing.T) {
+	// The working tree (the test directory) has no workflows
+	t.Chdir(t.TempDir())
+	SetSource(mapSource{
+		".github/workflows/ci.yml": `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/setup
+      - run: ./scripts/build.sh
+`,
+		".github/workflows/README.md":           "Not a workflow",
+		".github/actions/setup/action.yml":      "runs:\n  using: docker\n  image: Dockerfile\n",
+		"scripts/build.sh":                      "#!/bin/bash\nlsof -i\n",
+		".github/workflows/nightly/nightly.yml": "on: schedule\njobs: {}\n",
+	})
+	defer SetSource(nil)
+
+	workflows, err := LoadWorkflows()
+	if err != nil {
+		t.Fatalf("LoadWorkflows() error: %v", err)
+	}
+	var paths []string
+	for _, wf := range workflows {
+		paths = append(paths, wf.Path)
+	}
+	if want := []string{".github/workflows/ci.yml", ".github/workflows/nightly/nightly.yml"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("LoadWorkflows() = %v, want %v", paths, want)
+	}
+
+	expanded, err := ExpandPaths([]string{".github/workflows/nightly", ".github/**/ci.yml"})
+	if err != nil {
+		t.Fatalf("ExpandPaths() error: %v", err)
+	}
+	if want := []string{".github/workflows/nightly/nightly.yml", ".github/workflows/ci.yml"}; !reflect.DeepEqual(expanded, want) {
+		t.Errorf("ExpandPaths() = %v, want %v", expanded, want)
+	}
+
+	// Local actions and scripts are read from the source too
+	job := workflows[0].Jobs["build"]
+	if got := job.dockerLocalActions(); len(got) != 1 {
+		t.Errorf("dockerLocalActions() = %v, want the local Docker action", got)
+	}
+	if got := job.MissingCommandEvidence(); len(got) != 1 || got[0].Pattern != "lsof" {
+		t.Errorf("MissingCommandEvidence() = %v, want lsof used by scripts/build.sh", got)
+	}
+
+	SetSource(mapSource{})
+	if _, err := LoadWorkflows(); err == nil {
+		t.Error("LoadWorkflows() expected an error when the source has no workflow directory")
+	}
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdinPath is the workflow path read from standard input (e.g., gh slimify -),
+// so editors can analyze unsaved buffers
+const StdinPath = "-"
+
+var (
+	stdin     io.Reader = os.Stdin
+	stdinMu   sync.Mutex
+	stdinData []byte
+	stdinErr  error
+	stdinRead bool
+)
+
+// readStdin returns the workflow read from standard input. It is read once,
+// so that the workflow can be loaded again (e.g., to follow reusable workflows).
+func readStdin() ([]byte, error) {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+	if !stdinRead {
+		stdinData, stdinErr = io.ReadAll(stdin)
+		stdinRead = true
+	}
+	if stdinErr != nil {
+		return nil, fmt.Errorf("failed to read standard input: %w", stdinErr)
+	}
+	return stdinData, nil
+}
+
+// readWorkflowFile returns the content of the workflow at path, or of standard
+// input if path is StdinPath
+func readWorkflowFile(path string) ([]byte, error) {
+	if path == StdinPath {
+		return readStdin()
+	}
+	data, err := readRepoFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return data, nil
+}
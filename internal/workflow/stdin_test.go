@@ -0,0 +1,35 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadWorkflow_Stdin(t *testing.T) {
+	original := stdin
+	stdin = strings.NewReader(`on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+`)
+	defer func() {
+		stdin, stdinData, stdinErr, stdinRead = original, nil, nil, false
+	}()
+
+	// Standard input is read once, and the workflow can be loaded again
+	for i := 0; i < 2; i++ {
+		wf, err := LoadWorkflow(StdinPath)
+		if err != nil {
+			t.Fatalf("LoadWorkflow(%q) error: %v", StdinPath, err)
+		}
+		if wf.Path != StdinPath {
+			t.Errorf("Path = %q, want %q", wf.Path, StdinPath)
+		}
+		job := wf.Jobs["lint"]
+		if job == nil || job.LineStart != 4 {
+			t.Fatalf("Jobs[lint] = %+v, want runs-on at line 4", job)
+		}
+	}
+}
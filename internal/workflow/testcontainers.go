@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// testEcosystem describes how to tell that a test runner runs tests of a
+// project depending on Testcontainers
+type testEcosystem struct {
+	// runners matches test commands from the command name (e.g., "go test ./...")
+	runners *regexp.Regexp
+	// manifests are the dependency files of the project (e.g., go.mod)
+	manifests []string
+	// dependency is the text revealing a Testcontainers dependency in a manifest
+	dependency string
+}
+
+var testEcosystems = []testEcosystem{
+	{
+		runners:    regexp.MustCompile(`^(?:go\s+test|gotestsum|ginkgo)\b`),
+		manifests:  []string{"go.mod"},
+		dependency: "github.com/testcontainers/testcontainers-go",
+	},
+	{
+		runners:    regexp.MustCompile(`^(?:(?:npm|pnpm|yarn|bun)\s+(?:run\s+)?test|npx\s+(?:jest|vitest|mocha)|jest|vitest|mocha)\b`),
+		manifests:  []string{"package.json"},
+		dependency: "testcontainers",
+	},
+	{
+		runners:    regexp.MustCompile(`^(?:pytest|tox|nox|python3?\s+-m\s+(?:pytest|unittest)|(?:poetry|uv|pdm|hatch)\s+run\s+(?:pytest|test))\b`),
+		manifests:  []string{"pyproject.toml", "requirements.txt", "requirements-dev.txt", "requirements-test.txt", "setup.py", "setup.cfg", "Pipfile"},
+		dependency: "testcontainers",
+	},
+	{
+		runners:    regexp.MustCompile(`^(?:mvn|mvnw|gradle|gradlew)\b.*\b(?:test|verify|check|build|install)\b`),
+		manifests:  []string{"pom.xml", "build.gradle", "build.gradle.kts"},
+		dependency: "org.testcontainers",
+	},
+}
+
+// testcontainersEnvPrefix is the prefix of environment variables configuring Testcontainers
+// (e.g., TESTCONTAINERS_RYUK_DISABLED)
+const testcontainersEnvPrefix = "TESTCONTAINERS_"
+
+// TestcontainersEvidence returns evidence that the job runs tests using
+// Testcontainers, which start containers through a Docker daemon. This is a
+// heuristic: a test command (go test, npm test, pytest, mvn verify, ...) must
+// run in a project whose manifest (go.mod, package.json, requirements.txt,
+// pom.xml, ...) depends on Testcontainers, or in a job with TESTCONTAINERS_*
+// environment variables. Manifests are looked up from the step's working
+// directory up to the repository root. workflowEnv is the workflow-level env.
+// Returns nil if the job runs no such tests.
+func (j *Job) TestcontainersEvidence(workflowEnv map[string]interface{}) []Evidence {
+	envVars := testcontainersEnv(workflowEnv)
+	envVars = append(envVars, testcontainersEnv(j.Env)...)
+
+	var evidence []Evidence
+	manifests := make(map[string]bool)
+	for _, step := range j.expandedSteps() {
+		stepEnv := append(append([]string(nil), envVars...), testcontainersEnv(step.Env)...)
+		for _, cmd := range stepCommands(step) {
+			line := cmd.Line()
+			for _, eco := range testEcosystems {
+				if !eco.runners.MatchString(line) {
+					continue
+				}
+				if manifest := testcontainersManifest(eco, step.WorkingDirectory, manifests); manifest != "" {
+					evidence = append(evidence, Evidence{Step: stepLabel(step), Source: manifest, Line: line, Pattern: eco.dependency, LineNumber: commandLineNumber(step, cmd)})
+				} else if len(stepEnv) > 0 {
+					evidence = append(evidence, Evidence{Step: stepLabel(step), Source: cmd.Source, Line: line, Pattern: stepEnv[0], LineNumber: commandLineNumber(step, cmd)})
+				}
+				break
+			}
+		}
+	}
+	return evidence
+}
+
+// testcontainersEnv returns the names of TESTCONTAINERS_* variables in env, sorted
+func testcontainersEnv(env map[string]interface{}) []string {
+	var names []string
+	for name := range env {
+		if strings.HasPrefix(strings.ToUpper(name), testcontainersEnvPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// testcontainersManifest returns the path of a manifest of the project
+// containing dir if it depends on Testcontainers. The project is the nearest
+// directory with a manifest of the ecosystem, up to the repository root.
+// cache holds the manifests already read, mapped to whether they depend on
+// Testcontainers; manifests that do not exist are not in the cache.
+func testcontainersManifest(eco testEcosystem, dir string, cache map[string]bool) string {
+	dir = filepath.Clean(dir)
+	if !filepath.IsLocal(dir) {
+		dir = "."
+	}
+	for {
+		found := false
+		for _, name := range eco.manifests {
+			path := filepath.Join(dir, name)
+			depends, ok := cache[path]
+			if !ok {
+				data, err := readRepoFile(path)
+				if err != nil {
+					continue
+				}
+				depends = strings.Contains(string(data), eco.dependency)
+				cache[path] = depends
+			}
+			if depends {
+				return filepath.ToSlash(path)
+			}
+			found = true
+		}
+		if found || dir == "." {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
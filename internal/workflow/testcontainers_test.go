@@ -0,0 +1,78 @@
+package workflow
+
+import "testing"
+
+func TestJob_TestcontainersEvidence(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeRepoFiles(t, map[string]string{
+		"go.mod":               "module example.com/app\n\nrequire github.com/testcontainers/testcontainers-go v0.33.0\n",
+		"tools/go.mod":         "module example.com/tools\n",
+		"web/package.json":     `{"devDependencies": {"@testcontainers/postgresql": "^10.0.0"}}`,
+		"api/requirements.txt": "pytest\n",
+		"scripts/test.sh":      "go test -race ./...\n",
+	})
+
+	tests := []struct {
+		name        string
+		job         *Job
+		workflowEnv map[string]interface{}
+		wantSource  string // Source of the first evidence, or "" for none
+		wantPattern string
+	}{
+		{
+			name:        "go test in a module depending on testcontainers-go",
+			job:         &Job{Steps: []Step{{Run: "go test ./..."}}},
+			wantSource:  "go.mod",
+			wantPattern: "github.com/testcontainers/testcontainers-go",
+		},
+		{
+			name:        "repository script running go test",
+			job:         &Job{Steps: []Step{{Run: "./scripts/test.sh"}}},
+			wantSource:  "go.mod",
+			wantPattern: "github.com/testcontainers/testcontainers-go",
+		},
+		{
+			name: "nested module without testcontainers",
+			job:  &Job{Steps: []Step{{Run: "go test ./...", WorkingDirectory: "tools"}}},
+		},
+		{
+			name:        "npm test in a package depending on @testcontainers",
+			job:         &Job{Steps: []Step{{Run: "npm ci\nnpm run test:integration", WorkingDirectory: "web"}}},
+			wantSource:  "web/package.json",
+			wantPattern: "testcontainers",
+		},
+		{
+			name: "pytest without testcontainers",
+			job:  &Job{Steps: []Step{{Run: "pytest -q", WorkingDirectory: "api"}}},
+		},
+		{
+			name:        "pytest with TESTCONTAINERS env in the workflow",
+			job:         &Job{Steps: []Step{{Run: "pytest -q", WorkingDirectory: "api"}}},
+			workflowEnv: map[string]interface{}{"TESTCONTAINERS_RYUK_DISABLED": "true"},
+			wantPattern: "TESTCONTAINERS_RYUK_DISABLED",
+		},
+		{
+			name:        "TESTCONTAINERS env without tests",
+			job:         &Job{Steps: []Step{{Run: "go build ./..."}}},
+			workflowEnv: map[string]interface{}{"TESTCONTAINERS_RYUK_DISABLED": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evidence := tt.job.TestcontainersEvidence(tt.workflowEnv)
+			if tt.wantPattern == "" {
+				if len(evidence) != 0 {
+					t.Errorf("TestcontainersEvidence() = %+v, want none", evidence)
+				}
+				return
+			}
+			if len(evidence) == 0 {
+				t.Fatalf("TestcontainersEvidence() = none, want %s", tt.wantPattern)
+			}
+			if evidence[0].Source != tt.wantSource || evidence[0].Pattern != tt.wantPattern {
+				t.Errorf("TestcontainersEvidence()[0] = %+v, want source %q and pattern %q", evidence[0], tt.wantSource, tt.wantPattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// toolchain is a toolchain preinstalled on both ubuntu-latest and
+// ubuntu-slim, whose versions may differ
+type toolchain struct {
+	parts  int    // Leading version components that must match (node 20 and 22 differ, python3 3.12.3 and 3.12.8 do not)
+	action string // Setup action pinning the version
+	input  string // Input of the action selecting the version
+}
+
+// versionedToolchains maps the commands of versioned toolchains to them
+var versionedToolchains = map[string]toolchain{
+	"node":    {parts: 1, action: "actions/setup-node@v4", input: "node-version"},
+	"python3": {parts: 2, action: "actions/setup-python@v5", input: "python-version"},
+}
+
+// ToolchainVersionEvidence returns the first use of each preinstalled
+// toolchain (e.g., node, python3) whose version differs between ubuntu-latest
+// and ubuntu-slim, unless a setup action (e.g., actions/setup-node) or an
+// earlier step installs it. Pattern holds the command name. Versions come
+// from the command dataset, and toolchains without a version on both images
+// are not reported.
+func (j *Job) ToolchainVersionEvidence() []Evidence {
+	if !j.IsUbuntuLatest() {
+		return nil
+	}
+	return j.uninstalledCommandEvidence(byName(ToolchainVersionDiffers))
+}
+
+// ToolchainVersionDiffers reports whether cmd is a toolchain preinstalled on
+// both images with different versions in the command dataset
+func ToolchainVersionDiffers(cmd string) bool {
+	tc, ok := versionedToolchains[cmd]
+	if !ok {
+		return false
+	}
+	info := LookupCommand(cmd)
+	if !info.Latest || !info.Slim || info.LatestVersion == "" || info.SlimVersion == "" {
+		return false
+	}
+	return versionPrefix(info.LatestVersion, tc.parts) != versionPrefix(info.SlimVersion, tc.parts)
+}
+
+// ToolchainPin describes the setup action step pinning a toolchain to its
+// version on ubuntu-latest (e.g., "actions/setup-node@v4 with node-version:
+// 20"), or returns "" if cmd is not a versioned toolchain
+func ToolchainPin(cmd string) string {
+	tc, ok := versionedToolchains[cmd]
+	if !ok {
+		return ""
+	}
+	version := LookupCommand(cmd).LatestVersion
+	if version == "" {
+		return tc.action
+	}
+	return fmt.Sprintf("%s with %s: %s", tc.action, tc.input, versionPrefix(version, tc.parts))
+}
+
+// versionPrefix returns the first parts components of the default version of
+// a tool (the first one of a list such as "20.19.5, 22.20.0")
+func versionPrefix(version string, parts int) string {
+	version, _, _ = strings.Cut(version, ",")
+	components := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(components) > parts {
+		components = components[:parts]
+	}
+	return strings.Join(components, ".")
+}
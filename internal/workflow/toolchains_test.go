@@ -0,0 +1,55 @@
+package workflow
+
+import "testing"
+
+func TestJob_ToolchainVersionEvidence(t *testing.T) {
+	defer useCommandsDB(Commands())
+	db := Commands()
+	db.Images[ImageLatest].Tools = map[string]string{"node": "20.19.5", "python3": "3.12.3"}
+	db.Images[ImageSlim].Tools = map[string]string{"node": "22.11.0", "python3": "3.12.8"}
+	useCommandsDB(db)
+
+	tests := []struct {
+		name string
+		job  *Job
+		want []string
+	}{
+		{
+			name: "preinstalled node",
+			job:  &Job{RunsOn: "ubuntu-latest", Steps: []Step{{Run: "node app.js"}, {Run: "python3 -m pytest"}}},
+			want: []string{"node"},
+		},
+		{
+			name: "node pinned with setup-node",
+			job:  &Job{RunsOn: "ubuntu-latest", Steps: []Step{{Uses: "actions/setup-node@v4"}, {Run: "node app.js"}}},
+		},
+		{
+			name: "non-ubuntu-latest runner",
+			job:  &Job{RunsOn: "ubuntu-22.04", Steps: []Step{{Run: "node app.js"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range tt.job.ToolchainVersionEvidence() {
+				got = append(got, e.Pattern)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("ToolchainVersionEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := ToolchainPin("node"), "actions/setup-node@v4 with node-version: 20"; got != want {
+		t.Errorf("ToolchainPin(node) = %q, want %q", got, want)
+	}
+	if got := ToolchainPin("jq"); got != "" {
+		t.Errorf("ToolchainPin(jq) = %q, want empty", got)
+	}
+
+	db.Images[ImageSlim].Tools = nil
+	useCommandsDB(db)
+	if ToolchainVersionDiffers("node") {
+		t.Error("ToolchainVersionDiffers(node) = true without a version on ubuntu-slim, want false")
+	}
+}
@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"sort"
+	"strings"
+)
+
+// SetupAction is a setup action used by a job and the commands it provides
+type SetupAction struct {
+	Step     string   `json:"step"` // Label of the step using the action
+	Uses     string   `json:"uses"`
+	Commands []string `json:"commands"`
+}
+
+// SetupActions returns the setup actions used by the job's steps (including
+// steps of local composite actions), in step order
+func (j *Job) SetupActions() []SetupAction {
+	// Match prefixes in a stable order
+	prefixes := make([]string, 0, len(setupActionCommands))
+	for prefix := range setupActionCommands {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var actions []SetupAction
+	for _, step := range j.expandedSteps() {
+		if step.Uses == "" {
+			continue
+		}
+
+		// Setup actions typically follow the pattern: actions/setup-<lang>@<version>
+		// We match the base action name without version
+		var commands []string
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(step.Uses, prefix) {
+				commands = append(commands, setupActionCommands[prefix]...)
+			}
+		}
+		if len(commands) > 0 {
+			actions = append(actions, SetupAction{Step: stepLabel(step), Uses: step.Uses, Commands: commands})
+		}
+	}
+	return actions
+}
+
+// Command is a command extracted from a job's run: scripts
+type Command struct {
+	Step   string `json:"step"`             // Label of the step running the command
+	Source string `json:"source,omitempty"` // Repository script or Makefile target the command comes from, if any
+	Name   string `json:"name"`             // Command name (e.g., "go")
+	Line   string `json:"line"`             // The command with its arguments
+	// LineNumber is the line of the workflow file the command is on (see
+	// Evidence.LineNumber), or 0 if unknown
+	LineNumber int `json:"line_number,omitempty"`
+}
+
+// Commands returns the commands run by the job's steps (including steps of
+// local composite actions, repository scripts, and Makefile targets), in order
+func (j *Job) Commands() []Command {
+	var commands []Command
+	for _, step := range j.expandedSteps() {
+		for _, cmd := range stepCommands(step) {
+			if cmd.Name() == "" {
+				continue
+			}
+			commands = append(commands, Command{
+				Step:       stepLabel(step),
+				Source:     cmd.Source,
+				Name:       cmd.Name(),
+				Line:       cmd.Line(),
+				LineNumber: commandLineNumber(step, cmd),
+			})
+		}
+	}
+	return commands
+}
@@ -0,0 +1,33 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJob_SetupActionsAndCommands(t *testing.T) {
+	job := &Job{
+		RunsOn: "ubuntu-latest",
+		Steps: []Step{
+			{Uses: "actions/checkout@v4"},
+			{Name: "Set up Go", Uses: "actions/setup-go@v5"},
+			{Name: "Test", Run: "go test ./... | tee out.txt\n# comment\necho done"},
+		},
+	}
+
+	actions := job.SetupActions()
+	if len(actions) != 1 || actions[0].Step != "Set up Go" || !strings.Contains(strings.Join(actions[0].Commands, ","), "go") {
+		t.Errorf("SetupActions() = %+v, want actions/setup-go providing go", actions)
+	}
+
+	var got []string
+	for _, c := range job.Commands() {
+		if c.Step != "Test" {
+			t.Errorf("command %q has step %q, want %q", c.Line, c.Step, "Test")
+		}
+		got = append(got, c.Name)
+	}
+	if strings.Join(got, ",") != "go,tee,echo" {
+		t.Errorf("Commands() names = %v, want [go tee echo]", got)
+	}
+}
@@ -1,18 +1,55 @@
 package workflow
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/fchimpan/gh-slimify/internal/readonly"
 	"gopkg.in/yaml.v3"
 )
 
 // Workflow represents a GitHub Actions workflow file
 type Workflow struct {
-	Path string
-	Jobs map[string]*Job
+	Path        string
+	Jobs        map[string]*Job
+	Permissions interface{}            // Workflow-level permissions, used by jobs that do not set their own
+	Env         map[string]interface{} // Workflow-level env, inherited by every job
+	// DispatchInputs holds the inputs of the workflow_dispatch trigger, keyed by name
+	DispatchInputs map[string]*DispatchInput
+	// CallInputs holds the inputs of the workflow_call trigger, keyed by name
+	CallInputs map[string]*DispatchInput
+	// Variables holds the values of the configuration variables runs-on is set
+	// to, keyed by name, once they are looked up (see scan.Options.ResolveVariables)
+	Variables map[string]string
+}
+
+// JobIDs returns the IDs of the jobs in the order they appear in the file
+func (w *Workflow) JobIDs() []string {
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	// Jobs are ordered by their key: runs-on may come from an anchor
+	line := func(j *Job) int {
+		if j.Positions.Key.Line > 0 {
+			return j.Positions.Key.Line
+		}
+		return j.LineStart
+	}
+	sort.Slice(ids, func(i, k int) bool {
+		if line(w.Jobs[ids[i]]) != line(w.Jobs[ids[k]]) {
+			return line(w.Jobs[ids[i]]) < line(w.Jobs[ids[k]])
+		}
+		return ids[i] < ids[k]
+	})
+	return ids
 }
 
 // Job represents a job in a GitHub Actions workflow
@@ -23,7 +60,30 @@ type Job struct {
 	Steps     []Step      `yaml:"steps"`
 	Services  interface{} `yaml:"services"`
 	Container interface{} `yaml:"container"`
-	LineStart int         // Line number where the job starts
+	// Secrets is passed to reusable workflows (a map of secrets or "inherit")
+	Secrets     interface{}            `yaml:"secrets"`
+	Permissions interface{}            `yaml:"permissions"`
+	Env         map[string]interface{} `yaml:"env"`
+	// Uses references a reusable workflow (e.g., "./.github/workflows/build.yml"
+	// or "owner/repo/.github/workflows/build.yml@v1"). Such jobs have no runs-on.
+	Uses string `yaml:"uses"`
+	// ContinueOnError is true, false, or an expression
+	ContinueOnError interface{} `yaml:"continue-on-error"`
+	Defaults        Defaults    `yaml:"defaults"`
+	// Needs is the ID of a job, or a list of the IDs of the jobs, that must
+	// complete before this one
+	Needs interface{} `yaml:"needs"`
+	// LineStart is the line of the job's runs-on, or of its key if it has
+	// none (e.g., jobs calling reusable workflows)
+	LineStart int
+	// Positions locates the job's key and values in the workflow file
+	Positions JobPositions `yaml:"-"`
+	// Ignore is the "# slimify:ignore" directive on the job, or nil
+	Ignore *IgnoreDirective `yaml:"-"`
+	// LocalActions holds the local actions used by the job's steps, keyed by
+	// their cleaned directory (e.g., ".github/actions/setup"). Entries are nil
+	// for actions whose metadata could not be loaded.
+	LocalActions map[string]*LocalAction `yaml:"-"`
 }
 
 // Step represents a step in a job
@@ -32,46 +92,108 @@ type Step struct {
 	Uses string                 `yaml:"uses"`
 	Run  string                 `yaml:"run"`
 	With map[string]interface{} `yaml:"with"`
+	Env  map[string]interface{} `yaml:"env"`
+	// WorkingDirectory is where run: executes, relative to the repository root
+	WorkingDirectory string `yaml:"working-directory"`
+	// Shell runs run: (e.g., bash, pwsh, python, or a template such as
+	// "bash -e {0}"). LoadWorkflow applies the defaults.run.shell of the job
+	// and workflow to steps without one.
+	Shell string `yaml:"shell"`
+	// Pos is the position of the step in the workflow file, and RunLine the
+	// line its run: script starts on (zero if unknown, e.g., for steps of
+	// local actions or folded scripts)
+	Pos     Position `yaml:"-"`
+	RunLine int      `yaml:"-"`
+}
+
+// Position is a 1-based line and column in a workflow file. The zero value
+// means unknown.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// nodePosition returns the position of a node, or the zero Position for nil
+func nodePosition(n *yaml.Node) Position {
+	if n == nil {
+		return Position{}
+	}
+	return Position{Line: n.Line, Column: n.Column}
+}
+
+// JobPositions locates a job in a workflow file. Values defined through
+// anchors (runs-on: *runner, <<: *defaults) are located where the anchor is.
+type JobPositions struct {
+	Key       Position // The job ID
+	RunsOn    Position // The runs-on: key
+	Container Position // The container: key
+	Services  Position // The services: key
+}
+
+// Defaults is the defaults: of a job or workflow
+type Defaults struct {
+	Run struct {
+		Shell string `yaml:"shell"`
+	} `yaml:"run"`
+}
+
+// DefaultDir is the directory containing workflow files, relative to the repository root
+const DefaultDir = ".github/workflows"
+
+// dir is the directory LoadWorkflows scans, set by SetDir
+var dir = DefaultDir
+
+// SetDir makes LoadWorkflows scan d instead of DefaultDir (e.g.,
+// ".gitea/workflows", or the workflows of a project nested in a monorepo).
+// An empty d restores DefaultDir.
+func SetDir(d string) {
+	if d == "" {
+		d = DefaultDir
+	}
+	dir = filepath.Clean(d)
 }
 
-// LoadWorkflows loads all workflow files from .github/workflows directory
+// Dir returns the directory LoadWorkflows scans
+func Dir() string {
+	return dir
+}
+
+// LoadWorkflows loads all workflow files from the workflows directory
+// (.github/workflows unless changed with SetDir)
 func LoadWorkflows() ([]*Workflow, error) {
-	workflowDir := ".github/workflows"
+	workflowDir := dir
 
-	// Check if directory exists
-	if _, err := os.Stat(workflowDir); os.IsNotExist(err) {
+	files, err := repoFiles(workflowDir)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && len(files) == 0 && source != nil) {
 		return nil, fmt.Errorf("workflow directory not found: %s", workflowDir)
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	var workflows []*Workflow
-
-	err := filepath.Walk(workflowDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
+	for _, path := range files {
 		// Only process .yml and .yaml files
-		if !info.IsDir() && (strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")) {
-			wf, err := LoadWorkflow(path)
-			if err != nil {
-				// Log error but continue processing other files
-				fmt.Fprintf(os.Stderr, "Warning: failed to load %s: %v\n", path, err)
-				return nil
-			}
-			workflows = append(workflows, wf)
+		if !IsWorkflowFile(path) {
+			continue
 		}
-
-		return nil
-	})
-
-	return workflows, err
+		wf, err := LoadWorkflow(path)
+		if err != nil {
+			// Log error but continue processing other files
+			slog.Warn("failed to load workflow", "path", path, "error", err)
+			continue
+		}
+		workflows = append(workflows, wf)
+	}
+	return workflows, nil
 }
 
-// LoadWorkflow loads a single workflow file
+// LoadWorkflow loads a single workflow file, or the workflow given on
+// standard input if path is StdinPath
 func LoadWorkflow(path string) (*Workflow, error) {
-	data, err := os.ReadFile(path)
+	data, err := readWorkflowFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+		return nil, err
 	}
 
 	var workflowData map[string]any
@@ -80,10 +202,13 @@ func LoadWorkflow(path string) (*Workflow, error) {
 	}
 
 	// Parse jobs
+	defaults := workflowDefaults(workflowData["defaults"])
 	jobs := make(map[string]*Job)
 	if jobsData, ok := workflowData["jobs"].(map[string]any); ok {
-		// Convert file content to lines for line number detection
+		// Lines are needed to read the comments of ignore directives
 		lines := strings.Split(string(data), "\n")
+		// Comments are only kept by the node tree
+		root, _ := parseDocument(data)
 
 		for jobID, jobData := range jobsData {
 			jobBytes, err := yaml.Marshal(jobData)
@@ -101,171 +226,332 @@ func LoadWorkflow(path string) (*Workflow, error) {
 			if job.Name == "" {
 				job.Name = jobID
 			}
-			// Find line number for this job's runs-on by searching in original file
-			job.LineStart = findRunsOnLineNumber(lines, jobID)
+			job.applyDefaultShell(defaults.Run.Shell)
+			key, value := jobNode(root, jobID)
+			job.Ignore = jobIgnoreDirective(key, lines)
+			job.setPositions(key, value)
+			// Load local actions so their steps are analyzed along with the job's steps
+			job.LocalActions = make(map[string]*LocalAction)
+			loadLocalActions(job.Steps, job.LocalActions)
 			jobs[jobID] = &job
 		}
 	}
 
 	return &Workflow{
-		Path: path,
-		Jobs: jobs,
+		Path:           path,
+		Jobs:           jobs,
+		Permissions:    workflowData["permissions"],
+		Env:            workflowEnv(workflowData["env"]),
+		DispatchInputs: parseInputs(workflowData["on"], "workflow_dispatch"),
+		CallInputs:     parseInputs(workflowData["on"], "workflow_call"),
 	}, nil
 }
 
-// findRunsOnLineNumber finds the line number of runs-on for a specific job by searching in file lines
-func findRunsOnLineNumber(lines []string, jobName string) int {
-	inJobsSection := false
-	inTargetJob := false
-	indentLevel := 0
-
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-
-		// Check if we're in jobs section
-		if trimmed == "jobs:" {
-			inJobsSection = true
-			continue
-		}
-
-		if !inJobsSection {
-			continue
-		}
-
-		// Calculate indentation level
-		lineIndent := 0
-		for _, char := range line {
-			switch char {
-			case ' ':
-				lineIndent++
-			case '\t':
-				lineIndent += 4 // Treat tab as 4 spaces
-			default:
-			}
-		}
+// workflowEnv returns the workflow-level env mapping, or nil if it is not a mapping
+func workflowEnv(env any) map[string]interface{} {
+	m, _ := env.(map[string]any)
+	return m
+}
 
-		// Check if we've left the jobs section (back to top level or another top-level key)
-		if inJobsSection && lineIndent == 0 && trimmed != "" && !strings.HasSuffix(trimmed, ":") {
-			break
-		}
+// Start returns the position LineStart refers to: the job's runs-on, or its
+// key if it has none
+func (j *Job) Start() Position {
+	if j.Positions.RunsOn.Line > 0 {
+		return j.Positions.RunsOn
+	}
+	return j.Positions.Key
+}
 
-		// Check if this is the target job name
-		if inJobsSection && strings.HasPrefix(trimmed, jobName+":") {
-			inTargetJob = true
-			indentLevel = lineIndent
-			continue
+// setPositions sets the positions of the job and its steps, and LineStart,
+// from the job's key and value nodes
+func (j *Job) setPositions(key, value *yaml.Node) {
+	j.Positions.Key = nodePosition(key)
+	runsOn, _ := lookupValue(value, "runs-on")
+	j.Positions.RunsOn = nodePosition(runsOn)
+	container, _ := lookupValue(value, "container")
+	j.Positions.Container = nodePosition(container)
+	services, _ := lookupValue(value, "services")
+	j.Positions.Services = nodePosition(services)
+	j.LineStart = j.Start().Line
+
+	_, seq := lookupValue(value, "steps")
+	if seq == nil || seq.Kind != yaml.SequenceNode || len(seq.Content) != len(j.Steps) {
+		return
+	}
+	steps := j.Steps
+	for i, item := range seq.Content {
+		item = resolveAlias(item)
+		steps[i].Pos = nodePosition(item)
+		_, run := lookupValue(item, "run")
+		switch {
+		case run == nil || run.Kind != yaml.ScalarNode:
+		case run.Style&yaml.LiteralStyle != 0:
+			steps[i].RunLine = run.Line + 1
+		case run.Style&yaml.FoldedStyle == 0 && !strings.Contains(steps[i].Run, "\n"):
+			steps[i].RunLine = run.Line
 		}
+	}
+}
 
-		// If we're in the target job, look for runs-on
-		if inTargetJob {
-			// Check if we've left this job (back to same or lower indent level)
-			if lineIndent <= indentLevel && trimmed != "" && !strings.HasPrefix(trimmed, " ") {
-				break
-			}
+// workflowDefaults returns the workflow-level defaults, or empty defaults if
+// they are not a mapping
+func workflowDefaults(defaults any) Defaults {
+	var d Defaults
+	if data, err := yaml.Marshal(defaults); err == nil {
+		_ = yaml.Unmarshal(data, &d)
+	}
+	return d
+}
 
-			// Look for runs-on line
-			if strings.Contains(trimmed, "runs-on:") {
-				return i + 1 // Line numbers are 1-based
-			}
+// applyDefaultShell sets the shell of run: steps without one to the job's
+// defaults.run.shell, or to workflowShell if the job has none
+func (j *Job) applyDefaultShell(workflowShell string) {
+	shell := j.Defaults.Run.Shell
+	if shell == "" {
+		shell = workflowShell
+	}
+	if shell == "" {
+		return
+	}
+	for i := range j.Steps {
+		if j.Steps[i].Run != "" && j.Steps[i].Shell == "" {
+			j.Steps[i].Shell = shell
 		}
 	}
-
-	return 0
 }
 
 // UpdateRunsOn updates the runs-on value for a specific job in a workflow file
 // jobID is the key in the jobs map (e.g., "Test", "Build")
-// It preserves the original file formatting by doing line-by-line replacement
+// It edits the YAML node holding ubuntu-latest in place, preserving comments,
+// quoting, and formatting. Scalars, flow sequences (runs-on: [ubuntu-latest]),
+// and block sequences are supported.
 func UpdateRunsOn(filePath string, jobID string, newRunsOn string) error {
+	failed, err := UpdateRunsOnJobs(filePath, []string{jobID}, newRunsOn)
+	if err != nil {
+		return err
+	}
+	if err := failed[jobID]; err != nil {
+		return fmt.Errorf("failed to update runs-on for job %s in %s: %w", jobID, filePath, err)
+	}
+	return nil
+}
+
+// UpdateRunsOnJobs updates the runs-on value of several jobs like UpdateRunsOn
+// (replacing ubuntu-latest, or the source label set with SetRunnerLabels),
+// in a single read-modify-write pass. The file is replaced atomically.
+// Jobs whose runs-on cannot be edited are left unchanged and returned with
+// their error, keyed by job ID, while the other jobs are updated. The error
+// is non-nil if the file cannot be read or written, in which case nothing is changed.
+func UpdateRunsOnJobs(filePath string, jobIDs []string, newRunsOn string) (map[string]error, error) {
+	return ReplaceRunnerLabel(filePath, jobIDs, sourceLabel, newRunsOn)
+}
+
+// ReplaceRunnerLabel replaces the runner label from with to in the runs-on
+// value of several jobs, like UpdateRunsOnJobs does for ubuntu-latest (e.g.,
+// to revert jobs from ubuntu-slim to ubuntu-latest).
+func ReplaceRunnerLabel(filePath string, jobIDs []string, from, to string) (map[string]error, error) {
+	if err := readonly.Check("write " + filePath); err != nil {
+		return nil, err
+	}
+
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	updated, failed, err := ReplaceRunnerLabelContent(data, jobIDs, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML %s: %w", filePath, err)
+	}
+	if bytes.Equal(updated, data) {
+		return failed, nil
 	}
 
-	lines := strings.Split(string(data), "\n")
-	updated := false
-	inJobsSection := false
-	inTargetJob := false
-	indentLevel := 0
+	if err := writeFileAtomic(filePath, updated); err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+// ReplaceRunnerLabelContent is ReplaceRunnerLabel for the content of a
+// workflow file: it returns the updated content, and the jobs that cannot be
+// edited with their error. The error is non-nil if data is not valid YAML.
+func ReplaceRunnerLabelContent(data []byte, jobIDs []string, from, to string) ([]byte, map[string]error, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Check if we're in jobs section
-		if trimmed == "jobs:" {
-			inJobsSection = true
+	failed := make(map[string]error)
+	var edits []textEdit
+	for _, jobID := range jobIDs {
+		jobEdits, err := runnerLabelEdits(data, root, jobID, from, to)
+		if err != nil {
+			failed[jobID] = err
 			continue
 		}
+		edits = append(edits, jobEdits...)
+	}
+	return applyEdits(data, edits), failed, nil
+}
 
-		if !inJobsSection {
-			continue
-		}
+// writeFileAtomic replaces a file's content by writing a temporary file in the
+// same directory and renaming it over the original, keeping its permissions,
+// so readers never see a partially written file
+func writeFileAtomic(filePath string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
 
-		// Calculate indentation level
-		lineIndent := 0
-		for _, char := range line {
-			switch char {
-			case ' ':
-				lineIndent++
-			case '\t':
-				lineIndent += 4 // Treat tab as 4 spaces
-			default:
-				// Not a space or tab, stop counting
-			}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// newline returns the line ending of a file ("\r\n" or "\n"), taken from its
+// first line, so that inserted lines match the existing ones
+func newline(data []byte) string {
+	if i := strings.IndexByte(string(data), '\n'); i > 0 && data[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// replaceRunnerLabel replaces the runner label from with to in the runs-on
+// value of a job and returns the updated file content.
+func replaceRunnerLabel(data []byte, jobID, from, to string) ([]byte, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	edits, err := runnerLabelEdits(data, root, jobID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return applyEdits(data, edits), nil
+}
+
+// runnerLabelEdits returns the edits replacing the runner label from with to
+// in the runs-on value of a job. root is the top-level mapping of data.
+// Aliases in runs-on (runs-on: *runner) are replaced by the label, and a
+// runs-on merged from an anchor (<<: *defaults) is overridden in the job, so
+// that other jobs using the anchor are left unchanged.
+func runnerLabelEdits(data []byte, root *yaml.Node, jobID, from, to string) ([]textEdit, error) {
+	_, job := jobNode(root, jobID)
+	if job == nil {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	if job.Kind == yaml.AliasNode {
+		return nil, fmt.Errorf("the job is an alias of *%s and cannot be edited safely", job.Value)
+	}
+	_, runsOn := mappingValue(job, "runs-on")
+	if runsOn == nil {
+		if _, merged := lookupValue(job, "runs-on"); merged != nil {
+			return runsOnOverride(data, job, merged, from, to)
 		}
+		return nil, fmt.Errorf("runs-on not found")
+	}
 
-		// Check if we've left the jobs section
-		if inJobsSection && lineIndent == 0 && trimmed != "" && !strings.HasSuffix(trimmed, ":") {
-			break
+	// Runner groups select their runners by labels
+	if runsOn.Kind == yaml.MappingNode {
+		if _, labels := mappingValue(runsOn, "labels"); labels != nil {
+			runsOn = labels
 		}
+	}
+
+	// Collect nodes holding the label to replace
+	var targets []*yaml.Node
+	switch runsOn.Kind {
+	case yaml.ScalarNode, yaml.AliasNode:
+		targets = append(targets, runsOn)
+	case yaml.SequenceNode:
+		targets = append(targets, runsOn.Content...)
+	}
 
-		// Check if this is the target job ID
-		if inJobsSection && strings.HasPrefix(trimmed, jobID+":") {
-			inTargetJob = true
-			indentLevel = lineIndent
+	var edits []textEdit
+	for _, n := range targets {
+		if resolved := resolveAlias(n); resolved.Kind != yaml.ScalarNode || resolved.Value != from {
 			continue
 		}
+		if n.Anchor != "" {
+			return nil, fmt.Errorf("runs-on defines the anchor &%s; editing it would change the jobs using it", n.Anchor)
+		}
+		var edit textEdit
+		var err error
+		if n.Kind == yaml.AliasNode {
+			edit, err = aliasEdit(data, n, to)
+		} else {
+			edit, err = scalarValueEdit(data, n, to)
+		}
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("runs-on does not contain %s", from)
+	}
+	return edits, nil
+}
 
-		// If we're in the target job, look for runs-on
-		if inTargetJob {
-			// Check if we've left this job
-			if lineIndent <= indentLevel && trimmed != "" && !strings.HasPrefix(trimmed, " ") {
-				break
-			}
+// runsOnOverride returns the edit adding a runs-on key to a job whose runs-on
+// is merged from an anchor, with the label from replaced with to in the
+// merged value
+func runsOnOverride(data []byte, job, merged *yaml.Node, from, to string) ([]textEdit, error) {
+	if job.Style&yaml.FlowStyle != 0 || len(job.Content) == 0 {
+		return nil, fmt.Errorf("runs-on is merged from an anchor into a flow mapping and cannot be edited safely")
+	}
 
-			// Look for runs-on line and replace ubuntu-latest with new value
-			if strings.Contains(trimmed, "runs-on:") {
-				// Handle both "runs-on: ubuntu-latest" and "runs-on:ubuntu-latest" formats
-				if strings.Contains(trimmed, "ubuntu-latest") {
-					// Extract original indentation from the line (preserve exact whitespace)
-					originalIndent := ""
-					for j := 0; j < len(line); j++ {
-						char := line[j]
-						if char == ' ' || char == '\t' {
-							originalIndent += string(char)
-						} else {
-							break
-						}
-					}
-					// Replace the value while preserving original indentation and format
-					// Use the exact same format as the original line
-					lines[i] = originalIndent + "runs-on: " + newRunsOn
-					updated = true
-					break
-				}
+	var value string
+	switch merged.Kind {
+	case yaml.ScalarNode:
+		if merged.Value == from {
+			value = quoteScalar(to)
+		}
+	case yaml.SequenceNode:
+		labels := make([]string, len(merged.Content))
+		replaced := false
+		for i, item := range merged.Content {
+			item = resolveAlias(item)
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("runs-on merged from an anchor must be a label or a list of labels")
+			}
+			labels[i] = quoteScalar(item.Value)
+			if item.Value == from {
+				labels[i] = quoteScalar(to)
+				replaced = true
 			}
 		}
+		if replaced {
+			value = "[" + strings.Join(labels, ", ") + "]"
+		}
 	}
-
-	if !updated {
-		return fmt.Errorf("failed to find runs-on for job %s in %s", jobID, filePath)
+	if value == "" {
+		return nil, fmt.Errorf("runs-on does not contain %s", from)
 	}
 
-	// Write updated content back to file
-	updatedContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(filePath, []byte(updatedContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	first := job.Content[0]
+	offset, err := nodeOffset(data, first)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return []textEdit{{offset: offset, text: "runs-on: " + value + newline(data) + pad(first.Column-1)}}, nil
 }
@@ -327,6 +327,37 @@ func TestLoadWorkflows_InvalidFile(t *testing.T) {
 	}
 }
 
+func TestLoadWorkflows_SetDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	workflowDir := filepath.Join(tmpDir, ".gitea", "workflows")
+	if err := os.MkdirAll(workflowDir, 0755); err != nil {
+		t.Fatalf("Failed to create workflow directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workflowDir, "valid.yml"), []byte(loadTestData(t, "valid.yml")), 0644); err != nil {
+		t.Fatalf("Failed to write valid file: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	SetDir("./.gitea/workflows")
+	defer SetDir("")
+	if got := Dir(); got != ".gitea/workflows" {
+		t.Errorf("Dir() = %q, want %q", got, ".gitea/workflows")
+	}
+
+	loaded, err := LoadWorkflows()
+	if err != nil {
+		t.Fatalf("LoadWorkflows() error: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Path != filepath.Join(".gitea", "workflows", "valid.yml") {
+		t.Errorf("LoadWorkflows() = %v, want .gitea/workflows/valid.yml", loaded)
+	}
+
+	SetDir("")
+	if got := Dir(); got != DefaultDir {
+		t.Errorf("Dir() after SetDir(\"\") = %q, want %q", got, DefaultDir)
+	}
+}
+
 func TestUpdateRunsOn_Basic(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -550,6 +581,99 @@ func TestUpdateRunsOn_Basic(t *testing.T) {
 	}
 }
 
+func TestUpdateRunsOn_Formats(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "plain scalar with trailing comment",
+			content:  "jobs:\n  test:\n    runs-on: ubuntu-latest # keep this comment\n",
+			expected: "jobs:\n  test:\n    runs-on: ubuntu-slim # keep this comment\n",
+		},
+		{
+			name:     "double-quoted scalar",
+			content:  "jobs:\n  test:\n    runs-on: \"ubuntu-latest\"\n",
+			expected: "jobs:\n  test:\n    runs-on: \"ubuntu-slim\"\n",
+		},
+		{
+			name:     "single-quoted scalar",
+			content:  "jobs:\n  test:\n    runs-on: 'ubuntu-latest'\n",
+			expected: "jobs:\n  test:\n    runs-on: 'ubuntu-slim'\n",
+		},
+		{
+			name:     "flow sequence",
+			content:  "jobs:\n  test:\n    runs-on: [ubuntu-latest]\n",
+			expected: "jobs:\n  test:\n    runs-on: [ubuntu-slim]\n",
+		},
+		{
+			name:     "block sequence",
+			content:  "jobs:\n  test:\n    runs-on:\n      - ubuntu-latest\n    steps:\n      - run: echo\n",
+			expected: "jobs:\n  test:\n    runs-on:\n      - ubuntu-slim\n    steps:\n      - run: echo\n",
+		},
+		{
+			name:     "literal block scalar",
+			content:  "jobs:\n  test:\n    runs-on: >-\n      ubuntu-latest\n",
+			expected: "jobs:\n  test:\n    runs-on: >-\n      ubuntu-slim\n",
+		},
+		{
+			name:     "job ID that prefixes another job",
+			content:  "jobs:\n  test-e2e:\n    runs-on: ubuntu-latest\n  test:\n    runs-on: ubuntu-latest\n",
+			expected: "jobs:\n  test-e2e:\n    runs-on: ubuntu-latest\n  test:\n    runs-on: ubuntu-slim\n",
+		},
+		{
+			name:     "non-ASCII characters before the value on the same line",
+			content:  "jobs:\n  test:\n    runs-on: [ランナー, ubuntu-latest]\n",
+			expected: "jobs:\n  test:\n    runs-on: [ランナー, ubuntu-slim]\n",
+		},
+		{
+			name:     "runner group in a flow mapping",
+			content:  "jobs:\n  test:\n    runs-on: { group: larger, labels: [ubuntu-latest] }\n",
+			expected: "jobs:\n  test:\n    runs-on: { group: larger, labels: [ubuntu-slim] }\n",
+		},
+		{
+			name:     "runner group in a block mapping",
+			content:  "jobs:\n  test:\n    runs-on:\n      group: larger\n      labels: ubuntu-latest\n",
+			expected: "jobs:\n  test:\n    runs-on:\n      group: larger\n      labels: ubuntu-slim\n",
+		},
+		{
+			name:    "runs-on without ubuntu-latest",
+			content: "jobs:\n  test:\n    runs-on: ubuntu-22.04\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "workflow.yml")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			err := UpdateRunsOn(filePath, "test", "ubuntu-slim")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("UpdateRunsOn() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateRunsOn() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Failed to read updated file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("UpdateRunsOn() result mismatch\ngot:\n%s\nwant:\n%s", data, tt.expected)
+			}
+		})
+	}
+}
+
 func TestJob_IsUbuntuLatest(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -658,6 +782,62 @@ func TestJob_HasDockerCommands(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "docker buildx",
+			job: &Job{
+				Steps: []Step{{Run: "docker buildx build --push ."}},
+			},
+			expected: true,
+		},
+		{
+			name: "podman",
+			job: &Job{
+				Steps: []Step{{Run: "podman build -t app ."}},
+			},
+			expected: true,
+		},
+		{
+			name: "podman-compose",
+			job: &Job{
+				Steps: []Step{{Run: "podman-compose up -d"}},
+			},
+			expected: true,
+		},
+		{
+			name: "buildah",
+			job: &Job{
+				Steps: []Step{{Run: "sudo buildah bud -t app ."}},
+			},
+			expected: true,
+		},
+		{
+			name: "nerdctl",
+			job: &Job{
+				Steps: []Step{{Run: "nerdctl run --rm alpine"}},
+			},
+			expected: true,
+		},
+		{
+			name: "skopeo",
+			job: &Job{
+				Steps: []Step{{Run: "skopeo copy docker://a docker://b"}},
+			},
+			expected: true,
+		},
+		{
+			name: "kaniko executor",
+			job: &Job{
+				Steps: []Step{{Run: "/kaniko/executor --context . --destination app"}},
+			},
+			expected: true,
+		},
+		{
+			name: "container tool as an argument",
+			job: &Job{
+				Steps: []Step{{Run: "echo podman build"}},
+			},
+			expected: false,
+		},
 		{
 			name: "multiple steps without docker",
 			job: &Job{
@@ -707,6 +887,13 @@ func TestJob_HasContainerActions(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "redhat-actions/buildah-build",
+			job: &Job{
+				Steps: []Step{{Uses: "redhat-actions/buildah-build@v2"}},
+			},
+			expected: true,
+		},
 		{
 			name: "docker/build-push-action",
 			job: &Job{
@@ -795,3 +982,206 @@ func TestJob_HasServices(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateRunsOnJobs(t *testing.T) {
+	content := "jobs:\n  lint:\n    runs-on: ubuntu-latest\n  test:\n    runs-on: [ubuntu-latest]\n  win:\n    runs-on: windows-latest\n"
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	failed, err := UpdateRunsOnJobs(filePath, []string{"lint", "test", "win", "missing"}, "ubuntu-slim")
+	if err != nil {
+		t.Fatalf("UpdateRunsOnJobs() unexpected error: %v", err)
+	}
+	if len(failed) != 2 || failed["win"] == nil || failed["missing"] == nil {
+		t.Errorf("UpdateRunsOnJobs() failed = %v, want errors for win and missing", failed)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+	expected := "jobs:\n  lint:\n    runs-on: ubuntu-slim\n  test:\n    runs-on: [ubuntu-slim]\n  win:\n    runs-on: windows-latest\n"
+	if string(data) != expected {
+		t.Errorf("UpdateRunsOnJobs() result mismatch\ngot:\n%s\nwant:\n%s", data, expected)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600 to be preserved", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want no leftover temporary files", len(entries))
+	}
+}
+
+func TestReplaceRunnerLabel(t *testing.T) {
+	content := "jobs:\n  lint:\n    runs-on: ubuntu-slim # migrated\n  test:\n    runs-on: [\"ubuntu-slim\"]\n  build:\n    runs-on: ubuntu-latest\n"
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	failed, err := ReplaceRunnerLabel(filePath, []string{"lint", "test", "build"}, "ubuntu-slim", "ubuntu-latest")
+	if err != nil {
+		t.Fatalf("ReplaceRunnerLabel() unexpected error: %v", err)
+	}
+	if len(failed) != 1 || failed["build"] == nil {
+		t.Errorf("ReplaceRunnerLabel() failed = %v, want an error for build only", failed)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read updated file: %v", err)
+	}
+	expected := "jobs:\n  lint:\n    runs-on: ubuntu-latest # migrated\n  test:\n    runs-on: [\"ubuntu-latest\"]\n  build:\n    runs-on: ubuntu-latest\n"
+	if string(data) != expected {
+		t.Errorf("ReplaceRunnerLabel() result mismatch\ngot:\n%s\nwant:\n%s", data, expected)
+	}
+}
+
+func TestLoadWorkflow_Positions(t *testing.T) {
+	content := `on: push
+x-defaults: &defaults
+  runs-on: ubuntu-latest
+  container: node:20
+env:
+  test: "1"
+jobs:
+  test-e2e:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo e2e
+  test:
+    <<: *defaults
+    steps:
+      - uses: actions/checkout@v4
+      -   run: make test
+  call:
+    uses: ./.github/workflows/reusable.yml
+`
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	wf, err := LoadWorkflow(filePath)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error: %v", err)
+	}
+
+	tests := []struct {
+		jobID     string
+		lineStart int
+		positions JobPositions
+	}{
+		{"test-e2e", 9, JobPositions{Key: Position{8, 3}, RunsOn: Position{9, 5}}},
+		// runs-on and container come from the anchor
+		{"test", 3, JobPositions{Key: Position{12, 3}, RunsOn: Position{3, 3}, Container: Position{4, 3}}},
+		{"call", 17, JobPositions{Key: Position{17, 3}}},
+	}
+	for _, tt := range tests {
+		job := wf.Jobs[tt.jobID]
+		if job.LineStart != tt.lineStart {
+			t.Errorf("%s: LineStart = %d, want %d", tt.jobID, job.LineStart, tt.lineStart)
+		}
+		if job.Positions != tt.positions {
+			t.Errorf("%s: Positions = %+v, want %+v", tt.jobID, job.Positions, tt.positions)
+		}
+	}
+
+	if ids := strings.Join(wf.JobIDs(), ","); ids != "test-e2e,test,call" {
+		t.Errorf("JobIDs() = %s, want the order of the job keys", ids)
+	}
+
+	steps := wf.Jobs["test"].Steps
+	if steps[0].Pos != (Position{15, 9}) || steps[1].Pos != (Position{16, 11}) {
+		t.Errorf("step positions = %+v, %+v, want 15:9 and 16:11", steps[0].Pos, steps[1].Pos)
+	}
+}
+
+func TestUpdateRunsOnJobs_Anchors(t *testing.T) {
+	content := `on: push
+x-runner: &runner ubuntu-latest
+x-job: &job
+  runs-on: ubuntu-latest
+  timeout-minutes: 10
+jobs:
+  lint:
+    <<: *job
+    steps:
+      - run: make lint
+  test:
+    runs-on: *runner
+    steps:
+      - run: make test
+  vet:
+    runs-on: [*runner, self-hosted]
+    steps:
+      - run: go vet
+  anchored:
+    runs-on: &label ubuntu-latest
+    steps:
+      - run: make
+  copy: *job
+`
+	want := `on: push
+x-runner: &runner ubuntu-latest
+x-job: &job
+  runs-on: ubuntu-latest
+  timeout-minutes: 10
+jobs:
+  lint:
+    runs-on: ubuntu-slim
+    <<: *job
+    steps:
+      - run: make lint
+  test:
+    runs-on: ubuntu-slim
+    steps:
+      - run: make test
+  vet:
+    runs-on: [ubuntu-slim, self-hosted]
+    steps:
+      - run: go vet
+  anchored:
+    runs-on: &label ubuntu-latest
+    steps:
+      - run: make
+  copy: *job
+`
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	failed, err := UpdateRunsOnJobs(filePath, []string{"lint", "test", "vet", "anchored", "copy"}, "ubuntu-slim")
+	if err != nil {
+		t.Fatalf("UpdateRunsOnJobs() error: %v", err)
+	}
+	if len(failed) != 2 || failed["anchored"] == nil || failed["copy"] == nil {
+		t.Errorf("UpdateRunsOnJobs() failed = %v, want anchored and copy", failed)
+	}
+	got, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("UpdateRunsOnJobs() result:\n%s\nwant:\n%s", got, want)
+	}
+
+	wf, err := LoadWorkflow(filePath)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error: %v", err)
+	}
+	if runsOn := wf.Jobs["lint"].RunsOn; runsOn != "ubuntu-slim" {
+		t.Errorf("lint runs-on = %v, want the override to take precedence over the merge key", runsOn)
+	}
+}
@@ -0,0 +1,166 @@
+// Package slimify is the Go API of gh-slimify. It finds the GitHub Actions
+// jobs that can migrate from ubuntu-latest to the lightweight ubuntu-slim
+// runner, so that other tools (organization dashboards, bots) can embed the
+// analysis without running the CLI:
+//
+//	result, err := slimify.Scan(slimify.Options{Dir: "path/to/repo"})
+//	if err != nil {
+//		return err
+//	}
+//	for _, c := range result.Candidates {
+//		fmt.Printf("%s:%d %s can use ubuntu-slim\n", c.WorkflowPath, c.LineNumber, c.JobName)
+//	}
+//
+// The analysis is offline by default. With Options.LookupDurations or
+// Options.ResolveActions, the GitHub API is called with the credentials of gh
+// or GH_TOKEN, for the repository given by GH_REPO or the origin remote of the
+// current directory.
+package slimify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fchimpan/gh-slimify/internal/api"
+	"github.com/fchimpan/gh-slimify/internal/scan"
+	"github.com/fchimpan/gh-slimify/internal/workflow"
+)
+
+// Workflow is a parsed workflow file
+type Workflow = workflow.Workflow
+
+// Job is a job of a workflow
+type Job = workflow.Job
+
+// Rule is a check a job must pass to migrate to ubuntu-slim
+type Rule = scan.Rule
+
+// Severity is how a rule violation affects a job
+type Severity = scan.Severity
+
+// Rule severities
+const (
+	SeverityError   = scan.SeverityError
+	SeverityWarning = scan.SeverityWarning
+	SeverityInfo    = scan.SeverityInfo
+)
+
+// ScanResult is the result of a scan: the jobs that can migrate
+// (Candidates), those that cannot (IneligibleJobs), and the workflow files
+// that failed to load
+type ScanResult = scan.ScanResult
+
+// Candidate is a job that can migrate to ubuntu-slim
+type Candidate = scan.Candidate
+
+// IneligibleJob is a job that cannot migrate to ubuntu-slim, with the reasons
+type IneligibleJob = scan.IneligibleJob
+
+// RuleResult is the result of a rule evaluated against a job
+type RuleResult = scan.RuleResult
+
+// DurationProvider looks up the durations of a job in its latest successful
+// runs, newest first. Implement it to supply cached or mocked durations.
+type DurationProvider = scan.DurationProvider
+
+// JobDuration is the duration of a job in a run, returned by a
+// DurationProvider
+type JobDuration = api.JobDuration
+
+// OfflineDurations is a DurationProvider that never accesses the network:
+// every duration is unknown
+var OfflineDurations = scan.OfflineDurations
+
+// Options configures Scan. The zero value scans the workflows of the current
+// directory with every rule and without network access.
+type Options struct {
+	// Dir is the repository root whose .github/workflows are scanned when no
+	// paths are given. Empty means the current directory.
+	Dir string
+	// LookupDurations fetches the last execution time of each candidate from
+	// the GitHub API (Candidate.Duration)
+	LookupDurations bool
+	// Durations, if set, looks up durations instead of the GitHub API
+	// (LookupDurations is implied). Its results are not cached.
+	Durations DurationProvider
+	// ResolveActions fetches the action.yml of third-party actions from the
+	// GitHub API and marks jobs using Docker-based actions as ineligible
+	ResolveActions bool
+	// DisabledRules lists rules to disable, by ID (e.g., SLIM007) or name
+	DisabledRules []string
+	// MaxWorkers is the number of GitHub API lookups made concurrently. If
+	// zero, a default is used.
+	MaxWorkers int
+	// Deadline bounds the time spent on network lookups. Zero means no limit.
+	Deadline time.Duration
+	// MaxDuration marks the candidates whose execution time exceeds it as
+	// requiring attention (Candidate.LongRunning). Zero means no limit.
+	MaxDuration time.Duration
+}
+
+// LoadWorkflow parses a workflow file
+func LoadWorkflow(path string) (*Workflow, error) {
+	return workflow.LoadWorkflow(path)
+}
+
+// Rules returns every rule, in evaluation order
+func Rules() []*Rule {
+	return scan.Rules()
+}
+
+// LookupRule returns the rule with the given ID or name
+func LookupRule(idOrName string) (*Rule, bool) {
+	return scan.LookupRule(idOrName)
+}
+
+// Scan analyzes the given workflow files, or every workflow of Options.Dir if
+// none are given. Files that fail to load are reported in
+// ScanResult.LoadErrors instead of failing the scan.
+func Scan(opts Options, paths ...string) (*ScanResult, error) {
+	rules := scan.NewRuleSet()
+	if err := rules.Disable(opts.DisabledRules...); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		var err error
+		if paths, err = workflowFiles(opts.Dir); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return &ScanResult{Candidates: []*Candidate{}, IneligibleJobs: []*IneligibleJob{}}, nil
+		}
+	}
+	return scan.ScanWithOptions(scan.Options{
+		SkipDuration:   !opts.LookupDurations && opts.Durations == nil,
+		Durations:      opts.Durations,
+		ResolveActions: opts.ResolveActions,
+		Rules:          rules,
+		MaxWorkers:     opts.MaxWorkers,
+		Deadline:       opts.Deadline,
+		MaxDuration:    opts.MaxDuration,
+	}, paths...)
+}
+
+// workflowFiles lists the workflow files under dir/.github/workflows
+func workflowFiles(dir string) ([]string, error) {
+	root := filepath.Join(dir, workflow.DefaultDir)
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("workflow directory not found: %s", root)
+	}
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
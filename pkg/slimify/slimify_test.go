@@ -0,0 +1,55 @@
+package slimify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	workflows := filepath.Join(dir, ".github", "workflows")
+	if err := os.MkdirAll(workflows, 0755); err != nil {
+		t.Fatalf("Failed to create workflow directory: %v", err)
+	}
+	content := `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo lint
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker build .
+`
+	if err := os.WriteFile(filepath.Join(workflows, "ci.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	result, err := Scan(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 1 || result.Candidates[0].JobID != "lint" {
+		t.Errorf("Scan() candidates = %+v, want lint", result.Candidates)
+	}
+	if len(result.IneligibleJobs) != 1 || result.IneligibleJobs[0].JobID != "image" {
+		t.Errorf("Scan() ineligible jobs = %+v, want image", result.IneligibleJobs)
+	}
+
+	result, err = Scan(Options{Dir: dir, DisabledRules: []string{"docker-commands"}})
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(result.Candidates) != 2 {
+		t.Errorf("Scan() with docker-commands disabled candidates = %d, want 2", len(result.Candidates))
+	}
+
+	if _, err := Scan(Options{DisabledRules: []string{"no-such-rule"}}); err == nil {
+		t.Error("Scan() with an unknown rule error = nil, want an error")
+	}
+	if _, err := Scan(Options{Dir: t.TempDir()}); err == nil {
+		t.Error("Scan() without workflows directory error = nil, want an error")
+	}
+}